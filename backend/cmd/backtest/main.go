@@ -0,0 +1,120 @@
+// Command backtest replays a family's logged feeds and sleeps through the prediction
+// engine and reports how accurate its predictions would have been.
+//
+//	go run ./cmd/backtest -family <id> [-from 2026-01-01] [-to 2026-02-01] [-step 30m] [-model recency_weighted -half-life 24h]
+//
+// It reads DATABASE_URL like the server does.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
+	"github.com/swatkatz/babybaton/backend/internal/prediction/backtest"
+	"github.com/swatkatz/babybaton/backend/internal/store/postgres"
+)
+
+func main() {
+	familyFlag := flag.String("family", "", "family ID to replay (required)")
+	fromFlag := flag.String("from", "", "first simulated date, YYYY-MM-DD (default: a day after the first record)")
+	toFlag := flag.String("to", "", "last simulated date, YYYY-MM-DD (default: the last record)")
+	step := flag.Duration("step", backtest.DefaultStep, "time between simulated points")
+	timezoneFlag := flag.String("timezone", "", "IANA timezone (default: the family's)")
+	modelFlag := flag.String("model", "", "prediction model, median or recency_weighted (default: the family's)")
+	halfLife := flag.Duration("half-life", 0, "half-life of recency_weighted (default: the family's)")
+	flag.Parse()
+
+	familyID, err := uuid.Parse(*familyFlag)
+	if err != nil {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		log.Fatal("DATABASE_URL environment variable is required")
+	}
+	store, err := postgres.NewPostgresStore(databaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	family, err := store.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		log.Fatalf("Failed to get family: %v", err)
+	}
+
+	timezone := *timezoneFlag
+	if timezone == "" {
+		timezone = family.Timezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Fatalf("Invalid timezone %q: %v", timezone, err)
+	}
+	switch model := domain.PredictionModel(*modelFlag); model {
+	case "":
+	case domain.PredictionModelMedian, domain.PredictionModelRecencyWeighted:
+		family.PredictionModel = model
+	default:
+		log.Fatalf("Invalid -model %q: want median or recency_weighted", *modelFlag)
+	}
+	if *halfLife != 0 {
+		family.PredictionHalfLife = *halfLife
+	}
+	predictor := prediction.PredictorFor(family)
+
+	opts := backtest.Options{Step: *step, Timezone: loc.String(), Predictor: predictor}
+	if opts.From, err = parseDate(*fromFlag, loc); err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+	if opts.To, err = parseDate(*toFlag, loc); err != nil {
+		log.Fatalf("Invalid -to: %v", err)
+	}
+
+	babies, err := store.GetBabiesForFamily(ctx, familyID)
+	if err != nil {
+		log.Fatalf("Failed to get babies: %v", err)
+	}
+
+	for _, baby := range babies {
+		// The whole history; Run limits what the engine sees at each point
+		feedDetails, err := store.GetRecentFeedDetailsForBaby(ctx, baby.ID, math.MaxInt32)
+		if err != nil {
+			log.Fatalf("Failed to get feeds for %s: %v", baby.Name, err)
+		}
+		sleepDetails, err := store.GetRecentSleepDetailsForBaby(ctx, baby.ID, math.MaxInt32)
+		if err != nil {
+			log.Fatalf("Failed to get sleeps for %s: %v", baby.Name, err)
+		}
+
+		fmt.Printf("%s (%d feeds, %d sleeps, %s, %s)\n", baby.Name, len(feedDetails), len(sleepDetails), loc, predictor.Describe())
+		result := backtest.Run(prediction.FeedRecords(feedDetails), prediction.SleepRecords(sleepDetails), opts)
+		if err := result.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println()
+	}
+}
+
+// parseDate parses a YYYY-MM-DD flag as midnight in loc; empty means unset
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, loc)
+}
@@ -0,0 +1,604 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrCodeVersionConflict is the error code for an edit rejected because the activity was
+// changed after the version the client edited.
+const ErrCodeVersionConflict = "VERSION_CONFLICT"
+
+// sessionForNewActivities returns the family's in-progress session for caregiverID, creating
+// one if needed (started reports whether it did). If another caregiver's session is in
+// progress it is completed first (passing the baton) and returned as handedOff so the caller
+// can notify subscribers after commit.
+func sessionForNewActivities(ctx context.Context, tx store.Store, caregiverID, familyID uuid.UUID) (session, handedOff *domain.CareSession, started bool, err error) {
+	session, err = tx.GetInProgressSessionForFamily(ctx, familyID)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get in-progress session: %w", err)
+	}
+
+	// If a different caregiver is adding activities, auto-complete the old session (pass the baton)
+	if session != nil && session.CaregiverID != caregiverID {
+		before := mapper.CareSessionToGraphQL(session)
+		now := time.Now()
+		session.Status = domain.StatusCompleted
+		session.CompletedAt = &now
+		session.UpdatedAt = now
+		if err := tx.UpdateCareSession(ctx, session); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to complete previous session: %w", err)
+		}
+		if err := audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityCareSession, session.ID, before, mapper.CareSessionToGraphQL(session)); err != nil {
+			return nil, nil, false, err
+		}
+		// Auto-end any active sleep activities in the old session
+		if err := endActiveSleeps(ctx, tx, session.ID, now); err != nil {
+			return nil, nil, false, err
+		}
+		fmt.Printf("🤝 Session handoff: completed session %s, new caregiver taking over\n", session.ID)
+		handedOff = session
+		session = nil
+	}
+
+	if session == nil {
+		// No active session, create one
+		now := time.Now()
+		session = &domain.CareSession{
+			ID:          uuid.New(),
+			CaregiverID: caregiverID,
+			FamilyID:    familyID,
+			Status:      domain.StatusInProgress,
+			StartedAt:   now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := tx.CreateCareSession(ctx, session); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to create care session: %w", err)
+		}
+		if err := audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityCareSession, session.ID, nil, mapper.CareSessionToGraphQL(session)); err != nil {
+			return nil, nil, false, err
+		}
+		fmt.Printf("✨ Created new care session: %s\n", session.ID)
+		started = true
+	}
+
+	return session, handedOff, started, nil
+}
+
+// createActivity writes an activity and its details from input, and records how the
+// baby's outstanding prediction for a feed or sleep turned out. check carries the dose
+// safety outcome for medication activities.
+func createActivity(ctx context.Context, tx store.Store, id, sessionID, babyID uuid.UUID, input *model.ActivityInput, check doseCheck) (*domain.Activity, error) {
+	now := time.Now()
+
+	// Convert GraphQL ActivityType (FEED, DIAPER, SLEEP, PUMP) to domain ActivityType (feed, diaper, sleep, pump)
+	activityType := strings.ToLower(string(input.ActivityType))
+
+	activity := &domain.Activity{
+		ID:            id,
+		CareSessionID: sessionID,
+		BabyID:        babyID,
+		ActivityType:  domain.ActivityType(activityType),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := tx.CreateActivity(ctx, activity); err != nil {
+		return nil, fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	// Create activity details based on type
+	switch input.ActivityType {
+	case model.ActivityTypeFeed:
+		if input.FeedDetails == nil {
+			return nil, fmt.Errorf("feed activity requires feedDetails")
+		}
+
+		// Convert GraphQL FeedType (BREAST_MILK, FORMULA, SOLIDS) to domain FeedType (breast_milk, formula, solids)
+		var feedType *domain.FeedType
+		if input.FeedDetails.FeedType != nil {
+			ft := domain.FeedType(strings.ToLower(string(*input.FeedDetails.FeedType)))
+			feedType = &ft
+		}
+
+		// Convert *int32 to *int for AmountMl
+		var amountMl *int
+		if input.FeedDetails.AmountMl != nil {
+			val := int(*input.FeedDetails.AmountMl)
+			amountMl = &val
+		}
+
+		// Convert SolidsUnit to string for QuantityUnit
+		var quantityUnit *string
+		if input.FeedDetails.QuantityUnit != nil {
+			qu := strings.ToLower(string(*input.FeedDetails.QuantityUnit))
+			quantityUnit = &qu
+		}
+
+		details := &domain.FeedDetails{
+			ID:           uuid.New(),
+			ActivityID:   activity.ID,
+			StartTime:    input.FeedDetails.StartTime,
+			EndTime:      input.FeedDetails.EndTime,
+			AmountMl:     amountMl,
+			FeedType:     feedType,
+			FoodName:     input.FeedDetails.FoodName,
+			Quantity:     input.FeedDetails.Quantity,
+			QuantityUnit: quantityUnit,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		fmt.Printf("📝 Creating feed details for activity %s (feed_type: %v)\n", activity.ID, feedType)
+		if err := tx.CreateFeedDetails(ctx, details); err != nil {
+			return nil, fmt.Errorf("failed to create feed details: %w", err)
+		}
+		fmt.Printf("✅ Feed details created successfully\n")
+		if err := prediction.RecordOutcome(ctx, tx, activity, details.StartTime, domain.PredictionTypeNextFeed); err != nil {
+			return nil, err
+		}
+
+	case model.ActivityTypeDiaper:
+		if input.DiaperDetails == nil {
+			return nil, fmt.Errorf("diaper activity requires diaperDetails")
+		}
+		hadPee := false
+		if input.DiaperDetails.HadPee != nil {
+			hadPee = *input.DiaperDetails.HadPee
+		}
+		details := &domain.DiaperDetails{
+			ID:         uuid.New(),
+			ActivityID: activity.ID,
+			ChangedAt:  input.DiaperDetails.ChangedAt,
+			HadPoop:    input.DiaperDetails.HadPoop,
+			HadPee:     hadPee,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := tx.CreateDiaperDetails(ctx, details); err != nil {
+			return nil, fmt.Errorf("failed to create diaper details: %w", err)
+		}
+
+	case model.ActivityTypeSleep:
+		if input.SleepDetails == nil {
+			return nil, fmt.Errorf("sleep activity requires sleepDetails")
+		}
+		var durationMinutes *int
+		if input.SleepDetails.EndTime != nil {
+			duration := int(input.SleepDetails.EndTime.Sub(input.SleepDetails.StartTime).Minutes())
+			durationMinutes = &duration
+		}
+		details := &domain.SleepDetails{
+			ID:              uuid.New(),
+			ActivityID:      activity.ID,
+			StartTime:       input.SleepDetails.StartTime,
+			EndTime:         input.SleepDetails.EndTime,
+			DurationMinutes: durationMinutes,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if err := tx.CreateSleepDetails(ctx, details); err != nil {
+			return nil, fmt.Errorf("failed to create sleep details: %w", err)
+		}
+		// Whichever of the next nap and bedtime is closer
+		if err := prediction.RecordOutcome(ctx, tx, activity, details.StartTime, domain.PredictionTypeNextNap, domain.PredictionTypeBedtime); err != nil {
+			return nil, err
+		}
+
+	case model.ActivityTypePump:
+		if input.PumpDetails == nil {
+			return nil, fmt.Errorf("pump activity requires pumpDetails")
+		}
+		leftMl := int32PtrToInt(input.PumpDetails.LeftMl)
+		rightMl := int32PtrToInt(input.PumpDetails.RightMl)
+		details := &domain.PumpDetails{
+			ID:         uuid.New(),
+			ActivityID: activity.ID,
+			StartTime:  input.PumpDetails.StartTime,
+			EndTime:    input.PumpDetails.EndTime,
+			LeftMl:     leftMl,
+			RightMl:    rightMl,
+			TotalMl:    pumpTotalMl(leftMl, rightMl, int32PtrToInt(input.PumpDetails.TotalMl)),
+			Notes:      input.PumpDetails.Notes,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := tx.CreatePumpDetails(ctx, details); err != nil {
+			return nil, fmt.Errorf("failed to create pump details: %w", err)
+		}
+
+	case model.ActivityTypeMedication:
+		if input.MedicationDetails == nil {
+			return nil, fmt.Errorf("medication activity requires medicationDetails")
+		}
+		route := domain.MedicationRouteOral
+		if input.MedicationDetails.Route != nil {
+			route = domain.MedicationRoute(strings.ToLower(string(*input.MedicationDetails.Route)))
+		}
+		details := &domain.MedicationDetails{
+			ID:                 uuid.New(),
+			ActivityID:         activity.ID,
+			DrugName:           strings.TrimSpace(input.MedicationDetails.DrugName),
+			DoseAmount:         input.MedicationDetails.DoseAmount,
+			DoseUnit:           domain.DoseUnit(strings.ToLower(string(input.MedicationDetails.DoseUnit))),
+			Route:              route,
+			GivenAt:            input.MedicationDetails.GivenAt,
+			IntervalOverridden: check.overridden,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+		if med := check.medication; med != nil {
+			details.MedicationID = &med.ID
+		}
+		if err := tx.CreateMedicationDetails(ctx, details); err != nil {
+			return nil, fmt.Errorf("failed to create medication details: %w", err)
+		}
+	}
+
+	after, err := loadActivityFrom(ctx, tx, activity)
+	if err != nil {
+		return nil, err
+	}
+	if err := audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityActivity, activity.ID, nil, after); err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+// endActiveSleeps ends the session's sleeps that are still running at now, as when the
+// session completes.
+func endActiveSleeps(ctx context.Context, tx store.Store, sessionID uuid.UUID, now time.Time) error {
+	activities, err := tx.GetActivitiesForSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get activities for session: %w", err)
+	}
+	for _, activity := range activities {
+		if activity.ActivityType != domain.ActivityTypeSleep {
+			continue
+		}
+		sleepDetails, err := tx.GetSleepDetails(ctx, activity.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get sleep details: %w", err)
+		}
+		if sleepDetails.EndTime != nil {
+			continue
+		}
+
+		before, err := loadActivityFrom(ctx, tx, activity)
+		if err != nil {
+			return err
+		}
+		sleepDetails.EndTime = &now
+		duration := int(now.Sub(sleepDetails.StartTime).Minutes())
+		sleepDetails.DurationMinutes = &duration
+		sleepDetails.UpdatedAt = now
+		if err := tx.UpdateSleepDetails(ctx, sleepDetails); err != nil {
+			return fmt.Errorf("failed to end active sleep: %w", err)
+		}
+		if activity.Version, err = tx.TouchActivity(ctx, activity.ID, nil); err != nil {
+			return err
+		}
+		after, err := loadActivityFrom(ctx, tx, activity)
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityActivity, activity.ID, before, after); err != nil {
+			return err
+		}
+		fmt.Printf("💤 Auto-ended sleep activity %s (duration: %d minutes)\n", activity.ID, duration)
+	}
+	return nil
+}
+
+// editActivity is updateActivityDetails recorded in the audit log.
+func editActivity(ctx context.Context, tx store.Store, familyID uuid.UUID, activity *domain.Activity, input model.ActivityInput, expectedVersion *int) (model.Activity, error) {
+	before, err := loadActivityFrom(ctx, tx, activity)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := updateActivityDetails(ctx, tx, familyID, activity, input, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityActivity, activity.ID, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// updateActivityDetails applies input to an existing activity's details and bumps the
+// activity's version so editors and syncing clients pick up the change. When
+// expectedVersion is set, the edit fails with a *store.VersionConflictError if the
+// activity has changed since.
+func updateActivityDetails(ctx context.Context, tx store.Store, familyID uuid.UUID, activity *domain.Activity, input model.ActivityInput, expectedVersion *int) (model.Activity, error) {
+	version, err := tx.TouchActivity(ctx, activity.ID, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	activity.Version = version
+
+	now := time.Now()
+
+	switch activity.ActivityType {
+	case domain.ActivityTypeFeed:
+		if input.FeedDetails == nil {
+			return nil, fmt.Errorf("feed activity requires feedDetails")
+		}
+
+		feedDetails, err := tx.GetFeedDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feed details: %w", err)
+		}
+
+		// Update fields
+		feedDetails.StartTime = input.FeedDetails.StartTime
+		feedDetails.EndTime = input.FeedDetails.EndTime
+
+		if input.FeedDetails.AmountMl != nil {
+			val := int(*input.FeedDetails.AmountMl)
+			feedDetails.AmountMl = &val
+		} else {
+			feedDetails.AmountMl = nil
+		}
+
+		if input.FeedDetails.FeedType != nil {
+			ft := domain.FeedType(strings.ToLower(string(*input.FeedDetails.FeedType)))
+			feedDetails.FeedType = &ft
+		} else {
+			feedDetails.FeedType = nil
+		}
+
+		feedDetails.FoodName = input.FeedDetails.FoodName
+		feedDetails.Quantity = input.FeedDetails.Quantity
+
+		if input.FeedDetails.QuantityUnit != nil {
+			qu := strings.ToLower(string(*input.FeedDetails.QuantityUnit))
+			feedDetails.QuantityUnit = &qu
+		} else {
+			feedDetails.QuantityUnit = nil
+		}
+
+		feedDetails.UpdatedAt = now
+
+		if err := tx.UpdateFeedDetails(ctx, feedDetails); err != nil {
+			return nil, fmt.Errorf("failed to update feed details: %w", err)
+		}
+
+		return &model.FeedActivity{
+			ID:           activity.ID.String(),
+			BabyID:       activity.BabyID.String(),
+			ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
+			CreatedAt:    activity.CreatedAt,
+			Version:      int32(activity.Version),
+			FeedDetails:  mapper.FeedDetailsToGraphQL(feedDetails),
+		}, nil
+
+	case domain.ActivityTypeDiaper:
+		if input.DiaperDetails == nil {
+			return nil, fmt.Errorf("diaper activity requires diaperDetails")
+		}
+
+		diaperDetails, err := tx.GetDiaperDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diaper details: %w", err)
+		}
+
+		// Update fields
+		diaperDetails.ChangedAt = input.DiaperDetails.ChangedAt
+		diaperDetails.HadPoop = input.DiaperDetails.HadPoop
+		if input.DiaperDetails.HadPee != nil {
+			diaperDetails.HadPee = *input.DiaperDetails.HadPee
+		}
+		diaperDetails.UpdatedAt = now
+
+		if err := tx.UpdateDiaperDetails(ctx, diaperDetails); err != nil {
+			return nil, fmt.Errorf("failed to update diaper details: %w", err)
+		}
+
+		return &model.DiaperActivity{
+			ID:            activity.ID.String(),
+			BabyID:        activity.BabyID.String(),
+			ActivityType:  model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
+			CreatedAt:     activity.CreatedAt,
+			Version:       int32(activity.Version),
+			DiaperDetails: mapper.DiaperDetailsToGraphQL(diaperDetails),
+		}, nil
+
+	case domain.ActivityTypeSleep:
+		if input.SleepDetails == nil {
+			return nil, fmt.Errorf("sleep activity requires sleepDetails")
+		}
+
+		sleepDetails, err := tx.GetSleepDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sleep details: %w", err)
+		}
+
+		// Update fields
+		sleepDetails.StartTime = input.SleepDetails.StartTime
+		sleepDetails.EndTime = input.SleepDetails.EndTime
+
+		if sleepDetails.EndTime != nil {
+			duration := int(sleepDetails.EndTime.Sub(sleepDetails.StartTime).Minutes())
+			sleepDetails.DurationMinutes = &duration
+		} else {
+			sleepDetails.DurationMinutes = nil
+		}
+
+		sleepDetails.UpdatedAt = now
+
+		if err := tx.UpdateSleepDetails(ctx, sleepDetails); err != nil {
+			return nil, fmt.Errorf("failed to update sleep details: %w", err)
+		}
+
+		return &model.SleepActivity{
+			ID:           activity.ID.String(),
+			BabyID:       activity.BabyID.String(),
+			ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
+			CreatedAt:    activity.CreatedAt,
+			Version:      int32(activity.Version),
+			SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
+		}, nil
+
+	case domain.ActivityTypePump:
+		if input.PumpDetails == nil {
+			return nil, fmt.Errorf("pump activity requires pumpDetails")
+		}
+
+		pumpDetails, err := tx.GetPumpDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pump details: %w", err)
+		}
+
+		// Update fields
+		pumpDetails.StartTime = input.PumpDetails.StartTime
+		pumpDetails.EndTime = input.PumpDetails.EndTime
+		pumpDetails.LeftMl = int32PtrToInt(input.PumpDetails.LeftMl)
+		pumpDetails.RightMl = int32PtrToInt(input.PumpDetails.RightMl)
+		pumpDetails.TotalMl = pumpTotalMl(pumpDetails.LeftMl, pumpDetails.RightMl, int32PtrToInt(input.PumpDetails.TotalMl))
+		pumpDetails.Notes = input.PumpDetails.Notes
+		pumpDetails.UpdatedAt = now
+
+		if err := tx.UpdatePumpDetails(ctx, pumpDetails); err != nil {
+			return nil, fmt.Errorf("failed to update pump details: %w", err)
+		}
+
+		return &model.PumpActivity{
+			ID:           activity.ID.String(),
+			BabyID:       activity.BabyID.String(),
+			ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
+			CreatedAt:    activity.CreatedAt,
+			Version:      int32(activity.Version),
+			PumpDetails:  mapper.PumpDetailsToGraphQL(pumpDetails),
+		}, nil
+
+	case domain.ActivityTypeMedication:
+		if input.MedicationDetails == nil {
+			return nil, fmt.Errorf("medication activity requires medicationDetails")
+		}
+
+		medicationDetails, err := tx.GetMedicationDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication details: %w", err)
+		}
+
+		// Re-link to the catalog in case the drug name was corrected
+		drugName := strings.TrimSpace(input.MedicationDetails.DrugName)
+		med, err := tx.GetMedicationByName(ctx, familyID, drugName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication: %w", err)
+		}
+		medicationDetails.MedicationID = nil
+		if med != nil {
+			medicationDetails.MedicationID = &med.ID
+		}
+
+		// Update fields
+		medicationDetails.DrugName = drugName
+		medicationDetails.DoseAmount = input.MedicationDetails.DoseAmount
+		medicationDetails.DoseUnit = domain.DoseUnit(strings.ToLower(string(input.MedicationDetails.DoseUnit)))
+		if input.MedicationDetails.Route != nil {
+			medicationDetails.Route = domain.MedicationRoute(strings.ToLower(string(*input.MedicationDetails.Route)))
+		}
+		medicationDetails.GivenAt = input.MedicationDetails.GivenAt
+		medicationDetails.UpdatedAt = now
+
+		if err := tx.UpdateMedicationDetails(ctx, medicationDetails); err != nil {
+			return nil, fmt.Errorf("failed to update medication details: %w", err)
+		}
+
+		return &model.MedicationActivity{
+			ID:                activity.ID.String(),
+			BabyID:            activity.BabyID.String(),
+			ActivityType:      model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
+			CreatedAt:         activity.CreatedAt,
+			Version:           int32(activity.Version),
+			MedicationDetails: mapper.MedicationDetailsToGraphQL(medicationDetails),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown activity type: %s", activity.ActivityType)
+	}
+}
+
+// activityBelongsToFamily reports whether an activity's baby is in the family.
+func activityBelongsToFamily(ctx context.Context, tx store.Store, activity *domain.Activity, familyID uuid.UUID) (bool, error) {
+	baby, err := tx.GetBabyByID(ctx, activity.BabyID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get baby: %w", err)
+	}
+	return baby.FamilyID == familyID, nil
+}
+
+// familyActivity loads an activity for the family, treating one belonging to another
+// family as not found.
+func (r *Resolver) familyActivity(ctx context.Context, familyID, id uuid.UUID) (*domain.Activity, error) {
+	activity, err := r.store.GetActivityByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	belongs, err := activityBelongsToFamily(ctx, r.store, activity, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if !belongs {
+		return nil, fmt.Errorf("activity not found")
+	}
+	return activity, nil
+}
+
+// clientActivityID returns the client-generated ID from input, or a new ID if none was sent.
+func clientActivityID(input *model.ActivityInput) (uuid.UUID, error) {
+	if input.ID == nil {
+		return uuid.New(), nil
+	}
+	id, err := uuid.Parse(*input.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid activity ID: %w", err)
+	}
+	return id, nil
+}
+
+// versionConflictError turns a *store.VersionConflictError into a VERSION_CONFLICT GraphQL
+// error carrying the server's current version of the activity, so the client can show the
+// other edit before retrying. The activity is only included if it is in the caller's
+// family. Other errors are returned unchanged.
+func (r *Resolver) versionConflictError(ctx context.Context, familyID uuid.UUID, err error) error {
+	var conflict *store.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+
+	activity, getErr := r.familyActivity(ctx, familyID, conflict.ID)
+	if getErr != nil {
+		return err
+	}
+	current, loadErr := r.loadActivity(ctx, activity)
+	if loadErr != nil {
+		return err
+	}
+
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("activity was changed by someone else (now at version %d); review their edit and try again", conflict.Version),
+		Extensions: map[string]interface{}{
+			"code":           ErrCodeVersionConflict,
+			"currentVersion": conflict.Version,
+			"serverActivity": current,
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// audit records a change made by the signed-in caregiver in their family's audit log. Call
+// it with the change's transaction so the change and its record commit together.
+//
+// before and after are the entity as the API returns it (the mapper's GraphQL models), so
+// snapshots never carry password hashes, invite code hashes or webhook secrets. Pass an
+// untyped nil for the side on which the entity doesn't exist.
+func audit(ctx context.Context, tx store.Store, action domain.AuditAction, entityType domain.AuditEntityType, entityID uuid.UUID, before, after any) error {
+	caregiverID, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("authentication required: %w", err)
+	}
+
+	actor := &domain.Caregiver{ID: caregiverID, FamilyID: familyID, Name: middleware.GetCaregiverName(ctx)}
+	return auditAs(ctx, tx, actor, action, entityType, entityID, before, after)
+}
+
+// auditAs is audit for changes made before the caller is signed in to the family, such as
+// creating or joining it, where actor is the caregiver the change creates.
+func auditAs(ctx context.Context, tx store.Store, actor *domain.Caregiver, action domain.AuditAction, entityType domain.AuditEntityType, entityID uuid.UUID, before, after any) error {
+	beforeJSON, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	err = tx.CreateAuditEvent(ctx, &domain.AuditEvent{
+		ID:               uuid.New(),
+		FamilyID:         actor.FamilyID,
+		ActorCaregiverID: actor.ID,
+		ActorName:        actor.Name,
+		Action:           action,
+		EntityType:       entityType,
+		EntityID:         entityID,
+		Before:           beforeJSON,
+		After:            afterJSON,
+		CreatedAt:        time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+func auditSnapshot(v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %T for the audit log: %w", v, err)
+	}
+	s := string(data)
+	return &s, nil
+}
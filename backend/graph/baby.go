@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+)
+
+// resolveBaby returns the baby a request targets. An explicit babyID must belong to the
+// family; without one, a family with a single baby defaults to it so that clients
+// that predate multi-baby support keep working.
+func (r *Resolver) resolveBaby(ctx context.Context, familyID uuid.UUID, babyID *string) (*domain.Baby, error) {
+	if babyID != nil {
+		id, err := uuid.Parse(*babyID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid baby ID: %w", err)
+		}
+
+		baby, err := r.store.GetBabyByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("baby not found: %w", err)
+		}
+		if baby.FamilyID != familyID {
+			return nil, fmt.Errorf("baby does not belong to your family")
+		}
+		return baby, nil
+	}
+
+	babies, err := r.store.GetBabiesForFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get babies: %w", err)
+	}
+
+	switch len(babies) {
+	case 0:
+		return nil, fmt.Errorf("family has no babies")
+	case 1:
+		return babies[0], nil
+	default:
+		return nil, fmt.Errorf("babyId is required when the family has more than one baby")
+	}
+}
+
+// babiesForFamily returns the family's babies as GraphQL models, oldest record first.
+func (r *Resolver) babiesForFamily(ctx context.Context, familyID uuid.UUID) ([]*model.Baby, error) {
+	babies, err := r.store.GetBabiesForFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get babies: %w", err)
+	}
+
+	result := make([]*model.Baby, len(babies))
+	for i, baby := range babies {
+		result[i] = mapper.BabyToGraphQL(baby)
+	}
+	return result, nil
+}
+
+// applyBabyProfile sets whichever of birth date and sex were provided. Birth dates are
+// stored as UTC calendar dates and cannot be in the future.
+func applyBabyProfile(baby *domain.Baby, birthDate *time.Time, sex *model.BabySex) error {
+	if birthDate != nil {
+		date := time.Date(birthDate.Year(), birthDate.Month(), birthDate.Day(), 0, 0, 0, 0, time.UTC)
+		if date.After(time.Now()) {
+			return fmt.Errorf("birth date cannot be in the future")
+		}
+		baby.BirthDate = &date
+	}
+	if sex != nil {
+		babySex := domain.BabySex(strings.ToLower(string(*sex)))
+		baby.Sex = &babySex
+	}
+	return nil
+}
+
+// findBabyByName matches a spoken baby name against the family's babies, ignoring case.
+func findBabyByName(babies []*domain.Baby, name string) *domain.Baby {
+	name = strings.TrimSpace(name)
+	for _, baby := range babies {
+		if strings.EqualFold(baby.Name, name) {
+			return baby
+		}
+	}
+	return nil
+}
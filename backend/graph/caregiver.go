@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// errLastOwner is returned when a change would leave a family with caregivers but no owner.
+var errLastOwner = errors.New("a family must keep at least one owner; make someone else an owner first")
+
+// caregiverRoleFromGraphQL converts a GraphQL role to a domain role
+func caregiverRoleFromGraphQL(role model.CaregiverRole) domain.CaregiverRole {
+	return domain.CaregiverRole(strings.ToLower(string(role)))
+}
+
+// familyCaregiver returns the caregiver with the given ID, failing unless they belong to
+// the family.
+func (r *Resolver) familyCaregiver(ctx context.Context, familyID uuid.UUID, id string) (*domain.Caregiver, error) {
+	caregiverID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid caregiver ID: %w", err)
+	}
+
+	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverID)
+	if err != nil || caregiver.FamilyID != familyID {
+		return nil, fmt.Errorf("caregiver not found")
+	}
+	return caregiver, nil
+}
+
+// keepOwner returns errLastOwner if taking away caregiverID's owner role, by demoting or
+// removing them, would leave their family's other caregivers without an owner. Run it in
+// the same transaction as the change so it sees the current roles.
+func keepOwner(ctx context.Context, tx store.Store, familyID, caregiverID uuid.UUID) error {
+	caregivers, err := tx.GetCaregiversByFamily(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get caregivers: %w", err)
+	}
+
+	var isOwner, hasOthers bool
+	for _, c := range caregivers {
+		if c.ID == caregiverID {
+			isOwner = c.Role == domain.RoleOwner
+			continue
+		}
+		if c.Role == domain.RoleOwner {
+			return nil
+		}
+		hasOthers = true
+	}
+
+	if isOwner && hasOthers {
+		return errLastOwner
+	}
+	return nil
+}
+
+// removeCaregiver deletes a caregiver from their family, unless they are its last owner.
+// Deleting the caregiver cascades to their sessions and activities, so the babies' cached
+// predictions go stale in the same transaction.
+func (r *Resolver) removeCaregiver(ctx context.Context, caregiver *domain.Caregiver) error {
+	familyID := caregiver.FamilyID
+	babies, err := r.store.GetBabiesForFamily(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get babies: %w", err)
+	}
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := keepOwner(ctx, tx, familyID, caregiver.ID); err != nil {
+			return err
+		}
+		if err := tx.DeleteCaregiver(ctx, caregiver.ID); err != nil {
+			return fmt.Errorf("failed to remove caregiver: %w", err)
+		}
+		if err := audit(ctx, tx, domain.AuditActionDelete, domain.AuditEntityCaregiver, caregiver.ID, mapper.CaregiverToGraphQL(caregiver), nil); err != nil {
+			return err
+		}
+		for _, baby := range babies {
+			if err := tx.DeletePredictionsForBaby(ctx, baby.ID); err != nil {
+				return fmt.Errorf("failed to invalidate predictions: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, baby := range babies {
+		r.publishPredictionsChanged(familyID, baby.ID)
+	}
+	return nil
+}
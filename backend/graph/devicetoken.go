@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swatkatz/babybaton/backend/internal/devicetoken"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// issueDeviceToken returns a signed device token for caregiver, or nil if no signer is
+// configured. rotate first advances the caregiver's token generation, signing out
+// whichever device held the previous token; new caregivers start at generation 0 and
+// don't need it.
+func (r *Resolver) issueDeviceToken(ctx context.Context, caregiver *domain.Caregiver, rotate bool) (*string, error) {
+	if r.deviceTokens == nil {
+		return nil, nil
+	}
+
+	generation := caregiver.TokenGeneration
+	if rotate {
+		var err error
+		generation, err = r.store.RotateDeviceToken(ctx, caregiver.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rotate device token: %w", err)
+		}
+	}
+
+	token := r.deviceTokens.Issue(devicetoken.Claims{
+		CaregiverID: caregiver.ID,
+		FamilyID:    caregiver.FamilyID,
+		Generation:  generation,
+	})
+	return &token, nil
+}
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/pubsub"
+)
+
+// publishCareSessionUpdated notifies subscribers that a session's status or activities changed.
+func (r *Resolver) publishCareSessionUpdated(familyID, sessionID uuid.UUID) {
+	r.events.Publish(pubsub.Event{
+		Type:          pubsub.EventCareSessionUpdated,
+		FamilyID:      familyID,
+		CareSessionID: sessionID,
+	})
+}
+
+// publishActivityAdded notifies subscribers that a new activity was logged.
+func (r *Resolver) publishActivityAdded(familyID, sessionID, activityID uuid.UUID) {
+	r.events.Publish(pubsub.Event{
+		Type:          pubsub.EventActivityAdded,
+		FamilyID:      familyID,
+		CareSessionID: sessionID,
+		ActivityID:    activityID,
+	})
+}
+
+// publishPredictionsChanged notifies subscribers that a baby's predictions were
+// invalidated. Subscribers regenerate them when they handle the event.
+func (r *Resolver) publishPredictionsChanged(familyID, babyID uuid.UUID) {
+	r.events.Publish(pubsub.Event{
+		Type:     pubsub.EventPredictionsChanged,
+		FamilyID: familyID,
+		BabyID:   babyID,
+	})
+}
+
+// errSkipEvent is returned by a subscribe load func to drop an event that doesn't concern
+// the subscriber, without logging it as a failure.
+var errSkipEvent = errors.New("event skipped")
+
+// subscribe streams events of one type for a family, converting each into a GraphQL
+// payload with load. Events that fail to load are logged and skipped. The returned
+// channel is closed when ctx is cancelled (i.e. the client unsubscribes or disconnects).
+func subscribe[T any](ctx context.Context, broker *pubsub.Broker, familyID uuid.UUID, eventType pubsub.EventType, load func(pubsub.Event) (T, error)) <-chan T {
+	events, cancel := broker.Subscribe(familyID)
+	out := make(chan T, 1)
+
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event.Type != eventType {
+					continue
+				}
+				payload, err := load(event)
+				if errors.Is(err, errSkipEvent) {
+					continue
+				}
+				if err != nil {
+					log.Printf("subscription: failed to load %s event for family %s: %v", event.Type, familyID, err)
+					continue
+				}
+				select {
+				case out <- payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
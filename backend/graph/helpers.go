@@ -2,27 +2,40 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/graph/model"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
 	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// Sentinel errors returned from WithTx callbacks so resolvers can map them to
+// user-facing AuthResult messages once the transaction has rolled back.
+var (
+	errFamilyNameTaken     = errors.New("family name already taken")
+	errDeviceInOtherFamily = errors.New("device already belongs to a different family")
+	errInviteInactive      = errors.New("invite is no longer active")
 )
 
 // loadCareSessionWithActivities loads all activities and details for a care session.
 // Extracted from schema.resolvers.go so gqlgen doesn't move it to the "unknown code" section.
-func (r *queryResolver) loadCareSessionWithActivities(ctx context.Context, session *domain.CareSession) (*model.CareSession, error) {
-	// Get all activities for the session
-	activities, err := r.store.GetActivitiesForSession(ctx, session.ID)
+func (r *Resolver) loadCareSessionWithActivities(ctx context.Context, session *domain.CareSession) (*model.CareSession, error) {
+	sessions, err := r.loadCareSessions(ctx, []*domain.CareSession{session})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activities: %w", err)
+		return nil, err
 	}
+	return sessions[0], nil
+}
 
-	fmt.Printf("📋 Loading %d activities for session %s\n", len(activities), session.ID)
-
-	// Load details for each activity and convert to GraphQL types
+// careSessionToGraphQL builds a session's GraphQL response, with its summary, from its
+// activities and their loaded details.
+func careSessionToGraphQL(session *domain.CareSession, caregiver *domain.Caregiver, activities []*domain.Activity, details *activityDetails) (*model.CareSession, error) {
 	graphQLActivities := make([]model.Activity, 0, len(activities))
 
 	// Variables for summary calculation
@@ -31,24 +44,16 @@ func (r *queryResolver) loadCareSessionWithActivities(ctx context.Context, sessi
 	var currentlyAsleep bool
 
 	for _, activity := range activities {
-		fmt.Printf("   🔍 Processing activity %s (type: %s)\n", activity.ID, activity.ActivityType)
+		graphQLActivity, err := details.toGraphQL(activity)
+		if err != nil {
+			return nil, err
+		}
+		graphQLActivities = append(graphQLActivities, graphQLActivity)
+
+		// Update summary
 		switch activity.ActivityType {
 		case domain.ActivityTypeFeed:
-			fmt.Printf("   📥 Loading feed details for activity %s\n", activity.ID)
-			feedDetails, err := r.store.GetFeedDetails(ctx, activity.ID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get feed details: %w", err)
-			}
-			fmt.Printf("   ✅ Feed details loaded successfully\n")
-
-			graphQLActivities = append(graphQLActivities, &model.FeedActivity{
-				ID:           activity.ID.String(),
-				ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
-				CreatedAt:    activity.CreatedAt,
-				FeedDetails:  mapper.FeedDetailsToGraphQL(feedDetails),
-			})
-
-			// Update summary
+			feedDetails := details.feeds[activity.ID]
 			totalFeeds++
 			if feedDetails.AmountMl != nil {
 				totalMl += int32(*feedDetails.AmountMl)
@@ -59,35 +64,10 @@ func (r *queryResolver) loadCareSessionWithActivities(ctx context.Context, sessi
 			}
 
 		case domain.ActivityTypeDiaper:
-			diaperDetails, err := r.store.GetDiaperDetails(ctx, activity.ID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get diaper details: %w", err)
-			}
-
-			graphQLActivities = append(graphQLActivities, &model.DiaperActivity{
-				ID:            activity.ID.String(),
-				ActivityType:  model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
-				CreatedAt:     activity.CreatedAt,
-				DiaperDetails: mapper.DiaperDetailsToGraphQL(diaperDetails),
-			})
-
-			// Update summary
 			totalDiaperChanges++
 
 		case domain.ActivityTypeSleep:
-			sleepDetails, err := r.store.GetSleepDetails(ctx, activity.ID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get sleep details: %w", err)
-			}
-
-			graphQLActivities = append(graphQLActivities, &model.SleepActivity{
-				ID:           activity.ID.String(),
-				ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
-				CreatedAt:    activity.CreatedAt,
-				SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
-			})
-
-			// Update summary
+			sleepDetails := details.sleeps[activity.ID]
 			sleepTime := sleepDetails.StartTime
 			if lastSleepTime == nil || sleepTime.After(*lastSleepTime) {
 				lastSleepTime = &sleepTime
@@ -101,12 +81,6 @@ func (r *queryResolver) loadCareSessionWithActivities(ctx context.Context, sessi
 		}
 	}
 
-	// Get caregiver
-	caregiver, err := r.store.GetCaregiverByID(ctx, session.CaregiverID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get caregiver: %w", err)
-	}
-
 	// Build GraphQL response
 	return &model.CareSession{
 		ID:          session.ID.String(),
@@ -128,3 +102,138 @@ func (r *queryResolver) loadCareSessionWithActivities(ctx context.Context, sessi
 	}, nil
 }
 
+// loadActivity loads the details for a single activity and converts it to its GraphQL union member.
+func (r *Resolver) loadActivity(ctx context.Context, activity *domain.Activity) (model.Activity, error) {
+	return loadActivityFrom(ctx, r.store, activity)
+}
+
+// loadActivityFrom is loadActivity reading from s, so it can be used inside a transaction.
+func loadActivityFrom(ctx context.Context, s store.Store, activity *domain.Activity) (model.Activity, error) {
+	details := newActivityDetails()
+
+	switch activity.ActivityType {
+	case domain.ActivityTypeFeed:
+		feedDetails, err := s.GetFeedDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feed details: %w", err)
+		}
+		details.feeds[activity.ID] = feedDetails
+
+	case domain.ActivityTypeDiaper:
+		diaperDetails, err := s.GetDiaperDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diaper details: %w", err)
+		}
+		details.diapers[activity.ID] = diaperDetails
+
+	case domain.ActivityTypeSleep:
+		sleepDetails, err := s.GetSleepDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sleep details: %w", err)
+		}
+		details.sleeps[activity.ID] = sleepDetails
+
+	case domain.ActivityTypePump:
+		pumpDetails, err := s.GetPumpDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pump details: %w", err)
+		}
+		details.pumps[activity.ID] = pumpDetails
+
+	case domain.ActivityTypeMedication:
+		medicationDetails, err := s.GetMedicationDetails(ctx, activity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication details: %w", err)
+		}
+		details.medications[activity.ID] = medicationDetails
+	}
+
+	return details.toGraphQL(activity)
+}
+
+// defaultAccuracyDays is how many days predictionAccuracy covers by default
+const defaultAccuracyDays = 14
+
+// defaultPatternDays is how many days detectedPatterns covers by default
+const defaultPatternDays = 7
+
+// maxPredictionHalfLifeHours caps setPredictionModel's half-life at a week; longer ones
+// weigh history about as evenly as the median
+const maxPredictionHalfLifeHours = 7 * 24
+
+// predictionsForBaby returns a baby's prediction timeline, reusing predictions computed
+// within the last minute and otherwise regenerating and persisting them.
+func (r *Resolver) predictionsForBaby(ctx context.Context, familyID, babyID uuid.UUID) ([]*model.Prediction, error) {
+	now := time.Now()
+
+	// Cleanup old predictions
+	_ = r.store.CleanupOldPredictions(ctx, now.Add(-24*time.Hour))
+
+	// Check if existing predictions are fresh (computed within last 1 minute)
+	existing, err := r.store.GetPredictionsForBaby(ctx, babyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get predictions: %w", err)
+	}
+	if len(existing) > 0 && now.Sub(existing[0].ComputedAt) < 1*time.Minute {
+		return predictionsToGraphQL(existing), nil
+	}
+
+	timezone, err := r.familyTimezone(ctx, familyID, now)
+	if err != nil {
+		return nil, err
+	}
+	predictions, err := prediction.Refresh(ctx, r.store, familyID, babyID, now, timezone)
+	if err != nil {
+		return nil, err
+	}
+	return predictionsToGraphQL(predictions), nil
+}
+
+// predictionsToGraphQL maps a prediction timeline to GraphQL
+func predictionsToGraphQL(predictions []*domain.Prediction) []*model.Prediction {
+	result := make([]*model.Prediction, 0, len(predictions))
+	for _, dp := range predictions {
+		result = append(result, mapper.PredictionToGraphQL(dp))
+	}
+	return result
+}
+
+// int32PtrToInt converts an optional GraphQL Int to an optional domain int.
+func int32PtrToInt(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
+
+// pumpTotalMl returns the explicit total if given, otherwise the sum of whichever sides were recorded.
+func pumpTotalMl(leftMl, rightMl, totalMl *int) *int {
+	if totalMl != nil {
+		return totalMl
+	}
+	if leftMl == nil && rightMl == nil {
+		return nil
+	}
+	sum := 0
+	if leftMl != nil {
+		sum += *leftMl
+	}
+	if rightMl != nil {
+		sum += *rightMl
+	}
+	return &sum
+}
+
+// uniqueIDs returns the distinct IDs in first-seen order.
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// addCaregiverToFamily signs the caller in to family, creating a caregiver with role for their
+// user or device unless they already have one there, in which case they keep their role.
+// admit, if set, runs in the same transaction just before a new caregiver is created, so an
+// invite is only used up by someone new.
+func (r *Resolver) addCaregiverToFamily(ctx context.Context, family *domain.Family, caregiverName string, role domain.CaregiverRole, deviceID, deviceName *string, admit func(tx store.Store) error) *model.AuthResult {
+	// Determine auth path: JWT user-based or legacy device-based
+	userID, hasUser := middleware.GetUserID(ctx)
+
+	if !hasUser && (deviceID == nil || *deviceID == "") {
+		return &model.AuthResult{
+			Success: false,
+			Error:   stringPtr("deviceId is required for device-based authentication"),
+		}
+	}
+
+	// Look up and create the caregiver in one transaction
+	var caregiver *domain.Caregiver
+	var reauth bool
+	err := r.store.WithTx(ctx, func(tx store.Store) error {
+		if hasUser {
+			// Check if user already has a caregiver in this family
+			existingCaregiver, err := tx.GetCaregiverByUserAndFamily(ctx, userID, family.ID)
+			if err == nil && existingCaregiver != nil {
+				// Re-authentication: user already in this family
+				caregiver = existingCaregiver
+				return nil
+			}
+		} else {
+			// Check if device already exists
+			existingCaregiver, err := tx.GetCaregiverByDeviceID(ctx, *deviceID)
+			if err == nil && existingCaregiver != nil {
+				// Device exists in DIFFERENT family → Block it
+				if existingCaregiver.FamilyID != family.ID {
+					return errDeviceInOtherFamily
+				}
+				// Device exists in THIS family → Re-authentication (allow it!)
+				caregiver = existingCaregiver
+				reauth = true
+				return nil
+			}
+		}
+
+		if admit != nil {
+			if err := admit(tx); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		caregiver = &domain.Caregiver{
+			ID:         uuid.New(),
+			FamilyID:   family.ID,
+			Name:       caregiverName,
+			DeviceName: deviceName,
+			Role:       role,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if hasUser {
+			// User-based auth: link caregiver to user, ignore deviceId
+			caregiver.UserID = &userID
+		} else {
+			caregiver.DeviceID = deviceID
+		}
+		if err := tx.CreateCaregiver(ctx, caregiver); err != nil {
+			return err
+		}
+		return auditAs(ctx, tx, caregiver, domain.AuditActionCreate, domain.AuditEntityCaregiver, caregiver.ID, nil, mapper.CaregiverToGraphQL(caregiver))
+	})
+	if errors.Is(err, errDeviceInOtherFamily) {
+		return &model.AuthResult{
+			Success: false,
+			Error:   stringPtr("Device already belongs to a different family. Leave that family first."),
+		}
+	}
+	if errors.Is(err, errInviteInactive) {
+		return &model.AuthResult{
+			Success: false,
+			Error:   stringPtr("Invite is invalid or has expired"),
+		}
+	}
+	if err != nil {
+		return &model.AuthResult{
+			Success: false,
+			Error:   stringPtr(fmt.Sprintf("Failed to join family: %v", err)),
+		}
+	}
+
+	// Device-based caregivers get a fresh token; re-joining signs out the old one
+	var deviceToken *string
+	if !hasUser {
+		deviceToken, err = r.issueDeviceToken(ctx, caregiver, reauth)
+		if err != nil {
+			return &model.AuthResult{
+				Success: false,
+				Error:   stringPtr(fmt.Sprintf("Failed to join family: %v", err)),
+			}
+		}
+	}
+
+	return &model.AuthResult{
+		Success:     true,
+		Family:      mapper.FamilyToGraphQL(family),
+		Caregiver:   mapper.CaregiverToGraphQL(caregiver),
+		Error:       nil,
+		DeviceToken: deviceToken,
+	}
+}
+
+// redeemInvite returns an admit func for addCaregiverToFamily that uses up one of the
+// invite's uses, failing with errInviteInactive if none are left.
+func redeemInvite(ctx context.Context, familyInvite *domain.FamilyInvite) func(tx store.Store) error {
+	return func(tx store.Store) error {
+		redeemed, err := tx.RedeemFamilyInvite(ctx, familyInvite.ID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to redeem invite: %w", err)
+		}
+		if !redeemed {
+			return errInviteInactive
+		}
+		return nil
+	}
+}
+
+// inviteLink returns the link for an invite code, or nil if no link base is configured.
+func (r *Resolver) inviteLink(code string) *string {
+	if r.inviteLinkBase == "" {
+		return nil
+	}
+
+	link, err := url.Parse(r.inviteLinkBase)
+	if err != nil {
+		return nil
+	}
+	query := link.Query()
+	query.Set("code", code)
+	link.RawQuery = query.Encode()
+
+	s := link.String()
+	return &s
+}
@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/dataloader"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// loaders returns the dataloaders to read through. Queries share the request's loaders, so
+// sessions and caregivers requested by several fields are loaded once. Mutations and
+// subscriptions get fresh loaders every call: a mutation has to see its own writes, and a
+// subscription's context lives as long as its websocket, so a cache there would go stale.
+func (r *Resolver) loaders(ctx context.Context) *dataloader.Loaders {
+	if graphql.HasOperationContext(ctx) {
+		op := graphql.GetOperationContext(ctx).Operation
+		if l, ok := dataloader.For(ctx); ok && op != nil && op.Operation == ast.Query {
+			return l
+		}
+	}
+	return dataloader.NewLoaders(r.store)
+}
+
+// loadCareSessions loads sessions with their activities, details and caregivers in one
+// batch per kind of row, however many sessions there are.
+func (r *Resolver) loadCareSessions(ctx context.Context, sessions []*domain.CareSession) ([]*model.CareSession, error) {
+	l := r.loaders(ctx)
+
+	sessionIDs := make([]uuid.UUID, len(sessions))
+	caregiverIDs := make([]uuid.UUID, len(sessions))
+	for i, session := range sessions {
+		sessionIDs[i] = session.ID
+		caregiverIDs[i] = session.CaregiverID
+	}
+
+	activitiesBySession, err := l.ActivitiesBySession.LoadMany(ctx, sessionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activities: %w", err)
+	}
+	var activities []*domain.Activity
+	for _, sessionActivities := range activitiesBySession {
+		activities = append(activities, sessionActivities...)
+	}
+
+	details, err := loadActivityDetails(ctx, l, activities)
+	if err != nil {
+		return nil, err
+	}
+
+	caregivers, err := l.Caregivers.LoadMany(ctx, caregiverIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caregiver: %w", err)
+	}
+
+	result := make([]*model.CareSession, len(sessions))
+	for i, session := range sessions {
+		if caregivers[i] == nil {
+			return nil, fmt.Errorf("failed to get caregiver: caregiver not found: %s", session.CaregiverID)
+		}
+		result[i], err = careSessionToGraphQL(session, caregivers[i], activitiesBySession[i], details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session %s: %w", session.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// loadActivities converts activities to their GraphQL union members, loading their
+// details in one batch per activity type.
+func (r *Resolver) loadActivities(ctx context.Context, activities []*domain.Activity) ([]model.Activity, error) {
+	details, err := loadActivityDetails(ctx, r.loaders(ctx), activities)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Activity, len(activities))
+	for i, activity := range activities {
+		if result[i], err = details.toGraphQL(activity); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// activityDetails holds the details loaded for a set of activities, keyed by activity ID
+type activityDetails struct {
+	feeds       map[uuid.UUID]*domain.FeedDetails
+	diapers     map[uuid.UUID]*domain.DiaperDetails
+	sleeps      map[uuid.UUID]*domain.SleepDetails
+	pumps       map[uuid.UUID]*domain.PumpDetails
+	medications map[uuid.UUID]*domain.MedicationDetails
+}
+
+func newActivityDetails() *activityDetails {
+	return &activityDetails{
+		feeds:       make(map[uuid.UUID]*domain.FeedDetails),
+		diapers:     make(map[uuid.UUID]*domain.DiaperDetails),
+		sleeps:      make(map[uuid.UUID]*domain.SleepDetails),
+		pumps:       make(map[uuid.UUID]*domain.PumpDetails),
+		medications: make(map[uuid.UUID]*domain.MedicationDetails),
+	}
+}
+
+// loadActivityDetails loads the details of activities through l, one batch per type
+func loadActivityDetails(ctx context.Context, l *dataloader.Loaders, activities []*domain.Activity) (*activityDetails, error) {
+	idsByType := make(map[domain.ActivityType][]uuid.UUID)
+	for _, activity := range activities {
+		idsByType[activity.ActivityType] = append(idsByType[activity.ActivityType], activity.ID)
+	}
+
+	details := newActivityDetails()
+	if err := loadInto(ctx, l.FeedDetails, idsByType[domain.ActivityTypeFeed], details.feeds); err != nil {
+		return nil, fmt.Errorf("failed to get feed details: %w", err)
+	}
+	if err := loadInto(ctx, l.DiaperDetails, idsByType[domain.ActivityTypeDiaper], details.diapers); err != nil {
+		return nil, fmt.Errorf("failed to get diaper details: %w", err)
+	}
+	if err := loadInto(ctx, l.SleepDetails, idsByType[domain.ActivityTypeSleep], details.sleeps); err != nil {
+		return nil, fmt.Errorf("failed to get sleep details: %w", err)
+	}
+	if err := loadInto(ctx, l.PumpDetails, idsByType[domain.ActivityTypePump], details.pumps); err != nil {
+		return nil, fmt.Errorf("failed to get pump details: %w", err)
+	}
+	if err := loadInto(ctx, l.MedicationDetails, idsByType[domain.ActivityTypeMedication], details.medications); err != nil {
+		return nil, fmt.Errorf("failed to get medication details: %w", err)
+	}
+	return details, nil
+}
+
+// loadInto loads ids through loader into dst, skipping the round trip when there are none
+func loadInto[V any](ctx context.Context, loader *dataloader.Loader[uuid.UUID, *V], ids []uuid.UUID, dst map[uuid.UUID]*V) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	values, err := loader.LoadMany(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if values[i] != nil {
+			dst[id] = values[i]
+		}
+	}
+	return nil
+}
+
+// toGraphQL converts an activity to its GraphQL union member using its loaded details
+func (d *activityDetails) toGraphQL(activity *domain.Activity) (model.Activity, error) {
+	activityType := model.ActivityType(strings.ToUpper(string(activity.ActivityType)))
+
+	switch activity.ActivityType {
+	case domain.ActivityTypeFeed:
+		feedDetails, ok := d.feeds[activity.ID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get feed details: feed details not found for activity: %s", activity.ID)
+		}
+		return &model.FeedActivity{
+			ID:           activity.ID.String(),
+			BabyID:       activity.BabyID.String(),
+			ActivityType: activityType,
+			CreatedAt:    activity.CreatedAt,
+			Version:      int32(activity.Version),
+			FeedDetails:  mapper.FeedDetailsToGraphQL(feedDetails),
+		}, nil
+
+	case domain.ActivityTypeDiaper:
+		diaperDetails, ok := d.diapers[activity.ID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get diaper details: diaper details not found for activity: %s", activity.ID)
+		}
+		return &model.DiaperActivity{
+			ID:            activity.ID.String(),
+			BabyID:        activity.BabyID.String(),
+			ActivityType:  activityType,
+			CreatedAt:     activity.CreatedAt,
+			Version:       int32(activity.Version),
+			DiaperDetails: mapper.DiaperDetailsToGraphQL(diaperDetails),
+		}, nil
+
+	case domain.ActivityTypeSleep:
+		sleepDetails, ok := d.sleeps[activity.ID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get sleep details: sleep details not found for activity: %s", activity.ID)
+		}
+		return &model.SleepActivity{
+			ID:           activity.ID.String(),
+			BabyID:       activity.BabyID.String(),
+			ActivityType: activityType,
+			CreatedAt:    activity.CreatedAt,
+			Version:      int32(activity.Version),
+			SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
+		}, nil
+
+	case domain.ActivityTypePump:
+		pumpDetails, ok := d.pumps[activity.ID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get pump details: pump details not found for activity: %s", activity.ID)
+		}
+		return &model.PumpActivity{
+			ID:           activity.ID.String(),
+			BabyID:       activity.BabyID.String(),
+			ActivityType: activityType,
+			CreatedAt:    activity.CreatedAt,
+			Version:      int32(activity.Version),
+			PumpDetails:  mapper.PumpDetailsToGraphQL(pumpDetails),
+		}, nil
+
+	case domain.ActivityTypeMedication:
+		medicationDetails, ok := d.medications[activity.ID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get medication details: medication details not found for activity: %s", activity.ID)
+		}
+		return &model.MedicationActivity{
+			ID:                activity.ID.String(),
+			BabyID:            activity.BabyID.String(),
+			ActivityType:      activityType,
+			CreatedAt:         activity.CreatedAt,
+			Version:           int32(activity.Version),
+			MedicationDetails: mapper.MedicationDetailsToGraphQL(medicationDetails),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown activity type: %s", activity.ActivityType)
+	}
+}
@@ -0,0 +1,186 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/medication"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrCodeDoseIntervalViolation is the GraphQL error extension code returned when a dose
+// would break a medication's minimum interval or max daily dose limit.
+const ErrCodeDoseIntervalViolation = "DOSE_INTERVAL_VIOLATION"
+
+// doseCheck is the safety-check outcome for one medication activity in an addActivities batch.
+type doseCheck struct {
+	medication *domain.Medication // catalog entry matched by drug name, nil if none
+	overridden bool               // dose violated a limit and the caregiver chose to log it anyway
+}
+
+// checkMedicationDoses validates every medication activity in the batch against the family's
+// catalog limits, counting earlier doses for the same baby in the same batch. babyIDs holds the
+// target baby of each activity. Results are keyed by batch index. It returns a
+// DOSE_INTERVAL_VIOLATION error for the first dose that breaks a limit without
+// overrideSafetyCheck set, before anything has been written.
+func (r *Resolver) checkMedicationDoses(ctx context.Context, familyID uuid.UUID, activities []*model.ActivityInput, babyIDs []uuid.UUID) (map[int]doseCheck, error) {
+	checks := make(map[int]doseCheck)
+
+	earliest := make(map[uuid.UUID]time.Time)
+	lookback := 24 * time.Hour
+	for i, input := range activities {
+		if input.ActivityType != model.ActivityTypeMedication || input.MedicationDetails == nil {
+			continue
+		}
+		med, err := r.store.GetMedicationByName(ctx, familyID, strings.TrimSpace(input.MedicationDetails.DrugName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication: %w", err)
+		}
+		checks[i] = doseCheck{medication: med}
+
+		babyID := babyIDs[i]
+		if t, ok := earliest[babyID]; !ok || input.MedicationDetails.GivenAt.Before(t) {
+			earliest[babyID] = input.MedicationDetails.GivenAt
+		}
+		if med != nil && med.MinIntervalMinutes != nil {
+			if interval := time.Duration(*med.MinIntervalMinutes) * time.Minute; interval > lookback {
+				lookback = interval
+			}
+		}
+	}
+	if len(checks) == 0 {
+		return checks, nil
+	}
+
+	doses := make(map[uuid.UUID][]*domain.MedicationDetails, len(earliest))
+	for babyID, t := range earliest {
+		recent, err := r.store.GetRecentMedicationDetailsForBaby(ctx, babyID, t.Add(-lookback))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recent medication doses: %w", err)
+		}
+		doses[babyID] = recent
+	}
+
+	for i, input := range activities {
+		check, ok := checks[i]
+		if !ok {
+			continue
+		}
+		details := input.MedicationDetails
+		babyID := babyIDs[i]
+
+		if check.medication != nil {
+			status := medication.Check(check.medication, doses[babyID], details.GivenAt)
+			if !status.CanGiveNow {
+				if details.OverrideSafetyCheck == nil || !*details.OverrideSafetyCheck {
+					return nil, doseIntervalViolationError(details.DrugName, status)
+				}
+				check.overridden = true
+				checks[i] = check
+				fmt.Printf("⚠️  Dose safety check overridden for %s (%s)\n", details.DrugName, status.Reason)
+			}
+		}
+
+		// Count this dose against later doses of the same drug for this baby in the batch
+		pending := &domain.MedicationDetails{DrugName: details.DrugName, GivenAt: details.GivenAt}
+		if check.medication != nil {
+			pending.MedicationID = &check.medication.ID
+		}
+		doses[babyID] = append(doses[babyID], pending)
+	}
+
+	return checks, nil
+}
+
+// doseIntervalViolationError builds the overridable GraphQL error for a blocked dose.
+func doseIntervalViolationError(drugName string, status medication.Status) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("%s cannot be given yet (%s); next dose allowed at %s. Set overrideSafetyCheck to log it anyway",
+			drugName, status.Reason, status.NextAllowedAt.Format(time.RFC3339)),
+		Extensions: map[string]interface{}{
+			"code":          ErrCodeDoseIntervalViolation,
+			"drugName":      drugName,
+			"nextAllowedAt": status.NextAllowedAt.Format(time.RFC3339),
+			"reason":        status.Reason,
+			"overridable":   true,
+		},
+	}
+}
+
+// medicationStatuses reports, for each catalog medication, the baby's last dose and when their next dose is allowed.
+func (r *Resolver) medicationStatuses(ctx context.Context, familyID, babyID uuid.UUID, now time.Time) ([]*model.MedicationStatus, error) {
+	medications, err := r.store.GetMedicationsForFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medications: %w", err)
+	}
+	if len(medications) == 0 {
+		return []*model.MedicationStatus{}, nil
+	}
+
+	lookback := 24 * time.Hour
+	for _, med := range medications {
+		if med.MinIntervalMinutes != nil {
+			if interval := time.Duration(*med.MinIntervalMinutes) * time.Minute; interval > lookback {
+				lookback = interval
+			}
+		}
+	}
+
+	recent, err := r.store.GetRecentMedicationDetailsForBaby(ctx, babyID, now.Add(-lookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent medication doses: %w", err)
+	}
+	latest, err := r.store.GetLatestMedicationDetailsForBaby(ctx, babyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest medication doses: %w", err)
+	}
+
+	result := make([]*model.MedicationStatus, 0, len(medications))
+	for _, med := range medications {
+		status := medication.Check(med, recent, now)
+
+		// The last dose may be older than the lookback window
+		lastDose := status.LastDose
+		if lastDose == nil {
+			for _, d := range latest {
+				if medication.Matches(med, d) && (lastDose == nil || d.GivenAt.After(lastDose.GivenAt)) {
+					lastDose = d
+				}
+			}
+		}
+
+		gql := &model.MedicationStatus{
+			Medication:       mapper.MedicationToGraphQL(med),
+			DosesLast24Hours: int32(status.DosesLast24h),
+			NextAllowedAt:    status.NextAllowedAt,
+			CanGiveNow:       status.CanGiveNow,
+		}
+		if status.Reason != "" {
+			reason := status.Reason
+			gql.Reason = &reason
+		}
+		if lastDose != nil {
+			activity, err := r.store.GetActivityByID(ctx, lastDose.ActivityID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get activity: %w", err)
+			}
+			gql.LastDose = &model.MedicationActivity{
+				ID:                activity.ID.String(),
+				BabyID:            activity.BabyID.String(),
+				ActivityType:      model.ActivityTypeMedication,
+				CreatedAt:         activity.CreatedAt,
+				Version:           int32(activity.Version),
+				MedicationDetails: mapper.MedicationDetailsToGraphQL(lastDose),
+			}
+		}
+		result = append(result, gql)
+	}
+
+	return result, nil
+}
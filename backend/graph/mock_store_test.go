@@ -3,10 +3,13 @@ package graph
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -29,16 +32,25 @@ type mockStore struct {
 	getCaregiverByDeviceIDErr     error
 	caregiverByUserAndFamily      *domain.Caregiver
 	getCaregiverByUserAndFamilyErr error
+	tokenGeneration               int
+	caregivers                    []*domain.Caregiver
+
+	// Babies (newMockStore seeds a single baby)
+	babies []*domain.Baby
 
 	// Session history
 	careSessionHistory    []*domain.CareSession
 	careSessionHistoryErr error
 
+	// Activities
+	activityByID *domain.Activity
+
 	// Baby status
 	latestActivityByType map[domain.ActivityType]*domain.Activity
 	feedDetails          *domain.FeedDetails
 	diaperDetails        *domain.DiaperDetails
 	sleepDetails         *domain.SleepDetails
+	pumpDetails          *domain.PumpDetails
 
 	// Predictions
 	recentFeedDetails  []*domain.FeedDetails
@@ -48,20 +60,85 @@ type mockStore struct {
 	predictions        []*domain.Prediction
 	predictionsErr     error
 	upsertPredErr      error
+	predictionOutcomes []*domain.PredictionOutcome
+
+	// Medications
+	medications             []*domain.Medication
+	recentMedicationDetails []*domain.MedicationDetails
+	medicationDetails       *domain.MedicationDetails
+
+	// Growth
+	growthMeasurements []*domain.GrowthMeasurement
+
+	// Offline sync
+	idempotencyRecords     map[string]*domain.IdempotencyRecord
+	deletedActivities      map[uuid.UUID]*domain.DeletedActivity
+	activitiesUpdatedSince []*domain.Activity
+
+	// Trash
+	sessionActivities       []*domain.Activity
+	recentlyDeleted         []*domain.Activity
+	recentlyDeletedSessions []*domain.CareSession
+	deletedSessionIDs       []uuid.UUID
+	restoredSessionIDs      []uuid.UUID
+	restoredActivityIDs     []uuid.UUID
+
+	// Batch loads, one entry of IDs per call
+	activitiesForSessionsCalls [][]uuid.UUID
+	caregiversByIDsCalls       [][]uuid.UUID
+
+	// Reminder preferences
+	reminderPreferences map[uuid.UUID]*domain.ReminderPreferences
+
+	// Invites
+	invites []*domain.FamilyInvite
+
+	// Webhooks
+	webhookSubscriptions []*domain.WebhookSubscription
+	webhookDeliveries    []*domain.WebhookDelivery
+
+	// Audit log
+	auditEvents []*domain.AuditEvent
+
+	// Write failures
+	createFeedDetailsErr error
+	createCaregiverErr   error
+	deleteCaregiverErr   error
+	createAuditEventErr  error
 
 	// Tracking calls
 	lastCreatedCaregiver      *domain.Caregiver
 	linkCaregiverToUserCalled bool
 	deleteCaregiverCalled     bool
 	upsertedPredictions       []*domain.Prediction
+	upsertPredictionsCount    int
+	lastCreatedPumpDetails    *domain.PumpDetails
+	createdMedicationDetails  []*domain.MedicationDetails
+	updatedFamily             *domain.Family
+	lastCreatedBaby           *domain.Baby
+	updatedBaby               *domain.Baby
+	createdSessions           []*domain.CareSession
+	createdActivities         []*domain.Activity
+	deletedPredictionBabyIDs  []uuid.UUID
+	deletePredictionsErr      error
+	upsertedGoalsBabyID       uuid.UUID
+	txCount                   int
+	touchedActivityIDs        []uuid.UUID
+	activityVersion           int
+	deletedActivityIDs        []uuid.UUID
+	rolledBackTxCount         int
 }
 
 func newMockStore() *mockStore {
-	return &mockStore{}
+	return &mockStore{
+		family: &domain.Family{ID: uuid.New(), Name: "Family"},
+		babies: []*domain.Baby{{ID: uuid.New(), Name: "Baby"}},
+	}
 }
 
 // Family operations
-func (m *mockStore) CreateFamilyWithCaregiver(_ context.Context, _ *domain.Family, caregiver *domain.Caregiver) error {
+func (m *mockStore) CreateFamilyWithCaregiver(_ context.Context, _ *domain.Family, baby *domain.Baby, caregiver *domain.Caregiver) error {
+	m.lastCreatedBaby = baby
 	m.lastCreatedCaregiver = caregiver
 	return nil
 }
@@ -83,7 +160,10 @@ func (m *mockStore) GetFamilyByName(_ context.Context, _ string) (*domain.Family
 	return nil, errNotFound
 }
 
-func (m *mockStore) UpdateFamily(_ context.Context, _ *domain.Family) error { return nil }
+func (m *mockStore) UpdateFamily(_ context.Context, family *domain.Family) error {
+	m.updatedFamily = family
+	return nil
+}
 func (m *mockStore) DeleteFamily(_ context.Context, _ uuid.UUID) error      { return nil }
 
 func (m *mockStore) FamilyNameExists(_ context.Context, _ string) (bool, error) {
@@ -94,6 +174,84 @@ func (m *mockStore) GetFamiliesByUserID(_ context.Context, _ uuid.UUID) ([]*doma
 	return m.familiesByUser, nil
 }
 
+func (m *mockStore) GetActiveFamilyIDs(_ context.Context, _ time.Time) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+// Invite operations
+func (m *mockStore) CreateFamilyInvite(_ context.Context, invite *domain.FamilyInvite) error {
+	m.invites = append(m.invites, invite)
+	return nil
+}
+func (m *mockStore) GetFamilyInviteByID(_ context.Context, id uuid.UUID) (*domain.FamilyInvite, error) {
+	for _, invite := range m.invites {
+		if invite.ID == id {
+			return invite, nil
+		}
+	}
+	return nil, errNotFound
+}
+func (m *mockStore) GetFamilyInviteByCodeHash(_ context.Context, codeHash string) (*domain.FamilyInvite, error) {
+	for _, invite := range m.invites {
+		if invite.CodeHash == codeHash {
+			return invite, nil
+		}
+	}
+	return nil, errNotFound
+}
+func (m *mockStore) GetFamilyInvitesForFamily(_ context.Context, familyID uuid.UUID) ([]*domain.FamilyInvite, error) {
+	var result []*domain.FamilyInvite
+	for _, invite := range m.invites {
+		if invite.FamilyID == familyID {
+			result = append(result, invite)
+		}
+	}
+	return result, nil
+}
+func (m *mockStore) RedeemFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	invite, err := m.GetFamilyInviteByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !invite.Active(now) {
+		return false, nil
+	}
+	invite.UseCount++
+	return true, nil
+}
+func (m *mockStore) RevokeFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) error {
+	invite, err := m.GetFamilyInviteByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invite.RevokedAt == nil {
+		invite.RevokedAt = &now
+	}
+	return nil
+}
+
+// Baby operations
+func (m *mockStore) CreateBaby(_ context.Context, baby *domain.Baby) error {
+	m.lastCreatedBaby = baby
+	m.babies = append(m.babies, baby)
+	return nil
+}
+func (m *mockStore) GetBabyByID(_ context.Context, id uuid.UUID) (*domain.Baby, error) {
+	for _, baby := range m.babies {
+		if baby.ID == id {
+			return baby, nil
+		}
+	}
+	return nil, errNotFound
+}
+func (m *mockStore) GetBabiesForFamily(_ context.Context, _ uuid.UUID) ([]*domain.Baby, error) {
+	return m.babies, nil
+}
+func (m *mockStore) UpdateBaby(_ context.Context, baby *domain.Baby) error {
+	m.updatedBaby = baby
+	return nil
+}
+
 // User operations
 func (m *mockStore) CreateUser(_ context.Context, _ *domain.User) error { return nil }
 func (m *mockStore) GetUserBySupabaseID(_ context.Context, _ string) (*domain.User, error) {
@@ -105,10 +263,18 @@ func (m *mockStore) GetUserByID(_ context.Context, _ uuid.UUID) (*domain.User, e
 
 // Caregiver operations
 func (m *mockStore) CreateCaregiver(_ context.Context, caregiver *domain.Caregiver) error {
+	if m.createCaregiverErr != nil {
+		return m.createCaregiverErr
+	}
 	m.lastCreatedCaregiver = caregiver
 	return nil
 }
 
+func (m *mockStore) RotateDeviceToken(_ context.Context, _ uuid.UUID) (int, error) {
+	m.tokenGeneration++
+	return m.tokenGeneration, nil
+}
+
 func (m *mockStore) GetCaregiverByID(_ context.Context, _ uuid.UUID) (*domain.Caregiver, error) {
 	if m.caregiverByID != nil {
 		return m.caregiverByID, nil
@@ -119,6 +285,20 @@ func (m *mockStore) GetCaregiverByID(_ context.Context, _ uuid.UUID) (*domain.Ca
 	return nil, errNotFound
 }
 
+func (m *mockStore) GetCaregiversByIDs(_ context.Context, ids []uuid.UUID) ([]*domain.Caregiver, error) {
+	m.caregiversByIDsCalls = append(m.caregiversByIDsCalls, ids)
+	if m.caregiverByID == nil {
+		return nil, m.caregiverByIDErr
+	}
+	result := make([]*domain.Caregiver, len(ids))
+	for i, id := range ids {
+		c := *m.caregiverByID
+		c.ID = id
+		result[i] = &c
+	}
+	return result, nil
+}
+
 func (m *mockStore) GetCaregiverByDeviceID(_ context.Context, _ string) (*domain.Caregiver, error) {
 	if m.caregiverByDeviceID != nil {
 		return m.caregiverByDeviceID, nil
@@ -140,7 +320,17 @@ func (m *mockStore) GetCaregiverByUserAndFamily(_ context.Context, _ uuid.UUID,
 }
 
 func (m *mockStore) GetCaregiversByFamily(_ context.Context, _ uuid.UUID) ([]*domain.Caregiver, error) {
-	return nil, nil
+	return m.caregivers, nil
+}
+
+func (m *mockStore) SetCaregiverRole(_ context.Context, caregiverID uuid.UUID, role domain.CaregiverRole) error {
+	for _, c := range m.caregivers {
+		if c.ID == caregiverID {
+			c.Role = role
+			return nil
+		}
+	}
+	return fmt.Errorf("caregiver not found: %s", caregiverID)
 }
 
 func (m *mockStore) UpdateCaregiver(_ context.Context, _ *domain.Caregiver) error { return nil }
@@ -151,13 +341,24 @@ func (m *mockStore) LinkCaregiverToUser(_ context.Context, _ uuid.UUID, _ uuid.U
 }
 
 func (m *mockStore) DeleteCaregiver(_ context.Context, _ uuid.UUID) error {
+	if m.deleteCaregiverErr != nil {
+		return m.deleteCaregiverErr
+	}
 	m.deleteCaregiverCalled = true
 	return nil
 }
 
 // Care Session operations
-func (m *mockStore) CreateCareSession(_ context.Context, _ *domain.CareSession) error { return nil }
-func (m *mockStore) GetCareSessionByID(_ context.Context, _ uuid.UUID) (*domain.CareSession, error) {
+func (m *mockStore) CreateCareSession(_ context.Context, session *domain.CareSession) error {
+	m.createdSessions = append(m.createdSessions, session)
+	return nil
+}
+func (m *mockStore) GetCareSessionByID(_ context.Context, id uuid.UUID) (*domain.CareSession, error) {
+	for _, session := range m.createdSessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
 	return nil, errNotFound
 }
 func (m *mockStore) GetInProgressSessionForFamily(_ context.Context, _ uuid.UUID) (*domain.CareSession, error) {
@@ -173,17 +374,54 @@ func (m *mockStore) GetCareSessionHistoryForFamily(_ context.Context, _ uuid.UUI
 	return m.careSessionHistory, nil
 }
 func (m *mockStore) UpdateCareSession(_ context.Context, _ *domain.CareSession) error { return nil }
-func (m *mockStore) DeleteCareSession(_ context.Context, _ uuid.UUID) error            { return nil }
+func (m *mockStore) DeleteCareSession(_ context.Context, id uuid.UUID) error {
+	m.deletedSessionIDs = append(m.deletedSessionIDs, id)
+	return nil
+}
+func (m *mockStore) RestoreCareSession(_ context.Context, id uuid.UUID) error {
+	m.restoredSessionIDs = append(m.restoredSessionIDs, id)
+	return nil
+}
+func (m *mockStore) GetRecentlyDeletedCareSessionsForFamily(_ context.Context, _ uuid.UUID, since time.Time) ([]*domain.CareSession, error) {
+	var result []*domain.CareSession
+	for _, session := range m.recentlyDeletedSessions {
+		if session.DeletedAt.After(since) {
+			result = append(result, session)
+		}
+	}
+	return result, nil
+}
 
 // Activity operations
-func (m *mockStore) CreateActivity(_ context.Context, _ *domain.Activity) error { return nil }
-func (m *mockStore) GetActivityByID(_ context.Context, _ uuid.UUID) (*domain.Activity, error) {
+func (m *mockStore) CreateActivity(_ context.Context, activity *domain.Activity) error {
+	m.createdActivities = append(m.createdActivities, activity)
+	return nil
+}
+func (m *mockStore) GetActivityByID(_ context.Context, id uuid.UUID) (*domain.Activity, error) {
+	for _, activity := range m.createdActivities {
+		if activity.ID == id {
+			return activity, nil
+		}
+	}
+	if m.activityByID != nil {
+		return m.activityByID, nil
+	}
 	return nil, errNotFound
 }
 func (m *mockStore) GetActivitiesForSession(_ context.Context, _ uuid.UUID) ([]*domain.Activity, error) {
-	return nil, nil
+	return m.sessionActivities, nil
+}
+func (m *mockStore) GetActivitiesForSessions(_ context.Context, sessionIDs []uuid.UUID) ([]*domain.Activity, error) {
+	m.activitiesForSessionsCalls = append(m.activitiesForSessionsCalls, sessionIDs)
+	var result []*domain.Activity
+	for _, a := range m.sessionActivities {
+		if slices.Contains(sessionIDs, a.CareSessionID) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
 }
-func (m *mockStore) GetLatestActivityByTypeForFamily(_ context.Context, _ uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
+func (m *mockStore) GetLatestActivityByTypeForBaby(_ context.Context, _ uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
 	if m.latestActivityByType != nil {
 		if act, ok := m.latestActivityByType[activityType]; ok {
 			return act, nil
@@ -191,17 +429,64 @@ func (m *mockStore) GetLatestActivityByTypeForFamily(_ context.Context, _ uuid.U
 	}
 	return nil, nil
 }
-func (m *mockStore) DeleteActivity(_ context.Context, _ uuid.UUID) error { return nil }
+func (m *mockStore) DeleteActivity(_ context.Context, id uuid.UUID) error {
+	m.deletedActivityIDs = append(m.deletedActivityIDs, id)
+	return nil
+}
+func (m *mockStore) RestoreActivity(_ context.Context, id uuid.UUID) error {
+	m.restoredActivityIDs = append(m.restoredActivityIDs, id)
+	return nil
+}
+func (m *mockStore) GetRecentlyDeletedActivitiesForFamily(_ context.Context, _ uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	var result []*domain.Activity
+	for _, activity := range m.recentlyDeleted {
+		if activity.DeletedAt.After(since) {
+			result = append(result, activity)
+		}
+	}
+	return result, nil
+}
+func (m *mockStore) PurgeDeleted(_ context.Context, _ time.Time) error { return nil }
+func (m *mockStore) TouchActivity(_ context.Context, id uuid.UUID, expectedVersion *int) (int, error) {
+	if expectedVersion != nil && *expectedVersion != m.activityVersion {
+		return 0, &store.VersionConflictError{ID: id, Version: m.activityVersion}
+	}
+	m.touchedActivityIDs = append(m.touchedActivityIDs, id)
+	m.activityVersion++
+	return m.activityVersion, nil
+}
+func (m *mockStore) GetActivitiesUpdatedSinceForFamily(_ context.Context, _ uuid.UUID, _ time.Time) ([]*domain.Activity, error) {
+	return m.activitiesUpdatedSince, nil
+}
 
-// Activity detail operations
-func (m *mockStore) CreateFeedDetails(_ context.Context, _ *domain.FeedDetails) error { return nil }
+// Activity detail operations. Created details are read back by the Get methods, as
+// resolvers do when recording an activity in the audit log.
+func (m *mockStore) CreateFeedDetails(_ context.Context, details *domain.FeedDetails) error {
+	if m.createFeedDetailsErr != nil {
+		return m.createFeedDetailsErr
+	}
+	m.feedDetails = details
+	return nil
+}
 func (m *mockStore) GetFeedDetails(_ context.Context, _ uuid.UUID) (*domain.FeedDetails, error) {
 	if m.feedDetails != nil {
 		return m.feedDetails, nil
 	}
 	return nil, errNotFound
 }
-func (m *mockStore) GetRecentFeedDetailsForFamily(_ context.Context, _ uuid.UUID, _ int) ([]*domain.FeedDetails, error) {
+func (m *mockStore) GetFeedDetailsByActivityIDs(_ context.Context, activityIDs []uuid.UUID) ([]*domain.FeedDetails, error) {
+	if m.feedDetails == nil {
+		return nil, nil
+	}
+	result := make([]*domain.FeedDetails, len(activityIDs))
+	for i, id := range activityIDs {
+		d := *m.feedDetails
+		d.ActivityID = id
+		result[i] = &d
+	}
+	return result, nil
+}
+func (m *mockStore) GetRecentFeedDetailsForBaby(_ context.Context, _ uuid.UUID, _ int) ([]*domain.FeedDetails, error) {
 	if m.recentFeedErr != nil {
 		return nil, m.recentFeedErr
 	}
@@ -209,7 +494,8 @@ func (m *mockStore) GetRecentFeedDetailsForFamily(_ context.Context, _ uuid.UUID
 }
 func (m *mockStore) UpdateFeedDetails(_ context.Context, _ *domain.FeedDetails) error { return nil }
 
-func (m *mockStore) CreateDiaperDetails(_ context.Context, _ *domain.DiaperDetails) error {
+func (m *mockStore) CreateDiaperDetails(_ context.Context, details *domain.DiaperDetails) error {
+	m.diaperDetails = details
 	return nil
 }
 func (m *mockStore) GetDiaperDetails(_ context.Context, _ uuid.UUID) (*domain.DiaperDetails, error) {
@@ -218,18 +504,45 @@ func (m *mockStore) GetDiaperDetails(_ context.Context, _ uuid.UUID) (*domain.Di
 	}
 	return nil, errNotFound
 }
+func (m *mockStore) GetDiaperDetailsByActivityIDs(_ context.Context, activityIDs []uuid.UUID) ([]*domain.DiaperDetails, error) {
+	if m.diaperDetails == nil {
+		return nil, nil
+	}
+	result := make([]*domain.DiaperDetails, len(activityIDs))
+	for i, id := range activityIDs {
+		d := *m.diaperDetails
+		d.ActivityID = id
+		result[i] = &d
+	}
+	return result, nil
+}
 func (m *mockStore) UpdateDiaperDetails(_ context.Context, _ *domain.DiaperDetails) error {
 	return nil
 }
 
-func (m *mockStore) CreateSleepDetails(_ context.Context, _ *domain.SleepDetails) error { return nil }
+func (m *mockStore) CreateSleepDetails(_ context.Context, details *domain.SleepDetails) error {
+	m.sleepDetails = details
+	return nil
+}
 func (m *mockStore) GetSleepDetails(_ context.Context, _ uuid.UUID) (*domain.SleepDetails, error) {
 	if m.sleepDetails != nil {
 		return m.sleepDetails, nil
 	}
 	return nil, errNotFound
 }
-func (m *mockStore) GetRecentSleepDetailsForFamily(_ context.Context, _ uuid.UUID, _ int) ([]*domain.SleepDetails, error) {
+func (m *mockStore) GetSleepDetailsByActivityIDs(_ context.Context, activityIDs []uuid.UUID) ([]*domain.SleepDetails, error) {
+	if m.sleepDetails == nil {
+		return nil, nil
+	}
+	result := make([]*domain.SleepDetails, len(activityIDs))
+	for i, id := range activityIDs {
+		d := *m.sleepDetails
+		d.ActivityID = id
+		result[i] = &d
+	}
+	return result, nil
+}
+func (m *mockStore) GetRecentSleepDetailsForBaby(_ context.Context, _ uuid.UUID, _ int) ([]*domain.SleepDetails, error) {
 	if m.recentSleepErr != nil {
 		return nil, m.recentSleepErr
 	}
@@ -237,12 +550,114 @@ func (m *mockStore) GetRecentSleepDetailsForFamily(_ context.Context, _ uuid.UUI
 }
 func (m *mockStore) UpdateSleepDetails(_ context.Context, _ *domain.SleepDetails) error { return nil }
 
+func (m *mockStore) CreatePumpDetails(_ context.Context, details *domain.PumpDetails) error {
+	m.lastCreatedPumpDetails = details
+	m.pumpDetails = details
+	return nil
+}
+func (m *mockStore) GetPumpDetails(_ context.Context, _ uuid.UUID) (*domain.PumpDetails, error) {
+	if m.pumpDetails != nil {
+		return m.pumpDetails, nil
+	}
+	return nil, errNotFound
+}
+func (m *mockStore) GetPumpDetailsByActivityIDs(_ context.Context, activityIDs []uuid.UUID) ([]*domain.PumpDetails, error) {
+	if m.pumpDetails == nil {
+		return nil, nil
+	}
+	result := make([]*domain.PumpDetails, len(activityIDs))
+	for i, id := range activityIDs {
+		d := *m.pumpDetails
+		d.ActivityID = id
+		result[i] = &d
+	}
+	return result, nil
+}
+func (m *mockStore) UpdatePumpDetails(_ context.Context, _ *domain.PumpDetails) error { return nil }
+
+func (m *mockStore) CreateMedicationDetails(_ context.Context, details *domain.MedicationDetails) error {
+	m.createdMedicationDetails = append(m.createdMedicationDetails, details)
+	m.medicationDetails = details
+	return nil
+}
+func (m *mockStore) GetMedicationDetails(_ context.Context, _ uuid.UUID) (*domain.MedicationDetails, error) {
+	if m.medicationDetails != nil {
+		return m.medicationDetails, nil
+	}
+	return nil, errNotFound
+}
+func (m *mockStore) GetMedicationDetailsByActivityIDs(_ context.Context, activityIDs []uuid.UUID) ([]*domain.MedicationDetails, error) {
+	if m.medicationDetails == nil {
+		return nil, nil
+	}
+	result := make([]*domain.MedicationDetails, len(activityIDs))
+	for i, id := range activityIDs {
+		d := *m.medicationDetails
+		d.ActivityID = id
+		result[i] = &d
+	}
+	return result, nil
+}
+func (m *mockStore) GetRecentMedicationDetailsForBaby(_ context.Context, _ uuid.UUID, since time.Time) ([]*domain.MedicationDetails, error) {
+	var result []*domain.MedicationDetails
+	for _, d := range m.recentMedicationDetails {
+		if !d.GivenAt.Before(since) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+func (m *mockStore) GetLatestMedicationDetailsForBaby(_ context.Context, _ uuid.UUID) ([]*domain.MedicationDetails, error) {
+	return m.recentMedicationDetails, nil
+}
+func (m *mockStore) UpdateMedicationDetails(_ context.Context, _ *domain.MedicationDetails) error {
+	return nil
+}
+
+// Medication catalog operations
+func (m *mockStore) UpsertMedication(_ context.Context, medication *domain.Medication) (*domain.Medication, error) {
+	if medication.ID == uuid.Nil {
+		medication.ID = uuid.New()
+	}
+	return medication, nil
+}
+func (m *mockStore) GetMedicationByID(_ context.Context, id uuid.UUID) (*domain.Medication, error) {
+	for _, med := range m.medications {
+		if med.ID == id {
+			return med, nil
+		}
+	}
+	return nil, errNotFound
+}
+func (m *mockStore) GetMedicationByName(_ context.Context, _ uuid.UUID, name string) (*domain.Medication, error) {
+	for _, med := range m.medications {
+		if strings.EqualFold(med.Name, name) {
+			return med, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockStore) GetMedicationsForFamily(_ context.Context, _ uuid.UUID) ([]*domain.Medication, error) {
+	return m.medications, nil
+}
+func (m *mockStore) DeleteMedication(_ context.Context, _ uuid.UUID) error { return nil }
+
+// Growth measurement operations
+func (m *mockStore) CreateGrowthMeasurement(_ context.Context, measurement *domain.GrowthMeasurement) error {
+	m.growthMeasurements = append(m.growthMeasurements, measurement)
+	return nil
+}
+func (m *mockStore) GetGrowthMeasurementsForBaby(_ context.Context, _ uuid.UUID) ([]*domain.GrowthMeasurement, error) {
+	return m.growthMeasurements, nil
+}
+
 // Prediction operations
 func (m *mockStore) UpsertPredictions(_ context.Context, _ uuid.UUID, predictions []*domain.Prediction) error {
 	m.upsertedPredictions = predictions
+	m.upsertPredictionsCount++
 	return m.upsertPredErr
 }
-func (m *mockStore) GetPredictionsForFamily(_ context.Context, _ uuid.UUID) ([]*domain.Prediction, error) {
+func (m *mockStore) GetPredictionsForBaby(_ context.Context, _ uuid.UUID) ([]*domain.Prediction, error) {
 	if m.predictionsErr != nil {
 		return nil, m.predictionsErr
 	}
@@ -251,19 +666,186 @@ func (m *mockStore) GetPredictionsForFamily(_ context.Context, _ uuid.UUID) ([]*
 func (m *mockStore) DismissPrediction(_ context.Context, _ uuid.UUID) error {
 	return nil
 }
-func (m *mockStore) DeletePredictionsForFamily(_ context.Context, _ uuid.UUID) error {
+func (m *mockStore) DeletePredictionsForBaby(_ context.Context, babyID uuid.UUID) error {
+	if m.deletePredictionsErr != nil {
+		return m.deletePredictionsErr
+	}
+	m.deletedPredictionBabyIDs = append(m.deletedPredictionBabyIDs, babyID)
 	return nil
 }
 func (m *mockStore) CleanupOldPredictions(_ context.Context, _ time.Time) error {
 	return nil
 }
 
+// Prediction outcome operations
+func (m *mockStore) CreatePredictionOutcome(_ context.Context, outcome *domain.PredictionOutcome) error {
+	m.predictionOutcomes = append(m.predictionOutcomes, outcome)
+	return nil
+}
+func (m *mockStore) GetPredictionOutcomesForFamily(_ context.Context, _ uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return m.outcomesSince(func(*domain.PredictionOutcome) bool { return true }, since), nil
+}
+func (m *mockStore) GetPredictionOutcomesForBaby(_ context.Context, babyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return m.outcomesSince(func(o *domain.PredictionOutcome) bool { return o.BabyID == babyID }, since), nil
+}
+func (m *mockStore) outcomesSince(keep func(*domain.PredictionOutcome) bool, since time.Time) []*domain.PredictionOutcome {
+	var outcomes []*domain.PredictionOutcome
+	for _, o := range m.predictionOutcomes {
+		if keep(o) && !o.ActualTime.Before(since) {
+			outcomes = append(outcomes, o)
+		}
+	}
+	return outcomes
+}
+
 // Schedule Goals operations
 func (m *mockStore) GetScheduleGoals(_ context.Context, _ uuid.UUID) (*domain.ScheduleGoals, error) {
 	return nil, nil
 }
-func (m *mockStore) UpsertScheduleGoals(_ context.Context, _ uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error) {
+func (m *mockStore) UpsertScheduleGoals(_ context.Context, babyID uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error) {
+	m.upsertedGoalsBabyID = babyID
 	return goals, nil
 }
 
+// Reminder preference operations
+func (m *mockStore) GetReminderPreferences(_ context.Context, caregiverID uuid.UUID) (*domain.ReminderPreferences, error) {
+	return m.reminderPreferences[caregiverID], nil
+}
+func (m *mockStore) GetReminderPreferencesForFamily(_ context.Context, familyID uuid.UUID) ([]*domain.ReminderPreferences, error) {
+	var result []*domain.ReminderPreferences
+	for _, prefs := range m.reminderPreferences {
+		if prefs.FamilyID == familyID {
+			result = append(result, prefs)
+		}
+	}
+	return result, nil
+}
+func (m *mockStore) UpsertReminderPreferences(_ context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	if m.reminderPreferences == nil {
+		m.reminderPreferences = make(map[uuid.UUID]*domain.ReminderPreferences)
+	}
+	m.reminderPreferences[prefs.CaregiverID] = prefs
+	return prefs, nil
+}
+
+// Webhook operations
+func (m *mockStore) CreateWebhookSubscription(_ context.Context, sub *domain.WebhookSubscription) error {
+	m.webhookSubscriptions = append(m.webhookSubscriptions, sub)
+	return nil
+}
+func (m *mockStore) GetWebhookSubscriptionByID(_ context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	for _, sub := range m.webhookSubscriptions {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("webhook subscription not found: %s", id)
+}
+func (m *mockStore) GetWebhookSubscriptionsForFamily(_ context.Context, familyID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	var result []*domain.WebhookSubscription
+	for _, sub := range m.webhookSubscriptions {
+		if sub.FamilyID == familyID {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+func (m *mockStore) DeleteWebhookSubscription(_ context.Context, id uuid.UUID) error {
+	m.webhookSubscriptions = slices.DeleteFunc(m.webhookSubscriptions, func(sub *domain.WebhookSubscription) bool { return sub.ID == id })
+	m.webhookDeliveries = slices.DeleteFunc(m.webhookDeliveries, func(d *domain.WebhookDelivery) bool { return d.SubscriptionID == id })
+	return nil
+}
+func (m *mockStore) CreateWebhookDelivery(_ context.Context, d *domain.WebhookDelivery) error {
+	m.webhookDeliveries = append(m.webhookDeliveries, d)
+	return nil
+}
+func (m *mockStore) GetDueWebhookDeliveries(_ context.Context, _ time.Time, _ int) ([]*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateWebhookDelivery(_ context.Context, _ *domain.WebhookDelivery) error {
+	return nil
+}
+func (m *mockStore) GetWebhookDeliveriesForFamily(_ context.Context, familyID uuid.UUID, status *domain.WebhookDeliveryStatus, _ int) ([]*domain.WebhookDelivery, error) {
+	var result []*domain.WebhookDelivery
+	for _, d := range m.webhookDeliveries {
+		if d.FamilyID == familyID && (status == nil || d.Status == *status) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// Audit log operations
+func (m *mockStore) CreateAuditEvent(_ context.Context, event *domain.AuditEvent) error {
+	if m.createAuditEventErr != nil {
+		return m.createAuditEventErr
+	}
+	m.auditEvents = append(m.auditEvents, event)
+	return nil
+}
+func (m *mockStore) GetAuditEventsForFamily(_ context.Context, familyID uuid.UUID, filter domain.AuditFilter, n int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.AuditEvent, error) {
+	var result []*domain.AuditEvent
+	for _, e := range slices.Backward(m.auditEvents) {
+		if e.FamilyID != familyID || !filter.Matches(e) {
+			continue
+		}
+		// Events are appended in time order, so the cursor is a position in the slice
+		if afterID != nil {
+			if e.ID == *afterID {
+				afterID = nil
+			}
+			continue
+		}
+		result = append(result, e)
+		if len(result) == n {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Offline sync operations
+func (m *mockStore) GetIdempotencyRecord(_ context.Context, _ uuid.UUID, key string) (*domain.IdempotencyRecord, error) {
+	return m.idempotencyRecords[key], nil
+}
+func (m *mockStore) SaveIdempotencyRecord(_ context.Context, record *domain.IdempotencyRecord) error {
+	if m.idempotencyRecords == nil {
+		m.idempotencyRecords = make(map[string]*domain.IdempotencyRecord)
+	}
+	m.idempotencyRecords[record.Key] = record
+	return nil
+}
+func (m *mockStore) RecordDeletedActivity(_ context.Context, deleted *domain.DeletedActivity) error {
+	if m.deletedActivities == nil {
+		m.deletedActivities = make(map[uuid.UUID]*domain.DeletedActivity)
+	}
+	m.deletedActivities[deleted.ActivityID] = deleted
+	return nil
+}
+func (m *mockStore) GetDeletedActivity(_ context.Context, activityID uuid.UUID) (*domain.DeletedActivity, error) {
+	return m.deletedActivities[activityID], nil
+}
+func (m *mockStore) ClearDeletedActivity(_ context.Context, activityID uuid.UUID) error {
+	delete(m.deletedActivities, activityID)
+	return nil
+}
+func (m *mockStore) GetDeletedActivitiesSinceForFamily(_ context.Context, _ uuid.UUID, _ time.Time) ([]*domain.DeletedActivity, error) {
+	var deleted []*domain.DeletedActivity
+	for _, d := range m.deletedActivities {
+		deleted = append(deleted, d)
+	}
+	return deleted, nil
+}
+
+// WithTx runs fn against the mock itself; it can't undo writes, so tests assert on
+// rolledBackTxCount to check that a failure aborted the transaction.
+func (m *mockStore) WithTx(_ context.Context, fn func(store.Store) error) error {
+	m.txCount++
+	if err := fn(m); err != nil {
+		m.rolledBackTxCount++
+		return err
+	}
+	return nil
+}
+
 func (m *mockStore) Close() error { return nil }
@@ -15,23 +15,74 @@ type Activity interface {
 }
 
 type ActivityInput struct {
-	ActivityType  ActivityType        `json:"activityType"`
-	FeedDetails   *FeedDetailsInput   `json:"feedDetails,omitempty"`
-	DiaperDetails *DiaperDetailsInput `json:"diaperDetails,omitempty"`
-	SleepDetails  *SleepDetailsInput  `json:"sleepDetails,omitempty"`
+	ID                *string                 `json:"id,omitempty"`
+	IdempotencyKey    *string                 `json:"idempotencyKey,omitempty"`
+	BabyID            *string                 `json:"babyId,omitempty"`
+	ActivityType      ActivityType            `json:"activityType"`
+	FeedDetails       *FeedDetailsInput       `json:"feedDetails,omitempty"`
+	DiaperDetails     *DiaperDetailsInput     `json:"diaperDetails,omitempty"`
+	SleepDetails      *SleepDetailsInput      `json:"sleepDetails,omitempty"`
+	PumpDetails       *PumpDetailsInput       `json:"pumpDetails,omitempty"`
+	MedicationDetails *MedicationDetailsInput `json:"medicationDetails,omitempty"`
+}
+
+type AuditEvent struct {
+	ID               string          `json:"id"`
+	ActorCaregiverID string          `json:"actorCaregiverId"`
+	ActorName        string          `json:"actorName"`
+	Action           AuditAction     `json:"action"`
+	EntityType       AuditEntityType `json:"entityType"`
+	EntityID         string          `json:"entityId"`
+	Before           *string         `json:"before,omitempty"`
+	After            *string         `json:"after,omitempty"`
+	CreatedAt        time.Time       `json:"createdAt"`
+}
+
+type AuditEventConnection struct {
+	Edges    []*AuditEventEdge   `json:"edges"`
+	PageInfo *AuditEventPageInfo `json:"pageInfo"`
+}
+
+type AuditEventEdge struct {
+	Node   *AuditEvent `json:"node"`
+	Cursor string      `json:"cursor"`
+}
+
+type AuditEventPageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
+}
+
+type AuditLogFilter struct {
+	ActorCaregiverID *string          `json:"actorCaregiverId,omitempty"`
+	Action           *AuditAction     `json:"action,omitempty"`
+	EntityType       *AuditEntityType `json:"entityType,omitempty"`
+	EntityID         *string          `json:"entityId,omitempty"`
 }
 
 type AuthResult struct {
-	Success   bool       `json:"success"`
-	Family    *Family    `json:"family,omitempty"`
-	Caregiver *Caregiver `json:"caregiver,omitempty"`
-	Error     *string    `json:"error,omitempty"`
+	Success     bool       `json:"success"`
+	Family      *Family    `json:"family,omitempty"`
+	Caregiver   *Caregiver `json:"caregiver,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	DeviceToken *string    `json:"deviceToken,omitempty"`
+}
+
+type Baby struct {
+	ID        string     `json:"id"`
+	FamilyID  string     `json:"familyId"`
+	Name      string     `json:"name"`
+	BirthDate *time.Time `json:"birthDate,omitempty"`
+	Sex       *BabySex   `json:"sex,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
 }
 
 type BabyStatus struct {
-	LastFeed   *FeedActivity   `json:"lastFeed,omitempty"`
-	LastDiaper *DiaperActivity `json:"lastDiaper,omitempty"`
-	LastSleep  *SleepActivity  `json:"lastSleep,omitempty"`
+	Baby           *Baby               `json:"baby"`
+	LastFeed       *FeedActivity       `json:"lastFeed,omitempty"`
+	LastDiaper     *DiaperActivity     `json:"lastDiaper,omitempty"`
+	LastSleep      *SleepActivity      `json:"lastSleep,omitempty"`
+	LastMedication *MedicationActivity `json:"lastMedication,omitempty"`
 }
 
 type CareSession struct {
@@ -72,18 +123,43 @@ type CareSessionSummary struct {
 }
 
 type Caregiver struct {
-	ID         string    `json:"id"`
-	FamilyID   string    `json:"familyId"`
-	Name       string    `json:"name"`
-	DeviceID   string    `json:"deviceId"`
-	DeviceName *string   `json:"deviceName,omitempty"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID         string        `json:"id"`
+	FamilyID   string        `json:"familyId"`
+	Name       string        `json:"name"`
+	DeviceID   string        `json:"deviceId"`
+	DeviceName *string       `json:"deviceName,omitempty"`
+	Role       CaregiverRole `json:"role"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type CreateInviteInput struct {
+	ExpiresInHours *int32         `json:"expiresInHours,omitempty"`
+	MaxUses        *int32         `json:"maxUses,omitempty"`
+	Role           *CaregiverRole `json:"role,omitempty"`
+}
+
+type CreatedInvite struct {
+	Invite *FamilyInvite `json:"invite"`
+	Code   string        `json:"code"`
+	Link   *string       `json:"link,omitempty"`
+}
+
+type DetectedPattern struct {
+	Type        DetectedPatternType `json:"type"`
+	StartTime   time.Time           `json:"startTime"`
+	EndTime     time.Time           `json:"endTime"`
+	FeedCount   int32               `json:"feedCount"`
+	Increase    *float64            `json:"increase,omitempty"`
+	Ongoing     bool                `json:"ongoing"`
+	Description string              `json:"description"`
 }
 
 type DiaperActivity struct {
 	ID            string         `json:"id"`
+	BabyID        string         `json:"babyId"`
 	ActivityType  ActivityType   `json:"activityType"`
 	CreatedAt     time.Time      `json:"createdAt"`
+	Version       int32          `json:"version"`
 	DiaperDetails *DiaperDetails `json:"diaperDetails,omitempty"`
 }
 
@@ -102,18 +178,36 @@ type DiaperDetailsInput struct {
 }
 
 type Family struct {
-	ID         string       `json:"id"`
-	Name       string       `json:"name"`
-	BabyName   string       `json:"babyName"`
-	Password   string       `json:"password"`
-	Caregivers []*Caregiver `json:"caregivers"`
-	CreatedAt  time.Time    `json:"createdAt"`
+	ID                      string          `json:"id"`
+	Name                    string          `json:"name"`
+	BabyName                string          `json:"babyName"`
+	Babies                  []*Baby         `json:"babies"`
+	Caregivers              []*Caregiver    `json:"caregivers"`
+	Timezone                *string         `json:"timezone,omitempty"`
+	TravelMode              *TravelMode     `json:"travelMode,omitempty"`
+	PredictionModel         PredictionModel `json:"predictionModel"`
+	PredictionHalfLifeHours *int32          `json:"predictionHalfLifeHours,omitempty"`
+	CreatedAt               time.Time       `json:"createdAt"`
+}
+
+type FamilyInvite struct {
+	ID        string        `json:"id"`
+	CreatedBy *string       `json:"createdBy,omitempty"`
+	ExpiresAt time.Time     `json:"expiresAt"`
+	MaxUses   *int32        `json:"maxUses,omitempty"`
+	UseCount  int32         `json:"useCount"`
+	Role      CaregiverRole `json:"role"`
+	RevokedAt *time.Time    `json:"revokedAt,omitempty"`
+	Active    bool          `json:"active"`
+	CreatedAt time.Time     `json:"createdAt"`
 }
 
 type FeedActivity struct {
 	ID           string       `json:"id"`
+	BabyID       string       `json:"babyId"`
 	ActivityType ActivityType `json:"activityType"`
 	CreatedAt    time.Time    `json:"createdAt"`
+	Version      int32        `json:"version"`
 	FeedDetails  *FeedDetails `json:"feedDetails,omitempty"`
 }
 
@@ -140,14 +234,103 @@ type FeedDetailsInput struct {
 	QuantityUnit *SolidsUnit `json:"quantityUnit,omitempty"`
 }
 
+type GrowthMeasurement struct {
+	ID                          string            `json:"id"`
+	BabyID                      string            `json:"babyId"`
+	MeasuredAt                  time.Time         `json:"measuredAt"`
+	WeightKg                    *float64          `json:"weightKg,omitempty"`
+	LengthCm                    *float64          `json:"lengthCm,omitempty"`
+	HeadCircumferenceCm         *float64          `json:"headCircumferenceCm,omitempty"`
+	Notes                       *string           `json:"notes,omitempty"`
+	AgeMonths                   *float64          `json:"ageMonths,omitempty"`
+	WeightPercentile            *GrowthPercentile `json:"weightPercentile,omitempty"`
+	LengthPercentile            *GrowthPercentile `json:"lengthPercentile,omitempty"`
+	HeadCircumferencePercentile *GrowthPercentile `json:"headCircumferencePercentile,omitempty"`
+}
+
+type GrowthMeasurementInput struct {
+	BabyID              *string   `json:"babyId,omitempty"`
+	MeasuredAt          time.Time `json:"measuredAt"`
+	WeightKg            *float64  `json:"weightKg,omitempty"`
+	LengthCm            *float64  `json:"lengthCm,omitempty"`
+	HeadCircumferenceCm *float64  `json:"headCircumferenceCm,omitempty"`
+	Notes               *string   `json:"notes,omitempty"`
+}
+
+type GrowthPercentile struct {
+	ZScore     float64 `json:"zScore"`
+	Percentile float64 `json:"percentile"`
+}
+
+type Medication struct {
+	ID                 string           `json:"id"`
+	Name               string           `json:"name"`
+	DefaultDoseAmount  *float64         `json:"defaultDoseAmount,omitempty"`
+	DefaultDoseUnit    *DoseUnit        `json:"defaultDoseUnit,omitempty"`
+	DefaultRoute       *MedicationRoute `json:"defaultRoute,omitempty"`
+	MinIntervalMinutes *int32           `json:"minIntervalMinutes,omitempty"`
+	MaxDailyDoses      *int32           `json:"maxDailyDoses,omitempty"`
+}
+
+type MedicationActivity struct {
+	ID                string             `json:"id"`
+	BabyID            string             `json:"babyId"`
+	ActivityType      ActivityType       `json:"activityType"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	Version           int32              `json:"version"`
+	MedicationDetails *MedicationDetails `json:"medicationDetails,omitempty"`
+}
+
+func (MedicationActivity) IsActivity() {}
+
+type MedicationDetails struct {
+	MedicationID       *string         `json:"medicationId,omitempty"`
+	DrugName           string          `json:"drugName"`
+	DoseAmount         float64         `json:"doseAmount"`
+	DoseUnit           DoseUnit        `json:"doseUnit"`
+	Route              MedicationRoute `json:"route"`
+	GivenAt            time.Time       `json:"givenAt"`
+	IntervalOverridden bool            `json:"intervalOverridden"`
+}
+
+type MedicationDetailsInput struct {
+	DrugName            string           `json:"drugName"`
+	DoseAmount          float64          `json:"doseAmount"`
+	DoseUnit            DoseUnit         `json:"doseUnit"`
+	Route               *MedicationRoute `json:"route,omitempty"`
+	GivenAt             time.Time        `json:"givenAt"`
+	OverrideSafetyCheck *bool            `json:"overrideSafetyCheck,omitempty"`
+}
+
+type MedicationInput struct {
+	Name               string           `json:"name"`
+	DefaultDoseAmount  *float64         `json:"defaultDoseAmount,omitempty"`
+	DefaultDoseUnit    *DoseUnit        `json:"defaultDoseUnit,omitempty"`
+	DefaultRoute       *MedicationRoute `json:"defaultRoute,omitempty"`
+	MinIntervalMinutes *int32           `json:"minIntervalMinutes,omitempty"`
+	MaxDailyDoses      *int32           `json:"maxDailyDoses,omitempty"`
+}
+
+type MedicationStatus struct {
+	Medication       *Medication         `json:"medication"`
+	LastDose         *MedicationActivity `json:"lastDose,omitempty"`
+	DosesLast24Hours int32               `json:"dosesLast24Hours"`
+	NextAllowedAt    *time.Time          `json:"nextAllowedAt,omitempty"`
+	CanGiveNow       bool                `json:"canGiveNow"`
+	Reason           *string             `json:"reason,omitempty"`
+}
+
 type Mutation struct {
 }
 
 type ParsedActivity struct {
-	ActivityType  ActivityType   `json:"activityType"`
-	FeedDetails   *FeedDetails   `json:"feedDetails,omitempty"`
-	DiaperDetails *DiaperDetails `json:"diaperDetails,omitempty"`
-	SleepDetails  *SleepDetails  `json:"sleepDetails,omitempty"`
+	BabyID            *string            `json:"babyId,omitempty"`
+	ActivityType      ActivityType       `json:"activityType"`
+	FeedDetails       *FeedDetails       `json:"feedDetails,omitempty"`
+	DiaperDetails     *DiaperDetails     `json:"diaperDetails,omitempty"`
+	SleepDetails      *SleepDetails      `json:"sleepDetails,omitempty"`
+	PumpDetails       *PumpDetails       `json:"pumpDetails,omitempty"`
+	MedicationDetails *MedicationDetails `json:"medicationDetails,omitempty"`
 }
 
 type ParsedVoiceResult struct {
@@ -159,6 +342,7 @@ type ParsedVoiceResult struct {
 
 type Prediction struct {
 	ID                       string                `json:"id"`
+	BabyID                   string                `json:"babyId"`
 	ActivityType             ActivityType          `json:"activityType"`
 	PredictionType           PredictionType        `json:"predictionType"`
 	PredictedTime            time.Time             `json:"predictedTime"`
@@ -170,10 +354,84 @@ type Prediction struct {
 	CareSessionID            *string               `json:"careSessionId,omitempty"`
 }
 
+type PredictionAccuracy struct {
+	PredictionType           PredictionType `json:"predictionType"`
+	SampleCount              int32          `json:"sampleCount"`
+	MeanAbsoluteErrorMinutes *float64       `json:"meanAbsoluteErrorMinutes,omitempty"`
+	MeanErrorMinutes         *float64       `json:"meanErrorMinutes,omitempty"`
+	Within15Rate             *float64       `json:"within15Rate,omitempty"`
+	Within30Rate             *float64       `json:"within30Rate,omitempty"`
+}
+
+type PumpActivity struct {
+	ID           string       `json:"id"`
+	BabyID       string       `json:"babyId"`
+	ActivityType ActivityType `json:"activityType"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	Version      int32        `json:"version"`
+	PumpDetails  *PumpDetails `json:"pumpDetails,omitempty"`
+}
+
+func (PumpActivity) IsActivity() {}
+
+type PumpDetails struct {
+	StartTime       time.Time  `json:"startTime"`
+	EndTime         *time.Time `json:"endTime,omitempty"`
+	LeftMl          *int32     `json:"leftMl,omitempty"`
+	RightMl         *int32     `json:"rightMl,omitempty"`
+	TotalMl         *int32     `json:"totalMl,omitempty"`
+	DurationMinutes *int32     `json:"durationMinutes,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+}
+
+type PumpDetailsInput struct {
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	LeftMl    *int32     `json:"leftMl,omitempty"`
+	RightMl   *int32     `json:"rightMl,omitempty"`
+	TotalMl   *int32     `json:"totalMl,omitempty"`
+	Notes     *string    `json:"notes,omitempty"`
+}
+
 type Query struct {
 }
 
+type RecentlyDeleted struct {
+	Activities   []*RecentlyDeletedActivity    `json:"activities"`
+	CareSessions []*RecentlyDeletedCareSession `json:"careSessions"`
+}
+
+type RecentlyDeletedActivity struct {
+	Activity  Activity  `json:"activity"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+type RecentlyDeletedCareSession struct {
+	CareSession *CareSession `json:"careSession"`
+	DeletedAt   time.Time    `json:"deletedAt"`
+	PurgeAt     time.Time    `json:"purgeAt"`
+}
+
+type ReminderPreferences struct {
+	CaregiverID      string `json:"caregiverId"`
+	FeedReminders    bool   `json:"feedReminders"`
+	NapReminders     bool   `json:"napReminders"`
+	BedtimeReminders bool   `json:"bedtimeReminders"`
+	OverdueReminders bool   `json:"overdueReminders"`
+	LeadMinutes      int32  `json:"leadMinutes"`
+}
+
+type ReminderPreferencesInput struct {
+	FeedReminders    bool   `json:"feedReminders"`
+	NapReminders     bool   `json:"napReminders"`
+	BedtimeReminders bool   `json:"bedtimeReminders"`
+	OverdueReminders bool   `json:"overdueReminders"`
+	LeadMinutes      *int32 `json:"leadMinutes,omitempty"`
+}
+
 type ScheduleGoals struct {
+	BabyID                    string  `json:"babyId"`
 	TargetWakeWindowMinutes   *int32  `json:"targetWakeWindowMinutes,omitempty"`
 	TargetFeedIntervalMinutes *int32  `json:"targetFeedIntervalMinutes,omitempty"`
 	TargetNapCount            *int32  `json:"targetNapCount,omitempty"`
@@ -193,8 +451,10 @@ type ScheduleGoalsInput struct {
 
 type SleepActivity struct {
 	ID           string        `json:"id"`
+	BabyID       string        `json:"babyId"`
 	ActivityType ActivityType  `json:"activityType"`
 	CreatedAt    time.Time     `json:"createdAt"`
+	Version      int32         `json:"version"`
 	SleepDetails *SleepDetails `json:"sleepDetails,omitempty"`
 }
 
@@ -212,23 +472,93 @@ type SleepDetailsInput struct {
 	EndTime   *time.Time `json:"endTime,omitempty"`
 }
 
+type Subscription struct {
+}
+
+type SyncChangeInput struct {
+	Operation      SyncOperation  `json:"operation"`
+	ActivityID     string         `json:"activityId"`
+	IdempotencyKey string         `json:"idempotencyKey"`
+	ChangedAt      time.Time      `json:"changedAt"`
+	Activity       *ActivityInput `json:"activity,omitempty"`
+}
+
+type SyncConflict struct {
+	ActivityID     string        `json:"activityId"`
+	IdempotencyKey string        `json:"idempotencyKey"`
+	Operation      SyncOperation `json:"operation"`
+	Reason         string        `json:"reason"`
+	ServerActivity Activity      `json:"serverActivity,omitempty"`
+}
+
+type SyncResult struct {
+	AppliedKeys        []string        `json:"appliedKeys"`
+	Conflicts          []*SyncConflict `json:"conflicts"`
+	Changes            []Activity      `json:"changes"`
+	DeletedActivityIds []string        `json:"deletedActivityIds"`
+	Cursor             time.Time       `json:"cursor"`
+}
+
+type TravelMode struct {
+	Timezone  string    `json:"timezone"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+type TravelModeInput struct {
+	Timezone  string    `json:"timezone"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	SubscriptionID string                `json:"subscriptionId"`
+	EventType      WebhookEventType      `json:"eventType"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int32                 `json:"attempts"`
+	LastStatusCode *int32                `json:"lastStatusCode,omitempty"`
+	LastError      *string               `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	Payload        string                `json:"payload"`
+}
+
+type WebhookSubscription struct {
+	ID         string             `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"eventTypes"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+type WebhookSubscriptionInput struct {
+	URL        string             `json:"url"`
+	Secret     string             `json:"secret"`
+	EventTypes []WebhookEventType `json:"eventTypes,omitempty"`
+}
+
 type ActivityType string
 
 const (
-	ActivityTypeFeed   ActivityType = "FEED"
-	ActivityTypeDiaper ActivityType = "DIAPER"
-	ActivityTypeSleep  ActivityType = "SLEEP"
+	ActivityTypeFeed       ActivityType = "FEED"
+	ActivityTypeDiaper     ActivityType = "DIAPER"
+	ActivityTypeSleep      ActivityType = "SLEEP"
+	ActivityTypePump       ActivityType = "PUMP"
+	ActivityTypeMedication ActivityType = "MEDICATION"
 )
 
 var AllActivityType = []ActivityType{
 	ActivityTypeFeed,
 	ActivityTypeDiaper,
 	ActivityTypeSleep,
+	ActivityTypePump,
+	ActivityTypeMedication,
 }
 
 func (e ActivityType) IsValid() bool {
 	switch e {
-	case ActivityTypeFeed, ActivityTypeDiaper, ActivityTypeSleep:
+	case ActivityTypeFeed, ActivityTypeDiaper, ActivityTypeSleep, ActivityTypePump, ActivityTypeMedication:
 		return true
 	}
 	return false
@@ -269,48 +599,54 @@ func (e ActivityType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-type CareSessionStatus string
+type AuditAction string
 
 const (
-	CareSessionStatusInProgress CareSessionStatus = "IN_PROGRESS"
-	CareSessionStatusCompleted  CareSessionStatus = "COMPLETED"
+	AuditActionCreate  AuditAction = "CREATE"
+	AuditActionUpdate  AuditAction = "UPDATE"
+	AuditActionDelete  AuditAction = "DELETE"
+	AuditActionRevoke  AuditAction = "REVOKE"
+	AuditActionRestore AuditAction = "RESTORE"
 )
 
-var AllCareSessionStatus = []CareSessionStatus{
-	CareSessionStatusInProgress,
-	CareSessionStatusCompleted,
+var AllAuditAction = []AuditAction{
+	AuditActionCreate,
+	AuditActionUpdate,
+	AuditActionDelete,
+	AuditActionRevoke,
+	AuditActionRestore,
 }
 
-func (e CareSessionStatus) IsValid() bool {
+func (e AuditAction) IsValid() bool {
 	switch e {
-	case CareSessionStatusInProgress, CareSessionStatusCompleted:
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRevoke, AuditActionRestore:
 		return true
 	}
 	return false
 }
 
-func (e CareSessionStatus) String() string {
+func (e AuditAction) String() string {
 	return string(e)
 }
 
-func (e *CareSessionStatus) UnmarshalGQL(v any) error {
+func (e *AuditAction) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = CareSessionStatus(str)
+	*e = AuditAction(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid CareSessionStatus", str)
+		return fmt.Errorf("%s is not a valid AuditAction", str)
 	}
 	return nil
 }
 
-func (e CareSessionStatus) MarshalGQL(w io.Writer) {
+func (e AuditAction) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *CareSessionStatus) UnmarshalJSON(b []byte) error {
+func (e *AuditAction) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -318,56 +654,74 @@ func (e *CareSessionStatus) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e CareSessionStatus) MarshalJSON() ([]byte, error) {
+func (e AuditAction) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type PredictionConfidence string
+type AuditEntityType string
 
 const (
-	PredictionConfidenceHigh   PredictionConfidence = "HIGH"
-	PredictionConfidenceMedium PredictionConfidence = "MEDIUM"
-	PredictionConfidenceLow    PredictionConfidence = "LOW"
+	AuditEntityTypeFamily              AuditEntityType = "FAMILY"
+	AuditEntityTypeBaby                AuditEntityType = "BABY"
+	AuditEntityTypeCaregiver           AuditEntityType = "CAREGIVER"
+	AuditEntityTypeInvite              AuditEntityType = "INVITE"
+	AuditEntityTypeCareSession         AuditEntityType = "CARE_SESSION"
+	AuditEntityTypeActivity            AuditEntityType = "ACTIVITY"
+	AuditEntityTypePrediction          AuditEntityType = "PREDICTION"
+	AuditEntityTypeScheduleGoals       AuditEntityType = "SCHEDULE_GOALS"
+	AuditEntityTypeReminderPreferences AuditEntityType = "REMINDER_PREFERENCES"
+	AuditEntityTypeWebhookSubscription AuditEntityType = "WEBHOOK_SUBSCRIPTION"
+	AuditEntityTypeMedication          AuditEntityType = "MEDICATION"
+	AuditEntityTypeGrowthMeasurement   AuditEntityType = "GROWTH_MEASUREMENT"
 )
 
-var AllPredictionConfidence = []PredictionConfidence{
-	PredictionConfidenceHigh,
-	PredictionConfidenceMedium,
-	PredictionConfidenceLow,
+var AllAuditEntityType = []AuditEntityType{
+	AuditEntityTypeFamily,
+	AuditEntityTypeBaby,
+	AuditEntityTypeCaregiver,
+	AuditEntityTypeInvite,
+	AuditEntityTypeCareSession,
+	AuditEntityTypeActivity,
+	AuditEntityTypePrediction,
+	AuditEntityTypeScheduleGoals,
+	AuditEntityTypeReminderPreferences,
+	AuditEntityTypeWebhookSubscription,
+	AuditEntityTypeMedication,
+	AuditEntityTypeGrowthMeasurement,
 }
 
-func (e PredictionConfidence) IsValid() bool {
+func (e AuditEntityType) IsValid() bool {
 	switch e {
-	case PredictionConfidenceHigh, PredictionConfidenceMedium, PredictionConfidenceLow:
+	case AuditEntityTypeFamily, AuditEntityTypeBaby, AuditEntityTypeCaregiver, AuditEntityTypeInvite, AuditEntityTypeCareSession, AuditEntityTypeActivity, AuditEntityTypePrediction, AuditEntityTypeScheduleGoals, AuditEntityTypeReminderPreferences, AuditEntityTypeWebhookSubscription, AuditEntityTypeMedication, AuditEntityTypeGrowthMeasurement:
 		return true
 	}
 	return false
 }
 
-func (e PredictionConfidence) String() string {
+func (e AuditEntityType) String() string {
 	return string(e)
 }
 
-func (e *PredictionConfidence) UnmarshalGQL(v any) error {
+func (e *AuditEntityType) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = PredictionConfidence(str)
+	*e = AuditEntityType(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid PredictionConfidence", str)
+		return fmt.Errorf("%s is not a valid AuditEntityType", str)
 	}
 	return nil
 }
 
-func (e PredictionConfidence) MarshalGQL(w io.Writer) {
+func (e AuditEntityType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *PredictionConfidence) UnmarshalJSON(b []byte) error {
+func (e *AuditEntityType) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -375,56 +729,54 @@ func (e *PredictionConfidence) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e PredictionConfidence) MarshalJSON() ([]byte, error) {
+func (e AuditEntityType) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type PredictionStatus string
+type BabySex string
 
 const (
-	PredictionStatusOverdue  PredictionStatus = "OVERDUE"
-	PredictionStatusUpcoming PredictionStatus = "UPCOMING"
-	PredictionStatusPlanned  PredictionStatus = "PLANNED"
+	BabySexMale   BabySex = "MALE"
+	BabySexFemale BabySex = "FEMALE"
 )
 
-var AllPredictionStatus = []PredictionStatus{
-	PredictionStatusOverdue,
-	PredictionStatusUpcoming,
-	PredictionStatusPlanned,
+var AllBabySex = []BabySex{
+	BabySexMale,
+	BabySexFemale,
 }
 
-func (e PredictionStatus) IsValid() bool {
+func (e BabySex) IsValid() bool {
 	switch e {
-	case PredictionStatusOverdue, PredictionStatusUpcoming, PredictionStatusPlanned:
+	case BabySexMale, BabySexFemale:
 		return true
 	}
 	return false
 }
 
-func (e PredictionStatus) String() string {
+func (e BabySex) String() string {
 	return string(e)
 }
 
-func (e *PredictionStatus) UnmarshalGQL(v any) error {
+func (e *BabySex) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = PredictionStatus(str)
+	*e = BabySex(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid PredictionStatus", str)
+		return fmt.Errorf("%s is not a valid BabySex", str)
 	}
 	return nil
 }
 
-func (e PredictionStatus) MarshalGQL(w io.Writer) {
+func (e BabySex) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *PredictionStatus) UnmarshalJSON(b []byte) error {
+func (e *BabySex) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -432,58 +784,54 @@ func (e *PredictionStatus) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e PredictionStatus) MarshalJSON() ([]byte, error) {
+func (e BabySex) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type PredictionType string
+type CareSessionStatus string
 
 const (
-	PredictionTypeNextFeed PredictionType = "NEXT_FEED"
-	PredictionTypeNextNap  PredictionType = "NEXT_NAP"
-	PredictionTypeNextWake PredictionType = "NEXT_WAKE"
-	PredictionTypeBedtime  PredictionType = "BEDTIME"
+	CareSessionStatusInProgress CareSessionStatus = "IN_PROGRESS"
+	CareSessionStatusCompleted  CareSessionStatus = "COMPLETED"
 )
 
-var AllPredictionType = []PredictionType{
-	PredictionTypeNextFeed,
-	PredictionTypeNextNap,
-	PredictionTypeNextWake,
-	PredictionTypeBedtime,
+var AllCareSessionStatus = []CareSessionStatus{
+	CareSessionStatusInProgress,
+	CareSessionStatusCompleted,
 }
 
-func (e PredictionType) IsValid() bool {
+func (e CareSessionStatus) IsValid() bool {
 	switch e {
-	case PredictionTypeNextFeed, PredictionTypeNextNap, PredictionTypeNextWake, PredictionTypeBedtime:
+	case CareSessionStatusInProgress, CareSessionStatusCompleted:
 		return true
 	}
 	return false
 }
 
-func (e PredictionType) String() string {
+func (e CareSessionStatus) String() string {
 	return string(e)
 }
 
-func (e *PredictionType) UnmarshalGQL(v any) error {
+func (e *CareSessionStatus) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = PredictionType(str)
+	*e = CareSessionStatus(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid PredictionType", str)
+		return fmt.Errorf("%s is not a valid CareSessionStatus", str)
 	}
 	return nil
 }
 
-func (e PredictionType) MarshalGQL(w io.Writer) {
+func (e CareSessionStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *PredictionType) UnmarshalJSON(b []byte) error {
+func (e *CareSessionStatus) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -491,7 +839,646 @@ func (e *PredictionType) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e PredictionType) MarshalJSON() ([]byte, error) {
+func (e CareSessionStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type CaregiverRole string
+
+const (
+	CaregiverRoleOwner     CaregiverRole = "OWNER"
+	CaregiverRoleParent    CaregiverRole = "PARENT"
+	CaregiverRoleCaregiver CaregiverRole = "CAREGIVER"
+	CaregiverRoleViewer    CaregiverRole = "VIEWER"
+)
+
+var AllCaregiverRole = []CaregiverRole{
+	CaregiverRoleOwner,
+	CaregiverRoleParent,
+	CaregiverRoleCaregiver,
+	CaregiverRoleViewer,
+}
+
+func (e CaregiverRole) IsValid() bool {
+	switch e {
+	case CaregiverRoleOwner, CaregiverRoleParent, CaregiverRoleCaregiver, CaregiverRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e CaregiverRole) String() string {
+	return string(e)
+}
+
+func (e *CaregiverRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CaregiverRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CaregiverRole", str)
+	}
+	return nil
+}
+
+func (e CaregiverRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CaregiverRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CaregiverRole) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DetectedPatternType string
+
+const (
+	DetectedPatternTypeClusterFeeding DetectedPatternType = "CLUSTER_FEEDING"
+	DetectedPatternTypeGrowthSpurt    DetectedPatternType = "GROWTH_SPURT"
+)
+
+var AllDetectedPatternType = []DetectedPatternType{
+	DetectedPatternTypeClusterFeeding,
+	DetectedPatternTypeGrowthSpurt,
+}
+
+func (e DetectedPatternType) IsValid() bool {
+	switch e {
+	case DetectedPatternTypeClusterFeeding, DetectedPatternTypeGrowthSpurt:
+		return true
+	}
+	return false
+}
+
+func (e DetectedPatternType) String() string {
+	return string(e)
+}
+
+func (e *DetectedPatternType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DetectedPatternType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DetectedPatternType", str)
+	}
+	return nil
+}
+
+func (e DetectedPatternType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DetectedPatternType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DetectedPatternType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DoseUnit string
+
+const (
+	DoseUnitMl      DoseUnit = "ML"
+	DoseUnitMg      DoseUnit = "MG"
+	DoseUnitDrops   DoseUnit = "DROPS"
+	DoseUnitTablets DoseUnit = "TABLETS"
+	DoseUnitIu      DoseUnit = "IU"
+)
+
+var AllDoseUnit = []DoseUnit{
+	DoseUnitMl,
+	DoseUnitMg,
+	DoseUnitDrops,
+	DoseUnitTablets,
+	DoseUnitIu,
+}
+
+func (e DoseUnit) IsValid() bool {
+	switch e {
+	case DoseUnitMl, DoseUnitMg, DoseUnitDrops, DoseUnitTablets, DoseUnitIu:
+		return true
+	}
+	return false
+}
+
+func (e DoseUnit) String() string {
+	return string(e)
+}
+
+func (e *DoseUnit) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DoseUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DoseUnit", str)
+	}
+	return nil
+}
+
+func (e DoseUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DoseUnit) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DoseUnit) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MedicationRoute string
+
+const (
+	MedicationRouteOral    MedicationRoute = "ORAL"
+	MedicationRouteRectal  MedicationRoute = "RECTAL"
+	MedicationRouteTopical MedicationRoute = "TOPICAL"
+	MedicationRouteOther   MedicationRoute = "OTHER"
+)
+
+var AllMedicationRoute = []MedicationRoute{
+	MedicationRouteOral,
+	MedicationRouteRectal,
+	MedicationRouteTopical,
+	MedicationRouteOther,
+}
+
+func (e MedicationRoute) IsValid() bool {
+	switch e {
+	case MedicationRouteOral, MedicationRouteRectal, MedicationRouteTopical, MedicationRouteOther:
+		return true
+	}
+	return false
+}
+
+func (e MedicationRoute) String() string {
+	return string(e)
+}
+
+func (e *MedicationRoute) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MedicationRoute(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MedicationRoute", str)
+	}
+	return nil
+}
+
+func (e MedicationRoute) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MedicationRoute) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MedicationRoute) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PredictionConfidence string
+
+const (
+	PredictionConfidenceHigh   PredictionConfidence = "HIGH"
+	PredictionConfidenceMedium PredictionConfidence = "MEDIUM"
+	PredictionConfidenceLow    PredictionConfidence = "LOW"
+)
+
+var AllPredictionConfidence = []PredictionConfidence{
+	PredictionConfidenceHigh,
+	PredictionConfidenceMedium,
+	PredictionConfidenceLow,
+}
+
+func (e PredictionConfidence) IsValid() bool {
+	switch e {
+	case PredictionConfidenceHigh, PredictionConfidenceMedium, PredictionConfidenceLow:
+		return true
+	}
+	return false
+}
+
+func (e PredictionConfidence) String() string {
+	return string(e)
+}
+
+func (e *PredictionConfidence) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PredictionConfidence(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PredictionConfidence", str)
+	}
+	return nil
+}
+
+func (e PredictionConfidence) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PredictionConfidence) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PredictionConfidence) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PredictionModel string
+
+const (
+	PredictionModelMedian          PredictionModel = "MEDIAN"
+	PredictionModelRecencyWeighted PredictionModel = "RECENCY_WEIGHTED"
+)
+
+var AllPredictionModel = []PredictionModel{
+	PredictionModelMedian,
+	PredictionModelRecencyWeighted,
+}
+
+func (e PredictionModel) IsValid() bool {
+	switch e {
+	case PredictionModelMedian, PredictionModelRecencyWeighted:
+		return true
+	}
+	return false
+}
+
+func (e PredictionModel) String() string {
+	return string(e)
+}
+
+func (e *PredictionModel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PredictionModel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PredictionModel", str)
+	}
+	return nil
+}
+
+func (e PredictionModel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PredictionModel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PredictionModel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PredictionStatus string
+
+const (
+	PredictionStatusOverdue  PredictionStatus = "OVERDUE"
+	PredictionStatusUpcoming PredictionStatus = "UPCOMING"
+	PredictionStatusPlanned  PredictionStatus = "PLANNED"
+)
+
+var AllPredictionStatus = []PredictionStatus{
+	PredictionStatusOverdue,
+	PredictionStatusUpcoming,
+	PredictionStatusPlanned,
+}
+
+func (e PredictionStatus) IsValid() bool {
+	switch e {
+	case PredictionStatusOverdue, PredictionStatusUpcoming, PredictionStatusPlanned:
+		return true
+	}
+	return false
+}
+
+func (e PredictionStatus) String() string {
+	return string(e)
+}
+
+func (e *PredictionStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PredictionStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PredictionStatus", str)
+	}
+	return nil
+}
+
+func (e PredictionStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PredictionStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PredictionStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PredictionType string
+
+const (
+	PredictionTypeNextFeed PredictionType = "NEXT_FEED"
+	PredictionTypeNextNap  PredictionType = "NEXT_NAP"
+	PredictionTypeNextWake PredictionType = "NEXT_WAKE"
+	PredictionTypeBedtime  PredictionType = "BEDTIME"
+)
+
+var AllPredictionType = []PredictionType{
+	PredictionTypeNextFeed,
+	PredictionTypeNextNap,
+	PredictionTypeNextWake,
+	PredictionTypeBedtime,
+}
+
+func (e PredictionType) IsValid() bool {
+	switch e {
+	case PredictionTypeNextFeed, PredictionTypeNextNap, PredictionTypeNextWake, PredictionTypeBedtime:
+		return true
+	}
+	return false
+}
+
+func (e PredictionType) String() string {
+	return string(e)
+}
+
+func (e *PredictionType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PredictionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PredictionType", str)
+	}
+	return nil
+}
+
+func (e PredictionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PredictionType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PredictionType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SyncOperation string
+
+const (
+	SyncOperationCreate SyncOperation = "CREATE"
+	SyncOperationUpdate SyncOperation = "UPDATE"
+	SyncOperationDelete SyncOperation = "DELETE"
+)
+
+var AllSyncOperation = []SyncOperation{
+	SyncOperationCreate,
+	SyncOperationUpdate,
+	SyncOperationDelete,
+}
+
+func (e SyncOperation) IsValid() bool {
+	switch e {
+	case SyncOperationCreate, SyncOperationUpdate, SyncOperationDelete:
+		return true
+	}
+	return false
+}
+
+func (e SyncOperation) String() string {
+	return string(e)
+}
+
+func (e *SyncOperation) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SyncOperation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SyncOperation", str)
+	}
+	return nil
+}
+
+func (e SyncOperation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SyncOperation) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SyncOperation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusDelivered,
+	WebhookDeliveryStatusFailed,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusDelivered, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookDeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookEventType string
+
+const (
+	WebhookEventTypeSessionStarted    WebhookEventType = "SESSION_STARTED"
+	WebhookEventTypeSessionCompleted  WebhookEventType = "SESSION_COMPLETED"
+	WebhookEventTypeActivityAdded     WebhookEventType = "ACTIVITY_ADDED"
+	WebhookEventTypeActivityUpdated   WebhookEventType = "ACTIVITY_UPDATED"
+	WebhookEventTypeActivityDeleted   WebhookEventType = "ACTIVITY_DELETED"
+	WebhookEventTypePredictionOverdue WebhookEventType = "PREDICTION_OVERDUE"
+)
+
+var AllWebhookEventType = []WebhookEventType{
+	WebhookEventTypeSessionStarted,
+	WebhookEventTypeSessionCompleted,
+	WebhookEventTypeActivityAdded,
+	WebhookEventTypeActivityUpdated,
+	WebhookEventTypeActivityDeleted,
+	WebhookEventTypePredictionOverdue,
+}
+
+func (e WebhookEventType) IsValid() bool {
+	switch e {
+	case WebhookEventTypeSessionStarted, WebhookEventTypeSessionCompleted, WebhookEventTypeActivityAdded, WebhookEventTypeActivityUpdated, WebhookEventTypeActivityDeleted, WebhookEventTypePredictionOverdue:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEventType) String() string {
+	return string(e)
+}
+
+func (e *WebhookEventType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEventType", str)
+	}
+	return nil
+}
+
+func (e WebhookEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookEventType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookEventType) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// callerSubjects returns the rate limit subjects identifying the caller: their IP, and their
+// user if signed in or else the device they claim to be. The IP is always first.
+func callerSubjects(ctx context.Context, deviceID *string) []ratelimit.Subject {
+	subjects := []ratelimit.Subject{ratelimit.IP(ratelimit.ClientIP(ctx))}
+	if userID, ok := middleware.GetUserID(ctx); ok {
+		subjects = append(subjects, ratelimit.Device("user:"+userID.String()))
+	} else if deviceID != nil {
+		subjects = append(subjects, ratelimit.Device(*deviceID))
+	}
+	return subjects
+}
+
+// allowAttempt counts an attempt at action, returning a RATE_LIMITED error telling the client
+// when to retry if it is over a limit or locked out. Limiter failures are logged and the
+// attempt allowed, so an unavailable backend doesn't lock everyone out.
+func (r *Resolver) allowAttempt(ctx context.Context, action ratelimit.Action, subjects ...ratelimit.Subject) error {
+	if r.limiter == nil {
+		return nil
+	}
+
+	err := r.limiter.Allow(ctx, action, subjects...)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		return &gqlerror.Error{
+			Message:    limitErr.Error(),
+			Extensions: ratelimit.Extensions(limitErr, time.Now()),
+		}
+	}
+	if err != nil {
+		log.Printf("rate limiter: %v", err)
+	}
+	return nil
+}
+
+// attemptFailed records a failed attempt at action, progressively locking out its subjects
+func (r *Resolver) attemptFailed(ctx context.Context, action ratelimit.Action, subjects ...ratelimit.Subject) {
+	if r.limiter == nil {
+		return
+	}
+	if err := r.limiter.Fail(ctx, action, subjects...); err != nil {
+		log.Printf("rate limiter: %v", err)
+	}
+}
+
+// attemptSucceeded clears the subjects' failures for action. Callers pass only subjects
+// that prove something, never the IP, so one good login can't wipe out failures
+// racked up against other families.
+func (r *Resolver) attemptSucceeded(ctx context.Context, action ratelimit.Action, subjects ...ratelimit.Subject) {
+	if r.limiter == nil {
+		return
+	}
+	if err := r.limiter.Succeed(ctx, action, subjects...); err != nil {
+		log.Printf("rate limiter: %v", err)
+	}
+}
@@ -1,18 +1,67 @@
 package graph
 
-import "github.com/swatkatz/babybaton/backend/internal/store"
+import (
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/devicetoken"
+	"github.com/swatkatz/babybaton/backend/internal/pubsub"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
-type Resolver struct{
-	store store.Store
+type Resolver struct {
+	store  store.Store
+	events *pubsub.Broker
+
+	// inviteLinkBase is the URL invite codes are added to as ?code=; empty means no links
+	inviteLinkBase string
+
+	// limiter throttles joinFamily and family name checks; nil disables rate limiting
+	limiter *ratelimit.Limiter
+
+	// deviceTokens signs the tokens device-based clients authenticate with; nil issues none
+	deviceTokens *devicetoken.Signer
+
+	// deletedRetention is how long deleted activities and sessions stay restorable
+	deletedRetention time.Duration
 }
 
+// DefaultDeletedRetention is how long deleted activities and sessions stay restorable
+// unless SetDeletedRetention says otherwise.
+const DefaultDeletedRetention = 30 * 24 * time.Hour
+
 // NewResolver creates a new resolver with the given store
 func NewResolver(store store.Store) *Resolver {
 	return &Resolver{
-		store: store,
+		store:            store,
+		events:           pubsub.NewBroker(),
+		deletedRetention: DefaultDeletedRetention,
 	}
 }
+
+// SetInviteLinkBase makes createInvite return links built from base, e.g.
+// "https://babybaton.app/join" gives "https://babybaton.app/join?code=...".
+func (r *Resolver) SetInviteLinkBase(base string) {
+	r.inviteLinkBase = base
+}
+
+// SetRateLimiter makes the resolver throttle attempts to join families or probe family
+// names with l.
+func (r *Resolver) SetRateLimiter(l *ratelimit.Limiter) {
+	r.limiter = l
+}
+
+// SetDeviceTokenSigner makes createFamily and joinFamily issue device tokens signed by s.
+func (r *Resolver) SetDeviceTokenSigner(s *devicetoken.Signer) {
+	r.deviceTokens = s
+}
+
+// SetDeletedRetention sets how long deleted activities and sessions are listed by
+// recentlyDeleted before they are purged.
+func (r *Resolver) SetDeletedRetention(d time.Duration) {
+	r.deletedRetention = d
+}
@@ -7,6 +7,7 @@ package graph
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -17,14 +18,34 @@ import (
 	"github.com/swatkatz/babybaton/backend/graph/model"
 	"github.com/swatkatz/babybaton/backend/internal/ai"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/invite"
 	"github.com/swatkatz/babybaton/backend/internal/mapper"
 	"github.com/swatkatz/babybaton/backend/internal/middleware"
 	"github.com/swatkatz/babybaton/backend/internal/prediction"
+	"github.com/swatkatz/babybaton/backend/internal/pubsub"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+	"github.com/swatkatz/babybaton/backend/internal/store"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Babies is the resolver for the babies field.
+func (r *familyResolver) Babies(ctx context.Context, obj *model.Family) ([]*model.Baby, error) {
+	familyID, err := uuid.Parse(obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid family ID: %w", err)
+	}
+
+	return r.babiesForFamily(ctx, familyID)
+}
+
 // CreateFamily is the resolver for the createFamily field.
 func (r *mutationResolver) CreateFamily(ctx context.Context, familyName string, password string, babyName string, caregiverName string, deviceID *string, deviceName *string) (*model.AuthResult, error) {
+	// A taken name reveals that the family exists, so creates share the name check's limit
+	subjects := callerSubjects(ctx, deviceID)
+	if err := r.allowAttempt(ctx, ratelimit.ActionCheckFamilyName, subjects...); err != nil {
+		return nil, err
+	}
+
 	// Validate password length
 	if len(password) < 6 {
 		return &model.AuthResult{
@@ -33,21 +54,6 @@ func (r *mutationResolver) CreateFamily(ctx context.Context, familyName string,
 		}, nil
 	}
 
-	// Check if family name already exists
-	exists, err := r.store.FamilyNameExists(ctx, familyName)
-	if err != nil {
-		return &model.AuthResult{
-			Success: false,
-			Error:   stringPtr("Failed to check family name availability"),
-		}, nil
-	}
-	if exists {
-		return &model.AuthResult{
-			Success: false,
-			Error:   stringPtr("Family name already taken"),
-		}, nil
-	}
-
 	// Hash password
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -67,12 +73,19 @@ func (r *mutationResolver) CreateFamily(ctx context.Context, familyName string,
 		ID:           familyID,
 		Name:         familyName,
 		PasswordHash: string(passwordHash),
-		Password:     password,
 		BabyName:     babyName,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
 
+	baby := &domain.Baby{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		Name:      babyName,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
 	// Determine auth path: JWT user-based or legacy device-based
 	userID, hasUser := middleware.GetUserID(ctx)
 
@@ -81,6 +94,7 @@ func (r *mutationResolver) CreateFamily(ctx context.Context, familyName string,
 		FamilyID:   familyID,
 		Name:       caregiverName,
 		DeviceName: deviceName,
+		Role:       domain.RoleOwner,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
@@ -99,8 +113,27 @@ func (r *mutationResolver) CreateFamily(ctx context.Context, familyName string,
 		caregiver.DeviceID = deviceID
 	}
 
-	// Create family and caregiver atomically
-	err = r.store.CreateFamilyWithCaregiver(ctx, family, caregiver)
+	// Check the name and create family, first baby and caregiver in one transaction
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		exists, err := tx.FamilyNameExists(ctx, familyName)
+		if err != nil {
+			return fmt.Errorf("failed to check family name availability: %w", err)
+		}
+		if exists {
+			return errFamilyNameTaken
+		}
+		if err := tx.CreateFamilyWithCaregiver(ctx, family, baby, caregiver); err != nil {
+			return err
+		}
+		return auditAs(ctx, tx, caregiver, domain.AuditActionCreate, domain.AuditEntityFamily, family.ID, nil, mapper.FamilyToGraphQL(family))
+	})
+	if errors.Is(err, errFamilyNameTaken) {
+		r.attemptFailed(ctx, ratelimit.ActionCheckFamilyName, subjects...)
+		return &model.AuthResult{
+			Success: false,
+			Error:   stringPtr("Family name already taken"),
+		}, nil
+	}
 	if err != nil {
 		return &model.AuthResult{
 			Success: false,
@@ -108,138 +141,186 @@ func (r *mutationResolver) CreateFamily(ctx context.Context, familyName string,
 		}, nil
 	}
 
+	var deviceToken *string
+	if !hasUser {
+		deviceToken, err = r.issueDeviceToken(ctx, caregiver, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Convert to GraphQL types
 	return &model.AuthResult{
-		Success:   true,
-		Family:    mapper.FamilyToGraphQL(family),
-		Caregiver: mapper.CaregiverToGraphQL(caregiver),
-		Error:     nil,
+		Success:     true,
+		Family:      mapper.FamilyToGraphQL(family),
+		Caregiver:   mapper.CaregiverToGraphQL(caregiver),
+		Error:       nil,
+		DeviceToken: deviceToken,
 	}, nil
 }
 
 // JoinFamily is the resolver for the joinFamily field.
 func (r *mutationResolver) JoinFamily(ctx context.Context, familyName string, password string, caregiverName string, deviceID *string, deviceName *string) (*model.AuthResult, error) {
+	subjects := append(callerSubjects(ctx, deviceID), ratelimit.Family(familyName))
+	if err := r.allowAttempt(ctx, ratelimit.ActionJoinFamily, subjects...); err != nil {
+		return nil, err
+	}
+
+	// An unknown family and a wrong password get the same answer so the response can't be
+	// used to find out which family names exist
 	family, err := r.store.GetFamilyByName(ctx, familyName)
 	if err != nil {
+		r.attemptFailed(ctx, ratelimit.ActionJoinFamily, subjects...)
 		return &model.AuthResult{
 			Success: false,
-			Error:   stringPtr("Family not found"),
+			Error:   stringPtr("Incorrect family name or password"),
 		}, nil
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(family.PasswordHash), []byte(password))
 	if err != nil {
+		r.attemptFailed(ctx, ratelimit.ActionJoinFamily, subjects...)
 		return &model.AuthResult{
 			Success: false,
-			Error:   stringPtr("Incorrect password"),
+			Error:   stringPtr("Incorrect family name or password"),
 		}, nil
 	}
 
-	// Determine auth path: JWT user-based or legacy device-based
-	userID, hasUser := middleware.GetUserID(ctx)
-
-	if hasUser {
-		// User-based auth path
-		// Check if user already has a caregiver in this family
-		existingCaregiver, err := r.store.GetCaregiverByUserAndFamily(ctx, userID, family.ID)
-		if err == nil && existingCaregiver != nil {
-			// Re-authentication: user already in this family
-			return &model.AuthResult{
-				Success:   true,
-				Family:    mapper.FamilyToGraphQL(family),
-				Caregiver: mapper.CaregiverToGraphQL(existingCaregiver),
-				Error:     nil,
-			}, nil
-		}
-
-		// Create new caregiver linked to user
-		caregiverID := uuid.New()
-		now := time.Now()
-
-		caregiver := &domain.Caregiver{
-			ID:         caregiverID,
-			FamilyID:   family.ID,
-			UserID:     &userID,
-			Name:       caregiverName,
-			DeviceName: deviceName,
-			CreatedAt:  now,
-			UpdatedAt:  now,
-		}
+	r.attemptSucceeded(ctx, ratelimit.ActionJoinFamily, subjects[1:]...)
+	return r.addCaregiverToFamily(ctx, family, caregiverName, domain.RoleCaregiver, deviceID, deviceName, nil), nil
+}
 
-		err = r.store.CreateCaregiver(ctx, caregiver)
-		if err != nil {
-			return &model.AuthResult{
-				Success: false,
-				Error:   stringPtr(fmt.Sprintf("Failed to join family: %v", err)),
-			}, nil
-		}
+// JoinFamilyWithInvite is the resolver for the joinFamilyWithInvite field.
+func (r *mutationResolver) JoinFamilyWithInvite(ctx context.Context, code string, caregiverName string, deviceID *string, deviceName *string) (*model.AuthResult, error) {
+	subjects := callerSubjects(ctx, deviceID)
+	if err := r.allowAttempt(ctx, ratelimit.ActionJoinFamilyWithInvite, subjects...); err != nil {
+		return nil, err
+	}
 
+	familyInvite, err := r.store.GetFamilyInviteByCodeHash(ctx, invite.Hash(code))
+	if err != nil || !familyInvite.Active(time.Now()) {
+		r.attemptFailed(ctx, ratelimit.ActionJoinFamilyWithInvite, subjects...)
 		return &model.AuthResult{
-			Success:   true,
-			Family:    mapper.FamilyToGraphQL(family),
-			Caregiver: mapper.CaregiverToGraphQL(caregiver),
-			Error:     nil,
+			Success: false,
+			Error:   stringPtr("Invite is invalid or has expired"),
 		}, nil
 	}
 
-	// Legacy device-based auth path
-	if deviceID == nil || *deviceID == "" {
+	family, err := r.store.GetFamilyByID(ctx, familyInvite.FamilyID)
+	if err != nil {
 		return &model.AuthResult{
 			Success: false,
-			Error:   stringPtr("deviceId is required for device-based authentication"),
+			Error:   stringPtr("Family not found"),
 		}, nil
 	}
 
-	// Check if device already exists
-	existingCaregiver, err := r.store.GetCaregiverByDeviceID(ctx, *deviceID)
+	r.attemptSucceeded(ctx, ratelimit.ActionJoinFamilyWithInvite, subjects[1:]...)
+	return r.addCaregiverToFamily(ctx, family, caregiverName, familyInvite.Role, deviceID, deviceName, redeemInvite(ctx, familyInvite)), nil
+}
 
-	// Case 1: Device exists in THIS family → Re-authentication (allow it!)
-	if err == nil && existingCaregiver != nil && existingCaregiver.FamilyID == family.ID {
-		return &model.AuthResult{
-			Success:   true,
-			Family:    mapper.FamilyToGraphQL(family),
-			Caregiver: mapper.CaregiverToGraphQL(existingCaregiver),
-			Error:     nil,
-		}, nil
+// CreateInvite is the resolver for the createInvite field.
+func (r *mutationResolver) CreateInvite(ctx context.Context, input *model.CreateInviteInput) (*model.CreatedInvite, error) {
+	caregiverID, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily)
+	if err != nil {
+		return nil, err
 	}
 
-	// Case 2: Device exists in DIFFERENT family → Block it
-	if err == nil && existingCaregiver != nil && existingCaregiver.FamilyID != family.ID {
-		return &model.AuthResult{
-			Success: false,
-			Error:   stringPtr("Device already belongs to a different family. Leave that family first."),
-		}, nil
+	ttl := domain.DefaultInviteTTL
+	var maxUses *int
+	role := domain.RoleCaregiver
+	if input != nil {
+		if input.ExpiresInHours != nil {
+			ttl = time.Duration(*input.ExpiresInHours) * time.Hour
+			if ttl <= 0 || ttl > domain.MaxInviteTTL {
+				return nil, fmt.Errorf("expiresInHours must be between 1 and %d", int(domain.MaxInviteTTL.Hours()))
+			}
+		}
+		if input.MaxUses != nil {
+			if *input.MaxUses < 1 {
+				return nil, fmt.Errorf("maxUses must be at least 1")
+			}
+			v := int(*input.MaxUses)
+			maxUses = &v
+		}
+		if input.Role != nil {
+			role = caregiverRoleFromGraphQL(*input.Role)
+		}
+	}
+
+	code, err := invite.NewCode()
+	if err != nil {
+		return nil, err
 	}
 
-	// Case 3: Device doesn't exist → Create new caregiver
-	caregiverID := uuid.New()
 	now := time.Now()
+	familyInvite := &domain.FamilyInvite{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		CodeHash:  invite.Hash(code),
+		CreatedBy: &caregiverID,
+		ExpiresAt: now.Add(ttl),
+		MaxUses:   maxUses,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	created := mapper.FamilyInviteToGraphQL(familyInvite, now)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateFamilyInvite(ctx, familyInvite); err != nil {
+			return fmt.Errorf("failed to create invite: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityInvite, familyInvite.ID, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	caregiver := &domain.Caregiver{
-		ID:         caregiverID,
-		FamilyID:   family.ID,
-		Name:       caregiverName,
-		DeviceID:   deviceID,
-		DeviceName: deviceName,
-		CreatedAt:  now,
-		UpdatedAt:  now,
+	return &model.CreatedInvite{
+		Invite: created,
+		Code:   code,
+		Link:   r.inviteLink(code),
+	}, nil
+}
+
+// RevokeInvite is the resolver for the revokeInvite field.
+func (r *mutationResolver) RevokeInvite(ctx context.Context, id string) (*model.FamilyInvite, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily)
+	if err != nil {
+		return nil, err
 	}
 
-	err = r.store.CreateCaregiver(ctx, caregiver)
+	inviteID, err := uuid.Parse(id)
 	if err != nil {
-		return &model.AuthResult{
-			Success: false,
-			Error:   stringPtr(fmt.Sprintf("Failed to join family: %v", err)),
-		}, nil
+		return nil, fmt.Errorf("invalid invite ID: %w", err)
 	}
 
-	return &model.AuthResult{
-		Success:   true,
-		Family:    mapper.FamilyToGraphQL(family),
-		Caregiver: mapper.CaregiverToGraphQL(caregiver),
-		Error:     nil,
-	}, nil
+	familyInvite, err := r.store.GetFamilyInviteByID(ctx, inviteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if familyInvite.FamilyID != familyID {
+		return nil, fmt.Errorf("invite not found")
+	}
+
+	now := time.Now()
+	before := mapper.FamilyInviteToGraphQL(familyInvite, now)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.RevokeFamilyInvite(ctx, inviteID, now); err != nil {
+			return fmt.Errorf("failed to revoke invite: %w", err)
+		}
+
+		familyInvite, err = tx.GetFamilyInviteByID(ctx, inviteID)
+		if err != nil {
+			return fmt.Errorf("failed to reload invite: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionRevoke, domain.AuditEntityInvite, inviteID, before, mapper.FamilyInviteToGraphQL(familyInvite, now))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.FamilyInviteToGraphQL(familyInvite, now), nil
 }
 
 // LinkCaregiverToUser is the resolver for the linkCaregiverToUser field.
@@ -255,6 +336,12 @@ func (r *mutationResolver) LinkCaregiverToUser(ctx context.Context, caregiverID
 		return nil, fmt.Errorf("invalid caregiver ID: %w", err)
 	}
 
+	// Only the caregiver's own device can hand it over: the request must carry a valid
+	// device token issued to that caregiver. Caregiver IDs are visible to the whole family.
+	if deviceCaregiverID, ok := middleware.GetDeviceCaregiverID(ctx); !ok || deviceCaregiverID != caregiverUUID {
+		return nil, fmt.Errorf("%w: a caregiver can only be linked from its own device", middleware.ErrForbidden)
+	}
+
 	// Verify caregiver exists
 	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverUUID)
 	if err != nil {
@@ -266,884 +353,1837 @@ func (r *mutationResolver) LinkCaregiverToUser(ctx context.Context, caregiverID
 		return nil, fmt.Errorf("caregiver is already linked to a user")
 	}
 
-	// Link caregiver to user
-	if err := r.store.LinkCaregiverToUser(ctx, caregiverUUID, userID); err != nil {
-		return nil, fmt.Errorf("failed to link caregiver to user: %w", err)
-	}
+	// Link caregiver to user and reload to get updated state. The caller may not be signed
+	// in to the family yet, so the caregiver is recorded as making the change.
+	before := mapper.CaregiverToGraphQL(caregiver)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.LinkCaregiverToUser(ctx, caregiverUUID, userID); err != nil {
+			return fmt.Errorf("failed to link caregiver to user: %w", err)
+		}
 
-	// Reload to get updated state
-	caregiver, err = r.store.GetCaregiverByID(ctx, caregiverUUID)
+		caregiver, err = tx.GetCaregiverByID(ctx, caregiverUUID)
+		if err != nil {
+			return fmt.Errorf("failed to reload caregiver: %w", err)
+		}
+		return auditAs(ctx, tx, caregiver, domain.AuditActionUpdate, domain.AuditEntityCaregiver, caregiver.ID, before, mapper.CaregiverToGraphQL(caregiver))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to reload caregiver: %w", err)
+		return nil, err
 	}
 
 	return mapper.CaregiverToGraphQL(caregiver), nil
 }
 
-// UpdateBabyName is the resolver for the updateBabyName field.
-func (r *mutationResolver) UpdateBabyName(ctx context.Context, babyName string) (*model.Family, error) {
-	_, familyID, err := middleware.RequireAuth(ctx)
+// SetCaregiverRole is the resolver for the setCaregiverRole field.
+func (r *mutationResolver) SetCaregiverRole(ctx context.Context, caregiverID string, role model.CaregiverRole) (*model.Caregiver, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return nil, err
 	}
 
-	family, err := r.store.GetFamilyByID(ctx, familyID)
+	caregiver, err := r.familyCaregiver(ctx, familyID, caregiverID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get family: %w", err)
+		return nil, err
 	}
 
-	family.BabyName = babyName
-	family.UpdatedAt = time.Now()
-
-	if err := r.store.UpdateFamily(ctx, family); err != nil {
-		return nil, fmt.Errorf("failed to update baby name: %w", err)
+	newRole := caregiverRoleFromGraphQL(role)
+	before := mapper.CaregiverToGraphQL(caregiver)
+	after := *before
+	after.Role = role
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if newRole != domain.RoleOwner {
+			if err := keepOwner(ctx, tx, familyID, caregiver.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.SetCaregiverRole(ctx, caregiver.ID, newRole); err != nil {
+			return err
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityCaregiver, caregiver.ID, before, &after)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return mapper.FamilyToGraphQL(family), nil
+	return &after, nil
 }
 
-// LeaveFamily is the resolver for the leaveFamily field.
-func (r *mutationResolver) LeaveFamily(ctx context.Context) (bool, error) {
-	caregiverID, _, err := middleware.RequireAuth(ctx)
+// RemoveCaregiver is the resolver for the removeCaregiver field.
+func (r *mutationResolver) RemoveCaregiver(ctx context.Context, caregiverID string) (bool, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily)
 	if err != nil {
-		return false, fmt.Errorf("authentication required: %w", err)
+		return false, err
 	}
 
-	if err := r.store.DeleteCaregiver(ctx, caregiverID); err != nil {
-		return false, fmt.Errorf("failed to leave family: %w", err)
+	caregiver, err := r.familyCaregiver(ctx, familyID, caregiverID)
+	if err != nil {
+		return false, err
 	}
 
+	if err := r.removeCaregiver(ctx, caregiver); err != nil {
+		return false, err
+	}
 	return true, nil
 }
 
-// StartCareSession is the resolver for the startCareSession field.
-func (r *mutationResolver) StartCareSession(ctx context.Context) (*model.CareSession, error) {
-	caregiverID, familyID, err := middleware.RequireAuth(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
-	}
-
-	// Check for existing in-progress session
-	existing, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
+// RotateDeviceToken is the resolver for the rotateDeviceToken field.
+func (r *mutationResolver) RotateDeviceToken(ctx context.Context) (string, error) {
+	caregiverID, _, err := middleware.RequireAuth(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check for existing session: %w", err)
-	}
-	if existing != nil {
-		return nil, fmt.Errorf("a care session is already in progress")
+		return "", fmt.Errorf("authentication required: %w", err)
 	}
-
-	now := time.Now()
-	session := &domain.CareSession{
-		ID:          uuid.New(),
-		CaregiverID: caregiverID,
-		FamilyID:    familyID,
-		Status:      domain.StatusInProgress,
-		StartedAt:   now,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	if r.deviceTokens == nil {
+		return "", fmt.Errorf("device tokens are not enabled")
 	}
 
-	if err := r.store.CreateCareSession(ctx, session); err != nil {
-		return nil, fmt.Errorf("failed to create care session: %w", err)
+	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get caregiver: %w", err)
 	}
 
-	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverID)
+	token, err := r.issueDeviceToken(ctx, caregiver, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get caregiver: %w", err)
+		return "", err
 	}
 
-	return &model.CareSession{
-		ID:         session.ID.String(),
-		Caregiver:  mapper.CaregiverToGraphQL(caregiver),
-		FamilyID:   familyID.String(),
-		Status:     model.CareSessionStatusInProgress,
-		StartedAt:  session.StartedAt,
-		Activities: []model.Activity{},
-		Summary:    &model.CareSessionSummary{},
-	}, nil
+	return *token, nil
 }
 
-// ParseVoiceInput is the resolver for the parseVoiceInput field.
-func (r *mutationResolver) ParseVoiceInput(ctx context.Context, audioFile graphql.Upload) (*model.ParsedVoiceResult, error) {
-	// Step 1: Initialize clients
-	whisperClient := ai.NewWhisperClient()
-	claudeClient := ai.NewClaudeClient(os.Getenv("CLAUDE_API_KEY"))
-
-	// Step 2: Transcribe audio using Whisper
-	fmt.Printf("📤 Uploading audio: %s (%d bytes, %s)\n", audioFile.Filename, audioFile.Size, audioFile.ContentType)
-	transcribedText, err := whisperClient.TranscribeAudio(ctx, audioFile.File, audioFile.Filename)
+// RevokeDeviceToken is the resolver for the revokeDeviceToken field.
+func (r *mutationResolver) RevokeDeviceToken(ctx context.Context, caregiverID string) (bool, error) {
+	callerID, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
-		fmt.Printf("❌ Whisper transcription failed: %v\n", err)
-		return &model.ParsedVoiceResult{
-			Success: false,
-			RawText: "",
-			Errors:  []string{fmt.Sprintf("Failed to transcribe audio: %v", err)},
-		}, nil
+		return false, fmt.Errorf("authentication required: %w", err)
 	}
-	fmt.Printf("✅ Whisper transcription: %q\n", transcribedText)
 
-	// Step 3: Parse transcribed text with Claude
-	timezone := middleware.GetTimezone(ctx)
-	claudeResponse, err := claudeClient.ParseVoiceInput(transcribedText, time.Now(), timezone)
+	caregiver, err := r.familyCaregiver(ctx, familyID, caregiverID)
 	if err != nil {
-		fmt.Printf("❌ Claude parsing failed: %v\n", err)
-		return &model.ParsedVoiceResult{
-			Success: false,
-			RawText: transcribedText,
-			Errors:  []string{fmt.Sprintf("Failed to parse voice input: %v", err)},
-		}, nil
+		return false, err
 	}
-	fmt.Printf("✅ Claude response: %s\n", claudeResponse)
 
-	// Step 4: Parse Claude's JSON response
-	var activities []map[string]interface{}
-	if err := json.Unmarshal([]byte(claudeResponse), &activities); err != nil {
-		return &model.ParsedVoiceResult{
-			Success: false,
-			RawText: transcribedText,
-			Errors:  []string{fmt.Sprintf("Failed to parse Claude response: %v", err)},
-		}, nil
+	// Anyone can sign out their own device; signing out others is for owners
+	if caregiver.ID != callerID {
+		if _, _, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily); err != nil {
+			return false, err
+		}
 	}
 
-	// Step 5: Convert to GraphQL types
-	parsedActivities, conversionErrors := ai.ConvertToParsedActivities(activities)
-
-	// Step 6: Return result
-	fmt.Printf("✅ Successfully parsed %d activities\n", len(parsedActivities))
-	for i, activity := range parsedActivities {
-		fmt.Printf("   Activity %d: Type=%s\n", i+1, activity.ActivityType)
-	}
-	if len(conversionErrors) > 0 {
-		fmt.Printf("⚠️  Conversion errors: %v\n", conversionErrors)
+	// Advancing the generation without issuing a token signs the device out
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if _, err := tx.RotateDeviceToken(ctx, caregiver.ID); err != nil {
+			return fmt.Errorf("failed to revoke device token: %w", err)
+		}
+		snapshot := mapper.CaregiverToGraphQL(caregiver)
+		return audit(ctx, tx, domain.AuditActionRevoke, domain.AuditEntityCaregiver, caregiver.ID, snapshot, snapshot)
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return &model.ParsedVoiceResult{
-		Success:          true,
-		RawText:          transcribedText,
-		ParsedActivities: parsedActivities,
-		Errors:           conversionErrors,
-	}, nil
+	return true, nil
 }
 
-// AddActivities is the resolver for the addActivities field.
-func (r *mutationResolver) AddActivities(ctx context.Context, activities []*model.ActivityInput) (*model.CareSession, error) {
-	// Step 1: Get authenticated user
-	caregiverID, familyID, err := middleware.RequireAuth(ctx)
+// UpdateBabyName is the resolver for the updateBabyName field.
+func (r *mutationResolver) UpdateBabyName(ctx context.Context, babyName string) (*model.Family, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return nil, err
 	}
 
-	// Step 2: Get or create in_progress session
-	session, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
+	family, err := r.store.GetFamilyByID(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get in-progress session: %w", err)
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+
+	babies, err := r.store.GetBabiesForFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get babies: %w", err)
 	}
 
-	// If a different caregiver is adding activities, auto-complete the old session (pass the baton)
-	if session != nil && session.CaregiverID != caregiverID {
-		now := time.Now()
-		session.Status = domain.StatusCompleted
-		session.CompletedAt = &now
-		session.UpdatedAt = now
-		if err := r.store.UpdateCareSession(ctx, session); err != nil {
-			return nil, fmt.Errorf("failed to complete previous session: %w", err)
+	now := time.Now()
+	before := mapper.FamilyToGraphQL(family)
+	family.BabyName = babyName
+	family.UpdatedAt = now
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.UpdateFamily(ctx, family); err != nil {
+			return fmt.Errorf("failed to update baby name: %w", err)
 		}
-		// Auto-end any active sleep activities in the old session
-		oldActivities, err := r.store.GetActivitiesForSession(ctx, session.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get activities for previous session: %w", err)
+		if err := audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityFamily, family.ID, before, mapper.FamilyToGraphQL(family)); err != nil {
+			return err
 		}
-		for _, activity := range oldActivities {
-			if activity.ActivityType != domain.ActivityTypeSleep {
-				continue
-			}
-			sleepDetails, err := r.store.GetSleepDetails(ctx, activity.ID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get sleep details: %w", err)
+
+		// babyName mirrors the first baby, so keep its record in sync
+		if len(babies) > 0 {
+			baby := babies[0]
+			beforeBaby := mapper.BabyToGraphQL(baby)
+			baby.Name = babyName
+			baby.UpdatedAt = now
+			if err := tx.UpdateBaby(ctx, baby); err != nil {
+				return fmt.Errorf("failed to update baby name: %w", err)
 			}
-			if sleepDetails.EndTime != nil {
-				continue
-			}
-			sleepDetails.EndTime = &now
-			duration := int(now.Sub(sleepDetails.StartTime).Minutes())
-			sleepDetails.DurationMinutes = &duration
-			sleepDetails.UpdatedAt = now
-			if err := r.store.UpdateSleepDetails(ctx, sleepDetails); err != nil {
-				return nil, fmt.Errorf("failed to end active sleep: %w", err)
-			}
-			fmt.Printf("💤 Auto-ended sleep activity %s during handoff (duration: %d minutes)\n", activity.ID, duration)
-		}
-		fmt.Printf("🤝 Session handoff: completed session %s, new caregiver taking over\n", session.ID)
-		session = nil
-	}
-
-	if session == nil {
-		// No active session, create one
-		now := time.Now()
-		session = &domain.CareSession{
-			ID:          uuid.New(),
-			CaregiverID: caregiverID,
-			FamilyID:    familyID,
-			Status:      domain.StatusInProgress,
-			StartedAt:   now,
-			CreatedAt:   now,
-			UpdatedAt:   now,
+			return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityBaby, baby.ID, beforeBaby, mapper.BabyToGraphQL(baby))
 		}
-		if err := r.store.CreateCareSession(ctx, session); err != nil {
-			return nil, fmt.Errorf("failed to create care session: %w", err)
-		}
-		fmt.Printf("✨ Created new care session: %s\n", session.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Step 3: Add each activity
-	fmt.Printf("📝 Adding %d activities to session %s\n", len(activities), session.ID)
-	for i, activityInput := range activities {
-		now := time.Now()
+	return mapper.FamilyToGraphQL(family), nil
+}
 
-		// Convert GraphQL ActivityType (FEED, DIAPER, SLEEP) to domain ActivityType (feed, diaper, sleep)
-		activityType := strings.ToLower(string(activityInput.ActivityType))
+// UpdateFamilyTimezone is the resolver for the updateFamilyTimezone field.
+func (r *mutationResolver) UpdateFamilyTimezone(ctx context.Context, timezone string) (*model.Family, error) {
+	timezone = strings.TrimSpace(timezone)
+	if err := domain.ValidateTimezone(timezone); err != nil {
+		return nil, err
+	}
 
-		activity := &domain.Activity{
-			ID:            uuid.New(),
-			CareSessionID: session.ID,
-			ActivityType:  domain.ActivityType(activityType),
-			CreatedAt:     now,
-			UpdatedAt:     now,
-		}
+	return r.updateFamilySettings(ctx, func(family *domain.Family) {
+		family.Timezone = timezone
+	})
+}
 
-		if err := r.store.CreateActivity(ctx, activity); err != nil {
-			return nil, fmt.Errorf("failed to create activity: %w", err)
+// SetTravelMode is the resolver for the setTravelMode field.
+func (r *mutationResolver) SetTravelMode(ctx context.Context, input *model.TravelModeInput) (*model.Family, error) {
+	var travel *domain.TravelMode
+	if input != nil {
+		var err error
+		if travel, err = travelModeFromInput(input); err != nil {
+			return nil, err
 		}
+	}
 
-		// Create activity details based on type
-		switch activityInput.ActivityType {
-		case model.ActivityTypeFeed:
-			if activityInput.FeedDetails == nil {
-				return nil, fmt.Errorf("feed activity requires feedDetails")
-			}
-
-			// Convert GraphQL FeedType (BREAST_MILK, FORMULA, SOLIDS) to domain FeedType (breast_milk, formula, solids)
-			var feedType *domain.FeedType
-			if activityInput.FeedDetails.FeedType != nil {
-				ft := domain.FeedType(strings.ToLower(string(*activityInput.FeedDetails.FeedType)))
-				feedType = &ft
-			}
-
-			// Convert *int32 to *int for AmountMl
-			var amountMl *int
-			if activityInput.FeedDetails.AmountMl != nil {
-				val := int(*activityInput.FeedDetails.AmountMl)
-				amountMl = &val
-			}
-
-			// Convert SolidsUnit to string for QuantityUnit
-			var quantityUnit *string
-			if activityInput.FeedDetails.QuantityUnit != nil {
-				qu := strings.ToLower(string(*activityInput.FeedDetails.QuantityUnit))
-				quantityUnit = &qu
-			}
-
-			details := &domain.FeedDetails{
-				ID:           uuid.New(),
-				ActivityID:   activity.ID,
-				StartTime:    activityInput.FeedDetails.StartTime,
-				EndTime:      activityInput.FeedDetails.EndTime,
-				AmountMl:     amountMl,
-				FeedType:     feedType,
-				FoodName:     activityInput.FeedDetails.FoodName,
-				Quantity:     activityInput.FeedDetails.Quantity,
-				QuantityUnit: quantityUnit,
-				CreatedAt:    now,
-				UpdatedAt:    now,
-			}
-			fmt.Printf("📝 Creating feed details for activity %s (feed_type: %v)\n", activity.ID, feedType)
-			if err := r.store.CreateFeedDetails(ctx, details); err != nil {
-				return nil, fmt.Errorf("failed to create feed details: %w", err)
-			}
-			fmt.Printf("✅ Feed details created successfully\n")
-
-		case model.ActivityTypeDiaper:
-			if activityInput.DiaperDetails == nil {
-				return nil, fmt.Errorf("diaper activity requires diaperDetails")
-			}
-			hadPee := false
-			if activityInput.DiaperDetails.HadPee != nil {
-				hadPee = *activityInput.DiaperDetails.HadPee
-			}
-			details := &domain.DiaperDetails{
-				ID:         uuid.New(),
-				ActivityID: activity.ID,
-				ChangedAt:  activityInput.DiaperDetails.ChangedAt,
-				HadPoop:    activityInput.DiaperDetails.HadPoop,
-				HadPee:     hadPee,
-				CreatedAt:  now,
-				UpdatedAt:  now,
-			}
-			if err := r.store.CreateDiaperDetails(ctx, details); err != nil {
-				return nil, fmt.Errorf("failed to create diaper details: %w", err)
-			}
+	return r.updateFamilySettings(ctx, func(family *domain.Family) {
+		family.Travel = travel
+	})
+}
 
-		case model.ActivityTypeSleep:
-			if activityInput.SleepDetails == nil {
-				return nil, fmt.Errorf("sleep activity requires sleepDetails")
-			}
-			var durationMinutes *int
-			if activityInput.SleepDetails.EndTime != nil {
-				duration := int(activityInput.SleepDetails.EndTime.Sub(activityInput.SleepDetails.StartTime).Minutes())
-				durationMinutes = &duration
-			}
-			details := &domain.SleepDetails{
-				ID:              uuid.New(),
-				ActivityID:      activity.ID,
-				StartTime:       activityInput.SleepDetails.StartTime,
-				EndTime:         activityInput.SleepDetails.EndTime,
-				DurationMinutes: durationMinutes,
-				CreatedAt:       now,
-				UpdatedAt:       now,
-			}
-			if err := r.store.CreateSleepDetails(ctx, details); err != nil {
-				return nil, fmt.Errorf("failed to create sleep details: %w", err)
-			}
+// SetPredictionModel is the resolver for the setPredictionModel field.
+func (r *mutationResolver) SetPredictionModel(ctx context.Context, predictionModel model.PredictionModel, halfLifeHours *int32) (*model.Family, error) {
+	var halfLife time.Duration
+	if halfLifeHours != nil {
+		if predictionModel != model.PredictionModelRecencyWeighted {
+			return nil, fmt.Errorf("halfLifeHours only applies to the RECENCY_WEIGHTED model")
 		}
-
-		fmt.Printf("   ✅ Activity %d: %s\n", i+1, activity.ActivityType)
+		if *halfLifeHours < 1 || *halfLifeHours > maxPredictionHalfLifeHours {
+			return nil, fmt.Errorf("halfLifeHours must be between 1 and %d", maxPredictionHalfLifeHours)
+		}
+		halfLife = time.Duration(*halfLifeHours) * time.Hour
 	}
 
-	// Step 4: Invalidate prediction cache so next query recomputes with new data
-	_ = r.store.DeletePredictionsForFamily(ctx, familyID)
-
-	// Step 5: Return the updated session
-	return mapper.CareSessionToGraphQL(session), nil
+	return r.updateFamilySettings(ctx, func(family *domain.Family) {
+		family.PredictionModel = domain.PredictionModel(strings.ToLower(string(predictionModel)))
+		family.PredictionHalfLife = halfLife
+	})
 }
 
-// EndActivity is the resolver for the endActivity field.
-func (r *mutationResolver) EndActivity(ctx context.Context, activityID string, endTime *time.Time) (model.Activity, error) {
-	// Require authentication
-	_, familyID, err := middleware.RequireAuth(ctx)
+// AddBaby is the resolver for the addBaby field.
+func (r *mutationResolver) AddBaby(ctx context.Context, name string, birthDate *time.Time, sex *model.BabySex) (*model.Baby, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return nil, err
 	}
 
-	activityUUID, err := uuid.Parse(activityID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid activity ID: %w", err)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("baby name is required")
 	}
 
-	// Get the activity
-	activity, err := r.store.GetActivityByID(ctx, activityUUID)
+	babies, err := r.store.GetBabiesForFamily(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activity: %w", err)
-	}
-
-	// Only sleep activities can be ended
-	if activity.ActivityType != domain.ActivityTypeSleep {
-		return nil, fmt.Errorf("only sleep activities can be ended")
+		return nil, fmt.Errorf("failed to get babies: %w", err)
 	}
-
-	// Get sleep details and update end time
-	sleepDetails, err := r.store.GetSleepDetails(ctx, activityUUID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get sleep details: %w", err)
+	if findBabyByName(babies, name) != nil {
+		return nil, fmt.Errorf("a baby named %s already exists in this family", name)
 	}
 
 	now := time.Now()
-	if endTime == nil {
-		endTime = &now
+	baby := &domain.Baby{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyBabyProfile(baby, birthDate, sex); err != nil {
+		return nil, err
 	}
 
-	sleepDetails.EndTime = endTime
-	duration := int(endTime.Sub(sleepDetails.StartTime).Minutes())
-	sleepDetails.DurationMinutes = &duration
-	sleepDetails.UpdatedAt = now
-
-	if err := r.store.UpdateSleepDetails(ctx, sleepDetails); err != nil {
-		return nil, fmt.Errorf("failed to update sleep details: %w", err)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateBaby(ctx, baby); err != nil {
+			return fmt.Errorf("failed to add baby: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityBaby, baby.ID, nil, mapper.BabyToGraphQL(baby))
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("✅ Ended sleep activity %s at %s (duration: %d minutes)\n", activityID, endTime.Format(time.RFC3339), duration)
-
-	// Invalidate prediction cache
-	_ = r.store.DeletePredictionsForFamily(ctx, familyID)
-
-	// Return the sleep activity with details
-	return &model.SleepActivity{
-		ID:           activity.ID.String(),
-		ActivityType: model.ActivityType(activity.ActivityType),
-		CreatedAt:    activity.CreatedAt,
-		SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
-	}, nil
+	return mapper.BabyToGraphQL(baby), nil
 }
 
-// CompleteCareSession is the resolver for the completeCareSession field.
-func (r *mutationResolver) CompleteCareSession(ctx context.Context, notes *string) (*model.CareSession, error) {
-	// Require authentication
-	_, familyID, err := middleware.RequireAuth(ctx)
+// UpdateBaby is the resolver for the updateBaby field.
+func (r *mutationResolver) UpdateBaby(ctx context.Context, id string, name *string, birthDate *time.Time, sex *model.BabySex) (*model.Baby, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return nil, err
 	}
 
-	// Get the current in-progress session
-	session, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
+	baby, err := r.resolveBaby(ctx, familyID, &id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get in-progress session: %w", err)
-	}
-
-	if session == nil {
-		return nil, fmt.Errorf("no active session to complete")
-	}
-
-	// Update session to completed
-	now := time.Now()
-	session.Status = domain.StatusCompleted
-	session.CompletedAt = &now
-	session.Notes = notes
-	session.UpdatedAt = now
-
-	if err := r.store.UpdateCareSession(ctx, session); err != nil {
-		return nil, fmt.Errorf("failed to complete care session: %w", err)
+		return nil, err
 	}
+	before := mapper.BabyToGraphQL(baby)
 
-	// End any active sleep activities
-	activities, err := r.store.GetActivitiesForSession(ctx, session.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get activities for session: %w", err)
-	}
-	for _, activity := range activities {
-		if activity.ActivityType != domain.ActivityTypeSleep {
-			continue
+	// Only provided fields are updated
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return nil, fmt.Errorf("baby name is required")
 		}
-		sleepDetails, err := r.store.GetSleepDetails(ctx, activity.ID)
+		babies, err := r.store.GetBabiesForFamily(ctx, familyID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get sleep details: %w", err)
-		}
-		if sleepDetails.EndTime != nil {
-			continue
+			return nil, fmt.Errorf("failed to get babies: %w", err)
 		}
-		sleepDetails.EndTime = &now
-		duration := int(now.Sub(sleepDetails.StartTime).Minutes())
-		sleepDetails.DurationMinutes = &duration
-		sleepDetails.UpdatedAt = now
-		if err := r.store.UpdateSleepDetails(ctx, sleepDetails); err != nil {
-			return nil, fmt.Errorf("failed to end active sleep: %w", err)
+		if other := findBabyByName(babies, trimmed); other != nil && other.ID != baby.ID {
+			return nil, fmt.Errorf("a baby named %s already exists in this family", trimmed)
 		}
-		fmt.Printf("💤 Auto-ended sleep activity %s (duration: %d minutes)\n", activity.ID, duration)
+		baby.Name = trimmed
 	}
+	if err := applyBabyProfile(baby, birthDate, sex); err != nil {
+		return nil, err
+	}
+	baby.UpdatedAt = time.Now()
 
-	fmt.Printf("✅ Completed care session %s\n", session.ID)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.UpdateBaby(ctx, baby); err != nil {
+			return fmt.Errorf("failed to update baby: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityBaby, baby.ID, before, mapper.BabyToGraphQL(baby))
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return mapper.CareSessionToGraphQL(session), nil
+	return mapper.BabyToGraphQL(baby), nil
 }
 
-// DeleteActivity is the resolver for the deleteActivity field.
-func (r *mutationResolver) DeleteActivity(ctx context.Context, activityID string) (bool, error) {
-	// Require authentication
-	_, familyID, err := middleware.RequireAuth(ctx)
+// LeaveFamily is the resolver for the leaveFamily field.
+func (r *mutationResolver) LeaveFamily(ctx context.Context) (bool, error) {
+	caregiverID, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return false, fmt.Errorf("authentication required: %w", err)
 	}
 
-	activityUUID, err := uuid.Parse(activityID)
+	caregiver, err := r.familyCaregiver(ctx, familyID, caregiverID.String())
 	if err != nil {
-		return false, fmt.Errorf("invalid activity ID: %w", err)
+		return false, err
 	}
 
-	// Delete the activity (cascades to details via DB foreign key)
-	if err := r.store.DeleteActivity(ctx, activityUUID); err != nil {
-		return false, fmt.Errorf("failed to delete activity: %w", err)
+	if err := r.removeCaregiver(ctx, caregiver); err != nil {
+		return false, err
 	}
 
-	// Invalidate prediction cache
-	_ = r.store.DeletePredictionsForFamily(ctx, familyID)
-
-	fmt.Printf("🗑️  Deleted activity %s\n", activityID)
-
 	return true, nil
 }
 
-// UpdateActivity is the resolver for the updateActivity field.
-func (r *mutationResolver) UpdateActivity(ctx context.Context, activityID string, input model.ActivityInput) (model.Activity, error) {
-	// Require authentication
-	_, familyID, err := middleware.RequireAuth(ctx)
+// StartCareSession is the resolver for the startCareSession field.
+func (r *mutationResolver) StartCareSession(ctx context.Context) (*model.CareSession, error) {
+	caregiverID, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return nil, err
 	}
 
-	activityUUID, err := uuid.Parse(activityID)
+	// Check for existing in-progress session
+	existing, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid activity ID: %w", err)
+		return nil, fmt.Errorf("failed to check for existing session: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("a care session is already in progress")
 	}
 
-	// Get the existing activity
-	activity, err := r.store.GetActivityByID(ctx, activityUUID)
+	now := time.Now()
+	session := &domain.CareSession{
+		ID:          uuid.New(),
+		CaregiverID: caregiverID,
+		FamilyID:    familyID,
+		Status:      domain.StatusInProgress,
+		StartedAt:   now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateCareSession(ctx, session); err != nil {
+			return fmt.Errorf("failed to create care session: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityCareSession, session.ID, nil, mapper.CareSessionToGraphQL(session))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activity: %w", err)
+		return nil, err
 	}
 
-	// Invalidate prediction cache after successful update
-	defer func() { _ = r.store.DeletePredictionsForFamily(ctx, familyID) }()
+	r.publishCareSessionUpdated(familyID, session.ID)
+	r.emitSessionWebhook(ctx, domain.WebhookEventSessionStarted, session)
 
-	now := time.Now()
+	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caregiver: %w", err)
+	}
 
-	switch activity.ActivityType {
-	case domain.ActivityTypeFeed:
-		if input.FeedDetails == nil {
-			return nil, fmt.Errorf("feed activity requires feedDetails")
-		}
+	return &model.CareSession{
+		ID:         session.ID.String(),
+		Caregiver:  mapper.CaregiverToGraphQL(caregiver),
+		FamilyID:   familyID.String(),
+		Status:     model.CareSessionStatusInProgress,
+		StartedAt:  session.StartedAt,
+		Activities: []model.Activity{},
+		Summary:    &model.CareSessionSummary{},
+	}, nil
+}
 
-		feedDetails, err := r.store.GetFeedDetails(ctx, activityUUID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get feed details: %w", err)
-		}
+// ParseVoiceInput is the resolver for the parseVoiceInput field.
+func (r *mutationResolver) ParseVoiceInput(ctx context.Context, audioFile graphql.Upload) (*model.ParsedVoiceResult, error) {
+	// Step 1: Initialize clients
+	whisperClient := ai.NewWhisperClient()
+	claudeClient := ai.NewClaudeClient(os.Getenv("CLAUDE_API_KEY"))
 
-		// Update fields
-		feedDetails.StartTime = input.FeedDetails.StartTime
-		feedDetails.EndTime = input.FeedDetails.EndTime
+	// Step 2: Transcribe audio using Whisper
+	fmt.Printf("📤 Uploading audio: %s (%d bytes, %s)\n", audioFile.Filename, audioFile.Size, audioFile.ContentType)
+	transcribedText, err := whisperClient.TranscribeAudio(ctx, audioFile.File, audioFile.Filename)
+	if err != nil {
+		fmt.Printf("❌ Whisper transcription failed: %v\n", err)
+		return &model.ParsedVoiceResult{
+			Success: false,
+			RawText: "",
+			Errors:  []string{fmt.Sprintf("Failed to transcribe audio: %v", err)},
+		}, nil
+	}
+	fmt.Printf("✅ Whisper transcription: %q\n", transcribedText)
 
-		if input.FeedDetails.AmountMl != nil {
-			val := int(*input.FeedDetails.AmountMl)
-			feedDetails.AmountMl = &val
-		} else {
-			feedDetails.AmountMl = nil
+	// Step 3: Parse transcribed text with Claude, telling it the family's baby names and
+	// the time in the family's timezone
+	now := time.Now()
+	var babies []*domain.Baby
+	timezone := middleware.GetTimezone(ctx)
+	if _, familyID, err := middleware.RequireAuth(ctx); err == nil {
+		babies, err = r.store.GetBabiesForFamily(ctx, familyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get babies: %w", err)
 		}
-
-		if input.FeedDetails.FeedType != nil {
-			ft := domain.FeedType(strings.ToLower(string(*input.FeedDetails.FeedType)))
-			feedDetails.FeedType = &ft
-		} else {
-			feedDetails.FeedType = nil
+		if timezone, err = r.familyTimezone(ctx, familyID, now); err != nil {
+			return nil, err
 		}
+	}
+	babyNames := make([]string, len(babies))
+	babyIDsByName := make(map[string]string, len(babies))
+	for i, baby := range babies {
+		babyNames[i] = baby.Name
+		babyIDsByName[strings.ToLower(baby.Name)] = baby.ID.String()
+	}
 
-		feedDetails.FoodName = input.FeedDetails.FoodName
-		feedDetails.Quantity = input.FeedDetails.Quantity
+	claudeResponse, err := claudeClient.ParseVoiceInput(transcribedText, now, timezone, babyNames)
+	if err != nil {
+		fmt.Printf("❌ Claude parsing failed: %v\n", err)
+		return &model.ParsedVoiceResult{
+			Success: false,
+			RawText: transcribedText,
+			Errors:  []string{fmt.Sprintf("Failed to parse voice input: %v", err)},
+		}, nil
+	}
+	fmt.Printf("✅ Claude response: %s\n", claudeResponse)
 
-		if input.FeedDetails.QuantityUnit != nil {
-			qu := strings.ToLower(string(*input.FeedDetails.QuantityUnit))
-			feedDetails.QuantityUnit = &qu
-		} else {
-			feedDetails.QuantityUnit = nil
-		}
+	// Step 4: Parse Claude's JSON response
+	var activities []map[string]interface{}
+	if err := json.Unmarshal([]byte(claudeResponse), &activities); err != nil {
+		return &model.ParsedVoiceResult{
+			Success: false,
+			RawText: transcribedText,
+			Errors:  []string{fmt.Sprintf("Failed to parse Claude response: %v", err)},
+		}, nil
+	}
 
-		feedDetails.UpdatedAt = now
+	// Step 5: Convert to GraphQL types
+	parsedActivities, conversionErrors := ai.ConvertToParsedActivities(activities, babyIDsByName)
 
-		if err := r.store.UpdateFeedDetails(ctx, feedDetails); err != nil {
-			return nil, fmt.Errorf("failed to update feed details: %w", err)
-		}
+	// Step 6: Return result
+	fmt.Printf("✅ Successfully parsed %d activities\n", len(parsedActivities))
+	for i, activity := range parsedActivities {
+		fmt.Printf("   Activity %d: Type=%s\n", i+1, activity.ActivityType)
+	}
+	if len(conversionErrors) > 0 {
+		fmt.Printf("⚠️  Conversion errors: %v\n", conversionErrors)
+	}
 
-		return &model.FeedActivity{
-			ID:           activity.ID.String(),
-			ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
-			CreatedAt:    activity.CreatedAt,
-			FeedDetails:  mapper.FeedDetailsToGraphQL(feedDetails),
-		}, nil
+	return &model.ParsedVoiceResult{
+		Success:          true,
+		RawText:          transcribedText,
+		ParsedActivities: parsedActivities,
+		Errors:           conversionErrors,
+	}, nil
+}
 
-	case domain.ActivityTypeDiaper:
-		if input.DiaperDetails == nil {
-			return nil, fmt.Errorf("diaper activity requires diaperDetails")
-		}
+// AddActivities is the resolver for the addActivities field.
+func (r *mutationResolver) AddActivities(ctx context.Context, activities []*model.ActivityInput) (*model.CareSession, error) {
+	// Step 1: Get authenticated user
+	caregiverID, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return nil, err
+	}
 
-		diaperDetails, err := r.store.GetDiaperDetails(ctx, activityUUID)
+	// Skip activities applied by an earlier attempt so offline clients can retry safely
+	pending, replayed, err := r.pendingActivities(ctx, familyID, activities)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		if len(replayed) == 0 {
+			return nil, fmt.Errorf("activities were already applied and have since been deleted")
+		}
+		session, err := r.store.GetCareSessionByID(ctx, replayed[0].CareSessionID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get diaper details: %w", err)
+			return nil, fmt.Errorf("failed to get care session: %w", err)
 		}
+		return mapper.CareSessionToGraphQL(session), nil
+	}
+	activities = pending
 
-		// Update fields
-		diaperDetails.ChangedAt = input.DiaperDetails.ChangedAt
-		diaperDetails.HadPoop = input.DiaperDetails.HadPoop
-		if input.DiaperDetails.HadPee != nil {
-			diaperDetails.HadPee = *input.DiaperDetails.HadPee
+	// Resolve which baby each activity is for
+	babyIDs := make([]uuid.UUID, len(activities))
+	for i, activityInput := range activities {
+		baby, err := r.resolveBaby(ctx, familyID, activityInput.BabyID)
+		if err != nil {
+			return nil, err
+		}
+		babyIDs[i] = baby.ID
+	}
+
+	// Check medication doses against the family's safety limits before writing anything
+	doseChecks, err := r.checkMedicationDoses(ctx, familyID, activities, babyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Steps 2-4 run in one transaction so a failure part-way through never leaves an
+	// activity without its details or a half-finished handoff
+	var session, handedOff *domain.CareSession
+	var started bool
+	added := make([]*domain.Activity, 0, len(activities))
+	affectedBabyIDs := uniqueIDs(babyIDs)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		// Step 2: Get or create in_progress session
+		var err error
+		session, handedOff, started, err = sessionForNewActivities(ctx, tx, caregiverID, familyID)
+		if err != nil {
+			return err
+		}
+
+		// Step 3: Add each activity
+		fmt.Printf("📝 Adding %d activities to session %s\n", len(activities), session.ID)
+		for i, activityInput := range activities {
+			id, err := clientActivityID(activityInput)
+			if err != nil {
+				return err
+			}
+
+			activity, err := createActivity(ctx, tx, id, session.ID, babyIDs[i], activityInput, doseChecks[i])
+			if err != nil {
+				return err
+			}
+
+			if err := saveIdempotencyKey(ctx, tx, familyID, activityInput.IdempotencyKey, activity.ID, domain.SyncOperationCreate); err != nil {
+				return err
+			}
+
+			added = append(added, activity)
+			fmt.Printf("   ✅ Activity %d: %s\n", i+1, activity.ActivityType)
+		}
+
+		// Step 4: Invalidate each affected baby's prediction cache so next query recomputes with new data
+		for _, babyID := range affectedBabyIDs {
+			if err := tx.DeletePredictionsForBaby(ctx, babyID); err != nil {
+				return fmt.Errorf("failed to invalidate predictions: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 5: Notify subscribers and webhooks once the transaction has committed
+	if handedOff != nil {
+		r.publishCareSessionUpdated(familyID, handedOff.ID)
+		r.emitSessionWebhook(ctx, domain.WebhookEventSessionCompleted, handedOff)
+	}
+	if started {
+		r.emitSessionWebhook(ctx, domain.WebhookEventSessionStarted, session)
+	}
+	for _, activity := range added {
+		r.publishActivityAdded(familyID, session.ID, activity.ID)
+		r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityAdded, activity)
+	}
+	r.publishCareSessionUpdated(familyID, session.ID)
+	for _, babyID := range affectedBabyIDs {
+		r.publishPredictionsChanged(familyID, babyID)
+	}
+
+	// Step 6: Return the updated session
+	return mapper.CareSessionToGraphQL(session), nil
+}
+
+// EndActivity is the resolver for the endActivity field.
+func (r *mutationResolver) EndActivity(ctx context.Context, activityID string, endTime *time.Time, expectedVersion *int32) (model.Activity, error) {
+	// Require authentication
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return nil, err
+	}
+
+	activityUUID, err := uuid.Parse(activityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid activity ID: %w", err)
+	}
+
+	// Get the activity
+	activity, err := r.familyActivity(ctx, familyID, activityUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only sleep activities can be ended
+	if activity.ActivityType != domain.ActivityTypeSleep {
+		return nil, fmt.Errorf("only sleep activities can be ended")
+	}
+
+	// Get sleep details and update end time
+	sleepDetails, err := r.store.GetSleepDetails(ctx, activityUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sleep details: %w", err)
+	}
+
+	before, err := r.loadActivity(ctx, activity)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if endTime == nil {
+		endTime = &now
+	}
+
+	sleepDetails.EndTime = endTime
+	duration := int(endTime.Sub(sleepDetails.StartTime).Minutes())
+	sleepDetails.DurationMinutes = &duration
+	sleepDetails.UpdatedAt = now
+
+	result := &model.SleepActivity{
+		ID:           activity.ID.String(),
+		BabyID:       activity.BabyID.String(),
+		ActivityType: model.ActivityType(activity.ActivityType),
+		CreatedAt:    activity.CreatedAt,
+		Version:      int32(activity.Version),
+		SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
+	}
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		version, err := tx.TouchActivity(ctx, activity.ID, int32PtrToInt(expectedVersion))
+		if err != nil {
+			return err
+		}
+		result.Version = int32(version)
+		if err := tx.UpdateSleepDetails(ctx, sleepDetails); err != nil {
+			return fmt.Errorf("failed to update sleep details: %w", err)
+		}
+		if err := prediction.RecordOutcome(ctx, tx, activity, *endTime, domain.PredictionTypeNextWake); err != nil {
+			return err
+		}
+		if err := audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityActivity, activity.ID, before, result); err != nil {
+			return err
+		}
+
+		if err := tx.DeletePredictionsForBaby(ctx, activity.BabyID); err != nil {
+			return fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, r.versionConflictError(ctx, familyID, err)
+	}
+
+	fmt.Printf("✅ Ended sleep activity %s at %s (duration: %d minutes)\n", activityID, endTime.Format(time.RFC3339), duration)
+
+	r.publishCareSessionUpdated(familyID, activity.CareSessionID)
+	r.publishPredictionsChanged(familyID, activity.BabyID)
+	r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityUpdated, activity)
+
+	// Return the sleep activity with details
+	return result, nil
+}
+
+// CompleteCareSession is the resolver for the completeCareSession field.
+func (r *mutationResolver) CompleteCareSession(ctx context.Context, notes *string) (*model.CareSession, error) {
+	// Require authentication
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the current in-progress session
+	session, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get in-progress session: %w", err)
+	}
+
+	if session == nil {
+		return nil, fmt.Errorf("no active session to complete")
+	}
+
+	// Update session to completed and end any active sleep activities
+	before := mapper.CareSessionToGraphQL(session)
+	now := time.Now()
+	session.Status = domain.StatusCompleted
+	session.CompletedAt = &now
+	session.Notes = notes
+	session.UpdatedAt = now
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.UpdateCareSession(ctx, session); err != nil {
+			return fmt.Errorf("failed to complete care session: %w", err)
+		}
+		if err := audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityCareSession, session.ID, before, mapper.CareSessionToGraphQL(session)); err != nil {
+			return err
+		}
+		return endActiveSleeps(ctx, tx, session.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✅ Completed care session %s\n", session.ID)
+
+	r.publishCareSessionUpdated(familyID, session.ID)
+	r.emitSessionWebhook(ctx, domain.WebhookEventSessionCompleted, session)
+
+	return mapper.CareSessionToGraphQL(session), nil
+}
+
+// DeleteActivity is the resolver for the deleteActivity field.
+func (r *mutationResolver) DeleteActivity(ctx context.Context, activityID string, idempotencyKey *string) (bool, error) {
+	// Require authentication
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionDeleteHistory)
+	if err != nil {
+		return false, err
+	}
+
+	activityUUID, err := uuid.Parse(activityID)
+	if err != nil {
+		return false, fmt.Errorf("invalid activity ID: %w", err)
+	}
+
+	// Look up the activity first so subscribers can be told which session changed
+	activity, err := r.store.GetActivityByID(ctx, activityUUID)
+	if err != nil {
+		// Deleting an activity that's already gone is a no-op, so offline clients can retry safely
+		deleted, lookupErr := r.store.GetDeletedActivity(ctx, activityUUID)
+		if lookupErr == nil && deleted != nil && deleted.FamilyID == familyID {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get activity: %w", err)
+	}
+	belongs, err := activityBelongsToFamily(ctx, r.store, activity, familyID)
+	if err != nil {
+		return false, err
+	}
+	if !belongs {
+		return false, fmt.Errorf("activity not found")
+	}
+
+	// Delete the activity (cascades to details via DB foreign key), leaving a tombstone for
+	// syncing clients, and invalidate the prediction cache in one transaction
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		return deleteActivity(ctx, tx, familyID, activity, idempotencyKey)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	r.publishCareSessionUpdated(familyID, activity.CareSessionID)
+	r.publishPredictionsChanged(familyID, activity.BabyID)
+	r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityDeleted, activity)
+
+	fmt.Printf("🗑️  Deleted activity %s\n", activityID)
+
+	return true, nil
+}
+
+// DeleteCareSession is the resolver for the deleteCareSession field.
+func (r *mutationResolver) DeleteCareSession(ctx context.Context, id string) (bool, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionDeleteHistory)
+	if err != nil {
+		return false, err
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	session, err := r.store.GetCareSessionByID(ctx, sessionID)
+	if err != nil || session.FamilyID != familyID {
+		return false, fmt.Errorf("care session not found")
+	}
+	if session.Status != domain.StatusCompleted {
+		return false, fmt.Errorf("only completed care sessions can be deleted")
+	}
+
+	var activities []*domain.Activity
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		activities, err = deleteCareSession(ctx, tx, session)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, babyID := range activityBabyIDs(activities) {
+		r.publishPredictionsChanged(familyID, babyID)
+	}
+	for _, activity := range activities {
+		r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityDeleted, activity)
+	}
+
+	return true, nil
+}
+
+// RestoreActivity is the resolver for the restoreActivity field.
+func (r *mutationResolver) RestoreActivity(ctx context.Context, activityID string) (model.Activity, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionDeleteHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	activityUUID, err := uuid.Parse(activityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid activity ID: %w", err)
+	}
+
+	activity, err := r.recentlyDeletedActivity(ctx, familyID, activityUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore the activity, clear its tombstone so syncing clients pick it up again, and
+	// invalidate the prediction cache in one transaction
+	var restored model.Activity
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.RestoreActivity(ctx, activity.ID); err != nil {
+			return fmt.Errorf("failed to restore activity: %w", err)
+		}
+		if err := tx.ClearDeletedActivity(ctx, activity.ID); err != nil {
+			return err
+		}
+
+		var err error
+		restored, err = loadActivityFrom(ctx, tx, activity)
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, tx, domain.AuditActionRestore, domain.AuditEntityActivity, activity.ID, nil, restored); err != nil {
+			return err
+		}
+
+		if err := tx.DeletePredictionsForBaby(ctx, activity.BabyID); err != nil {
+			return fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.publishCareSessionUpdated(familyID, activity.CareSessionID)
+	r.publishPredictionsChanged(familyID, activity.BabyID)
+
+	return restored, nil
+}
+
+// RestoreCareSession is the resolver for the restoreCareSession field.
+func (r *mutationResolver) RestoreCareSession(ctx context.Context, id string) (*model.CareSession, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionDeleteHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	session, err := r.recentlyDeletedCareSession(ctx, familyID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var activities []*domain.Activity
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		activities, err = restoreCareSession(ctx, tx, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.publishCareSessionUpdated(familyID, session.ID)
+	for _, babyID := range activityBabyIDs(activities) {
+		r.publishPredictionsChanged(familyID, babyID)
+	}
+
+	session.DeletedAt = nil
+	return r.loadCareSessionWithActivities(ctx, session)
+}
+
+// UpdateActivity is the resolver for the updateActivity field.
+func (r *mutationResolver) UpdateActivity(ctx context.Context, activityID string, input model.ActivityInput, expectedVersion *int32) (model.Activity, error) {
+	// Require authentication
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return nil, err
+	}
+
+	activityUUID, err := uuid.Parse(activityID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid activity ID: %w", err)
+	}
+
+	// Get the existing activity
+	activity, err := r.familyActivity(ctx, familyID, activityUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.BabyID != nil && *input.BabyID != activity.BabyID.String() {
+		return nil, fmt.Errorf("moving an activity to another baby is not supported")
+	}
+
+	// Replaying an update that was already applied returns the current version unchanged
+	if input.IdempotencyKey != nil {
+		record, err := r.store.GetIdempotencyRecord(ctx, familyID, *input.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			return r.loadActivity(ctx, activity)
+		}
+	}
+
+	// The details update and prediction invalidation commit together
+	var result model.Activity
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		result, err = editActivity(ctx, tx, familyID, activity, input, int32PtrToInt(expectedVersion))
+		if err != nil {
+			return err
+		}
+
+		if err := saveIdempotencyKey(ctx, tx, familyID, input.IdempotencyKey, activity.ID, domain.SyncOperationUpdate); err != nil {
+			return err
+		}
+
+		if err := tx.DeletePredictionsForBaby(ctx, activity.BabyID); err != nil {
+			return fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, r.versionConflictError(ctx, familyID, err)
+	}
+
+	// Only a committed update is worth telling subscribers about
+	r.publishCareSessionUpdated(familyID, activity.CareSessionID)
+	r.publishPredictionsChanged(familyID, activity.BabyID)
+	r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityUpdated, activity)
+
+	return result, nil
+}
+
+// SyncActivities is the resolver for the syncActivities field.
+func (r *mutationResolver) SyncActivities(ctx context.Context, changes []*model.SyncChangeInput, since *time.Time) (*model.SyncResult, error) {
+	caregiverID, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return nil, err
+	}
+
+	// Take the cursor before applying anything so writes racing with this sync are
+	// returned by the next one
+	cursor := time.Now()
+
+	result := &model.SyncResult{
+		AppliedKeys:        []string{},
+		Conflicts:          []*model.SyncConflict{},
+		Changes:            []model.Activity{},
+		DeletedActivityIds: []string{},
+		Cursor:             cursor,
+	}
+
+	// Each change commits on its own, so notify subscribers of whatever was applied even if
+	// a later change fails
+	events := &syncEvents{}
+	defer r.publishSyncEvents(ctx, familyID, events)
+
+	for _, change := range changes {
+		conflict, err := r.applySyncChange(ctx, caregiverID, familyID, change, events)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync change %s: %w", change.IdempotencyKey, err)
+		}
+		if conflict != nil {
+			result.Conflicts = append(result.Conflicts, conflict)
+			continue
+		}
+		result.AppliedKeys = append(result.AppliedKeys, change.IdempotencyKey)
+	}
+
+	// Without a cursor the client loads history through the regular queries
+	if since != nil {
+		if err := r.serverChangesSince(ctx, familyID, *since, result); err != nil {
+			return nil, fmt.Errorf("failed to load server changes: %w", err)
+		}
+	}
+
+	fmt.Printf("🔄 Synced %d changes (%d conflicts)\n", len(changes), len(result.Conflicts))
+
+	return result, nil
+}
+
+// DismissPrediction is the resolver for the dismissPrediction field.
+func (r *mutationResolver) DismissPrediction(ctx context.Context, id string) (bool, error) {
+	_, _, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return false, err
+	}
+
+	predictionID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid prediction ID: %w", err)
+	}
+
+	// Predictions are recomputed rather than edited, so the event carries no snapshots
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.DismissPrediction(ctx, predictionID); err != nil {
+			return fmt.Errorf("failed to dismiss prediction: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityPrediction, predictionID, nil, nil)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpdateScheduleGoals is the resolver for the updateScheduleGoals field.
+func (r *mutationResolver) UpdateScheduleGoals(ctx context.Context, babyID *string, input model.ScheduleGoalsInput) (*model.ScheduleGoals, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
+	if err != nil {
+		return nil, err
+	}
+
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := mapper.ScheduleGoalsInputToDomain(input, familyID)
+	if err != nil {
+		return nil, err
+	}
+	goals.BabyID = baby.ID
+
+	var result *domain.ScheduleGoals
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		existing, err := tx.GetScheduleGoals(ctx, baby.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get schedule goals: %w", err)
+		}
+
+		result, err = tx.UpsertScheduleGoals(ctx, baby.ID, goals)
+		if err != nil {
+			return fmt.Errorf("failed to update schedule goals: %w", err)
+		}
+
+		if existing == nil {
+			return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityScheduleGoals, baby.ID, nil, mapper.ScheduleGoalsToGraphQL(result))
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityScheduleGoals, baby.ID, mapper.ScheduleGoalsToGraphQL(existing), mapper.ScheduleGoalsToGraphQL(result))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ScheduleGoalsToGraphQL(result), nil
+}
+
+// UpdateReminderPreferences is the resolver for the updateReminderPreferences field.
+func (r *mutationResolver) UpdateReminderPreferences(ctx context.Context, input model.ReminderPreferencesInput) (*model.ReminderPreferences, error) {
+	caregiverID, _, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required")
+	}
+
+	prefs, err := mapper.ReminderPreferencesInputToDomain(input, caregiverID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *domain.ReminderPreferences
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		existing, err := tx.GetReminderPreferences(ctx, caregiverID)
+		if err != nil {
+			return fmt.Errorf("failed to get reminder preferences: %w", err)
+		}
+
+		result, err = tx.UpsertReminderPreferences(ctx, prefs)
+		if err != nil {
+			return fmt.Errorf("failed to update reminder preferences: %w", err)
+		}
+
+		if existing == nil {
+			return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityReminderPreferences, caregiverID, nil, mapper.ReminderPreferencesToGraphQL(result))
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityReminderPreferences, caregiverID, mapper.ReminderPreferencesToGraphQL(existing), mapper.ReminderPreferencesToGraphQL(result))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ReminderPreferencesToGraphQL(result), nil
+}
+
+// CreateWebhookSubscription is the resolver for the createWebhookSubscription field.
+func (r *mutationResolver) CreateWebhookSubscription(ctx context.Context, input model.WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := mapper.WebhookSubscriptionInputToDomain(input, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateWebhookSubscription(ctx, sub); err != nil {
+			return fmt.Errorf("failed to create webhook subscription: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityWebhookSubscription, sub.ID, nil, mapper.WebhookSubscriptionToGraphQL(sub))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.WebhookSubscriptionToGraphQL(sub), nil
+}
+
+// DeleteWebhookSubscription is the resolver for the deleteWebhookSubscription field.
+func (r *mutationResolver) DeleteWebhookSubscription(ctx context.Context, id string) (bool, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageFamily)
+	if err != nil {
+		return false, err
+	}
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook subscription ID: %w", err)
+	}
+
+	sub, err := r.store.GetWebhookSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if sub.FamilyID != familyID {
+		return false, fmt.Errorf("webhook subscription not found")
+	}
+
+	// Pending deliveries are deleted with the subscription
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.DeleteWebhookSubscription(ctx, subscriptionID); err != nil {
+			return fmt.Errorf("failed to delete webhook subscription: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionDelete, domain.AuditEntityWebhookSubscription, sub.ID, mapper.WebhookSubscriptionToGraphQL(sub), nil)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpsertMedication is the resolver for the upsertMedication field.
+func (r *mutationResolver) UpsertMedication(ctx context.Context, input model.MedicationInput) (*model.Medication, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
+	if err != nil {
+		return nil, err
+	}
+
+	med, err := mapper.MedicationInputToDomain(input, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid medication: %w", err)
+	}
+
+	var result *domain.Medication
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		// Upserting replaces the entry with the same name, if there is one
+		existing, err := tx.GetMedicationByName(ctx, familyID, med.Name)
+		if err != nil {
+			existing = nil
+		}
+
+		result, err = tx.UpsertMedication(ctx, med)
+		if err != nil {
+			return fmt.Errorf("failed to save medication: %w", err)
+		}
+
+		if existing == nil {
+			return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityMedication, result.ID, nil, mapper.MedicationToGraphQL(result))
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityMedication, result.ID, mapper.MedicationToGraphQL(existing), mapper.MedicationToGraphQL(result))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.MedicationToGraphQL(result), nil
+}
+
+// DeleteMedication is the resolver for the deleteMedication field.
+func (r *mutationResolver) DeleteMedication(ctx context.Context, id string) (bool, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
+	if err != nil {
+		return false, err
+	}
+
+	medicationID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid medication ID: %w", err)
+	}
+
+	med, err := r.store.GetMedicationByID(ctx, medicationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get medication: %w", err)
+	}
+	if med.FamilyID != familyID {
+		return false, fmt.Errorf("medication not found")
+	}
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.DeleteMedication(ctx, medicationID); err != nil {
+			return fmt.Errorf("failed to delete medication: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionDelete, domain.AuditEntityMedication, med.ID, mapper.MedicationToGraphQL(med), nil)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// AddGrowthMeasurement is the resolver for the addGrowthMeasurement field.
+func (r *mutationResolver) AddGrowthMeasurement(ctx context.Context, input model.GrowthMeasurementInput) (*model.GrowthMeasurement, error) {
+	caregiverID, familyID, err := middleware.RequirePermission(ctx, domain.PermissionLogCare)
+	if err != nil {
+		return nil, err
+	}
+
+	baby, err := r.resolveBaby(ctx, familyID, input.BabyID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement, err := mapper.GrowthMeasurementInputToDomain(input, familyID, baby.ID, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid growth measurement: %w", err)
+	}
+
+	result := mapper.GrowthMeasurementToGraphQL(measurement, baby)
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateGrowthMeasurement(ctx, measurement); err != nil {
+			return fmt.Errorf("failed to save growth measurement: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionCreate, domain.AuditEntityGrowthMeasurement, measurement.ID, nil, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CheckFamilyNameAvailable is the resolver for the checkFamilyNameAvailable field.
+func (r *queryResolver) CheckFamilyNameAvailable(ctx context.Context, name string) (bool, error) {
+	if err := r.allowAttempt(ctx, ratelimit.ActionCheckFamilyName, callerSubjects(ctx, nil)...); err != nil {
+		return false, err
+	}
+
+	exists, err := r.store.FamilyNameExists(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil // Return true if available (not exists)
+}
+
+// GetMyFamily is the resolver for the getMyFamily field.
+func (r *queryResolver) GetMyFamily(ctx context.Context) (*model.Family, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	family, err := r.store.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+
+	return mapper.FamilyToGraphQL(family), nil
+}
+
+// GetMyCaregiver is the resolver for the getMyCaregiver field.
+func (r *queryResolver) GetMyCaregiver(ctx context.Context) (*model.Caregiver, error) {
+	caregiverID, _, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caregiver: %w", err)
+	}
+
+	return mapper.CaregiverToGraphQL(caregiver), nil
+}
+
+// GetMyFamilies is the resolver for the getMyFamilies field.
+func (r *queryResolver) GetMyFamilies(ctx context.Context) ([]*model.Family, error) {
+	// Require JWT-based user auth
+	userID, hasUser := middleware.GetUserID(ctx)
+	if !hasUser {
+		return nil, fmt.Errorf("authentication required: must be authenticated with a user account")
+	}
+
+	families, err := r.store.GetFamiliesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get families: %w", err)
+	}
+
+	result := make([]*model.Family, len(families))
+	for i, f := range families {
+		result[i] = mapper.FamilyToGraphQL(f)
+	}
+
+	return result, nil
+}
+
+// GetRecentCareSessions is the resolver for the getRecentCareSessions field.
+func (r *queryResolver) GetRecentCareSessions(ctx context.Context, limit *int32) ([]*model.CareSession, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	queryLimit := 10
+	if limit != nil {
+		queryLimit = int(*limit)
+	}
+
+	sessions, err := r.store.GetRecentCareSessionsForFamily(ctx, familyID, queryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent sessions: %w", err)
+	}
+
+	return r.loadCareSessions(ctx, sessions)
+}
+
+// GetCurrentSession is the resolver for the getCurrentSession field.
+func (r *queryResolver) GetCurrentSession(ctx context.Context) (*model.CareSession, error) {
+	// Get authenticated user
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	// Get in-progress session for family
+	session, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get in-progress session: %w", err)
+	}
+
+	// If no session exists, return nil
+	if session == nil {
+		return nil, nil
+	}
+
+	// Load activities and build GraphQL response
+	return r.loadCareSessionWithActivities(ctx, session)
+}
+
+// GetCareSession is the resolver for the getCareSession field.
+func (r *queryResolver) GetCareSession(ctx context.Context, id string) (*model.CareSession, error) {
+	_, _, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	sessionUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	session, err := r.store.GetCareSessionByID(ctx, sessionUUID)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	return r.loadCareSessionWithActivities(ctx, session)
+}
+
+// Babies is the resolver for the babies field.
+func (r *queryResolver) Babies(ctx context.Context) ([]*model.Baby, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	return r.babiesForFamily(ctx, familyID)
+}
+
+// GetBabyStatus is the resolver for the getBabyStatus field.
+func (r *queryResolver) GetBabyStatus(ctx context.Context, babyID *string) (*model.BabyStatus, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.BabyStatus{
+		Baby: mapper.BabyToGraphQL(baby),
+	}
+
+	// Get latest feed
+	feedActivity, err := r.store.GetLatestActivityByTypeForBaby(ctx, baby.ID, domain.ActivityTypeFeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest feed: %w", err)
+	}
+	if feedActivity != nil {
+		feedDetails, err := r.store.GetFeedDetails(ctx, feedActivity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feed details: %w", err)
+		}
+		status.LastFeed = &model.FeedActivity{
+			ID:           feedActivity.ID.String(),
+			BabyID:       feedActivity.BabyID.String(),
+			ActivityType: model.ActivityTypeFeed,
+			CreatedAt:    feedActivity.CreatedAt,
+			Version:      int32(feedActivity.Version),
+			FeedDetails:  mapper.FeedDetailsToGraphQL(feedDetails),
+		}
+	}
+
+	// Get latest diaper
+	diaperActivity, err := r.store.GetLatestActivityByTypeForBaby(ctx, baby.ID, domain.ActivityTypeDiaper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest diaper: %w", err)
+	}
+	if diaperActivity != nil {
+		diaperDetails, err := r.store.GetDiaperDetails(ctx, diaperActivity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diaper details: %w", err)
+		}
+		status.LastDiaper = &model.DiaperActivity{
+			ID:            diaperActivity.ID.String(),
+			BabyID:        diaperActivity.BabyID.String(),
+			ActivityType:  model.ActivityTypeDiaper,
+			CreatedAt:     diaperActivity.CreatedAt,
+			Version:       int32(diaperActivity.Version),
+			DiaperDetails: mapper.DiaperDetailsToGraphQL(diaperDetails),
+		}
+	}
+
+	// Get latest sleep
+	sleepActivity, err := r.store.GetLatestActivityByTypeForBaby(ctx, baby.ID, domain.ActivityTypeSleep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest sleep: %w", err)
+	}
+	if sleepActivity != nil {
+		sleepDetails, err := r.store.GetSleepDetails(ctx, sleepActivity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sleep details: %w", err)
+		}
+		status.LastSleep = &model.SleepActivity{
+			ID:           sleepActivity.ID.String(),
+			BabyID:       sleepActivity.BabyID.String(),
+			ActivityType: model.ActivityTypeSleep,
+			CreatedAt:    sleepActivity.CreatedAt,
+			Version:      int32(sleepActivity.Version),
+			SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
+		}
+	}
+
+	// Get latest medication dose
+	medicationActivity, err := r.store.GetLatestActivityByTypeForBaby(ctx, baby.ID, domain.ActivityTypeMedication)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest medication: %w", err)
+	}
+	if medicationActivity != nil {
+		medicationDetails, err := r.store.GetMedicationDetails(ctx, medicationActivity.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication details: %w", err)
+		}
+		status.LastMedication = &model.MedicationActivity{
+			ID:                medicationActivity.ID.String(),
+			BabyID:            medicationActivity.BabyID.String(),
+			ActivityType:      model.ActivityTypeMedication,
+			CreatedAt:         medicationActivity.CreatedAt,
+			Version:           int32(medicationActivity.Version),
+			MedicationDetails: mapper.MedicationDetailsToGraphQL(medicationDetails),
+		}
+	}
+
+	return status, nil
+}
+
+// GetCareSessionHistory is the resolver for the getCareSessionHistory field.
+func (r *queryResolver) GetCareSessionHistory(ctx context.Context, first int32, after *string) (*model.CareSessionConnection, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	if first <= 0 {
+		return nil, fmt.Errorf("first must be greater than 0")
+	}
+
+	var afterTime *time.Time
+	var afterID *uuid.UUID
+	if after != nil {
+		t, id, err := domain.DecodeCursor(*after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
 		}
-		diaperDetails.UpdatedAt = now
+		afterTime = &t
+		afterID = &id
+	}
+
+	// Fetch first+1 to determine hasNextPage
+	fetchLimit := int(first) + 1
+	sessions, err := r.store.GetCareSessionHistoryForFamily(ctx, familyID, fetchLimit, afterTime, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session history: %w", err)
+	}
+
+	hasNextPage := len(sessions) > int(first)
+	if hasNextPage {
+		sessions = sessions[:first]
+	}
+
+	loaded, err := r.loadCareSessions(ctx, sessions)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.CareSessionEdge, 0, len(sessions))
+	for i, session := range sessions {
+		cursor := domain.EncodeCursor(session.StartedAt, session.ID)
+		edges = append(edges, &model.CareSessionEdge{
+			Node:   loaded[i],
+			Cursor: cursor,
+		})
+	}
+
+	var endCursor *string
+	if len(edges) > 0 {
+		endCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model.CareSessionConnection{
+		Edges: edges,
+		PageInfo: &model.CareSessionPageInfo{
+			HasNextPage: hasNextPage,
+			EndCursor:   endCursor,
+		},
+	}, nil
+}
+
+// Predictions is the resolver for the predictions field.
+func (r *queryResolver) Predictions(ctx context.Context, babyID *string) ([]*model.Prediction, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required")
+	}
+
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := r.store.UpdateDiaperDetails(ctx, diaperDetails); err != nil {
-			return nil, fmt.Errorf("failed to update diaper details: %w", err)
-		}
+	return r.predictionsForBaby(ctx, familyID, baby.ID)
+}
 
-		return &model.DiaperActivity{
-			ID:            activity.ID.String(),
-			ActivityType:  model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
-			CreatedAt:     activity.CreatedAt,
-			DiaperDetails: mapper.DiaperDetailsToGraphQL(diaperDetails),
-		}, nil
+// PredictionAccuracy is the resolver for the predictionAccuracy field.
+func (r *queryResolver) PredictionAccuracy(ctx context.Context, days *int32, babyID *string) ([]*model.PredictionAccuracy, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required")
+	}
 
-	case domain.ActivityTypeSleep:
-		if input.SleepDetails == nil {
-			return nil, fmt.Errorf("sleep activity requires sleepDetails")
+	window := defaultAccuracyDays
+	if days != nil {
+		if *days <= 0 {
+			return nil, fmt.Errorf("days must be positive")
 		}
+		window = int(*days)
+	}
+	since := time.Now().AddDate(0, 0, -window)
 
-		sleepDetails, err := r.store.GetSleepDetails(ctx, activityUUID)
+	var outcomes []*domain.PredictionOutcome
+	if babyID != nil {
+		baby, err := r.resolveBaby(ctx, familyID, babyID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get sleep details: %w", err)
+			return nil, err
 		}
-
-		// Update fields
-		sleepDetails.StartTime = input.SleepDetails.StartTime
-		sleepDetails.EndTime = input.SleepDetails.EndTime
-
-		if sleepDetails.EndTime != nil {
-			duration := int(sleepDetails.EndTime.Sub(sleepDetails.StartTime).Minutes())
-			sleepDetails.DurationMinutes = &duration
-		} else {
-			sleepDetails.DurationMinutes = nil
+		outcomes, err = r.store.GetPredictionOutcomesForBaby(ctx, baby.ID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prediction outcomes: %w", err)
 		}
-
-		sleepDetails.UpdatedAt = now
-
-		if err := r.store.UpdateSleepDetails(ctx, sleepDetails); err != nil {
-			return nil, fmt.Errorf("failed to update sleep details: %w", err)
+	} else {
+		outcomes, err = r.store.GetPredictionOutcomesForFamily(ctx, familyID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prediction outcomes: %w", err)
 		}
+	}
 
-		return &model.SleepActivity{
-			ID:           activity.ID.String(),
-			ActivityType: model.ActivityType(strings.ToUpper(string(activity.ActivityType))),
-			CreatedAt:    activity.CreatedAt,
-			SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
-		}, nil
-
-	default:
-		return nil, fmt.Errorf("unknown activity type: %s", activity.ActivityType)
+	result := make([]*model.PredictionAccuracy, len(prediction.OutcomeTypes))
+	for i, predictionType := range prediction.OutcomeTypes {
+		result[i] = mapper.PredictionAccuracyToGraphQL(predictionType, prediction.OutcomeAccuracy(outcomes, predictionType))
 	}
+	return result, nil
 }
 
-// DismissPrediction is the resolver for the dismissPrediction field.
-func (r *mutationResolver) DismissPrediction(ctx context.Context, id string) (bool, error) {
-	_, _, err := middleware.RequireAuth(ctx)
+// DetectedPatterns is the resolver for the detectedPatterns field.
+func (r *queryResolver) DetectedPatterns(ctx context.Context, days *int32, babyID *string) ([]*model.DetectedPattern, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
-		return false, fmt.Errorf("authentication required")
+		return nil, fmt.Errorf("authentication required")
 	}
 
-	predictionID, err := uuid.Parse(id)
+	window := defaultPatternDays
+	if days != nil {
+		if *days <= 0 {
+			return nil, fmt.Errorf("days must be positive")
+		}
+		window = int(*days)
+	}
+
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
 	if err != nil {
-		return false, fmt.Errorf("invalid prediction ID: %w", err)
+		return nil, err
 	}
 
-	err = r.store.DismissPrediction(ctx, predictionID)
+	now := time.Now()
+	timezone, err := r.familyTimezone(ctx, familyID, now)
+	if err != nil {
+		return nil, err
+	}
+	// The same feeds predictions are made from, so growth spurts are judged against the
+	// days before the window too
+	feedDetails, err := r.store.GetRecentFeedDetailsForBaby(ctx, baby.ID, prediction.RecentRecordLimit)
 	if err != nil {
-		return false, fmt.Errorf("failed to dismiss prediction: %w", err)
+		return nil, fmt.Errorf("failed to get feed details: %w", err)
 	}
 
-	return true, nil
+	since := now.AddDate(0, 0, -window)
+	result := []*model.DetectedPattern{}
+	for _, p := range prediction.DetectPatterns(now, prediction.FeedRecords(feedDetails), timezone) {
+		if p.End.Before(since) {
+			continue
+		}
+		result = append(result, mapper.DetectedPatternToGraphQL(p, now))
+	}
+	return result, nil
 }
 
-// UpdateScheduleGoals is the resolver for the updateScheduleGoals field.
-func (r *mutationResolver) UpdateScheduleGoals(ctx context.Context, input model.ScheduleGoalsInput) (*model.ScheduleGoals, error) {
+// ScheduleGoals is the resolver for the scheduleGoals field.
+func (r *queryResolver) ScheduleGoals(ctx context.Context, babyID *string) (*model.ScheduleGoals, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required")
 	}
 
-	goals, err := mapper.ScheduleGoalsInputToDomain(input, familyID)
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := r.store.UpsertScheduleGoals(ctx, familyID, goals)
+	goals, err := r.store.GetScheduleGoals(ctx, baby.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update schedule goals: %w", err)
+		return nil, fmt.Errorf("failed to get schedule goals: %w", err)
 	}
 
-	return mapper.ScheduleGoalsToGraphQL(result), nil
+	return mapper.ScheduleGoalsToGraphQL(goals), nil
 }
 
-// CheckFamilyNameAvailable is the resolver for the checkFamilyNameAvailable field.
-func (r *queryResolver) CheckFamilyNameAvailable(ctx context.Context, name string) (bool, error) {
-	exists, err := r.store.FamilyNameExists(ctx, name)
+// ReminderPreferences is the resolver for the reminderPreferences field.
+func (r *queryResolver) ReminderPreferences(ctx context.Context) (*model.ReminderPreferences, error) {
+	caregiverID, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
-		return false, err
+		return nil, fmt.Errorf("authentication required")
 	}
-	return !exists, nil // Return true if available (not exists)
-}
 
-// GetMyFamily is the resolver for the getMyFamily field.
-func (r *queryResolver) GetMyFamily(ctx context.Context) (*model.Family, error) {
-	_, familyID, err := middleware.RequireAuth(ctx)
+	prefs, err := r.store.GetReminderPreferences(ctx, caregiverID)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
 	}
-
-	family, err := r.store.GetFamilyByID(ctx, familyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get family: %w", err)
+	if prefs == nil {
+		// No preferences yet: every reminder is off
+		prefs = &domain.ReminderPreferences{
+			CaregiverID: caregiverID,
+			FamilyID:    familyID,
+			LeadMinutes: domain.DefaultReminderLeadMinutes,
+		}
 	}
 
-	return mapper.FamilyToGraphQL(family), nil
+	return mapper.ReminderPreferencesToGraphQL(prefs), nil
 }
 
-// GetMyCaregiver is the resolver for the getMyCaregiver field.
-func (r *queryResolver) GetMyCaregiver(ctx context.Context) (*model.Caregiver, error) {
-	caregiverID, _, err := middleware.RequireAuth(ctx)
+// Invites is the resolver for the invites field.
+func (r *queryResolver) Invites(ctx context.Context) ([]*model.FamilyInvite, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	caregiver, err := r.store.GetCaregiverByID(ctx, caregiverID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get caregiver: %w", err)
-	}
-
-	return mapper.CaregiverToGraphQL(caregiver), nil
-}
-
-// GetMyFamilies is the resolver for the getMyFamilies field.
-func (r *queryResolver) GetMyFamilies(ctx context.Context) ([]*model.Family, error) {
-	// Require JWT-based user auth
-	userID, hasUser := middleware.GetUserID(ctx)
-	if !hasUser {
-		return nil, fmt.Errorf("authentication required: must be authenticated with a user account")
-	}
-
-	families, err := r.store.GetFamiliesByUserID(ctx, userID)
+	invites, err := r.store.GetFamilyInvitesForFamily(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get families: %w", err)
+		return nil, fmt.Errorf("failed to get invites: %w", err)
 	}
 
-	result := make([]*model.Family, len(families))
-	for i, f := range families {
-		result[i] = mapper.FamilyToGraphQL(f)
+	now := time.Now()
+	result := make([]*model.FamilyInvite, 0, len(invites))
+	for _, i := range invites {
+		result = append(result, mapper.FamilyInviteToGraphQL(i, now))
 	}
 
 	return result, nil
 }
 
-// GetRecentCareSessions is the resolver for the getRecentCareSessions field.
-func (r *queryResolver) GetRecentCareSessions(ctx context.Context, limit *int32) ([]*model.CareSession, error) {
+// WebhookSubscriptions is the resolver for the webhookSubscriptions field.
+func (r *queryResolver) WebhookSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	queryLimit := 10
-	if limit != nil {
-		queryLimit = int(*limit)
-	}
-
-	sessions, err := r.store.GetRecentCareSessionsForFamily(ctx, familyID, queryLimit)
+	subs, err := r.store.GetWebhookSubscriptionsForFamily(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get recent sessions: %w", err)
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
 	}
 
-	var result []*model.CareSession
-	for _, session := range sessions {
-		loaded, err := r.loadCareSessionWithActivities(ctx, session)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load session %s: %w", session.ID, err)
-		}
-		result = append(result, loaded)
+	result := make([]*model.WebhookSubscription, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, mapper.WebhookSubscriptionToGraphQL(sub))
 	}
 
 	return result, nil
 }
 
-// GetCurrentSession is the resolver for the getCurrentSession field.
-func (r *queryResolver) GetCurrentSession(ctx context.Context) (*model.CareSession, error) {
-	// Get authenticated user
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, status *model.WebhookDeliveryStatus, limit *int32) ([]*model.WebhookDelivery, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	// Get in-progress session for family
-	session, err := r.store.GetInProgressSessionForFamily(ctx, familyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get in-progress session: %w", err)
-	}
-
-	// If no session exists, return nil
-	if session == nil {
-		return nil, nil
+	queryLimit := 50
+	if limit != nil {
+		queryLimit = int(*limit)
 	}
 
-	// Load activities and build GraphQL response
-	return r.loadCareSessionWithActivities(ctx, session)
-}
-
-// GetCareSession is the resolver for the getCareSession field.
-func (r *queryResolver) GetCareSession(ctx context.Context, id string) (*model.CareSession, error) {
-	_, _, err := middleware.RequireAuth(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+	var deliveryStatus *domain.WebhookDeliveryStatus
+	if status != nil {
+		s := domain.WebhookDeliveryStatus(strings.ToLower(string(*status)))
+		deliveryStatus = &s
 	}
 
-	sessionUUID, err := uuid.Parse(id)
+	deliveries, err := r.store.GetWebhookDeliveriesForFamily(ctx, familyID, deliveryStatus, queryLimit)
 	if err != nil {
-		return nil, fmt.Errorf("invalid session ID: %w", err)
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
 	}
 
-	session, err := r.store.GetCareSessionByID(ctx, sessionUUID)
-	if err != nil {
-		return nil, fmt.Errorf("session not found: %w", err)
+	result := make([]*model.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, mapper.WebhookDeliveryToGraphQL(d))
 	}
 
-	return r.loadCareSessionWithActivities(ctx, session)
+	return result, nil
 }
 
-// GetBabyStatus is the resolver for the getBabyStatus field.
-func (r *queryResolver) GetBabyStatus(ctx context.Context) (*model.BabyStatus, error) {
+// RecentlyDeleted is the resolver for the recentlyDeleted field.
+func (r *queryResolver) RecentlyDeleted(ctx context.Context) (*model.RecentlyDeleted, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	status := &model.BabyStatus{}
-
-	// Get latest feed
-	feedActivity, err := r.store.GetLatestActivityByTypeForFamily(ctx, familyID, domain.ActivityTypeFeed)
+	since := r.deletedSince()
+	activities, err := r.store.GetRecentlyDeletedActivitiesForFamily(ctx, familyID, since)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest feed: %w", err)
+		return nil, fmt.Errorf("failed to get deleted activities: %w", err)
 	}
-	if feedActivity != nil {
-		feedDetails, err := r.store.GetFeedDetails(ctx, feedActivity.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get feed details: %w", err)
-		}
-		status.LastFeed = &model.FeedActivity{
-			ID:           feedActivity.ID.String(),
-			ActivityType: model.ActivityTypeFeed,
-			CreatedAt:    feedActivity.CreatedAt,
-			FeedDetails:  mapper.FeedDetailsToGraphQL(feedDetails),
-		}
+	sessions, err := r.store.GetRecentlyDeletedCareSessionsForFamily(ctx, familyID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted care sessions: %w", err)
 	}
 
-	// Get latest diaper
-	diaperActivity, err := r.store.GetLatestActivityByTypeForFamily(ctx, familyID, domain.ActivityTypeDiaper)
+	result := &model.RecentlyDeleted{
+		Activities:   make([]*model.RecentlyDeletedActivity, 0, len(activities)),
+		CareSessions: make([]*model.RecentlyDeletedCareSession, 0, len(sessions)),
+	}
+	loadedActivities, err := r.loadActivities(ctx, activities)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest diaper: %w", err)
+		return nil, err
 	}
-	if diaperActivity != nil {
-		diaperDetails, err := r.store.GetDiaperDetails(ctx, diaperActivity.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get diaper details: %w", err)
-		}
-		status.LastDiaper = &model.DiaperActivity{
-			ID:            diaperActivity.ID.String(),
-			ActivityType:  model.ActivityTypeDiaper,
-			CreatedAt:     diaperActivity.CreatedAt,
-			DiaperDetails: mapper.DiaperDetailsToGraphQL(diaperDetails),
-		}
+	for i, activity := range activities {
+		result.Activities = append(result.Activities, &model.RecentlyDeletedActivity{
+			Activity:  loadedActivities[i],
+			DeletedAt: *activity.DeletedAt,
+			PurgeAt:   activity.DeletedAt.Add(r.deletedRetention),
+		})
 	}
-
-	// Get latest sleep
-	sleepActivity, err := r.store.GetLatestActivityByTypeForFamily(ctx, familyID, domain.ActivityTypeSleep)
+	loadedSessions, err := r.loadCareSessions(ctx, sessions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest sleep: %w", err)
+		return nil, err
 	}
-	if sleepActivity != nil {
-		sleepDetails, err := r.store.GetSleepDetails(ctx, sleepActivity.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get sleep details: %w", err)
-		}
-		status.LastSleep = &model.SleepActivity{
-			ID:           sleepActivity.ID.String(),
-			ActivityType: model.ActivityTypeSleep,
-			CreatedAt:    sleepActivity.CreatedAt,
-			SleepDetails: mapper.SleepDetailsToGraphQL(sleepDetails),
-		}
+	for i, session := range sessions {
+		result.CareSessions = append(result.CareSessions, &model.RecentlyDeletedCareSession{
+			CareSession: loadedSessions[i],
+			DeletedAt:   *session.DeletedAt,
+			PurgeAt:     session.DeletedAt.Add(r.deletedRetention),
+		})
 	}
 
-	return status, nil
+	return result, nil
 }
 
-// GetCareSessionHistory is the resolver for the getCareSessionHistory field.
-func (r *queryResolver) GetCareSessionHistory(ctx context.Context, first int32, after *string) (*model.CareSessionConnection, error) {
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, filter *model.AuditLogFilter, first int32, after *string) (*model.AuditEventConnection, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required: %w", err)
@@ -1153,6 +2193,11 @@ func (r *queryResolver) GetCareSessionHistory(ctx context.Context, first int32,
 		return nil, fmt.Errorf("first must be greater than 0")
 	}
 
+	auditFilter, err := mapper.AuditLogFilterToDomain(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	var afterTime *time.Time
 	var afterID *uuid.UUID
 	if after != nil {
@@ -1165,27 +2210,21 @@ func (r *queryResolver) GetCareSessionHistory(ctx context.Context, first int32,
 	}
 
 	// Fetch first+1 to determine hasNextPage
-	fetchLimit := int(first) + 1
-	sessions, err := r.store.GetCareSessionHistoryForFamily(ctx, familyID, fetchLimit, afterTime, afterID)
+	events, err := r.store.GetAuditEventsForFamily(ctx, familyID, auditFilter, int(first)+1, afterTime, afterID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session history: %w", err)
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
 	}
 
-	hasNextPage := len(sessions) > int(first)
+	hasNextPage := len(events) > int(first)
 	if hasNextPage {
-		sessions = sessions[:first]
+		events = events[:first]
 	}
 
-	edges := make([]*model.CareSessionEdge, 0, len(sessions))
-	for _, session := range sessions {
-		loaded, err := r.loadCareSessionWithActivities(ctx, session)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load session %s: %w", session.ID, err)
-		}
-		cursor := domain.EncodeCursor(session.StartedAt, session.ID)
-		edges = append(edges, &model.CareSessionEdge{
-			Node:   loaded,
-			Cursor: cursor,
+	edges := make([]*model.AuditEventEdge, 0, len(events))
+	for _, event := range events {
+		edges = append(edges, &model.AuditEventEdge{
+			Node:   mapper.AuditEventToGraphQL(event),
+			Cursor: domain.EncodeCursor(event.CreatedAt, event.ID),
 		})
 	}
 
@@ -1194,135 +2233,139 @@ func (r *queryResolver) GetCareSessionHistory(ctx context.Context, first int32,
 		endCursor = &edges[len(edges)-1].Cursor
 	}
 
-	return &model.CareSessionConnection{
+	return &model.AuditEventConnection{
 		Edges: edges,
-		PageInfo: &model.CareSessionPageInfo{
+		PageInfo: &model.AuditEventPageInfo{
 			HasNextPage: hasNextPage,
 			EndCursor:   endCursor,
 		},
 	}, nil
 }
 
-// Predictions is the resolver for the predictions field.
-func (r *queryResolver) Predictions(ctx context.Context) ([]*model.Prediction, error) {
+// Medications is the resolver for the medications field.
+func (r *queryResolver) Medications(ctx context.Context) ([]*model.Medication, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required")
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	now := time.Now()
-
-	// Cleanup old predictions
-	_ = r.store.CleanupOldPredictions(ctx, now.Add(-24*time.Hour))
-
-	// Check if existing predictions are fresh (computed within last 1 minute)
-	existing, err := r.store.GetPredictionsForFamily(ctx, familyID)
+	medications, err := r.store.GetMedicationsForFamily(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get predictions: %w", err)
-	}
-	if len(existing) > 0 && now.Sub(existing[0].ComputedAt) < 1*time.Minute {
-		result := make([]*model.Prediction, 0, len(existing))
-		for _, dp := range existing {
-			result = append(result, mapper.PredictionToGraphQL(dp))
-		}
-		return result, nil
+		return nil, fmt.Errorf("failed to get medications: %w", err)
 	}
 
-	// Fetch recent activity data (last 14 days)
-	since14Days := now.Add(-14 * 24 * time.Hour)
-	_ = since14Days // used conceptually below
+	result := make([]*model.Medication, 0, len(medications))
+	for _, med := range medications {
+		result = append(result, mapper.MedicationToGraphQL(med))
+	}
+	return result, nil
+}
 
-	feedDetails, err := r.store.GetRecentFeedDetailsForFamily(ctx, familyID, 200)
+// GetMedicationStatus is the resolver for the getMedicationStatus field.
+func (r *queryResolver) GetMedicationStatus(ctx context.Context, babyID *string) ([]*model.MedicationStatus, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get feed details: %w", err)
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	sleepDetails, err := r.store.GetRecentSleepDetailsForFamily(ctx, familyID, 200)
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sleep details: %w", err)
+		return nil, err
 	}
 
-	// Convert domain models to prediction engine input types
-	feeds := make([]prediction.FeedRecord, 0, len(feedDetails))
-	for _, fd := range feedDetails {
-		feeds = append(feeds, prediction.FeedRecord{
-			StartTime: fd.StartTime,
-			EndTime:   fd.EndTime,
-			AmountMl:  fd.AmountMl,
-			FeedType:  fd.FeedType,
-		})
-	}
+	return r.medicationStatuses(ctx, familyID, baby.ID, time.Now())
+}
 
-	sleeps := make([]prediction.SleepRecord, 0, len(sleepDetails))
-	for _, sd := range sleepDetails {
-		sleeps = append(sleeps, prediction.SleepRecord{
-			StartTime:       sd.StartTime,
-			EndTime:         sd.EndTime,
-			DurationMinutes: sd.DurationMinutes,
-		})
+// GrowthHistory is the resolver for the growthHistory field.
+func (r *queryResolver) GrowthHistory(ctx context.Context, babyID *string) ([]*model.GrowthMeasurement, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	// Fetch schedule goals for blending
-	goals, err := r.store.GetScheduleGoals(ctx, familyID)
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
 	if err != nil {
-		// Non-fatal: proceed without goals
-		goals = nil
+		return nil, err
 	}
 
-	// Generate predictions
-	timezone := middleware.GetTimezone(ctx)
-	predictions := prediction.GeneratePredictions(now, feeds, sleeps, timezone)
-
-	// If no data-driven predictions but goals exist, generate goal-only predictions
-	if len(predictions) == 0 && goals != nil {
-		predictions = prediction.GenerateGoalOnlyPredictions(now, goals, timezone)
+	measurements, err := r.store.GetGrowthMeasurementsForBaby(ctx, baby.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get growth measurements: %w", err)
 	}
 
-	// Blend predictions with schedule goals
-	if goals != nil && len(predictions) > 0 {
-		predictions = prediction.BlendPredictions(predictions, goals, len(feeds), len(sleeps))
+	result := make([]*model.GrowthMeasurement, len(measurements))
+	for i, m := range measurements {
+		result[i] = mapper.GrowthMeasurementToGraphQL(m, baby)
 	}
 
-	// Set family ID on all predictions
-	for _, p := range predictions {
-		p.FamilyID = familyID
+	return result, nil
+}
+
+// CareSessionUpdated is the resolver for the careSessionUpdated field.
+func (r *subscriptionResolver) CareSessionUpdated(ctx context.Context) (<-chan *model.CareSession, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	// Persist predictions
-	if len(predictions) > 0 {
-		if err := r.store.UpsertPredictions(ctx, familyID, predictions); err != nil {
-			return nil, fmt.Errorf("failed to save predictions: %w", err)
+	return subscribe(ctx, r.events, familyID, pubsub.EventCareSessionUpdated, func(event pubsub.Event) (*model.CareSession, error) {
+		session, err := r.store.GetCareSessionByID(ctx, event.CareSessionID)
+		if err != nil {
+			return nil, fmt.Errorf("session not found: %w", err)
 		}
-	}
+		return r.loadCareSessionWithActivities(ctx, session)
+	}), nil
+}
 
-	// Map to GraphQL
-	result := make([]*model.Prediction, 0, len(predictions))
-	for _, dp := range predictions {
-		result = append(result, mapper.PredictionToGraphQL(dp))
+// ActivityAdded is the resolver for the activityAdded field.
+func (r *subscriptionResolver) ActivityAdded(ctx context.Context) (<-chan model.Activity, error) {
+	_, familyID, err := middleware.RequireAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
-	return result, nil
+
+	return subscribe(ctx, r.events, familyID, pubsub.EventActivityAdded, func(event pubsub.Event) (model.Activity, error) {
+		activity, err := r.store.GetActivityByID(ctx, event.ActivityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity: %w", err)
+		}
+		return r.loadActivity(ctx, activity)
+	}), nil
 }
 
-// ScheduleGoals is the resolver for the scheduleGoals field.
-func (r *queryResolver) ScheduleGoals(ctx context.Context) (*model.ScheduleGoals, error) {
+// PredictionsChanged is the resolver for the predictionsChanged field.
+func (r *subscriptionResolver) PredictionsChanged(ctx context.Context, babyID *string) (<-chan []*model.Prediction, error) {
 	_, familyID, err := middleware.RequireAuth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication required")
 	}
 
-	goals, err := r.store.GetScheduleGoals(ctx, familyID)
+	baby, err := r.resolveBaby(ctx, familyID, babyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get schedule goals: %w", err)
+		return nil, err
 	}
 
-	return mapper.ScheduleGoalsToGraphQL(goals), nil
+	return subscribe(ctx, r.events, familyID, pubsub.EventPredictionsChanged, func(event pubsub.Event) ([]*model.Prediction, error) {
+		if event.BabyID != baby.ID {
+			return nil, errSkipEvent
+		}
+		return r.predictionsForBaby(ctx, familyID, baby.ID)
+	}), nil
 }
 
+// Family returns FamilyResolver implementation.
+func (r *Resolver) Family() FamilyResolver { return &familyResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type familyResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
@@ -2,13 +2,21 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/devicetoken"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/invite"
 	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/pubsub"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // withUserID sets a user ID in context (simulates JWT auth path)
@@ -16,10 +24,20 @@ func withUserID(ctx context.Context, userID uuid.UUID) context.Context {
 	return context.WithValue(ctx, middleware.UserIDKey, userID)
 }
 
+// withDeviceCaregiver sets the caregiver a verified device token was issued to
+func withDeviceCaregiver(ctx context.Context, caregiverID uuid.UUID) context.Context {
+	return context.WithValue(ctx, middleware.DeviceCaregiverIDKey, caregiverID)
+}
+
 // withAuth sets caregiver and family IDs in context (simulates legacy auth path)
 func withAuth(ctx context.Context, caregiverID, familyID uuid.UUID) context.Context {
+	return withRole(ctx, caregiverID, familyID, domain.RoleOwner)
+}
+
+func withRole(ctx context.Context, caregiverID, familyID uuid.UUID, role domain.CaregiverRole) context.Context {
 	ctx = context.WithValue(ctx, middleware.CaregiverIDKey, caregiverID)
 	ctx = context.WithValue(ctx, middleware.FamilyIDKey, familyID)
+	ctx = context.WithValue(ctx, middleware.RoleKey, role)
 	return ctx
 }
 
@@ -142,7 +160,6 @@ func TestJoinFamily_DeviceBased_NewDevice(t *testing.T) {
 		ID:           familyID,
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 	store.getCaregiverByDeviceIDErr = errNotFound
@@ -174,7 +191,6 @@ func TestJoinFamily_DeviceBased_ExistingDeviceSameFamily(t *testing.T) {
 		ID:           familyID,
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 	store.caregiverByDeviceID = &domain.Caregiver{
@@ -210,7 +226,6 @@ func TestJoinFamily_DeviceBased_ExistingDeviceDifferentFamily(t *testing.T) {
 		ID:           familyID,
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 	store.caregiverByDeviceID = &domain.Caregiver{
@@ -239,7 +254,6 @@ func TestJoinFamily_DeviceBased_MissingDeviceID(t *testing.T) {
 		ID:           uuid.New(),
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 
@@ -265,7 +279,6 @@ func TestJoinFamily_UserBased_NewUser(t *testing.T) {
 		ID:           familyID,
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 	store.getCaregiverByUserAndFamilyErr = errNotFound
@@ -299,7 +312,6 @@ func TestJoinFamily_UserBased_ExistingUser(t *testing.T) {
 		ID:           familyID,
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 	store.caregiverByUserAndFamily = &domain.Caregiver{
@@ -331,7 +343,6 @@ func TestJoinFamily_WrongPassword(t *testing.T) {
 		ID:           uuid.New(),
 		Name:         "TestFamily",
 		PasswordHash: hashPassword("password123"),
-		Password:     "password123",
 		BabyName:     "Baby",
 	}
 
@@ -366,7 +377,7 @@ func TestLinkCaregiverToUser_Success(t *testing.T) {
 
 	resolver := NewResolver(store)
 	mr := &mutationResolver{resolver}
-	ctx := withUserID(context.Background(), userID)
+	ctx := withDeviceCaregiver(withUserID(context.Background(), userID), caregiverID)
 
 	result, err := mr.LinkCaregiverToUser(ctx, caregiverID.String())
 	if err != nil {
@@ -406,7 +417,7 @@ func TestLinkCaregiverToUser_AlreadyLinked(t *testing.T) {
 
 	resolver := NewResolver(store)
 	mr := &mutationResolver{resolver}
-	ctx := withUserID(context.Background(), userID)
+	ctx := withDeviceCaregiver(withUserID(context.Background(), userID), caregiverID)
 
 	_, err := mr.LinkCaregiverToUser(ctx, caregiverID.String())
 	if err == nil {
@@ -414,6 +425,38 @@ func TestLinkCaregiverToUser_AlreadyLinked(t *testing.T) {
 	}
 }
 
+func TestLinkCaregiverToUser_RequiresCaregiversDevice(t *testing.T) {
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		// A signed-in outsider who only knows the caregiver's ID
+		{"no device token", withUserID(context.Background(), uuid.New())},
+		// Another caregiver in the family, e.g. a viewer, claiming an owner
+		{"another caregiver's device", withDeviceCaregiver(withRole(withUserID(context.Background(), uuid.New()), uuid.New(), familyID, domain.RoleViewer), uuid.New())},
+		// Legacy unsigned headers name the caregiver but prove nothing
+		{"unsigned headers", withAuth(withUserID(context.Background(), uuid.New()), caregiverID, familyID)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockStore()
+			store.caregiverByID = &domain.Caregiver{ID: caregiverID, FamilyID: familyID, Name: "Mom", Role: domain.RoleOwner}
+
+			mr := &mutationResolver{NewResolver(store)}
+			_, err := mr.LinkCaregiverToUser(tt.ctx, caregiverID.String())
+			if !errors.Is(err, middleware.ErrForbidden) {
+				t.Fatalf("expected ErrForbidden, got %v", err)
+			}
+			if store.linkCaregiverToUserCalled {
+				t.Error("expected caregiver not to be linked")
+			}
+		})
+	}
+}
+
 func TestLinkCaregiverToUser_InvalidCaregiverID(t *testing.T) {
 	store := newMockStore()
 	resolver := NewResolver(store)
@@ -430,8 +473,8 @@ func TestLinkCaregiverToUser_InvalidCaregiverID(t *testing.T) {
 
 func TestGetMyFamilies_Success(t *testing.T) {
 	userID := uuid.New()
-	family1 := &domain.Family{ID: uuid.New(), Name: "Family1", BabyName: "Baby1", Password: "pass1"}
-	family2 := &domain.Family{ID: uuid.New(), Name: "Family2", BabyName: "Baby2", Password: "pass2"}
+	family1 := &domain.Family{ID: uuid.New(), Name: "Family1", BabyName: "Baby1"}
+	family2 := &domain.Family{ID: uuid.New(), Name: "Family2", BabyName: "Baby2"}
 
 	store := newMockStore()
 	store.familiesByUser = []*domain.Family{family1, family2}
@@ -489,6 +532,7 @@ func TestLeaveFamily_Success(t *testing.T) {
 	familyID := uuid.New()
 
 	store := newMockStore()
+	store.caregiverByID = &domain.Caregiver{ID: caregiverID, FamilyID: familyID}
 	resolver := NewResolver(store)
 	mr := &mutationResolver{resolver}
 	ctx := withAuth(context.Background(), caregiverID, familyID)
@@ -566,7 +610,7 @@ func TestGetBabyStatus_AllTypesPresent(t *testing.T) {
 	qr := &queryResolver{resolver}
 	ctx := withAuth(context.Background(), caregiverID, familyID)
 
-	result, err := qr.GetBabyStatus(ctx)
+	result, err := qr.GetBabyStatus(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -604,7 +648,7 @@ func TestGetBabyStatus_SomeTypesMissing(t *testing.T) {
 	qr := &queryResolver{resolver}
 	ctx := withAuth(context.Background(), caregiverID, familyID)
 
-	result, err := qr.GetBabyStatus(ctx)
+	result, err := qr.GetBabyStatus(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -628,7 +672,7 @@ func TestGetBabyStatus_NoActivities(t *testing.T) {
 	qr := &queryResolver{resolver}
 	ctx := withAuth(context.Background(), caregiverID, familyID)
 
-	result, err := qr.GetBabyStatus(ctx)
+	result, err := qr.GetBabyStatus(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -649,7 +693,7 @@ func TestGetBabyStatus_Unauthenticated(t *testing.T) {
 	qr := &queryResolver{resolver}
 	ctx := context.Background()
 
-	_, err := qr.GetBabyStatus(ctx)
+	_, err := qr.GetBabyStatus(ctx, nil)
 	if err == nil {
 		t.Fatal("expected error for unauthenticated request")
 	}
@@ -793,6 +837,39 @@ func TestGetCareSessionHistory_EdgesHaveCursors(t *testing.T) {
 	}
 }
 
+func TestGetCareSessionHistory_BatchesSessionLoads(t *testing.T) {
+	familyID := uuid.New()
+	caregiverID := uuid.New()
+	sessions := makeTestSessions(familyID, caregiverID, 3)
+
+	store := newMockStore()
+	store.caregiverByID = &domain.Caregiver{ID: caregiverID, FamilyID: familyID, Name: "Test"}
+	store.careSessionHistory = sessions
+	for _, session := range sessions {
+		store.sessionActivities = append(store.sessionActivities,
+			&domain.Activity{ID: uuid.New(), CareSessionID: session.ID, BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeFeed})
+	}
+	store.feedDetails = &domain.FeedDetails{ID: uuid.New(), StartTime: time.Now().Add(-time.Hour)}
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), caregiverID, familyID)
+
+	result, err := qr.GetCareSessionHistory(ctx, 10, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.activitiesForSessionsCalls) != 1 || len(store.activitiesForSessionsCalls[0]) != 3 {
+		t.Errorf("activity loads = %v, want one batch of 3 sessions", store.activitiesForSessionsCalls)
+	}
+	if len(store.caregiversByIDsCalls) != 1 {
+		t.Errorf("caregiver loads = %d, want 1", len(store.caregiversByIDsCalls))
+	}
+	for i, edge := range result.Edges {
+		if len(edge.Node.Activities) != 1 {
+			t.Errorf("session %d has %d activities, want just its own", i, len(edge.Node.Activities))
+		}
+	}
+}
+
 // ==================== Predictions Tests ====================
 
 func withTimezone(ctx context.Context, tz string) context.Context {
@@ -804,7 +881,7 @@ func TestPredictions_NoAuth_ReturnsError(t *testing.T) {
 	resolver := NewResolver(store)
 	qr := &queryResolver{resolver}
 
-	_, err := qr.Predictions(context.Background())
+	_, err := qr.Predictions(context.Background(), nil)
 	if err == nil {
 		t.Error("expected error when not authenticated")
 	}
@@ -837,7 +914,7 @@ func TestPredictions_WithFeedData_ReturnsPredictions(t *testing.T) {
 	}
 	store.recentFeedDetails = feeds
 
-	result, err := qr.Predictions(ctx)
+	result, err := qr.Predictions(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -911,7 +988,7 @@ func TestPredictions_WithFeedAndSleepData_ReturnsMultipleTypes(t *testing.T) {
 	}
 	store.recentSleepDetails = sleeps
 
-	result, err := qr.Predictions(ctx)
+	result, err := qr.Predictions(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -940,7 +1017,7 @@ func TestPredictions_StoreError_ReturnsError(t *testing.T) {
 
 	store.recentFeedErr = fmt.Errorf("database connection failed")
 
-	_, err := qr.Predictions(ctx)
+	_, err := qr.Predictions(ctx, nil)
 	if err == nil {
 		t.Error("expected error when store fails")
 	}
@@ -975,7 +1052,7 @@ func TestPredictions_FreshCache_ReturnsCached(t *testing.T) {
 		},
 	}
 
-	result, err := qr.Predictions(ctx)
+	result, err := qr.Predictions(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -998,7 +1075,7 @@ func TestPredictions_NoData_ReturnsEmpty(t *testing.T) {
 	familyID := uuid.New()
 	ctx := withAuth(context.Background(), caregiverID, familyID)
 
-	result, err := qr.Predictions(ctx)
+	result, err := qr.Predictions(ctx, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1006,3 +1083,2825 @@ func TestPredictions_NoData_ReturnsEmpty(t *testing.T) {
 		t.Errorf("expected 0 predictions for new family, got %d", len(result))
 	}
 }
+
+func TestAddActivities_Feed_RecordsPredictionOutcome(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), store.family.ID)
+
+	now := time.Now()
+	medium := domain.PredictionConfidenceMedium
+	nextFeed := &domain.Prediction{
+		ID:             uuid.New(),
+		FamilyID:       store.family.ID,
+		BabyID:         store.babies[0].ID,
+		PredictionType: domain.PredictionTypeNextFeed,
+		PredictedTime:  now.Add(10 * time.Minute),
+		Status:         domain.PredictionStatusUpcoming,
+		Confidence:     &medium,
+	}
+	store.predictions = []*domain.Prediction{
+		{ID: uuid.New(), PredictionType: domain.PredictionTypeNextNap, PredictedTime: now, Status: domain.PredictionStatusUpcoming},
+		nextFeed,
+		{ID: uuid.New(), PredictionType: domain.PredictionTypeNextFeed, PredictedTime: now, Status: domain.PredictionStatusPlanned},
+	}
+
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{ActivityType: model.ActivityTypeFeed, FeedDetails: &model.FeedDetailsInput{StartTime: now}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.predictionOutcomes) != 1 {
+		t.Fatalf("expected 1 prediction outcome, got %d", len(store.predictionOutcomes))
+	}
+	outcome := store.predictionOutcomes[0]
+	if outcome.PredictionID != nextFeed.ID || outcome.Offset() != 10*time.Minute {
+		t.Errorf("outcome = %+v, want the upcoming NEXT_FEED prediction 10 minutes late", outcome)
+	}
+	if outcome.Confidence == nil || *outcome.Confidence != medium {
+		t.Errorf("expected the prediction's confidence to be recorded, got %v", outcome.Confidence)
+	}
+	if len(store.deletedPredictionBabyIDs) != 1 {
+		t.Error("expected predictions to still be invalidated")
+	}
+}
+
+func TestPredictionAccuracy(t *testing.T) {
+	store := newMockStore()
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), store.family.ID)
+
+	now := time.Now()
+	outcome := func(predictionType domain.PredictionType, actual time.Time, offset time.Duration) *domain.PredictionOutcome {
+		return &domain.PredictionOutcome{
+			ID:             uuid.New(),
+			BabyID:         store.babies[0].ID,
+			PredictionType: predictionType,
+			PredictedTime:  actual.Add(offset),
+			ActualTime:     actual,
+		}
+	}
+	store.predictionOutcomes = []*domain.PredictionOutcome{
+		outcome(domain.PredictionTypeNextFeed, now.AddDate(0, 0, -20), 3*time.Hour),
+		outcome(domain.PredictionTypeNextFeed, now.Add(-3*time.Hour), 10*time.Minute),
+		outcome(domain.PredictionTypeNextFeed, now.Add(-time.Hour), -20*time.Minute),
+	}
+
+	result, err := qr.PredictionAccuracy(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 4 {
+		t.Fatalf("expected one entry per prediction type, got %d", len(result))
+	}
+
+	feed := result[0]
+	if feed.PredictionType != model.PredictionTypeNextFeed || feed.SampleCount != 2 {
+		t.Fatalf("first entry = %+v, want NEXT_FEED from the last 14 days", feed)
+	}
+	if *feed.MeanAbsoluteErrorMinutes != 15 || *feed.MeanErrorMinutes != -5 {
+		t.Errorf("errors = %v, %v, want 15, -5", *feed.MeanAbsoluteErrorMinutes, *feed.MeanErrorMinutes)
+	}
+	if *feed.Within15Rate != 0.5 || *feed.Within30Rate != 1 {
+		t.Errorf("rates = %v, %v, want 0.5, 1", *feed.Within15Rate, *feed.Within30Rate)
+	}
+	if nap := result[1]; nap.SampleCount != 0 || nap.MeanAbsoluteErrorMinutes != nil {
+		t.Errorf("expected no NEXT_NAP samples, got %+v", nap)
+	}
+
+	days := int32(30)
+	result, err = qr.PredictionAccuracy(ctx, &days, nil)
+	if err != nil || result[0].SampleCount != 3 {
+		t.Errorf("expected 3 samples over 30 days, got %+v (err %v)", result[0], err)
+	}
+
+	days = 0
+	if _, err := qr.PredictionAccuracy(ctx, &days, nil); err == nil {
+		t.Error("expected an error for non-positive days")
+	}
+}
+
+// ==================== Timezone Tests ====================
+
+func TestUpdateFamilyTimezone(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	familyID := store.family.ID
+
+	if _, err := mr.UpdateFamilyTimezone(withAuth(context.Background(), uuid.New(), familyID), "Mars/Olympus_Mons"); err == nil {
+		t.Fatal("expected an error for an unknown timezone")
+	}
+	if _, err := mr.UpdateFamilyTimezone(withRole(context.Background(), uuid.New(), familyID, domain.RoleCaregiver), "America/Toronto"); err == nil {
+		t.Fatal("expected a caregiver to be refused")
+	}
+	if store.updatedFamily != nil {
+		t.Fatal("expected the family to be left alone")
+	}
+
+	family, err := mr.UpdateFamilyTimezone(withAuth(context.Background(), uuid.New(), familyID), " America/Toronto ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if family.Timezone == nil || *family.Timezone != "America/Toronto" {
+		t.Errorf("timezone = %v, want America/Toronto", family.Timezone)
+	}
+	if store.updatedFamily == nil || store.updatedFamily.Timezone != "America/Toronto" {
+		t.Error("expected the timezone to be saved")
+	}
+	if len(store.auditEvents) != 1 {
+		t.Errorf("expected 1 audit event, got %d", len(store.auditEvents))
+	}
+}
+
+func TestSetTravelMode(t *testing.T) {
+	store := newMockStore()
+	store.family.Timezone = "America/Toronto"
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), store.family.ID)
+
+	start := time.Date(2026, 7, 1, 15, 30, 0, 0, time.UTC)
+	_, err := mr.SetTravelMode(ctx, &model.TravelModeInput{Timezone: "Asia/Kolkata", StartDate: start, EndDate: start.AddDate(0, 0, -1)})
+	if err == nil {
+		t.Fatal("expected an error for an end date before the start date")
+	}
+
+	family, err := mr.SetTravelMode(ctx, &model.TravelModeInput{Timezone: "Asia/Kolkata", StartDate: start, EndDate: start.AddDate(0, 0, 13)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if family.TravelMode == nil || family.TravelMode.Timezone != "Asia/Kolkata" {
+		t.Fatalf("travelMode = %+v, want Asia/Kolkata", family.TravelMode)
+	}
+	if want := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC); !family.TravelMode.StartDate.Equal(want) {
+		t.Errorf("startDate = %v, want the date alone, %v", family.TravelMode.StartDate, want)
+	}
+	if family.Timezone == nil || *family.Timezone != "America/Toronto" {
+		t.Errorf("timezone = %v, want the home timezone kept", family.Timezone)
+	}
+
+	family, err = mr.SetTravelMode(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if family.TravelMode != nil || store.updatedFamily.Travel != nil {
+		t.Error("expected travel mode to be turned off")
+	}
+}
+
+func TestDetectedPatterns(t *testing.T) {
+	store := newMockStore()
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), store.family.ID)
+
+	now := time.Now()
+	formula := domain.FeedTypeFormula
+	for _, start := range []time.Time{
+		// A cluster three weeks ago, outside the default window
+		now.AddDate(0, 0, -21), now.AddDate(0, 0, -21).Add(40 * time.Minute), now.AddDate(0, 0, -21).Add(80 * time.Minute),
+		// One going on now
+		now.Add(-90 * time.Minute), now.Add(-time.Hour), now.Add(-20 * time.Minute),
+	} {
+		store.recentFeedDetails = append(store.recentFeedDetails, &domain.FeedDetails{ID: uuid.New(), StartTime: start, FeedType: &formula})
+	}
+
+	result, err := qr.DetectedPatterns(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 pattern in the last week, got %d", len(result))
+	}
+	if p := result[0]; p.Type != model.DetectedPatternTypeClusterFeeding || p.FeedCount != 3 || !p.Ongoing || p.Increase != nil {
+		t.Errorf("expected an ongoing cluster of 3 feeds, got %+v", p)
+	}
+
+	month, zero := int32(30), int32(0)
+	result, err = qr.DetectedPatterns(ctx, &month, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 || result[0].Ongoing {
+		t.Errorf("expected both clusters over 30 days, the first one over, got %+v", result)
+	}
+
+	if _, err := qr.DetectedPatterns(ctx, &zero, nil); err == nil {
+		t.Error("expected an error for zero days")
+	}
+}
+
+func TestSetPredictionModel(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), store.family.ID)
+	day, zero := int32(24), int32(0)
+
+	if _, err := mr.SetPredictionModel(ctx, model.PredictionModelMedian, &day); err == nil {
+		t.Error("expected an error for a half-life with the median")
+	}
+	if _, err := mr.SetPredictionModel(ctx, model.PredictionModelRecencyWeighted, &zero); err == nil {
+		t.Error("expected an error for a zero half-life")
+	}
+	if store.updatedFamily != nil {
+		t.Fatal("expected the family to be left alone")
+	}
+
+	family, err := mr.SetPredictionModel(ctx, model.PredictionModelRecencyWeighted, &day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if family.PredictionModel != model.PredictionModelRecencyWeighted || family.PredictionHalfLifeHours == nil || *family.PredictionHalfLifeHours != 24 {
+		t.Errorf("got %v with half-life %v, want RECENCY_WEIGHTED with 24", family.PredictionModel, family.PredictionHalfLifeHours)
+	}
+	if store.updatedFamily.PredictionModel != domain.PredictionModelRecencyWeighted || store.updatedFamily.PredictionHalfLife != 24*time.Hour {
+		t.Errorf("saved %q with half-life %v", store.updatedFamily.PredictionModel, store.updatedFamily.PredictionHalfLife)
+	}
+
+	family, err = mr.SetPredictionModel(ctx, model.PredictionModelMedian, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if family.PredictionModel != model.PredictionModelMedian || family.PredictionHalfLifeHours != nil {
+		t.Errorf("got %v with half-life %v, want MEDIAN", family.PredictionModel, family.PredictionHalfLifeHours)
+	}
+}
+
+func TestFamilyTimezone(t *testing.T) {
+	store := newMockStore()
+	r := NewResolver(store)
+	familyID := store.family.ID
+	ctx := withTimezone(withAuth(context.Background(), uuid.New(), familyID), "Europe/Paris")
+	now := time.Date(2026, 7, 5, 12, 0, 0, 0, time.UTC)
+
+	// A family without a timezone falls back to the phone's
+	if tz, _ := r.familyTimezone(ctx, familyID, now); tz != "Europe/Paris" {
+		t.Errorf("timezone = %q, want the X-Timezone fallback", tz)
+	}
+
+	store.family.Timezone = "America/Toronto"
+	if tz, _ := r.familyTimezone(ctx, familyID, now); tz != "America/Toronto" {
+		t.Errorf("timezone = %q, want the family's over the header", tz)
+	}
+
+	store.family.Travel = &domain.TravelMode{
+		Timezone:  "Asia/Kolkata",
+		StartDate: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 7, 14, 0, 0, 0, 0, time.UTC),
+	}
+	if tz, _ := r.familyTimezone(ctx, familyID, now); tz != "Asia/Kolkata" {
+		t.Errorf("timezone = %q, want the travel timezone", tz)
+	}
+	if tz, _ := r.familyTimezone(ctx, familyID, now.AddDate(0, 0, 10)); tz != "America/Toronto" {
+		t.Errorf("timezone = %q, want home again after the trip", tz)
+	}
+}
+
+// ==================== Subscription Tests ====================
+
+func TestStartCareSession_PublishesCareSessionUpdated(t *testing.T) {
+	store := newMockStore()
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+	store.caregiverByID = &domain.Caregiver{ID: caregiverID, FamilyID: familyID, Name: "Alice"}
+
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+
+	events, cancel := resolver.events.Subscribe(familyID)
+	defer cancel()
+
+	ctx := withAuth(context.Background(), caregiverID, familyID)
+	session, err := mr.StartCareSession(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != pubsub.EventCareSessionUpdated {
+			t.Errorf("event type = %s, want %s", e.Type, pubsub.EventCareSessionUpdated)
+		}
+		if e.CareSessionID.String() != session.ID {
+			t.Errorf("event session ID = %s, want %s", e.CareSessionID, session.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for careSessionUpdated event")
+	}
+}
+
+func TestPredictionsChanged_StreamsOnPublish(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	sr := &subscriptionResolver{resolver}
+
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+	ctx, cancel := context.WithCancel(withAuth(context.Background(), caregiverID, familyID))
+
+	ch, err := sr.PredictionsChanged(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Events for other types, families or babies are ignored
+	babyID := store.babies[0].ID
+	resolver.publishActivityAdded(familyID, uuid.New(), uuid.New())
+	resolver.publishPredictionsChanged(uuid.New(), babyID)
+	resolver.publishPredictionsChanged(familyID, uuid.New())
+	resolver.publishPredictionsChanged(familyID, babyID)
+
+	select {
+	case result, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed unexpectedly")
+		}
+		if len(result) != 0 {
+			t.Errorf("expected 0 predictions for new family, got %d", len(result))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for predictionsChanged payload")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected channel to be closed after context cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after context cancel")
+	}
+}
+
+func TestPredictionsChanged_SubscriberRegenerates(t *testing.T) {
+	store := newMockStore()
+	// Old enough for age norms, so there are predictions to regenerate
+	birthDate := time.Now().AddDate(0, -2, 0)
+	store.babies[0].BirthDate = &birthDate
+	resolver := NewResolver(store)
+	sr := &subscriptionResolver{resolver}
+
+	familyID := uuid.New()
+	ctx, cancel := context.WithCancel(withAuth(context.Background(), uuid.New(), familyID))
+	defer cancel()
+
+	// Publishing only notifies; nothing is regenerated without a subscriber
+	resolver.publishPredictionsChanged(familyID, store.babies[0].ID)
+	if store.upsertPredictionsCount != 0 {
+		t.Fatalf("predictions saved %d times by the publisher, want none", store.upsertPredictionsCount)
+	}
+
+	ch, err := sr.PredictionsChanged(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resolver.publishPredictionsChanged(familyID, store.babies[0].ID)
+	select {
+	case predictions := <-ch:
+		if len(predictions) == 0 {
+			t.Error("expected regenerated predictions in the payload")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for predictionsChanged payload")
+	}
+	if store.upsertPredictionsCount != 1 {
+		t.Errorf("predictions saved %d times, want once by the subscriber", store.upsertPredictionsCount)
+	}
+}
+
+func TestSubscriptions_NotAuthenticated(t *testing.T) {
+	resolver := NewResolver(newMockStore())
+	sr := &subscriptionResolver{resolver}
+	ctx := context.Background()
+
+	if _, err := sr.CareSessionUpdated(ctx); err == nil {
+		t.Error("careSessionUpdated: expected auth error")
+	}
+	if _, err := sr.ActivityAdded(ctx); err == nil {
+		t.Error("activityAdded: expected auth error")
+	}
+	if _, err := sr.PredictionsChanged(ctx, nil); err == nil {
+		t.Error("predictionsChanged: expected auth error")
+	}
+}
+
+// ==================== Pump Activity Tests ====================
+
+func TestAddActivities_Pump_TotalFromSides(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	left, right := int32(70), int32(50)
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			ActivityType: model.ActivityTypePump,
+			PumpDetails: &model.PumpDetailsInput{
+				StartTime: time.Now(),
+				LeftMl:    &left,
+				RightMl:   &right,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	details := store.lastCreatedPumpDetails
+	if details == nil {
+		t.Fatal("expected pump details to be created")
+	}
+	if details.TotalMl == nil || *details.TotalMl != 120 {
+		t.Errorf("TotalMl = %v, want 120 (left + right)", details.TotalMl)
+	}
+}
+
+func TestAddActivities_Pump_ExplicitTotalWins(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	total := int32(120)
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			ActivityType: model.ActivityTypePump,
+			PumpDetails: &model.PumpDetailsInput{
+				StartTime: time.Now(),
+				TotalMl:   &total,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	details := store.lastCreatedPumpDetails
+	if details == nil {
+		t.Fatal("expected pump details to be created")
+	}
+	if details.TotalMl == nil || *details.TotalMl != 120 {
+		t.Errorf("TotalMl = %v, want 120", details.TotalMl)
+	}
+	if details.LeftMl != nil || details.RightMl != nil {
+		t.Errorf("expected nil per-side amounts, got left=%v right=%v", details.LeftMl, details.RightMl)
+	}
+}
+
+func TestAddActivities_Pump_MissingDetails(t *testing.T) {
+	resolver := NewResolver(newMockStore())
+	mr := &mutationResolver{resolver}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{ActivityType: model.ActivityTypePump},
+	})
+	if err == nil {
+		t.Fatal("expected error when pumpDetails is missing")
+	}
+}
+
+// ==================== Medication Tests ====================
+
+func intPtr(v int) *int { return &v }
+
+func medicationInput(drugName string, givenAt time.Time, override bool) *model.ActivityInput {
+	input := &model.ActivityInput{
+		ActivityType: model.ActivityTypeMedication,
+		MedicationDetails: &model.MedicationDetailsInput{
+			DrugName:   drugName,
+			DoseAmount: 2.5,
+			DoseUnit:   model.DoseUnitMl,
+			GivenAt:    givenAt,
+		},
+	}
+	if override {
+		input.MedicationDetails.OverrideSafetyCheck = &override
+	}
+	return input
+}
+
+func TestAddActivities_Medication_IntervalViolation(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	med := &domain.Medication{ID: uuid.New(), FamilyID: familyID, Name: "Acetaminophen", MinIntervalMinutes: intPtr(240)}
+	store.medications = []*domain.Medication{med}
+	store.recentMedicationDetails = []*domain.MedicationDetails{
+		{ID: uuid.New(), MedicationID: &med.ID, DrugName: "Acetaminophen", GivenAt: time.Now().Add(-2 * time.Hour)},
+	}
+
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{medicationInput("acetaminophen", time.Now(), false)})
+	if err == nil {
+		t.Fatal("expected dose interval violation error")
+	}
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("expected *gqlerror.Error, got %T", err)
+	}
+	if gqlErr.Extensions["code"] != ErrCodeDoseIntervalViolation {
+		t.Errorf("code = %v, want %s", gqlErr.Extensions["code"], ErrCodeDoseIntervalViolation)
+	}
+	if _, ok := gqlErr.Extensions["nextAllowedAt"]; !ok {
+		t.Error("expected nextAllowedAt extension")
+	}
+	if len(store.createdMedicationDetails) != 0 {
+		t.Errorf("expected no doses to be written, got %d", len(store.createdMedicationDetails))
+	}
+}
+
+func TestAddActivities_Medication_Override(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	med := &domain.Medication{ID: uuid.New(), FamilyID: familyID, Name: "Acetaminophen", MinIntervalMinutes: intPtr(240)}
+	store.medications = []*domain.Medication{med}
+	store.recentMedicationDetails = []*domain.MedicationDetails{
+		{ID: uuid.New(), MedicationID: &med.ID, DrugName: "Acetaminophen", GivenAt: time.Now().Add(-2 * time.Hour)},
+	}
+
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{medicationInput("Acetaminophen", time.Now(), true)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.createdMedicationDetails) != 1 {
+		t.Fatalf("expected 1 dose written, got %d", len(store.createdMedicationDetails))
+	}
+	dose := store.createdMedicationDetails[0]
+	if !dose.IntervalOverridden {
+		t.Error("expected IntervalOverridden to be recorded")
+	}
+	if dose.MedicationID == nil || *dose.MedicationID != med.ID {
+		t.Errorf("MedicationID = %v, want %v", dose.MedicationID, med.ID)
+	}
+	if dose.Route != domain.MedicationRouteOral {
+		t.Errorf("Route = %q, want default %q", dose.Route, domain.MedicationRouteOral)
+	}
+}
+
+func TestAddActivities_Medication_BatchCountsEarlierDoses(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.medications = []*domain.Medication{
+		{ID: uuid.New(), FamilyID: familyID, Name: "Ibuprofen", MinIntervalMinutes: intPtr(360)},
+	}
+
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	now := time.Now()
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		medicationInput("Ibuprofen", now.Add(-time.Hour), false),
+		medicationInput("Ibuprofen", now, false),
+	})
+	if err == nil {
+		t.Fatal("expected second dose in the same batch to violate the interval")
+	}
+}
+
+func TestAddActivities_Medication_NoCatalogEntry(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{medicationInput("Vitamin D", time.Now(), false)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.createdMedicationDetails) != 1 {
+		t.Fatalf("expected 1 dose written, got %d", len(store.createdMedicationDetails))
+	}
+	if store.createdMedicationDetails[0].MedicationID != nil {
+		t.Error("expected no catalog link for an unknown drug")
+	}
+}
+
+func TestGetMedicationStatus_NextAllowed(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	med := &domain.Medication{ID: uuid.New(), FamilyID: familyID, Name: "Acetaminophen", MinIntervalMinutes: intPtr(240)}
+	activityID := uuid.New()
+	givenAt := time.Now().Add(-time.Hour)
+	store.medications = []*domain.Medication{med}
+	store.recentMedicationDetails = []*domain.MedicationDetails{
+		{ID: uuid.New(), ActivityID: activityID, MedicationID: &med.ID, DrugName: "Acetaminophen", DoseUnit: domain.DoseUnitMl, Route: domain.MedicationRouteOral, GivenAt: givenAt},
+	}
+	store.activityByID = &domain.Activity{ID: activityID, ActivityType: domain.ActivityTypeMedication}
+
+	resolver := NewResolver(store)
+	qr := &queryResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	result, err := qr.GetMedicationStatus(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 status, got %d", len(result))
+	}
+	status := result[0]
+	if status.CanGiveNow {
+		t.Error("expected CanGiveNow = false one hour after a 4h-interval dose")
+	}
+	want := givenAt.Add(4 * time.Hour)
+	if status.NextAllowedAt == nil || !status.NextAllowedAt.Equal(want) {
+		t.Errorf("NextAllowedAt = %v, want %v", status.NextAllowedAt, want)
+	}
+	if status.LastDose == nil || status.LastDose.ID != activityID.String() {
+		t.Errorf("LastDose = %v, want activity %s", status.LastDose, activityID)
+	}
+	if status.DosesLast24Hours != 1 {
+		t.Errorf("DosesLast24Hours = %d, want 1", status.DosesLast24Hours)
+	}
+}
+
+func TestGetMedicationStatus_NotAuthenticated(t *testing.T) {
+	qr := &queryResolver{NewResolver(newMockStore())}
+
+	_, err := qr.GetMedicationStatus(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected auth error")
+	}
+}
+
+func TestDeleteMedication_OtherFamily(t *testing.T) {
+	store := newMockStore()
+	med := &domain.Medication{ID: uuid.New(), FamilyID: uuid.New(), Name: "Ibuprofen"}
+	store.medications = []*domain.Medication{med}
+
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	_, err := mr.DeleteMedication(ctx, med.ID.String())
+	if err == nil {
+		t.Fatal("expected error deleting another family's medication")
+	}
+}
+
+func TestGetBabyStatus_LastMedication(t *testing.T) {
+	store := newMockStore()
+	activityID := uuid.New()
+	store.latestActivityByType = map[domain.ActivityType]*domain.Activity{
+		domain.ActivityTypeMedication: {ID: activityID, ActivityType: domain.ActivityTypeMedication, CreatedAt: time.Now()},
+	}
+	store.medicationDetails = &domain.MedicationDetails{
+		ActivityID: activityID,
+		DrugName:   "Vitamin D",
+		DoseAmount: 1,
+		DoseUnit:   domain.DoseUnitDrops,
+		Route:      domain.MedicationRouteOral,
+		GivenAt:    time.Now(),
+	}
+
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	status, err := qr.GetBabyStatus(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.LastMedication == nil {
+		t.Fatal("expected LastMedication to be set")
+	}
+	details := status.LastMedication.MedicationDetails
+	if details == nil || details.DrugName != "Vitamin D" {
+		t.Errorf("MedicationDetails = %+v, want drug Vitamin D", details)
+	}
+	if details != nil && details.DoseUnit != model.DoseUnitDrops {
+		t.Errorf("DoseUnit = %q, want %q", details.DoseUnit, model.DoseUnitDrops)
+	}
+}
+
+func TestUpdateBaby_SetsBirthDateAndSex(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	baby := store.babies[0]
+	baby.FamilyID = familyID
+
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	birthDate := time.Date(2024, 3, 15, 22, 45, 0, 0, time.UTC)
+	sex := model.BabySexFemale
+	result, err := mr.UpdateBaby(ctx, baby.ID.String(), nil, &birthDate, &sex)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	if result.BirthDate == nil || !result.BirthDate.Equal(want) {
+		t.Errorf("BirthDate = %v, want %v", result.BirthDate, want)
+	}
+	if result.Sex == nil || *result.Sex != model.BabySexFemale {
+		t.Errorf("Sex = %v, want FEMALE", result.Sex)
+	}
+	if result.Name != "Baby" {
+		t.Errorf("Name = %q, want unchanged", result.Name)
+	}
+	if store.updatedBaby == nil || store.updatedBaby.Sex == nil || *store.updatedBaby.Sex != domain.BabySexFemale {
+		t.Error("expected baby to be saved with sex = female")
+	}
+}
+
+func TestUpdateBaby_FutureBirthDate(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	future := time.Now().AddDate(0, 0, 7)
+	if _, err := mr.UpdateBaby(ctx, store.babies[0].ID.String(), nil, &future, nil); err == nil {
+		t.Fatal("expected error for a birth date in the future")
+	}
+	if store.updatedBaby != nil {
+		t.Error("expected baby not to be saved")
+	}
+}
+
+func TestUpdateBaby_OtherFamily(t *testing.T) {
+	store := newMockStore()
+	store.babies[0].FamilyID = uuid.New()
+
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	name := "Ava"
+	if _, err := mr.UpdateBaby(ctx, store.babies[0].ID.String(), &name, nil, nil); err == nil {
+		t.Fatal("expected error when updating another family's baby")
+	}
+	if store.updatedBaby != nil {
+		t.Error("expected baby not to be saved")
+	}
+}
+
+func TestAddGrowthMeasurement_ReturnsPercentiles(t *testing.T) {
+	store := newMockStore()
+	birthDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sex := domain.BabySexMale
+	store.babies[0].BirthDate = &birthDate
+	store.babies[0].Sex = &sex
+
+	mr := &mutationResolver{NewResolver(store)}
+	caregiverID := uuid.New()
+	ctx := withAuth(context.Background(), caregiverID, uuid.New())
+
+	// WHO median weight for boys at 6 months
+	weight := 7.934
+	result, err := mr.AddGrowthMeasurement(ctx, model.GrowthMeasurementInput{
+		MeasuredAt: birthDate.AddDate(0, 0, 183),
+		WeightKg:   &weight,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.AgeMonths == nil {
+		t.Fatal("expected AgeMonths to be set")
+	}
+	if result.WeightPercentile == nil {
+		t.Fatal("expected WeightPercentile to be set")
+	}
+	if result.WeightPercentile.Percentile < 45 || result.WeightPercentile.Percentile > 55 {
+		t.Errorf("Percentile = %v, want ~50", result.WeightPercentile.Percentile)
+	}
+	if result.LengthPercentile != nil {
+		t.Error("expected no LengthPercentile when length wasn't measured")
+	}
+
+	if len(store.growthMeasurements) != 1 {
+		t.Fatalf("expected 1 measurement saved, got %d", len(store.growthMeasurements))
+	}
+	saved := store.growthMeasurements[0]
+	if saved.CaregiverID == nil || *saved.CaregiverID != caregiverID {
+		t.Errorf("CaregiverID = %v, want %v", saved.CaregiverID, caregiverID)
+	}
+	if saved.BabyID != store.babies[0].ID {
+		t.Errorf("BabyID = %v, want %v", saved.BabyID, store.babies[0].ID)
+	}
+}
+
+func TestAddGrowthMeasurement_RequiresAValue(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	_, err := mr.AddGrowthMeasurement(ctx, model.GrowthMeasurementInput{MeasuredAt: time.Now()})
+	if err == nil {
+		t.Fatal("expected error when no measurement values are given")
+	}
+	if len(store.growthMeasurements) != 0 {
+		t.Error("expected nothing to be saved")
+	}
+}
+
+func TestGrowthHistory_NoBirthDate(t *testing.T) {
+	store := newMockStore()
+	store.family = &domain.Family{ID: uuid.New()}
+	weight := 5.2
+	store.growthMeasurements = []*domain.GrowthMeasurement{
+		{ID: uuid.New(), FamilyID: store.family.ID, MeasuredAt: time.Now(), WeightKg: &weight},
+	}
+
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), store.family.ID)
+
+	result, err := qr.GrowthHistory(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 measurement, got %d", len(result))
+	}
+	if result[0].WeightKg == nil || *result[0].WeightKg != 5.2 {
+		t.Errorf("WeightKg = %v, want 5.2", result[0].WeightKg)
+	}
+	if result[0].AgeMonths != nil || result[0].WeightPercentile != nil {
+		t.Error("expected no age or percentile without a birth date")
+	}
+}
+
+func TestGrowthHistory_NotAuthenticated(t *testing.T) {
+	qr := &queryResolver{NewResolver(newMockStore())}
+
+	_, err := qr.GrowthHistory(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected auth error")
+	}
+}
+
+// ==================== Baby Tests ====================
+
+// withTwins replaces the mock's default baby with two babies in the given family.
+func withTwins(store *mockStore, familyID uuid.UUID) (*domain.Baby, *domain.Baby) {
+	ava := &domain.Baby{ID: uuid.New(), FamilyID: familyID, Name: "Ava"}
+	ben := &domain.Baby{ID: uuid.New(), FamilyID: familyID, Name: "Ben"}
+	store.babies = []*domain.Baby{ava, ben}
+	return ava, ben
+}
+
+func TestCreateFamily_CreatesFirstBaby(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+
+	deviceID := "test-device-123"
+	result, err := mr.CreateFamily(context.Background(), "TestFamily", "password123", "Emma", "Mom", &deviceID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", *result.Error)
+	}
+
+	baby := store.lastCreatedBaby
+	if baby == nil {
+		t.Fatal("expected first baby to be created with the family")
+	}
+	if baby.Name != "Emma" {
+		t.Errorf("Name = %q, want Emma", baby.Name)
+	}
+	if baby.FamilyID.String() != result.Family.ID {
+		t.Errorf("FamilyID = %s, want %s", baby.FamilyID, result.Family.ID)
+	}
+}
+
+func TestAddBaby_Success(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	sex := model.BabySexMale
+	result, err := mr.AddBaby(ctx, "  Ben ", nil, &sex)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Name != "Ben" {
+		t.Errorf("Name = %q, want Ben", result.Name)
+	}
+	if result.FamilyID != familyID.String() {
+		t.Errorf("FamilyID = %s, want %s", result.FamilyID, familyID)
+	}
+	if result.Sex == nil || *result.Sex != model.BabySexMale {
+		t.Errorf("Sex = %v, want MALE", result.Sex)
+	}
+	if len(store.babies) != 2 {
+		t.Errorf("expected 2 babies, got %d", len(store.babies))
+	}
+}
+
+func TestAddBaby_DuplicateName(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	withTwins(store, familyID)
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	if _, err := mr.AddBaby(ctx, "ava", nil, nil); err == nil {
+		t.Fatal("expected error for duplicate baby name")
+	}
+	if store.lastCreatedBaby != nil {
+		t.Error("expected baby not to be created")
+	}
+}
+
+func TestAddActivities_MultipleBabies_RequiresBabyID(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	withTwins(store, familyID)
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			ActivityType:  model.ActivityTypeDiaper,
+			DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error when babyId is missing for a family with twins")
+	}
+	if len(store.createdActivities) != 0 {
+		t.Errorf("expected nothing to be written, got %d activities", len(store.createdActivities))
+	}
+}
+
+func TestAddActivities_TargetsBaby(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	_, ben := withTwins(store, familyID)
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	benID := ben.ID.String()
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			BabyID:        &benID,
+			ActivityType:  model.ActivityTypeDiaper,
+			DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.createdActivities) != 1 || store.createdActivities[0].BabyID != ben.ID {
+		t.Fatalf("expected one activity for Ben, got %+v", store.createdActivities)
+	}
+	if len(store.deletedPredictionBabyIDs) != 1 || store.deletedPredictionBabyIDs[0] != ben.ID {
+		t.Errorf("expected only Ben's predictions to be invalidated, got %v", store.deletedPredictionBabyIDs)
+	}
+}
+
+func TestAddActivities_OtherFamilysBaby(t *testing.T) {
+	store := newMockStore()
+	store.babies[0].FamilyID = uuid.New()
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	babyID := store.babies[0].ID.String()
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			BabyID:        &babyID,
+			ActivityType:  model.ActivityTypeDiaper,
+			DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error when logging for another family's baby")
+	}
+}
+
+func TestUpdateActivity_RejectsBabyChange(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	ava, ben := withTwins(store, familyID)
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: ava.ID, ActivityType: domain.ActivityTypeDiaper}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	benID := ben.ID.String()
+	_, err := mr.UpdateActivity(ctx, store.activityByID.ID.String(), model.ActivityInput{
+		BabyID:        &benID,
+		ActivityType:  model.ActivityTypeDiaper,
+		DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected error when moving an activity to another baby")
+	}
+}
+
+func TestGetBabyStatus_ForBaby(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	_, ben := withTwins(store, familyID)
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	if _, err := qr.GetBabyStatus(ctx, nil); err == nil {
+		t.Error("expected error without babyId for a family with twins")
+	}
+
+	benID := ben.ID.String()
+	result, err := qr.GetBabyStatus(ctx, &benID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Baby == nil || result.Baby.Name != "Ben" {
+		t.Errorf("Baby = %+v, want Ben", result.Baby)
+	}
+}
+
+func TestUpdateScheduleGoals_TargetsBaby(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	ava, _ := withTwins(store, familyID)
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	naps := int32(3)
+	avaID := ava.ID.String()
+	result, err := mr.UpdateScheduleGoals(ctx, &avaID, model.ScheduleGoalsInput{TargetNapCount: &naps})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.upsertedGoalsBabyID != ava.ID {
+		t.Errorf("goals saved for %s, want %s", store.upsertedGoalsBabyID, ava.ID)
+	}
+	if result.BabyID != avaID {
+		t.Errorf("BabyID = %s, want %s", result.BabyID, avaID)
+	}
+}
+
+// ==================== Transaction Tests ====================
+
+func TestAddActivities_DetailsFailureRollsBack(t *testing.T) {
+	store := newMockStore()
+	store.createFeedDetailsErr = fmt.Errorf("insert failed")
+	resolver := NewResolver(store)
+	mr := &mutationResolver{resolver}
+
+	familyID := uuid.New()
+	events, cancel := resolver.events.Subscribe(familyID)
+	defer cancel()
+
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			ActivityType: model.ActivityTypeFeed,
+			FeedDetails:  &model.FeedDetailsInput{StartTime: time.Now()},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error when feed details fail to save")
+	}
+	if store.txCount != 1 || store.rolledBackTxCount != 1 {
+		t.Errorf("txCount = %d, rolledBackTxCount = %d, want 1 and 1", store.txCount, store.rolledBackTxCount)
+	}
+	if len(store.deletedPredictionBabyIDs) != 0 {
+		t.Error("expected predictions to be left alone after a failed write")
+	}
+
+	select {
+	case e := <-events:
+		t.Errorf("unexpected %s event after rollback", e.Type)
+	default:
+	}
+}
+
+func TestUpdateActivity_RunsInTransaction(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	babyID := store.babies[0].ID
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: babyID, ActivityType: domain.ActivityTypeDiaper}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	_, err := mr.UpdateActivity(ctx, store.activityByID.ID.String(), model.ActivityInput{
+		ActivityType:  model.ActivityTypeDiaper,
+		DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.txCount != 1 || store.rolledBackTxCount != 0 {
+		t.Errorf("txCount = %d, rolledBackTxCount = %d, want 1 and 0", store.txCount, store.rolledBackTxCount)
+	}
+	if len(store.deletedPredictionBabyIDs) != 1 || store.deletedPredictionBabyIDs[0] != babyID {
+		t.Errorf("deletedPredictionBabyIDs = %v, want [%s]", store.deletedPredictionBabyIDs, babyID)
+	}
+}
+
+func TestJoinFamily_CreateCaregiverFailure(t *testing.T) {
+	store := newMockStore()
+	store.family = &domain.Family{
+		ID:           uuid.New(),
+		Name:         "TestFamily",
+		PasswordHash: hashPassword("password123"),
+	}
+	store.getCaregiverByDeviceIDErr = errNotFound
+	store.createCaregiverErr = fmt.Errorf("insert failed")
+	mr := &mutationResolver{NewResolver(store)}
+
+	deviceID := "new-device"
+	result, err := mr.JoinFamily(context.Background(), "TestFamily", "password123", "Dad", &deviceID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected failure when caregiver can't be created")
+	}
+	if store.rolledBackTxCount != 1 {
+		t.Errorf("rolledBackTxCount = %d, want 1", store.rolledBackTxCount)
+	}
+}
+
+func TestLeaveFamily_InvalidatesPredictions(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	babyID := store.babies[0].ID
+	store.caregiverByID = &domain.Caregiver{ID: uuid.New(), FamilyID: familyID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), store.caregiverByID.ID, familyID)
+
+	if _, err := mr.LeaveFamily(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.txCount != 1 {
+		t.Errorf("txCount = %d, want 1", store.txCount)
+	}
+	if len(store.deletedPredictionBabyIDs) != 1 || store.deletedPredictionBabyIDs[0] != babyID {
+		t.Errorf("deletedPredictionBabyIDs = %v, want [%s]", store.deletedPredictionBabyIDs, babyID)
+	}
+}
+
+func TestLeaveFamily_DeleteFailure(t *testing.T) {
+	store := newMockStore()
+	store.deleteCaregiverErr = fmt.Errorf("delete failed")
+	store.caregiverByID = &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New()}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), store.caregiverByID.ID, store.caregiverByID.FamilyID)
+
+	if _, err := mr.LeaveFamily(ctx); err == nil {
+		t.Fatal("expected error when caregiver can't be deleted")
+	}
+	if store.rolledBackTxCount != 1 || len(store.deletedPredictionBabyIDs) != 0 {
+		t.Errorf("rolledBackTxCount = %d, deletedPredictionBabyIDs = %v, want 1 and none",
+			store.rolledBackTxCount, store.deletedPredictionBabyIDs)
+	}
+}
+
+// ==================== Offline Sync Tests ====================
+
+func TestAddActivities_ClientIDAndReplay(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	clientID := uuid.New().String()
+	key := "add-1"
+	input := []*model.ActivityInput{
+		{
+			ID:             &clientID,
+			IdempotencyKey: &key,
+			ActivityType:   model.ActivityTypeDiaper,
+			DiaperDetails:  &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+		},
+	}
+
+	first, err := mr.AddActivities(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.createdActivities) != 1 || store.createdActivities[0].ID.String() != clientID {
+		t.Fatalf("createdActivities = %v, want one activity with client ID %s", store.createdActivities, clientID)
+	}
+
+	second, err := mr.AddActivities(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error on replay: %v", err)
+	}
+	if len(store.createdActivities) != 1 {
+		t.Errorf("replay created %d more activities, want 0", len(store.createdActivities)-1)
+	}
+	if second.ID != first.ID {
+		t.Errorf("replay session = %s, want %s", second.ID, first.ID)
+	}
+}
+
+func TestAddActivities_ClientIDFromOtherFamily(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies = []*domain.Baby{
+		{ID: uuid.New(), FamilyID: familyID, Name: "Ava"},
+	}
+	otherBaby := &domain.Baby{ID: uuid.New(), FamilyID: uuid.New(), Name: "Zed"}
+	store.babies = append(store.babies, otherBaby)
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: otherBaby.ID, ActivityType: domain.ActivityTypeDiaper}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	clientID := store.activityByID.ID.String()
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{
+			ID:            &clientID,
+			BabyID:        stringPtr(store.babies[0].ID.String()),
+			ActivityType:  model.ActivityTypeDiaper,
+			DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error when reusing another family's activity ID")
+	}
+}
+
+func TestUpdateActivity_ReplayIsNoOp(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	key := "update-1"
+	input := model.ActivityInput{
+		IdempotencyKey: &key,
+		ActivityType:   model.ActivityTypeDiaper,
+		DiaperDetails:  &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := mr.UpdateActivity(ctx, store.activityByID.ID.String(), input, nil); err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if len(store.touchedActivityIDs) != 1 {
+		t.Errorf("activity updated %d times, want 1", len(store.touchedActivityIDs))
+	}
+}
+
+func TestDeleteActivity_ReplayAfterDelete(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	activity := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.activityByID = activity
+	store.diaperDetails = &domain.DiaperDetails{ActivityID: activity.ID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	if _, err := mr.DeleteActivity(ctx, activity.ID.String(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.deletedActivities[activity.ID] == nil {
+		t.Fatal("expected a tombstone for the deleted activity")
+	}
+
+	store.activityByID = nil
+	ok, err := mr.DeleteActivity(ctx, activity.ID.String(), nil)
+	if err != nil || !ok {
+		t.Errorf("replayed delete = (%v, %v), want (true, nil)", ok, err)
+	}
+	if len(store.deletedActivityIDs) != 1 {
+		t.Errorf("DeleteActivity called %d times, want 1", len(store.deletedActivityIDs))
+	}
+}
+
+func TestSyncActivities_AppliesCreateAndAcknowledgesReplay(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	changes := []*model.SyncChangeInput{
+		{
+			Operation:      model.SyncOperationCreate,
+			ActivityID:     uuid.New().String(),
+			IdempotencyKey: "create-1",
+			ChangedAt:      time.Now().Add(-3 * time.Hour),
+			Activity: &model.ActivityInput{
+				ActivityType:  model.ActivityTypeDiaper,
+				DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now().Add(-3 * time.Hour), HadPoop: true},
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := mr.SyncActivities(ctx, changes, nil)
+		if err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", i+1, err)
+		}
+		if len(result.AppliedKeys) != 1 || result.AppliedKeys[0] != "create-1" {
+			t.Errorf("attempt %d: AppliedKeys = %v, want [create-1]", i+1, result.AppliedKeys)
+		}
+		if len(result.Conflicts) != 0 {
+			t.Errorf("attempt %d: unexpected conflicts: %+v", i+1, result.Conflicts[0])
+		}
+	}
+	if len(store.createdActivities) != 1 || store.createdActivities[0].ID.String() != changes[0].ActivityID {
+		t.Errorf("createdActivities = %v, want the one client-generated activity", store.createdActivities)
+	}
+}
+
+func TestSyncActivities_ServerNewerWins(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{
+		ID:           uuid.New(),
+		BabyID:       store.babies[0].ID,
+		ActivityType: domain.ActivityTypeDiaper,
+		UpdatedAt:    time.Now(),
+	}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID, HadPoop: true}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	result, err := mr.SyncActivities(ctx, []*model.SyncChangeInput{
+		{
+			Operation:      model.SyncOperationUpdate,
+			ActivityID:     store.activityByID.ID.String(),
+			IdempotencyKey: "update-1",
+			ChangedAt:      time.Now().Add(-time.Hour),
+			Activity: &model.ActivityInput{
+				ActivityType:  model.ActivityTypeDiaper,
+				DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: false},
+			},
+		},
+		{
+			Operation:      model.SyncOperationDelete,
+			ActivityID:     store.activityByID.ID.String(),
+			IdempotencyKey: "delete-1",
+			ChangedAt:      time.Now().Add(-time.Hour),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.AppliedKeys) != 0 {
+		t.Errorf("AppliedKeys = %v, want none", result.AppliedKeys)
+	}
+	if len(result.Conflicts) != 2 {
+		t.Fatalf("got %d conflicts, want 2", len(result.Conflicts))
+	}
+	for _, conflict := range result.Conflicts {
+		if _, ok := conflict.ServerActivity.(*model.DiaperActivity); !ok {
+			t.Errorf("%s conflict ServerActivity = %T, want the server's diaper activity", conflict.Operation, conflict.ServerActivity)
+		}
+	}
+	if len(store.touchedActivityIDs) != 0 || len(store.deletedActivityIDs) != 0 {
+		t.Error("expected the server's version to be left untouched")
+	}
+}
+
+func TestSyncActivities_ClientNewerWins(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{
+		ID:           uuid.New(),
+		BabyID:       store.babies[0].ID,
+		ActivityType: domain.ActivityTypeDiaper,
+		UpdatedAt:    time.Now().Add(-2 * time.Hour),
+	}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID, HadPoop: true}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	result, err := mr.SyncActivities(ctx, []*model.SyncChangeInput{
+		{
+			Operation:      model.SyncOperationUpdate,
+			ActivityID:     store.activityByID.ID.String(),
+			IdempotencyKey: "update-1",
+			ChangedAt:      time.Now().Add(-time.Hour),
+			Activity: &model.ActivityInput{
+				ActivityType:  model.ActivityTypeDiaper,
+				DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: false},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("unexpected conflict: %s", result.Conflicts[0].Reason)
+	}
+	if len(store.touchedActivityIDs) != 1 {
+		t.Errorf("expected the offline edit to be applied")
+	}
+	if store.idempotencyRecords["update-1"] == nil {
+		t.Error("expected the update's idempotency key to be recorded")
+	}
+}
+
+func TestSyncActivities_ReturnsServerChanges(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	changed := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.activitiesUpdatedSince = []*domain.Activity{changed}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: changed.ID}
+	deletedID := uuid.New()
+	store.deletedActivities = map[uuid.UUID]*domain.DeletedActivity{
+		deletedID: {ActivityID: deletedID, FamilyID: familyID, BabyID: store.babies[0].ID},
+	}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	before := time.Now()
+	since := before.Add(-time.Hour)
+	result, err := mr.SyncActivities(ctx, nil, &since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Changes) != 1 {
+		t.Errorf("got %d changes, want 1", len(result.Changes))
+	}
+	if len(result.DeletedActivityIds) != 1 || result.DeletedActivityIds[0] != deletedID.String() {
+		t.Errorf("DeletedActivityIds = %v, want [%s]", result.DeletedActivityIds, deletedID)
+	}
+	if result.Cursor.Before(before) {
+		t.Errorf("Cursor = %v, want at or after %v", result.Cursor, before)
+	}
+}
+
+func TestSyncActivities_OfflineDoseOverLimitIsRecorded(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	minInterval := 240
+	store.medications = []*domain.Medication{
+		{ID: uuid.New(), FamilyID: familyID, Name: "Acetaminophen", MinIntervalMinutes: &minInterval},
+	}
+	store.recentMedicationDetails = []*domain.MedicationDetails{
+		{DrugName: "Acetaminophen", MedicationID: &store.medications[0].ID, GivenAt: time.Now().Add(-time.Hour)},
+	}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	result, err := mr.SyncActivities(ctx, []*model.SyncChangeInput{
+		{
+			Operation:      model.SyncOperationCreate,
+			ActivityID:     uuid.New().String(),
+			IdempotencyKey: "med-1",
+			ChangedAt:      time.Now(),
+			Activity: &model.ActivityInput{
+				ActivityType: model.ActivityTypeMedication,
+				MedicationDetails: &model.MedicationDetailsInput{
+					DrugName:   "Acetaminophen",
+					DoseAmount: 5,
+					DoseUnit:   model.DoseUnitMl,
+					GivenAt:    time.Now(),
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.AppliedKeys) != 1 {
+		t.Fatalf("AppliedKeys = %v, want [med-1]", result.AppliedKeys)
+	}
+	if len(store.createdMedicationDetails) != 1 || !store.createdMedicationDetails[0].IntervalOverridden {
+		t.Error("expected the offline dose to be recorded as overridden")
+	}
+}
+
+// ==================== Reminder Preferences Tests ====================
+
+func TestReminderPreferences_DefaultsWhenUnset(t *testing.T) {
+	qr := &queryResolver{NewResolver(newMockStore())}
+	caregiverID := uuid.New()
+	ctx := withAuth(context.Background(), caregiverID, uuid.New())
+
+	result, err := qr.ReminderPreferences(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.CaregiverID != caregiverID.String() {
+		t.Errorf("CaregiverID = %s, want %s", result.CaregiverID, caregiverID)
+	}
+	if result.FeedReminders || result.NapReminders || result.BedtimeReminders || result.OverdueReminders {
+		t.Errorf("expected every reminder off, got %+v", result)
+	}
+	if result.LeadMinutes != domain.DefaultReminderLeadMinutes {
+		t.Errorf("LeadMinutes = %d, want %d", result.LeadMinutes, domain.DefaultReminderLeadMinutes)
+	}
+}
+
+func TestUpdateReminderPreferences_SavesForCaller(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	caregiverID := uuid.New()
+	ctx := withAuth(context.Background(), caregiverID, uuid.New())
+
+	lead := int32(30)
+	result, err := mr.UpdateReminderPreferences(ctx, model.ReminderPreferencesInput{NapReminders: true, OverdueReminders: true, LeadMinutes: &lead})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.NapReminders || !result.OverdueReminders || result.FeedReminders || result.LeadMinutes != 30 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	saved := store.reminderPreferences[caregiverID]
+	if saved == nil || !saved.NapReminders || saved.LeadMinutes != 30 {
+		t.Errorf("expected preferences saved for the caller, got %+v", saved)
+	}
+}
+
+func TestUpdateReminderPreferences_NotAuthenticated(t *testing.T) {
+	mr := &mutationResolver{NewResolver(newMockStore())}
+
+	_, err := mr.UpdateReminderPreferences(context.Background(), model.ReminderPreferencesInput{FeedReminders: true})
+	if err == nil {
+		t.Fatal("expected auth error")
+	}
+}
+
+func TestAddActivities_QueuesWebhooks(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	familyID := uuid.New()
+	store.webhookSubscriptions = []*domain.WebhookSubscription{
+		{ID: uuid.New(), FamilyID: familyID, URL: "https://example.com/all", Secret: "s"},
+		{ID: uuid.New(), FamilyID: familyID, URL: "https://example.com/sessions", Secret: "s", EventTypes: []domain.WebhookEventType{domain.WebhookEventSessionStarted}},
+		{ID: uuid.New(), FamilyID: uuid.New(), URL: "https://example.com/other", Secret: "s"},
+	}
+
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+	_, err := mr.AddActivities(ctx, []*model.ActivityInput{
+		{ActivityType: model.ActivityTypeDiaper, DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, d := range store.webhookDeliveries {
+		got = append(got, string(d.EventType)+" -> "+d.SubscriptionID.String())
+	}
+	want := []string{
+		"session_started -> " + store.webhookSubscriptions[0].ID.String(),
+		"session_started -> " + store.webhookSubscriptions[1].ID.String(),
+		"activity_added -> " + store.webhookSubscriptions[0].ID.String(),
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("deliveries = %v, want %v", got, want)
+	}
+}
+
+func TestCreateWebhookSubscription_SavesForFamily(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	familyID := uuid.New()
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	result, err := mr.CreateWebhookSubscription(ctx, model.WebhookSubscriptionInput{
+		URL:        "https://home.example.com/hooks",
+		Secret:     "s3cret",
+		EventTypes: []model.WebhookEventType{model.WebhookEventTypePredictionOverdue},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.EventTypes) != 1 || result.EventTypes[0] != model.WebhookEventTypePredictionOverdue {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(store.webhookSubscriptions) != 1 || store.webhookSubscriptions[0].FamilyID != familyID || store.webhookSubscriptions[0].Secret != "s3cret" {
+		t.Errorf("expected subscription saved for the caller's family, got %+v", store.webhookSubscriptions)
+	}
+
+	if _, err := mr.CreateWebhookSubscription(ctx, model.WebhookSubscriptionInput{URL: "not a url", Secret: "s"}); err == nil {
+		t.Error("expected error for an invalid URL")
+	}
+}
+
+func TestDeleteWebhookSubscription_OtherFamily(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	sub := &domain.WebhookSubscription{ID: uuid.New(), FamilyID: uuid.New(), URL: "https://example.com", Secret: "s"}
+	store.webhookSubscriptions = []*domain.WebhookSubscription{sub}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+	if _, err := mr.DeleteWebhookSubscription(ctx, sub.ID.String()); err == nil {
+		t.Fatal("expected error deleting another family's subscription")
+	}
+	if len(store.webhookSubscriptions) != 1 {
+		t.Error("expected subscription to be kept")
+	}
+
+	ctx = withAuth(context.Background(), uuid.New(), sub.FamilyID)
+	ok, err := mr.DeleteWebhookSubscription(ctx, sub.ID.String())
+	if err != nil || !ok {
+		t.Fatalf("DeleteWebhookSubscription() = %v, %v", ok, err)
+	}
+	if len(store.webhookSubscriptions) != 0 {
+		t.Error("expected subscription to be deleted")
+	}
+}
+
+func TestWebhookDeliveries_FiltersByStatus(t *testing.T) {
+	store := newMockStore()
+	qr := &queryResolver{NewResolver(store)}
+	familyID := uuid.New()
+	failed := &domain.WebhookDelivery{ID: uuid.New(), FamilyID: familyID, EventType: domain.WebhookEventActivityAdded, Status: domain.WebhookDeliveryFailed, Attempts: 8}
+	store.webhookDeliveries = []*domain.WebhookDelivery{
+		{ID: uuid.New(), FamilyID: familyID, EventType: domain.WebhookEventActivityAdded, Status: domain.WebhookDeliveryDelivered, Attempts: 1},
+		failed,
+	}
+
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+	status := model.WebhookDeliveryStatusFailed
+	result, err := qr.WebhookDeliveries(ctx, &status, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].ID != failed.ID.String() || result[0].Status != model.WebhookDeliveryStatusFailed || result[0].EventType != model.WebhookEventTypeActivityAdded {
+		t.Errorf("unexpected deliveries: %+v", result)
+	}
+}
+
+// ==================== Invite Tests ====================
+
+// newInviteStore returns a store with one family and an invite for it with the given code
+func newInviteStore(code string, configure func(*domain.FamilyInvite)) (*mockStore, *domain.FamilyInvite) {
+	store := newMockStore()
+	store.family = &domain.Family{ID: uuid.New(), Name: "TestFamily", BabyName: "Baby"}
+	store.getCaregiverByDeviceIDErr = errNotFound
+
+	familyInvite := &domain.FamilyInvite{
+		ID:        uuid.New(),
+		FamilyID:  store.family.ID,
+		CodeHash:  invite.Hash(code),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if configure != nil {
+		configure(familyInvite)
+	}
+	store.invites = []*domain.FamilyInvite{familyInvite}
+	return store, familyInvite
+}
+
+func TestCreateInvite_StoresOnlyHash(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	resolver.SetInviteLinkBase("https://babybaton.app/join")
+	mr := &mutationResolver{resolver}
+
+	caregiverID, familyID := uuid.New(), uuid.New()
+	maxUses := int32(2)
+	result, err := mr.CreateInvite(withAuth(context.Background(), caregiverID, familyID), &model.CreateInviteInput{MaxUses: &maxUses})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Code == "" || result.Link == nil || *result.Link != "https://babybaton.app/join?code="+result.Code {
+		t.Errorf("unexpected code and link: %q, %v", result.Code, result.Link)
+	}
+	if len(store.invites) != 1 {
+		t.Fatalf("expected 1 stored invite, got %d", len(store.invites))
+	}
+	stored := store.invites[0]
+	if stored.CodeHash != invite.Hash(result.Code) || stored.CodeHash == result.Code {
+		t.Error("expected only the code's hash to be stored")
+	}
+	if stored.FamilyID != familyID || stored.CreatedBy == nil || *stored.CreatedBy != caregiverID {
+		t.Errorf("unexpected invite owner: %+v", stored)
+	}
+	if stored.MaxUses == nil || *stored.MaxUses != 2 || !result.Invite.Active {
+		t.Errorf("unexpected invite: %+v", result.Invite)
+	}
+	if d := time.Until(stored.ExpiresAt); d < domain.DefaultInviteTTL-time.Minute || d > domain.DefaultInviteTTL {
+		t.Errorf("expected default expiry, got %v", d)
+	}
+}
+
+func TestCreateInvite_RejectsLongExpiry(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+
+	hours := int32(24*30 + 1)
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+	if _, err := mr.CreateInvite(ctx, &model.CreateInviteInput{ExpiresInHours: &hours}); err == nil {
+		t.Fatal("expected error for an expiry over 30 days")
+	}
+	if len(store.invites) != 0 {
+		t.Error("expected no invite to be stored")
+	}
+}
+
+func TestJoinFamilyWithInvite_Success(t *testing.T) {
+	store, familyInvite := newInviteStore("ABCD-EFGH-JKMN", nil)
+	mr := &mutationResolver{NewResolver(store)}
+
+	deviceID := "new-device"
+	result, err := mr.JoinFamilyWithInvite(context.Background(), "abcd efgh jkmn", "Grandma", &deviceID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", *result.Error)
+	}
+	if result.Family.ID != store.family.ID.String() || store.lastCreatedCaregiver == nil || store.lastCreatedCaregiver.FamilyID != store.family.ID {
+		t.Error("expected a caregiver to be created in the invite's family")
+	}
+	if familyInvite.UseCount != 1 {
+		t.Errorf("expected invite to be used once, got %d", familyInvite.UseCount)
+	}
+}
+
+func TestJoinFamilyWithInvite_InactiveInvites(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	one := 1
+	tests := []struct {
+		name      string
+		code      string
+		configure func(*domain.FamilyInvite)
+	}{
+		{"unknown", "ZZZZ-ZZZZ-ZZZZ", nil},
+		{"expired", "ABCD-EFGH-JKMN", func(i *domain.FamilyInvite) { i.ExpiresAt = past }},
+		{"revoked", "ABCD-EFGH-JKMN", func(i *domain.FamilyInvite) { i.RevokedAt = &past }},
+		{"used up", "ABCD-EFGH-JKMN", func(i *domain.FamilyInvite) { i.MaxUses = &one; i.UseCount = 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := newInviteStore("ABCD-EFGH-JKMN", tt.configure)
+			mr := &mutationResolver{NewResolver(store)}
+
+			deviceID := "new-device"
+			result, err := mr.JoinFamilyWithInvite(context.Background(), tt.code, "Grandma", &deviceID, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success || result.Error == nil || *result.Error != "Invite is invalid or has expired" {
+				t.Errorf("expected invalid invite error, got %+v", result)
+			}
+			if store.lastCreatedCaregiver != nil {
+				t.Error("expected no caregiver to be created")
+			}
+		})
+	}
+}
+
+func TestJoinFamilyWithInvite_ReauthDoesNotUseInvite(t *testing.T) {
+	deviceID := "existing-device"
+	store, familyInvite := newInviteStore("ABCD-EFGH-JKMN", nil)
+	store.getCaregiverByDeviceIDErr = nil
+	store.caregiverByDeviceID = &domain.Caregiver{ID: uuid.New(), FamilyID: store.family.ID, Name: "Grandma", DeviceID: &deviceID}
+	mr := &mutationResolver{NewResolver(store)}
+
+	result, err := mr.JoinFamilyWithInvite(context.Background(), "ABCD-EFGH-JKMN", "Grandma", &deviceID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success || result.Caregiver.ID != store.caregiverByDeviceID.ID.String() {
+		t.Fatalf("expected re-auth as the existing caregiver, got %+v", result)
+	}
+	if familyInvite.UseCount != 0 {
+		t.Errorf("expected re-auth not to use the invite, got %d uses", familyInvite.UseCount)
+	}
+}
+
+func TestRevokeInvite_OtherFamily(t *testing.T) {
+	store, familyInvite := newInviteStore("ABCD-EFGH-JKMN", nil)
+	mr := &mutationResolver{NewResolver(store)}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+	if _, err := mr.RevokeInvite(ctx, familyInvite.ID.String()); err == nil {
+		t.Fatal("expected error revoking another family's invite")
+	}
+	if familyInvite.RevokedAt != nil {
+		t.Error("expected invite to stay active")
+	}
+
+	ctx = withAuth(context.Background(), uuid.New(), store.family.ID)
+	result, err := mr.RevokeInvite(ctx, familyInvite.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Active || result.RevokedAt == nil {
+		t.Errorf("expected invite to be revoked, got %+v", result)
+	}
+}
+
+// ==================== Rate Limit Tests ====================
+
+func TestJoinFamily_LocksOutAfterRepeatedFailures(t *testing.T) {
+	store := newMockStore()
+	store.family = &domain.Family{
+		ID:           uuid.New(),
+		Name:         "TestFamily",
+		PasswordHash: hashPassword("password123"),
+		BabyName:     "Baby",
+	}
+	store.getCaregiverByDeviceIDErr = errNotFound
+
+	resolver := NewResolver(store)
+	resolver.SetRateLimiter(ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), map[ratelimit.Action]ratelimit.Policy{
+		ratelimit.ActionJoinFamily: {Lockout: ratelimit.Lockout{Threshold: 3, Base: time.Minute, Max: time.Hour, Window: time.Hour}},
+	}))
+	mr := &mutationResolver{resolver}
+	ctx := ratelimit.WithClientIP(context.Background(), "203.0.113.7")
+
+	deviceID := "test-device"
+	for i := range 3 {
+		result, err := mr.JoinFamily(ctx, "TestFamily", "wrongpassword", "Dad", &deviceID, nil)
+		if err != nil || result.Success {
+			t.Fatalf("attempt %d: expected a failed join, got %+v (err %v)", i+1, result, err)
+		}
+	}
+
+	// Even the right password is refused while locked out
+	_, err := mr.JoinFamily(ctx, "TestFamily", "password123", "Dad", &deviceID, nil)
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("expected *gqlerror.Error, got %v", err)
+	}
+	if gqlErr.Extensions["code"] != ratelimit.ErrCodeRateLimited || gqlErr.Extensions["lockedOut"] != true {
+		t.Errorf("unexpected extensions: %v", gqlErr.Extensions)
+	}
+	if secs, _ := gqlErr.Extensions["retryAfterSeconds"].(int); secs < 59 || secs > 60 {
+		t.Errorf("retryAfterSeconds = %v, want about 60", gqlErr.Extensions["retryAfterSeconds"])
+	}
+	if store.lastCreatedCaregiver != nil {
+		t.Error("expected no caregiver to be created while locked out")
+	}
+
+	// Other families can still be joined from another device and IP
+	otherCtx := ratelimit.WithClientIP(context.Background(), "198.51.100.1")
+	store.family.Name = "OtherFamily"
+	otherDevice := "other-device"
+	if result, err := mr.JoinFamily(otherCtx, "OtherFamily", "password123", "Mom", &otherDevice, nil); err != nil || !result.Success {
+		t.Errorf("expected an unrelated join to succeed, got %+v (err %v)", result, err)
+	}
+}
+
+func TestCheckFamilyNameAvailable_RateLimited(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	resolver.SetRateLimiter(ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), map[ratelimit.Action]ratelimit.Policy{
+		ratelimit.ActionCheckFamilyName: {Limits: map[ratelimit.Scope]ratelimit.Limit{
+			ratelimit.ScopeIP: {Attempts: 2, Window: time.Minute},
+		}},
+	}))
+	qr := &queryResolver{resolver}
+	ctx := ratelimit.WithClientIP(context.Background(), "203.0.113.7")
+
+	for i := range 2 {
+		if _, err := qr.CheckFamilyNameAvailable(ctx, fmt.Sprintf("Family%d", i)); err != nil {
+			t.Fatalf("check %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	_, err := qr.CheckFamilyNameAvailable(ctx, "Family3")
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) || gqlErr.Extensions["code"] != ratelimit.ErrCodeRateLimited || gqlErr.Extensions["scope"] != "ip" {
+		t.Fatalf("expected an IP rate limit error, got %v", err)
+	}
+}
+
+func TestCreateFamily_SharesNameCheckRateLimit(t *testing.T) {
+	store := newMockStore()
+	store.familyNameExists = true
+	resolver := NewResolver(store)
+	resolver.SetRateLimiter(ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), map[ratelimit.Action]ratelimit.Policy{
+		ratelimit.ActionCheckFamilyName: {Limits: map[ratelimit.Scope]ratelimit.Limit{
+			ratelimit.ScopeIP: {Attempts: 2, Window: time.Minute},
+		}},
+	}))
+	qr := &queryResolver{resolver}
+	mr := &mutationResolver{resolver}
+	ctx := ratelimit.WithClientIP(context.Background(), "203.0.113.7")
+
+	if _, err := qr.CheckFamilyNameAvailable(ctx, "Family1"); err != nil {
+		t.Fatalf("check: unexpected error: %v", err)
+	}
+	deviceID := "probing-device"
+	if result, err := mr.CreateFamily(ctx, "Family2", "password123", "Baby", "Mom", &deviceID, nil); err != nil || result.Success {
+		t.Fatalf("create: expected a taken name, got %+v (err %v)", result, err)
+	}
+
+	_, err := mr.CreateFamily(ctx, "Family3", "password123", "Baby", "Mom", &deviceID, nil)
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) || gqlErr.Extensions["code"] != ratelimit.ErrCodeRateLimited || gqlErr.Extensions["scope"] != "ip" {
+		t.Fatalf("expected an IP rate limit error, got %v", err)
+	}
+}
+
+func TestJoinFamily_UnknownFamilyLooksLikeWrongPassword(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := context.Background()
+	deviceID := "test-device"
+
+	unknown, err := mr.JoinFamily(ctx, "NoSuchFamily", "password123", "Dad", &deviceID, nil)
+	if err != nil || unknown.Success {
+		t.Fatalf("unknown family: expected failure, got %+v (err %v)", unknown, err)
+	}
+
+	store.family = &domain.Family{
+		ID:           uuid.New(),
+		Name:         "TestFamily",
+		PasswordHash: hashPassword("password123"),
+		BabyName:     "Baby",
+	}
+	wrong, err := mr.JoinFamily(ctx, "TestFamily", "wrongpassword", "Dad", &deviceID, nil)
+	if err != nil || wrong.Success {
+		t.Fatalf("wrong password: expected failure, got %+v (err %v)", wrong, err)
+	}
+
+	if unknown.Error == nil || wrong.Error == nil || *unknown.Error != *wrong.Error {
+		t.Errorf("expected the same error for both, got %v and %v", unknown.Error, wrong.Error)
+	}
+}
+
+func TestJoinFamilyWithInvite_GuessesLockOutDevice(t *testing.T) {
+	store, _ := newInviteStore("ABCD-EFGH-JKMN", nil)
+	resolver := NewResolver(store)
+	resolver.SetRateLimiter(ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), map[ratelimit.Action]ratelimit.Policy{
+		ratelimit.ActionJoinFamilyWithInvite: {Lockout: ratelimit.Lockout{Threshold: 2, Base: time.Minute, Max: time.Hour, Window: time.Hour}},
+	}))
+	mr := &mutationResolver{resolver}
+
+	deviceID := "guessing-device"
+	for i := range 2 {
+		ctx := ratelimit.WithClientIP(context.Background(), fmt.Sprintf("203.0.113.%d", i))
+		if result, err := mr.JoinFamilyWithInvite(ctx, fmt.Sprintf("WRNG-CODE-000%d", i), "Eve", &deviceID, nil); err != nil || result.Success {
+			t.Fatalf("guess %d: expected failure, got %+v (err %v)", i+1, result, err)
+		}
+	}
+
+	ctx := ratelimit.WithClientIP(context.Background(), "203.0.113.99")
+	if _, err := mr.JoinFamilyWithInvite(ctx, "ABCD-EFGH-JKMN", "Eve", &deviceID, nil); err == nil {
+		t.Fatal("expected the device to be locked out even from a new IP")
+	}
+}
+
+// ==================== Device Token Tests ====================
+
+func TestCreateFamily_IssuesDeviceToken(t *testing.T) {
+	store := newMockStore()
+	signer := devicetoken.NewSigner([]byte("test-secret"))
+	resolver := NewResolver(store)
+	resolver.SetDeviceTokenSigner(signer)
+	mr := &mutationResolver{resolver}
+
+	deviceID := "new-device"
+	result, err := mr.CreateFamily(context.Background(), "TestFamily", "password123", "Baby", "Mom", &deviceID, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("CreateFamily() = %+v, %v", result, err)
+	}
+	if result.DeviceToken == nil {
+		t.Fatal("expected a device token")
+	}
+
+	claims, err := signer.Verify(*result.DeviceToken)
+	if err != nil {
+		t.Fatalf("expected a valid token: %v", err)
+	}
+	if claims.CaregiverID.String() != result.Caregiver.ID || claims.FamilyID.String() != result.Family.ID || claims.Generation != 0 {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestCreateFamily_UserBased_NoDeviceToken(t *testing.T) {
+	store := newMockStore()
+	resolver := NewResolver(store)
+	resolver.SetDeviceTokenSigner(devicetoken.NewSigner([]byte("test-secret")))
+	mr := &mutationResolver{resolver}
+
+	ctx := withUserID(context.Background(), uuid.New())
+	result, err := mr.CreateFamily(ctx, "TestFamily", "password123", "Baby", "Mom", nil, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("CreateFamily() = %+v, %v", result, err)
+	}
+	if result.DeviceToken != nil {
+		t.Error("expected no device token for a user account")
+	}
+}
+
+func TestJoinFamily_ReauthRotatesDeviceToken(t *testing.T) {
+	familyID := uuid.New()
+	deviceID := "existing-device"
+
+	store := newMockStore()
+	store.family = &domain.Family{
+		ID:           familyID,
+		Name:         "TestFamily",
+		PasswordHash: hashPassword("password123"),
+		BabyName:     "Baby",
+	}
+	store.caregiverByDeviceID = &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Name: "Mom", DeviceID: &deviceID}
+	signer := devicetoken.NewSigner([]byte("test-secret"))
+	resolver := NewResolver(store)
+	resolver.SetDeviceTokenSigner(signer)
+	mr := &mutationResolver{resolver}
+
+	result, err := mr.JoinFamily(context.Background(), "TestFamily", "password123", "Mom", &deviceID, nil)
+	if err != nil || !result.Success || result.DeviceToken == nil {
+		t.Fatalf("JoinFamily() = %+v, %v", result, err)
+	}
+	claims, err := signer.Verify(*result.DeviceToken)
+	if err != nil {
+		t.Fatalf("expected a valid token: %v", err)
+	}
+	if claims.Generation != 1 || store.tokenGeneration != 1 {
+		t.Errorf("expected re-joining to rotate to generation 1, got %d", claims.Generation)
+	}
+}
+
+func TestRotateDeviceToken(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New(), TokenGeneration: 4}
+	store := newMockStore()
+	store.caregiverByID = caregiver
+	store.tokenGeneration = caregiver.TokenGeneration
+	signer := devicetoken.NewSigner([]byte("test-secret"))
+	resolver := NewResolver(store)
+	resolver.SetDeviceTokenSigner(signer)
+	mr := &mutationResolver{resolver}
+
+	token, err := mr.RotateDeviceToken(withAuth(context.Background(), caregiver.ID, caregiver.FamilyID))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims, err := signer.Verify(token)
+	if err != nil || claims.CaregiverID != caregiver.ID || claims.Generation != 5 {
+		t.Errorf("expected a token at generation 5, got %+v (err %v)", claims, err)
+	}
+
+	if _, err := mr.RotateDeviceToken(context.Background()); err == nil {
+		t.Error("expected error without auth")
+	}
+}
+
+func TestRevokeDeviceToken_OtherFamily(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New()}
+	store := newMockStore()
+	store.caregiverByID = caregiver
+	mr := &mutationResolver{NewResolver(store)}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+	if _, err := mr.RevokeDeviceToken(ctx, caregiver.ID.String()); err == nil {
+		t.Fatal("expected error revoking another family's caregiver")
+	}
+	if store.tokenGeneration != 0 {
+		t.Error("expected the token to stay valid")
+	}
+
+	ctx = withAuth(context.Background(), uuid.New(), caregiver.FamilyID)
+	ok, err := mr.RevokeDeviceToken(ctx, caregiver.ID.String())
+	if err != nil || !ok {
+		t.Fatalf("RevokeDeviceToken() = %v, %v", ok, err)
+	}
+	if store.tokenGeneration != 1 {
+		t.Error("expected revoking to advance the token generation")
+	}
+}
+
+// ==================== Role Tests ====================
+
+func TestCreateFamily_CreatorIsOwner(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+
+	deviceID := "new-device"
+	result, err := mr.CreateFamily(context.Background(), "TestFamily", "password123", "Baby", "Mom", &deviceID, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("CreateFamily() = %+v, %v", result, err)
+	}
+	if result.Caregiver.Role != model.CaregiverRoleOwner {
+		t.Errorf("Role = %q, want %q", result.Caregiver.Role, model.CaregiverRoleOwner)
+	}
+}
+
+func TestJoinFamilyWithInvite_UsesInviteRole(t *testing.T) {
+	store, _ := newInviteStore("ABCD-EFGH-JKMN", func(i *domain.FamilyInvite) { i.Role = domain.RoleViewer })
+	mr := &mutationResolver{NewResolver(store)}
+
+	deviceID := "new-device"
+	result, err := mr.JoinFamilyWithInvite(context.Background(), "ABCD-EFGH-JKMN", "Grandpa", &deviceID, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("JoinFamilyWithInvite() = %+v, %v", result, err)
+	}
+	if store.lastCreatedCaregiver.Role != domain.RoleViewer {
+		t.Errorf("Role = %q, want %q", store.lastCreatedCaregiver.Role, domain.RoleViewer)
+	}
+}
+
+func TestMutations_EnforceRoles(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+	caregiverID, familyID := uuid.New(), store.babies[0].FamilyID
+
+	tests := []struct {
+		name string
+		role domain.CaregiverRole
+		call func(ctx context.Context) error
+	}{
+		{"viewer cannot start a session", domain.RoleViewer, func(ctx context.Context) error {
+			_, err := mr.StartCareSession(ctx)
+			return err
+		}},
+		{"caregiver cannot delete history", domain.RoleCaregiver, func(ctx context.Context) error {
+			_, err := mr.DeleteActivity(ctx, uuid.New().String(), nil)
+			return err
+		}},
+		{"caregiver cannot change schedule goals", domain.RoleCaregiver, func(ctx context.Context) error {
+			_, err := mr.UpdateScheduleGoals(ctx, nil, model.ScheduleGoalsInput{})
+			return err
+		}},
+		{"caregiver cannot delete a session", domain.RoleCaregiver, func(ctx context.Context) error {
+			_, err := mr.DeleteCareSession(ctx, uuid.New().String())
+			return err
+		}},
+		{"caregiver cannot restore deleted activities", domain.RoleCaregiver, func(ctx context.Context) error {
+			_, err := mr.RestoreActivity(ctx, uuid.New().String())
+			return err
+		}},
+		{"parent cannot invite", domain.RoleParent, func(ctx context.Context) error {
+			_, err := mr.CreateInvite(ctx, nil)
+			return err
+		}},
+		{"parent cannot remove caregivers", domain.RoleParent, func(ctx context.Context) error {
+			_, err := mr.RemoveCaregiver(ctx, uuid.New().String())
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(withRole(context.Background(), caregiverID, familyID, tt.role))
+			if !errors.Is(err, middleware.ErrForbidden) {
+				t.Errorf("expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+	if len(store.deletedActivityIDs) != 0 || len(store.deletedSessionIDs) != 0 || store.deleteCaregiverCalled {
+		t.Error("expected nothing to be deleted")
+	}
+}
+
+func TestSyncActivities_DeleteForbiddenIsConflict(t *testing.T) {
+	store := newMockStore()
+	familyID := store.babies[0].FamilyID
+	store.activityByID = &domain.Activity{
+		ID:           uuid.New(),
+		BabyID:       store.babies[0].ID,
+		ActivityType: domain.ActivityTypeDiaper,
+		UpdatedAt:    time.Now().Add(-time.Hour),
+	}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withRole(context.Background(), uuid.New(), familyID, domain.RoleCaregiver)
+
+	result, err := mr.SyncActivities(ctx, []*model.SyncChangeInput{{
+		Operation:      model.SyncOperationDelete,
+		ActivityID:     store.activityByID.ID.String(),
+		IdempotencyKey: "delete-1",
+		ChangedAt:      time.Now(),
+	}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Conflicts) != 1 || len(store.deletedActivityIDs) != 0 {
+		t.Fatalf("expected the delete to be rejected as a conflict, got %+v", result)
+	}
+	if _, ok := result.Conflicts[0].ServerActivity.(*model.DiaperActivity); !ok {
+		t.Errorf("ServerActivity = %T, want the server's diaper activity", result.Conflicts[0].ServerActivity)
+	}
+}
+
+func TestSetCaregiverRole_KeepsAnOwner(t *testing.T) {
+	familyID := uuid.New()
+	owner := &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Role: domain.RoleOwner}
+	nanny := &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Role: domain.RoleCaregiver}
+	store := newMockStore()
+	store.caregivers = []*domain.Caregiver{owner, nanny}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), owner.ID, familyID)
+
+	store.caregiverByID = owner
+	if _, err := mr.SetCaregiverRole(ctx, owner.ID.String(), model.CaregiverRoleParent); !errors.Is(err, errLastOwner) {
+		t.Fatalf("expected errLastOwner demoting the only owner, got %v", err)
+	}
+
+	store.caregiverByID = nanny
+	result, err := mr.SetCaregiverRole(ctx, nanny.ID.String(), model.CaregiverRoleOwner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Role != model.CaregiverRoleOwner || nanny.Role != domain.RoleOwner {
+		t.Errorf("expected the nanny to be promoted, got %q", nanny.Role)
+	}
+
+	store.caregiverByID = owner
+	if _, err := mr.SetCaregiverRole(ctx, owner.ID.String(), model.CaregiverRoleParent); err != nil {
+		t.Fatalf("expected demoting to succeed with another owner, got %v", err)
+	}
+	if owner.Role != domain.RoleParent {
+		t.Errorf("Role = %q, want %q", owner.Role, domain.RoleParent)
+	}
+}
+
+func TestSetCaregiverRole_OtherFamily(t *testing.T) {
+	store := newMockStore()
+	store.caregiverByID = &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New(), Role: domain.RoleViewer}
+	mr := &mutationResolver{NewResolver(store)}
+
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+	if _, err := mr.SetCaregiverRole(ctx, store.caregiverByID.ID.String(), model.CaregiverRoleOwner); err == nil {
+		t.Fatal("expected error changing another family's caregiver")
+	}
+}
+
+func TestRemoveCaregiver(t *testing.T) {
+	familyID := uuid.New()
+	owner := &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Role: domain.RoleOwner}
+	nanny := &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Role: domain.RoleCaregiver}
+	store := newMockStore()
+	store.caregivers = []*domain.Caregiver{owner, nanny}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), owner.ID, familyID)
+
+	store.caregiverByID = nanny
+	ok, err := mr.RemoveCaregiver(ctx, nanny.ID.String())
+	if err != nil || !ok {
+		t.Fatalf("RemoveCaregiver() = %v, %v", ok, err)
+	}
+	if !store.deleteCaregiverCalled {
+		t.Error("expected the caregiver to be deleted")
+	}
+}
+
+func TestLeaveFamily_LastOwner(t *testing.T) {
+	familyID := uuid.New()
+	owner := &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Role: domain.RoleOwner}
+	nanny := &domain.Caregiver{ID: uuid.New(), FamilyID: familyID, Role: domain.RoleCaregiver}
+	store := newMockStore()
+	store.caregivers = []*domain.Caregiver{owner, nanny}
+	store.caregiverByID = owner
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), owner.ID, familyID)
+
+	if _, err := mr.LeaveFamily(ctx); !errors.Is(err, errLastOwner) {
+		t.Fatalf("expected errLastOwner, got %v", err)
+	}
+	if store.deleteCaregiverCalled {
+		t.Error("expected the owner to stay in the family")
+	}
+
+	// The last caregiver of all can still leave
+	store.caregivers = []*domain.Caregiver{owner}
+	if _, err := mr.LeaveFamily(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !store.deleteCaregiverCalled {
+		t.Error("expected the owner to leave")
+	}
+}
+
+func TestRevokeDeviceToken_OwnDeviceNeedsNoPermission(t *testing.T) {
+	viewer := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New(), Role: domain.RoleViewer}
+	store := newMockStore()
+	store.caregiverByID = viewer
+	mr := &mutationResolver{NewResolver(store)}
+
+	ctx := withRole(context.Background(), viewer.ID, viewer.FamilyID, domain.RoleViewer)
+	if ok, err := mr.RevokeDeviceToken(ctx, viewer.ID.String()); err != nil || !ok {
+		t.Fatalf("RevokeDeviceToken() = %v, %v", ok, err)
+	}
+
+	ctx = withRole(context.Background(), uuid.New(), viewer.FamilyID, domain.RoleParent)
+	if _, err := mr.RevokeDeviceToken(ctx, viewer.ID.String()); !errors.Is(err, middleware.ErrForbidden) {
+		t.Errorf("expected ErrForbidden signing out someone else, got %v", err)
+	}
+}
+
+func TestUpdateActivity_RecordsAuditEvent(t *testing.T) {
+	store := newMockStore()
+	caregiverID, familyID := uuid.New(), uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := context.WithValue(withAuth(context.Background(), caregiverID, familyID), middleware.CaregiverNameKey, "Mom")
+
+	_, err := mr.UpdateActivity(ctx, store.activityByID.ID.String(), model.ActivityInput{
+		ActivityType:  model.ActivityTypeDiaper,
+		DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.auditEvents) != 1 {
+		t.Fatalf("recorded %d audit events, want 1", len(store.auditEvents))
+	}
+	event := store.auditEvents[0]
+	if event.FamilyID != familyID || event.ActorCaregiverID != caregiverID || event.ActorName != "Mom" {
+		t.Errorf("event actor = (%s, %s, %q), want (%s, %s, %q)", event.FamilyID, event.ActorCaregiverID, event.ActorName, familyID, caregiverID, "Mom")
+	}
+	if event.Action != domain.AuditActionUpdate || event.EntityType != domain.AuditEntityActivity || event.EntityID != store.activityByID.ID {
+		t.Errorf("event = %s %s %s, want update activity %s", event.Action, event.EntityType, event.EntityID, store.activityByID.ID)
+	}
+	if event.Before == nil || !strings.Contains(*event.Before, `"hadPoop":false`) {
+		t.Errorf("Before = %v, want the diaper without poop", event.Before)
+	}
+	if event.After == nil || !strings.Contains(*event.After, `"hadPoop":true`) {
+		t.Errorf("After = %v, want the diaper with poop", event.After)
+	}
+}
+
+func TestActivityMutations_RejectOtherFamilysActivity(t *testing.T) {
+	newStore := func() *mockStore {
+		store := newMockStore()
+		store.babies[0].FamilyID = uuid.New()
+		activity := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeSleep}
+		store.activityByID = activity
+		store.sleepDetails = &domain.SleepDetails{ID: uuid.New(), ActivityID: activity.ID, StartTime: time.Now().Add(-time.Hour)}
+		return store
+	}
+	// A caller in a second family who knows the activity's ID
+	familyID := uuid.New()
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	mutations := map[string]func(*mutationResolver, string) error{
+		"deleteActivity": func(mr *mutationResolver, id string) error {
+			_, err := mr.DeleteActivity(ctx, id, nil)
+			return err
+		},
+		"updateActivity": func(mr *mutationResolver, id string) error {
+			_, err := mr.UpdateActivity(ctx, id, model.ActivityInput{
+				ActivityType: model.ActivityTypeSleep,
+				SleepDetails: &model.SleepDetailsInput{StartTime: time.Now().Add(-2 * time.Hour)},
+			}, nil)
+			return err
+		},
+		"endActivity": func(mr *mutationResolver, id string) error {
+			_, err := mr.EndActivity(ctx, id, nil, nil)
+			return err
+		},
+	}
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+			resolver := NewResolver(store)
+			events, cancel := resolver.events.Subscribe(familyID)
+			defer cancel()
+
+			err := mutate(&mutationResolver{resolver}, store.activityByID.ID.String())
+			if err == nil || err.Error() != "activity not found" {
+				t.Fatalf("expected activity not found, got %v", err)
+			}
+			if store.txCount != 0 || len(store.auditEvents) != 0 || len(store.deletedActivities) != 0 {
+				t.Error("expected nothing to be written")
+			}
+			select {
+			case event := <-events:
+				t.Errorf("expected no events, got %s", event.Type)
+			default:
+			}
+		})
+	}
+}
+
+func TestDeleteActivity_RecordsAuditEvent(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	activity := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.activityByID = activity
+	store.diaperDetails = &domain.DiaperDetails{ActivityID: activity.ID}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	if _, err := mr.DeleteActivity(ctx, activity.ID.String(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.auditEvents) != 1 {
+		t.Fatalf("recorded %d audit events, want 1", len(store.auditEvents))
+	}
+	event := store.auditEvents[0]
+	if event.Action != domain.AuditActionDelete || event.EntityID != activity.ID {
+		t.Errorf("event = %s %s, want delete %s", event.Action, event.EntityID, activity.ID)
+	}
+	if event.Before == nil || event.After != nil {
+		t.Errorf("(Before, After) = (%v, %v), want a before snapshot only", event.Before, event.After)
+	}
+}
+
+func TestDeleteActivity_AuditFailureRollsBack(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	activity := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.activityByID = activity
+	store.diaperDetails = &domain.DiaperDetails{ActivityID: activity.ID}
+	store.createAuditEventErr = fmt.Errorf("insert failed")
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	if _, err := mr.DeleteActivity(ctx, activity.ID.String(), nil); err == nil {
+		t.Fatal("expected error when the audit event can't be recorded")
+	}
+	if store.rolledBackTxCount != 1 {
+		t.Errorf("rolledBackTxCount = %d, want 1", store.rolledBackTxCount)
+	}
+}
+
+func TestCreateFamily_AuditsCreatorAsActor(t *testing.T) {
+	store := newMockStore()
+	mr := &mutationResolver{NewResolver(store)}
+
+	deviceID := "new-device"
+	result, err := mr.CreateFamily(context.Background(), "TestFamily", "password123", "Baby", "Mom", &deviceID, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("CreateFamily() = %+v, %v", result, err)
+	}
+
+	var created *domain.AuditEvent
+	for _, event := range store.auditEvents {
+		if event.EntityType == domain.AuditEntityFamily {
+			created = event
+		}
+	}
+	if created == nil {
+		t.Fatalf("no family audit event in %v", store.auditEvents)
+	}
+	if created.Action != domain.AuditActionCreate || created.ActorCaregiverID.String() != result.Caregiver.ID || created.ActorName != "Mom" {
+		t.Errorf("event = %s by (%s, %q), want create by (%s, %q)", created.Action, created.ActorCaregiverID, created.ActorName, result.Caregiver.ID, "Mom")
+	}
+}
+
+func TestAuditLog_PaginatesAndFilters(t *testing.T) {
+	store := newMockStore()
+	caregiverID, familyID := uuid.New(), uuid.New()
+	base := time.Now().Add(-time.Hour)
+	for i, action := range []domain.AuditAction{domain.AuditActionCreate, domain.AuditActionUpdate, domain.AuditActionDelete} {
+		store.auditEvents = append(store.auditEvents, &domain.AuditEvent{
+			ID:               uuid.New(),
+			FamilyID:         familyID,
+			ActorCaregiverID: caregiverID,
+			ActorName:        "Mom",
+			Action:           action,
+			EntityType:       domain.AuditEntityActivity,
+			EntityID:         uuid.New(),
+			CreatedAt:        base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	qr := &queryResolver{NewResolver(store)}
+	ctx := withRole(context.Background(), uuid.New(), familyID, domain.RoleViewer)
+
+	page, err := qr.AuditLog(ctx, nil, 2, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Edges) != 2 || !page.PageInfo.HasNextPage {
+		t.Fatalf("first page has %d edges (hasNextPage %v), want 2 and more", len(page.Edges), page.PageInfo.HasNextPage)
+	}
+	if page.Edges[0].Node.Action != model.AuditActionDelete {
+		t.Errorf("first event = %s, want the newest (DELETE)", page.Edges[0].Node.Action)
+	}
+
+	page, err = qr.AuditLog(ctx, nil, 2, page.PageInfo.EndCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Edges) != 1 || page.PageInfo.HasNextPage || page.Edges[0].Node.Action != model.AuditActionCreate {
+		t.Errorf("second page = %d edges (hasNextPage %v), want just the CREATE", len(page.Edges), page.PageInfo.HasNextPage)
+	}
+
+	action := model.AuditActionUpdate
+	page, err = qr.AuditLog(ctx, &model.AuditLogFilter{Action: &action}, 10, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Edges) != 1 || page.Edges[0].Node.Action != model.AuditActionUpdate {
+		t.Errorf("filtered page has %d edges, want just the UPDATE", len(page.Edges))
+	}
+}
+
+func TestDeleteCareSession_TrashesSessionAndActivities(t *testing.T) {
+	store := newMockStore()
+	familyID, babyID := uuid.New(), store.babies[0].ID
+	session := &domain.CareSession{ID: uuid.New(), FamilyID: familyID, Status: domain.StatusCompleted}
+	store.createdSessions = []*domain.CareSession{session}
+	store.sessionActivities = []*domain.Activity{
+		{ID: uuid.New(), CareSessionID: session.ID, BabyID: babyID, ActivityType: domain.ActivityTypeDiaper},
+		{ID: uuid.New(), CareSessionID: session.ID, BabyID: babyID, ActivityType: domain.ActivityTypeDiaper},
+	}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	ok, err := mr.DeleteCareSession(ctx, session.ID.String())
+	if err != nil || !ok {
+		t.Fatalf("DeleteCareSession() = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	if len(store.deletedSessionIDs) != 1 || store.deletedSessionIDs[0] != session.ID {
+		t.Errorf("deletedSessionIDs = %v, want [%s]", store.deletedSessionIDs, session.ID)
+	}
+	for _, activity := range store.sessionActivities {
+		if store.deletedActivities[activity.ID] == nil {
+			t.Errorf("expected a tombstone for activity %s", activity.ID)
+		}
+	}
+	if len(store.deletedPredictionBabyIDs) != 1 || store.deletedPredictionBabyIDs[0] != babyID {
+		t.Errorf("deletedPredictionBabyIDs = %v, want [%s] once", store.deletedPredictionBabyIDs, babyID)
+	}
+	if len(store.auditEvents) != 1 || store.auditEvents[0].Action != domain.AuditActionDelete || store.auditEvents[0].EntityType != domain.AuditEntityCareSession {
+		t.Errorf("audit events = %v, want one care session delete", store.auditEvents)
+	}
+}
+
+func TestDeleteCareSession_Rejects(t *testing.T) {
+	familyID := uuid.New()
+	tests := []struct {
+		name    string
+		session *domain.CareSession
+	}{
+		{"in progress", &domain.CareSession{ID: uuid.New(), FamilyID: familyID, Status: domain.StatusInProgress}},
+		{"another family's", &domain.CareSession{ID: uuid.New(), FamilyID: uuid.New(), Status: domain.StatusCompleted}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockStore()
+			store.createdSessions = []*domain.CareSession{tt.session}
+			mr := &mutationResolver{NewResolver(store)}
+			ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+			if _, err := mr.DeleteCareSession(ctx, tt.session.ID.String()); err == nil {
+				t.Fatal("expected error")
+			}
+			if len(store.deletedSessionIDs) != 0 {
+				t.Error("expected the session to be kept")
+			}
+		})
+	}
+}
+
+func TestRestoreActivity_ClearsTombstoneAndAudits(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	deletedAt := time.Now().Add(-time.Hour)
+	activity := &domain.Activity{ID: uuid.New(), CareSessionID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, DeletedAt: &deletedAt}
+	store.recentlyDeleted = []*domain.Activity{activity}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: activity.ID, HadPoop: true}
+	store.deletedActivities = map[uuid.UUID]*domain.DeletedActivity{activity.ID: {ActivityID: activity.ID, FamilyID: familyID}}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	restored, err := mr.RestoreActivity(ctx, activity.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diaper, ok := restored.(*model.DiaperActivity); !ok || diaper.ID != activity.ID.String() || !diaper.DiaperDetails.HadPoop {
+		t.Errorf("restored = %+v, want the diaper with its details", restored)
+	}
+
+	if len(store.restoredActivityIDs) != 1 || store.restoredActivityIDs[0] != activity.ID {
+		t.Errorf("restoredActivityIDs = %v, want [%s]", store.restoredActivityIDs, activity.ID)
+	}
+	if store.deletedActivities[activity.ID] != nil {
+		t.Error("expected the tombstone to be cleared")
+	}
+	if len(store.deletedPredictionBabyIDs) != 1 {
+		t.Errorf("deletedPredictionBabyIDs = %v, want the baby's predictions invalidated", store.deletedPredictionBabyIDs)
+	}
+	if len(store.auditEvents) != 1 || store.auditEvents[0].Action != domain.AuditActionRestore || store.auditEvents[0].After == nil {
+		t.Errorf("audit events = %v, want one restore with an after snapshot", store.auditEvents)
+	}
+}
+
+func TestRestoreActivity_PastRetention(t *testing.T) {
+	store := newMockStore()
+	deletedAt := time.Now().Add(-2 * time.Hour)
+	activity := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, DeletedAt: &deletedAt}
+	store.recentlyDeleted = []*domain.Activity{activity}
+	resolver := NewResolver(store)
+	resolver.SetDeletedRetention(time.Hour)
+	mr := &mutationResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), uuid.New())
+
+	if _, err := mr.RestoreActivity(ctx, activity.ID.String()); err == nil {
+		t.Fatal("expected error restoring an activity past the retention window")
+	}
+	if len(store.restoredActivityIDs) != 0 || store.txCount != 0 {
+		t.Error("expected nothing to be restored")
+	}
+}
+
+func TestRestoreCareSession_ClearsTombstones(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	deletedAt := time.Now().Add(-time.Hour)
+	session := &domain.CareSession{ID: uuid.New(), CaregiverID: uuid.New(), FamilyID: familyID, Status: domain.StatusCompleted, DeletedAt: &deletedAt}
+	store.recentlyDeletedSessions = []*domain.CareSession{session}
+	store.caregiverByID = &domain.Caregiver{ID: session.CaregiverID, FamilyID: familyID, Name: "Mom"}
+	activity := &domain.Activity{ID: uuid.New(), CareSessionID: session.ID, BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper}
+	store.sessionActivities = []*domain.Activity{activity}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: activity.ID}
+	store.deletedActivities = map[uuid.UUID]*domain.DeletedActivity{activity.ID: {ActivityID: activity.ID, FamilyID: familyID}}
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	restored, err := mr.RestoreCareSession(ctx, session.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.ID != session.ID.String() || len(restored.Activities) != 1 {
+		t.Errorf("restored = %+v, want the session with its activity", restored)
+	}
+	if len(store.restoredSessionIDs) != 1 || store.restoredSessionIDs[0] != session.ID {
+		t.Errorf("restoredSessionIDs = %v, want [%s]", store.restoredSessionIDs, session.ID)
+	}
+	if store.deletedActivities[activity.ID] != nil {
+		t.Error("expected the activity's tombstone to be cleared")
+	}
+	if len(store.auditEvents) != 1 || store.auditEvents[0].Action != domain.AuditActionRestore || store.auditEvents[0].EntityType != domain.AuditEntityCareSession {
+		t.Errorf("audit events = %v, want one care session restore", store.auditEvents)
+	}
+}
+
+func TestRecentlyDeleted_ReportsPurgeTime(t *testing.T) {
+	store := newMockStore()
+	deletedAt := time.Now().Add(-time.Hour)
+	tooOld := time.Now().Add(-48 * time.Hour)
+	activity := &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, DeletedAt: &deletedAt}
+	store.recentlyDeleted = []*domain.Activity{
+		activity,
+		{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, DeletedAt: &tooOld},
+	}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: activity.ID}
+	resolver := NewResolver(store)
+	resolver.SetDeletedRetention(24 * time.Hour)
+	qr := &queryResolver{resolver}
+	ctx := withRole(context.Background(), uuid.New(), uuid.New(), domain.RoleViewer)
+
+	result, err := qr.RecentlyDeleted(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Activities) != 1 || len(result.CareSessions) != 0 {
+		t.Fatalf("got %d activities and %d sessions, want 1 and 0", len(result.Activities), len(result.CareSessions))
+	}
+	if got, want := result.Activities[0].PurgeAt, deletedAt.Add(24*time.Hour); !got.Equal(want) {
+		t.Errorf("PurgeAt = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateActivity_ExpectedVersion(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, Version: 2}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID}
+	store.activityVersion = 2
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	expected := int32(2)
+	result, err := mr.UpdateActivity(ctx, store.activityByID.ID.String(), model.ActivityInput{
+		ActivityType:  model.ActivityTypeDiaper,
+		DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+	}, &expected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diaper, ok := result.(*model.DiaperActivity); !ok || diaper.Version != 3 {
+		t.Errorf("result = %+v, want the diaper at version 3", result)
+	}
+}
+
+func TestUpdateActivity_VersionConflict(t *testing.T) {
+	store := newMockStore()
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, Version: 3}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID, HadPee: true}
+	store.activityVersion = 3
+	mr := &mutationResolver{NewResolver(store)}
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+	events, cancel := mr.events.Subscribe(familyID)
+	defer cancel()
+
+	stale := int32(2)
+	_, err := mr.UpdateActivity(ctx, store.activityByID.ID.String(), model.ActivityInput{
+		ActivityType:  model.ActivityTypeDiaper,
+		DiaperDetails: &model.DiaperDetailsInput{ChangedAt: time.Now(), HadPoop: true},
+	}, &stale)
+
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("expected *gqlerror.Error, got %T (%v)", err, err)
+	}
+	if gqlErr.Extensions["code"] != ErrCodeVersionConflict || gqlErr.Extensions["currentVersion"] != 3 {
+		t.Errorf("extensions = %v, want a VERSION_CONFLICT at version 3", gqlErr.Extensions)
+	}
+	server, ok := gqlErr.Extensions["serverActivity"].(*model.DiaperActivity)
+	if !ok || server.Version != 3 || !server.DiaperDetails.HadPee {
+		t.Errorf("serverActivity = %+v, want the server's diaper at version 3", gqlErr.Extensions["serverActivity"])
+	}
+	if store.rolledBackTxCount != 1 || len(store.deletedPredictionBabyIDs) != 0 {
+		t.Error("expected the edit to be rolled back")
+	}
+	select {
+	case event := <-events:
+		t.Errorf("expected no events for a rejected edit, got %s", event.Type)
+	default:
+	}
+}
+
+func TestEndActivity_VersionConflict(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeSleep, Version: 2}
+	store.sleepDetails = &domain.SleepDetails{ID: uuid.New(), ActivityID: store.activityByID.ID, StartTime: time.Now().Add(-time.Hour)}
+	store.activityVersion = 2
+	mr := &mutationResolver{NewResolver(store)}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	stale := int32(1)
+	_, err := mr.EndActivity(ctx, store.activityByID.ID.String(), nil, &stale)
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) || gqlErr.Extensions["code"] != ErrCodeVersionConflict {
+		t.Fatalf("expected a VERSION_CONFLICT error, got %v", err)
+	}
+	if len(store.auditEvents) != 0 {
+		t.Error("expected nothing to be audited")
+	}
+
+	current := int32(2)
+	result, err := mr.EndActivity(ctx, store.activityByID.ID.String(), nil, &current)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sleep, ok := result.(*model.SleepActivity); !ok || sleep.Version != 3 || sleep.SleepDetails.EndTime == nil {
+		t.Errorf("result = %+v, want the ended sleep at version 3", result)
+	}
+}
+
+func TestEndActivity_InvalidatesPredictionsInTransaction(t *testing.T) {
+	store := newMockStore()
+	familyID := uuid.New()
+	store.babies[0].FamilyID = familyID
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeSleep}
+	store.sleepDetails = &domain.SleepDetails{ID: uuid.New(), ActivityID: store.activityByID.ID, StartTime: time.Now().Add(-time.Hour)}
+	store.deletePredictionsErr = fmt.Errorf("delete failed")
+	resolver := NewResolver(store)
+	events, cancel := resolver.events.Subscribe(familyID)
+	defer cancel()
+	mr := &mutationResolver{resolver}
+	ctx := withAuth(context.Background(), uuid.New(), familyID)
+
+	if _, err := mr.EndActivity(ctx, store.activityByID.ID.String(), nil, nil); err == nil {
+		t.Fatal("expected an error when predictions can't be invalidated")
+	}
+	if store.txCount != 1 || store.rolledBackTxCount != 1 {
+		t.Errorf("txCount = %d, rolledBackTxCount = %d, want 1 and 1", store.txCount, store.rolledBackTxCount)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("expected no events for a rolled back end, got %+v", event)
+	default:
+	}
+}
+
+func TestVersionConflictError_HidesOtherFamiliesActivity(t *testing.T) {
+	store := newMockStore()
+	store.babies[0].FamilyID = uuid.New()
+	store.activityByID = &domain.Activity{ID: uuid.New(), BabyID: store.babies[0].ID, ActivityType: domain.ActivityTypeDiaper, Version: 3}
+	store.diaperDetails = &domain.DiaperDetails{ID: uuid.New(), ActivityID: store.activityByID.ID, HadPee: true}
+	store.activityVersion = 3
+	resolver := NewResolver(store)
+
+	stale := 2
+	_, conflict := store.TouchActivity(context.Background(), store.activityByID.ID, &stale)
+	err := resolver.versionConflictError(context.Background(), uuid.New(), conflict)
+
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		t.Errorf("expected the plain conflict without the other family's activity, got extensions %v", gqlErr.Extensions)
+	}
+}
@@ -0,0 +1,432 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// syncEvents collects the notifications owed for a sync batch so they can be published
+// once its changes have committed.
+type syncEvents struct {
+	sessionIDs []uuid.UUID
+	added      []*domain.Activity
+	babyIDs    []uuid.UUID
+
+	// Webhook-only events; subscribers learn about these through sessionIDs
+	startedSessions   []*domain.CareSession
+	completedSessions []*domain.CareSession
+	updated           []*domain.Activity
+	deleted           []*domain.Activity
+}
+
+func (r *Resolver) publishSyncEvents(ctx context.Context, familyID uuid.UUID, events *syncEvents) {
+	for _, session := range events.completedSessions {
+		r.emitSessionWebhook(ctx, domain.WebhookEventSessionCompleted, session)
+	}
+	for _, session := range events.startedSessions {
+		r.emitSessionWebhook(ctx, domain.WebhookEventSessionStarted, session)
+	}
+	for _, activity := range events.added {
+		r.publishActivityAdded(familyID, activity.CareSessionID, activity.ID)
+		r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityAdded, activity)
+	}
+	for _, activity := range events.updated {
+		r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityUpdated, activity)
+	}
+	for _, activity := range events.deleted {
+		r.emitActivityWebhook(ctx, familyID, domain.WebhookEventActivityDeleted, activity)
+	}
+	for _, sessionID := range uniqueIDs(events.sessionIDs) {
+		r.publishCareSessionUpdated(familyID, sessionID)
+	}
+	for _, babyID := range uniqueIDs(events.babyIDs) {
+		r.publishPredictionsChanged(familyID, babyID)
+	}
+}
+
+// saveIdempotencyKey records that a keyed write was applied. It does nothing without a key.
+func saveIdempotencyKey(ctx context.Context, tx store.Store, familyID uuid.UUID, key *string, activityID uuid.UUID, operation domain.SyncOperation) error {
+	if key == nil {
+		return nil
+	}
+	return tx.SaveIdempotencyRecord(ctx, &domain.IdempotencyRecord{
+		FamilyID:   familyID,
+		Key:        *key,
+		ActivityID: activityID,
+		Operation:  operation,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// deleteActivity removes an activity (cascading to its details), leaves a tombstone for
+// syncing clients and invalidates the baby's predictions.
+func deleteActivity(ctx context.Context, tx store.Store, familyID uuid.UUID, activity *domain.Activity, idempotencyKey *string) error {
+	before, err := loadActivityFrom(ctx, tx, activity)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.DeleteActivity(ctx, activity.ID); err != nil {
+		return fmt.Errorf("failed to delete activity: %w", err)
+	}
+	if err := audit(ctx, tx, domain.AuditActionDelete, domain.AuditEntityActivity, activity.ID, before, nil); err != nil {
+		return err
+	}
+
+	err = tx.RecordDeletedActivity(ctx, &domain.DeletedActivity{
+		ActivityID: activity.ID,
+		FamilyID:   familyID,
+		BabyID:     activity.BabyID,
+		DeletedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := saveIdempotencyKey(ctx, tx, familyID, idempotencyKey, activity.ID, domain.SyncOperationDelete); err != nil {
+		return err
+	}
+
+	if err := tx.DeletePredictionsForBaby(ctx, activity.BabyID); err != nil {
+		return fmt.Errorf("failed to invalidate predictions: %w", err)
+	}
+	return nil
+}
+
+// pendingActivities splits an addActivities batch into the inputs still to apply and the
+// activities an earlier attempt already created.
+func (r *Resolver) pendingActivities(ctx context.Context, familyID uuid.UUID, inputs []*model.ActivityInput) (pending []*model.ActivityInput, replayed []*domain.Activity, err error) {
+	for _, input := range inputs {
+		activity, applied, err := r.appliedActivity(ctx, familyID, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !applied {
+			pending = append(pending, input)
+		} else if activity != nil {
+			replayed = append(replayed, activity)
+		}
+	}
+	return pending, replayed, nil
+}
+
+// appliedActivity reports whether input was already created, matching it by idempotency key
+// or client-generated ID, and returns the activity if it still exists. A client ID that is
+// taken by another family's activity is an error.
+func (r *Resolver) appliedActivity(ctx context.Context, familyID uuid.UUID, input *model.ActivityInput) (*domain.Activity, bool, error) {
+	if input.IdempotencyKey != nil {
+		record, err := r.store.GetIdempotencyRecord(ctx, familyID, *input.IdempotencyKey)
+		if err != nil {
+			return nil, false, err
+		}
+		if record != nil {
+			activity, err := r.store.GetActivityByID(ctx, record.ActivityID)
+			if err != nil {
+				// Applied, then deleted since
+				return nil, true, nil
+			}
+			return activity, true, nil
+		}
+	}
+
+	if input.ID == nil {
+		return nil, false, nil
+	}
+	id, err := clientActivityID(input)
+	if err != nil {
+		return nil, false, err
+	}
+
+	activity, err := r.store.GetActivityByID(ctx, id)
+	if err != nil {
+		deleted, err := r.store.GetDeletedActivity(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		return nil, deleted != nil && deleted.FamilyID == familyID, nil
+	}
+
+	belongs, err := activityBelongsToFamily(ctx, r.store, activity, familyID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !belongs {
+		return nil, false, fmt.Errorf("activity ID %s is already in use", id)
+	}
+	return activity, true, nil
+}
+
+// applySyncChange applies one queued offline change, returning a conflict if the server's
+// version wins or the change can't be applied. Errors are reserved for failures the client
+// should retry.
+func (r *Resolver) applySyncChange(ctx context.Context, caregiverID, familyID uuid.UUID, change *model.SyncChangeInput, events *syncEvents) (*model.SyncConflict, error) {
+	activityID, err := uuid.Parse(change.ActivityID)
+	if err != nil {
+		return r.syncConflict(ctx, change, nil, "invalid activity ID")
+	}
+
+	// A change whose key was already applied is acknowledged without reapplying it
+	record, err := r.store.GetIdempotencyRecord(ctx, familyID, change.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		return nil, nil
+	}
+
+	activity, err := r.store.GetActivityByID(ctx, activityID)
+	if err != nil {
+		activity = nil
+	}
+	if activity != nil {
+		belongs, err := activityBelongsToFamily(ctx, r.store, activity, familyID)
+		if err != nil {
+			return nil, err
+		}
+		if !belongs {
+			return r.syncConflict(ctx, change, nil, "activity ID is already in use")
+		}
+	}
+
+	deleted, err := r.store.GetDeletedActivity(ctx, activityID)
+	if err != nil {
+		return nil, err
+	}
+	wasDeleted := deleted != nil && deleted.FamilyID == familyID
+
+	switch change.Operation {
+	case model.SyncOperationCreate:
+		if activity != nil {
+			// Created by an earlier attempt that didn't get to record its key
+			return nil, nil
+		}
+		if wasDeleted {
+			return r.syncConflict(ctx, change, nil, "activity was deleted on the server")
+		}
+		return r.syncCreate(ctx, caregiverID, familyID, activityID, change, events)
+
+	case model.SyncOperationUpdate:
+		if activity == nil {
+			if wasDeleted {
+				return r.syncConflict(ctx, change, nil, "activity was deleted on the server")
+			}
+			return r.syncConflict(ctx, change, nil, "activity not found")
+		}
+		return r.syncUpdate(ctx, familyID, activity, change, events)
+
+	case model.SyncOperationDelete:
+		// Reported as a conflict so the client restores the activity instead of retrying
+		if role, _ := middleware.GetRole(ctx); !role.Can(domain.PermissionDeleteHistory) {
+			return r.syncConflict(ctx, change, activity, fmt.Sprintf("role %q cannot delete history", role))
+		}
+		if activity == nil {
+			if wasDeleted {
+				return nil, nil
+			}
+			return r.syncConflict(ctx, change, nil, "activity not found")
+		}
+		// Last writer wins: an edit made on the server after the offline delete keeps the activity
+		if activity.UpdatedAt.After(change.ChangedAt) {
+			return r.syncConflict(ctx, change, activity, "activity was changed on the server after it was deleted")
+		}
+		err := r.store.WithTx(ctx, func(tx store.Store) error {
+			return deleteActivity(ctx, tx, familyID, activity, &change.IdempotencyKey)
+		})
+		if err != nil {
+			return nil, err
+		}
+		events.sessionIDs = append(events.sessionIDs, activity.CareSessionID)
+		events.babyIDs = append(events.babyIDs, activity.BabyID)
+		events.deleted = append(events.deleted, activity)
+		return nil, nil
+
+	default:
+		return r.syncConflict(ctx, change, nil, fmt.Sprintf("unknown operation: %s", change.Operation))
+	}
+}
+
+func (r *Resolver) syncCreate(ctx context.Context, caregiverID, familyID, activityID uuid.UUID, change *model.SyncChangeInput, events *syncEvents) (*model.SyncConflict, error) {
+	if change.Activity == nil {
+		return r.syncConflict(ctx, change, nil, "activity is required to create an activity")
+	}
+	if err := activityDetailsError(change.Activity); err != nil {
+		return r.syncConflict(ctx, change, nil, err.Error())
+	}
+	baby, err := r.resolveBaby(ctx, familyID, change.Activity.BabyID)
+	if err != nil {
+		return r.syncConflict(ctx, change, nil, err.Error())
+	}
+
+	// A dose logged offline has already been given, so one that breaks a safety limit is
+	// recorded as overridden rather than rejected
+	input := *change.Activity
+	if input.MedicationDetails != nil {
+		details := *input.MedicationDetails
+		override := true
+		details.OverrideSafetyCheck = &override
+		input.MedicationDetails = &details
+	}
+	doseChecks, err := r.checkMedicationDoses(ctx, familyID, []*model.ActivityInput{&input}, []uuid.UUID{baby.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	var created *domain.Activity
+	var session, handedOff *domain.CareSession
+	var started bool
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		session, handedOff, started, err = sessionForNewActivities(ctx, tx, caregiverID, familyID)
+		if err != nil {
+			return err
+		}
+
+		created, err = createActivity(ctx, tx, activityID, session.ID, baby.ID, &input, doseChecks[0])
+		if err != nil {
+			return err
+		}
+
+		if err := saveIdempotencyKey(ctx, tx, familyID, &change.IdempotencyKey, activityID, domain.SyncOperationCreate); err != nil {
+			return err
+		}
+		if err := tx.DeletePredictionsForBaby(ctx, baby.ID); err != nil {
+			return fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if handedOff != nil {
+		events.sessionIDs = append(events.sessionIDs, handedOff.ID)
+		events.completedSessions = append(events.completedSessions, handedOff)
+	}
+	if started {
+		events.startedSessions = append(events.startedSessions, session)
+	}
+	events.sessionIDs = append(events.sessionIDs, created.CareSessionID)
+	events.added = append(events.added, created)
+	events.babyIDs = append(events.babyIDs, baby.ID)
+	return nil, nil
+}
+
+func (r *Resolver) syncUpdate(ctx context.Context, familyID uuid.UUID, activity *domain.Activity, change *model.SyncChangeInput, events *syncEvents) (*model.SyncConflict, error) {
+	input := change.Activity
+	if input == nil {
+		return r.syncConflict(ctx, change, activity, "activity is required to update an activity")
+	}
+	if input.ActivityType != model.ActivityType(strings.ToUpper(string(activity.ActivityType))) {
+		return r.syncConflict(ctx, change, activity, "activity type cannot be changed")
+	}
+	if input.BabyID != nil && *input.BabyID != activity.BabyID.String() {
+		return r.syncConflict(ctx, change, activity, "moving an activity to another baby is not supported")
+	}
+	if err := activityDetailsError(input); err != nil {
+		return r.syncConflict(ctx, change, activity, err.Error())
+	}
+
+	// Last writer wins: the server keeps an edit made after this one
+	if activity.UpdatedAt.After(change.ChangedAt) {
+		return r.syncConflict(ctx, change, activity, "activity was changed on the server after this edit")
+	}
+
+	err := r.store.WithTx(ctx, func(tx store.Store) error {
+		if _, err := editActivity(ctx, tx, familyID, activity, *input, nil); err != nil {
+			return err
+		}
+		if err := saveIdempotencyKey(ctx, tx, familyID, &change.IdempotencyKey, activity.ID, domain.SyncOperationUpdate); err != nil {
+			return err
+		}
+		if err := tx.DeletePredictionsForBaby(ctx, activity.BabyID); err != nil {
+			return fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.sessionIDs = append(events.sessionIDs, activity.CareSessionID)
+	events.babyIDs = append(events.babyIDs, activity.BabyID)
+	events.updated = append(events.updated, activity)
+	return nil, nil
+}
+
+// syncConflict builds the conflict reported for change, including the server's current
+// version of the activity when there is one.
+func (r *Resolver) syncConflict(ctx context.Context, change *model.SyncChangeInput, activity *domain.Activity, reason string) (*model.SyncConflict, error) {
+	conflict := &model.SyncConflict{
+		ActivityID:     change.ActivityID,
+		IdempotencyKey: change.IdempotencyKey,
+		Operation:      change.Operation,
+		Reason:         reason,
+	}
+	if activity != nil {
+		serverActivity, err := r.loadActivity(ctx, activity)
+		if err != nil {
+			return nil, err
+		}
+		conflict.ServerActivity = serverActivity
+	}
+	return conflict, nil
+}
+
+// activityDetailsError returns an error if input lacks the details for its activity type.
+func activityDetailsError(input *model.ActivityInput) error {
+	switch input.ActivityType {
+	case model.ActivityTypeFeed:
+		if input.FeedDetails == nil {
+			return fmt.Errorf("feed activity requires feedDetails")
+		}
+	case model.ActivityTypeDiaper:
+		if input.DiaperDetails == nil {
+			return fmt.Errorf("diaper activity requires diaperDetails")
+		}
+	case model.ActivityTypeSleep:
+		if input.SleepDetails == nil {
+			return fmt.Errorf("sleep activity requires sleepDetails")
+		}
+	case model.ActivityTypePump:
+		if input.PumpDetails == nil {
+			return fmt.Errorf("pump activity requires pumpDetails")
+		}
+	case model.ActivityTypeMedication:
+		if input.MedicationDetails == nil {
+			return fmt.Errorf("medication activity requires medicationDetails")
+		}
+	}
+	return nil
+}
+
+// serverChangesSince loads the family's activities created, changed or deleted after since.
+func (r *Resolver) serverChangesSince(ctx context.Context, familyID uuid.UUID, since time.Time, result *model.SyncResult) error {
+	activities, err := r.store.GetActivitiesUpdatedSinceForFamily(ctx, familyID, since)
+	if err != nil {
+		return err
+	}
+	for _, activity := range activities {
+		loaded, err := r.loadActivity(ctx, activity)
+		if err != nil {
+			return err
+		}
+		result.Changes = append(result.Changes, loaded)
+	}
+
+	deleted, err := r.store.GetDeletedActivitiesSinceForFamily(ctx, familyID, since)
+	if err != nil {
+		return err
+	}
+	for _, d := range deleted {
+		result.DeletedActivityIds = append(result.DeletedActivityIds, d.ActivityID.String())
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/graph/model"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// familyTimezone returns the timezone to interpret the family's day in at now. Families
+// that haven't set one yet fall back to the requesting phone's X-Timezone header.
+func (r *Resolver) familyTimezone(ctx context.Context, familyID uuid.UUID, now time.Time) (string, error) {
+	family, err := r.store.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get family: %w", err)
+	}
+	if timezone := family.TimezoneAt(now); timezone != "" {
+		return timezone, nil
+	}
+	return middleware.GetTimezone(ctx), nil
+}
+
+// updateFamilySettings applies change to the caller's family and saves it with an audit event
+func (r *Resolver) updateFamilySettings(ctx context.Context, change func(family *domain.Family)) (*model.Family, error) {
+	_, familyID, err := middleware.RequirePermission(ctx, domain.PermissionManageBabies)
+	if err != nil {
+		return nil, err
+	}
+
+	family, err := r.store.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+
+	before := mapper.FamilyToGraphQL(family)
+	change(family)
+	family.UpdatedAt = time.Now()
+
+	err = r.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.UpdateFamily(ctx, family); err != nil {
+			return fmt.Errorf("failed to update family: %w", err)
+		}
+		return audit(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityFamily, family.ID, before, mapper.FamilyToGraphQL(family))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.FamilyToGraphQL(family), nil
+}
+
+// travelModeFromInput validates a travel mode. Its dates are kept as calendar dates.
+func travelModeFromInput(input *model.TravelModeInput) (*domain.TravelMode, error) {
+	if err := domain.ValidateTimezone(input.Timezone); err != nil {
+		return nil, err
+	}
+
+	startDate := time.Date(input.StartDate.Year(), input.StartDate.Month(), input.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(input.EndDate.Year(), input.EndDate.Month(), input.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("travel end date cannot be before its start date")
+	}
+
+	return &domain.TravelMode{Timezone: input.Timezone, StartDate: startDate, EndDate: endDate}, nil
+}
@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/mapper"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// deletedSince is the oldest deletion that can still be restored.
+func (r *Resolver) deletedSince() time.Time {
+	return time.Now().Add(-r.deletedRetention)
+}
+
+// recentlyDeletedActivity finds one of the family's activities that can still be restored.
+func (r *Resolver) recentlyDeletedActivity(ctx context.Context, familyID, id uuid.UUID) (*domain.Activity, error) {
+	activities, err := r.store.GetRecentlyDeletedActivitiesForFamily(ctx, familyID, r.deletedSince())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted activities: %w", err)
+	}
+	for _, activity := range activities {
+		if activity.ID == id {
+			return activity, nil
+		}
+	}
+	return nil, fmt.Errorf("activity is not in recently deleted: %s", id)
+}
+
+// recentlyDeletedCareSession finds one of the family's sessions that can still be restored.
+func (r *Resolver) recentlyDeletedCareSession(ctx context.Context, familyID, id uuid.UUID) (*domain.CareSession, error) {
+	sessions, err := r.store.GetRecentlyDeletedCareSessionsForFamily(ctx, familyID, r.deletedSince())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted care sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
+	return nil, fmt.Errorf("care session is not in recently deleted: %s", id)
+}
+
+// deleteCareSession moves a session and its activities to the trash, leaves tombstones for
+// syncing clients and invalidates the predictions of the babies it covered. It returns the
+// deleted activities.
+func deleteCareSession(ctx context.Context, tx store.Store, session *domain.CareSession) ([]*domain.Activity, error) {
+	activities, err := tx.GetActivitiesForSession(ctx, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activities: %w", err)
+	}
+
+	if err := tx.DeleteCareSession(ctx, session.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete care session: %w", err)
+	}
+	if err := audit(ctx, tx, domain.AuditActionDelete, domain.AuditEntityCareSession, session.ID, mapper.CareSessionToGraphQL(session), nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, activity := range activities {
+		err := tx.RecordDeletedActivity(ctx, &domain.DeletedActivity{
+			ActivityID: activity.ID,
+			FamilyID:   session.FamilyID,
+			BabyID:     activity.BabyID,
+			DeletedAt:  now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, babyID := range activityBabyIDs(activities) {
+		if err := tx.DeletePredictionsForBaby(ctx, babyID); err != nil {
+			return nil, fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+	}
+	return activities, nil
+}
+
+// restoreCareSession takes a session out of the trash with the activities deleted along
+// with it, clears their tombstones and invalidates the affected predictions. It returns
+// the restored activities.
+func restoreCareSession(ctx context.Context, tx store.Store, session *domain.CareSession) ([]*domain.Activity, error) {
+	if err := tx.RestoreCareSession(ctx, session.ID); err != nil {
+		return nil, fmt.Errorf("failed to restore care session: %w", err)
+	}
+	activities, err := tx.GetActivitiesForSession(ctx, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activities: %w", err)
+	}
+
+	restored := *session
+	restored.DeletedAt = nil
+	if err := audit(ctx, tx, domain.AuditActionRestore, domain.AuditEntityCareSession, session.ID, nil, mapper.CareSessionToGraphQL(&restored)); err != nil {
+		return nil, err
+	}
+
+	for _, activity := range activities {
+		if err := tx.ClearDeletedActivity(ctx, activity.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, babyID := range activityBabyIDs(activities) {
+		if err := tx.DeletePredictionsForBaby(ctx, babyID); err != nil {
+			return nil, fmt.Errorf("failed to invalidate predictions: %w", err)
+		}
+	}
+	return activities, nil
+}
+
+// activityBabyIDs returns the babies the activities were logged for, each once.
+func activityBabyIDs(activities []*domain.Activity) []uuid.UUID {
+	babyIDs := make([]uuid.UUID, 0, len(activities))
+	for _, activity := range activities {
+		babyIDs = append(babyIDs, activity.BabyID)
+	}
+	return uniqueIDs(babyIDs)
+}
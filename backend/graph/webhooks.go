@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/webhook"
+)
+
+// emitWebhook queues an event for the family's webhook subscribers. Like the publish
+// helpers it is called after commit; a failure to queue is logged rather than failing
+// the mutation that caused it.
+func (r *Resolver) emitWebhook(ctx context.Context, familyID uuid.UUID, eventType domain.WebhookEventType, data map[string]any) {
+	if err := webhook.Enqueue(ctx, r.store, eventType, familyID, data); err != nil {
+		log.Printf("webhook: failed to queue %s event for family %s: %v", eventType, familyID, err)
+	}
+}
+
+// emitSessionWebhook queues a session_started or session_completed event.
+func (r *Resolver) emitSessionWebhook(ctx context.Context, eventType domain.WebhookEventType, session *domain.CareSession) {
+	r.emitWebhook(ctx, session.FamilyID, eventType, map[string]any{
+		"careSessionId": session.ID,
+		"caregiverId":   session.CaregiverID,
+		"status":        session.Status,
+	})
+}
+
+// emitActivityWebhook queues an activity_added, activity_updated or activity_deleted event.
+func (r *Resolver) emitActivityWebhook(ctx context.Context, familyID uuid.UUID, eventType domain.WebhookEventType, activity *domain.Activity) {
+	r.emitWebhook(ctx, familyID, eventType, map[string]any{
+		"activityId":    activity.ID,
+		"careSessionId": activity.CareSessionID,
+		"babyId":        activity.BabyID,
+		"activityType":  activity.ActivityType,
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -45,8 +46,8 @@ func NewClaudeClient(apiKey string) *ClaudeClient {
 	}
 }
 
-func (c *ClaudeClient) ParseVoiceInput(text string, currentTime time.Time, timezone string) (string, error) {
-	prompt := buildVoiceParsingPrompt(text, currentTime, timezone)
+func (c *ClaudeClient) ParseVoiceInput(text string, currentTime time.Time, timezone string, babyNames []string) (string, error) {
+	prompt := buildVoiceParsingPrompt(text, currentTime, timezone, babyNames)
 	
 	reqBody := claudeRequest{
 		Model:     "claude-sonnet-4-6",
@@ -115,7 +116,7 @@ func (c *ClaudeClient) ParseVoiceInput(text string, currentTime time.Time, timez
 	return claudeResp.Content[0].Text, nil
 }
 
-func buildVoiceParsingPrompt(voiceText string, currentTime time.Time, timezone string) string {
+func buildVoiceParsingPrompt(voiceText string, currentTime time.Time, timezone string, babyNames []string) string {
 	// Convert current time to the user's timezone so Claude sees the correct local time
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
@@ -127,6 +128,7 @@ func buildVoiceParsingPrompt(voiceText string, currentTime time.Time, timezone s
 
 Current local time: %s
 Timezone: %s
+Babies in this family: %s
 
 Voice input: "%s"
 
@@ -138,6 +140,9 @@ Rules:
 5. "peed" means had_pee=true for diaper change
 6. ALL timestamps MUST use the user's timezone offset (e.g. -05:00), NEVER use "Z"
 7. If the caregiver mentions solid food (e.g. carrots, avocado, banana, rice cereal, puree), set feed_type to "SOLIDS". Extract food_name, quantity, and quantity_unit if mentioned.
+8. "pumped", "pumping" or "expressed" means a PUMP activity (the parent pumping milk), NOT a feed
+9. Giving a medicine, drops or vitamins (e.g. "gave 2.5 ml Tylenol", "vitamin D drops") means a MEDICATION activity
+10. If an activity names one of the babies above (e.g. "fed Ava 90 ml"), set baby_name to that name exactly as listed; otherwise set baby_name to null. PUMP activities never have a baby_name
 
 FEED ACTIVITIES (FORMULA/BREAST_MILK):
 - MUST have: start_time, amount_ml, feed_type
@@ -162,11 +167,28 @@ DIAPER ACTIVITIES:
 - MUST have: changed_at timestamp
 - Extract had_poop and had_pee from context
 
+MEDICATION ACTIVITIES:
+- MUST have: given_at, drug_name, dose_amount, dose_unit
+- drug_name as spoken (e.g. "Tylenol", "ibuprofen", "vitamin D")
+- dose_unit must be one of: "ML", "MG", "DROPS", "TABLETS", "IU"
+- route must be one of: "ORAL", "RECTAL", "TOPICAL", "OTHER" (default "ORAL" if not mentioned; suppositories are "RECTAL", creams are "TOPICAL")
+- Vitamin D "drops" with no number = dose_amount: 1, dose_unit: "DROPS"
+
+PUMP ACTIVITIES:
+- MUST have: start_time
+- "both sides" with one amount (e.g. "pumped 120 ml both sides") = total_ml: 120, left_ml and right_ml null
+- Per-side amounts (e.g. "60 left and 50 right") = left_ml: 60, right_ml: 50, total_ml: 110
+- Only one side mentioned (e.g. "pumped 80 from the left") = left_ml: 80, right_ml: null, total_ml: 80
+- Put any extra context (e.g. "low supply", "used the hand pump") in notes, otherwise null
+- If end_time provided: use it
+- If end_time NOT provided: set to null
+
 Extract all activities mentioned. Return ONLY valid JSON array (no markdown, no explanation):
 
 [
   {
     "activity_type": "FEED",
+    "baby_name": "Ava",
     "feed_details": {
       "start_time": "2024-01-15T14:30:00-05:00",
       "end_time": null,
@@ -203,12 +225,34 @@ Extract all activities mentioned. Return ONLY valid JSON array (no markdown, no
       "start_time": "2024-01-15T15:00:00-05:00",
       "end_time": null
     }
+  },
+  {
+    "activity_type": "PUMP",
+    "pump_details": {
+      "start_time": "2024-01-15T15:10:00-05:00",
+      "end_time": null,
+      "left_ml": null,
+      "right_ml": null,
+      "total_ml": 120,
+      "notes": null
+    }
+  },
+  {
+    "activity_type": "MEDICATION",
+    "medication_details": {
+      "given_at": "2024-01-15T15:30:00-05:00",
+      "drug_name": "Tylenol",
+      "dose_amount": 2.5,
+      "dose_unit": "ML",
+      "route": "ORAL"
+    }
   }
 ]
 
 If you cannot parse the input, return: {"error": "reason"}`,
 		localTime.Format(time.RFC3339),
 		timezone,
+		strings.Join(babyNames, ", "),
 		voiceText,
 	)
 }
\ No newline at end of file
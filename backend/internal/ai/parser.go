@@ -1,13 +1,16 @@
 package ai
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/swatkatz/babybaton/backend/graph/model"
 )
 
-// ConvertToParsedActivities converts Claude's JSON response to ParsedActivity output types
-func ConvertToParsedActivities(activities []map[string]interface{}) ([]*model.ParsedActivity, []string) {
+// ConvertToParsedActivities converts Claude's JSON response to ParsedActivity output types.
+// babyIDsByName maps lowercased baby names to IDs and is used to resolve "baby_name".
+func ConvertToParsedActivities(activities []map[string]interface{}, babyIDsByName map[string]string) ([]*model.ParsedActivity, []string) {
 	var result []*model.ParsedActivity
 	var errors []string
 
@@ -22,6 +25,15 @@ func ConvertToParsedActivities(activities []map[string]interface{}) ([]*model.Pa
 			ActivityType: model.ActivityType(activityType),
 		}
 
+		// Resolve the named baby, if any
+		if babyName, ok := act["baby_name"].(string); ok && strings.TrimSpace(babyName) != "" {
+			if babyID, ok := babyIDsByName[strings.ToLower(strings.TrimSpace(babyName))]; ok {
+				output.BabyID = &babyID
+			} else {
+				errors = append(errors, fmt.Sprintf("Unknown baby name: %s", babyName))
+			}
+		}
+
 		// Parse feed details
 		if feedDetails, ok := act["feed_details"].(map[string]interface{}); ok {
 			output.FeedDetails = parseFeedDetailsOutput(feedDetails)
@@ -37,6 +49,16 @@ func ConvertToParsedActivities(activities []map[string]interface{}) ([]*model.Pa
 			output.SleepDetails = parseSleepDetailsOutput(sleepDetails)
 		}
 
+		// Parse pump details
+		if pumpDetails, ok := act["pump_details"].(map[string]interface{}); ok {
+			output.PumpDetails = parsePumpDetailsOutput(pumpDetails)
+		}
+
+		// Parse medication details
+		if medicationDetails, ok := act["medication_details"].(map[string]interface{}); ok {
+			output.MedicationDetails = parseMedicationDetailsOutput(medicationDetails)
+		}
+
 		result = append(result, output)
 	}
 
@@ -138,6 +160,78 @@ func parseSleepDetailsOutput(details map[string]interface{}) *model.SleepDetails
 	return sd
 }
 
+func parsePumpDetailsOutput(details map[string]interface{}) *model.PumpDetails {
+	pd := &model.PumpDetails{}
+
+	if startTime, ok := details["start_time"].(string); ok {
+		t, _ := time.Parse(time.RFC3339, startTime)
+		t = t.UTC()
+		pd.StartTime = t
+	}
+
+	if endTime, ok := details["end_time"].(string); ok && endTime != "" {
+		t, _ := time.Parse(time.RFC3339, endTime)
+		t = t.UTC()
+		pd.EndTime = &t
+	}
+
+	if leftMl, ok := details["left_ml"].(float64); ok {
+		amt := int32(leftMl)
+		pd.LeftMl = &amt
+	}
+
+	if rightMl, ok := details["right_ml"].(float64); ok {
+		amt := int32(rightMl)
+		pd.RightMl = &amt
+	}
+
+	if totalMl, ok := details["total_ml"].(float64); ok {
+		amt := int32(totalMl)
+		pd.TotalMl = &amt
+	}
+
+	if notes, ok := details["notes"].(string); ok && notes != "" {
+		pd.Notes = &notes
+	}
+
+	// Calculate duration if both times exist
+	if pd.EndTime != nil {
+		duration := int32(pd.EndTime.Sub(pd.StartTime).Minutes())
+		pd.DurationMinutes = &duration
+	}
+
+	return pd
+}
+
+func parseMedicationDetailsOutput(details map[string]interface{}) *model.MedicationDetails {
+	md := &model.MedicationDetails{
+		Route: model.MedicationRouteOral,
+	}
+
+	if drugName, ok := details["drug_name"].(string); ok {
+		md.DrugName = drugName
+	}
+
+	if doseAmount, ok := details["dose_amount"].(float64); ok {
+		md.DoseAmount = doseAmount
+	}
+
+	if doseUnit, ok := details["dose_unit"].(string); ok {
+		md.DoseUnit = model.DoseUnit(doseUnit)
+	}
+
+	if route, ok := details["route"].(string); ok && route != "" {
+		md.Route = model.MedicationRoute(route)
+	}
+
+	if givenAt, ok := details["given_at"].(string); ok {
+		t, _ := time.Parse(time.RFC3339, givenAt)
+		md.GivenAt = t.UTC()
+	}
+
+	return md
+}
+
 // ConvertToActivityInputs converts Claude's JSON response to ActivityInput types
 func ConvertToActivityInputs(activities []map[string]interface{}) ([]*model.ActivityInput, []string) {
 	var result []*model.ActivityInput
@@ -169,6 +263,16 @@ func ConvertToActivityInputs(activities []map[string]interface{}) ([]*model.Acti
 			input.SleepDetails = parseSleepDetails(sleepDetails)
 		}
 
+		// Parse pump details
+		if pumpDetails, ok := act["pump_details"].(map[string]interface{}); ok {
+			input.PumpDetails = parsePumpDetails(pumpDetails)
+		}
+
+		// Parse medication details
+		if medicationDetails, ok := act["medication_details"].(map[string]interface{}); ok {
+			input.MedicationDetails = parseMedicationDetails(medicationDetails)
+		}
+
 		result = append(result, input)
 	}
 
@@ -252,4 +356,69 @@ func parseSleepDetails(details map[string]interface{}) *model.SleepDetailsInput
 	}
 
 	return sd
-}
\ No newline at end of file
+}
+
+func parsePumpDetails(details map[string]interface{}) *model.PumpDetailsInput {
+	pd := &model.PumpDetailsInput{}
+
+	if startTime, ok := details["start_time"].(string); ok {
+		t, _ := time.Parse(time.RFC3339, startTime)
+		t = t.UTC()
+		pd.StartTime = t
+	}
+
+	if endTime, ok := details["end_time"].(string); ok && endTime != "" {
+		t, _ := time.Parse(time.RFC3339, endTime)
+		t = t.UTC()
+		pd.EndTime = &t
+	}
+
+	if leftMl, ok := details["left_ml"].(float64); ok {
+		amt := int32(leftMl)
+		pd.LeftMl = &amt
+	}
+
+	if rightMl, ok := details["right_ml"].(float64); ok {
+		amt := int32(rightMl)
+		pd.RightMl = &amt
+	}
+
+	if totalMl, ok := details["total_ml"].(float64); ok {
+		amt := int32(totalMl)
+		pd.TotalMl = &amt
+	}
+
+	if notes, ok := details["notes"].(string); ok && notes != "" {
+		pd.Notes = &notes
+	}
+
+	return pd
+}
+
+func parseMedicationDetails(details map[string]interface{}) *model.MedicationDetailsInput {
+	md := &model.MedicationDetailsInput{}
+
+	if drugName, ok := details["drug_name"].(string); ok {
+		md.DrugName = drugName
+	}
+
+	if doseAmount, ok := details["dose_amount"].(float64); ok {
+		md.DoseAmount = doseAmount
+	}
+
+	if doseUnit, ok := details["dose_unit"].(string); ok {
+		md.DoseUnit = model.DoseUnit(doseUnit)
+	}
+
+	if route, ok := details["route"].(string); ok && route != "" {
+		r := model.MedicationRoute(route)
+		md.Route = &r
+	}
+
+	if givenAt, ok := details["given_at"].(string); ok {
+		t, _ := time.Parse(time.RFC3339, givenAt)
+		md.GivenAt = t.UTC()
+	}
+
+	return md
+}
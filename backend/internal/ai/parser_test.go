@@ -20,7 +20,7 @@ func TestConvertToParsedActivities_Feed(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -73,7 +73,7 @@ func TestConvertToParsedActivities_Diaper(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -108,7 +108,7 @@ func TestConvertToParsedActivities_Sleep(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -146,7 +146,7 @@ func TestConvertToParsedActivities_SleepActive(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -170,7 +170,7 @@ func TestConvertToParsedActivities_MissingActivityType(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 1 {
 		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
@@ -201,7 +201,7 @@ func TestConvertToParsedActivities_MultipleActivities(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -218,7 +218,7 @@ func TestConvertToParsedActivities_MultipleActivities(t *testing.T) {
 }
 
 func TestConvertToParsedActivities_EmptyInput(t *testing.T) {
-	result, errors := ConvertToParsedActivities(nil)
+	result, errors := ConvertToParsedActivities(nil, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -377,7 +377,7 @@ func TestConvertToParsedActivities_Solids(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -422,7 +422,7 @@ func TestConvertToParsedActivities_Solids_NoQuantity(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -452,7 +452,7 @@ func TestConvertToParsedActivities_Formula_Unchanged(t *testing.T) {
 		},
 	}
 
-	result, errors := ConvertToParsedActivities(activities)
+	result, errors := ConvertToParsedActivities(activities, nil)
 
 	if len(errors) != 0 {
 		t.Fatalf("unexpected errors: %v", errors)
@@ -598,3 +598,223 @@ func TestConvertToActivityInputs_FeedNoEndTime(t *testing.T) {
 		t.Errorf("EndTime = %v, want nil", r.FeedDetails.EndTime)
 	}
 }
+
+func TestConvertToParsedActivities_Pump(t *testing.T) {
+	// "pumped 120 ml both sides"
+	activities := []map[string]interface{}{
+		{
+			"activity_type": "PUMP",
+			"pump_details": map[string]interface{}{
+				"start_time": "2024-01-01T06:00:00-05:00",
+				"end_time":   "2024-01-01T06:20:00-05:00",
+				"left_ml":    nil,
+				"right_ml":   nil,
+				"total_ml":   float64(120),
+				"notes":      nil,
+			},
+		},
+	}
+
+	result, errors := ConvertToParsedActivities(activities, nil)
+
+	if len(errors) != 0 {
+		t.Fatalf("unexpected errors: %v", errors)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(result))
+	}
+
+	r := result[0]
+	if r.ActivityType != model.ActivityTypePump {
+		t.Errorf("ActivityType = %q, want %q", r.ActivityType, model.ActivityTypePump)
+	}
+	if r.PumpDetails == nil {
+		t.Fatal("expected PumpDetails to be set")
+	}
+
+	expectedStart := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	if !r.PumpDetails.StartTime.Equal(expectedStart) {
+		t.Errorf("StartTime = %v, want %v", r.PumpDetails.StartTime, expectedStart)
+	}
+	if r.PumpDetails.TotalMl == nil || *r.PumpDetails.TotalMl != 120 {
+		t.Errorf("TotalMl = %v, want 120", r.PumpDetails.TotalMl)
+	}
+	if r.PumpDetails.LeftMl != nil || r.PumpDetails.RightMl != nil {
+		t.Errorf("expected nil per-side amounts, got left=%v right=%v", r.PumpDetails.LeftMl, r.PumpDetails.RightMl)
+	}
+	if r.PumpDetails.Notes != nil {
+		t.Errorf("Notes = %v, want nil", r.PumpDetails.Notes)
+	}
+	if r.PumpDetails.DurationMinutes == nil || *r.PumpDetails.DurationMinutes != 20 {
+		t.Errorf("DurationMinutes = %v, want 20", r.PumpDetails.DurationMinutes)
+	}
+}
+
+func TestConvertToActivityInputs_Pump(t *testing.T) {
+	activities := []map[string]interface{}{
+		{
+			"activity_type": "PUMP",
+			"pump_details": map[string]interface{}{
+				"start_time": "2024-01-01T06:00:00Z",
+				"end_time":   nil,
+				"left_ml":    float64(60),
+				"right_ml":   float64(50),
+				"total_ml":   float64(110),
+				"notes":      "hand pump",
+			},
+		},
+	}
+
+	result, errors := ConvertToActivityInputs(activities)
+
+	if len(errors) != 0 {
+		t.Fatalf("unexpected errors: %v", errors)
+	}
+
+	r := result[0]
+	if r.PumpDetails == nil {
+		t.Fatal("expected PumpDetails to be set")
+	}
+	if r.PumpDetails.EndTime != nil {
+		t.Errorf("EndTime = %v, want nil", r.PumpDetails.EndTime)
+	}
+	if r.PumpDetails.LeftMl == nil || *r.PumpDetails.LeftMl != 60 {
+		t.Errorf("LeftMl = %v, want 60", r.PumpDetails.LeftMl)
+	}
+	if r.PumpDetails.RightMl == nil || *r.PumpDetails.RightMl != 50 {
+		t.Errorf("RightMl = %v, want 50", r.PumpDetails.RightMl)
+	}
+	if r.PumpDetails.TotalMl == nil || *r.PumpDetails.TotalMl != 110 {
+		t.Errorf("TotalMl = %v, want 110", r.PumpDetails.TotalMl)
+	}
+	if r.PumpDetails.Notes == nil || *r.PumpDetails.Notes != "hand pump" {
+		t.Errorf("Notes = %v, want %q", r.PumpDetails.Notes, "hand pump")
+	}
+}
+
+func TestConvertToParsedActivities_Medication(t *testing.T) {
+	activities := []map[string]interface{}{
+		{
+			"activity_type": "MEDICATION",
+			"medication_details": map[string]interface{}{
+				"given_at":    "2024-01-01T08:00:00-05:00",
+				"drug_name":   "Tylenol",
+				"dose_amount": 2.5,
+				"dose_unit":   "ML",
+				"route":       nil,
+			},
+		},
+	}
+
+	result, errors := ConvertToParsedActivities(activities, nil)
+
+	if len(errors) != 0 {
+		t.Fatalf("unexpected errors: %v", errors)
+	}
+
+	r := result[0]
+	if r.ActivityType != model.ActivityTypeMedication {
+		t.Errorf("ActivityType = %q, want %q", r.ActivityType, model.ActivityTypeMedication)
+	}
+	if r.MedicationDetails == nil {
+		t.Fatal("expected MedicationDetails to be set")
+	}
+	if r.MedicationDetails.DrugName != "Tylenol" {
+		t.Errorf("DrugName = %q, want Tylenol", r.MedicationDetails.DrugName)
+	}
+	if r.MedicationDetails.DoseAmount != 2.5 {
+		t.Errorf("DoseAmount = %v, want 2.5", r.MedicationDetails.DoseAmount)
+	}
+	if r.MedicationDetails.DoseUnit != model.DoseUnitMl {
+		t.Errorf("DoseUnit = %q, want ML", r.MedicationDetails.DoseUnit)
+	}
+	if r.MedicationDetails.Route != model.MedicationRouteOral {
+		t.Errorf("Route = %q, want ORAL default", r.MedicationDetails.Route)
+	}
+	expected := time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+	if !r.MedicationDetails.GivenAt.Equal(expected) {
+		t.Errorf("GivenAt = %v, want %v", r.MedicationDetails.GivenAt, expected)
+	}
+}
+
+func TestConvertToActivityInputs_Medication(t *testing.T) {
+	activities := []map[string]interface{}{
+		{
+			"activity_type": "MEDICATION",
+			"medication_details": map[string]interface{}{
+				"given_at":    "2024-01-01T08:00:00Z",
+				"drug_name":   "Acetaminophen",
+				"dose_amount": float64(80),
+				"dose_unit":   "MG",
+				"route":       "RECTAL",
+			},
+		},
+	}
+
+	result, errors := ConvertToActivityInputs(activities)
+
+	if len(errors) != 0 {
+		t.Fatalf("unexpected errors: %v", errors)
+	}
+
+	r := result[0]
+	if r.MedicationDetails == nil {
+		t.Fatal("expected MedicationDetails to be set")
+	}
+	if r.MedicationDetails.Route == nil || *r.MedicationDetails.Route != model.MedicationRouteRectal {
+		t.Errorf("Route = %v, want RECTAL", r.MedicationDetails.Route)
+	}
+	if r.MedicationDetails.DoseUnit != model.DoseUnitMg {
+		t.Errorf("DoseUnit = %q, want MG", r.MedicationDetails.DoseUnit)
+	}
+	if r.MedicationDetails.OverrideSafetyCheck != nil {
+		t.Error("voice input must never override dose safety checks")
+	}
+}
+
+func TestConvertToParsedActivities_BabyName(t *testing.T) {
+	babyIDsByName := map[string]string{"ava": "ava-id", "ben": "ben-id"}
+	activities := []map[string]interface{}{
+		{
+			"activity_type": "FEED",
+			"baby_name":     "Ava",
+			"feed_details": map[string]interface{}{
+				"start_time": "2024-01-01T10:00:00Z",
+				"amount_ml":  float64(90),
+			},
+		},
+		{
+			"activity_type": "DIAPER",
+			"baby_name":     nil,
+			"diaper_details": map[string]interface{}{
+				"changed_at": "2024-01-01T12:00:00Z",
+				"had_poop":   false,
+			},
+		},
+		{
+			"activity_type": "SLEEP",
+			"baby_name":     "Charlie",
+			"sleep_details": map[string]interface{}{
+				"start_time": "2024-01-01T13:00:00Z",
+			},
+		},
+	}
+
+	result, errors := ConvertToParsedActivities(activities, babyIDsByName)
+
+	if len(result) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(result))
+	}
+	if result[0].BabyID == nil || *result[0].BabyID != "ava-id" {
+		t.Errorf("first BabyID = %v, want ava-id", result[0].BabyID)
+	}
+	if result[1].BabyID != nil {
+		t.Errorf("second BabyID = %v, want nil", *result[1].BabyID)
+	}
+	if result[2].BabyID != nil {
+		t.Errorf("third BabyID = %v, want nil for unknown name", *result[2].BabyID)
+	}
+	if len(errors) != 1 || errors[0] != "Unknown baby name: Charlie" {
+		t.Errorf("errors = %v, want one unknown baby name error", errors)
+	}
+}
@@ -0,0 +1,122 @@
+// Package dataloader batches and caches the lookups made while resolving one GraphQL
+// request, so loading a page of sessions costs a handful of queries instead of several
+// per session.
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader batches the keys requested within wait of each other into a single fetch and
+// caches what it returns. Loaders never expire their cache, so each should live for one
+// request.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+	wait  time.Duration
+
+	mu       sync.Mutex
+	cache    map[K]V
+	inflight map[K]*batch[K, V]
+	pending  *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys []K
+	done chan struct{}
+	err  error
+}
+
+// NewLoader creates a loader that fetches batches of keys with fetch. Keys missing from
+// the map fetch returns load as the zero value.
+func NewLoader[K comparable, V any](wait time.Duration, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		cache:    make(map[K]V),
+		inflight: make(map[K]*batch[K, V]),
+	}
+}
+
+// Load returns the value for key, batched with any other keys requested meanwhile
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	values, err := l.LoadMany(ctx, []K{key})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return values[0], nil
+}
+
+// LoadMany returns the values for keys in order, fetching the ones not yet cached in as
+// few batches as possible. A failed fetch isn't cached, so a later load retries it.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	l.mu.Lock()
+	waiting := make(map[*batch[K, V]]bool)
+	for _, key := range keys {
+		if _, ok := l.cache[key]; ok {
+			continue
+		}
+		b, ok := l.inflight[key]
+		if !ok {
+			b = l.pendingBatch(ctx)
+			b.keys = append(b.keys, key)
+			l.inflight[key] = b
+		}
+		waiting[b] = true
+	}
+	l.mu.Unlock()
+
+	for b := range waiting {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	values := make([]V, len(keys))
+	for i, key := range keys {
+		values[i] = l.cache[key]
+	}
+	return values, nil
+}
+
+// pendingBatch returns the batch still collecting keys, starting one if there is none.
+// Callers must hold l.mu.
+func (l *Loader[K, V]) pendingBatch(ctx context.Context) *batch[K, V] {
+	if l.pending == nil {
+		b := &batch[K, V]{done: make(chan struct{})}
+		l.pending = b
+		time.AfterFunc(l.wait, func() { l.dispatch(ctx, b) })
+	}
+	return l.pending
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	results, err := l.fetch(ctx, b.keys)
+
+	l.mu.Lock()
+	for _, key := range b.keys {
+		if err == nil {
+			l.cache[key] = results[key]
+		}
+		delete(l.inflight, key)
+	}
+	b.err = err
+	l.mu.Unlock()
+
+	close(b.done)
+}
@@ -0,0 +1,104 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingFetch squares its keys, recording each batch it was asked for
+type recordingFetch struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (f *recordingFetch) fetch(_ context.Context, keys []int) (map[int]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, slices.Clone(keys))
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := make(map[int]int, len(keys))
+	for _, k := range keys {
+		if k >= 0 {
+			values[k] = k * k
+		}
+	}
+	return values, nil
+}
+
+func TestLoader_BatchesConcurrentLoads(t *testing.T) {
+	f := &recordingFetch{}
+	l := NewLoader(10*time.Millisecond, f.fetch)
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Load(context.Background(), i)
+			if err != nil {
+				t.Errorf("Load(%d) error: %v", i, err)
+			}
+			results[i] = v
+		}()
+	}
+	wg.Wait()
+
+	if !slices.Equal(results, []int{0, 1, 4, 9, 16}) {
+		t.Errorf("results = %v, want squares", results)
+	}
+	if len(f.batches) != 1 || len(f.batches[0]) != 5 {
+		t.Errorf("batches = %v, want one batch of 5 keys", f.batches)
+	}
+}
+
+func TestLoader_CachesResults(t *testing.T) {
+	f := &recordingFetch{}
+	l := NewLoader(0, f.fetch)
+	ctx := context.Background()
+
+	values, err := l.LoadMany(ctx, []int{2, 3, -1})
+	if err != nil {
+		t.Fatalf("LoadMany error: %v", err)
+	}
+	if !slices.Equal(values, []int{4, 9, 0}) {
+		t.Errorf("values = %v, want [4 9 0] with the missing key as zero", values)
+	}
+
+	values, err = l.LoadMany(ctx, []int{3, -1, 4, 4})
+	if err != nil {
+		t.Fatalf("LoadMany error: %v", err)
+	}
+	if !slices.Equal(values, []int{9, 0, 16, 16}) {
+		t.Errorf("values = %v, want [9 0 16 16]", values)
+	}
+	if len(f.batches) != 2 || !slices.Equal(f.batches[1], []int{4}) {
+		t.Errorf("batches = %v, want the second to fetch only the uncached key", f.batches)
+	}
+}
+
+func TestLoader_DoesNotCacheErrors(t *testing.T) {
+	f := &recordingFetch{err: errors.New("database unavailable")}
+	l := NewLoader(0, f.fetch)
+	ctx := context.Background()
+
+	if _, err := l.Load(ctx, 1); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+
+	f.err = nil
+	v, err := l.Load(ctx, 1)
+	if err != nil || v != 1 {
+		t.Errorf("Load(1) = (%d, %v), want a retry to succeed", v, err)
+	}
+	if len(f.batches) != 2 {
+		t.Errorf("fetched %d times, want 2", len(f.batches))
+	}
+}
@@ -0,0 +1,103 @@
+package dataloader
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// batchWait is how long a loader collects keys before fetching them. Resolvers that know
+// everything they need load it with LoadMany, so this only has to cover fields resolved
+// concurrently.
+const batchWait = time.Millisecond
+
+// Loaders are the request-scoped loaders for what loading a care session needs
+type Loaders struct {
+	// ActivitiesBySession loads each session's activities in GetActivitiesForSession order
+	ActivitiesBySession *Loader[uuid.UUID, []*domain.Activity]
+
+	// The details loaders are keyed by activity ID
+	FeedDetails       *Loader[uuid.UUID, *domain.FeedDetails]
+	DiaperDetails     *Loader[uuid.UUID, *domain.DiaperDetails]
+	SleepDetails      *Loader[uuid.UUID, *domain.SleepDetails]
+	PumpDetails       *Loader[uuid.UUID, *domain.PumpDetails]
+	MedicationDetails *Loader[uuid.UUID, *domain.MedicationDetails]
+
+	Caregivers *Loader[uuid.UUID, *domain.Caregiver]
+}
+
+// NewLoaders creates empty loaders reading from s
+func NewLoaders(s store.Store) *Loaders {
+	return &Loaders{
+		ActivitiesBySession: NewLoader(batchWait, func(ctx context.Context, sessionIDs []uuid.UUID) (map[uuid.UUID][]*domain.Activity, error) {
+			activities, err := s.GetActivitiesForSessions(ctx, sessionIDs)
+			if err != nil {
+				return nil, err
+			}
+			bySession := make(map[uuid.UUID][]*domain.Activity, len(sessionIDs))
+			for _, activity := range activities {
+				bySession[activity.CareSessionID] = append(bySession[activity.CareSessionID], activity)
+			}
+			return bySession, nil
+		}),
+		FeedDetails: NewLoader(batchWait, byID(s.GetFeedDetailsByActivityIDs, func(d *domain.FeedDetails) uuid.UUID {
+			return d.ActivityID
+		})),
+		DiaperDetails: NewLoader(batchWait, byID(s.GetDiaperDetailsByActivityIDs, func(d *domain.DiaperDetails) uuid.UUID {
+			return d.ActivityID
+		})),
+		SleepDetails: NewLoader(batchWait, byID(s.GetSleepDetailsByActivityIDs, func(d *domain.SleepDetails) uuid.UUID {
+			return d.ActivityID
+		})),
+		PumpDetails: NewLoader(batchWait, byID(s.GetPumpDetailsByActivityIDs, func(d *domain.PumpDetails) uuid.UUID {
+			return d.ActivityID
+		})),
+		MedicationDetails: NewLoader(batchWait, byID(s.GetMedicationDetailsByActivityIDs, func(d *domain.MedicationDetails) uuid.UUID {
+			return d.ActivityID
+		})),
+		Caregivers: NewLoader(batchWait, byID(s.GetCaregiversByIDs, func(c *domain.Caregiver) uuid.UUID {
+			return c.ID
+		})),
+	}
+}
+
+// byID adapts a batch store method into a loader fetch, keying each row returned with key
+func byID[V any](get func(context.Context, []uuid.UUID) ([]V, error), key func(V) uuid.UUID) func(context.Context, []uuid.UUID) (map[uuid.UUID]V, error) {
+	return func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]V, error) {
+		rows, err := get(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[uuid.UUID]V, len(rows))
+		for _, row := range rows {
+			values[key(row)] = row
+		}
+		return values, nil
+	}
+}
+
+type contextKey struct{}
+
+// Middleware gives each request its own loaders reading from s
+func Middleware(s store.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithLoaders(r.Context(), NewLoaders(s))))
+		})
+	}
+}
+
+// WithLoaders returns a copy of ctx carrying l
+func WithLoaders(ctx context.Context, l *Loaders) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// For returns the loaders the middleware attached to ctx
+func For(ctx context.Context) (*Loaders, bool) {
+	l, ok := ctx.Value(contextKey{}).(*Loaders)
+	return l, ok
+}
@@ -0,0 +1,53 @@
+package devicetoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	claims := Claims{CaregiverID: uuid.New(), FamilyID: uuid.New(), Generation: 3}
+
+	token := s.Issue(claims)
+	if !strings.HasPrefix(token, "dt1.") {
+		t.Errorf("expected versioned token, got %q", token)
+	}
+
+	got, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != claims {
+		t.Errorf("Verify() = %+v, want %+v", got, claims)
+	}
+}
+
+func TestVerify_RejectsForgeries(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	claims := Claims{CaregiverID: uuid.New(), FamilyID: uuid.New(), Generation: 1}
+	token := s.Issue(claims)
+	signature := token[strings.LastIndex(token, ".")+1:]
+
+	// Claiming another family under the original signature must fail
+	tampered := s.Issue(Claims{CaregiverID: claims.CaregiverID, FamilyID: uuid.New(), Generation: 1})
+	tampered = tampered[:strings.LastIndex(tampered, ".")+1] + signature
+
+	tests := map[string]string{
+		"empty":            "",
+		"no signature":     "dt1." + claims.CaregiverID.String(),
+		"other secret":     NewSigner([]byte("other")).Issue(claims),
+		"tampered payload": tampered,
+		"wrong version":    "dt0" + token[3:],
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Verify(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
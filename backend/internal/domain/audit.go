@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is what a change did to the entity it touched.
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	// AuditActionRevoke covers revoking an invite or signing a caregiver's device out
+	AuditActionRevoke AuditAction = "revoke"
+	// AuditActionRestore covers bringing a deleted activity or care session back
+	AuditActionRestore AuditAction = "restore"
+)
+
+// AuditEntityType is the kind of record a change touched. Like the API, schedule goals are
+// identified by their baby's ID and reminder preferences by their caregiver's ID.
+type AuditEntityType string
+
+const (
+	AuditEntityFamily              AuditEntityType = "family"
+	AuditEntityBaby                AuditEntityType = "baby"
+	AuditEntityCaregiver           AuditEntityType = "caregiver"
+	AuditEntityInvite              AuditEntityType = "invite"
+	AuditEntityCareSession         AuditEntityType = "care_session"
+	AuditEntityActivity            AuditEntityType = "activity"
+	AuditEntityPrediction          AuditEntityType = "prediction"
+	AuditEntityScheduleGoals       AuditEntityType = "schedule_goals"
+	AuditEntityReminderPreferences AuditEntityType = "reminder_preferences"
+	AuditEntityWebhookSubscription AuditEntityType = "webhook_subscription"
+	AuditEntityMedication          AuditEntityType = "medication"
+	AuditEntityGrowthMeasurement   AuditEntityType = "growth_measurement"
+)
+
+// AuditEvent records one change to a family's data. Events are append-only: they are
+// never updated, and only go away with their family.
+type AuditEvent struct {
+	ID       uuid.UUID
+	FamilyID uuid.UUID
+	// ActorCaregiverID and ActorName identify who made the change. The name is copied at
+	// the time so the event still says who it was after they leave the family.
+	ActorCaregiverID uuid.UUID
+	ActorName        string
+	Action           AuditAction
+	EntityType       AuditEntityType
+	EntityID         uuid.UUID
+	Before           *string // JSON snapshot; nil when the entity was created
+	After            *string // JSON snapshot; nil when the entity was deleted
+	CreatedAt        time.Time
+}
+
+// AuditFilter narrows a family's audit log. Nil fields match every event.
+type AuditFilter struct {
+	ActorCaregiverID *uuid.UUID
+	Action           *AuditAction
+	EntityType       *AuditEntityType
+	EntityID         *uuid.UUID
+}
+
+// Matches reports whether event passes the filter.
+func (f AuditFilter) Matches(event *AuditEvent) bool {
+	return (f.ActorCaregiverID == nil || event.ActorCaregiverID == *f.ActorCaregiverID) &&
+		(f.Action == nil || event.Action == *f.Action) &&
+		(f.EntityType == nil || event.EntityType == *f.EntityType) &&
+		(f.EntityID == nil || event.EntityID == *f.EntityID)
+}
@@ -17,9 +17,11 @@ const (
 type ActivityType string
 
 const (
-	ActivityTypeFeed   ActivityType = "feed"
-	ActivityTypeDiaper ActivityType = "diaper"
-	ActivityTypeSleep  ActivityType = "sleep"
+	ActivityTypeFeed       ActivityType = "feed"
+	ActivityTypeDiaper     ActivityType = "diaper"
+	ActivityTypeSleep      ActivityType = "sleep"
+	ActivityTypePump       ActivityType = "pump"
+	ActivityTypeMedication ActivityType = "medication"
 )
 
 type FeedType string
@@ -30,16 +32,84 @@ const (
 	FeedTypeSolids     FeedType = "solids"
 )
 
+type DoseUnit string
+
+const (
+	DoseUnitMl      DoseUnit = "ml"
+	DoseUnitMg      DoseUnit = "mg"
+	DoseUnitDrops   DoseUnit = "drops"
+	DoseUnitTablets DoseUnit = "tablets"
+	DoseUnitIU      DoseUnit = "iu"
+)
+
+type BabySex string
+
+const (
+	BabySexMale   BabySex = "male"
+	BabySexFemale BabySex = "female"
+)
+
+// PredictionModel is how a family's predictions estimate typical intervals from history
+type PredictionModel string
+
+const (
+	// PredictionModelMedian weighs all recent history the same
+	PredictionModelMedian PredictionModel = "median"
+	// PredictionModelRecencyWeighted weighs recent days more, to follow a changing schedule
+	PredictionModelRecencyWeighted PredictionModel = "recency_weighted"
+)
+
+type SyncOperation string
+
+const (
+	SyncOperationCreate SyncOperation = "create"
+	SyncOperationUpdate SyncOperation = "update"
+	SyncOperationDelete SyncOperation = "delete"
+)
+
+type MedicationRoute string
+
+const (
+	MedicationRouteOral    MedicationRoute = "oral"
+	MedicationRouteRectal  MedicationRoute = "rectal"
+	MedicationRouteTopical MedicationRoute = "topical"
+	MedicationRouteOther   MedicationRoute = "other"
+)
+
 // Domain Models
 
 type Family struct {
 	ID           uuid.UUID
 	Name         string
-	PasswordHash string // bcrypt hash for authentication
-	Password     string // plain text password for sharing (stored in DB)
-	BabyName     string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	PasswordHash string // bcrypt hash, checked by the legacy joinFamily; new caregivers join with invites
+	BabyName     string // name of the first baby, kept for clients that predate multi-baby support
+	Timezone     string // home IANA timezone; empty until the family sets one
+	Travel       *TravelMode
+	// PredictionModel defaults to PredictionModelMedian when empty
+	PredictionModel PredictionModel
+	// PredictionHalfLife tunes PredictionModelRecencyWeighted; zero uses the default
+	PredictionHalfLife time.Duration
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// TravelMode temporarily replaces a family's home timezone while they are away.
+// StartDate and EndDate are dates only, both inclusive, in the travel timezone.
+type TravelMode struct {
+	Timezone  string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// Baby is a child tracked by a family. Activities, schedule goals and predictions belong to a baby.
+type Baby struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	Name      string
+	BirthDate *time.Time // date only; used for age-based growth percentiles
+	Sex       *BabySex
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type User struct {
@@ -57,8 +127,12 @@ type Caregiver struct {
 	Name       string
 	DeviceID   *string
 	DeviceName *string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	// TokenGeneration is bumped to rotate or revoke the caregiver's device token;
+	// only a token carrying the current generation is accepted
+	TokenGeneration int
+	Role            CaregiverRole
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 type CareSession struct {
@@ -71,14 +145,23 @@ type CareSession struct {
 	Notes       *string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	// DeletedAt is set while the session is in the trash, from where it can be restored
+	// until it is purged
+	DeletedAt *time.Time
 }
 
 type Activity struct {
 	ID            uuid.UUID
 	CareSessionID uuid.UUID
+	BabyID        uuid.UUID
 	ActivityType  ActivityType
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// Version starts at 1 and goes up with every change to the activity or its details
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set while the activity is in the trash. Activities deleted along with
+	// their session share the session's DeletedAt.
+	DeletedAt *time.Time
 }
 
 type FeedDetails struct {
@@ -115,6 +198,62 @@ type SleepDetails struct {
 	UpdatedAt       time.Time
 }
 
+type PumpDetails struct {
+	ID         uuid.UUID
+	ActivityID uuid.UUID
+	StartTime  time.Time
+	EndTime    *time.Time
+	LeftMl     *int
+	RightMl    *int
+	TotalMl    *int
+	Notes      *string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Medication is a family's catalog entry for a drug, holding its dose safety limits.
+type Medication struct {
+	ID                 uuid.UUID
+	FamilyID           uuid.UUID
+	Name               string
+	DefaultDoseAmount  *float64
+	DefaultDoseUnit    *DoseUnit
+	DefaultRoute       *MedicationRoute
+	MinIntervalMinutes *int
+	MaxDailyDoses      *int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type MedicationDetails struct {
+	ID                 uuid.UUID
+	ActivityID         uuid.UUID
+	MedicationID       *uuid.UUID // catalog entry matched at log time, if any
+	DrugName           string
+	DoseAmount         float64
+	DoseUnit           DoseUnit
+	Route              MedicationRoute
+	GivenAt            time.Time
+	IntervalOverridden bool // caregiver logged the dose despite a safety-check violation
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// GrowthMeasurement is one visit's worth of weight, length and/or head circumference
+type GrowthMeasurement struct {
+	ID                  uuid.UUID
+	FamilyID            uuid.UUID
+	BabyID              uuid.UUID
+	CaregiverID         *uuid.UUID
+	MeasuredAt          time.Time
+	WeightKg            *float64
+	LengthCm            *float64
+	HeadCircumferenceCm *float64
+	Notes               *string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
 // Prediction enums
 type PredictionType string
 
@@ -144,6 +283,7 @@ const (
 type ScheduleGoals struct {
 	ID                        uuid.UUID
 	FamilyID                  uuid.UUID
+	BabyID                    uuid.UUID
 	TargetWakeWindowMinutes   *int
 	TargetFeedIntervalMinutes *int
 	TargetNapCount            *int
@@ -157,6 +297,7 @@ type ScheduleGoals struct {
 type Prediction struct {
 	ID                       uuid.UUID
 	FamilyID                 uuid.UUID
+	BabyID                   uuid.UUID
 	CareSessionID            *uuid.UUID
 	ActivityType             ActivityType
 	PredictionType           PredictionType
@@ -169,4 +310,151 @@ type Prediction struct {
 	DismissedAt              *time.Time
 	ComputedAt               time.Time
 	CreatedAt                time.Time
-}
\ No newline at end of file
+}
+
+// PredictionOutcome records how an outstanding prediction compared with the feed or sleep
+// that fulfilled it. Predictions are replaced whenever data changes; outcomes are kept.
+type PredictionOutcome struct {
+	ID             uuid.UUID
+	FamilyID       uuid.UUID
+	BabyID         uuid.UUID
+	PredictionID   uuid.UUID
+	ActivityID     uuid.UUID
+	PredictionType PredictionType
+	Confidence     *PredictionConfidence
+	PredictedTime  time.Time
+	ActualTime     time.Time
+	CreatedAt      time.Time
+}
+
+// Offset is how far the prediction was from what happened; positive means it was late
+func (o *PredictionOutcome) Offset() time.Duration {
+	return o.PredictedTime.Sub(o.ActualTime)
+}
+
+// IdempotencyRecord marks a client write as applied so that replaying it is a no-op
+type IdempotencyRecord struct {
+	FamilyID   uuid.UUID
+	Key        string
+	ActivityID uuid.UUID
+	Operation  SyncOperation
+	CreatedAt  time.Time
+}
+
+// DeletedActivity is a tombstone telling syncing clients an activity was removed
+type DeletedActivity struct {
+	ActivityID uuid.UUID
+	FamilyID   uuid.UUID
+	BabyID     uuid.UUID
+	DeletedAt  time.Time
+}
+
+// Reminder lead time limits, in minutes before a predicted feed, nap or bedtime
+const (
+	DefaultReminderLeadMinutes = 15
+	MaxReminderLeadMinutes     = 120
+)
+
+// ReminderPreferences is a caregiver's opt-in to server-sent reminders. Caregivers
+// without preferences receive no reminders.
+type ReminderPreferences struct {
+	CaregiverID      uuid.UUID
+	FamilyID         uuid.UUID
+	FeedReminders    bool
+	NapReminders     bool
+	BedtimeReminders bool
+	OverdueReminders bool
+	LeadMinutes      int
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Webhook enums
+type WebhookEventType string
+
+const (
+	WebhookEventSessionStarted    WebhookEventType = "session_started"
+	WebhookEventSessionCompleted  WebhookEventType = "session_completed"
+	WebhookEventActivityAdded     WebhookEventType = "activity_added"
+	WebhookEventActivityUpdated   WebhookEventType = "activity_updated"
+	WebhookEventActivityDeleted   WebhookEventType = "activity_deleted"
+	WebhookEventPredictionOverdue WebhookEventType = "prediction_overdue"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookSubscription sends a family's events to a URL, signed with Secret. An empty
+// EventTypes filter subscribes to every event.
+type WebhookSubscription struct {
+	ID         uuid.UUID
+	FamilyID   uuid.UUID
+	URL        string
+	Secret     string
+	EventTypes []WebhookEventType
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Wants reports whether the subscription's filter includes eventType
+func (s *WebhookSubscription) Wants(eventType WebhookEventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription. Pending deliveries are
+// attempted at NextAttemptAt until they succeed or run out of attempts.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	FamilyID       uuid.UUID
+	EventType      WebhookEventType
+	Payload        string // JSON body, signed as sent
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Invite lifetime limits
+const (
+	DefaultInviteTTL = 72 * time.Hour
+	MaxInviteTTL     = 30 * 24 * time.Hour
+)
+
+// FamilyInvite lets someone join a family with a shared code. Only a hash of the code is
+// stored; the code itself is shown once, when the invite is created.
+type FamilyInvite struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	CodeHash  string
+	CreatedBy *uuid.UUID // nil once the creating caregiver has left
+	ExpiresAt time.Time
+	MaxUses   *int // nil means unlimited
+	UseCount  int
+	Role      CaregiverRole // given to caregivers who join with the invite
+	RevokedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Active reports whether the invite can still be redeemed at now.
+func (i *FamilyInvite) Active(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && (i.MaxUses == nil || i.UseCount < *i.MaxUses)
+}
@@ -0,0 +1,54 @@
+package domain
+
+// CaregiverRole decides what a caregiver may change in their family. Every caregiver
+// can read the family's data; roles only limit writes.
+type CaregiverRole string
+
+const (
+	// RoleOwner can do everything, including managing who is in the family
+	RoleOwner CaregiverRole = "owner"
+	// RoleParent can do everything except manage the family's caregivers
+	RoleParent CaregiverRole = "parent"
+	// RoleCaregiver can log care but not delete history or change settings, e.g. a nanny
+	RoleCaregiver CaregiverRole = "caregiver"
+	// RoleViewer can only look, e.g. a grandparent following along
+	RoleViewer CaregiverRole = "viewer"
+)
+
+// Permission is a kind of change a role may be allowed to make.
+type Permission string
+
+const (
+	// PermissionLogCare covers care sessions, logging and editing activities, and growth
+	PermissionLogCare Permission = "log care"
+	// PermissionDeleteHistory covers deleting logged activities
+	PermissionDeleteHistory Permission = "delete history"
+	// PermissionManageBabies covers baby profiles, schedule goals, the medication list and the
+	// family's timezone and prediction model
+	PermissionManageBabies Permission = "manage babies"
+	// PermissionManageFamily covers invites, caregivers' roles and devices, and webhooks
+	PermissionManageFamily Permission = "manage the family"
+)
+
+var rolePermissions = map[CaregiverRole][]Permission{
+	RoleOwner:     {PermissionLogCare, PermissionDeleteHistory, PermissionManageBabies, PermissionManageFamily},
+	RoleParent:    {PermissionLogCare, PermissionDeleteHistory, PermissionManageBabies},
+	RoleCaregiver: {PermissionLogCare},
+	RoleViewer:    {},
+}
+
+// Valid reports whether r is a known role.
+func (r CaregiverRole) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether r grants permission. Unknown roles grant nothing.
+func (r CaregiverRole) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestCaregiverRole_Can(t *testing.T) {
+	tests := []struct {
+		role       CaregiverRole
+		permission Permission
+		want       bool
+	}{
+		{RoleOwner, PermissionManageFamily, true},
+		{RoleParent, PermissionManageFamily, false},
+		{RoleParent, PermissionDeleteHistory, true},
+		{RoleParent, PermissionManageBabies, true},
+		{RoleCaregiver, PermissionLogCare, true},
+		{RoleCaregiver, PermissionDeleteHistory, false},
+		{RoleCaregiver, PermissionManageBabies, false},
+		{RoleViewer, PermissionLogCare, false},
+		{CaregiverRole("admin"), PermissionLogCare, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Can(tt.permission); got != tt.want {
+			t.Errorf("%s.Can(%s) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestCaregiverRole_Valid(t *testing.T) {
+	for _, role := range []CaregiverRole{RoleOwner, RoleParent, RoleCaregiver, RoleViewer} {
+		if !role.Valid() {
+			t.Errorf("expected %s to be valid", role)
+		}
+	}
+	if CaregiverRole("").Valid() || CaregiverRole("admin").Valid() {
+		t.Error("expected unknown roles to be invalid")
+	}
+}
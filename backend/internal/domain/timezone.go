@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateTimezone checks that name is an IANA timezone such as "America/Toronto"
+func ValidateTimezone(name string) error {
+	// LoadLocation accepts "" and "Local" as the server's own zone, which isn't a family's
+	if name == "" || name == "Local" {
+		return fmt.Errorf("invalid timezone: %q", name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("invalid timezone: %q", name)
+	}
+	return nil
+}
+
+// TimezoneAt returns the timezone the family is living in at now: the travel timezone
+// during travel mode, otherwise the home timezone. It is empty if neither is set.
+func (f *Family) TimezoneAt(now time.Time) string {
+	if f.Travel != nil && f.Travel.Active(now) {
+		return f.Travel.Timezone
+	}
+	return f.Timezone
+}
+
+// Active reports whether now falls on one of the travel dates, in the travel timezone
+func (t *TravelMode) Active(now time.Time) bool {
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	today := dateOf(now.In(loc))
+	return !today.Before(dateOf(t.StartDate)) && !today.After(dateOf(t.EndDate))
+}
+
+// dateOf returns t's calendar date as midnight UTC, so dates compare regardless of zone
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTimezone(t *testing.T) {
+	for _, name := range []string{"UTC", "America/Toronto", "Asia/Kolkata"} {
+		if err := ValidateTimezone(name); err != nil {
+			t.Errorf("ValidateTimezone(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "Local", "Mars/Olympus_Mons"} {
+		if err := ValidateTimezone(name); err == nil {
+			t.Errorf("ValidateTimezone(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestFamily_TimezoneAt(t *testing.T) {
+	family := &Family{
+		Timezone: "America/Toronto",
+		Travel: &TravelMode{
+			Timezone:  "Asia/Kolkata",
+			StartDate: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2026, 7, 14, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"before the trip", time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC), "America/Toronto"},
+		// 20:00 UTC on June 30 is already July 1 in Kolkata
+		{"first travel day, local time", time.Date(2026, 6, 30, 20, 0, 0, 0, time.UTC), "Asia/Kolkata"},
+		{"last travel day", time.Date(2026, 7, 14, 12, 0, 0, 0, time.UTC), "Asia/Kolkata"},
+		{"after the trip", time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC), "America/Toronto"},
+	}
+	for _, tt := range tests {
+		if got := family.TimezoneAt(tt.now); got != tt.want {
+			t.Errorf("%s: TimezoneAt(%s) = %q, want %q", tt.name, tt.now, got, tt.want)
+		}
+	}
+
+	if got := (&Family{}).TimezoneAt(time.Now()); got != "" {
+		t.Errorf("TimezoneAt() with nothing set = %q, want empty", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Package growth computes WHO Child Growth Standards z-scores and percentiles
+// for weight, length and head circumference from birth to 24 months.
+package growth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Indicator is a WHO growth-for-age chart.
+type Indicator string
+
+const (
+	WeightForAge            Indicator = "weight_for_age"
+	LengthForAge            Indicator = "length_for_age"
+	HeadCircumferenceForAge Indicator = "head_circumference_for_age"
+)
+
+// daysPerMonth is the WHO convention for converting age in days to months.
+const daysPerMonth = 30.4375
+
+// maxAgeMonths is the last month covered by the bundled tables.
+const maxAgeMonths = 24
+
+// ErrAgeOutOfRange is returned for measurements before birth or after 24 months.
+var ErrAgeOutOfRange = errors.New("age is outside the 0-24 month range of the WHO tables")
+
+// lms holds the Box-Cox power (L), median (M) and coefficient of variation (S) for one age.
+type lms struct {
+	L, M, S float64
+}
+
+// Result is a measurement's position on the WHO reference chart.
+type Result struct {
+	ZScore     float64
+	Percentile float64 // 0–100
+}
+
+// AgeInMonths returns the age at measuredAt in fractional months.
+// Both times are reduced to calendar dates so time of day doesn't shift the age.
+func AgeInMonths(birthDate, measuredAt time.Time) float64 {
+	birth := time.Date(birthDate.Year(), birthDate.Month(), birthDate.Day(), 0, 0, 0, 0, time.UTC)
+	measured := time.Date(measuredAt.Year(), measuredAt.Month(), measuredAt.Day(), 0, 0, 0, 0, time.UTC)
+	days := measured.Sub(birth).Hours() / 24
+	return days / daysPerMonth
+}
+
+// Compute returns the z-score and percentile of value (kg for weight, cm otherwise)
+// for a baby of the given sex and age in months.
+func Compute(indicator Indicator, sex domain.BabySex, ageMonths, value float64) (Result, error) {
+	if ageMonths < 0 || ageMonths > maxAgeMonths {
+		return Result{}, ErrAgeOutOfRange
+	}
+	if value <= 0 {
+		return Result{}, fmt.Errorf("measurement must be positive, got %v", value)
+	}
+
+	table, err := tableFor(indicator, sex)
+	if err != nil {
+		return Result{}, err
+	}
+
+	params := interpolate(table, ageMonths)
+	z := zScore(params, value)
+	if indicator == WeightForAge {
+		z = restrictTail(params, value, z)
+	}
+
+	return Result{ZScore: z, Percentile: Percentile(z)}, nil
+}
+
+// Percentile converts a z-score to a percentile on the standard normal distribution.
+func Percentile(z float64) float64 {
+	return 50 * math.Erfc(-z/math.Sqrt2)
+}
+
+func tableFor(indicator Indicator, sex domain.BabySex) (*[maxAgeMonths + 1]lms, error) {
+	boys := sex == domain.BabySexMale
+	if !boys && sex != domain.BabySexFemale {
+		return nil, fmt.Errorf("unsupported sex: %q", sex)
+	}
+
+	switch indicator {
+	case WeightForAge:
+		if boys {
+			return &weightForAgeBoys, nil
+		}
+		return &weightForAgeGirls, nil
+	case LengthForAge:
+		if boys {
+			return &lengthForAgeBoys, nil
+		}
+		return &lengthForAgeGirls, nil
+	case HeadCircumferenceForAge:
+		if boys {
+			return &headCircumferenceForAgeBoys, nil
+		}
+		return &headCircumferenceForAgeGirls, nil
+	default:
+		return nil, fmt.Errorf("unsupported indicator: %q", indicator)
+	}
+}
+
+// interpolate linearly interpolates LMS parameters between the surrounding whole months.
+func interpolate(table *[maxAgeMonths + 1]lms, ageMonths float64) lms {
+	lower := int(math.Floor(ageMonths))
+	if lower >= maxAgeMonths {
+		return table[maxAgeMonths]
+	}
+	frac := ageMonths - float64(lower)
+	a, b := table[lower], table[lower+1]
+	return lms{
+		L: a.L + (b.L-a.L)*frac,
+		M: a.M + (b.M-a.M)*frac,
+		S: a.S + (b.S-a.S)*frac,
+	}
+}
+
+func zScore(p lms, value float64) float64 {
+	if p.L == 0 {
+		return math.Log(value/p.M) / p.S
+	}
+	return (math.Pow(value/p.M, p.L) - 1) / (p.L * p.S)
+}
+
+// valueAt returns the measurement at z standard deviations from the median.
+func valueAt(p lms, z float64) float64 {
+	if p.L == 0 {
+		return p.M * math.Exp(p.S*z)
+	}
+	return p.M * math.Pow(1+p.L*p.S*z, 1/p.L)
+}
+
+// restrictTail applies the WHO adjustment for |z| > 3 on skewed indicators: beyond ±3 SD
+// the distance is measured in units of the 2–3 SD gap so extreme values aren't compressed.
+func restrictTail(p lms, value, z float64) float64 {
+	switch {
+	case z > 3:
+		sd3 := valueAt(p, 3)
+		return 3 + (value-sd3)/(sd3-valueAt(p, 2))
+	case z < -3:
+		sd3 := valueAt(p, -3)
+		return -3 + (value-sd3)/(valueAt(p, -2)-sd3)
+	default:
+		return z
+	}
+}
@@ -0,0 +1,133 @@
+package growth
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestCompute_MedianIsFiftiethPercentile(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator Indicator
+		sex       domain.BabySex
+		age       float64
+		value     float64
+	}{
+		{"boy weight at birth", WeightForAge, domain.BabySexMale, 0, 3.3464},
+		{"girl weight at 6 months", WeightForAge, domain.BabySexFemale, 6, 7.2970},
+		{"boy length at 12 months", LengthForAge, domain.BabySexMale, 12, 75.7488},
+		{"girl head at 24 months", HeadCircumferenceForAge, domain.BabySexFemale, 24, 47.1822},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compute(tt.indicator, tt.sex, tt.age, tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !almostEqual(result.ZScore, 0, 1e-6) {
+				t.Errorf("ZScore = %v, want 0", result.ZScore)
+			}
+			if !almostEqual(result.Percentile, 50, 1e-4) {
+				t.Errorf("Percentile = %v, want 50", result.Percentile)
+			}
+		})
+	}
+}
+
+func TestCompute_KnownSDValues(t *testing.T) {
+	// WHO published SD lines (rounded to 0.1 kg) for boys' weight at 6 months: -2 SD = 6.4 kg, +2 SD = 9.8 kg
+	low, err := Compute(WeightForAge, domain.BabySexMale, 6, 6.4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(low.ZScore, -2, 0.1) {
+		t.Errorf("ZScore = %v, want ~-2", low.ZScore)
+	}
+	if !almostEqual(low.Percentile, 2.3, 0.5) {
+		t.Errorf("Percentile = %v, want ~2.3", low.Percentile)
+	}
+
+	high, err := Compute(WeightForAge, domain.BabySexMale, 6, 9.8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(high.ZScore, 2, 0.1) {
+		t.Errorf("ZScore = %v, want ~2", high.ZScore)
+	}
+}
+
+func TestCompute_InterpolatesBetweenMonths(t *testing.T) {
+	// Halfway between the 3 and 4 month length medians for girls
+	median := (59.8029 + 62.0899) / 2
+	result, err := Compute(LengthForAge, domain.BabySexFemale, 3.5, median)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(result.ZScore, 0, 1e-6) {
+		t.Errorf("ZScore = %v, want 0", result.ZScore)
+	}
+}
+
+func TestCompute_RestrictsWeightTail(t *testing.T) {
+	p := weightForAgeBoys[6]
+	sd3, sd2 := valueAt(p, 3), valueAt(p, 2)
+
+	// One full 2–3 SD gap past +3 SD is +4 SD under the WHO adjustment
+	result, err := Compute(WeightForAge, domain.BabySexMale, 6, sd3+(sd3-sd2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(result.ZScore, 4, 1e-6) {
+		t.Errorf("ZScore = %v, want 4", result.ZScore)
+	}
+}
+
+func TestCompute_AgeOutOfRange(t *testing.T) {
+	for _, age := range []float64{-0.5, 24.1, 36} {
+		_, err := Compute(WeightForAge, domain.BabySexFemale, age, 10)
+		if !errors.Is(err, ErrAgeOutOfRange) {
+			t.Errorf("age %v: err = %v, want ErrAgeOutOfRange", age, err)
+		}
+	}
+}
+
+func TestCompute_InvalidInput(t *testing.T) {
+	if _, err := Compute(WeightForAge, domain.BabySex("unknown"), 3, 6); err == nil {
+		t.Error("expected error for unsupported sex")
+	}
+	if _, err := Compute(Indicator("bmi_for_age"), domain.BabySexMale, 3, 6); err == nil {
+		t.Error("expected error for unsupported indicator")
+	}
+	if _, err := Compute(WeightForAge, domain.BabySexMale, 3, 0); err == nil {
+		t.Error("expected error for non-positive measurement")
+	}
+}
+
+func TestAgeInMonths_IgnoresTimeOfDay(t *testing.T) {
+	birth := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	measured := time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)
+
+	got := AgeInMonths(birth, measured)
+	want := 182 / daysPerMonth
+	if !almostEqual(got, want, 1e-9) {
+		t.Errorf("AgeInMonths = %v, want %v", got, want)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	if !almostEqual(Percentile(1), 84.13, 0.01) {
+		t.Errorf("Percentile(1) = %v, want ~84.13", Percentile(1))
+	}
+	if !almostEqual(Percentile(-1.881), 3, 0.01) {
+		t.Errorf("Percentile(-1.881) = %v, want ~3", Percentile(-1.881))
+	}
+}
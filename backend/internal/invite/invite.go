@@ -0,0 +1,61 @@
+// Package invite generates and hashes the codes caregivers share to let someone join
+// their family.
+package invite
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// alphabet is Crockford's base32, which leaves out I, L, O and U so codes read aloud or
+// copied by hand aren't mistyped
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// codeLength is the number of significant characters in a code (60 bits)
+const codeLength = 12
+
+// NewCode returns a random code formatted as XXXX-XXXX-XXXX.
+func NewCode() (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	var sb strings.Builder
+	for i, v := range b {
+		if i > 0 && i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		// 256 is a multiple of 32, so this isn't biased
+		sb.WriteByte(alphabet[int(v)%len(alphabet)])
+	}
+	return sb.String(), nil
+}
+
+// Normalize canonicalizes a code as typed by a person: case, separators and the
+// characters Crockford's base32 treats as look-alikes are forgiven.
+func Normalize(code string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(code) {
+		switch r {
+		case '-', ' ':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Hash returns the hex SHA-256 of a normalized code, which is what the store keeps.
+// Codes are random enough that a fast unsalted hash is sufficient.
+func Hash(code string) string {
+	sum := sha256.Sum256([]byte(Normalize(code)))
+	return hex.EncodeToString(sum[:])
+}
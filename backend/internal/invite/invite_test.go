@@ -0,0 +1,39 @@
+package invite
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewCode(t *testing.T) {
+	format := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}$`)
+
+	seen := map[string]bool{}
+	for range 100 {
+		code, err := NewCode()
+		if err != nil {
+			t.Fatalf("NewCode failed: %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("code %q is not formatted as XXXX-XXXX-XXXX", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashForgivesTyping(t *testing.T) {
+	want := Hash("AB1C-D0EF-GH23")
+
+	for _, typed := range []string{"ab1c-d0ef-gh23", "AB1CD0EFGH23", "ABlC DOEF GH23", " abIc-doef-gh23 "} {
+		if got := Hash(typed); got != want {
+			t.Errorf("Hash(%q) differs from Hash of the canonical code", typed)
+		}
+	}
+
+	if Hash("AB1C-D0EF-GH24") == want {
+		t.Error("expected different codes to hash differently")
+	}
+}
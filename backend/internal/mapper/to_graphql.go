@@ -2,12 +2,17 @@ package mapper
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/graph/model"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/growth"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
 )
 
 var hhmmRegexp = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
@@ -48,14 +53,54 @@ func FamilyToGraphQL(f *domain.Family) *model.Family {
 		return nil
 	}
 
-	return &model.Family{
-		ID:        f.ID.String(),
-		Name:      f.Name,
-		BabyName:  f.BabyName,
-		Password:  f.Password, // Send plain password for sharing (HTTPS encrypts in transit)
-		CreatedAt: f.CreatedAt,
-		// Caregivers field loaded separately via resolver
+	family := &model.Family{
+		ID:              f.ID.String(),
+		Name:            f.Name,
+		BabyName:        f.BabyName,
+		PredictionModel: model.PredictionModelMedian,
+		CreatedAt:       f.CreatedAt,
+		// Caregivers and Babies fields loaded separately via resolver
 	}
+	if f.Timezone != "" {
+		family.Timezone = &f.Timezone
+	}
+	if f.PredictionModel != "" {
+		family.PredictionModel = model.PredictionModel(strings.ToUpper(string(f.PredictionModel)))
+	}
+	if f.PredictionHalfLife > 0 {
+		hours := int32(f.PredictionHalfLife.Hours())
+		family.PredictionHalfLifeHours = &hours
+	}
+	if f.Travel != nil {
+		family.TravelMode = &model.TravelMode{
+			Timezone:  f.Travel.Timezone,
+			StartDate: f.Travel.StartDate,
+			EndDate:   f.Travel.EndDate,
+		}
+	}
+	return family
+}
+
+// BabyToGraphQL converts a domain Baby to a GraphQL model
+func BabyToGraphQL(b *domain.Baby) *model.Baby {
+	if b == nil {
+		return nil
+	}
+
+	result := &model.Baby{
+		ID:        b.ID.String(),
+		FamilyID:  b.FamilyID.String(),
+		Name:      b.Name,
+		BirthDate: b.BirthDate,
+		CreatedAt: b.CreatedAt,
+	}
+
+	if b.Sex != nil {
+		sex := model.BabySex(strings.ToUpper(string(*b.Sex)))
+		result.Sex = &sex
+	}
+
+	return result
 }
 
 // CaregiverToGraphQL converts a domain Caregiver to a GraphQL model
@@ -75,6 +120,7 @@ func CaregiverToGraphQL(c *domain.Caregiver) *model.Caregiver {
 		Name:       c.Name,
 		DeviceID:   deviceID,
 		DeviceName: c.DeviceName,
+		Role:       model.CaregiverRole(strings.ToUpper(string(c.Role))),
 		CreatedAt:  c.CreatedAt,
 	}
 }
@@ -107,24 +153,48 @@ func ActivityToGraphQL(a *domain.Activity) model.Activity {
 	case domain.ActivityTypeFeed:
 		return &model.FeedActivity{
 			ID:           a.ID.String(),
+			BabyID:       a.BabyID.String(),
 			ActivityType: model.ActivityType(a.ActivityType),
 			CreatedAt:    a.CreatedAt,
+			Version:      int32(a.Version),
 			// FeedDetails loaded via resolver
 		}
 	case domain.ActivityTypeDiaper:
 		return &model.DiaperActivity{
 			ID:           a.ID.String(),
+			BabyID:       a.BabyID.String(),
 			ActivityType: model.ActivityType(a.ActivityType),
 			CreatedAt:    a.CreatedAt,
+			Version:      int32(a.Version),
 			// DiaperDetails loaded via resolver
 		}
 	case domain.ActivityTypeSleep:
 		return &model.SleepActivity{
 			ID:           a.ID.String(),
+			BabyID:       a.BabyID.String(),
 			ActivityType: model.ActivityType(a.ActivityType),
 			CreatedAt:    a.CreatedAt,
+			Version:      int32(a.Version),
 			// SleepDetails loaded via resolver
 		}
+	case domain.ActivityTypePump:
+		return &model.PumpActivity{
+			ID:           a.ID.String(),
+			BabyID:       a.BabyID.String(),
+			ActivityType: model.ActivityType(a.ActivityType),
+			CreatedAt:    a.CreatedAt,
+			Version:      int32(a.Version),
+			// PumpDetails loaded via resolver
+		}
+	case domain.ActivityTypeMedication:
+		return &model.MedicationActivity{
+			ID:           a.ID.String(),
+			BabyID:       a.BabyID.String(),
+			ActivityType: model.ActivityType(a.ActivityType),
+			CreatedAt:    a.CreatedAt,
+			Version:      int32(a.Version),
+			// MedicationDetails loaded via resolver
+		}
 	default:
 		return nil
 	}
@@ -218,6 +288,214 @@ func SleepDetailsToGraphQL(sd *domain.SleepDetails) *model.SleepDetails {
 	}
 }
 
+// PumpDetailsToGraphQL converts domain PumpDetails to GraphQL model
+func PumpDetailsToGraphQL(pd *domain.PumpDetails) *model.PumpDetails {
+	if pd == nil {
+		return nil
+	}
+
+	var durationMinutes *int32
+	if pd.EndTime != nil && !pd.EndTime.IsZero() {
+		duration := int32(pd.EndTime.Sub(pd.StartTime).Minutes())
+		durationMinutes = &duration
+	}
+
+	return &model.PumpDetails{
+		StartTime:       pd.StartTime,
+		EndTime:         pd.EndTime,
+		LeftMl:          intPtrToInt32(pd.LeftMl),
+		RightMl:         intPtrToInt32(pd.RightMl),
+		TotalMl:         intPtrToInt32(pd.TotalMl),
+		DurationMinutes: durationMinutes, // Calculated field
+		Notes:           pd.Notes,
+	}
+}
+
+// MedicationDetailsToGraphQL converts domain MedicationDetails to GraphQL model
+func MedicationDetailsToGraphQL(md *domain.MedicationDetails) *model.MedicationDetails {
+	if md == nil {
+		return nil
+	}
+
+	var medicationID *string
+	if md.MedicationID != nil {
+		id := md.MedicationID.String()
+		medicationID = &id
+	}
+
+	return &model.MedicationDetails{
+		MedicationID:       medicationID,
+		DrugName:           md.DrugName,
+		DoseAmount:         md.DoseAmount,
+		DoseUnit:           model.DoseUnit(strings.ToUpper(string(md.DoseUnit))),
+		Route:              model.MedicationRoute(strings.ToUpper(string(md.Route))),
+		GivenAt:            md.GivenAt,
+		IntervalOverridden: md.IntervalOverridden,
+	}
+}
+
+// MedicationToGraphQL converts a domain Medication catalog entry to a GraphQL model
+func MedicationToGraphQL(m *domain.Medication) *model.Medication {
+	if m == nil {
+		return nil
+	}
+
+	result := &model.Medication{
+		ID:                 m.ID.String(),
+		Name:               m.Name,
+		DefaultDoseAmount:  m.DefaultDoseAmount,
+		MinIntervalMinutes: intPtrToInt32(m.MinIntervalMinutes),
+		MaxDailyDoses:      intPtrToInt32(m.MaxDailyDoses),
+	}
+
+	if m.DefaultDoseUnit != nil {
+		du := model.DoseUnit(strings.ToUpper(string(*m.DefaultDoseUnit)))
+		result.DefaultDoseUnit = &du
+	}
+	if m.DefaultRoute != nil {
+		route := model.MedicationRoute(strings.ToUpper(string(*m.DefaultRoute)))
+		result.DefaultRoute = &route
+	}
+
+	return result
+}
+
+// MedicationInputToDomain converts a GraphQL MedicationInput to a domain Medication
+func MedicationInputToDomain(input model.MedicationInput, familyID uuid.UUID) (*domain.Medication, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	m := &domain.Medication{
+		FamilyID: familyID,
+		Name:     name,
+	}
+
+	if input.DefaultDoseAmount != nil {
+		if *input.DefaultDoseAmount <= 0 {
+			return nil, fmt.Errorf("defaultDoseAmount must be positive")
+		}
+		m.DefaultDoseAmount = input.DefaultDoseAmount
+	}
+	if input.DefaultDoseUnit != nil {
+		du := domain.DoseUnit(strings.ToLower(string(*input.DefaultDoseUnit)))
+		m.DefaultDoseUnit = &du
+	}
+	if input.DefaultRoute != nil {
+		route := domain.MedicationRoute(strings.ToLower(string(*input.DefaultRoute)))
+		m.DefaultRoute = &route
+	}
+	if input.MinIntervalMinutes != nil {
+		v := int(*input.MinIntervalMinutes)
+		if v <= 0 {
+			return nil, fmt.Errorf("minIntervalMinutes must be positive")
+		}
+		m.MinIntervalMinutes = &v
+	}
+	if input.MaxDailyDoses != nil {
+		v := int(*input.MaxDailyDoses)
+		if v <= 0 {
+			return nil, fmt.Errorf("maxDailyDoses must be positive")
+		}
+		m.MaxDailyDoses = &v
+	}
+
+	return m, nil
+}
+
+// GrowthMeasurementToGraphQL converts a domain GrowthMeasurement to GraphQL, adding the baby's
+// age and WHO percentiles when the baby has a birth date and sex on file.
+func GrowthMeasurementToGraphQL(m *domain.GrowthMeasurement, baby *domain.Baby) *model.GrowthMeasurement {
+	if m == nil {
+		return nil
+	}
+
+	result := &model.GrowthMeasurement{
+		ID:                  m.ID.String(),
+		BabyID:              m.BabyID.String(),
+		MeasuredAt:          m.MeasuredAt,
+		WeightKg:            m.WeightKg,
+		LengthCm:            m.LengthCm,
+		HeadCircumferenceCm: m.HeadCircumferenceCm,
+		Notes:               m.Notes,
+	}
+
+	if baby == nil || baby.BirthDate == nil {
+		return result
+	}
+
+	age := growth.AgeInMonths(*baby.BirthDate, m.MeasuredAt)
+	result.AgeMonths = &age
+
+	if baby.Sex == nil {
+		return result
+	}
+
+	result.WeightPercentile = growthPercentile(growth.WeightForAge, *baby.Sex, age, m.WeightKg)
+	result.LengthPercentile = growthPercentile(growth.LengthForAge, *baby.Sex, age, m.LengthCm)
+	result.HeadCircumferencePercentile = growthPercentile(growth.HeadCircumferenceForAge, *baby.Sex, age, m.HeadCircumferenceCm)
+
+	return result
+}
+
+// growthPercentile returns nil when the value wasn't measured or can't be placed on the WHO chart.
+func growthPercentile(indicator growth.Indicator, sex domain.BabySex, ageMonths float64, value *float64) *model.GrowthPercentile {
+	if value == nil {
+		return nil
+	}
+
+	r, err := growth.Compute(indicator, sex, ageMonths, *value)
+	if err != nil {
+		return nil
+	}
+
+	return &model.GrowthPercentile{
+		ZScore:     r.ZScore,
+		Percentile: r.Percentile,
+	}
+}
+
+// GrowthMeasurementInputToDomain converts a GraphQL GrowthMeasurementInput to a domain GrowthMeasurement
+func GrowthMeasurementInputToDomain(input model.GrowthMeasurementInput, familyID, babyID, caregiverID uuid.UUID) (*domain.GrowthMeasurement, error) {
+	if input.WeightKg == nil && input.LengthCm == nil && input.HeadCircumferenceCm == nil {
+		return nil, fmt.Errorf("at least one of weightKg, lengthCm or headCircumferenceCm is required")
+	}
+	if input.WeightKg != nil && *input.WeightKg <= 0 {
+		return nil, fmt.Errorf("weightKg must be positive")
+	}
+	if input.LengthCm != nil && *input.LengthCm <= 0 {
+		return nil, fmt.Errorf("lengthCm must be positive")
+	}
+	if input.HeadCircumferenceCm != nil && *input.HeadCircumferenceCm <= 0 {
+		return nil, fmt.Errorf("headCircumferenceCm must be positive")
+	}
+
+	now := time.Now()
+	return &domain.GrowthMeasurement{
+		ID:                  uuid.New(),
+		FamilyID:            familyID,
+		BabyID:              babyID,
+		CaregiverID:         &caregiverID,
+		MeasuredAt:          input.MeasuredAt,
+		WeightKg:            input.WeightKg,
+		LengthCm:            input.LengthCm,
+		HeadCircumferenceCm: input.HeadCircumferenceCm,
+		Notes:               input.Notes,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}, nil
+}
+
+// intPtrToInt32 converts an optional domain int to an optional GraphQL Int.
+func intPtrToInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
+
 // domainPredictionTypeToGraphQL maps domain PredictionType to GraphQL PredictionType.
 func domainPredictionTypeToGraphQL(pt domain.PredictionType) model.PredictionType {
 	return model.PredictionType(strings.ToUpper(string(pt)))
@@ -241,6 +519,7 @@ func PredictionToGraphQL(p *domain.Prediction) *model.Prediction {
 
 	gql := &model.Prediction{
 		ID:             p.ID.String(),
+		BabyID:         p.BabyID.String(),
 		ActivityType:   model.ActivityType(p.ActivityType),
 		PredictionType: domainPredictionTypeToGraphQL(p.PredictionType),
 		PredictedTime:  p.PredictedTime,
@@ -271,13 +550,50 @@ func PredictionToGraphQL(p *domain.Prediction) *model.Prediction {
 	return gql
 }
 
+// PredictionAccuracyToGraphQL converts the accuracy of one prediction type to a GraphQL model
+func PredictionAccuracyToGraphQL(predictionType domain.PredictionType, a prediction.Accuracy) *model.PredictionAccuracy {
+	gql := &model.PredictionAccuracy{
+		PredictionType: domainPredictionTypeToGraphQL(predictionType),
+		SampleCount:    int32(a.Count),
+	}
+	if a.Count == 0 {
+		return gql
+	}
+
+	mae := a.MAE.Minutes()
+	bias := a.Bias.Minutes()
+	gql.MeanAbsoluteErrorMinutes = &mae
+	gql.MeanErrorMinutes = &bias
+	gql.Within15Rate = &a.Within15
+	gql.Within30Rate = &a.Within30
+	return gql
+}
+
+// DetectedPatternToGraphQL converts a detected feeding pattern to a GraphQL model
+func DetectedPatternToGraphQL(p prediction.Pattern, now time.Time) *model.DetectedPattern {
+	gql := &model.DetectedPattern{
+		Type:        model.DetectedPatternType(strings.ToUpper(string(p.Type))),
+		StartTime:   p.Start,
+		EndTime:     p.End,
+		FeedCount:   int32(p.Feeds),
+		Ongoing:     p.OngoingAt(now),
+		Description: p.Description,
+	}
+	if p.Type == prediction.PatternGrowthSpurt {
+		gql.Increase = &p.Increase
+	}
+	return gql
+}
+
 // ScheduleGoalsToGraphQL converts a domain ScheduleGoals to a GraphQL model
 func ScheduleGoalsToGraphQL(sg *domain.ScheduleGoals) *model.ScheduleGoals {
 	if sg == nil {
 		return nil
 	}
 
-	result := &model.ScheduleGoals{}
+	result := &model.ScheduleGoals{
+		BabyID: sg.BabyID.String(),
+	}
 
 	if sg.TargetWakeWindowMinutes != nil {
 		v := int32(*sg.TargetWakeWindowMinutes)
@@ -349,4 +665,192 @@ func ScheduleGoalsInputToDomain(input model.ScheduleGoalsInput, familyID uuid.UU
 	}
 
 	return sg, nil
-}
\ No newline at end of file
+}
+
+// ReminderPreferencesToGraphQL converts domain ReminderPreferences to a GraphQL model
+func ReminderPreferencesToGraphQL(p *domain.ReminderPreferences) *model.ReminderPreferences {
+	if p == nil {
+		return nil
+	}
+
+	return &model.ReminderPreferences{
+		CaregiverID:      p.CaregiverID.String(),
+		FeedReminders:    p.FeedReminders,
+		NapReminders:     p.NapReminders,
+		BedtimeReminders: p.BedtimeReminders,
+		OverdueReminders: p.OverdueReminders,
+		LeadMinutes:      int32(p.LeadMinutes),
+	}
+}
+
+// ReminderPreferencesInputToDomain converts a GraphQL ReminderPreferencesInput to domain
+// ReminderPreferences for the given caregiver
+func ReminderPreferencesInputToDomain(input model.ReminderPreferencesInput, caregiverID uuid.UUID) (*domain.ReminderPreferences, error) {
+	prefs := &domain.ReminderPreferences{
+		CaregiverID:      caregiverID,
+		FeedReminders:    input.FeedReminders,
+		NapReminders:     input.NapReminders,
+		BedtimeReminders: input.BedtimeReminders,
+		OverdueReminders: input.OverdueReminders,
+		LeadMinutes:      domain.DefaultReminderLeadMinutes,
+	}
+
+	if input.LeadMinutes != nil {
+		v := int(*input.LeadMinutes)
+		if v < 0 || v > domain.MaxReminderLeadMinutes {
+			return nil, fmt.Errorf("leadMinutes must be between 0 and %d", domain.MaxReminderLeadMinutes)
+		}
+		prefs.LeadMinutes = v
+	}
+
+	return prefs, nil
+}
+
+// WebhookSubscriptionToGraphQL converts a domain WebhookSubscription to a GraphQL model.
+// The secret is never returned.
+func WebhookSubscriptionToGraphQL(sub *domain.WebhookSubscription) *model.WebhookSubscription {
+	if sub == nil {
+		return nil
+	}
+
+	eventTypes := make([]model.WebhookEventType, 0, len(sub.EventTypes))
+	for _, t := range sub.EventTypes {
+		eventTypes = append(eventTypes, model.WebhookEventType(strings.ToUpper(string(t))))
+	}
+
+	return &model.WebhookSubscription{
+		ID:         sub.ID.String(),
+		URL:        sub.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  sub.CreatedAt,
+	}
+}
+
+// WebhookSubscriptionInputToDomain converts a GraphQL WebhookSubscriptionInput to a new
+// domain WebhookSubscription for the given family
+func WebhookSubscriptionInputToDomain(input model.WebhookSubscriptionInput, familyID uuid.UUID) (*domain.WebhookSubscription, error) {
+	u, err := url.Parse(input.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if strings.TrimSpace(input.Secret) == "" {
+		return nil, fmt.Errorf("secret is required")
+	}
+
+	var eventTypes []domain.WebhookEventType
+	for _, t := range input.EventTypes {
+		eventType := domain.WebhookEventType(strings.ToLower(string(t)))
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	now := time.Now()
+	return &domain.WebhookSubscription{
+		ID:         uuid.New(),
+		FamilyID:   familyID,
+		URL:        input.URL,
+		Secret:     input.Secret,
+		EventTypes: eventTypes,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// WebhookDeliveryToGraphQL converts a domain WebhookDelivery to a GraphQL model
+func WebhookDeliveryToGraphQL(d *domain.WebhookDelivery) *model.WebhookDelivery {
+	if d == nil {
+		return nil
+	}
+
+	return &model.WebhookDelivery{
+		ID:             d.ID.String(),
+		SubscriptionID: d.SubscriptionID.String(),
+		EventType:      model.WebhookEventType(strings.ToUpper(string(d.EventType))),
+		Status:         model.WebhookDeliveryStatus(strings.ToUpper(string(d.Status))),
+		Attempts:       int32(d.Attempts),
+		LastStatusCode: intPtrToInt32(d.LastStatusCode),
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		Payload:        d.Payload,
+	}
+}
+
+// FamilyInviteToGraphQL converts a domain FamilyInvite to a GraphQL model, computing
+// whether it is still active at now. The code hash is never returned.
+func FamilyInviteToGraphQL(i *domain.FamilyInvite, now time.Time) *model.FamilyInvite {
+	if i == nil {
+		return nil
+	}
+
+	result := &model.FamilyInvite{
+		ID:        i.ID.String(),
+		ExpiresAt: i.ExpiresAt,
+		MaxUses:   intPtrToInt32(i.MaxUses),
+		UseCount:  int32(i.UseCount),
+		Role:      model.CaregiverRole(strings.ToUpper(string(i.Role))),
+		RevokedAt: i.RevokedAt,
+		Active:    i.Active(now),
+		CreatedAt: i.CreatedAt,
+	}
+	if i.CreatedBy != nil {
+		createdBy := i.CreatedBy.String()
+		result.CreatedBy = &createdBy
+	}
+
+	return result
+}
+
+// AuditEventToGraphQL converts a domain AuditEvent to a GraphQL model
+func AuditEventToGraphQL(e *domain.AuditEvent) *model.AuditEvent {
+	if e == nil {
+		return nil
+	}
+
+	return &model.AuditEvent{
+		ID:               e.ID.String(),
+		ActorCaregiverID: e.ActorCaregiverID.String(),
+		ActorName:        e.ActorName,
+		Action:           model.AuditAction(strings.ToUpper(string(e.Action))),
+		EntityType:       model.AuditEntityType(strings.ToUpper(string(e.EntityType))),
+		EntityID:         e.EntityID.String(),
+		Before:           e.Before,
+		After:            e.After,
+		CreatedAt:        e.CreatedAt,
+	}
+}
+
+// AuditLogFilterToDomain converts an optional GraphQL AuditLogFilter to a domain AuditFilter
+func AuditLogFilterToDomain(filter *model.AuditLogFilter) (domain.AuditFilter, error) {
+	var result domain.AuditFilter
+	if filter == nil {
+		return result, nil
+	}
+
+	if filter.ActorCaregiverID != nil {
+		id, err := uuid.Parse(*filter.ActorCaregiverID)
+		if err != nil {
+			return result, fmt.Errorf("invalid actor caregiver ID: %w", err)
+		}
+		result.ActorCaregiverID = &id
+	}
+	if filter.EntityID != nil {
+		id, err := uuid.Parse(*filter.EntityID)
+		if err != nil {
+			return result, fmt.Errorf("invalid entity ID: %w", err)
+		}
+		result.EntityID = &id
+	}
+	if filter.Action != nil {
+		action := domain.AuditAction(strings.ToLower(string(*filter.Action)))
+		result.Action = &action
+	}
+	if filter.EntityType != nil {
+		entityType := domain.AuditEntityType(strings.ToLower(string(*filter.EntityType)))
+		result.EntityType = &entityType
+	}
+
+	return result, nil
+}
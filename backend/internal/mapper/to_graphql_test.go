@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -17,7 +18,6 @@ func TestFamilyToGraphQL(t *testing.T) {
 		ID:        id,
 		Name:      "Smith Family",
 		BabyName:  "Liam",
-		Password:  "abc123",
 		CreatedAt: now,
 	}
 
@@ -32,12 +32,12 @@ func TestFamilyToGraphQL(t *testing.T) {
 	if result.BabyName != "Liam" {
 		t.Errorf("BabyName = %q, want %q", result.BabyName, "Liam")
 	}
-	if result.Password != "abc123" {
-		t.Errorf("Password = %q, want %q", result.Password, "abc123")
-	}
 	if !result.CreatedAt.Equal(now) {
 		t.Errorf("CreatedAt = %v, want %v", result.CreatedAt, now)
 	}
+	if result.PredictionModel != model.PredictionModelMedian || result.PredictionHalfLifeHours != nil {
+		t.Errorf("PredictionModel = %v with half-life %v, want MEDIAN by default", result.PredictionModel, result.PredictionHalfLifeHours)
+	}
 }
 
 func TestFamilyToGraphQL_Nil(t *testing.T) {
@@ -60,6 +60,7 @@ func TestCaregiverToGraphQL(t *testing.T) {
 		Name:       "Alice",
 		DeviceID:   &deviceID,
 		DeviceName: &deviceName,
+		Role:       domain.RoleParent,
 		CreatedAt:  now,
 	}
 
@@ -77,6 +78,9 @@ func TestCaregiverToGraphQL(t *testing.T) {
 	if result.DeviceID != "device-123" {
 		t.Errorf("DeviceID = %q, want %q", result.DeviceID, "device-123")
 	}
+	if result.Role != model.CaregiverRoleParent {
+		t.Errorf("Role = %q, want %q", result.Role, model.CaregiverRoleParent)
+	}
 	if result.DeviceName == nil || *result.DeviceName != "iPhone 15" {
 		t.Errorf("DeviceName = %v, want %q", result.DeviceName, "iPhone 15")
 	}
@@ -216,6 +220,23 @@ func TestActivityToGraphQL_Sleep(t *testing.T) {
 	}
 }
 
+func TestActivityToGraphQL_Pump(t *testing.T) {
+	a := &domain.Activity{
+		ID:           uuid.New(),
+		ActivityType: domain.ActivityTypePump,
+		CreatedAt:    time.Now(),
+	}
+
+	result := ActivityToGraphQL(a)
+	pump, ok := result.(*model.PumpActivity)
+	if !ok {
+		t.Fatalf("expected *model.PumpActivity, got %T", result)
+	}
+	if pump.ActivityType != model.ActivityType(domain.ActivityTypePump) {
+		t.Errorf("ActivityType = %q, want %q", pump.ActivityType, domain.ActivityTypePump)
+	}
+}
+
 func TestActivityToGraphQL_UnknownType(t *testing.T) {
 	a := &domain.Activity{
 		ID:           uuid.New(),
@@ -482,6 +503,68 @@ func TestSleepDetailsToGraphQL_Nil(t *testing.T) {
 	}
 }
 
+func TestPumpDetailsToGraphQL_BothSides(t *testing.T) {
+	start := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 1, 6, 20, 0, 0, time.UTC)
+	left, right, total := 70, 50, 120
+	notes := "after morning feed"
+
+	pd := &domain.PumpDetails{
+		StartTime: start,
+		EndTime:   &end,
+		LeftMl:    &left,
+		RightMl:   &right,
+		TotalMl:   &total,
+		Notes:     &notes,
+	}
+
+	result := PumpDetailsToGraphQL(pd)
+
+	if result.LeftMl == nil || *result.LeftMl != 70 {
+		t.Errorf("LeftMl = %v, want 70", result.LeftMl)
+	}
+	if result.RightMl == nil || *result.RightMl != 50 {
+		t.Errorf("RightMl = %v, want 50", result.RightMl)
+	}
+	if result.TotalMl == nil || *result.TotalMl != 120 {
+		t.Errorf("TotalMl = %v, want 120", result.TotalMl)
+	}
+	if result.DurationMinutes == nil || *result.DurationMinutes != 20 {
+		t.Errorf("DurationMinutes = %v, want 20", result.DurationMinutes)
+	}
+	if result.Notes == nil || *result.Notes != notes {
+		t.Errorf("Notes = %v, want %q", result.Notes, notes)
+	}
+}
+
+func TestPumpDetailsToGraphQL_TotalOnly(t *testing.T) {
+	total := 90
+
+	pd := &domain.PumpDetails{
+		StartTime: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		TotalMl:   &total,
+	}
+
+	result := PumpDetailsToGraphQL(pd)
+
+	if result.LeftMl != nil || result.RightMl != nil {
+		t.Errorf("expected nil per-side amounts, got left=%v right=%v", result.LeftMl, result.RightMl)
+	}
+	if result.TotalMl == nil || *result.TotalMl != 90 {
+		t.Errorf("TotalMl = %v, want 90", result.TotalMl)
+	}
+	if result.DurationMinutes != nil {
+		t.Errorf("DurationMinutes = %v, want nil", result.DurationMinutes)
+	}
+}
+
+func TestPumpDetailsToGraphQL_Nil(t *testing.T) {
+	result := PumpDetailsToGraphQL(nil)
+	if result != nil {
+		t.Error("expected nil for nil input")
+	}
+}
+
 func TestScheduleGoalsToGraphQL_Nil(t *testing.T) {
 	result := ScheduleGoalsToGraphQL(nil)
 	if result != nil {
@@ -644,3 +727,344 @@ func TestScheduleGoalsInputToDomain_ValidBoundary(t *testing.T) {
 		t.Errorf("TargetWakeTime = %v, want 23:59", *result.TargetWakeTime)
 	}
 }
+
+func TestMedicationDetailsToGraphQL(t *testing.T) {
+	medID := uuid.New()
+	givenAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	md := &domain.MedicationDetails{
+		MedicationID:       &medID,
+		DrugName:           "Ibuprofen",
+		DoseAmount:         2.5,
+		DoseUnit:           domain.DoseUnitMl,
+		Route:              domain.MedicationRouteOral,
+		GivenAt:            givenAt,
+		IntervalOverridden: true,
+	}
+
+	result := MedicationDetailsToGraphQL(md)
+
+	if result.MedicationID == nil || *result.MedicationID != medID.String() {
+		t.Errorf("MedicationID = %v, want %s", result.MedicationID, medID)
+	}
+	if result.DoseUnit != model.DoseUnitMl {
+		t.Errorf("DoseUnit = %q, want %q", result.DoseUnit, model.DoseUnitMl)
+	}
+	if result.Route != model.MedicationRouteOral {
+		t.Errorf("Route = %q, want %q", result.Route, model.MedicationRouteOral)
+	}
+	if !result.GivenAt.Equal(givenAt) {
+		t.Errorf("GivenAt = %v, want %v", result.GivenAt, givenAt)
+	}
+	if !result.IntervalOverridden {
+		t.Error("expected IntervalOverridden = true")
+	}
+}
+
+func TestMedicationDetailsToGraphQL_Nil(t *testing.T) {
+	if MedicationDetailsToGraphQL(nil) != nil {
+		t.Error("expected nil for nil input")
+	}
+}
+
+func TestMedicationToGraphQL(t *testing.T) {
+	amount := 2.5
+	unit := domain.DoseUnitMl
+	route := domain.MedicationRouteRectal
+	interval := 240
+	maxDaily := 5
+
+	m := &domain.Medication{
+		ID:                 uuid.New(),
+		Name:               "Acetaminophen",
+		DefaultDoseAmount:  &amount,
+		DefaultDoseUnit:    &unit,
+		DefaultRoute:       &route,
+		MinIntervalMinutes: &interval,
+		MaxDailyDoses:      &maxDaily,
+	}
+
+	result := MedicationToGraphQL(m)
+
+	if result.DefaultDoseUnit == nil || *result.DefaultDoseUnit != model.DoseUnitMl {
+		t.Errorf("DefaultDoseUnit = %v, want ML", result.DefaultDoseUnit)
+	}
+	if result.DefaultRoute == nil || *result.DefaultRoute != model.MedicationRouteRectal {
+		t.Errorf("DefaultRoute = %v, want RECTAL", result.DefaultRoute)
+	}
+	if result.MinIntervalMinutes == nil || *result.MinIntervalMinutes != 240 {
+		t.Errorf("MinIntervalMinutes = %v, want 240", result.MinIntervalMinutes)
+	}
+	if result.MaxDailyDoses == nil || *result.MaxDailyDoses != 5 {
+		t.Errorf("MaxDailyDoses = %v, want 5", result.MaxDailyDoses)
+	}
+}
+
+func TestMedicationInputToDomain_Valid(t *testing.T) {
+	interval := int32(360)
+	unit := model.DoseUnitMg
+	familyID := uuid.New()
+
+	m, err := MedicationInputToDomain(model.MedicationInput{
+		Name:               "  Ibuprofen ",
+		DefaultDoseUnit:    &unit,
+		MinIntervalMinutes: &interval,
+	}, familyID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Name != "Ibuprofen" {
+		t.Errorf("Name = %q, want trimmed %q", m.Name, "Ibuprofen")
+	}
+	if m.FamilyID != familyID {
+		t.Errorf("FamilyID = %v, want %v", m.FamilyID, familyID)
+	}
+	if m.DefaultDoseUnit == nil || *m.DefaultDoseUnit != domain.DoseUnitMg {
+		t.Errorf("DefaultDoseUnit = %v, want mg", m.DefaultDoseUnit)
+	}
+	if m.MinIntervalMinutes == nil || *m.MinIntervalMinutes != 360 {
+		t.Errorf("MinIntervalMinutes = %v, want 360", m.MinIntervalMinutes)
+	}
+}
+
+func TestMedicationInputToDomain_Invalid(t *testing.T) {
+	zero := int32(0)
+	negativeAmount := -1.0
+
+	tests := []struct {
+		name  string
+		input model.MedicationInput
+	}{
+		{"empty name", model.MedicationInput{Name: "  "}},
+		{"zero interval", model.MedicationInput{Name: "Ibuprofen", MinIntervalMinutes: &zero}},
+		{"zero max daily", model.MedicationInput{Name: "Ibuprofen", MaxDailyDoses: &zero}},
+		{"negative dose", model.MedicationInput{Name: "Ibuprofen", DefaultDoseAmount: &negativeAmount}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MedicationInputToDomain(tt.input, uuid.New()); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestBabyToGraphQL(t *testing.T) {
+	birthDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	sex := domain.BabySexMale
+	familyID := uuid.New()
+
+	result := BabyToGraphQL(&domain.Baby{ID: uuid.New(), FamilyID: familyID, Name: "Ava", BirthDate: &birthDate, Sex: &sex})
+
+	if result.Name != "Ava" {
+		t.Errorf("Name = %q, want Ava", result.Name)
+	}
+	if result.FamilyID != familyID.String() {
+		t.Errorf("FamilyID = %q, want %q", result.FamilyID, familyID.String())
+	}
+	if result.BirthDate == nil || !result.BirthDate.Equal(birthDate) {
+		t.Errorf("BirthDate = %v, want %v", result.BirthDate, birthDate)
+	}
+	if result.Sex == nil || *result.Sex != model.BabySexMale {
+		t.Errorf("Sex = %v, want MALE", result.Sex)
+	}
+}
+
+func TestBabyToGraphQL_Nil(t *testing.T) {
+	if BabyToGraphQL(nil) != nil {
+		t.Error("expected nil for nil input")
+	}
+}
+
+func TestGrowthMeasurementToGraphQL_OlderThanTables(t *testing.T) {
+	birthDate := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	sex := domain.BabySexFemale
+	baby := &domain.Baby{BirthDate: &birthDate, Sex: &sex}
+	weight := 14.0
+
+	result := GrowthMeasurementToGraphQL(&domain.GrowthMeasurement{
+		ID:         uuid.New(),
+		MeasuredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		WeightKg:   &weight,
+	}, baby)
+
+	if result.AgeMonths == nil || *result.AgeMonths < 35 {
+		t.Errorf("AgeMonths = %v, want ~36", result.AgeMonths)
+	}
+	if result.WeightPercentile != nil {
+		t.Error("expected no percentile past 24 months")
+	}
+}
+
+func TestGrowthMeasurementInputToDomain_Invalid(t *testing.T) {
+	negative := -3.0
+
+	tests := []struct {
+		name  string
+		input model.GrowthMeasurementInput
+	}{
+		{"no values", model.GrowthMeasurementInput{MeasuredAt: time.Now()}},
+		{"negative weight", model.GrowthMeasurementInput{MeasuredAt: time.Now(), WeightKg: &negative}},
+		{"negative head", model.GrowthMeasurementInput{MeasuredAt: time.Now(), HeadCircumferenceCm: &negative}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GrowthMeasurementInputToDomain(tt.input, uuid.New(), uuid.New(), uuid.New()); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestReminderPreferencesInputToDomain_DefaultLead(t *testing.T) {
+	caregiverID := uuid.New()
+
+	result, err := ReminderPreferencesInputToDomain(model.ReminderPreferencesInput{FeedReminders: true}, caregiverID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.CaregiverID != caregiverID || !result.FeedReminders || result.NapReminders {
+		t.Errorf("unexpected preferences: %+v", result)
+	}
+	if result.LeadMinutes != domain.DefaultReminderLeadMinutes {
+		t.Errorf("LeadMinutes = %d, want %d", result.LeadMinutes, domain.DefaultReminderLeadMinutes)
+	}
+}
+
+func TestReminderPreferencesInputToDomain_LeadOutOfRange(t *testing.T) {
+	for _, lead := range []int32{-1, domain.MaxReminderLeadMinutes + 1} {
+		input := model.ReminderPreferencesInput{LeadMinutes: &lead}
+		if _, err := ReminderPreferencesInputToDomain(input, uuid.New()); err == nil {
+			t.Errorf("expected error for leadMinutes %d", lead)
+		}
+	}
+}
+
+func TestWebhookSubscriptionInputToDomain(t *testing.T) {
+	familyID := uuid.New()
+	input := model.WebhookSubscriptionInput{
+		URL:        "https://home.example.com/hooks/baby",
+		Secret:     "s3cret",
+		EventTypes: []model.WebhookEventType{model.WebhookEventTypeActivityAdded, model.WebhookEventTypeActivityAdded, model.WebhookEventTypePredictionOverdue},
+	}
+
+	sub, err := WebhookSubscriptionInputToDomain(input, familyID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.FamilyID != familyID || sub.URL != input.URL || sub.Secret != input.Secret {
+		t.Errorf("unexpected subscription: %+v", sub)
+	}
+	want := []domain.WebhookEventType{domain.WebhookEventActivityAdded, domain.WebhookEventPredictionOverdue}
+	if !slices.Equal(sub.EventTypes, want) {
+		t.Errorf("EventTypes = %v, want %v", sub.EventTypes, want)
+	}
+
+	result := WebhookSubscriptionToGraphQL(sub)
+	if !slices.Equal(result.EventTypes, []model.WebhookEventType{model.WebhookEventTypeActivityAdded, model.WebhookEventTypePredictionOverdue}) {
+		t.Errorf("GraphQL EventTypes = %v", result.EventTypes)
+	}
+}
+
+func TestWebhookSubscriptionInputToDomain_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input model.WebhookSubscriptionInput
+	}{
+		{"relative url", model.WebhookSubscriptionInput{URL: "/hooks", Secret: "s"}},
+		{"unsupported scheme", model.WebhookSubscriptionInput{URL: "ftp://example.com/hooks", Secret: "s"}},
+		{"missing secret", model.WebhookSubscriptionInput{URL: "https://example.com/hooks", Secret: " "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := WebhookSubscriptionInputToDomain(tt.input, uuid.New()); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestFamilyInviteToGraphQL_Active(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	maxUses := 2
+	i := &domain.FamilyInvite{
+		ID:        uuid.New(),
+		FamilyID:  uuid.New(),
+		CodeHash:  "hash",
+		ExpiresAt: now.Add(time.Hour),
+		MaxUses:   &maxUses,
+		UseCount:  1,
+		CreatedAt: now,
+	}
+
+	result := FamilyInviteToGraphQL(i, now)
+	if !result.Active || result.MaxUses == nil || *result.MaxUses != 2 || result.UseCount != 1 || result.CreatedBy != nil {
+		t.Errorf("unexpected invite: %+v", result)
+	}
+
+	i.UseCount = 2
+	if FamilyInviteToGraphQL(i, now).Active {
+		t.Error("expected a used-up invite to be inactive")
+	}
+	if FamilyInviteToGraphQL(&domain.FamilyInvite{ExpiresAt: now}, now).Active {
+		t.Error("expected an invite to be inactive at its expiry")
+	}
+}
+
+func TestAuditEventToGraphQL(t *testing.T) {
+	after := `{"name":"Ava"}`
+	e := &domain.AuditEvent{
+		ID:               uuid.New(),
+		FamilyID:         uuid.New(),
+		ActorCaregiverID: uuid.New(),
+		ActorName:        "Mom",
+		Action:           domain.AuditActionCreate,
+		EntityType:       domain.AuditEntityCareSession,
+		EntityID:         uuid.New(),
+		After:            &after,
+		CreatedAt:        time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+	}
+
+	result := AuditEventToGraphQL(e)
+	if result.Action != model.AuditActionCreate || result.EntityType != model.AuditEntityTypeCareSession {
+		t.Errorf("expected CREATE CARE_SESSION, got %s %s", result.Action, result.EntityType)
+	}
+	if result.ActorCaregiverID != e.ActorCaregiverID.String() || result.ActorName != "Mom" || result.EntityID != e.EntityID.String() {
+		t.Errorf("unexpected audit event: %+v", result)
+	}
+	if result.Before != nil || result.After == nil || *result.After != after {
+		t.Errorf("expected only an after snapshot, got before %v after %v", result.Before, result.After)
+	}
+}
+
+func TestAuditLogFilterToDomain(t *testing.T) {
+	entityID := uuid.New()
+	action := model.AuditActionDelete
+	entityType := model.AuditEntityTypeScheduleGoals
+	idStr := entityID.String()
+
+	result, err := AuditLogFilterToDomain(&model.AuditLogFilter{Action: &action, EntityType: &entityType, EntityID: &idStr})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Action == nil || *result.Action != domain.AuditActionDelete {
+		t.Errorf("expected delete action, got %v", result.Action)
+	}
+	if result.EntityType == nil || *result.EntityType != domain.AuditEntityScheduleGoals {
+		t.Errorf("expected schedule_goals entity type, got %v", result.EntityType)
+	}
+	if result.EntityID == nil || *result.EntityID != entityID || result.ActorCaregiverID != nil {
+		t.Errorf("unexpected filter: %+v", result)
+	}
+
+	if result, err := AuditLogFilterToDomain(nil); err != nil || result != (domain.AuditFilter{}) {
+		t.Errorf("expected an empty filter for nil, got %+v (err %v)", result, err)
+	}
+	bad := "not-a-uuid"
+	if _, err := AuditLogFilterToDomain(&model.AuditLogFilter{ActorCaregiverID: &bad}); err == nil {
+		t.Error("expected error for an invalid caregiver ID")
+	}
+}
@@ -0,0 +1,104 @@
+package medication
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// dailyWindow is the rolling window max daily doses are counted over.
+const dailyWindow = 24 * time.Hour
+
+// Status describes whether another dose of a medication may be given at a point in time.
+type Status struct {
+	LastDose       *domain.MedicationDetails
+	DosesLast24h   int
+	NextAllowedAt  *time.Time // nil when a dose is allowed right away
+	CanGiveNow     bool
+	Reason         string // why a dose is not allowed yet; empty when CanGiveNow
+	MinIntervalHit bool
+	MaxDailyHit    bool
+}
+
+// Matches reports whether a logged dose belongs to the catalog medication,
+// by catalog ID when the dose was linked at log time, otherwise by case-insensitive name.
+func Matches(med *domain.Medication, dose *domain.MedicationDetails) bool {
+	if dose.MedicationID != nil {
+		return *dose.MedicationID == med.ID
+	}
+	return strings.EqualFold(strings.TrimSpace(dose.DrugName), strings.TrimSpace(med.Name))
+}
+
+// Check evaluates the medication's min-interval and max-daily-dose limits against the
+// previously logged doses as of time at. Doses that don't belong to med are ignored,
+// as are doses given after at.
+func Check(med *domain.Medication, doses []*domain.MedicationDetails, at time.Time) Status {
+	var relevant []*domain.MedicationDetails
+	for _, d := range doses {
+		if d.GivenAt.After(at) || !Matches(med, d) {
+			continue
+		}
+		relevant = append(relevant, d)
+	}
+	sort.Slice(relevant, func(i, j int) bool {
+		return relevant[i].GivenAt.Before(relevant[j].GivenAt)
+	})
+
+	status := Status{CanGiveNow: true}
+	if len(relevant) == 0 {
+		return status
+	}
+	status.LastDose = relevant[len(relevant)-1]
+
+	var inWindow []*domain.MedicationDetails
+	windowStart := at.Add(-dailyWindow)
+	for _, d := range relevant {
+		if d.GivenAt.After(windowStart) {
+			inWindow = append(inWindow, d)
+		}
+	}
+	status.DosesLast24h = len(inWindow)
+
+	var nextAllowed time.Time
+	var reasons []string
+
+	if med.MinIntervalMinutes != nil {
+		earliest := status.LastDose.GivenAt.Add(time.Duration(*med.MinIntervalMinutes) * time.Minute)
+		if earliest.After(at) {
+			status.MinIntervalHit = true
+			nextAllowed = earliest
+			reasons = append(reasons, fmt.Sprintf("minimum interval is %s", formatMinutes(*med.MinIntervalMinutes)))
+		}
+	}
+
+	if med.MaxDailyDoses != nil && len(inWindow) >= *med.MaxDailyDoses {
+		// Once enough of the oldest doses roll out of the window, the count drops below the max
+		earliest := inWindow[len(inWindow)-*med.MaxDailyDoses].GivenAt.Add(dailyWindow)
+		status.MaxDailyHit = true
+		if earliest.After(nextAllowed) {
+			nextAllowed = earliest
+		}
+		reasons = append(reasons, fmt.Sprintf("max %d doses per 24 hours", *med.MaxDailyDoses))
+	}
+
+	if !nextAllowed.IsZero() {
+		status.CanGiveNow = false
+		status.NextAllowedAt = &nextAllowed
+		status.Reason = strings.Join(reasons, "; ")
+	}
+
+	return status
+}
+
+func formatMinutes(minutes int) string {
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	if minutes > 60 {
+		return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
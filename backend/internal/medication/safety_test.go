@@ -0,0 +1,192 @@
+package medication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func intPtr(v int) *int { return &v }
+
+var now = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func dose(name string, ago time.Duration) *domain.MedicationDetails {
+	return &domain.MedicationDetails{
+		ID:         uuid.New(),
+		DrugName:   name,
+		DoseAmount: 2.5,
+		DoseUnit:   domain.DoseUnitMl,
+		Route:      domain.MedicationRouteOral,
+		GivenAt:    now.Add(-ago),
+	}
+}
+
+func acetaminophen() *domain.Medication {
+	return &domain.Medication{
+		ID:                 uuid.New(),
+		Name:               "Acetaminophen",
+		MinIntervalMinutes: intPtr(240),
+		MaxDailyDoses:      intPtr(5),
+	}
+}
+
+func TestCheck_NoDoses(t *testing.T) {
+	status := Check(acetaminophen(), nil, now)
+
+	if !status.CanGiveNow {
+		t.Error("expected CanGiveNow with no prior doses")
+	}
+	if status.LastDose != nil {
+		t.Errorf("LastDose = %v, want nil", status.LastDose)
+	}
+	if status.NextAllowedAt != nil {
+		t.Errorf("NextAllowedAt = %v, want nil", status.NextAllowedAt)
+	}
+}
+
+func TestCheck_WithinMinInterval(t *testing.T) {
+	doses := []*domain.MedicationDetails{dose("acetaminophen", 3*time.Hour)}
+
+	status := Check(acetaminophen(), doses, now)
+
+	if status.CanGiveNow {
+		t.Fatal("expected dose to be blocked 3h after last dose with 4h interval")
+	}
+	if !status.MinIntervalHit {
+		t.Error("expected MinIntervalHit")
+	}
+	want := now.Add(time.Hour)
+	if status.NextAllowedAt == nil || !status.NextAllowedAt.Equal(want) {
+		t.Errorf("NextAllowedAt = %v, want %v", status.NextAllowedAt, want)
+	}
+	if status.Reason == "" {
+		t.Error("expected a reason")
+	}
+}
+
+func TestCheck_AfterMinInterval(t *testing.T) {
+	doses := []*domain.MedicationDetails{dose("Acetaminophen", 5*time.Hour)}
+
+	status := Check(acetaminophen(), doses, now)
+
+	if !status.CanGiveNow {
+		t.Errorf("expected dose to be allowed, reason: %s", status.Reason)
+	}
+	if status.DosesLast24h != 1 {
+		t.Errorf("DosesLast24h = %d, want 1", status.DosesLast24h)
+	}
+	if status.LastDose == nil {
+		t.Error("expected LastDose to be set")
+	}
+}
+
+func TestCheck_MaxDailyDoses(t *testing.T) {
+	med := &domain.Medication{ID: uuid.New(), Name: "Ibuprofen", MaxDailyDoses: intPtr(3)}
+	doses := []*domain.MedicationDetails{
+		dose("ibuprofen", 20*time.Hour),
+		dose("ibuprofen", 14*time.Hour),
+		dose("ibuprofen", 8*time.Hour),
+	}
+
+	status := Check(med, doses, now)
+
+	if status.CanGiveNow {
+		t.Fatal("expected dose to be blocked after 3 doses in 24h")
+	}
+	if !status.MaxDailyHit {
+		t.Error("expected MaxDailyHit")
+	}
+	// Oldest dose (20h ago) rolls out of the window in 4h
+	want := now.Add(4 * time.Hour)
+	if status.NextAllowedAt == nil || !status.NextAllowedAt.Equal(want) {
+		t.Errorf("NextAllowedAt = %v, want %v", status.NextAllowedAt, want)
+	}
+}
+
+func TestCheck_DosesOutsideWindowNotCounted(t *testing.T) {
+	med := &domain.Medication{ID: uuid.New(), Name: "Ibuprofen", MaxDailyDoses: intPtr(2)}
+	doses := []*domain.MedicationDetails{
+		dose("ibuprofen", 30*time.Hour),
+		dose("ibuprofen", 26*time.Hour),
+		dose("ibuprofen", 6*time.Hour),
+	}
+
+	status := Check(med, doses, now)
+
+	if !status.CanGiveNow {
+		t.Errorf("expected dose to be allowed, reason: %s", status.Reason)
+	}
+	if status.DosesLast24h != 1 {
+		t.Errorf("DosesLast24h = %d, want 1", status.DosesLast24h)
+	}
+}
+
+func TestCheck_BothLimits_LaterTimeWins(t *testing.T) {
+	med := &domain.Medication{
+		ID:                 uuid.New(),
+		Name:               "Ibuprofen",
+		MinIntervalMinutes: intPtr(360),
+		MaxDailyDoses:      intPtr(2),
+	}
+	doses := []*domain.MedicationDetails{
+		dose("ibuprofen", 12*time.Hour),
+		dose("ibuprofen", 1*time.Hour),
+	}
+
+	status := Check(med, doses, now)
+
+	if !status.MinIntervalHit || !status.MaxDailyHit {
+		t.Fatalf("expected both limits hit, got interval=%v daily=%v", status.MinIntervalHit, status.MaxDailyHit)
+	}
+	// Interval clears in 5h, daily window clears in 12h
+	want := now.Add(12 * time.Hour)
+	if status.NextAllowedAt == nil || !status.NextAllowedAt.Equal(want) {
+		t.Errorf("NextAllowedAt = %v, want %v", status.NextAllowedAt, want)
+	}
+}
+
+func TestCheck_IgnoresOtherDrugsAndFutureDoses(t *testing.T) {
+	doses := []*domain.MedicationDetails{
+		dose("Vitamin D", 30*time.Minute),
+		dose("acetaminophen", -2*time.Hour), // logged for later
+	}
+
+	status := Check(acetaminophen(), doses, now)
+
+	if !status.CanGiveNow {
+		t.Errorf("expected dose to be allowed, reason: %s", status.Reason)
+	}
+	if status.LastDose != nil {
+		t.Errorf("LastDose = %v, want nil", status.LastDose)
+	}
+}
+
+func TestCheck_NoLimits(t *testing.T) {
+	med := &domain.Medication{ID: uuid.New(), Name: "Vitamin D"}
+	doses := []*domain.MedicationDetails{dose("vitamin d", 10*time.Minute)}
+
+	status := Check(med, doses, now)
+
+	if !status.CanGiveNow {
+		t.Error("expected no restriction for medication without limits")
+	}
+}
+
+func TestMatches_PrefersCatalogID(t *testing.T) {
+	med := acetaminophen()
+	otherID := uuid.New()
+
+	linked := dose("Acetaminophen", time.Hour)
+	linked.MedicationID = &otherID
+	if Matches(med, linked) {
+		t.Error("dose linked to a different catalog entry should not match by name")
+	}
+
+	linked.MedicationID = &med.ID
+	linked.DrugName = "Tylenol"
+	if !Matches(med, linked) {
+		t.Error("dose linked to this catalog entry should match regardless of name")
+	}
+}
@@ -2,13 +2,16 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/internal/auth"
+	"github.com/swatkatz/babybaton/backend/internal/devicetoken"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
 	"github.com/swatkatz/babybaton/backend/internal/store"
 )
@@ -17,106 +20,233 @@ type contextKey string
 
 const (
 	CaregiverIDKey   contextKey = "caregiverId"
+	CaregiverNameKey contextKey = "caregiverName"
 	FamilyIDKey      contextKey = "familyId"
+	RoleKey          contextKey = "role"
 	TimezoneKey      contextKey = "timezone"
 	UserIDKey        contextKey = "userId"
 	UserKey          contextKey = "user"
 	SupabaseIDKey    contextKey = "supabaseId"
 	SupabaseEmailKey contextKey = "supabaseEmail"
+
+	// DeviceCaregiverIDKey holds the caregiver a valid device token was issued to, even
+	// when a JWT decides the request's caregiver
+	DeviceCaregiverIDKey contextKey = "deviceCaregiverId"
 )
 
-// AuthMiddleware extracts caregiver and family IDs from headers (legacy device-based auth).
-// Use NewDualAuthMiddleware for combined JWT + header auth support.
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+// DeviceTokenHeader carries the signed token issued to device-based clients by
+// createFamily and joinFamily.
+const DeviceTokenHeader = "X-Device-Token"
+
+// errInvalidDeviceToken is returned for device tokens that are forged, revoked, rotated
+// out, or name a caregiver who is no longer in the family.
+var errInvalidDeviceToken = errors.New("invalid or revoked device token")
+
+// DeviceAuth authenticates device-based clients (no user account) from the signed token
+// in X-Device-Token, checking that its caregiver still belongs to its family and that
+// the token hasn't been rotated or revoked. Use NewDualAuthMiddleware for combined JWT +
+// device auth support.
+type DeviceAuth struct {
+	signer *devicetoken.Signer
+	store  store.Store
+
+	// allowUnsigned also accepts bare X-Caregiver-Id / X-Family-Id headers from clients
+	// that predate device tokens, as long as the caregiver belongs to the family.
+	allowUnsigned bool
+}
 
-		// Extract caregiver ID
-		caregiverIDStr := r.Header.Get("X-Caregiver-Id")
-		if caregiverIDStr != "" {
-			caregiverID, err := uuid.Parse(caregiverIDStr)
-			if err == nil {
-				ctx = context.WithValue(ctx, CaregiverIDKey, caregiverID)
-			}
+// NewDeviceAuth creates device auth verifying tokens with signer. allowUnsigned keeps
+// accepting the legacy ID headers while clients upgrade.
+func NewDeviceAuth(signer *devicetoken.Signer, store store.Store, allowUnsigned bool) *DeviceAuth {
+	return &DeviceAuth{
+		signer:        signer,
+		store:         store,
+		allowUnsigned: allowUnsigned,
+	}
+}
+
+// Handler returns HTTP middleware authenticating requests with device tokens only.
+// Requests with an invalid token get a 401 so the client knows to join again.
+func (d *DeviceAuth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := d.authenticate(r.Context(), r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
 		}
 
-		// Extract family ID
-		familyIDStr := r.Header.Get("X-Family-Id")
-		if familyIDStr != "" {
-			familyID, err := uuid.Parse(familyIDStr)
-			if err == nil {
-				ctx = context.WithValue(ctx, FamilyIDKey, familyID)
-			}
+		next.ServeHTTP(w, r.WithContext(withTimezone(ctx, r)))
+	})
+}
+
+// WebsocketInit is the device-only counterpart of DualAuthMiddleware.WebsocketInit,
+// reading X-Device-Token / X-Timezone from the connection_init payload.
+func (d *DeviceAuth) WebsocketInit(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	if _, ok := GetFamilyID(ctx); ok {
+		return ctx, &payload, nil
+	}
+
+	r := requestFromInitPayload(ctx, payload)
+	ctx, err := d.authenticate(ctx, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return withTimezone(ctx, r), &payload, nil
+}
+
+// authenticate sets the caregiver and family from the request's device token, or from
+// the legacy ID headers if unsigned auth is allowed. A request with neither passes
+// through unauthenticated; a forged, rotated or revoked token is an error.
+func (d *DeviceAuth) authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	if token := r.Header.Get(DeviceTokenHeader); token != "" {
+		claims, err := d.signer.Verify(token)
+		if err != nil {
+			return nil, errInvalidDeviceToken
 		}
 
-		// Extract timezone
-		timezone := r.Header.Get("X-Timezone")
-		if timezone == "" {
-			timezone = "UTC" // Default fallback
+		caregiver, err := d.store.GetCaregiverByID(ctx, claims.CaregiverID)
+		if err != nil {
+			// Left the family, or the store is unavailable; either way, no auth context
+			log.Printf("Device token caregiver lookup failed: %v", err)
+			return ctx, nil
 		}
-		ctx = context.WithValue(ctx, TimezoneKey, timezone)
+		if caregiver.FamilyID != claims.FamilyID || caregiver.TokenGeneration != claims.Generation {
+			return nil, errInvalidDeviceToken
+		}
+		ctx = context.WithValue(ctx, DeviceCaregiverIDKey, caregiver.ID)
+		return withCaregiver(ctx, caregiver), nil
+	}
 
-		// Pass modified context to next handler
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	if !d.allowUnsigned {
+		return ctx, nil
+	}
+
+	caregiverID, err := uuid.Parse(r.Header.Get("X-Caregiver-Id"))
+	if err != nil {
+		return ctx, nil
+	}
+	familyID, err := uuid.Parse(r.Header.Get("X-Family-Id"))
+	if err != nil {
+		return ctx, nil
+	}
+
+	// Unsigned headers are only trusted as far as the caregiver really being in the family
+	caregiver, err := d.store.GetCaregiverByID(ctx, caregiverID)
+	if err != nil || caregiver.FamilyID != familyID {
+		return ctx, nil
+	}
+	return withCaregiver(ctx, caregiver), nil
 }
 
-// DualAuthMiddleware supports both JWT Bearer token auth and legacy header-based auth.
+// withCaregiver stores the caregiver's ID, name, family ID and role in context
+func withCaregiver(ctx context.Context, caregiver *domain.Caregiver) context.Context {
+	ctx = context.WithValue(ctx, CaregiverIDKey, caregiver.ID)
+	ctx = context.WithValue(ctx, CaregiverNameKey, caregiver.Name)
+	ctx = context.WithValue(ctx, FamilyIDKey, caregiver.FamilyID)
+	return context.WithValue(ctx, RoleKey, caregiver.Role)
+}
+
+// DualAuthMiddleware supports both JWT Bearer token auth and device-based auth.
 type DualAuthMiddleware struct {
 	verifier auth.AuthVerifier
 	store    store.Store
+	device   *DeviceAuth
 }
 
 // NewDualAuthMiddleware creates middleware that checks auth in order:
-// 1. Authorization: Bearer <jwt> → verify via AuthVerifier, resolve user → caregiver + family
-// 2. X-Family-ID + X-Caregiver-ID headers → existing device-based path
+// 1. Authorization: Bearer <jwt> → verify via AuthVerifier, resolve user → caregiver + family.
+//    An X-Device-Token sent alongside is verified too, for linking its caregiver to the user.
+// 2. X-Device-Token (or legacy ID headers, if allowed) → device-based path via device
 // 3. Neither → unauthenticated (passes through with no auth context)
-func NewDualAuthMiddleware(verifier auth.AuthVerifier, store store.Store) *DualAuthMiddleware {
+func NewDualAuthMiddleware(verifier auth.AuthVerifier, store store.Store, device *DeviceAuth) *DualAuthMiddleware {
 	return &DualAuthMiddleware{
 		verifier: verifier,
 		store:    store,
+		device:   device,
 	}
 }
 
 // Handler returns the HTTP middleware handler.
 func (m *DualAuthMiddleware) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-
-		token := extractBearerToken(r)
-		if token != "" {
-			// JWT auth path
-			ctx = m.handleJWTAuth(ctx, w, r, token)
-			if ctx == nil {
-				// handleJWTAuth already wrote the error response
-				return
-			}
-		} else {
-			// Legacy header-based auth path
-			ctx = handleHeaderAuth(ctx, r)
+		ctx, err := m.authenticate(r.Context(), r)
+		if errors.Is(err, errInvalidDeviceToken) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
 		}
-
-		// Always extract timezone
-		timezone := r.Header.Get("X-Timezone")
-		if timezone == "" {
-			timezone = "UTC"
+		if err != nil {
+			log.Printf("JWT verification failed: %v", err)
+			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+			return
 		}
-		ctx = context.WithValue(ctx, TimezoneKey, timezone)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// WebsocketInit authenticates a GraphQL subscription from its connection_init payload.
+// Browsers and React Native cannot attach custom headers to the websocket upgrade
+// request, so clients send the same header values as payload keys instead. If the
+// upgrade request was already authenticated by Handler, the payload is ignored.
+func (m *DualAuthMiddleware) WebsocketInit(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	if _, ok := GetFamilyID(ctx); ok {
+		return ctx, &payload, nil
+	}
+
+	ctx, err := m.authenticate(ctx, requestFromInitPayload(ctx, payload))
+	if errors.Is(err, errInvalidDeviceToken) {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid or expired token")
+	}
+	return ctx, &payload, nil
+}
+
+// authenticate resolves the auth context for a request: JWT if a Bearer token is
+// present, otherwise device auth. Returns an error if a JWT or device token fails
+// verification.
+func (m *DualAuthMiddleware) authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	token := extractBearerToken(r)
+	if token != "" {
+		// JWT auth path
+		var err error
+		ctx, err = m.handleJWTAuth(ctx, r, token)
+		if err != nil {
+			return nil, err
+		}
+
+		// The JWT decides the caregiver; a device token only proves which one the device is
+		if r.Header.Get(DeviceTokenHeader) != "" {
+			deviceCtx, err := m.device.authenticate(ctx, r)
+			if err != nil {
+				return nil, err
+			}
+			if caregiverID, ok := GetDeviceCaregiverID(deviceCtx); ok {
+				ctx = context.WithValue(ctx, DeviceCaregiverIDKey, caregiverID)
+			}
+		}
+	} else {
+		// Device-based auth path
+		var err error
+		ctx, err = m.device.authenticate(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Always extract timezone
+	return withTimezone(ctx, r), nil
+}
+
 // handleJWTAuth verifies the JWT, looks up (or auto-creates) the user, and resolves
 // the family context. If X-Family-Id header is provided, uses that; otherwise
-// auto-selects if the user belongs to exactly one family. Returns nil context if
-// verification fails (and writes the HTTP error response).
-func (m *DualAuthMiddleware) handleJWTAuth(ctx context.Context, w http.ResponseWriter, r *http.Request, token string) context.Context {
+// auto-selects if the user belongs to exactly one family. Returns an error if
+// verification fails.
+func (m *DualAuthMiddleware) handleJWTAuth(ctx context.Context, r *http.Request, token string) (context.Context, error) {
 	supabaseID, email, err := m.verifier.VerifyToken(ctx, token)
 	if err != nil {
-		log.Printf("JWT verification failed: %v", err)
-		http.Error(w, "invalid or expired token", http.StatusUnauthorized)
-		return nil
+		return nil, err
 	}
 
 	// Always set verified Supabase identity in context
@@ -154,8 +284,7 @@ func (m *DualAuthMiddleware) handleJWTAuth(ctx context.Context, w http.ResponseW
 			if err == nil {
 				caregiver, err := m.store.GetCaregiverByUserAndFamily(ctx, user.ID, familyID)
 				if err == nil {
-					ctx = context.WithValue(ctx, CaregiverIDKey, caregiver.ID)
-					ctx = context.WithValue(ctx, FamilyIDKey, familyID)
+					ctx = withCaregiver(ctx, caregiver)
 				}
 			}
 		} else {
@@ -164,35 +293,41 @@ func (m *DualAuthMiddleware) handleJWTAuth(ctx context.Context, w http.ResponseW
 			if err == nil && len(families) == 1 {
 				caregiver, err := m.store.GetCaregiverByUserAndFamily(ctx, user.ID, families[0].ID)
 				if err == nil {
-					ctx = context.WithValue(ctx, CaregiverIDKey, caregiver.ID)
-					ctx = context.WithValue(ctx, FamilyIDKey, families[0].ID)
+					ctx = withCaregiver(ctx, caregiver)
 				}
 			}
 		}
 	}
 
-	return ctx
+	return ctx, nil
 }
 
-// handleHeaderAuth extracts caregiver and family IDs from legacy headers.
-func handleHeaderAuth(ctx context.Context, r *http.Request) context.Context {
-	caregiverIDStr := r.Header.Get("X-Caregiver-Id")
-	if caregiverIDStr != "" {
-		caregiverID, err := uuid.Parse(caregiverIDStr)
-		if err == nil {
-			ctx = context.WithValue(ctx, CaregiverIDKey, caregiverID)
-		}
+// withTimezone stores the X-Timezone header in context, defaulting to UTC.
+func withTimezone(ctx context.Context, r *http.Request) context.Context {
+	timezone := r.Header.Get("X-Timezone")
+	if timezone == "" {
+		timezone = "UTC" // Default fallback
 	}
+	return context.WithValue(ctx, TimezoneKey, timezone)
+}
 
-	familyIDStr := r.Header.Get("X-Family-Id")
-	if familyIDStr != "" {
-		familyID, err := uuid.Parse(familyIDStr)
-		if err == nil {
-			ctx = context.WithValue(ctx, FamilyIDKey, familyID)
+// initPayloadHeaders are the auth headers a websocket client may send in connection_init.
+var initPayloadHeaders = []string{"Authorization", DeviceTokenHeader, "X-Caregiver-Id", "X-Family-Id", "X-Timezone"}
+
+// requestFromInitPayload builds a synthetic request carrying the auth headers found in
+// a connection_init payload, so websocket auth can reuse the HTTP header parsing.
+func requestFromInitPayload(ctx context.Context, payload transport.InitPayload) *http.Request {
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	for _, name := range initPayloadHeaders {
+		value := payload.GetString(name)
+		if value == "" {
+			value = payload.GetString(strings.ToLower(name))
+		}
+		if value != "" {
+			r.Header.Set(name, value)
 		}
 	}
-
-	return ctx
+	return r
 }
 
 // extractBearerToken extracts the token from an "Authorization: Bearer <token>" header.
@@ -214,13 +349,33 @@ func GetCaregiverID(ctx context.Context) (uuid.UUID, bool) {
 	return caregiverID, ok
 }
 
+// GetDeviceCaregiverID extracts the caregiver a verified device token was issued to. Legacy
+// unsigned headers never set it.
+func GetDeviceCaregiverID(ctx context.Context) (uuid.UUID, bool) {
+	caregiverID, ok := ctx.Value(DeviceCaregiverIDKey).(uuid.UUID)
+	return caregiverID, ok
+}
+
+// GetCaregiverName extracts the caregiver's name from context
+func GetCaregiverName(ctx context.Context) string {
+	name, _ := ctx.Value(CaregiverNameKey).(string)
+	return name
+}
+
 // GetFamilyID extracts family ID from context
 func GetFamilyID(ctx context.Context) (uuid.UUID, bool) {
 	familyID, ok := ctx.Value(FamilyIDKey).(uuid.UUID)
 	return familyID, ok
 }
 
-// GetTimezone extracts timezone from context
+// GetRole extracts the caregiver's role from context
+func GetRole(ctx context.Context) (domain.CaregiverRole, bool) {
+	role, ok := ctx.Value(RoleKey).(domain.CaregiverRole)
+	return role, ok
+}
+
+// GetTimezone extracts the X-Timezone header from context. Families store their own
+// timezone, so this is only a fallback for those that haven't set one.
 func GetTimezone(ctx context.Context) string {
 	if tz, ok := ctx.Value(TimezoneKey).(string); ok {
 		return tz
@@ -266,3 +421,24 @@ func RequireAuth(ctx context.Context) (caregiverID, familyID uuid.UUID, err erro
 
 	return caregiverID, familyID, nil
 }
+
+// ErrForbidden is returned by RequirePermission when the caregiver's role does not grant
+// the permission.
+var ErrForbidden = errors.New("permission denied")
+
+// RequirePermission is RequireAuth for changes: it also returns an error unless the
+// caregiver's role grants permission. Roles are loaded per request, so a role change
+// takes effect on the caregiver's next request.
+func RequirePermission(ctx context.Context, permission domain.Permission) (caregiverID, familyID uuid.UUID, err error) {
+	caregiverID, familyID, err = RequireAuth(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	role, _ := GetRole(ctx)
+	if !role.Can(permission) {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("%w: role %q cannot %s", ErrForbidden, role, permission)
+	}
+
+	return caregiverID, familyID, nil
+}
@@ -2,14 +2,18 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/devicetoken"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
 )
 
 // --- Mock AuthVerifier ---
@@ -46,7 +50,7 @@ func (m *mockStore) GetCaregiverByUserAndFamily(ctx context.Context, userID uuid
 }
 
 // Stub out remaining Store interface methods (not used by middleware)
-func (m *mockStore) CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, caregiver *domain.Caregiver) error {
+func (m *mockStore) CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, baby *domain.Baby, caregiver *domain.Caregiver) error {
 	return nil
 }
 func (m *mockStore) GetFamilyByID(ctx context.Context, id uuid.UUID) (*domain.Family, error) {
@@ -63,6 +67,9 @@ func (m *mockStore) FamilyNameExists(ctx context.Context, name string) (bool, er
 func (m *mockStore) GetFamiliesByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Family, error) {
 	return m.families, m.familiesErr
 }
+func (m *mockStore) GetActiveFamilyIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	return nil, nil
+}
 func (m *mockStore) CreateUser(ctx context.Context, user *domain.User) error {
 	m.createdUser = user
 	return m.createUserErr
@@ -74,8 +81,20 @@ func (m *mockStore) CreateCaregiver(ctx context.Context, caregiver *domain.Careg
 	return nil
 }
 func (m *mockStore) GetCaregiverByID(ctx context.Context, id uuid.UUID) (*domain.Caregiver, error) {
+	if m.caregiver == nil || m.caregiver.ID != id {
+		return nil, fmt.Errorf("caregiver not found: %s", id)
+	}
+	return m.caregiver, nil
+}
+func (m *mockStore) GetCaregiversByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Caregiver, error) {
 	return nil, nil
 }
+func (m *mockStore) RotateDeviceToken(ctx context.Context, caregiverID uuid.UUID) (int, error) {
+	return 0, nil
+}
+func (m *mockStore) SetCaregiverRole(ctx context.Context, caregiverID uuid.UUID, role domain.CaregiverRole) error {
+	return nil
+}
 func (m *mockStore) GetCaregiverByDeviceID(ctx context.Context, deviceID string) (*domain.Caregiver, error) {
 	return nil, nil
 }
@@ -108,26 +127,49 @@ func (m *mockStore) UpdateCareSession(ctx context.Context, session *domain.CareS
 	return nil
 }
 func (m *mockStore) DeleteCareSession(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *mockStore) RestoreCareSession(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *mockStore) GetRecentlyDeletedCareSessionsForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.CareSession, error) {
+	return nil, nil
+}
 func (m *mockStore) CreateActivity(ctx context.Context, activity *domain.Activity) error {
 	return nil
 }
+func (m *mockStore) CreateBaby(ctx context.Context, baby *domain.Baby) error { return nil }
+func (m *mockStore) GetBabyByID(ctx context.Context, id uuid.UUID) (*domain.Baby, error) {
+	return nil, nil
+}
+func (m *mockStore) GetBabiesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Baby, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateBaby(ctx context.Context, baby *domain.Baby) error { return nil }
 func (m *mockStore) GetActivityByID(ctx context.Context, id uuid.UUID) (*domain.Activity, error) {
 	return nil, nil
 }
 func (m *mockStore) GetActivitiesForSession(ctx context.Context, sessionID uuid.UUID) ([]*domain.Activity, error) {
 	return nil, nil
 }
-func (m *mockStore) GetLatestActivityByTypeForFamily(ctx context.Context, familyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
+func (m *mockStore) GetActivitiesForSessions(ctx context.Context, sessionIDs []uuid.UUID) ([]*domain.Activity, error) {
+	return nil, nil
+}
+func (m *mockStore) GetLatestActivityByTypeForBaby(ctx context.Context, babyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
 	return nil, nil
 }
 func (m *mockStore) DeleteActivity(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *mockStore) RestoreActivity(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *mockStore) GetRecentlyDeletedActivitiesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	return nil, nil
+}
+func (m *mockStore) PurgeDeleted(ctx context.Context, deletedBefore time.Time) error { return nil }
 func (m *mockStore) CreateFeedDetails(ctx context.Context, details *domain.FeedDetails) error {
 	return nil
 }
 func (m *mockStore) GetFeedDetails(ctx context.Context, activityID uuid.UUID) (*domain.FeedDetails, error) {
 	return nil, nil
 }
-func (m *mockStore) GetRecentFeedDetailsForFamily(ctx context.Context, familyID uuid.UUID, limit int) ([]*domain.FeedDetails, error) {
+func (m *mockStore) GetFeedDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.FeedDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) GetRecentFeedDetailsForBaby(ctx context.Context, babyID uuid.UUID, limit int) ([]*domain.FeedDetails, error) {
 	return nil, nil
 }
 func (m *mockStore) UpdateFeedDetails(ctx context.Context, details *domain.FeedDetails) error {
@@ -139,6 +181,9 @@ func (m *mockStore) CreateDiaperDetails(ctx context.Context, details *domain.Dia
 func (m *mockStore) GetDiaperDetails(ctx context.Context, activityID uuid.UUID) (*domain.DiaperDetails, error) {
 	return nil, nil
 }
+func (m *mockStore) GetDiaperDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.DiaperDetails, error) {
+	return nil, nil
+}
 func (m *mockStore) UpdateDiaperDetails(ctx context.Context, details *domain.DiaperDetails) error {
 	return nil
 }
@@ -148,38 +193,193 @@ func (m *mockStore) CreateSleepDetails(ctx context.Context, details *domain.Slee
 func (m *mockStore) GetSleepDetails(ctx context.Context, activityID uuid.UUID) (*domain.SleepDetails, error) {
 	return nil, nil
 }
-func (m *mockStore) GetRecentSleepDetailsForFamily(ctx context.Context, familyID uuid.UUID, limit int) ([]*domain.SleepDetails, error) {
+func (m *mockStore) GetSleepDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.SleepDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) GetRecentSleepDetailsForBaby(ctx context.Context, babyID uuid.UUID, limit int) ([]*domain.SleepDetails, error) {
 	return nil, nil
 }
 func (m *mockStore) UpdateSleepDetails(ctx context.Context, details *domain.SleepDetails) error {
 	return nil
 }
-func (m *mockStore) UpsertPredictions(ctx context.Context, familyID uuid.UUID, predictions []*domain.Prediction) error {
+func (m *mockStore) CreatePumpDetails(ctx context.Context, details *domain.PumpDetails) error {
+	return nil
+}
+func (m *mockStore) GetPumpDetails(ctx context.Context, activityID uuid.UUID) (*domain.PumpDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) GetPumpDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.PumpDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdatePumpDetails(ctx context.Context, details *domain.PumpDetails) error {
+	return nil
+}
+func (m *mockStore) CreateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error {
+	return nil
+}
+func (m *mockStore) GetMedicationDetails(ctx context.Context, activityID uuid.UUID) (*domain.MedicationDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) GetMedicationDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.MedicationDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) GetRecentMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.MedicationDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) GetLatestMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.MedicationDetails, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error {
+	return nil
+}
+func (m *mockStore) UpsertMedication(ctx context.Context, medication *domain.Medication) (*domain.Medication, error) {
+	return medication, nil
+}
+func (m *mockStore) GetMedicationByID(ctx context.Context, id uuid.UUID) (*domain.Medication, error) {
+	return nil, nil
+}
+func (m *mockStore) GetMedicationByName(ctx context.Context, familyID uuid.UUID, name string) (*domain.Medication, error) {
+	return nil, nil
+}
+func (m *mockStore) GetMedicationsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Medication, error) {
+	return nil, nil
+}
+func (m *mockStore) DeleteMedication(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *mockStore) CreateGrowthMeasurement(ctx context.Context, measurement *domain.GrowthMeasurement) error {
+	return nil
+}
+func (m *mockStore) GetGrowthMeasurementsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.GrowthMeasurement, error) {
+	return nil, nil
+}
+func (m *mockStore) UpsertPredictions(ctx context.Context, babyID uuid.UUID, predictions []*domain.Prediction) error {
 	return nil
 }
-func (m *mockStore) GetPredictionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Prediction, error) {
+func (m *mockStore) GetPredictionsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.Prediction, error) {
 	return nil, nil
 }
 func (m *mockStore) DismissPrediction(ctx context.Context, id uuid.UUID) error { return nil }
-func (m *mockStore) DeletePredictionsForFamily(ctx context.Context, familyID uuid.UUID) error {
+func (m *mockStore) DeletePredictionsForBaby(ctx context.Context, babyID uuid.UUID) error {
 	return nil
 }
 func (m *mockStore) CleanupOldPredictions(ctx context.Context, olderThan time.Time) error {
 	return nil
 }
-func (m *mockStore) GetScheduleGoals(ctx context.Context, familyID uuid.UUID) (*domain.ScheduleGoals, error) {
+func (m *mockStore) CreatePredictionOutcome(ctx context.Context, outcome *domain.PredictionOutcome) error {
+	return nil
+}
+func (m *mockStore) GetPredictionOutcomesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
 	return nil, nil
 }
-func (m *mockStore) UpsertScheduleGoals(ctx context.Context, familyID uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error) {
+func (m *mockStore) GetPredictionOutcomesForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return nil, nil
+}
+func (m *mockStore) GetScheduleGoals(ctx context.Context, babyID uuid.UUID) (*domain.ScheduleGoals, error) {
+	return nil, nil
+}
+func (m *mockStore) UpsertScheduleGoals(ctx context.Context, babyID uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error) {
 	return goals, nil
 }
+func (m *mockStore) GetReminderPreferences(ctx context.Context, caregiverID uuid.UUID) (*domain.ReminderPreferences, error) {
+	return nil, nil
+}
+func (m *mockStore) GetReminderPreferencesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.ReminderPreferences, error) {
+	return nil, nil
+}
+func (m *mockStore) UpsertReminderPreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	return prefs, nil
+}
+func (m *mockStore) CreateFamilyInvite(ctx context.Context, invite *domain.FamilyInvite) error {
+	return nil
+}
+func (m *mockStore) GetFamilyInviteByID(ctx context.Context, id uuid.UUID) (*domain.FamilyInvite, error) {
+	return nil, nil
+}
+func (m *mockStore) GetFamilyInviteByCodeHash(ctx context.Context, codeHash string) (*domain.FamilyInvite, error) {
+	return nil, nil
+}
+func (m *mockStore) GetFamilyInvitesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.FamilyInvite, error) {
+	return nil, nil
+}
+func (m *mockStore) RedeemFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	return false, nil
+}
+func (m *mockStore) RevokeFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return nil
+}
+func (m *mockStore) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	return nil
+}
+func (m *mockStore) GetWebhookSubscriptionByID(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	return nil, nil
+}
+func (m *mockStore) GetWebhookSubscriptionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	return nil, nil
+}
+func (m *mockStore) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+func (m *mockStore) CreateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	return nil
+}
+func (m *mockStore) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	return nil
+}
+func (m *mockStore) GetWebhookDeliveriesForFamily(ctx context.Context, familyID uuid.UUID, status *domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+func (m *mockStore) CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	return nil
+}
+func (m *mockStore) GetAuditEventsForFamily(ctx context.Context, familyID uuid.UUID, filter domain.AuditFilter, limit int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.AuditEvent, error) {
+	return nil, nil
+}
+func (m *mockStore) TouchActivity(ctx context.Context, id uuid.UUID, expectedVersion *int) (int, error) {
+	return 0, nil
+}
+func (m *mockStore) GetActivitiesUpdatedSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	return nil, nil
+}
+func (m *mockStore) GetIdempotencyRecord(ctx context.Context, familyID uuid.UUID, key string) (*domain.IdempotencyRecord, error) {
+	return nil, nil
+}
+func (m *mockStore) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return nil
+}
+func (m *mockStore) RecordDeletedActivity(ctx context.Context, deleted *domain.DeletedActivity) error {
+	return nil
+}
+func (m *mockStore) GetDeletedActivity(ctx context.Context, activityID uuid.UUID) (*domain.DeletedActivity, error) {
+	return nil, nil
+}
+func (m *mockStore) ClearDeletedActivity(ctx context.Context, activityID uuid.UUID) error {
+	return nil
+}
+func (m *mockStore) GetDeletedActivitiesSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.DeletedActivity, error) {
+	return nil, nil
+}
+func (m *mockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
 func (m *mockStore) Close() error { return nil }
 
-// ==================== Legacy AuthMiddleware Tests ====================
+// ==================== DeviceAuth Tests ====================
 
-func TestAuthMiddleware_ParsesAllHeaders(t *testing.T) {
-	caregiverID := uuid.New()
-	familyID := uuid.New()
+var testSigner = devicetoken.NewSigner([]byte("test-secret"))
+
+func newTestDeviceAuth(store *mockStore, allowUnsigned bool) *DeviceAuth {
+	return NewDeviceAuth(testSigner, store, allowUnsigned)
+}
+
+// deviceToken issues a token for the caregiver at its current generation
+func deviceToken(caregiver *domain.Caregiver) string {
+	return testSigner.Issue(devicetoken.Claims{CaregiverID: caregiver.ID, FamilyID: caregiver.FamilyID, Generation: caregiver.TokenGeneration})
+}
+
+func TestDeviceAuth_ValidToken(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New(), TokenGeneration: 2, Role: domain.RoleParent}
 	timezone := "America/New_York"
 
 	var capturedCtx context.Context
@@ -188,27 +388,24 @@ func TestAuthMiddleware_ParsesAllHeaders(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("POST", "/query", nil)
-	req.Header.Set("X-Caregiver-Id", caregiverID.String())
-	req.Header.Set("X-Family-Id", familyID.String())
+	req.Header.Set(DeviceTokenHeader, deviceToken(caregiver))
 	req.Header.Set("X-Timezone", timezone)
 
 	rr := httptest.NewRecorder()
-	AuthMiddleware(inner).ServeHTTP(rr, req)
+	newTestDeviceAuth(&mockStore{caregiver: caregiver}, false).Handler(inner).ServeHTTP(rr, req)
 
-	gotCaregiverID, ok := GetCaregiverID(capturedCtx)
-	if !ok {
-		t.Fatal("expected caregiver ID in context")
+	gotCaregiverID, gotFamilyID, err := RequireAuth(capturedCtx)
+	if err != nil {
+		t.Fatalf("expected auth context, got %v", err)
 	}
-	if gotCaregiverID != caregiverID {
-		t.Errorf("caregiver ID = %v, want %v", gotCaregiverID, caregiverID)
+	if gotCaregiverID != caregiver.ID {
+		t.Errorf("caregiver ID = %v, want %v", gotCaregiverID, caregiver.ID)
 	}
-
-	gotFamilyID, ok := GetFamilyID(capturedCtx)
-	if !ok {
-		t.Fatal("expected family ID in context")
+	if gotFamilyID != caregiver.FamilyID {
+		t.Errorf("family ID = %v, want %v", gotFamilyID, caregiver.FamilyID)
 	}
-	if gotFamilyID != familyID {
-		t.Errorf("family ID = %v, want %v", gotFamilyID, familyID)
+	if role, _ := GetRole(capturedCtx); role != domain.RoleParent {
+		t.Errorf("role = %q, want %q", role, domain.RoleParent)
 	}
 
 	gotTz := GetTimezone(capturedCtx)
@@ -217,7 +414,7 @@ func TestAuthMiddleware_ParsesAllHeaders(t *testing.T) {
 	}
 }
 
-func TestAuthMiddleware_MissingHeaders(t *testing.T) {
+func TestDeviceAuth_MissingToken(t *testing.T) {
 	var capturedCtx context.Context
 	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		capturedCtx = r.Context()
@@ -225,7 +422,7 @@ func TestAuthMiddleware_MissingHeaders(t *testing.T) {
 
 	req := httptest.NewRequest("POST", "/query", nil)
 	rr := httptest.NewRecorder()
-	AuthMiddleware(inner).ServeHTTP(rr, req)
+	newTestDeviceAuth(&mockStore{}, false).Handler(inner).ServeHTTP(rr, req)
 
 	_, ok := GetCaregiverID(capturedCtx)
 	if ok {
@@ -238,7 +435,7 @@ func TestAuthMiddleware_MissingHeaders(t *testing.T) {
 	}
 }
 
-func TestAuthMiddleware_DefaultTimezoneUTC(t *testing.T) {
+func TestDeviceAuth_DefaultTimezoneUTC(t *testing.T) {
 	var capturedCtx context.Context
 	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		capturedCtx = r.Context()
@@ -246,7 +443,7 @@ func TestAuthMiddleware_DefaultTimezoneUTC(t *testing.T) {
 
 	req := httptest.NewRequest("POST", "/query", nil)
 	rr := httptest.NewRecorder()
-	AuthMiddleware(inner).ServeHTTP(rr, req)
+	newTestDeviceAuth(&mockStore{}, false).Handler(inner).ServeHTTP(rr, req)
 
 	gotTz := GetTimezone(capturedCtx)
 	if gotTz != "UTC" {
@@ -254,27 +451,96 @@ func TestAuthMiddleware_DefaultTimezoneUTC(t *testing.T) {
 	}
 }
 
-func TestAuthMiddleware_InvalidUUIDs(t *testing.T) {
+func TestDeviceAuth_RejectsBadTokens(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New(), TokenGeneration: 2}
+
+	rotated := *caregiver
+	rotated.TokenGeneration = 1
+	otherFamily := *caregiver
+	otherFamily.FamilyID = uuid.New()
+
+	tests := map[string]string{
+		"forged":       devicetoken.NewSigner([]byte("other-secret")).Issue(devicetoken.Claims{CaregiverID: caregiver.ID, FamilyID: caregiver.FamilyID, Generation: 2}),
+		"rotated out":  deviceToken(&rotated),
+		"other family": deviceToken(&otherFamily),
+		"garbage":      "not-a-token",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			handlerCalled := false
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerCalled = true
+			})
+
+			req := httptest.NewRequest("POST", "/query", nil)
+			req.Header.Set(DeviceTokenHeader, token)
+
+			rr := httptest.NewRecorder()
+			newTestDeviceAuth(&mockStore{caregiver: caregiver}, false).Handler(inner).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected 401, got %d", rr.Code)
+			}
+			if handlerCalled {
+				t.Error("handler should not be called for a bad token")
+			}
+		})
+	}
+}
+
+func TestDeviceAuth_IgnoresUnsignedHeaders(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New()}
+
 	var capturedCtx context.Context
 	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		capturedCtx = r.Context()
 	})
 
 	req := httptest.NewRequest("POST", "/query", nil)
-	req.Header.Set("X-Caregiver-Id", "not-a-uuid")
-	req.Header.Set("X-Family-Id", "also-not-a-uuid")
+	req.Header.Set("X-Caregiver-Id", caregiver.ID.String())
+	req.Header.Set("X-Family-Id", caregiver.FamilyID.String())
 
 	rr := httptest.NewRecorder()
-	AuthMiddleware(inner).ServeHTTP(rr, req)
+	newTestDeviceAuth(&mockStore{caregiver: caregiver}, false).Handler(inner).ServeHTTP(rr, req)
 
-	_, ok := GetCaregiverID(capturedCtx)
-	if ok {
-		t.Error("expected no caregiver ID for invalid UUID")
+	if _, _, err := RequireAuth(capturedCtx); err == nil {
+		t.Error("expected bare ID headers to be ignored without unsigned auth")
 	}
+}
 
-	_, ok = GetFamilyID(capturedCtx)
-	if ok {
-		t.Error("expected no family ID for invalid UUID")
+func TestDeviceAuth_UnsignedHeadersRequireMembership(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New()}
+
+	tests := []struct {
+		name        string
+		caregiverID string
+		familyID    string
+		wantAuth    bool
+	}{
+		{"member", caregiver.ID.String(), caregiver.FamilyID.String(), true},
+		{"other family", caregiver.ID.String(), uuid.New().String(), false},
+		{"unknown caregiver", uuid.New().String(), caregiver.FamilyID.String(), false},
+		{"invalid UUIDs", "not-a-uuid", "also-not-a-uuid", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedCtx context.Context
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedCtx = r.Context()
+			})
+
+			req := httptest.NewRequest("POST", "/query", nil)
+			req.Header.Set("X-Caregiver-Id", tt.caregiverID)
+			req.Header.Set("X-Family-Id", tt.familyID)
+
+			rr := httptest.NewRecorder()
+			newTestDeviceAuth(&mockStore{caregiver: caregiver}, true).Handler(inner).ServeHTTP(rr, req)
+
+			_, _, err := RequireAuth(capturedCtx)
+			if (err == nil) != tt.wantAuth {
+				t.Errorf("authenticated = %v, want %v", err == nil, tt.wantAuth)
+			}
+		})
 	}
 }
 
@@ -329,6 +595,35 @@ func TestRequireAuth_EmptyContext(t *testing.T) {
 	}
 }
 
+func TestRequirePermission(t *testing.T) {
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, CaregiverIDKey, caregiverID)
+	ctx = context.WithValue(ctx, FamilyIDKey, familyID)
+
+	if _, _, err := RequirePermission(ctx, domain.PermissionLogCare); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden without a role, got %v", err)
+	}
+
+	caregiverCtx := context.WithValue(ctx, RoleKey, domain.RoleCaregiver)
+	gotCaregiver, gotFamily, err := RequirePermission(caregiverCtx, domain.PermissionLogCare)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotCaregiver != caregiverID || gotFamily != familyID {
+		t.Errorf("got (%v, %v), want (%v, %v)", gotCaregiver, gotFamily, caregiverID, familyID)
+	}
+	if _, _, err := RequirePermission(caregiverCtx, domain.PermissionDeleteHistory); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+
+	if _, _, err := RequirePermission(context.Background(), domain.PermissionLogCare); err == nil || errors.Is(err, ErrForbidden) {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+}
+
 func TestGetTimezone_FallbackForEmptyContext(t *testing.T) {
 	ctx := context.Background()
 	tz := GetTimezone(ctx)
@@ -340,7 +635,7 @@ func TestGetTimezone_FallbackForEmptyContext(t *testing.T) {
 // ==================== DualAuthMiddleware Tests ====================
 
 func newTestDualAuth(verifier *mockVerifier, store *mockStore) *DualAuthMiddleware {
-	return NewDualAuthMiddleware(verifier, store)
+	return NewDualAuthMiddleware(verifier, store, newTestDeviceAuth(store, false))
 }
 
 func TestDualAuth_ValidJWT_ResolvesUserAndCaregiver(t *testing.T) {
@@ -726,13 +1021,14 @@ func TestDualAuth_ValidJWT_UserAutoCreateFails_PassesThroughWithSupabaseIdentity
 	}
 }
 
-func TestDualAuth_FallsBackToHeaders_WhenNoBearer(t *testing.T) {
+func TestDualAuth_FallsBackToDeviceToken_WhenNoBearer(t *testing.T) {
 	caregiverID := uuid.New()
 	familyID := uuid.New()
+	caregiver := &domain.Caregiver{ID: caregiverID, FamilyID: familyID}
 
 	m := newTestDualAuth(
 		&mockVerifier{},
-		&mockStore{},
+		&mockStore{caregiver: caregiver},
 	)
 
 	var capturedCtx context.Context
@@ -741,8 +1037,7 @@ func TestDualAuth_FallsBackToHeaders_WhenNoBearer(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("POST", "/query", nil)
-	req.Header.Set("X-Caregiver-Id", caregiverID.String())
-	req.Header.Set("X-Family-Id", familyID.String())
+	req.Header.Set(DeviceTokenHeader, deviceToken(caregiver))
 	req.Header.Set("X-Timezone", "Europe/London")
 
 	rr := httptest.NewRecorder()
@@ -768,10 +1063,10 @@ func TestDualAuth_FallsBackToHeaders_WhenNoBearer(t *testing.T) {
 		t.Errorf("family ID = %v, want %v", gotFamilyID, familyID)
 	}
 
-	// No user context on header-based auth
+	// No user context on device-based auth
 	_, ok = GetUserID(capturedCtx)
 	if ok {
-		t.Error("expected no user ID on header-based auth")
+		t.Error("expected no user ID on device-based auth")
 	}
 
 	gotTz := GetTimezone(capturedCtx)
@@ -780,6 +1075,67 @@ func TestDualAuth_FallsBackToHeaders_WhenNoBearer(t *testing.T) {
 	}
 }
 
+func TestDualAuth_ValidJWT_WithDeviceToken_SetsDeviceCaregiver(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), SupabaseUserID: "sup-123"}
+	// Not linked to the user yet, so the JWT resolves no family
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New()}
+
+	m := newTestDualAuth(
+		&mockVerifier{userID: "sup-123"},
+		&mockStore{user: user, caregiver: caregiver},
+	)
+
+	var capturedCtx context.Context
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedCtx = r.Context()
+	})
+
+	req := httptest.NewRequest("POST", "/query", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set(DeviceTokenHeader, deviceToken(caregiver))
+
+	rr := httptest.NewRecorder()
+	m.Handler(inner).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if gotUserID, _ := GetUserID(capturedCtx); gotUserID != user.ID {
+		t.Errorf("user ID = %v, want %v", gotUserID, user.ID)
+	}
+	if gotID, ok := GetDeviceCaregiverID(capturedCtx); !ok || gotID != caregiver.ID {
+		t.Errorf("device caregiver ID = %v, %v, want %v", gotID, ok, caregiver.ID)
+	}
+	// The JWT alone decides the request's caregiver
+	if _, ok := GetCaregiverID(capturedCtx); ok {
+		t.Error("expected no caregiver context from the device token")
+	}
+}
+
+func TestDualAuth_ValidJWT_WithBadDeviceToken_Returns401(t *testing.T) {
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: uuid.New(), TokenGeneration: 2}
+	stale := *caregiver
+	stale.TokenGeneration = 1
+
+	m := newTestDualAuth(
+		&mockVerifier{userID: "sup-123"},
+		&mockStore{user: &domain.User{ID: uuid.New()}, caregiver: caregiver},
+	)
+
+	req := httptest.NewRequest("POST", "/query", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set(DeviceTokenHeader, deviceToken(&stale))
+
+	rr := httptest.NewRecorder()
+	m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	})).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rr.Code)
+	}
+}
+
 func TestDualAuth_NoAuth_PassesThrough(t *testing.T) {
 	m := newTestDualAuth(
 		&mockVerifier{},
@@ -820,6 +1176,107 @@ func TestDualAuth_NoAuth_PassesThrough(t *testing.T) {
 
 // ==================== extractBearerToken Tests ====================
 
+// ==================== Websocket Init Tests ====================
+
+func TestWebsocketInit_ReadsTokenFromPayload(t *testing.T) {
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+	caregiver := &domain.Caregiver{ID: caregiverID, FamilyID: familyID}
+
+	payload := transport.InitPayload{
+		DeviceTokenHeader: deviceToken(caregiver),
+		"X-Timezone":      "America/Toronto",
+	}
+
+	ctx, _, err := newTestDeviceAuth(&mockStore{caregiver: caregiver}, false).WebsocketInit(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gotCaregiverID, gotFamilyID, err := RequireAuth(ctx)
+	if err != nil {
+		t.Fatalf("expected auth context, got %v", err)
+	}
+	if gotCaregiverID != caregiverID {
+		t.Errorf("caregiver ID = %v, want %v", gotCaregiverID, caregiverID)
+	}
+	if gotFamilyID != familyID {
+		t.Errorf("family ID = %v, want %v", gotFamilyID, familyID)
+	}
+	if tz := GetTimezone(ctx); tz != "America/Toronto" {
+		t.Errorf("timezone = %q, want %q", tz, "America/Toronto")
+	}
+}
+
+func TestWebsocketInit_RejectsBadToken(t *testing.T) {
+	payload := transport.InitPayload{DeviceTokenHeader: "not-a-token"}
+
+	if _, _, err := newTestDeviceAuth(&mockStore{}, false).WebsocketInit(context.Background(), payload); err == nil {
+		t.Fatal("expected error for a bad device token")
+	}
+}
+
+func TestWebsocketInit_KeepsUpgradeRequestAuth(t *testing.T) {
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+	ctx := context.WithValue(context.Background(), CaregiverIDKey, caregiverID)
+	ctx = context.WithValue(ctx, FamilyIDKey, familyID)
+
+	payload := transport.InitPayload{DeviceTokenHeader: "not-a-token"}
+
+	ctx, _, err := newTestDeviceAuth(&mockStore{}, false).WebsocketInit(ctx, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := GetFamilyID(ctx); got != familyID {
+		t.Errorf("family ID = %v, want upgrade request value %v", got, familyID)
+	}
+}
+
+func TestDualAuth_WebsocketInit_ValidJWT(t *testing.T) {
+	userID := uuid.New()
+	caregiverID := uuid.New()
+	familyID := uuid.New()
+
+	m := newTestDualAuth(
+		&mockVerifier{userID: "sup-123", email: "test@example.com"},
+		&mockStore{
+			user:      &domain.User{ID: userID, SupabaseUserID: "sup-123"},
+			caregiver: &domain.Caregiver{ID: caregiverID, FamilyID: familyID, UserID: &userID},
+		},
+	)
+
+	payload := transport.InitPayload{
+		"authorization": "Bearer valid-token",
+		"X-Family-Id":   familyID.String(),
+	}
+
+	ctx, _, err := m.WebsocketInit(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gotCaregiverID, gotFamilyID, err := RequireAuth(ctx)
+	if err != nil {
+		t.Fatalf("expected auth context, got %v", err)
+	}
+	if gotCaregiverID != caregiverID || gotFamilyID != familyID {
+		t.Errorf("got caregiver %v family %v, want %v %v", gotCaregiverID, gotFamilyID, caregiverID, familyID)
+	}
+}
+
+func TestDualAuth_WebsocketInit_InvalidJWT(t *testing.T) {
+	m := newTestDualAuth(
+		&mockVerifier{err: fmt.Errorf("token expired")},
+		&mockStore{},
+	)
+
+	payload := transport.InitPayload{"Authorization": "Bearer expired-token"}
+
+	if _, _, err := m.WebsocketInit(context.Background(), payload); err == nil {
+		t.Fatal("expected error for invalid JWT")
+	}
+}
+
 func TestExtractBearerToken(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,291 @@
+// Package backtest replays a baby's history through the prediction engine at simulated
+// points in time and scores each prediction against what actually happened next, so
+// changes to the engine's constants can be judged on real data.
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
+)
+
+const (
+	// DefaultStep is the time between simulated points when Options.Step is zero
+	DefaultStep = 30 * time.Minute
+	// warmup is how long after the first record a run starts when Options.From is zero,
+	// so the engine has some history to work with
+	warmup = 24 * time.Hour
+)
+
+// Options controls a backtest run
+type Options struct {
+	// From and To bound the simulated points. Zero values cover the whole history, after
+	// a day of warm-up.
+	From, To time.Time
+	// Step is the time between simulated points
+	Step time.Duration
+	// Timezone is the family's IANA timezone, as passed to the engine
+	Timezone string
+	// Predictor estimates typical intervals; nil uses prediction.MedianPredictor
+	Predictor prediction.Predictor
+}
+
+// Sample is one prediction scored against the event it predicted
+type Sample struct {
+	Now            time.Time
+	PredictionType domain.PredictionType
+	Confidence     *domain.PredictionConfidence
+	PredictedTime  time.Time
+	ActualTime     time.Time
+}
+
+// Offset is how far the prediction was from what happened; positive means it was late
+func (s Sample) Offset() time.Duration {
+	return s.PredictedTime.Sub(s.ActualTime)
+}
+
+// Result holds every scored prediction of a run
+type Result struct {
+	// Points is how many simulated points were replayed
+	Points  int
+	Samples []Sample
+	// Unresolved counts predictions that couldn't be scored: the history ends before the
+	// predicted event, or something else happened instead, like bedtime instead of a nap
+	Unresolved map[domain.PredictionType]int
+}
+
+// Run replays feeds and sleeps through prediction.GeneratePredictionsWith at every step between
+// opts.From and opts.To. At each point the engine sees only what had been logged by then,
+// as Refresh would have loaded it: the most recent prediction.RecentRecordLimit of each,
+// with sleeps still in progress left open.
+//
+// Only upcoming predictions for the next feed, nap, wake and bedtime are scored. Overdue
+// predictions and the planned ones chained after them aren't forecasts to score.
+func Run(feeds []prediction.FeedRecord, sleeps []prediction.SleepRecord, opts Options) *Result {
+	feeds = slices.Clone(feeds)
+	sleeps = slices.Clone(sleeps)
+	slices.SortFunc(feeds, func(a, b prediction.FeedRecord) int { return a.StartTime.Compare(b.StartTime) })
+	slices.SortFunc(sleeps, func(a, b prediction.SleepRecord) int { return a.StartTime.Compare(b.StartTime) })
+
+	from, to := opts.From, opts.To
+	if from.IsZero() {
+		from = firstRecord(feeds, sleeps).Add(warmup)
+	}
+	if to.IsZero() {
+		to = lastRecord(feeds, sleeps)
+	}
+	step := opts.Step
+	if step <= 0 {
+		step = DefaultStep
+	}
+	predictor := opts.Predictor
+	if predictor == nil {
+		predictor = prediction.MedianPredictor{}
+	}
+
+	result := &Result{Unresolved: make(map[domain.PredictionType]int)}
+	for now := from; !now.After(to); now = now.Add(step) {
+		result.Points++
+
+		predictions := prediction.GeneratePredictionsWith(predictor, now, feedsAt(feeds, now), sleepsAt(sleeps, now), opts.Timezone)
+		for _, p := range predictions {
+			if p.Status != domain.PredictionStatusUpcoming || !slices.Contains(prediction.OutcomeTypes, p.PredictionType) {
+				continue
+			}
+
+			actual, ok := outcome(p.PredictionType, feeds, sleeps, now)
+			if !ok {
+				result.Unresolved[p.PredictionType]++
+				continue
+			}
+			result.Samples = append(result.Samples, Sample{
+				Now:            now,
+				PredictionType: p.PredictionType,
+				Confidence:     p.Confidence,
+				PredictedTime:  p.PredictedTime,
+				ActualTime:     actual,
+			})
+		}
+	}
+
+	return result
+}
+
+// feedsAt returns the feeds started by now, most recent first, with feeds still in
+// progress left open
+func feedsAt(feeds []prediction.FeedRecord, now time.Time) []prediction.FeedRecord {
+	n := sort.Search(len(feeds), func(i int) bool { return feeds[i].StartTime.After(now) })
+	known := make([]prediction.FeedRecord, 0, min(n, prediction.RecentRecordLimit))
+	for i := n - 1; i >= 0 && len(known) < prediction.RecentRecordLimit; i-- {
+		f := feeds[i]
+		if f.EndTime != nil && f.EndTime.After(now) {
+			f.EndTime = nil
+		}
+		known = append(known, f)
+	}
+	return known
+}
+
+// sleepsAt returns the sleeps started by now, most recent first, with sleeps still in
+// progress left open
+func sleepsAt(sleeps []prediction.SleepRecord, now time.Time) []prediction.SleepRecord {
+	n := sort.Search(len(sleeps), func(i int) bool { return sleeps[i].StartTime.After(now) })
+	known := make([]prediction.SleepRecord, 0, min(n, prediction.RecentRecordLimit))
+	for i := n - 1; i >= 0 && len(known) < prediction.RecentRecordLimit; i-- {
+		s := sleeps[i]
+		if s.EndTime != nil && s.EndTime.After(now) {
+			s.EndTime = nil
+			s.DurationMinutes = nil
+		}
+		known = append(known, s)
+	}
+	return known
+}
+
+// outcome returns when the predicted event actually happened after now
+func outcome(predictionType domain.PredictionType, feeds []prediction.FeedRecord, sleeps []prediction.SleepRecord, now time.Time) (time.Time, bool) {
+	switch predictionType {
+	case domain.PredictionTypeNextFeed:
+		i := sort.Search(len(feeds), func(i int) bool { return feeds[i].StartTime.After(now) })
+		if i == len(feeds) {
+			return time.Time{}, false
+		}
+		return feeds[i].StartTime, true
+
+	case domain.PredictionTypeNextWake:
+		// The nap in progress at now
+		n := sort.Search(len(sleeps), func(i int) bool { return sleeps[i].StartTime.After(now) })
+		for i := n - 1; i >= 0; i-- {
+			if end := sleeps[i].EndTime; end != nil && end.After(now) {
+				return *end, true
+			}
+		}
+		return time.Time{}, false
+
+	case domain.PredictionTypeNextNap, domain.PredictionTypeBedtime:
+		// The next sleep, which has to be of the predicted kind
+		i := sort.Search(len(sleeps), func(i int) bool { return sleeps[i].StartTime.After(now) })
+		if i == len(sleeps) || sleeps[i].EndTime == nil {
+			return time.Time{}, false
+		}
+		wantNap := predictionType == domain.PredictionTypeNextNap
+		if prediction.IsNap(sleeps[i]) != wantNap {
+			return time.Time{}, false
+		}
+		return sleeps[i].StartTime, true
+	}
+	return time.Time{}, false
+}
+
+func firstRecord(feeds []prediction.FeedRecord, sleeps []prediction.SleepRecord) time.Time {
+	var first time.Time
+	if len(feeds) > 0 {
+		first = feeds[0].StartTime
+	}
+	if len(sleeps) > 0 && (first.IsZero() || sleeps[0].StartTime.Before(first)) {
+		first = sleeps[0].StartTime
+	}
+	return first
+}
+
+func lastRecord(feeds []prediction.FeedRecord, sleeps []prediction.SleepRecord) time.Time {
+	var last time.Time
+	if len(feeds) > 0 {
+		last = feeds[len(feeds)-1].StartTime
+	}
+	if len(sleeps) > 0 && sleeps[len(sleeps)-1].StartTime.After(last) {
+		last = sleeps[len(sleeps)-1].StartTime
+	}
+	return last
+}
+
+func score(samples []Sample) prediction.Accuracy {
+	offsets := make([]time.Duration, len(samples))
+	for i, sample := range samples {
+		offsets[i] = sample.Offset()
+	}
+	return prediction.ScoreOffsets(offsets)
+}
+
+// confidenceLabel names a sample's confidence bucket
+func confidenceLabel(c *domain.PredictionConfidence) string {
+	if c == nil {
+		return "none"
+	}
+	return string(*c)
+}
+
+// confidenceOrder is the order buckets are reported in
+var confidenceOrder = []string{
+	string(domain.PredictionConfidenceHigh),
+	string(domain.PredictionConfidenceMedium),
+	string(domain.PredictionConfidenceLow),
+	"none",
+}
+
+// ByType scores the samples of one prediction type
+func (r *Result) ByType(predictionType domain.PredictionType) prediction.Accuracy {
+	return score(r.filter(func(s Sample) bool { return s.PredictionType == predictionType }))
+}
+
+// ByConfidence scores the samples of one prediction type per confidence bucket, keyed
+// "high", "medium", "low" or "none". A well calibrated engine hits more often the more
+// confident it is.
+func (r *Result) ByConfidence(predictionType domain.PredictionType) map[string]prediction.Accuracy {
+	buckets := make(map[string]prediction.Accuracy)
+	for _, label := range confidenceOrder {
+		samples := r.filter(func(s Sample) bool {
+			return s.PredictionType == predictionType && confidenceLabel(s.Confidence) == label
+		})
+		if len(samples) > 0 {
+			buckets[label] = score(samples)
+		}
+	}
+	return buckets
+}
+
+func (r *Result) filter(keep func(Sample) bool) []Sample {
+	var samples []Sample
+	for _, s := range r.Samples {
+		if keep(s) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// Print writes a table of scores per prediction type and confidence bucket
+func (r *Result) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%d simulated points, %d predictions scored\n\n", r.Points, len(r.Samples))
+	fmt.Fprintln(tw, "PREDICTION\tCONFIDENCE\tN\tMAE\tBIAS\t±15m\t±30m\tUNRESOLVED")
+
+	for _, predictionType := range prediction.OutcomeTypes {
+		overall := r.ByType(predictionType)
+		printRow(tw, predictionType, "all", overall, fmt.Sprint(r.Unresolved[predictionType]))
+
+		buckets := r.ByConfidence(predictionType)
+		for _, label := range confidenceOrder {
+			if s, ok := buckets[label]; ok {
+				printRow(tw, "", label, s, "")
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func printRow(w io.Writer, predictionType domain.PredictionType, label string, s prediction.Accuracy, unresolved string) {
+	if s.Count == 0 {
+		fmt.Fprintf(w, "%s\t%s\t0\t-\t-\t-\t-\t%s\n", predictionType, label, unresolved)
+		return
+	}
+	fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%.0f%%\t%.0f%%\t%s\n", predictionType, label, s.Count,
+		s.MAE.Round(time.Minute), s.Bias.Round(time.Minute), 100*s.Within15, 100*s.Within30, unresolved)
+}
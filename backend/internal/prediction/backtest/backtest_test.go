@@ -0,0 +1,210 @@
+package backtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var start = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+// regularFeeds are bottle feeds every 3 hours for the given number of days
+func regularFeeds(days int) []prediction.FeedRecord {
+	var feeds []prediction.FeedRecord
+	for t := start; t.Before(start.AddDate(0, 0, days)); t = t.Add(3 * time.Hour) {
+		feeds = append(feeds, prediction.FeedRecord{
+			StartTime: t,
+			EndTime:   ptr(t.Add(20 * time.Minute)),
+			AmountMl:  ptr(120),
+			FeedType:  ptr(domain.FeedTypeFormula),
+		})
+	}
+	return feeds
+}
+
+func TestRun_RegularFeeds(t *testing.T) {
+	result := Run(regularFeeds(4), nil, Options{Step: time.Hour, Timezone: "UTC"})
+
+	// A day of warm-up, then hourly to the last feed at day 4, 21:00
+	if result.Points != 70 {
+		t.Errorf("Expected 70 simulated points, got %d", result.Points)
+	}
+
+	feed := result.ByType(domain.PredictionTypeNextFeed)
+	if feed.Count == 0 {
+		t.Fatal("Expected NEXT_FEED predictions to be scored")
+	}
+	if feed.MAE != 0 || feed.Within15 != 1 {
+		t.Errorf("Expected a regular schedule to be predicted exactly, got MAE %v, within 15m %.2f", feed.MAE, feed.Within15)
+	}
+
+	total := 0
+	for _, bucket := range result.ByConfidence(domain.PredictionTypeNextFeed) {
+		total += bucket.Count
+	}
+	if total != feed.Count {
+		t.Errorf("Confidence buckets hold %d samples, want %d", total, feed.Count)
+	}
+
+	for _, s := range result.Samples {
+		if !s.ActualTime.After(s.Now) {
+			t.Errorf("Sample at %v scored against an event at %v", s.Now, s.ActualTime)
+		}
+	}
+}
+
+func TestRun_OnlySeesThePast(t *testing.T) {
+	feeds := regularFeeds(2)
+	now := start.Add(30*time.Hour + 10*time.Minute) // during the 30h feed
+
+	known := feedsAt(feeds, now)
+	if len(known) != 11 {
+		t.Fatalf("Expected the 11 feeds started by now, got %d", len(known))
+	}
+	if !known[0].StartTime.Equal(start.Add(30 * time.Hour)) {
+		t.Errorf("Expected most recent feed first, got %v", known[0].StartTime)
+	}
+	if known[0].EndTime != nil {
+		t.Error("Expected the feed in progress to be left open")
+	}
+	if known[1].EndTime == nil {
+		t.Error("Expected finished feeds to keep their end time")
+	}
+	if feeds[10].EndTime == nil {
+		t.Error("Expected the input not to be modified")
+	}
+
+	napStart := start.Add(13 * time.Hour)
+	sleeps := []prediction.SleepRecord{{StartTime: napStart, EndTime: ptr(napStart.Add(time.Hour)), DurationMinutes: ptr(60)}}
+	openNap := sleepsAt(sleeps, napStart.Add(30*time.Minute))
+	if len(openNap) != 1 || openNap[0].EndTime != nil || openNap[0].DurationMinutes != nil {
+		t.Errorf("Expected the nap in progress to be left open, got %+v", openNap)
+	}
+	if len(sleepsAt(sleeps, napStart.Add(-time.Minute))) != 0 {
+		t.Error("Expected a nap that hasn't started to be hidden")
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	napStart := start.Add(13 * time.Hour)
+	nightStart := start.Add(19 * time.Hour)
+	sleeps := []prediction.SleepRecord{
+		{StartTime: napStart, EndTime: ptr(napStart.Add(time.Hour)), DurationMinutes: ptr(60)},
+		{StartTime: nightStart, EndTime: ptr(nightStart.Add(10 * time.Hour)), DurationMinutes: ptr(600)},
+	}
+
+	tests := []struct {
+		name           string
+		predictionType domain.PredictionType
+		now            time.Time
+		want           time.Time
+		ok             bool
+	}{
+		{"next nap", domain.PredictionTypeNextNap, start.Add(12 * time.Hour), napStart, true},
+		{"wake from nap", domain.PredictionTypeNextWake, napStart.Add(30 * time.Minute), napStart.Add(time.Hour), true},
+		{"no nap in progress", domain.PredictionTypeNextWake, start.Add(12 * time.Hour), time.Time{}, false},
+		{"bedtime came before another nap", domain.PredictionTypeNextNap, start.Add(15 * time.Hour), time.Time{}, false},
+		{"bedtime", domain.PredictionTypeBedtime, start.Add(15 * time.Hour), nightStart, true},
+		{"history ends", domain.PredictionTypeBedtime, nightStart.Add(time.Hour), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := outcome(tt.predictionType, nil, sleeps, tt.now)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("outcome() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	actual := start
+	samples := []Sample{
+		{PredictedTime: actual.Add(10 * time.Minute), ActualTime: actual},
+		{PredictedTime: actual.Add(-20 * time.Minute), ActualTime: actual},
+		{PredictedTime: actual.Add(40 * time.Minute), ActualTime: actual},
+		{PredictedTime: actual, ActualTime: actual},
+	}
+
+	s := score(samples)
+	if s.Count != 4 {
+		t.Errorf("Count = %d, want 4", s.Count)
+	}
+	if s.MAE != 17*time.Minute+30*time.Second {
+		t.Errorf("MAE = %v, want 17m30s", s.MAE)
+	}
+	if s.Bias != 7*time.Minute+30*time.Second {
+		t.Errorf("Bias = %v, want 7m30s", s.Bias)
+	}
+	if s.Within15 != 0.5 || s.Within30 != 0.75 {
+		t.Errorf("Within15, Within30 = %v, %v, want 0.5, 0.75", s.Within15, s.Within30)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	result := Run(regularFeeds(3), nil, Options{Timezone: "UTC"})
+
+	var buf bytes.Buffer
+	if err := result.Print(&buf); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	for _, want := range []string{"next_feed", "next_nap", "bedtime", "±15m"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected report to mention %s:\n%s", want, buf.String())
+		}
+	}
+}
+
+// shiftingFeeds are formula feeds every 3 hours for ten days, then every 4 hours, as
+// when a baby stretches out feeds after a growth spurt
+func shiftingFeeds(daysAfterShift int) ([]prediction.FeedRecord, time.Time) {
+	shift := start.AddDate(0, 0, 10)
+	var feeds []prediction.FeedRecord
+	for t := start; t.Before(shift.AddDate(0, 0, daysAfterShift)); {
+		feeds = append(feeds, prediction.FeedRecord{StartTime: t, AmountMl: ptr(120), FeedType: ptr(domain.FeedTypeFormula)})
+		if t.Before(shift) {
+			t = t.Add(3 * time.Hour)
+		} else {
+			t = t.Add(4 * time.Hour)
+		}
+	}
+	return feeds, shift
+}
+
+func TestRun_RecencyWeightedFollowsScheduleShift(t *testing.T) {
+	feeds, shift := shiftingFeeds(3)
+	opts := Options{From: shift.Add(24 * time.Hour), Step: time.Hour, Timezone: "UTC"}
+
+	median := Run(feeds, nil, opts).ByType(domain.PredictionTypeNextFeed)
+	opts.Predictor = prediction.RecencyWeightedPredictor{HalfLife: 24 * time.Hour}
+	weighted := Run(feeds, nil, opts).ByType(domain.PredictionTypeNextFeed)
+
+	if median.Count == 0 || weighted.Count != median.Count {
+		t.Fatalf("Expected both models to score the same feeds, got %d and %d", median.Count, weighted.Count)
+	}
+	// The median still predicts the old 3 hour interval; weighting recent days catches up
+	if median.MAE < 45*time.Minute {
+		t.Errorf("Expected the median to lag behind the shift, got MAE %v", median.MAE)
+	}
+	if weighted.MAE >= median.MAE/2 {
+		t.Errorf("Expected recency weighting to at least halve the error, got MAE %v vs the median's %v", weighted.MAE, median.MAE)
+	}
+	if weighted.Bias >= 0 || median.Bias >= 0 {
+		t.Errorf("Expected both to predict early while catching up, got bias %v and %v", weighted.Bias, median.Bias)
+	}
+}
+
+func TestRun_RecencyWeightedMatchesMedianOnSteadySchedule(t *testing.T) {
+	opts := Options{Step: time.Hour, Timezone: "UTC", Predictor: prediction.RecencyWeightedPredictor{}}
+	feed := Run(regularFeeds(4), nil, opts).ByType(domain.PredictionTypeNextFeed)
+	if feed.Count == 0 || feed.MAE != 0 {
+		t.Errorf("Expected a regular schedule to be predicted exactly, got %d samples with MAE %v", feed.Count, feed.MAE)
+	}
+}
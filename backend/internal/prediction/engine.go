@@ -40,9 +40,15 @@ const (
 	maxPredictions = 20
 )
 
-// GeneratePredictions produces a timeline of predictions given recent feed and sleep data.
-// now is the current time, timezone is the family's local timezone string.
+// GeneratePredictions produces a timeline of predictions given recent feed and sleep data,
+// estimating from medians. now is the current time, timezone is the family's local timezone string.
 func GeneratePredictions(now time.Time, feeds []FeedRecord, sleeps []SleepRecord, timezone string) []*domain.Prediction {
+	return GeneratePredictionsWith(MedianPredictor{}, now, feeds, sleeps, timezone)
+}
+
+// GeneratePredictionsWith is GeneratePredictions with predictor estimating feed intervals,
+// wake windows and feed amounts.
+func GeneratePredictionsWith(predictor Predictor, now time.Time, feeds []FeedRecord, sleeps []SleepRecord, timezone string) []*domain.Prediction {
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
 		loc = time.UTC
@@ -50,21 +56,24 @@ func GeneratePredictions(now time.Time, feeds []FeedRecord, sleeps []SleepRecord
 
 	var predictions []*domain.Prediction
 
+	// Cluster feeds and growth spurts skew feed intervals
+	patterns := detectPatterns(now, feeds, loc)
+
 	// --- Feed predictions ---
-	feedPred := generateFeedPrediction(now, feeds, loc)
+	feedPred := generateFeedPrediction(predictor, now, feeds, patterns, loc)
 	if feedPred != nil {
 		predictions = append(predictions, feedPred)
 	}
 
 	// --- Feed amount prediction ---
-	amountPred := generateFeedAmountPrediction(now, feeds, feedPred, loc)
+	amountPred := generateFeedAmountPrediction(predictor, now, feeds, feedPred, loc)
 	if amountPred != nil && feedPred != nil {
 		// Merge amount into the feed prediction
 		feedPred.PredictedAmountMl = amountPred
 	}
 
 	// --- Sleep predictions ---
-	sleepPreds := generateSleepPredictions(now, sleeps, loc)
+	sleepPreds := generateSleepPredictions(predictor, now, sleeps, loc)
 	predictions = append(predictions, sleepPreds...)
 
 	// --- Bedtime prediction ---
@@ -74,7 +83,7 @@ func GeneratePredictions(now time.Time, feeds []FeedRecord, sleeps []SleepRecord
 	}
 
 	// --- Chain forward until bedtime ---
-	predictions = chainPredictions(now, predictions, feeds, sleeps, loc)
+	predictions = chainPredictions(predictor, now, predictions, feeds, sleeps, patterns, loc)
 
 	// Cap at maxPredictions
 	if len(predictions) > maxPredictions {
@@ -92,23 +101,15 @@ func GeneratePredictions(now time.Time, feeds []FeedRecord, sleeps []SleepRecord
 }
 
 // generateFeedPrediction computes the next feed prediction from historical feed data.
-func generateFeedPrediction(now time.Time, feeds []FeedRecord, loc *time.Location) *domain.Prediction {
-	daytimeFeeds := filterDaytimeFeeds(feeds, loc)
-	if len(daytimeFeeds) < 2 {
-		if len(daytimeFeeds) == 0 {
-			return nil
-		}
-		// With only 1 feed, we can't compute an interval
+func generateFeedPrediction(predictor Predictor, now time.Time, feeds []FeedRecord, patterns []Pattern, loc *time.Location) *domain.Prediction {
+	// Needs at least 2 daytime feeds for an interval
+	observations := feedIntervalObservations(feeds, loc, patterns)
+	if len(observations) == 0 {
 		return nil
 	}
 
-	intervals := computeFeedIntervals(daytimeFeeds)
-	filteredIntervals := filterFeedIntervals(intervals)
-	if len(filteredIntervals) == 0 {
-		return nil
-	}
-
-	medianInterval := medianDuration(filteredIntervals)
+	filteredIntervals := durationObservations(observations)
+	typicalInterval := estimateDuration(predictor, now, observations)
 
 	// Find the most recent feed (regardless of daytime filter, to anchor from)
 	lastFeed := findLastFeed(feeds)
@@ -116,15 +117,15 @@ func generateFeedPrediction(now time.Time, feeds []FeedRecord, loc *time.Locatio
 		return nil
 	}
 
-	predictedTime := lastFeed.StartTime.Add(medianInterval)
-	confidence := computeConfidence(len(filteredIntervals), stddevDuration(filteredIntervals), medianInterval)
+	predictedTime := lastFeed.StartTime.Add(typicalInterval)
+	confidence := computeConfidence(len(filteredIntervals), stddevDuration(filteredIntervals), typicalInterval)
 	status := assignStatus(predictedTime, now, false)
 
 	if status == domain.PredictionStatusOverdue {
 		confidence = nil
 	}
 
-	reasoning := formatFeedReasoning(medianInterval, len(filteredIntervals), confidence)
+	reasoning := formatFeedReasoning(predictor, typicalInterval, len(filteredIntervals), confidence)
 
 	return &domain.Prediction{
 		FamilyID:       uuid.Nil, // Set by caller
@@ -137,14 +138,14 @@ func generateFeedPrediction(now time.Time, feeds []FeedRecord, loc *time.Locatio
 	}
 }
 
-// generateFeedAmountPrediction returns the median feed amount in ml.
-func generateFeedAmountPrediction(_ time.Time, feeds []FeedRecord, feedPred *domain.Prediction, loc *time.Location) *int {
+// generateFeedAmountPrediction returns the typical feed amount in ml.
+func generateFeedAmountPrediction(predictor Predictor, now time.Time, feeds []FeedRecord, feedPred *domain.Prediction, loc *time.Location) *int {
 	if feedPred == nil {
 		return nil
 	}
 
 	// Filter to non-solid feeds with amounts
-	var amounts []int
+	var amounts []Observation
 	predictedTOD := feedPred.PredictedTime.In(loc)
 	predictedMinutes := predictedTOD.Hour()*60 + predictedTOD.Minute()
 
@@ -163,7 +164,7 @@ func generateFeedAmountPrediction(_ time.Time, feeds []FeedRecord, feedPred *dom
 			diff = 24*60 - diff // wrap around midnight
 		}
 		if diff <= 120 {
-			amounts = append(amounts, *f.AmountMl)
+			amounts = append(amounts, Observation{At: f.StartTime, Value: float64(*f.AmountMl)})
 		}
 	}
 
@@ -174,7 +175,7 @@ func generateFeedAmountPrediction(_ time.Time, feeds []FeedRecord, feedPred *dom
 				continue
 			}
 			if f.AmountMl != nil && *f.AmountMl > 0 {
-				amounts = append(amounts, *f.AmountMl)
+				amounts = append(amounts, Observation{At: f.StartTime, Value: float64(*f.AmountMl)})
 			}
 		}
 	}
@@ -183,12 +184,12 @@ func generateFeedAmountPrediction(_ time.Time, feeds []FeedRecord, feedPred *dom
 		return nil
 	}
 
-	median := medianInt(amounts)
-	return &median
+	amount := int(predictor.Estimate(now, amounts))
+	return &amount
 }
 
 // generateSleepPredictions generates nap/wake predictions based on current sleep state.
-func generateSleepPredictions(now time.Time, sleeps []SleepRecord, loc *time.Location) []*domain.Prediction {
+func generateSleepPredictions(predictor Predictor, now time.Time, sleeps []SleepRecord, loc *time.Location) []*domain.Prediction {
 	if len(sleeps) == 0 {
 		return nil
 	}
@@ -215,7 +216,7 @@ func generateSleepPredictions(now time.Time, sleeps []SleepRecord, loc *time.Loc
 	}
 
 	// Baby is awake -> predict next nap from wake windows
-	return generateNextNapPrediction(now, naps, sleeps, loc)
+	return generateNextNapPrediction(predictor, now, naps, sleeps, loc)
 }
 
 func generateNextWakePrediction(now time.Time, naps []SleepRecord, currentNap SleepRecord, _ *time.Location) []*domain.Prediction {
@@ -262,15 +263,15 @@ func generateNextWakePrediction(now time.Time, naps []SleepRecord, currentNap Sl
 	return []*domain.Prediction{pred}
 }
 
-func generateNextNapPrediction(now time.Time, naps []SleepRecord, allSleeps []SleepRecord, _ *time.Location) []*domain.Prediction {
-	// Compute wake windows: time between nap end and next nap start
-	wakeWindows := computeWakeWindows(naps)
-	filtered := filterWakeWindows(wakeWindows)
-	if len(filtered) == 0 {
+func generateNextNapPrediction(predictor Predictor, now time.Time, naps []SleepRecord, allSleeps []SleepRecord, _ *time.Location) []*domain.Prediction {
+	// Wake windows: time between nap end and next nap start
+	observations := wakeWindowObservations(naps)
+	if len(observations) == 0 {
 		return nil
 	}
 
-	medianWake := medianDuration(filtered)
+	filtered := durationObservations(observations)
+	typicalWake := estimateDuration(predictor, now, observations)
 
 	// Find last wake time (most recent nap end, or most recent overnight end)
 	var lastWakeTime *time.Time
@@ -286,15 +287,15 @@ func generateNextNapPrediction(now time.Time, naps []SleepRecord, allSleeps []Sl
 		return nil
 	}
 
-	predictedTime := lastWakeTime.Add(medianWake)
-	confidence := computeConfidence(len(filtered), stddevDuration(filtered), medianWake)
+	predictedTime := lastWakeTime.Add(typicalWake)
+	confidence := computeConfidence(len(filtered), stddevDuration(filtered), typicalWake)
 	status := assignStatus(predictedTime, now, false)
 
 	if status == domain.PredictionStatusOverdue {
 		confidence = nil
 	}
 
-	reasoning := fmt.Sprintf("Based on %.1fhr median wake window from last %d naps", medianWake.Hours(), len(filtered))
+	reasoning := fmt.Sprintf("Based on %.1fhr %s wake window from last %d naps", typicalWake.Hours(), predictor.Describe(), len(filtered))
 	if confidence != nil && *confidence == domain.PredictionConfidenceLow {
 		reasoning = fmt.Sprintf("Not enough data yet — only %d wake windows logged", len(filtered))
 	}
@@ -384,20 +385,21 @@ func generateBedtimePrediction(now time.Time, sleeps []SleepRecord, loc *time.Lo
 }
 
 // chainPredictions fills in PLANNED predictions (alternating feeds/naps) until bedtime.
-func chainPredictions(now time.Time, existing []*domain.Prediction, feeds []FeedRecord, sleeps []SleepRecord, loc *time.Location) []*domain.Prediction {
+func chainPredictions(predictor Predictor, now time.Time, existing []*domain.Prediction, feeds []FeedRecord, sleeps []SleepRecord, patterns []Pattern, loc *time.Location) []*domain.Prediction {
 	result := make([]*domain.Prediction, len(existing))
 	copy(result, existing)
 
-	// Get median intervals needed for chaining
-	daytimeFeeds := filterDaytimeFeeds(feeds, loc)
-	intervals := filterFeedIntervals(computeFeedIntervals(daytimeFeeds))
-	if len(intervals) == 0 {
+	// Get typical intervals needed for chaining
+	feedObservations := feedIntervalObservations(feeds, loc, patterns)
+	if len(feedObservations) == 0 {
 		return result
 	}
-	medianFeedInterval := medianDuration(intervals)
+	intervals := durationObservations(feedObservations)
+	typicalFeedInterval := estimateDuration(predictor, now, feedObservations)
 
 	naps, _ := classifySleeps(sleeps)
-	wakeWindows := filterWakeWindows(computeWakeWindows(naps))
+	wakeObservations := wakeWindowObservations(naps)
+	wakeWindows := durationObservations(wakeObservations)
 
 	// Find bedtime cutoff
 	var bedtimeCutoff time.Time
@@ -429,10 +431,10 @@ func chainPredictions(now time.Time, existing []*domain.Prediction, feeds []Feed
 	}
 
 	// Chain additional feed predictions
-	chainTime := lastFeedTime.Add(medianFeedInterval)
+	chainTime := lastFeedTime.Add(typicalFeedInterval)
 	for chainTime.Before(bedtimeCutoff) && len(result) < maxPredictions {
-		confidence := computeConfidence(len(intervals), stddevDuration(intervals), medianFeedInterval)
-		reasoning := fmt.Sprintf("Chained: based on %.1fhr median feed interval", medianFeedInterval.Hours())
+		confidence := computeConfidence(len(intervals), stddevDuration(intervals), typicalFeedInterval)
+		reasoning := fmt.Sprintf("Chained: based on %.1fhr %s feed interval", typicalFeedInterval.Hours(), predictor.Describe())
 		pred := &domain.Prediction{
 			FamilyID:       uuid.Nil,
 			ActivityType:   domain.ActivityTypeFeed,
@@ -443,12 +445,12 @@ func chainPredictions(now time.Time, existing []*domain.Prediction, feeds []Feed
 			Reasoning:      &reasoning,
 		}
 		result = append(result, pred)
-		chainTime = chainTime.Add(medianFeedInterval)
+		chainTime = chainTime.Add(typicalFeedInterval)
 	}
 
 	// Chain nap predictions if we have wake window data
 	if len(wakeWindows) > 0 {
-		medianWakeWindow := medianDuration(wakeWindows)
+		typicalWakeWindow := estimateDuration(predictor, now, wakeObservations)
 		var napDurations []time.Duration
 		for _, n := range naps {
 			if n.EndTime != nil {
@@ -472,10 +474,10 @@ func chainPredictions(now time.Time, existing []*domain.Prediction, feeds []Feed
 			}
 
 			if !lastWake.IsZero() {
-				napTime := lastWake.Add(medianWakeWindow)
+				napTime := lastWake.Add(typicalWakeWindow)
 				for napTime.Before(bedtimeCutoff) && len(result) < maxPredictions {
-					confidence := computeConfidence(len(wakeWindows), stddevDuration(wakeWindows), medianWakeWindow)
-					reasoning := fmt.Sprintf("Chained: based on %.1fhr median wake window", medianWakeWindow.Hours())
+					confidence := computeConfidence(len(wakeWindows), stddevDuration(wakeWindows), typicalWakeWindow)
+					reasoning := fmt.Sprintf("Chained: based on %.1fhr %s wake window", typicalWakeWindow.Hours(), predictor.Describe())
 					durationMin := int(medianNapDur.Minutes())
 					pred := &domain.Prediction{
 						FamilyID:                 uuid.Nil,
@@ -490,7 +492,7 @@ func chainPredictions(now time.Time, existing []*domain.Prediction, feeds []Feed
 					result = append(result, pred)
 
 					// Next nap starts after this nap ends + wake window
-					napTime = napTime.Add(medianNapDur).Add(medianWakeWindow)
+					napTime = napTime.Add(medianNapDur).Add(typicalWakeWindow)
 				}
 			}
 		}
@@ -527,68 +529,6 @@ func filterDaytimeFeeds(feeds []FeedRecord, loc *time.Location) []FeedRecord {
 	return result
 }
 
-func computeFeedIntervals(feeds []FeedRecord) []time.Duration {
-	if len(feeds) < 2 {
-		return nil
-	}
-
-	// Sort by start time ascending
-	sorted := make([]FeedRecord, len(feeds))
-	copy(sorted, feeds)
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].StartTime.Before(sorted[j].StartTime)
-	})
-
-	var intervals []time.Duration
-	for i := 1; i < len(sorted); i++ {
-		interval := sorted[i].StartTime.Sub(sorted[i-1].StartTime)
-		intervals = append(intervals, interval)
-	}
-	return intervals
-}
-
-func filterFeedIntervals(intervals []time.Duration) []time.Duration {
-	var result []time.Duration
-	for _, i := range intervals {
-		if i >= minFeedInterval && i <= maxFeedInterval {
-			result = append(result, i)
-		}
-	}
-	return result
-}
-
-func computeWakeWindows(naps []SleepRecord) []time.Duration {
-	if len(naps) < 2 {
-		return nil
-	}
-
-	// Sort by start time ascending
-	sorted := make([]SleepRecord, len(naps))
-	copy(sorted, naps)
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].StartTime.Before(sorted[j].StartTime)
-	})
-
-	var windows []time.Duration
-	for i := 1; i < len(sorted); i++ {
-		if sorted[i-1].EndTime != nil {
-			window := sorted[i].StartTime.Sub(*sorted[i-1].EndTime)
-			windows = append(windows, window)
-		}
-	}
-	return windows
-}
-
-func filterWakeWindows(windows []time.Duration) []time.Duration {
-	var result []time.Duration
-	for _, w := range windows {
-		if w >= minWakeWindow && w <= maxWakeWindow {
-			result = append(result, w)
-		}
-	}
-	return result
-}
-
 func classifySleeps(sleeps []SleepRecord) (naps []SleepRecord, overnights []SleepRecord) {
 	for _, s := range sleeps {
 		if classifySingleSleep(s) == "nap" {
@@ -600,6 +540,12 @@ func classifySleeps(sleeps []SleepRecord) (naps []SleepRecord, overnights []Slee
 	return
 }
 
+// IsNap reports whether the engine treats a sleep as a nap rather than overnight sleep.
+// Ongoing sleeps count as naps.
+func IsNap(s SleepRecord) bool {
+	return classifySingleSleep(s) == "nap"
+}
+
 func classifySingleSleep(s SleepRecord) string {
 	var durationMin float64
 	if s.DurationMinutes != nil {
@@ -735,11 +681,11 @@ func assignStatus(predictedTime, now time.Time, isChained bool) domain.Predictio
 	return domain.PredictionStatusUpcoming
 }
 
-func formatFeedReasoning(medianInterval time.Duration, dataPoints int, confidence *domain.PredictionConfidence) string {
+func formatFeedReasoning(predictor Predictor, interval time.Duration, dataPoints int, confidence *domain.PredictionConfidence) string {
 	if confidence != nil && *confidence == domain.PredictionConfidenceLow {
 		return fmt.Sprintf("Not enough data yet — only %d feed intervals logged", dataPoints)
 	}
-	return fmt.Sprintf("Based on %.1fhr median feed interval from last %d feeds", medianInterval.Hours(), dataPoints)
+	return fmt.Sprintf("Based on %.1fhr %s feed interval from last %d feeds", interval.Hours(), predictor.Describe(), dataPoints)
 }
 
 func formatTimeOfDay(minutes int, loc *time.Location) string {
@@ -130,15 +130,18 @@ func TestFilterDaytimeFeeds_ExcludesNightFeeds(t *testing.T) {
 	}
 }
 
-func TestFilterFeedIntervals_RemovesOutliers(t *testing.T) {
-	intervals := []time.Duration{
-		30 * time.Minute,       // too short
-		2 * time.Hour,          // ok
-		3 * time.Hour,          // ok
-		3*time.Hour + 30*time.Minute, // ok
-		10 * time.Hour,         // too long
-	}
-	result := filterFeedIntervals(intervals)
+func TestFeedIntervalObservations_RemovesOutliers(t *testing.T) {
+	// Intervals of 10h (too long), 3.5h, 12h overnight (too long), 3h, 2h and 30m (too short)
+	feeds := []FeedRecord{
+		makeFeed(31, domain.FeedTypeFormula, 100),
+		makeFeed(21, domain.FeedTypeFormula, 100),
+		makeFeed(17.5, domain.FeedTypeFormula, 100),
+		makeFeed(5.5, domain.FeedTypeFormula, 100),
+		makeFeed(2.5, domain.FeedTypeFormula, 100),
+		makeFeed(0.5, domain.FeedTypeFormula, 100),
+		makeFeed(0, domain.FeedTypeFormula, 100),
+	}
+	result := feedIntervalObservations(feeds, testLoc, nil)
 	if len(result) != 3 {
 		t.Errorf("expected 3 intervals, got %d", len(result))
 	}
@@ -169,15 +172,17 @@ func TestClassifySleep_Boundary(t *testing.T) {
 	}
 }
 
-func TestFilterWakeWindows_RemovesOutliers(t *testing.T) {
-	windows := []time.Duration{
-		30 * time.Minute,  // too short
-		90 * time.Minute,  // ok
-		2 * time.Hour,     // ok
-		150 * time.Minute, // ok
-		7 * time.Hour,     // too long
+func TestWakeWindowObservations_RemovesOutliers(t *testing.T) {
+	// 45 minute naps with wake windows of 30m (too short), 90m, 2h, 150m and 7h (too long)
+	naps := []SleepRecord{
+		makeNap(0.75, 45),
+		makeNap(8.5, 45),
+		makeNap(11.75, 45),
+		makeNap(14.5, 45),
+		makeNap(16.75, 45),
+		makeNap(18, 45),
 	}
-	result := filterWakeWindows(windows)
+	result := wakeWindowObservations(naps)
 	if len(result) != 3 {
 		t.Errorf("expected 3 windows, got %d", len(result))
 	}
@@ -0,0 +1,337 @@
+package prediction
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// AgeNorms are typical daytime rhythms for a baby of a given age. They seed predictions
+// before a family has logged enough to predict from, and act as a prior that observed
+// data outweighs as it grows.
+type AgeNorms struct {
+	// Weeks is the baby's age in whole weeks
+	Weeks          int
+	FeedInterval   time.Duration
+	WakeWindow     time.Duration
+	NapCount       int
+	NapDuration    time.Duration
+	TotalSleep     time.Duration // over 24 hours
+	BedtimeMinutes int           // minutes since midnight
+}
+
+// ageNorm is one row of ageNormTable, covering ages up to maxWeeks
+type ageNorm struct {
+	maxWeeks            int
+	feedIntervalMinutes int
+	wakeWindowMinutes   int
+	napCount            int
+	napMinutes          int
+	totalSleepMinutes   int
+	bedtime             string
+}
+
+// ageNormTable holds typical values by week of age, from common pediatric sleep and
+// feeding guidance. Babies older than the last row use it.
+var ageNormTable = []ageNorm{
+	{maxWeeks: 4, feedIntervalMinutes: 150, wakeWindowMinutes: 60, napCount: 5, napMinutes: 60, totalSleepMinutes: 960, bedtime: "22:00"},
+	{maxWeeks: 8, feedIntervalMinutes: 165, wakeWindowMinutes: 75, napCount: 5, napMinutes: 60, totalSleepMinutes: 930, bedtime: "21:30"},
+	{maxWeeks: 12, feedIntervalMinutes: 180, wakeWindowMinutes: 90, napCount: 4, napMinutes: 75, totalSleepMinutes: 900, bedtime: "20:30"},
+	{maxWeeks: 16, feedIntervalMinutes: 180, wakeWindowMinutes: 105, napCount: 4, napMinutes: 75, totalSleepMinutes: 870, bedtime: "20:00"},
+	{maxWeeks: 26, feedIntervalMinutes: 210, wakeWindowMinutes: 135, napCount: 3, napMinutes: 90, totalSleepMinutes: 840, bedtime: "19:30"},
+	{maxWeeks: 39, feedIntervalMinutes: 240, wakeWindowMinutes: 165, napCount: 2, napMinutes: 90, totalSleepMinutes: 840, bedtime: "19:00"},
+	{maxWeeks: 52, feedIntervalMinutes: 240, wakeWindowMinutes: 195, napCount: 2, napMinutes: 90, totalSleepMinutes: 810, bedtime: "19:00"},
+	{maxWeeks: 78, feedIntervalMinutes: 270, wakeWindowMinutes: 270, napCount: 1, napMinutes: 120, totalSleepMinutes: 780, bedtime: "19:30"},
+	{maxWeeks: 104, feedIntervalMinutes: 300, wakeWindowMinutes: 300, napCount: 1, napMinutes: 120, totalSleepMinutes: 750, bedtime: "19:30"},
+}
+
+// NormsForAge returns the norms for a baby born on birthDate, or nil if the birth date is
+// unknown or in the future.
+func NormsForAge(birthDate *time.Time, now time.Time) *AgeNorms {
+	if birthDate == nil || birthDate.After(now) {
+		return nil
+	}
+
+	weeks := int(now.Sub(*birthDate).Hours() / (24 * 7))
+	row := ageNormTable[len(ageNormTable)-1]
+	for _, candidate := range ageNormTable {
+		if weeks < candidate.maxWeeks {
+			row = candidate
+			break
+		}
+	}
+
+	return &AgeNorms{
+		Weeks:          weeks,
+		FeedInterval:   time.Duration(row.feedIntervalMinutes) * time.Minute,
+		WakeWindow:     time.Duration(row.wakeWindowMinutes) * time.Minute,
+		NapCount:       row.napCount,
+		NapDuration:    time.Duration(row.napMinutes) * time.Minute,
+		TotalSleep:     time.Duration(row.totalSleepMinutes) * time.Minute,
+		BedtimeMinutes: parseTimeToMinutes(row.bedtime),
+	}
+}
+
+// age describes the baby's age for reasoning text, like "6-week-old" or "7-month-old"
+func (n *AgeNorms) age() string {
+	if n.Weeks < 12 {
+		return fmt.Sprintf("%d-week-old", max(n.Weeks, 1))
+	}
+	return fmt.Sprintf("%d-month-old", int(float64(n.Weeks)*7/30.44))
+}
+
+// BlendAgeNorms pulls each upcoming or overdue data-driven prediction toward what is
+// typical for the baby's age, blending the norm in with BlendValue as if it were a goal:
+// it decides while data is sparse and gives way once there is enough. Planned predictions
+// chained after one move with it. While there is little sleep data, naps are also limited
+// to the typical count.
+func BlendAgeNorms(now time.Time, predictions []*domain.Prediction, norms *AgeNorms, feeds []FeedRecord, sleeps []SleepRecord, timezone string) []*domain.Prediction {
+	if norms == nil {
+		return predictions
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	naps, overnights := classifySleeps(sleeps)
+	for _, p := range predictions {
+		if p.Status == domain.PredictionStatusPlanned {
+			continue
+		}
+
+		var anchor time.Time
+		var dataPoints int
+		var prior time.Duration
+		switch p.PredictionType {
+		case domain.PredictionTypeNextFeed:
+			lastFeed := findLastFeed(feeds)
+			if lastFeed == nil {
+				continue
+			}
+			anchor = lastFeed.StartTime
+			dataPoints = len(feedIntervalObservations(feeds, loc, detectPatterns(now, feeds, loc)))
+			prior = norms.FeedInterval
+
+		case domain.PredictionTypeNextNap:
+			lastWake := lastWakeTime(sleeps)
+			if lastWake == nil {
+				continue
+			}
+			anchor = *lastWake
+			dataPoints = len(wakeWindowObservations(naps))
+			prior = norms.WakeWindow
+
+		case domain.PredictionTypeNextWake:
+			if p.PredictedDurationMinutes == nil {
+				continue
+			}
+			anchor = p.PredictedTime.Add(-time.Duration(*p.PredictedDurationMinutes) * time.Minute)
+			dataPoints = len(completedSleeps(naps))
+			prior = norms.NapDuration
+
+		case domain.PredictionTypeBedtime:
+			// Blend time of day, choosing the prior's day closest to the prediction
+			bedtime := p.PredictedTime.In(loc)
+			day := time.Date(bedtime.Year(), bedtime.Month(), bedtime.Day(), 0, 0, 0, 0, loc)
+			priorTime := day.Add(time.Duration(norms.BedtimeMinutes) * time.Minute)
+			if bedtime.Sub(priorTime) > 12*time.Hour {
+				priorTime = priorTime.Add(24 * time.Hour)
+			} else if priorTime.Sub(bedtime) > 12*time.Hour {
+				priorTime = priorTime.Add(-24 * time.Hour)
+			}
+			anchor = day
+			dataPoints = len(overnights)
+			prior = priorTime.Sub(day)
+		default:
+			continue
+		}
+
+		observed, priorMinutes := p.PredictedTime.Sub(anchor).Minutes(), prior.Minutes()
+		blended := time.Duration(math.Round(BlendValue(&observed, dataPoints, &priorMinutes).Value)) * time.Minute
+		delta := anchor.Add(blended).Sub(p.PredictedTime)
+		if delta.Abs() < time.Minute {
+			continue
+		}
+
+		shiftPrediction(p, delta, now)
+		if p.PredictionType == domain.PredictionTypeNextWake {
+			duration := int(blended.Minutes())
+			p.PredictedDurationMinutes = &duration
+		}
+		if p.Reasoning != nil {
+			reasoning := fmt.Sprintf("%s, adjusted toward what's typical for a %s", *p.Reasoning, norms.age())
+			p.Reasoning = &reasoning
+		}
+
+		// Keep the planned predictions chained after this one in step
+		for _, planned := range predictions {
+			if planned.Status == domain.PredictionStatusPlanned && chainsFrom(planned.PredictionType, p.PredictionType) {
+				shiftPrediction(planned, delta, now)
+			}
+		}
+	}
+
+	if len(completedSleeps(naps)) < enoughDataThreshold {
+		predictions = ConstrainNapCount(predictions, &norms.NapCount)
+	}
+	return predictions
+}
+
+// chainsFrom reports whether planned predictions of type planned are chained from a
+// prediction of type from
+func chainsFrom(planned, from domain.PredictionType) bool {
+	switch from {
+	case domain.PredictionTypeNextFeed:
+		return planned == domain.PredictionTypeNextFeed
+	case domain.PredictionTypeNextNap, domain.PredictionTypeNextWake:
+		return planned == domain.PredictionTypeNextNap
+	}
+	return false
+}
+
+// shiftPrediction moves a prediction by delta, keeping its ID and status consistent with
+// the new time
+func shiftPrediction(p *domain.Prediction, delta time.Duration, now time.Time) {
+	p.PredictedTime = p.PredictedTime.Add(delta)
+	p.ID = stableID(p.PredictionType, p.PredictedTime)
+	if p.Status == domain.PredictionStatusPlanned {
+		return
+	}
+	p.Status = assignStatus(p.PredictedTime, now, false)
+	if p.Status == domain.PredictionStatusOverdue {
+		p.Confidence = nil
+	} else if p.Confidence == nil {
+		low := domain.PredictionConfidenceLow
+		p.Confidence = &low
+	}
+}
+
+// GenerateAgeNormPredictions predicts from age norms alone whatever existing doesn't
+// cover: the next feed, the next nap or wake, and bedtime. Each is anchored on the most
+// recent matching event if there is one, and otherwise on now.
+func GenerateAgeNormPredictions(now time.Time, norms *AgeNorms, existing []*domain.Prediction, feeds []FeedRecord, sleeps []SleepRecord, timezone string) []*domain.Prediction {
+	if norms == nil {
+		return nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	covered := make(map[domain.PredictionType]bool)
+	for _, p := range existing {
+		covered[p.PredictionType] = true
+	}
+
+	var predictions []*domain.Prediction
+	add := func(activityType domain.ActivityType, predictionType domain.PredictionType, predictedTime time.Time, reasoning string) *domain.Prediction {
+		confidence := domain.PredictionConfidenceLow
+		p := &domain.Prediction{
+			FamilyID:       uuid.Nil,
+			ActivityType:   activityType,
+			PredictionType: predictionType,
+			PredictedTime:  predictedTime,
+			Status:         assignStatus(predictedTime, now, false),
+			Reasoning:      &reasoning,
+			ComputedAt:     now,
+			CreatedAt:      now,
+			ID:             stableID(predictionType, predictedTime),
+		}
+		if p.Status != domain.PredictionStatusOverdue {
+			p.Confidence = &confidence
+		}
+		predictions = append(predictions, p)
+		return p
+	}
+
+	if !covered[domain.PredictionTypeNextFeed] {
+		anchor := now
+		if lastFeed := findLastFeed(feeds); lastFeed != nil {
+			anchor = lastFeed.StartTime
+		}
+		add(domain.ActivityTypeFeed, domain.PredictionTypeNextFeed, anchor.Add(norms.FeedInterval),
+			fmt.Sprintf("Typical for a %s: feeds about every %s", norms.age(), formatHours(norms.FeedInterval)))
+	}
+
+	if !covered[domain.PredictionTypeNextNap] && !covered[domain.PredictionTypeNextWake] {
+		duration := int(norms.NapDuration.Minutes())
+		if current := currentNap(sleeps); current != nil {
+			p := add(domain.ActivityTypeSleep, domain.PredictionTypeNextWake, current.StartTime.Add(norms.NapDuration),
+				fmt.Sprintf("Typical for a %s: naps of about %s", norms.age(), formatHours(norms.NapDuration)))
+			p.CareSessionID = &current.CareSessionID
+			p.PredictedDurationMinutes = &duration
+		} else {
+			anchor := now
+			if lastWake := lastWakeTime(sleeps); lastWake != nil {
+				anchor = *lastWake
+			}
+			p := add(domain.ActivityTypeSleep, domain.PredictionTypeNextNap, anchor.Add(norms.WakeWindow),
+				fmt.Sprintf("Typical for a %s: %s awake between naps", norms.age(), formatHours(norms.WakeWindow)))
+			p.PredictedDurationMinutes = &duration
+		}
+	}
+
+	if !covered[domain.PredictionTypeBedtime] {
+		nowLocal := now.In(loc)
+		todayStart := time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, loc)
+		bedtime := todayStart.Add(time.Duration(norms.BedtimeMinutes) * time.Minute)
+		if bedtime.Before(now) {
+			bedtime = bedtime.Add(24 * time.Hour)
+		}
+		add(domain.ActivityTypeSleep, domain.PredictionTypeBedtime, bedtime,
+			fmt.Sprintf("Typical bedtime for a %s is around %s", norms.age(), formatTimeOfDay(norms.BedtimeMinutes, loc)))
+	}
+
+	return predictions
+}
+
+// currentNap returns the most recent sleep if it is a nap still in progress
+func currentNap(sleeps []SleepRecord) *SleepRecord {
+	if len(sleeps) == 0 {
+		return nil
+	}
+	mostRecent := sleeps[0]
+	for _, s := range sleeps[1:] {
+		if s.StartTime.After(mostRecent.StartTime) {
+			mostRecent = s
+		}
+	}
+	if mostRecent.EndTime != nil || !IsNap(mostRecent) {
+		return nil
+	}
+	return &mostRecent
+}
+
+// lastWakeTime returns the latest end of a completed sleep
+func lastWakeTime(sleeps []SleepRecord) *time.Time {
+	var lastWake *time.Time
+	for _, s := range sleeps {
+		if s.EndTime != nil && (lastWake == nil || s.EndTime.After(*lastWake)) {
+			lastWake = s.EndTime
+		}
+	}
+	return lastWake
+}
+
+func completedSleeps(sleeps []SleepRecord) []SleepRecord {
+	var completed []SleepRecord
+	for _, s := range sleeps {
+		if s.EndTime != nil {
+			completed = append(completed, s)
+		}
+	}
+	return completed
+}
+
+// formatHours formats a duration like "2.5hr" or "45min"
+func formatHours(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%.0fmin", d.Minutes())
+	}
+	hours := math.Round(d.Hours()*10) / 10
+	return fmt.Sprintf("%ghr", hours)
+}
@@ -0,0 +1,143 @@
+package prediction
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func TestNormsForAge(t *testing.T) {
+	if NormsForAge(nil, baseTime) != nil {
+		t.Error("expected no norms without a birth date")
+	}
+	if NormsForAge(ptr(baseTime.Add(24*time.Hour)), baseTime) != nil {
+		t.Error("expected no norms for a birth date in the future")
+	}
+
+	tests := []struct {
+		weeks        int
+		feedInterval time.Duration
+		napCount     int
+	}{
+		{0, 150 * time.Minute, 5},
+		{6, 165 * time.Minute, 5},
+		{20, 210 * time.Minute, 3},
+		{200, 300 * time.Minute, 1}, // older than the table
+	}
+	for _, tt := range tests {
+		norms := NormsForAge(ptr(baseTime.AddDate(0, 0, -7*tt.weeks)), baseTime)
+		if norms == nil || norms.Weeks != tt.weeks {
+			t.Fatalf("week %d: got %+v", tt.weeks, norms)
+		}
+		if norms.FeedInterval != tt.feedInterval || norms.NapCount != tt.napCount {
+			t.Errorf("week %d: feed interval %v, %d naps, want %v, %d", tt.weeks, norms.FeedInterval, norms.NapCount, tt.feedInterval, tt.napCount)
+		}
+	}
+}
+
+func TestGenerateAgeNormPredictions_NewBaby(t *testing.T) {
+	norms := NormsForAge(ptr(baseTime.AddDate(0, 0, -7*6)), baseTime)
+	feeds := []FeedRecord{makeFeed(1, domain.FeedTypeFormula, 90)}
+
+	result := GenerateAgeNormPredictions(baseTime, norms, nil, feeds, nil, "America/Los_Angeles")
+
+	byType := make(map[domain.PredictionType]*domain.Prediction)
+	for _, p := range result {
+		byType[p.PredictionType] = p
+	}
+	if len(result) != 3 || byType[domain.PredictionTypeNextFeed] == nil || byType[domain.PredictionTypeNextNap] == nil || byType[domain.PredictionTypeBedtime] == nil {
+		t.Fatalf("expected a feed, nap and bedtime prediction, got %d predictions", len(result))
+	}
+
+	feed := byType[domain.PredictionTypeNextFeed]
+	if want := feeds[0].StartTime.Add(165 * time.Minute); !feed.PredictedTime.Equal(want) {
+		t.Errorf("expected next feed at %v, got %v", want, feed.PredictedTime)
+	}
+	if feed.Confidence == nil || *feed.Confidence != domain.PredictionConfidenceLow {
+		t.Errorf("expected LOW confidence, got %v", feed.Confidence)
+	}
+	if !strings.Contains(*feed.Reasoning, "6-week-old") {
+		t.Errorf("unexpected reasoning: %s", *feed.Reasoning)
+	}
+
+	if nap := byType[domain.PredictionTypeNextNap]; !nap.PredictedTime.Equal(baseTime.Add(75 * time.Minute)) {
+		t.Errorf("expected next nap a wake window from now, got %v", nap.PredictedTime)
+	}
+	if bedtime := byType[domain.PredictionTypeBedtime]; bedtime.PredictedTime.Before(baseTime) {
+		t.Errorf("expected bedtime ahead, got %v", bedtime.PredictedTime)
+	}
+}
+
+func TestGenerateAgeNormPredictions_OnlyFillsGaps(t *testing.T) {
+	norms := NormsForAge(ptr(baseTime.AddDate(0, 0, -7*6)), baseTime)
+	existing := []*domain.Prediction{
+		{PredictionType: domain.PredictionTypeNextFeed},
+		{PredictionType: domain.PredictionTypeNextWake},
+	}
+
+	result := GenerateAgeNormPredictions(baseTime, norms, existing, nil, nil, "America/Los_Angeles")
+	if len(result) != 1 || result[0].PredictionType != domain.PredictionTypeBedtime {
+		t.Errorf("expected only a bedtime prediction, got %d predictions", len(result))
+	}
+
+	if GenerateAgeNormPredictions(baseTime, nil, nil, nil, nil, "America/Los_Angeles") != nil {
+		t.Error("expected no predictions without norms")
+	}
+}
+
+func TestBlendAgeNorms_SparseFeeds(t *testing.T) {
+	norms := NormsForAge(ptr(baseTime.AddDate(0, 0, -7*6)), baseTime)
+
+	tests := []struct {
+		name  string
+		feeds []FeedRecord
+		// wantInterval is from the last feed to the blended next feed
+		wantInterval time.Duration
+	}{
+		{
+			// Two 3hr intervals are too few to go on, so the 2.75hr norm is used
+			name: "too little data",
+			feeds: []FeedRecord{
+				makeFeed(0.5, domain.FeedTypeBreastMilk, 100),
+				makeFeed(3.5, domain.FeedTypeBreastMilk, 110),
+				makeFeed(6.5, domain.FeedTypeBreastMilk, 120),
+			},
+			wantInterval: 165 * time.Minute,
+		},
+		{
+			// Three 2hr intervals are blended with the norm like a goal: 0.4*120 + 0.6*165
+			name: "sparse data",
+			feeds: []FeedRecord{
+				makeFeed(0.5, domain.FeedTypeBreastMilk, 100),
+				makeFeed(2.5, domain.FeedTypeBreastMilk, 110),
+				makeFeed(4.5, domain.FeedTypeBreastMilk, 120),
+				makeFeed(6.5, domain.FeedTypeBreastMilk, 120),
+			},
+			wantInterval: 147 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := BlendAgeNorms(baseTime, GeneratePredictions(baseTime, tt.feeds, nil, "America/Los_Angeles"), norms, tt.feeds, nil, "America/Los_Angeles")
+
+			for _, p := range result {
+				if p.PredictionType != domain.PredictionTypeNextFeed || p.Status == domain.PredictionStatusPlanned {
+					continue
+				}
+				if want := tt.feeds[0].StartTime.Add(tt.wantInterval); !p.PredictedTime.Equal(want) {
+					t.Errorf("expected next feed at %v, got %v", want, p.PredictedTime)
+				}
+				if p.ID != stableID(p.PredictionType, p.PredictedTime) {
+					t.Error("expected the ID to follow the blended time")
+				}
+				if !strings.Contains(*p.Reasoning, "typical for a 6-week-old") {
+					t.Errorf("unexpected reasoning: %s", *p.Reasoning)
+				}
+				return
+			}
+			t.Fatal("expected a next feed prediction")
+		})
+	}
+}
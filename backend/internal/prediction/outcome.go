@@ -0,0 +1,180 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+const (
+	// maxOutcomeOffset is how far an event can be from an outstanding prediction and still
+	// count as fulfilling it. Further off, it's more likely a backfilled or mistimed entry
+	// than the event the prediction was about.
+	maxOutcomeOffset = 3 * time.Hour
+
+	// AccuracyWindow is how far back outcomes are considered when adjusting confidence
+	AccuracyWindow = 7 * 24 * time.Hour
+	// minOutcomes is how many recent outcomes a prediction type needs before its
+	// confidence is adjusted
+	minOutcomes = 5
+	// Recent mean absolute errors above these lower confidence by one and two levels
+	mediumErrorThreshold = 30 * time.Minute
+	largeErrorThreshold  = 60 * time.Minute
+)
+
+// OutcomeTypes are the prediction types that are scored against what happened, in the
+// order they are reported
+var OutcomeTypes = []domain.PredictionType{
+	domain.PredictionTypeNextFeed,
+	domain.PredictionTypeNextNap,
+	domain.PredictionTypeNextWake,
+	domain.PredictionTypeBedtime,
+}
+
+// RecordOutcome matches an event logged for activity at actual to the baby's outstanding
+// prediction of one of predictionTypes closest to it, and records how far off it was.
+// Planned predictions further along the timeline aren't matched. It does nothing when
+// there is no prediction within maxOutcomeOffset.
+//
+// It must run before the baby's predictions are invalidated for the new event.
+func RecordOutcome(ctx context.Context, s store.Store, activity *domain.Activity, actual time.Time, predictionTypes ...domain.PredictionType) error {
+	predictions, err := s.GetPredictionsForBaby(ctx, activity.BabyID)
+	if err != nil {
+		return fmt.Errorf("failed to get predictions: %w", err)
+	}
+
+	var match *domain.Prediction
+	for _, p := range predictions {
+		if p.Status == domain.PredictionStatusPlanned || !slices.Contains(predictionTypes, p.PredictionType) {
+			continue
+		}
+		offset := p.PredictedTime.Sub(actual).Abs()
+		if offset <= maxOutcomeOffset && (match == nil || offset < match.PredictedTime.Sub(actual).Abs()) {
+			match = p
+		}
+	}
+	if match == nil {
+		return nil
+	}
+
+	err = s.CreatePredictionOutcome(ctx, &domain.PredictionOutcome{
+		ID:             uuid.New(),
+		FamilyID:       match.FamilyID,
+		BabyID:         activity.BabyID,
+		PredictionID:   match.ID,
+		ActivityID:     activity.ID,
+		PredictionType: match.PredictionType,
+		Confidence:     match.Confidence,
+		PredictedTime:  match.PredictedTime,
+		ActualTime:     actual,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record prediction outcome: %w", err)
+	}
+	return nil
+}
+
+// Accuracy summarizes how close a set of predictions came to what happened
+type Accuracy struct {
+	Count int
+	// MAE is the mean absolute error
+	MAE time.Duration
+	// Bias is the mean signed error; positive means predictions ran late
+	Bias time.Duration
+	// Within15 and Within30 are the fractions of predictions within ±15 and ±30 minutes
+	Within15 float64
+	Within30 float64
+}
+
+// ScoreOffsets summarizes prediction errors, each the predicted time minus the actual one
+func ScoreOffsets(offsets []time.Duration) Accuracy {
+	a := Accuracy{Count: len(offsets)}
+	if len(offsets) == 0 {
+		return a
+	}
+
+	var absTotal, total time.Duration
+	var within15, within30 int
+	for _, offset := range offsets {
+		total += offset
+		absTotal += offset.Abs()
+		if offset.Abs() <= 15*time.Minute {
+			within15++
+		}
+		if offset.Abs() <= 30*time.Minute {
+			within30++
+		}
+	}
+
+	n := time.Duration(len(offsets))
+	a.MAE = absTotal / n
+	a.Bias = total / n
+	a.Within15 = float64(within15) / float64(len(offsets))
+	a.Within30 = float64(within30) / float64(len(offsets))
+	return a
+}
+
+// OutcomeAccuracy scores outcomes of one prediction type
+func OutcomeAccuracy(outcomes []*domain.PredictionOutcome, predictionType domain.PredictionType) Accuracy {
+	var offsets []time.Duration
+	for _, o := range outcomes {
+		if o.PredictionType == predictionType {
+			offsets = append(offsets, o.Offset())
+		}
+	}
+	return ScoreOffsets(offsets)
+}
+
+// AdjustConfidence lowers the confidence computeConfidence gave each prediction when the
+// baby's recent predictions of that type have been far off: one level for a mean error
+// over mediumErrorThreshold, two over largeErrorThreshold. Types with fewer than
+// minOutcomes recent outcomes are left alone.
+func AdjustConfidence(predictions []*domain.Prediction, outcomes []*domain.PredictionOutcome) {
+	downgrades := make(map[domain.PredictionType]int)
+	for _, p := range predictions {
+		if _, ok := downgrades[p.PredictionType]; ok {
+			continue
+		}
+		accuracy := OutcomeAccuracy(outcomes, p.PredictionType)
+		switch {
+		case accuracy.Count < minOutcomes:
+			downgrades[p.PredictionType] = 0
+		case accuracy.MAE > largeErrorThreshold:
+			downgrades[p.PredictionType] = 2
+		case accuracy.MAE > mediumErrorThreshold:
+			downgrades[p.PredictionType] = 1
+		default:
+			downgrades[p.PredictionType] = 0
+		}
+	}
+
+	for _, p := range predictions {
+		if p.Confidence == nil || downgrades[p.PredictionType] == 0 {
+			continue
+		}
+		p.Confidence = lowerConfidence(*p.Confidence, downgrades[p.PredictionType])
+	}
+}
+
+// confidenceLevels are the confidence levels from lowest to highest
+var confidenceLevels = []domain.PredictionConfidence{
+	domain.PredictionConfidenceLow,
+	domain.PredictionConfidenceMedium,
+	domain.PredictionConfidenceHigh,
+}
+
+func lowerConfidence(c domain.PredictionConfidence, levels int) *domain.PredictionConfidence {
+	for i, level := range confidenceLevels {
+		if level == c {
+			lowered := confidenceLevels[max(i-levels, 0)]
+			return &lowered
+		}
+	}
+	return &c
+}
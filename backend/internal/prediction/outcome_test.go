@@ -0,0 +1,114 @@
+package prediction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store/memory"
+)
+
+func TestRecordOutcome(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewMemoryStore()
+
+	family := &domain.Family{ID: uuid.New(), Name: "Outcomes", CreatedAt: baseTime, UpdatedAt: baseTime}
+	baby := &domain.Baby{ID: uuid.New(), FamilyID: family.ID, Name: "Baby", CreatedAt: baseTime, UpdatedAt: baseTime}
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: family.ID, Name: "Caregiver", CreatedAt: baseTime, UpdatedAt: baseTime}
+	if err := s.CreateFamilyWithCaregiver(ctx, family, baby, caregiver); err != nil {
+		t.Fatalf("Failed to create family: %v", err)
+	}
+	session := &domain.CareSession{ID: uuid.New(), CaregiverID: caregiver.ID, FamilyID: family.ID, Status: domain.StatusInProgress, StartedAt: baseTime}
+	if err := s.CreateCareSession(ctx, session); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	newActivity := func() *domain.Activity {
+		activity := &domain.Activity{ID: uuid.New(), CareSessionID: session.ID, BabyID: baby.ID, ActivityType: domain.ActivityTypeSleep, CreatedAt: baseTime}
+		if err := s.CreateActivity(ctx, activity); err != nil {
+			t.Fatalf("Failed to create activity: %v", err)
+		}
+		return activity
+	}
+
+	nap := &domain.Prediction{ID: uuid.New(), FamilyID: family.ID, PredictionType: domain.PredictionTypeNextNap,
+		PredictedTime: baseTime.Add(time.Hour), Status: domain.PredictionStatusUpcoming, Confidence: ptr(domain.PredictionConfidenceHigh)}
+	bedtime := &domain.Prediction{ID: uuid.New(), FamilyID: family.ID, PredictionType: domain.PredictionTypeBedtime,
+		PredictedTime: baseTime.Add(5 * time.Hour), Status: domain.PredictionStatusUpcoming}
+	if err := s.UpsertPredictions(ctx, baby.ID, []*domain.Prediction{nap, bedtime}); err != nil {
+		t.Fatalf("Failed to save predictions: %v", err)
+	}
+
+	// A sleep at 6pm is closer to bedtime than to the nap
+	if err := RecordOutcome(ctx, s, newActivity(), baseTime.Add(4*time.Hour), domain.PredictionTypeNextNap, domain.PredictionTypeBedtime); err != nil {
+		t.Fatalf("RecordOutcome failed: %v", err)
+	}
+	// Nothing within maxOutcomeOffset of a sleep logged the next morning
+	if err := RecordOutcome(ctx, s, newActivity(), baseTime.Add(20*time.Hour), domain.PredictionTypeNextNap, domain.PredictionTypeBedtime); err != nil {
+		t.Fatalf("RecordOutcome failed: %v", err)
+	}
+
+	outcomes, err := s.GetPredictionOutcomesForBaby(ctx, baby.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to get outcomes: %v", err)
+	}
+	if len(outcomes) != 1 {
+		t.Fatalf("Expected 1 outcome, got %d", len(outcomes))
+	}
+	if outcomes[0].PredictionID != bedtime.ID || outcomes[0].Offset() != time.Hour {
+		t.Errorf("Expected bedtime an hour late, got %+v", outcomes[0])
+	}
+}
+
+func TestAdjustConfidence(t *testing.T) {
+	outcomes := func(predictionType domain.PredictionType, n int, offset time.Duration) []*domain.PredictionOutcome {
+		var result []*domain.PredictionOutcome
+		for i := 0; i < n; i++ {
+			result = append(result, &domain.PredictionOutcome{PredictionType: predictionType, PredictedTime: baseTime.Add(offset), ActualTime: baseTime})
+		}
+		return result
+	}
+
+	var history []*domain.PredictionOutcome
+	history = append(history, outcomes(domain.PredictionTypeNextFeed, 5, 45*time.Minute)...)
+	history = append(history, outcomes(domain.PredictionTypeNextNap, 5, -90*time.Minute)...)
+	history = append(history, outcomes(domain.PredictionTypeBedtime, 4, 3*time.Hour)...)
+	history = append(history, outcomes(domain.PredictionTypeNextWake, 5, 10*time.Minute)...)
+
+	predictions := []*domain.Prediction{
+		{PredictionType: domain.PredictionTypeNextFeed, Confidence: ptr(domain.PredictionConfidenceHigh)},
+		{PredictionType: domain.PredictionTypeNextFeed, Confidence: nil},
+		{PredictionType: domain.PredictionTypeNextNap, Confidence: ptr(domain.PredictionConfidenceHigh)},
+		{PredictionType: domain.PredictionTypeBedtime, Confidence: ptr(domain.PredictionConfidenceHigh)},
+		{PredictionType: domain.PredictionTypeNextWake, Confidence: ptr(domain.PredictionConfidenceMedium)},
+	}
+	AdjustConfidence(predictions, history)
+
+	want := []*domain.PredictionConfidence{
+		ptr(domain.PredictionConfidenceMedium), // 45m off: one level
+		nil,                                    // overdue predictions have no confidence to lower
+		ptr(domain.PredictionConfidenceLow),    // 90m off: two levels
+		ptr(domain.PredictionConfidenceHigh),   // too few outcomes to judge
+		ptr(domain.PredictionConfidenceMedium), // accurate
+	}
+	for i, p := range predictions {
+		if (p.Confidence == nil) != (want[i] == nil) || (p.Confidence != nil && *p.Confidence != *want[i]) {
+			t.Errorf("prediction %d (%s) confidence = %v, want %v", i, p.PredictionType, p.Confidence, want[i])
+		}
+	}
+}
+
+func TestScoreOffsets(t *testing.T) {
+	a := ScoreOffsets([]time.Duration{10 * time.Minute, -20 * time.Minute, 40 * time.Minute, 0})
+	if a.Count != 4 || a.MAE != 17*time.Minute+30*time.Second || a.Bias != 7*time.Minute+30*time.Second {
+		t.Errorf("ScoreOffsets = %+v, want 4 samples, MAE 17m30s, bias 7m30s", a)
+	}
+	if a.Within15 != 0.5 || a.Within30 != 0.75 {
+		t.Errorf("Within15, Within30 = %v, %v, want 0.5, 0.75", a.Within15, a.Within30)
+	}
+
+	if empty := ScoreOffsets(nil); empty.Count != 0 || empty.MAE != 0 {
+		t.Errorf("Expected an empty score, got %+v", empty)
+	}
+}
@@ -0,0 +1,246 @@
+package prediction
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// PatternType is a kind of feeding pattern that throws off interval estimates
+type PatternType string
+
+const (
+	// PatternClusterFeeding is several feeds close together, typically in the evening
+	PatternClusterFeeding PatternType = "cluster_feeding"
+	// PatternGrowthSpurt is a sustained rise in how often or how much the baby feeds
+	PatternGrowthSpurt PatternType = "growth_spurt"
+)
+
+const (
+	// A cluster feeding episode is at least clusterMinFeeds feeds, each within
+	// clusterMaxGap of the one before
+	clusterMinFeeds = 3
+	clusterMaxGap   = 75 * time.Minute
+	// clusterMaxExcludedShare is the most of a baby's feed intervals cluster feeding can
+	// leave out. Many newborns feed this often all day, and then it is their usual rhythm.
+	clusterMaxExcludedShare = 2.0 / 3
+
+	// A growth spurt is at least growthSpurtMinDays days in a row with growthSpurtThreshold
+	// more feeds or volume than the median of up to growthBaselineDays days before each,
+	// when there are at least growthMinBaselineDays of them
+	growthSpurtMinDays    = 2
+	growthSpurtThreshold  = 0.2
+	growthBaselineDays    = 7
+	growthMinBaselineDays = 3
+	// growthSpurtWeight is how much feed intervals during a growth spurt count toward the
+	// typical interval. Spurts pass within days, so they shouldn't replace the usual rhythm.
+	growthSpurtWeight = 0.5
+)
+
+// Pattern is a stretch of feeding that doesn't follow the baby's usual rhythm
+type Pattern struct {
+	Type PatternType
+	// Start and End bound the pattern. A cluster runs from its first feed to its last; a
+	// growth spurt covers whole days, up to now if it ran through yesterday.
+	Start time.Time
+	End   time.Time
+	// Feeds is how many feeds the pattern covers
+	Feeds int
+	// Increase is how much more a growth spurt's days had than usual, as a fraction: 0.3 is
+	// 30% more. It is zero for clusters.
+	Increase    float64
+	Description string
+}
+
+// OngoingAt reports whether the pattern is still going on at now. A cluster is, until
+// clusterMaxGap passes without another feed.
+func (p Pattern) OngoingAt(now time.Time) bool {
+	end := p.End
+	if p.Type == PatternClusterFeeding {
+		end = end.Add(clusterMaxGap)
+	}
+	return !now.Before(p.Start) && !now.After(end)
+}
+
+// DetectPatterns finds cluster feeding episodes and growth spurts in feeds, oldest first.
+// Days are split in timezone, and only days before now's are judged for growth spurts.
+func DetectPatterns(now time.Time, feeds []FeedRecord, timezone string) []Pattern {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return detectPatterns(now, feeds, loc)
+}
+
+func detectPatterns(now time.Time, feeds []FeedRecord, loc *time.Location) []Pattern {
+	var milkFeeds []FeedRecord
+	for _, f := range feeds {
+		if f.FeedType != nil && *f.FeedType == domain.FeedTypeSolids {
+			continue
+		}
+		milkFeeds = append(milkFeeds, f)
+	}
+	sort.Slice(milkFeeds, func(i, j int) bool {
+		return milkFeeds[i].StartTime.Before(milkFeeds[j].StartTime)
+	})
+
+	patterns := append(detectClusters(milkFeeds), detectGrowthSpurts(now, milkFeeds, loc)...)
+	sort.Slice(patterns, func(i, j int) bool {
+		return patterns[i].Start.Before(patterns[j].Start)
+	})
+	return patterns
+}
+
+// detectClusters finds runs of feeds each within clusterMaxGap of the one before.
+// feeds must be sorted oldest first.
+func detectClusters(feeds []FeedRecord) []Pattern {
+	var patterns []Pattern
+	runStart := 0
+	for i := 1; i <= len(feeds); i++ {
+		if i < len(feeds) && feeds[i].StartTime.Sub(feeds[i-1].StartTime) <= clusterMaxGap {
+			continue
+		}
+		if n := i - runStart; n >= clusterMinFeeds {
+			start, end := feeds[runStart].StartTime, feeds[i-1].StartTime
+			patterns = append(patterns, Pattern{
+				Type:        PatternClusterFeeding,
+				Start:       start,
+				End:         end,
+				Feeds:       n,
+				Description: fmt.Sprintf("%d feeds in %s", n, formatHours(end.Sub(start))),
+			})
+		}
+		runStart = i
+	}
+	return patterns
+}
+
+// feedDay totals one local day of feeds
+type feedDay struct {
+	start    time.Time
+	feeds    int
+	volumeMl int
+}
+
+// detectGrowthSpurts finds runs of days with notably more feeds or volume than the days
+// before them. feeds must be sorted oldest first.
+func detectGrowthSpurts(now time.Time, feeds []FeedRecord, loc *time.Location) []Pattern {
+	nowLocal := now.In(loc)
+	today := time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, loc)
+
+	// Whole days only: today isn't over yet
+	var days []feedDay
+	for _, f := range feeds {
+		t := f.StartTime.In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if !day.Before(today) {
+			break
+		}
+		if len(days) == 0 || !days[len(days)-1].start.Equal(day) {
+			days = append(days, feedDay{start: day})
+		}
+		days[len(days)-1].feeds++
+		if f.AmountMl != nil {
+			days[len(days)-1].volumeMl += *f.AmountMl
+		}
+	}
+
+	// How much more each day had than its baseline, or NaN without enough baseline
+	increases := make([]float64, len(days))
+	for i, day := range days {
+		baseline := days[max(i-growthBaselineDays, 0):i]
+		if len(baseline) < growthMinBaselineDays {
+			increases[i] = math.NaN()
+			continue
+		}
+
+		counts := make([]int, len(baseline))
+		volumes := make([]int, len(baseline))
+		for j, b := range baseline {
+			counts[j] = b.feeds
+			volumes[j] = b.volumeMl
+		}
+		increase := float64(day.feeds)/float64(medianInt(counts)) - 1
+		if usualVolume := medianInt(volumes); usualVolume > 0 {
+			increase = max(increase, float64(day.volumeMl)/float64(usualVolume)-1)
+		}
+		increases[i] = increase
+	}
+
+	var patterns []Pattern
+	runStart := -1
+	for i := 0; i <= len(days); i++ {
+		elevated := i < len(days) && increases[i] >= growthSpurtThreshold
+		consecutive := i > 0 && i < len(days) && days[i].start.Equal(days[i-1].start.AddDate(0, 0, 1))
+		if elevated && runStart >= 0 && consecutive {
+			continue
+		}
+
+		// The run so far, if any, ends here
+		if n := i - runStart; runStart >= 0 && n >= growthSpurtMinDays {
+			run := days[runStart:i]
+			pattern := Pattern{Type: PatternGrowthSpurt, Start: run[0].start, End: run[n-1].start.AddDate(0, 0, 1)}
+			for j, day := range run {
+				pattern.Feeds += day.feeds
+				pattern.Increase += increases[runStart+j] / float64(n)
+			}
+			if pattern.End.Equal(today) {
+				pattern.End = now
+			}
+			pattern.Description = fmt.Sprintf("Feeding %.0f%% more than usual for %d days", 100*pattern.Increase, n)
+			patterns = append(patterns, pattern)
+		}
+
+		runStart = -1
+		if elevated {
+			runStart = i
+		}
+	}
+	return patterns
+}
+
+// within reports whether a pattern of patternType covers from through to
+func within(patterns []Pattern, patternType PatternType, from, to time.Time) bool {
+	for _, p := range patterns {
+		if p.Type == patternType && !from.Before(p.Start) && !to.After(p.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotatePatterns notes cluster feeding and growth spurts ongoing at now on the reasoning
+// of upcoming and overdue next-feed predictions. Their intervals are already left out of
+// or down-weighted in the typical interval, so feeds may come sooner than predicted.
+func AnnotatePatterns(predictions []*domain.Prediction, patterns []Pattern, now time.Time) {
+	var notes []string
+	for _, p := range patterns {
+		if !p.OngoingAt(now) {
+			continue
+		}
+		switch p.Type {
+		case PatternClusterFeeding:
+			notes = append(notes, fmt.Sprintf("Cluster feeding (%s), so the next feed may come sooner", p.Description))
+		case PatternGrowthSpurt:
+			notes = append(notes, fmt.Sprintf("Possible growth spurt: %s", strings.ToLower(p.Description[:1])+p.Description[1:]))
+		}
+	}
+	if len(notes) == 0 {
+		return
+	}
+
+	for _, p := range predictions {
+		if p.PredictionType != domain.PredictionTypeNextFeed || p.Status == domain.PredictionStatusPlanned || p.Reasoning == nil {
+			continue
+		}
+		reasoning := *p.Reasoning
+		for _, note := range notes {
+			reasoning += ". " + note
+		}
+		p.Reasoning = &reasoning
+	}
+}
@@ -0,0 +1,151 @@
+package prediction
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// pst is testLoc's zone by name, for functions that take a timezone string
+const pst = "Etc/GMT+8"
+
+// feedsOnDay returns formula feeds of amountMl at each hour (fractions allowed) of the day
+// offset days from baseTime
+func feedsOnDay(offset int, amountMl int, hours ...float64) []FeedRecord {
+	day := time.Date(baseTime.Year(), baseTime.Month(), baseTime.Day()+offset, 0, 0, 0, 0, testLoc)
+	var feeds []FeedRecord
+	for _, h := range hours {
+		feeds = append(feeds, makeFeedAt(day.Add(time.Duration(h*float64(time.Hour))), domain.FeedTypeFormula, amountMl))
+	}
+	return feeds
+}
+
+func TestDetectPatterns_ClusterFeeding(t *testing.T) {
+	// Every 3 hours, then feeds 50 minutes apart through the evening
+	feeds := feedsOnDay(-1, 100, 7, 10, 13, 17, 17+50.0/60, 18+40.0/60, 19.5)
+
+	patterns := DetectPatterns(baseTime, feeds, pst)
+	if len(patterns) != 1 {
+		t.Fatalf("expected 1 pattern, got %d", len(patterns))
+	}
+	p := patterns[0]
+	if p.Type != PatternClusterFeeding || p.Feeds != 4 || p.Description != "4 feeds in 2.5hr" {
+		t.Errorf("expected a cluster of 4 feeds in 2.5hr, got %+v", p)
+	}
+	if p.OngoingAt(baseTime) {
+		t.Error("expected yesterday's cluster to be over")
+	}
+	if !p.OngoingAt(p.End.Add(time.Hour)) {
+		t.Error("expected the cluster to go on while feeds might still follow")
+	}
+}
+
+func TestGeneratePredictions_IgnoresClusterFeeds(t *testing.T) {
+	// Three days of 3 hourly feeds with an evening cluster 70 minutes apart, which would
+	// otherwise outnumber the usual intervals
+	var feeds []FeedRecord
+	for offset := -3; offset <= -1; offset++ {
+		feeds = append(feeds, feedsOnDay(offset, 100, 7, 10, 13, 16, 16+70.0/60, 18+20.0/60, 19.5, 20+40.0/60)...)
+	}
+	feeds = append(feeds, feedsOnDay(0, 100, 7, 10, 13)...)
+
+	for _, p := range GeneratePredictions(baseTime, feeds, nil, pst) {
+		if p.PredictionType != domain.PredictionTypeNextFeed || p.Status == domain.PredictionStatusPlanned {
+			continue
+		}
+		if want := baseTime.Add(2 * time.Hour); !p.PredictedTime.Equal(want) {
+			t.Errorf("expected the next feed 3 hours after the last, at %v, got %v", want, p.PredictedTime)
+		}
+		return
+	}
+	t.Fatal("expected a next feed prediction")
+}
+
+func TestGeneratePredictions_NewbornFeedsAreNotAllClusters(t *testing.T) {
+	// A newborn feeding every 60-70 minutes all day looks like one long cluster
+	var feeds []FeedRecord
+	for offset := -2; offset <= 0; offset++ {
+		for h := 7.0; h < 21; h += 65.0 / 60 {
+			if offset == 0 && h > 13.5 {
+				break
+			}
+			feeds = append(feeds, feedsOnDay(offset, 60, h)...)
+		}
+	}
+
+	patterns := DetectPatterns(baseTime, feeds, pst)
+	if len(patterns) == 0 || patterns[0].Type != PatternClusterFeeding {
+		t.Fatalf("expected the feeds to be detected as clusters, got %+v", patterns)
+	}
+	if observations := feedIntervalObservations(feeds, testLoc, patterns); len(observations) != len(feedIntervalObservations(feeds, testLoc, nil)) {
+		t.Errorf("expected every interval to be kept, got %d", len(observations))
+	}
+
+	last := feeds[len(feeds)-1].StartTime
+	for _, p := range GeneratePredictions(baseTime, feeds, nil, pst) {
+		if p.PredictionType != domain.PredictionTypeNextFeed || p.Status == domain.PredictionStatusPlanned {
+			continue
+		}
+		if want := last.Add(65 * time.Minute); !p.PredictedTime.Equal(want) {
+			t.Errorf("expected the next feed 65 minutes after the last, at %v, got %v", want, p.PredictedTime)
+		}
+		return
+	}
+	t.Fatal("expected a next feed prediction")
+}
+
+func TestDetectPatterns_GrowthSpurt(t *testing.T) {
+	feedHours := []float64{6, 8, 10, 12, 14, 16, 18, 20}
+	var feeds []FeedRecord
+	for offset := -9; offset <= -3; offset++ {
+		amountMl := 100
+		if offset == -6 {
+			// A day of bigger feeds alone isn't a spurt
+			amountMl = 130
+		}
+		feeds = append(feeds, feedsOnDay(offset, amountMl, feedHours...)...)
+	}
+	// Two days in a row of 30% more is
+	feeds = append(feeds, feedsOnDay(-2, 130, feedHours...)...)
+	feeds = append(feeds, feedsOnDay(-1, 130, feedHours...)...)
+
+	patterns := DetectPatterns(baseTime, feeds, pst)
+	if len(patterns) != 1 {
+		t.Fatalf("expected 1 pattern, got %+v", patterns)
+	}
+	p := patterns[0]
+	if p.Type != PatternGrowthSpurt || p.Feeds != 16 || p.Increase < 0.29 || p.Increase > 0.31 {
+		t.Errorf("expected a growth spurt of 16 feeds, 30%% more than usual, got %+v", p)
+	}
+	if p.Description != "Feeding 30% more than usual for 2 days" {
+		t.Errorf("unexpected description: %s", p.Description)
+	}
+	if !p.End.Equal(baseTime) || !p.OngoingAt(baseTime) {
+		t.Errorf("expected a spurt through yesterday to last until now, got end %v", p.End)
+	}
+
+	// Intervals during the spurt count for less
+	observations := feedIntervalObservations(feeds, testLoc, patterns)
+	if first, last := observations[0], observations[len(observations)-1]; first.weight() != 1 || last.weight() != growthSpurtWeight {
+		t.Errorf("expected weights 1 before the spurt and %v during it, got %v and %v", growthSpurtWeight, first.weight(), last.weight())
+	}
+}
+
+func TestAnnotatePatterns(t *testing.T) {
+	cluster := Pattern{Type: PatternClusterFeeding, Start: baseTime.Add(-2 * time.Hour), End: baseTime.Add(-30 * time.Minute), Feeds: 3, Description: "3 feeds in 1.5hr"}
+	over := Pattern{Type: PatternGrowthSpurt, Start: baseTime.AddDate(0, 0, -5), End: baseTime.AddDate(0, 0, -3), Description: "Feeding 25% more than usual for 2 days"}
+	upcoming := &domain.Prediction{PredictionType: domain.PredictionTypeNextFeed, Status: domain.PredictionStatusUpcoming, Reasoning: ptr("Based on 3.0hr median feed interval from last 12 feeds")}
+	planned := &domain.Prediction{PredictionType: domain.PredictionTypeNextFeed, Status: domain.PredictionStatusPlanned, Reasoning: ptr("Chained: based on 3.0hr median feed interval")}
+	nap := &domain.Prediction{PredictionType: domain.PredictionTypeNextNap, Status: domain.PredictionStatusUpcoming, Reasoning: ptr("Based on 2.0hr median wake window from last 8 naps")}
+
+	AnnotatePatterns([]*domain.Prediction{upcoming, planned, nap}, []Pattern{over, cluster}, baseTime)
+
+	if want := "Based on 3.0hr median feed interval from last 12 feeds. Cluster feeding (3 feeds in 1.5hr), so the next feed may come sooner"; *upcoming.Reasoning != want {
+		t.Errorf("expected %q, got %q", want, *upcoming.Reasoning)
+	}
+	if strings.Contains(*planned.Reasoning, "Cluster") || strings.Contains(*nap.Reasoning, "Cluster") {
+		t.Error("expected only the upcoming feed to be annotated")
+	}
+}
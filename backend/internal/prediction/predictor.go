@@ -0,0 +1,186 @@
+package prediction
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// DefaultHalfLife is how quickly RecencyWeightedPredictor forgets old observations when
+// the family hasn't chosen a half-life
+const DefaultHalfLife = 48 * time.Hour
+
+// Observation is one value the engine predicts from, like a feed interval or a feed
+// amount, with the time it was observed
+type Observation struct {
+	At    time.Time
+	Value float64
+	// Weight is how much the observation counts next to the others. Zero counts as 1.
+	Weight float64
+}
+
+func (o Observation) weight() float64 {
+	if o.Weight == 0 {
+		return 1
+	}
+	return o.Weight
+}
+
+// Predictor estimates the typical value of a series of observations. The engine uses it
+// for feed intervals, wake windows and feed amounts.
+type Predictor interface {
+	// Estimate returns the typical value of observations as of now. observations is never
+	// empty.
+	Estimate(now time.Time, observations []Observation) float64
+	// Describe names the estimate in reasoning text, like "median"
+	Describe() string
+}
+
+// MedianPredictor takes the median, weighing old observations the same as recent ones. It
+// is robust to the odd outlier, but lags behind a schedule that is changing.
+type MedianPredictor struct{}
+
+func (MedianPredictor) Estimate(_ time.Time, observations []Observation) float64 {
+	sorted := slices.Clone(observations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Value < sorted[j].Value })
+
+	var total float64
+	for _, o := range sorted {
+		total += o.weight()
+	}
+
+	// The first value past half the total weight; between two values that split it exactly
+	var cumulative float64
+	for i, o := range sorted {
+		cumulative += o.weight()
+		if cumulative > total/2 {
+			return o.Value
+		}
+		if cumulative == total/2 && i+1 < len(sorted) {
+			return (o.Value + sorted[i+1].Value) / 2
+		}
+	}
+	return sorted[len(sorted)-1].Value
+}
+
+func (MedianPredictor) Describe() string { return "median" }
+
+// RecencyWeightedPredictor averages observations weighted by how recent they are: one
+// HalfLife old counts half as much as one observed now. It follows growth spurts and nap
+// transitions within a day or two.
+type RecencyWeightedPredictor struct {
+	HalfLife time.Duration
+}
+
+func (p RecencyWeightedPredictor) Estimate(now time.Time, observations []Observation) float64 {
+	halfLife := p.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultHalfLife
+	}
+
+	var weightedSum, totalWeight float64
+	for _, o := range observations {
+		age := max(now.Sub(o.At), 0)
+		weight := o.weight() * math.Exp2(-float64(age)/float64(halfLife))
+		weightedSum += weight * o.Value
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		// Everything is too old to weigh anything; fall back to an equal weighting
+		return MedianPredictor{}.Estimate(now, observations)
+	}
+	return weightedSum / totalWeight
+}
+
+func (RecencyWeightedPredictor) Describe() string { return "recency-weighted" }
+
+// PredictorFor returns the predictor a family has chosen
+func PredictorFor(family *domain.Family) Predictor {
+	if family != nil && family.PredictionModel == domain.PredictionModelRecencyWeighted {
+		return RecencyWeightedPredictor{HalfLife: family.PredictionHalfLife}
+	}
+	return MedianPredictor{}
+}
+
+// estimateDuration estimates a duration from observations of its length
+func estimateDuration(p Predictor, now time.Time, observations []Observation) time.Duration {
+	return time.Duration(math.Round(p.Estimate(now, observations)))
+}
+
+// durationObservations returns the lengths of observations as durations
+func durationObservations(observations []Observation) []time.Duration {
+	durations := make([]time.Duration, len(observations))
+	for i, o := range observations {
+		durations[i] = time.Duration(o.Value)
+	}
+	return durations
+}
+
+// feedIntervalObservations returns the daytime feed intervals the engine predicts from,
+// each observed at the feed that ended it. Intervals outside minFeedInterval and
+// maxFeedInterval are left out, as are those within a cluster feeding episode unless they
+// are more than clusterMaxExcludedShare of all intervals. Those during a growth spurt count
+// for growthSpurtWeight.
+func feedIntervalObservations(feeds []FeedRecord, loc *time.Location, patterns []Pattern) []Observation {
+	sorted := filterDaytimeFeeds(feeds, loc)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+
+	var observations []Observation
+	var clustered []bool
+	excluded := 0
+	for i := 1; i < len(sorted); i++ {
+		interval := sorted[i].StartTime.Sub(sorted[i-1].StartTime)
+		if interval < minFeedInterval || interval > maxFeedInterval {
+			continue
+		}
+		observation := Observation{At: sorted[i].StartTime, Value: float64(interval)}
+		if within(patterns, PatternGrowthSpurt, sorted[i-1].StartTime, sorted[i].StartTime) {
+			observation.Weight = growthSpurtWeight
+		}
+		inCluster := within(patterns, PatternClusterFeeding, sorted[i-1].StartTime, sorted[i].StartTime)
+		if inCluster {
+			excluded++
+		}
+		observations = append(observations, observation)
+		clustered = append(clustered, inCluster)
+	}
+	if float64(excluded) > clusterMaxExcludedShare*float64(len(observations)) {
+		return observations
+	}
+
+	kept := observations[:0]
+	for i, o := range observations {
+		if !clustered[i] {
+			kept = append(kept, o)
+		}
+	}
+	return kept
+}
+
+// wakeWindowObservations returns the wake windows between naps the engine predicts from,
+// each observed at the nap that ended it. Windows outside minWakeWindow and maxWakeWindow
+// are left out.
+func wakeWindowObservations(naps []SleepRecord) []Observation {
+	sorted := make([]SleepRecord, len(naps))
+	copy(sorted, naps)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+
+	var observations []Observation
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].EndTime == nil {
+			continue
+		}
+		window := sorted[i].StartTime.Sub(*sorted[i-1].EndTime)
+		if window >= minWakeWindow && window <= maxWakeWindow {
+			observations = append(observations, Observation{At: sorted[i].StartTime, Value: float64(window)})
+		}
+	}
+	return observations
+}
@@ -0,0 +1,74 @@
+package prediction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func TestMedianPredictor(t *testing.T) {
+	observations := []Observation{
+		{At: baseTime.Add(-time.Hour), Value: 4},
+		{At: baseTime.Add(-48 * time.Hour), Value: 1},
+		{At: baseTime.Add(-24 * time.Hour), Value: 2},
+	}
+	if got := (MedianPredictor{}).Estimate(baseTime, observations); got != 2 {
+		t.Errorf("expected 2, got %v", got)
+	}
+	if got := (MedianPredictor{}).Estimate(baseTime, observations[:2]); got != 2.5 {
+		t.Errorf("expected 2.5, got %v", got)
+	}
+}
+
+func TestRecencyWeightedPredictor(t *testing.T) {
+	p := RecencyWeightedPredictor{HalfLife: 24 * time.Hour}
+
+	// An observation one half-life old counts half as much as one from now
+	observations := []Observation{
+		{At: baseTime, Value: 240},
+		{At: baseTime.Add(-24 * time.Hour), Value: 180},
+	}
+	if got := p.Estimate(baseTime, observations); got != 220 {
+		t.Errorf("expected 220, got %v", got)
+	}
+
+	// Observations far older than the half-life still count when there's nothing newer
+	old := []Observation{{At: baseTime.AddDate(-1, 0, 0), Value: 180}}
+	if got := p.Estimate(baseTime, old); got != 180 {
+		t.Errorf("expected 180, got %v", got)
+	}
+}
+
+func TestPredictorFor(t *testing.T) {
+	if _, ok := PredictorFor(nil).(MedianPredictor); !ok {
+		t.Error("expected the median without a family")
+	}
+	if _, ok := PredictorFor(&domain.Family{}).(MedianPredictor); !ok {
+		t.Error("expected the median by default")
+	}
+
+	p, ok := PredictorFor(&domain.Family{PredictionModel: domain.PredictionModelRecencyWeighted, PredictionHalfLife: 12 * time.Hour}).(RecencyWeightedPredictor)
+	if !ok || p.HalfLife != 12*time.Hour {
+		t.Errorf("expected recency weighting with a 12h half-life, got %#v", p)
+	}
+}
+
+func TestGeneratePredictionsWith_ReasoningNamesPredictor(t *testing.T) {
+	feeds := []FeedRecord{
+		makeFeed(0.5, domain.FeedTypeFormula, 100),
+		makeFeed(3.5, domain.FeedTypeFormula, 100),
+		makeFeed(6.5, domain.FeedTypeFormula, 100),
+	}
+
+	result := GeneratePredictionsWith(RecencyWeightedPredictor{}, baseTime, feeds, nil, "America/Los_Angeles")
+	for _, p := range result {
+		if p.PredictionType == domain.PredictionTypeNextFeed && p.Status == domain.PredictionStatusPlanned {
+			if want := "Chained: based on 3.0hr recency-weighted feed interval"; *p.Reasoning != want {
+				t.Errorf("expected %q, got %q", want, *p.Reasoning)
+			}
+			return
+		}
+	}
+	t.Fatal("expected a chained feed prediction")
+}
@@ -0,0 +1,121 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// RecentRecordLimit is how many feeds and sleeps are loaded to generate predictions.
+const RecentRecordLimit = 200
+
+// Refresh regenerates a baby's prediction timeline from their recent feeds, sleeps,
+// schedule goals and the norms for their age, with the family's prediction model, and
+// persists it in place of the previous one. Confidence reflects how accurate the baby's
+// recent predictions turned out to be.
+func Refresh(ctx context.Context, s store.Store, familyID, babyID uuid.UUID, now time.Time, timezone string) ([]*domain.Prediction, error) {
+	feedDetails, err := s.GetRecentFeedDetailsForBaby(ctx, babyID, RecentRecordLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed details: %w", err)
+	}
+
+	sleepDetails, err := s.GetRecentSleepDetailsForBaby(ctx, babyID, RecentRecordLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sleep details: %w", err)
+	}
+
+	feeds := FeedRecords(feedDetails)
+	sleeps := SleepRecords(sleepDetails)
+
+	// Fetch schedule goals for blending
+	goals, err := s.GetScheduleGoals(ctx, babyID)
+	if err != nil {
+		// Non-fatal: proceed without goals
+		goals = nil
+	}
+
+	// Norms for the baby's age, if their birth date is known
+	var norms *AgeNorms
+	if baby, err := s.GetBabyByID(ctx, babyID); err == nil {
+		norms = NormsForAge(baby.BirthDate, now)
+	}
+
+	// The family's choice of prediction model; median if it can't be loaded
+	family, err := s.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		family = nil
+	}
+
+	predictions := GeneratePredictionsWith(PredictorFor(family), now, feeds, sleeps, timezone)
+
+	// Pull predictions from sparse data toward what's typical at the baby's age
+	predictions = BlendAgeNorms(now, predictions, norms, feeds, sleeps, timezone)
+
+	// If no data-driven predictions but goals exist, generate goal-only predictions
+	if len(predictions) == 0 && goals != nil {
+		predictions = GenerateGoalOnlyPredictions(now, goals, timezone)
+	}
+
+	// Fill in from age norms whatever neither data nor goals predict yet
+	predictions = append(predictions, GenerateAgeNormPredictions(now, norms, predictions, feeds, sleeps, timezone)...)
+
+	// Blend predictions with schedule goals
+	if goals != nil && len(predictions) > 0 {
+		predictions = BlendPredictions(predictions, goals, len(feeds), len(sleeps))
+	}
+
+	// Lower confidence where the baby's recent predictions have been far off
+	outcomes, err := s.GetPredictionOutcomesForBaby(ctx, babyID, now.Add(-AccuracyWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prediction outcomes: %w", err)
+	}
+	AdjustConfidence(predictions, outcomes)
+
+	// Note cluster feeding and growth spurts the next feed may not follow
+	AnnotatePatterns(predictions, DetectPatterns(now, feeds, timezone), now)
+
+	// Set family and baby IDs on all predictions
+	for _, p := range predictions {
+		p.FamilyID = familyID
+		p.BabyID = babyID
+	}
+
+	if len(predictions) > 0 {
+		if err := s.UpsertPredictions(ctx, babyID, predictions); err != nil {
+			return nil, fmt.Errorf("failed to save predictions: %w", err)
+		}
+	}
+
+	return predictions, nil
+}
+
+// FeedRecords converts stored feed details to prediction engine input
+func FeedRecords(details []*domain.FeedDetails) []FeedRecord {
+	feeds := make([]FeedRecord, 0, len(details))
+	for _, fd := range details {
+		feeds = append(feeds, FeedRecord{
+			StartTime: fd.StartTime,
+			EndTime:   fd.EndTime,
+			AmountMl:  fd.AmountMl,
+			FeedType:  fd.FeedType,
+		})
+	}
+	return feeds
+}
+
+// SleepRecords converts stored sleep details to prediction engine input
+func SleepRecords(details []*domain.SleepDetails) []SleepRecord {
+	sleeps := make([]SleepRecord, 0, len(details))
+	for _, sd := range details {
+		sleeps = append(sleeps, SleepRecord{
+			StartTime:       sd.StartTime,
+			EndTime:         sd.EndTime,
+			DurationMinutes: sd.DurationMinutes,
+		})
+	}
+	return sleeps
+}
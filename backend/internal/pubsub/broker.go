@@ -0,0 +1,95 @@
+package pubsub
+
+import (
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies what changed in a family's care data.
+type EventType string
+
+const (
+	EventCareSessionUpdated EventType = "care_session_updated"
+	EventActivityAdded      EventType = "activity_added"
+	EventPredictionsChanged EventType = "predictions_changed"
+)
+
+// Event is a change notification scoped to a single family. It carries IDs only;
+// subscribers reload the current state from the store so they never see stale payloads.
+type Event struct {
+	Type          EventType
+	FamilyID      uuid.UUID
+	CareSessionID uuid.UUID
+	ActivityID    uuid.UUID
+	BabyID        uuid.UUID
+}
+
+// subscriberBufferSize bounds how far a slow subscriber can fall behind before
+// events are dropped for it.
+const subscriberBufferSize = 32
+
+// Broker is an in-process publish/subscribe hub keyed by family ID.
+// It is safe for concurrent use.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan Event]struct{}
+}
+
+// NewBroker creates an empty broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[uuid.UUID]map[chan Event]struct{}),
+	}
+}
+
+// Subscribe registers a listener for a family's events. The returned cancel func
+// unregisters the listener and closes the channel; it is safe to call more than once.
+func (b *Broker) Subscribe(familyID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[familyID] == nil {
+		b.subscribers[familyID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[familyID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[familyID], ch)
+			if len(b.subscribers[familyID]) == 0 {
+				delete(b.subscribers, familyID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
+
+// Publish delivers an event to every subscriber of event.FamilyID without blocking.
+// Subscribers whose buffer is full miss the event.
+func (b *Broker) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[event.FamilyID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("pubsub: dropping %s event for family %s (subscriber buffer full)", event.Type, event.FamilyID)
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers for a family.
+func (b *Broker) SubscriberCount(familyID uuid.UUID) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[familyID])
+}
@@ -0,0 +1,135 @@
+package pubsub
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func receive(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed unexpectedly")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestPublish_DeliversToFamilySubscribers(t *testing.T) {
+	b := NewBroker()
+	familyID := uuid.New()
+
+	ch1, cancel1 := b.Subscribe(familyID)
+	defer cancel1()
+	ch2, cancel2 := b.Subscribe(familyID)
+	defer cancel2()
+
+	sessionID := uuid.New()
+	b.Publish(Event{Type: EventCareSessionUpdated, FamilyID: familyID, CareSessionID: sessionID})
+
+	for _, ch := range []<-chan Event{ch1, ch2} {
+		e := receive(t, ch)
+		if e.Type != EventCareSessionUpdated {
+			t.Errorf("Type = %s, want %s", e.Type, EventCareSessionUpdated)
+		}
+		if e.CareSessionID != sessionID {
+			t.Errorf("CareSessionID = %s, want %s", e.CareSessionID, sessionID)
+		}
+	}
+}
+
+func TestPublish_ScopedToFamily(t *testing.T) {
+	b := NewBroker()
+	familyA := uuid.New()
+	familyB := uuid.New()
+
+	chA, cancelA := b.Subscribe(familyA)
+	defer cancelA()
+	chB, cancelB := b.Subscribe(familyB)
+	defer cancelB()
+
+	b.Publish(Event{Type: EventActivityAdded, FamilyID: familyA})
+
+	receive(t, chA)
+	select {
+	case e := <-chB:
+		t.Fatalf("family B received event for family A: %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCancel_ClosesChannelAndUnregisters(t *testing.T) {
+	b := NewBroker()
+	familyID := uuid.New()
+
+	ch, cancel := b.Subscribe(familyID)
+	if got := b.SubscriberCount(familyID); got != 1 {
+		t.Fatalf("SubscriberCount = %d, want 1", got)
+	}
+
+	cancel()
+	cancel() // idempotent
+
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed")
+	}
+	if got := b.SubscriberCount(familyID); got != 0 {
+		t.Errorf("SubscriberCount = %d, want 0", got)
+	}
+
+	// Publishing with no subscribers must not panic
+	b.Publish(Event{Type: EventPredictionsChanged, FamilyID: familyID})
+}
+
+func TestPublish_DoesNotBlockOnSlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	familyID := uuid.New()
+
+	_, cancel := b.Subscribe(familyID)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriberBufferSize*2; i++ {
+			b.Publish(Event{Type: EventActivityAdded, FamilyID: familyID})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber buffer")
+	}
+}
+
+func TestBroker_ConcurrentUse(t *testing.T) {
+	b := NewBroker()
+	familyID := uuid.New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ch, cancel := b.Subscribe(familyID)
+			defer cancel()
+			select {
+			case <-ch:
+			case <-time.After(10 * time.Millisecond):
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			b.Publish(Event{Type: EventCareSessionUpdated, FamilyID: familyID})
+		}()
+	}
+	wg.Wait()
+}
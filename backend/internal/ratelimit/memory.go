@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps counters in process memory. Counters are lost on restart and not
+// shared between instances, so use a shared backend when running more than one.
+type MemoryBackend struct {
+	mu       sync.Mutex
+	counters map[string]counter
+}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+// Ensure MemoryBackend implements Backend
+var _ Backend = (*MemoryBackend)(nil)
+
+// NewMemoryBackend creates an empty in-memory backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{counters: make(map[string]counter)}
+}
+
+// Hit adds one to key's counter
+func (b *MemoryBackend) Hit(ctx context.Context, key string, window time.Duration, now time.Time) (int, time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		c = counter{resetAt: now.Add(window)}
+	}
+	c.count++
+	b.counters[key] = c
+
+	return c.count, c.resetAt, nil
+}
+
+// Peek returns key's live counter
+func (b *MemoryBackend) Peek(ctx context.Context, key string, now time.Time) (int, time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		return 0, time.Time{}, nil
+	}
+	return c.count, c.resetAt, nil
+}
+
+// Reset deletes key's counter
+func (b *MemoryBackend) Reset(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.counters, key)
+	return nil
+}
+
+// Prune deletes expired counters
+func (b *MemoryBackend) Prune(ctx context.Context, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, c := range b.counters {
+		if !now.Before(c.resetAt) {
+			delete(b.counters, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package ratelimit_test
+
+import (
+	"testing"
+
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit/ratelimittest"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ratelimittest.Run(t, ratelimit.NewMemoryBackend())
+}
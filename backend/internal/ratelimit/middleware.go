@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCodeRateLimited is the GraphQL error extension code for a rate-limited request.
+const ErrCodeRateLimited = "RATE_LIMITED"
+
+type contextKey string
+
+const clientIPKey contextKey = "clientIp"
+
+// ClientIP returns the client IP stored by Middleware, or "" if there is none.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// WithClientIP returns a context carrying ip as the client IP.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// Middleware stores each request's client IP in its context and caps requests per IP
+// with the ActionRequest policy. Rejected requests get a 429 with a Retry-After header
+// and a GraphQL-shaped error body. If trustProxy is set, the client IP is taken from
+// the last X-Forwarded-For entry, which is the one added by the proxy in front of us.
+func Middleware(l *Limiter, trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := requestIP(r, trustProxy)
+			ctx := WithClientIP(r.Context(), ip)
+
+			err := l.Allow(ctx, ActionRequest, IP(ip))
+			if limitErr, ok := err.(*LimitError); ok {
+				writeLimitError(w, limitErr, l.now())
+				return
+			}
+			if err != nil {
+				// Fail open: an unavailable backend shouldn't take the API down with it
+				log.Printf("rate limiter: %v", err)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func requestIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			hops := strings.Split(forwarded, ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeLimitError(w http.ResponseWriter, limitErr *LimitError, now time.Time) {
+	retryAfter := int(limitErr.RetryAfter(now).Seconds())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]any{{
+			"message":    limitErr.Error(),
+			"extensions": Extensions(limitErr, now),
+		}},
+	})
+}
+
+// Extensions returns the GraphQL error extensions describing limitErr, telling the
+// client when it may retry.
+func Extensions(limitErr *LimitError, now time.Time) map[string]any {
+	return map[string]any{
+		"code":              ErrCodeRateLimited,
+		"scope":             string(limitErr.Scope),
+		"lockedOut":         limitErr.LockedOut,
+		"retryAt":           limitErr.RetryAt.Format(time.RFC3339),
+		"retryAfterSeconds": int(limitErr.RetryAfter(now).Seconds()),
+	}
+}
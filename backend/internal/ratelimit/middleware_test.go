@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware_RejectsOverLimit(t *testing.T) {
+	l, _ := newTestLimiter(map[Action]Policy{
+		ActionRequest: {Limits: map[Scope]Limit{ScopeIP: {Attempts: 1, Window: time.Minute}}},
+	})
+
+	var gotIP string
+	handler := Middleware(l, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIP = ClientIP(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = "203.0.113.7:5123"
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || gotIP != "203.0.113.7" {
+		t.Fatalf("expected first request through with its IP, got %d and %q", rec.Code, gotIP)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q, want 60", rec.Header().Get("Retry-After"))
+	}
+
+	var body struct {
+		Errors []struct {
+			Extensions map[string]any `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Errors) != 1 {
+		t.Fatalf("expected a GraphQL error body, got %v", err)
+	}
+	if ext := body.Errors[0].Extensions; ext["code"] != ErrCodeRateLimited || ext["retryAfterSeconds"] != float64(60) {
+		t.Errorf("unexpected extensions: %v", ext)
+	}
+}
+
+func TestRequestIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = "10.0.0.1:5123"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+
+	if got := requestIP(req, false); got != "10.0.0.1" {
+		t.Errorf("without a trusted proxy, got %q", got)
+	}
+	if got := requestIP(req, true); got != "203.0.113.7" {
+		t.Errorf("with a trusted proxy, got %q", got)
+	}
+}
@@ -0,0 +1,281 @@
+// Package ratelimit throttles unauthenticated endpoints that can be used to guess family
+// passwords, invite codes or family names. Attempts are counted per subject (client IP,
+// device and family name) in fixed windows, and repeated failures lock a subject out for
+// progressively longer.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// Backend stores the limiter's counters. Counters live for a fixed window from their
+// first hit; a counter whose window has ended counts as zero.
+type Backend interface {
+	// Hit adds one to key's counter, starting a new window of length window if it has
+	// none live at now, and returns the new count and when the window ends.
+	Hit(ctx context.Context, key string, window time.Duration, now time.Time) (int, time.Time, error)
+
+	// Peek returns key's count and window end without changing them, or zero if it has
+	// no live counter at now.
+	Peek(ctx context.Context, key string, now time.Time) (int, time.Time, error)
+
+	// Reset deletes key's counter.
+	Reset(ctx context.Context, key string) error
+
+	// Prune deletes every counter whose window ended before now.
+	Prune(ctx context.Context, now time.Time) error
+}
+
+// Action names a rate-limited operation. Each action has its own counters and policy.
+type Action string
+
+const (
+	ActionJoinFamily           Action = "joinFamily"
+	ActionJoinFamilyWithInvite Action = "joinFamilyWithInvite"
+	ActionCheckFamilyName      Action = "checkFamilyNameAvailable"
+	ActionRequest              Action = "request"
+)
+
+// Scope is the kind of subject an attempt is counted against.
+type Scope string
+
+const (
+	ScopeIP     Scope = "ip"
+	ScopeDevice Scope = "device"
+	ScopeFamily Scope = "family"
+)
+
+// Subject is one thing an attempt is counted against, e.g. the client's IP.
+type Subject struct {
+	Scope Scope
+	Value string
+}
+
+// IP, Device and Family build subjects. Family names are case-insensitive like the
+// names themselves.
+func IP(ip string) Subject           { return Subject{Scope: ScopeIP, Value: ip} }
+func Device(deviceID string) Subject { return Subject{Scope: ScopeDevice, Value: deviceID} }
+func Family(name string) Subject {
+	return Subject{Scope: ScopeFamily, Value: strings.ToLower(strings.TrimSpace(name))}
+}
+
+// Limit allows Attempts per Window.
+type Limit struct {
+	Attempts int
+	Window   time.Duration
+}
+
+// Lockout locks a subject out once it has failed Threshold times within Window. The
+// first lockout lasts Base and each further failure doubles it, up to Max.
+type Lockout struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+}
+
+// Policy is an action's limits per scope and its lockout. Scopes without a limit are
+// not counted; a zero Lockout disables lockouts.
+type Policy struct {
+	Limits  map[Scope]Limit
+	Lockout Lockout
+}
+
+// DefaultPolicies are the policies used when none are configured.
+var DefaultPolicies = map[Action]Policy{
+	ActionJoinFamily: {
+		Limits: map[Scope]Limit{
+			ScopeIP:     {Attempts: 20, Window: 10 * time.Minute},
+			ScopeDevice: {Attempts: 10, Window: 10 * time.Minute},
+			ScopeFamily: {Attempts: 10, Window: 10 * time.Minute},
+		},
+		Lockout: Lockout{Threshold: 5, Base: time.Minute, Max: time.Hour, Window: 24 * time.Hour},
+	},
+	ActionJoinFamilyWithInvite: {
+		Limits: map[Scope]Limit{
+			ScopeIP:     {Attempts: 20, Window: 10 * time.Minute},
+			ScopeDevice: {Attempts: 10, Window: 10 * time.Minute},
+		},
+		Lockout: Lockout{Threshold: 5, Base: time.Minute, Max: time.Hour, Window: 24 * time.Hour},
+	},
+	ActionCheckFamilyName: {
+		Limits: map[Scope]Limit{
+			ScopeIP:     {Attempts: 30, Window: 10 * time.Minute},
+			ScopeDevice: {Attempts: 30, Window: 10 * time.Minute},
+		},
+	},
+	ActionRequest: {
+		Limits: map[Scope]Limit{
+			ScopeIP: {Attempts: 600, Window: time.Minute},
+		},
+	},
+}
+
+// LimitError is returned when a subject is over its limit or locked out.
+type LimitError struct {
+	Action    Action
+	Scope     Scope
+	RetryAt   time.Time
+	LockedOut bool
+}
+
+func (e *LimitError) Error() string {
+	if e.LockedOut {
+		return fmt.Sprintf("too many failed %s attempts; locked out until %s", e.Action, e.RetryAt.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("too many %s attempts; retry after %s", e.Action, e.RetryAt.Format(time.RFC3339))
+}
+
+// RetryAfter returns how long the client should wait from now, rounded up to a second.
+func (e *LimitError) RetryAfter(now time.Time) time.Duration {
+	wait := e.RetryAt.Sub(now)
+	if wait <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(wait.Seconds())) * time.Second
+}
+
+// Limiter applies policies to attempts.
+type Limiter struct {
+	backend  Backend
+	policies map[Action]Policy
+	now      func() time.Time
+}
+
+// NewLimiter creates a limiter storing its counters in backend. A nil policies map uses
+// DefaultPolicies.
+func NewLimiter(backend Backend, policies map[Action]Policy) *Limiter {
+	if policies == nil {
+		policies = DefaultPolicies
+	}
+	return &Limiter{
+		backend:  backend,
+		policies: policies,
+		now:      time.Now,
+	}
+}
+
+// Allow counts an attempt at action by each subject. It returns a *LimitError if any
+// subject is locked out or the attempt takes it over its limit; the error's RetryAt is the
+// latest time any of them frees up. Subjects with an empty value are skipped.
+func (l *Limiter) Allow(ctx context.Context, action Action, subjects ...Subject) error {
+	policy := l.policies[action]
+	now := l.now()
+
+	var limitErr *LimitError
+	block := func(scope Scope, retryAt time.Time, lockedOut bool) {
+		if limitErr == nil || retryAt.After(limitErr.RetryAt) {
+			limitErr = &LimitError{Action: action, Scope: scope, RetryAt: retryAt, LockedOut: lockedOut}
+		}
+	}
+
+	// Locked out subjects are rejected without counting the attempt
+	for _, subject := range subjects {
+		if subject.Value == "" || policy.Lockout.Threshold == 0 {
+			continue
+		}
+		locked, until, err := l.backend.Peek(ctx, key("lock", action, subject), now)
+		if err != nil {
+			return fmt.Errorf("failed to check lockout: %w", err)
+		}
+		if locked > 0 {
+			block(subject.Scope, until, true)
+		}
+	}
+	if limitErr != nil {
+		return limitErr
+	}
+
+	for _, subject := range subjects {
+		limit, ok := policy.Limits[subject.Scope]
+		if !ok || subject.Value == "" {
+			continue
+		}
+		count, resetAt, err := l.backend.Hit(ctx, key("hit", action, subject), limit.Window, now)
+		if err != nil {
+			return fmt.Errorf("failed to count attempt: %w", err)
+		}
+		if count > limit.Attempts {
+			block(subject.Scope, resetAt, false)
+		}
+	}
+	if limitErr != nil {
+		return limitErr
+	}
+	return nil
+}
+
+// Fail records a failed attempt at action by each subject, locking out any that reach
+// the policy's failure threshold.
+func (l *Limiter) Fail(ctx context.Context, action Action, subjects ...Subject) error {
+	lockout := l.policies[action].Lockout
+	if lockout.Threshold == 0 {
+		return nil
+	}
+	now := l.now()
+
+	for _, subject := range subjects {
+		if subject.Value == "" {
+			continue
+		}
+		failures, _, err := l.backend.Hit(ctx, key("fail", action, subject), lockout.Window, now)
+		if err != nil {
+			return fmt.Errorf("failed to record failure: %w", err)
+		}
+		if failures < lockout.Threshold {
+			continue
+		}
+		if _, _, err := l.backend.Hit(ctx, key("lock", action, subject), lockout.duration(failures), now); err != nil {
+			return fmt.Errorf("failed to lock out: %w", err)
+		}
+	}
+	return nil
+}
+
+// Succeed clears each subject's failures for action.
+func (l *Limiter) Succeed(ctx context.Context, action Action, subjects ...Subject) error {
+	for _, subject := range subjects {
+		if subject.Value == "" {
+			continue
+		}
+		if err := l.backend.Reset(ctx, key("fail", action, subject)); err != nil {
+			return fmt.Errorf("failed to clear failures: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run prunes expired counters every interval until ctx is cancelled.
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := l.backend.Prune(ctx, now); err != nil {
+				log.Printf("rate limiter: failed to prune counters: %v", err)
+			}
+		}
+	}
+}
+
+// duration returns how long the given number of failures locks a subject out
+func (lo Lockout) duration(failures int) time.Duration {
+	d := lo.Base
+	for i := lo.Threshold; i < failures && d < lo.Max; i++ {
+		d *= 2
+	}
+	return min(d, lo.Max)
+}
+
+func key(kind string, action Action, subject Subject) string {
+	return kind + ":" + string(action) + ":" + string(subject.Scope) + ":" + subject.Value
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter whose clock is controlled by the returned pointer
+func newTestLimiter(policies map[Action]Policy) (*Limiter, *time.Time) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(NewMemoryBackend(), policies)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllow_LimitsEachSubject(t *testing.T) {
+	l, now := newTestLimiter(map[Action]Policy{
+		ActionJoinFamily: {Limits: map[Scope]Limit{
+			ScopeIP:     {Attempts: 3, Window: time.Minute},
+			ScopeFamily: {Attempts: 2, Window: 10 * time.Minute},
+		}},
+	})
+	ctx := context.Background()
+
+	for i := range 2 {
+		if err := l.Allow(ctx, ActionJoinFamily, IP("1.2.3.4"), Family("Smith")); err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	var limitErr *LimitError
+	err := l.Allow(ctx, ActionJoinFamily, IP("1.2.3.4"), Family("smith "))
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected *LimitError, got %v", err)
+	}
+	if limitErr.Scope != ScopeFamily || limitErr.LockedOut || !limitErr.RetryAt.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("unexpected limit error: %+v", limitErr)
+	}
+	if got := limitErr.RetryAfter(now.Add(90 * time.Second).Add(time.Millisecond)); got != 510*time.Second {
+		t.Errorf("RetryAfter() = %v, want 510s", got)
+	}
+
+	// Another family from the same IP is only held back by the IP limit
+	if err := l.Allow(ctx, ActionJoinFamily, IP("1.2.3.4"), Family("Jones")); !errors.As(err, &limitErr) || limitErr.Scope != ScopeIP {
+		t.Errorf("expected IP limit, got %v", err)
+	}
+	if err := l.Allow(ctx, ActionJoinFamily, IP("5.6.7.8"), Family("Jones")); err != nil {
+		t.Errorf("expected another IP to be allowed: %v", err)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if err := l.Allow(ctx, ActionJoinFamily, IP("1.2.3.4"), Family("Smith")); err != nil {
+		t.Errorf("expected limits to reset after their window: %v", err)
+	}
+}
+
+func TestFail_ProgressiveLockout(t *testing.T) {
+	l, now := newTestLimiter(map[Action]Policy{
+		ActionJoinFamily: {Lockout: Lockout{Threshold: 3, Base: time.Minute, Max: 3 * time.Minute, Window: time.Hour}},
+	})
+	ctx := context.Background()
+	device := Device("device-1")
+
+	for range 2 {
+		l.Fail(ctx, ActionJoinFamily, device)
+	}
+	if err := l.Allow(ctx, ActionJoinFamily, device); err != nil {
+		t.Fatalf("expected no lockout below the threshold: %v", err)
+	}
+
+	// Each failure from the threshold on doubles the lockout, up to the max
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		l.Fail(ctx, ActionJoinFamily, device)
+
+		var limitErr *LimitError
+		if err := l.Allow(ctx, ActionJoinFamily, device); !errors.As(err, &limitErr) || !limitErr.LockedOut {
+			t.Fatalf("expected lockout, got %v", err)
+		}
+		if got := limitErr.RetryAt.Sub(*now); got != want {
+			t.Errorf("lockout = %v, want %v", got, want)
+		}
+		*now = limitErr.RetryAt
+	}
+
+	if err := l.Allow(ctx, ActionJoinFamily, device); err != nil {
+		t.Errorf("expected lockout to end: %v", err)
+	}
+	l.Succeed(ctx, ActionJoinFamily, device)
+	l.Fail(ctx, ActionJoinFamily, device)
+	if err := l.Allow(ctx, ActionJoinFamily, device); err != nil {
+		t.Errorf("expected success to clear failures: %v", err)
+	}
+}
+
+func TestAllow_SkipsEmptySubjects(t *testing.T) {
+	l, _ := newTestLimiter(map[Action]Policy{
+		ActionJoinFamily: {
+			Limits:  map[Scope]Limit{ScopeDevice: {Attempts: 1, Window: time.Minute}},
+			Lockout: Lockout{Threshold: 1, Base: time.Minute, Max: time.Minute, Window: time.Hour},
+		},
+	})
+	ctx := context.Background()
+
+	l.Fail(ctx, ActionJoinFamily, Device(""))
+	for range 3 {
+		if err := l.Allow(ctx, ActionJoinFamily, Device("")); err != nil {
+			t.Fatalf("expected an empty subject to be ignored: %v", err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package ratelimittest is a conformance suite for ratelimit.Backend implementations.
+package ratelimittest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+)
+
+// Run checks that b behaves as ratelimit.Backend documents. Keys are unique per run, so
+// b may be shared with other data.
+func Run(t *testing.T, b ratelimit.Backend) {
+	ctx := context.Background()
+	base := time.Now().Truncate(time.Second)
+	newKey := func() string { return "test:" + uuid.New().String() }
+
+	t.Run("HitCountsWithinWindow", func(t *testing.T) {
+		key := newKey()
+		t.Cleanup(func() { b.Reset(ctx, key) })
+
+		for i := 1; i <= 3; i++ {
+			count, resetAt, err := b.Hit(ctx, key, time.Minute, base.Add(time.Duration(i)*time.Second))
+			if err != nil {
+				t.Fatalf("Failed to hit: %v", err)
+			}
+			if count != i {
+				t.Errorf("Hit %d: expected count %d, got %d", i, i, count)
+			}
+			if !resetAt.Equal(base.Add(time.Minute + time.Second)) {
+				t.Errorf("Hit %d: expected the window to end a minute after the first hit, got %v", i, resetAt)
+			}
+		}
+
+		count, resetAt, err := b.Peek(ctx, key, base.Add(30*time.Second))
+		if err != nil {
+			t.Fatalf("Failed to peek: %v", err)
+		}
+		if count != 3 || !resetAt.Equal(base.Add(time.Minute+time.Second)) {
+			t.Errorf("Expected peek to see 3 hits, got %d until %v", count, resetAt)
+		}
+	})
+
+	t.Run("WindowRestartsAfterReset", func(t *testing.T) {
+		key := newKey()
+		t.Cleanup(func() { b.Reset(ctx, key) })
+
+		b.Hit(ctx, key, time.Minute, base)
+		b.Hit(ctx, key, time.Minute, base)
+
+		if count, _, err := b.Peek(ctx, key, base.Add(time.Minute)); err != nil || count != 0 {
+			t.Errorf("Expected an expired counter to peek as zero, got %d (err %v)", count, err)
+		}
+		count, resetAt, err := b.Hit(ctx, key, time.Hour, base.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to hit: %v", err)
+		}
+		if count != 1 || !resetAt.Equal(base.Add(time.Minute+time.Hour)) {
+			t.Errorf("Expected a new window, got %d until %v", count, resetAt)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		key := newKey()
+		b.Hit(ctx, key, time.Minute, base)
+
+		if err := b.Reset(ctx, key); err != nil {
+			t.Fatalf("Failed to reset: %v", err)
+		}
+		if count, _, _ := b.Peek(ctx, key, base); count != 0 {
+			t.Errorf("Expected reset counter to be gone, got %d", count)
+		}
+		if err := b.Reset(ctx, key); err != nil {
+			t.Errorf("Expected resetting a missing counter to succeed: %v", err)
+		}
+	})
+
+	t.Run("PruneKeepsLiveCounters", func(t *testing.T) {
+		expired, live := newKey(), newKey()
+		t.Cleanup(func() { b.Reset(ctx, live) })
+		b.Hit(ctx, expired, time.Minute, base)
+		b.Hit(ctx, live, time.Hour, base)
+
+		if err := b.Prune(ctx, base.Add(2*time.Minute)); err != nil {
+			t.Fatalf("Failed to prune: %v", err)
+		}
+		if count, _, _ := b.Peek(ctx, live, base.Add(2*time.Minute)); count != 1 {
+			t.Errorf("Expected live counter to survive pruning, got %d", count)
+		}
+		// A pruned counter starts afresh even if asked about a time inside its old window
+		if count, _, _ := b.Hit(ctx, expired, time.Minute, base); count != 1 {
+			t.Errorf("Expected expired counter to be pruned, got %d", count)
+		}
+		b.Reset(ctx, expired)
+	})
+}
@@ -0,0 +1,94 @@
+// Package reminder sends server-side reminders ahead of predicted feeds, naps and bedtime,
+// so that caregivers are reminded even when the phone on duty is asleep.
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Kind distinguishes a reminder sent ahead of a prediction from one sent after it has passed.
+type Kind string
+
+const (
+	KindUpcoming Kind = "upcoming"
+	KindOverdue  Kind = "overdue"
+)
+
+// Reminder is a single notification for one caregiver about one prediction.
+type Reminder struct {
+	Kind           Kind                  `json:"kind"`
+	FamilyID       uuid.UUID             `json:"familyId"`
+	CaregiverID    uuid.UUID             `json:"caregiverId"`
+	BabyID         uuid.UUID             `json:"babyId"`
+	BabyName       string                `json:"babyName"`
+	PredictionID   uuid.UUID             `json:"predictionId"`
+	PredictionType domain.PredictionType `json:"predictionType"`
+	PredictedTime  time.Time             `json:"predictedTime"`
+	Message        string                `json:"message"`
+}
+
+// Notifier delivers reminders to caregivers.
+type Notifier interface {
+	Notify(ctx context.Context, reminder Reminder) error
+}
+
+// LogNotifier writes reminders to the server log. Useful for local development.
+type LogNotifier struct{}
+
+// Notify logs the reminder.
+func (LogNotifier) Notify(ctx context.Context, reminder Reminder) error {
+	log.Printf("reminder: caregiver %s: %s", reminder.CaregiverID, reminder.Message)
+	return nil
+}
+
+// webhookTimeout bounds how long a webhook receiver can hold up a scheduler tick.
+const webhookTimeout = 10 * time.Second
+
+// WebhookNotifier POSTs each reminder as JSON to a fixed URL, typically a push gateway.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that POSTs to url.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Notify POSTs the reminder and fails on any non-2xx response.
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder Reminder) error {
+	body, err := json.Marshal(reminder)
+	if err != nil {
+		return fmt.Errorf("failed to encode reminder: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send reminder webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("reminder webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
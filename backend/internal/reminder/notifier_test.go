@@ -0,0 +1,49 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Reminder
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	reminder := Reminder{
+		Kind:           KindUpcoming,
+		CaregiverID:    uuid.New(),
+		PredictionType: domain.PredictionTypeNextNap,
+		Message:        "Emma's next nap is expected around 1:30pm",
+	}
+	if err := NewWebhookNotifier(srv.URL).Notify(context.Background(), reminder); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if received.CaregiverID != reminder.CaregiverID || received.Message != reminder.Message || received.PredictionType != domain.PredictionTypeNextNap {
+		t.Errorf("Received %+v, want %+v", received, reminder)
+	}
+}
+
+func TestWebhookNotifierErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	if err := NewWebhookNotifier(srv.URL).Notify(context.Background(), Reminder{}); err == nil {
+		t.Error("Expected error for a non-2xx response")
+	}
+}
@@ -0,0 +1,257 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/prediction"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+const (
+	// activeWindow is how recently a family must have touched a care session for the
+	// scheduler to keep its predictions fresh
+	activeWindow = 24 * time.Hour
+
+	// repeatWindow suppresses a second reminder for the same prediction when it only
+	// shifts by a few minutes between ticks
+	repeatWindow = 30 * time.Minute
+)
+
+// remindedKey identifies one kind of reminder for one prediction type, baby and caregiver.
+type remindedKey struct {
+	caregiverID    uuid.UUID
+	babyID         uuid.UUID
+	predictionType domain.PredictionType
+	kind           Kind
+}
+
+// Scheduler periodically regenerates predictions for active families and sends
+// reminders to caregivers who opted in.
+type Scheduler struct {
+	store    store.Store
+	notifier Notifier
+	interval time.Duration
+	timezone string // for families that haven't set their own
+
+	// onOverdue, if set, is called once for each prediction that becomes overdue
+	onOverdue OverdueFunc
+
+	// reminded holds the predicted time of the last reminder sent per key
+	reminded map[remindedKey]time.Time
+}
+
+// OverdueFunc is called when a baby's next feed, nap or bedtime becomes overdue,
+// regardless of reminder preferences. A returned error is retried on the next tick.
+type OverdueFunc func(ctx context.Context, familyID uuid.UUID, baby *domain.Baby, p *domain.Prediction) error
+
+// NewScheduler creates a scheduler that checks every interval. Predictions are computed
+// in each family's own timezone, or the given one for families that haven't set theirs.
+func NewScheduler(s store.Store, notifier Notifier, interval time.Duration, timezone string) *Scheduler {
+	return &Scheduler{
+		store:    s,
+		notifier: notifier,
+		interval: interval,
+		timezone: timezone,
+		reminded: make(map[remindedKey]time.Time),
+	}
+}
+
+// OnOverdue registers fn to be called for overdue predictions. It must be called before Run.
+func (s *Scheduler) OnOverdue(fn OverdueFunc) {
+	s.onOverdue = fn
+}
+
+// Run checks for due reminders every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Tick(ctx, now); err != nil {
+				log.Printf("reminder scheduler: %v", err)
+			}
+		}
+	}
+}
+
+// Tick regenerates predictions for every active family and sends the reminders due at now.
+// A failure for one family is logged and does not stop the others. Tick is not safe for
+// concurrent use.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) error {
+	familyIDs, err := s.store.GetActiveFamilyIDs(ctx, now.Add(-activeWindow))
+	if err != nil {
+		return fmt.Errorf("failed to get active families: %w", err)
+	}
+
+	for _, familyID := range familyIDs {
+		if err := s.tickFamily(ctx, familyID, now); err != nil {
+			log.Printf("reminder scheduler: family %s: %v", familyID, err)
+		}
+	}
+
+	s.forget(now)
+	return nil
+}
+
+func (s *Scheduler) tickFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	prefs, err := s.store.GetReminderPreferencesForFamily(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+	if len(prefs) == 0 && s.onOverdue == nil {
+		// Nobody opted in and nothing else is listening
+		return nil
+	}
+
+	family, err := s.store.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get family: %w", err)
+	}
+	timezone := family.TimezoneAt(now)
+	if timezone == "" {
+		timezone = s.timezone
+	}
+
+	babies, err := s.store.GetBabiesForFamily(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get babies: %w", err)
+	}
+
+	for _, baby := range babies {
+		predictions, err := prediction.Refresh(ctx, s.store, familyID, baby.ID, now, timezone)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range predictions {
+			s.overdue(ctx, familyID, baby, p)
+
+			for _, pref := range prefs {
+				kind, ok := dueReminder(p, pref, now)
+				if !ok {
+					continue
+				}
+				s.send(ctx, Reminder{
+					Kind:           kind,
+					FamilyID:       familyID,
+					CaregiverID:    pref.CaregiverID,
+					BabyID:         baby.ID,
+					BabyName:       baby.Name,
+					PredictionID:   p.ID,
+					PredictionType: p.PredictionType,
+					PredictedTime:  p.PredictedTime,
+					Message:        message(kind, baby.Name, p, timezone),
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// dueReminder reports which reminder, if any, the caregiver should get for a prediction
+// at now. Only the next feed, nap and bedtime are reminded; chained (planned) predictions
+// further out are not.
+func dueReminder(p *domain.Prediction, pref *domain.ReminderPreferences, now time.Time) (Kind, bool) {
+	var optedIn bool
+	switch p.PredictionType {
+	case domain.PredictionTypeNextFeed:
+		optedIn = pref.FeedReminders
+	case domain.PredictionTypeNextNap:
+		optedIn = pref.NapReminders
+	case domain.PredictionTypeBedtime:
+		optedIn = pref.BedtimeReminders
+	default:
+		return "", false
+	}
+
+	switch p.Status {
+	case domain.PredictionStatusOverdue:
+		return KindOverdue, pref.OverdueReminders
+	case domain.PredictionStatusUpcoming:
+		lead := time.Duration(pref.LeadMinutes) * time.Minute
+		return KindUpcoming, optedIn && !p.PredictedTime.After(now.Add(lead))
+	default:
+		return "", false
+	}
+}
+
+// send delivers a reminder unless one was already sent for roughly the same predicted time.
+// Failed deliveries are retried on the next tick.
+func (s *Scheduler) send(ctx context.Context, r Reminder) {
+	key := remindedKey{caregiverID: r.CaregiverID, babyID: r.BabyID, predictionType: r.PredictionType, kind: r.Kind}
+	if last, ok := s.reminded[key]; ok && r.PredictedTime.Sub(last).Abs() < repeatWindow {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, r); err != nil {
+		log.Printf("reminder scheduler: failed to notify caregiver %s: %v", r.CaregiverID, err)
+		return
+	}
+	s.reminded[key] = r.PredictedTime
+}
+
+// overdue calls the overdue hook once per overdue prediction. Hook calls are keyed like
+// reminders, with no caregiver.
+func (s *Scheduler) overdue(ctx context.Context, familyID uuid.UUID, baby *domain.Baby, p *domain.Prediction) {
+	if s.onOverdue == nil || p.Status != domain.PredictionStatusOverdue {
+		return
+	}
+	switch p.PredictionType {
+	case domain.PredictionTypeNextFeed, domain.PredictionTypeNextNap, domain.PredictionTypeBedtime:
+	default:
+		return
+	}
+
+	key := remindedKey{babyID: baby.ID, predictionType: p.PredictionType, kind: KindOverdue}
+	if last, ok := s.reminded[key]; ok && p.PredictedTime.Sub(last).Abs() < repeatWindow {
+		return
+	}
+
+	if err := s.onOverdue(ctx, familyID, baby, p); err != nil {
+		log.Printf("reminder scheduler: overdue hook for baby %s: %v", baby.ID, err)
+		return
+	}
+	s.reminded[key] = p.PredictedTime
+}
+
+// forget drops reminders for predictions long past so the map doesn't grow unbounded
+func (s *Scheduler) forget(now time.Time) {
+	for key, predictedTime := range s.reminded {
+		if predictedTime.Before(now.Add(-activeWindow)) {
+			delete(s.reminded, key)
+		}
+	}
+}
+
+func message(kind Kind, babyName string, p *domain.Prediction, timezone string) string {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	at := p.PredictedTime.In(loc).Format("3:04pm")
+
+	var what string
+	switch p.PredictionType {
+	case domain.PredictionTypeNextFeed:
+		what = "next feed"
+	case domain.PredictionTypeNextNap:
+		what = "next nap"
+	case domain.PredictionTypeBedtime:
+		what = "bedtime"
+	}
+
+	if kind == KindOverdue {
+		return fmt.Sprintf("%s's %s is overdue (expected around %s)", babyName, what, at)
+	}
+	return fmt.Sprintf("%s's %s is expected around %s", babyName, what, at)
+}
@@ -0,0 +1,249 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store/memory"
+)
+
+type recordingNotifier struct {
+	reminders []Reminder
+	err       error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, r Reminder) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.reminders = append(n.reminders, r)
+	return nil
+}
+
+type schedulerFixture struct {
+	store     *memory.MemoryStore
+	family    *domain.Family
+	baby      *domain.Baby
+	caregiver *domain.Caregiver
+}
+
+// newSchedulerFixture creates a family with an in-progress session and three daytime feeds
+// three hours apart, the last one at lastFeed, so the next feed is predicted at lastFeed+3h
+func newSchedulerFixture(t *testing.T, lastFeed time.Time) schedulerFixture {
+	t.Helper()
+	ctx := context.Background()
+	s := memory.NewMemoryStore()
+
+	family := &domain.Family{ID: uuid.New(), Name: "Reminder Family", CreatedAt: lastFeed, UpdatedAt: lastFeed}
+	baby := &domain.Baby{ID: uuid.New(), FamilyID: family.ID, Name: "Emma", CreatedAt: lastFeed, UpdatedAt: lastFeed}
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: family.ID, Name: "Parent", CreatedAt: lastFeed, UpdatedAt: lastFeed}
+	if err := s.CreateFamilyWithCaregiver(ctx, family, baby, caregiver); err != nil {
+		t.Fatalf("Failed to create family: %v", err)
+	}
+
+	session := &domain.CareSession{
+		ID: uuid.New(), CaregiverID: caregiver.ID, FamilyID: family.ID, Status: domain.StatusInProgress,
+		StartedAt: lastFeed.Add(-6 * time.Hour), CreatedAt: lastFeed, UpdatedAt: lastFeed,
+	}
+	if err := s.CreateCareSession(ctx, session); err != nil {
+		t.Fatalf("Failed to create care session: %v", err)
+	}
+
+	for i := range 3 {
+		startTime := lastFeed.Add(-time.Duration(i) * 3 * time.Hour)
+		activity := &domain.Activity{
+			ID: uuid.New(), CareSessionID: session.ID, BabyID: baby.ID, ActivityType: domain.ActivityTypeFeed,
+			CreatedAt: startTime, UpdatedAt: startTime,
+		}
+		if err := s.CreateActivity(ctx, activity); err != nil {
+			t.Fatalf("Failed to create activity: %v", err)
+		}
+		details := &domain.FeedDetails{ID: uuid.New(), ActivityID: activity.ID, StartTime: startTime, CreatedAt: startTime, UpdatedAt: startTime}
+		if err := s.CreateFeedDetails(ctx, details); err != nil {
+			t.Fatalf("Failed to create feed details: %v", err)
+		}
+	}
+
+	return schedulerFixture{store: s, family: family, baby: baby, caregiver: caregiver}
+}
+
+func (f schedulerFixture) optIn(t *testing.T, prefs domain.ReminderPreferences) {
+	t.Helper()
+	prefs.CaregiverID = f.caregiver.ID
+	if _, err := f.store.UpsertReminderPreferences(context.Background(), &prefs); err != nil {
+		t.Fatalf("Failed to save reminder preferences: %v", err)
+	}
+}
+
+func TestSchedulerUpcomingFeed(t *testing.T) {
+	ctx := context.Background()
+	lastFeed := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	f := newSchedulerFixture(t, lastFeed)
+	f.optIn(t, domain.ReminderPreferences{FeedReminders: true, LeadMinutes: 15})
+
+	notifier := &recordingNotifier{}
+	s := NewScheduler(f.store, notifier, time.Minute, "UTC")
+
+	// Next feed at 16:00 is outside the 15 minute lead
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+30*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 0 {
+		t.Fatalf("Expected no reminders before the lead time, got %+v", notifier.reminders)
+	}
+
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+50*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 1 {
+		t.Fatalf("Expected 1 reminder, got %d", len(notifier.reminders))
+	}
+	r := notifier.reminders[0]
+	if r.Kind != KindUpcoming || r.PredictionType != domain.PredictionTypeNextFeed || r.CaregiverID != f.caregiver.ID || r.BabyID != f.baby.ID {
+		t.Errorf("Unexpected reminder: %+v", r)
+	}
+	if want := "Emma's next feed is expected around 4:00pm"; r.Message != want {
+		t.Errorf("Message = %q, want %q", r.Message, want)
+	}
+
+	// The same prediction is not reminded twice
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+55*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 1 {
+		t.Errorf("Expected no repeat reminder, got %d", len(notifier.reminders))
+	}
+}
+
+func TestSchedulerUsesFamilyTimezone(t *testing.T) {
+	ctx := context.Background()
+	lastFeed := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	f := newSchedulerFixture(t, lastFeed)
+	f.optIn(t, domain.ReminderPreferences{FeedReminders: true, LeadMinutes: 15})
+
+	f.family.Timezone = "Europe/Paris"
+	if err := f.store.UpdateFamily(ctx, f.family); err != nil {
+		t.Fatalf("Failed to set timezone: %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+	s := NewScheduler(f.store, notifier, time.Minute, "UTC")
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+50*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 1 {
+		t.Fatalf("Expected 1 reminder, got %d", len(notifier.reminders))
+	}
+	// 16:00 UTC is 5pm in Paris
+	if want := "Emma's next feed is expected around 5:00pm"; notifier.reminders[0].Message != want {
+		t.Errorf("Message = %q, want %q", notifier.reminders[0].Message, want)
+	}
+}
+
+func TestSchedulerOverdueFeed(t *testing.T) {
+	ctx := context.Background()
+	lastFeed := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	f := newSchedulerFixture(t, lastFeed)
+	f.optIn(t, domain.ReminderPreferences{OverdueReminders: true, LeadMinutes: 15})
+
+	notifier := &recordingNotifier{}
+	s := NewScheduler(f.store, notifier, time.Minute, "UTC")
+
+	if err := s.Tick(ctx, lastFeed.Add(3*time.Hour+10*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 1 || notifier.reminders[0].Kind != KindOverdue {
+		t.Fatalf("Expected 1 overdue reminder, got %+v", notifier.reminders)
+	}
+	if want := "Emma's next feed is overdue (expected around 4:00pm)"; notifier.reminders[0].Message != want {
+		t.Errorf("Message = %q, want %q", notifier.reminders[0].Message, want)
+	}
+}
+
+func TestSchedulerRequiresOptIn(t *testing.T) {
+	ctx := context.Background()
+	lastFeed := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	f := newSchedulerFixture(t, lastFeed)
+
+	notifier := &recordingNotifier{}
+	s := NewScheduler(f.store, notifier, time.Minute, "UTC")
+
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+50*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 0 {
+		t.Errorf("Expected no reminders without preferences, got %+v", notifier.reminders)
+	}
+
+	// Opted in to naps only
+	f.optIn(t, domain.ReminderPreferences{NapReminders: true, LeadMinutes: 15})
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+50*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 0 {
+		t.Errorf("Expected no feed reminder for a nap-only caregiver, got %+v", notifier.reminders)
+	}
+}
+
+func TestSchedulerRetriesFailedDelivery(t *testing.T) {
+	ctx := context.Background()
+	lastFeed := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	f := newSchedulerFixture(t, lastFeed)
+	f.optIn(t, domain.ReminderPreferences{FeedReminders: true, LeadMinutes: 15})
+
+	notifier := &recordingNotifier{err: errors.New("push gateway down")}
+	s := NewScheduler(f.store, notifier, time.Minute, "UTC")
+
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+50*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+
+	notifier.err = nil
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+51*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.reminders) != 1 {
+		t.Errorf("Expected failed reminder to be retried, got %d", len(notifier.reminders))
+	}
+}
+
+func TestSchedulerOverdueHook(t *testing.T) {
+	ctx := context.Background()
+	lastFeed := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	f := newSchedulerFixture(t, lastFeed)
+
+	// The hook fires without any reminder preferences
+	notifier := &recordingNotifier{}
+	s := NewScheduler(f.store, notifier, time.Minute, "UTC")
+	var overdue []*domain.Prediction
+	s.OnOverdue(func(_ context.Context, familyID uuid.UUID, baby *domain.Baby, p *domain.Prediction) error {
+		if familyID != f.family.ID || baby.ID != f.baby.ID {
+			t.Errorf("Unexpected overdue hook call for family %s baby %s", familyID, baby.ID)
+		}
+		overdue = append(overdue, p)
+		return nil
+	})
+
+	if err := s.Tick(ctx, lastFeed.Add(2*time.Hour+50*time.Minute)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(overdue) != 0 {
+		t.Fatalf("Expected no overdue predictions before the feed is due, got %d", len(overdue))
+	}
+
+	for _, minutes := range []time.Duration{10, 11} {
+		if err := s.Tick(ctx, lastFeed.Add(3*time.Hour+minutes*time.Minute)); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+	}
+	if len(overdue) != 1 || overdue[0].PredictionType != domain.PredictionTypeNextFeed {
+		t.Fatalf("Expected the overdue feed once, got %+v", overdue)
+	}
+	if len(notifier.reminders) != 0 {
+		t.Errorf("Expected no reminders without preferences, got %+v", notifier.reminders)
+	}
+}
@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// Activity operations
+
+func copyActivity(a domain.Activity) *domain.Activity {
+	a.DeletedAt = clone(a.DeletedAt)
+	return &a
+}
+
+func copyActivities(rows []domain.Activity) []*domain.Activity {
+	var activities []*domain.Activity
+	for _, row := range rows {
+		activities = append(activities, copyActivity(row))
+	}
+	return activities
+}
+
+// CreateActivity creates a new activity
+func (s *MemoryStore) CreateActivity(ctx context.Context, activity *domain.Activity) error {
+	defer s.lock()()
+
+	if _, ok := s.data.activities[activity.ID]; ok {
+		return fmt.Errorf("failed to create activity: activity already exists: %s", activity.ID)
+	}
+	if _, ok := s.data.careSessions[activity.CareSessionID]; !ok {
+		return fmt.Errorf("failed to create activity: care session not found: %s", activity.CareSessionID)
+	}
+	if err := s.data.requireBaby(activity.BabyID); err != nil {
+		return fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	if activity.Version == 0 {
+		activity.Version = 1
+	}
+
+	s.data.activities[activity.ID] = *copyActivity(*activity)
+	return nil
+}
+
+func (t *tables) requireActivity(id uuid.UUID) error {
+	if _, ok := t.activities[id]; !ok {
+		return fmt.Errorf("activity not found: %s", id)
+	}
+	return nil
+}
+
+// GetActivityByID retrieves a single activity by ID
+func (s *MemoryStore) GetActivityByID(ctx context.Context, id uuid.UUID) (*domain.Activity, error) {
+	defer s.rlock()()
+
+	activity, ok := s.data.activities[id]
+	if !ok || activity.DeletedAt != nil {
+		return nil, fmt.Errorf("failed to get activity: activity not found: %s", id)
+	}
+
+	return copyActivity(activity), nil
+}
+
+// activityTime returns the time an activity happened: the start of a feed or sleep or
+// the time of a diaper change, falling back to when it was logged. includeAll also
+// considers pump and medication details, matching the ordering of
+// GetLatestActivityByTypeForBaby rather than GetActivitiesForSession.
+func (t *tables) activityTime(a domain.Activity, includeAll bool) time.Time {
+	if d, ok := first(t.feedDetails, func(d domain.FeedDetails) bool { return d.ActivityID == a.ID }); ok {
+		return d.StartTime
+	}
+	if d, ok := first(t.sleepDetails, func(d domain.SleepDetails) bool { return d.ActivityID == a.ID }); ok {
+		return d.StartTime
+	}
+	if d, ok := first(t.diaperDetails, func(d domain.DiaperDetails) bool { return d.ActivityID == a.ID }); ok {
+		return d.ChangedAt
+	}
+	if includeAll {
+		if d, ok := first(t.pumpDetails, func(d domain.PumpDetails) bool { return d.ActivityID == a.ID }); ok {
+			return d.StartTime
+		}
+		if d, ok := first(t.medicationDetails, func(d domain.MedicationDetails) bool { return d.ActivityID == a.ID }); ok {
+			return d.GivenAt
+		}
+	}
+	return a.CreatedAt
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// GetActivitiesForSession retrieves all activities for a care session in the order they
+// happened. For a deleted session these are the activities deleted with it.
+func (s *MemoryStore) GetActivitiesForSession(ctx context.Context, sessionID uuid.UUID) ([]*domain.Activity, error) {
+	defer s.rlock()()
+
+	return copyActivities(s.data.sessionActivities(sessionID)), nil
+}
+
+// GetActivitiesForSessions retrieves the activities for several care sessions at once,
+// each session's in GetActivitiesForSession order
+func (s *MemoryStore) GetActivitiesForSessions(ctx context.Context, sessionIDs []uuid.UUID) ([]*domain.Activity, error) {
+	defer s.rlock()()
+
+	var activities []*domain.Activity
+	seen := make(map[uuid.UUID]bool, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		if seen[sessionID] {
+			continue
+		}
+		seen[sessionID] = true
+		activities = append(activities, copyActivities(s.data.sessionActivities(sessionID))...)
+	}
+
+	return activities, nil
+}
+
+func (t *tables) sessionActivities(sessionID uuid.UUID) []domain.Activity {
+	session := t.careSessions[sessionID]
+	rows := filter(t.activities, func(a domain.Activity) bool {
+		return a.CareSessionID == sessionID && sameTime(a.DeletedAt, session.DeletedAt)
+	})
+	slices.SortFunc(rows, func(a, b domain.Activity) int {
+		return compareTimes(t.activityTime(a, false), t.activityTime(b, false), a.ID, b.ID)
+	})
+	return rows
+}
+
+// GetLatestActivityByTypeForBaby returns the most recent activity of a given type
+// across all sessions for a baby, ordered by the activity's own time (e.g. startTime
+// for feeds/sleeps/pumps, changedAt for diapers, givenAt for medication) with created_at as fallback.
+// Returns (nil, nil) if no matching activity exists.
+func (s *MemoryStore) GetLatestActivityByTypeForBaby(ctx context.Context, babyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.activities, func(a domain.Activity) bool {
+		return a.BabyID == babyID && a.ActivityType == activityType && a.DeletedAt == nil
+	})
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	latest := slices.MaxFunc(rows, func(a, b domain.Activity) int {
+		return compareTimes(s.data.activityTime(a, true), s.data.activityTime(b, true), a.ID, b.ID)
+	})
+	return copyActivity(latest), nil
+}
+
+// DeleteActivity moves an activity to the trash. Its details are kept so it can be restored.
+func (s *MemoryStore) DeleteActivity(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	activity, ok := s.data.activities[id]
+	if !ok || activity.DeletedAt != nil {
+		return fmt.Errorf("activity not found: %s", id)
+	}
+
+	now := time.Now()
+	activity.DeletedAt = &now
+	activity.UpdatedAt = now
+	s.data.activities[id] = activity
+	return nil
+}
+
+// RestoreActivity takes an activity out of the trash, unless its session is deleted too
+func (s *MemoryStore) RestoreActivity(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	activity, ok := s.data.activities[id]
+	if !ok || activity.DeletedAt == nil || s.data.careSessions[activity.CareSessionID].DeletedAt != nil {
+		return fmt.Errorf("deleted activity not found: %s", id)
+	}
+
+	activity.DeletedAt = nil
+	activity.UpdatedAt = time.Now()
+	s.data.activities[id] = activity
+	return nil
+}
+
+// GetRecentlyDeletedActivitiesForFamily retrieves a family's activities deleted after since,
+// most recently deleted first. Activities deleted with their session are left out; they
+// are restored with it.
+func (s *MemoryStore) GetRecentlyDeletedActivitiesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.activities, func(a domain.Activity) bool {
+		session := s.data.careSessions[a.CareSessionID]
+		return session.FamilyID == familyID && session.DeletedAt == nil &&
+			a.DeletedAt != nil && a.DeletedAt.After(since)
+	})
+	slices.SortFunc(rows, func(a, b domain.Activity) int {
+		return compareTimes(*b.DeletedAt, *a.DeletedAt, b.ID, a.ID)
+	})
+
+	return copyActivities(rows), nil
+}
+
+// PurgeDeleted permanently removes activities and care sessions deleted before
+// deletedBefore (cascading to activity details)
+func (s *MemoryStore) PurgeDeleted(ctx context.Context, deletedBefore time.Time) error {
+	defer s.lock()()
+
+	for _, activity := range s.data.activities {
+		if activity.DeletedAt != nil && activity.DeletedAt.Before(deletedBefore) {
+			s.data.deleteActivity(activity.ID)
+		}
+	}
+	for _, session := range s.data.careSessions {
+		if session.DeletedAt != nil && session.DeletedAt.Before(deletedBefore) {
+			s.data.deleteCareSession(session.ID)
+		}
+	}
+	return nil
+}
+
+func (t *tables) deleteActivity(id uuid.UUID) {
+	for _, o := range t.outcomes {
+		if o.ActivityID == id {
+			delete(t.outcomes, o.ID)
+		}
+	}
+	for _, d := range t.feedDetails {
+		if d.ActivityID == id {
+			delete(t.feedDetails, d.ID)
+		}
+	}
+	for _, d := range t.diaperDetails {
+		if d.ActivityID == id {
+			delete(t.diaperDetails, d.ID)
+		}
+	}
+	for _, d := range t.sleepDetails {
+		if d.ActivityID == id {
+			delete(t.sleepDetails, d.ID)
+		}
+	}
+	for _, d := range t.pumpDetails {
+		if d.ActivityID == id {
+			delete(t.pumpDetails, d.ID)
+		}
+	}
+	for _, d := range t.medicationDetails {
+		if d.ActivityID == id {
+			delete(t.medicationDetails, d.ID)
+		}
+	}
+	delete(t.activities, id)
+}
+
+// TouchActivity bumps an activity's version and updated_at so editors and syncing clients
+// see that its details changed. With expectedVersion set, the update only applies if nobody
+// changed the activity since that version.
+func (s *MemoryStore) TouchActivity(ctx context.Context, id uuid.UUID, expectedVersion *int) (int, error) {
+	defer s.lock()()
+
+	activity, ok := s.data.activities[id]
+	if !ok || activity.DeletedAt != nil {
+		return 0, fmt.Errorf("activity not found: %s", id)
+	}
+	if expectedVersion != nil && activity.Version != *expectedVersion {
+		return 0, &store.VersionConflictError{ID: id, Version: activity.Version}
+	}
+
+	activity.Version++
+	activity.UpdatedAt = time.Now()
+	s.data.activities[id] = activity
+
+	return activity.Version, nil
+}
+
+// GetActivitiesUpdatedSinceForFamily retrieves a family's activities created or changed after since,
+// oldest change first
+func (s *MemoryStore) GetActivitiesUpdatedSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.activities, func(a domain.Activity) bool {
+		return s.data.careSessions[a.CareSessionID].FamilyID == familyID && a.UpdatedAt.After(since) && a.DeletedAt == nil
+	})
+	slices.SortFunc(rows, func(a, b domain.Activity) int {
+		return compareTimes(a.UpdatedAt, b.UpdatedAt, a.ID, b.ID)
+	})
+
+	return copyActivities(rows), nil
+}
@@ -0,0 +1,446 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Activity detail operations (lazy loaded)
+
+// first returns a row of a table that matches, if any
+func first[K comparable, V any](table map[K]V, match func(V) bool) (V, bool) {
+	for _, row := range table {
+		if match(row) {
+			return row, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// forBaby reports whether an activity belongs to a baby and isn't deleted
+func (t *tables) forBaby(activityID, babyID uuid.UUID) bool {
+	activity := t.activities[activityID]
+	return activity.BabyID == babyID && activity.DeletedAt == nil
+}
+
+// Feed Details
+
+func copyFeedDetails(d domain.FeedDetails) *domain.FeedDetails {
+	d.EndTime = clone(d.EndTime)
+	d.AmountMl = clone(d.AmountMl)
+	d.FeedType = clone(d.FeedType)
+	d.FoodName = clone(d.FoodName)
+	d.Quantity = clone(d.Quantity)
+	d.QuantityUnit = clone(d.QuantityUnit)
+	return &d
+}
+
+// CreateFeedDetails creates feed details for an activity
+func (s *MemoryStore) CreateFeedDetails(ctx context.Context, details *domain.FeedDetails) error {
+	defer s.lock()()
+
+	if _, ok := s.data.feedDetails[details.ID]; ok {
+		return fmt.Errorf("failed to create feed details: feed details already exist: %s", details.ID)
+	}
+	if err := s.data.requireActivity(details.ActivityID); err != nil {
+		return fmt.Errorf("failed to create feed details: %w", err)
+	}
+
+	s.data.feedDetails[details.ID] = *copyFeedDetails(*details)
+	return nil
+}
+
+// GetFeedDetails retrieves feed details for an activity
+func (s *MemoryStore) GetFeedDetails(ctx context.Context, activityID uuid.UUID) (*domain.FeedDetails, error) {
+	defer s.rlock()()
+
+	details, ok := first(s.data.feedDetails, func(d domain.FeedDetails) bool { return d.ActivityID == activityID })
+	if !ok {
+		return nil, fmt.Errorf("feed details not found for activity: %s", activityID)
+	}
+
+	return copyFeedDetails(details), nil
+}
+
+// GetFeedDetailsByActivityIDs retrieves the feed details for several activities at once
+func (s *MemoryStore) GetFeedDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.FeedDetails, error) {
+	defer s.rlock()()
+
+	wanted := idSet(activityIDs)
+	var details []*domain.FeedDetails
+	for _, row := range filter(s.data.feedDetails, func(d domain.FeedDetails) bool { return wanted[d.ActivityID] }) {
+		details = append(details, copyFeedDetails(row))
+	}
+
+	return details, nil
+}
+
+// GetRecentFeedDetailsForBaby retrieves recent feed details across all sessions for a baby
+func (s *MemoryStore) GetRecentFeedDetailsForBaby(ctx context.Context, babyID uuid.UUID, n int) ([]*domain.FeedDetails, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.feedDetails, func(d domain.FeedDetails) bool { return s.data.forBaby(d.ActivityID, babyID) })
+	slices.SortFunc(rows, func(a, b domain.FeedDetails) int {
+		return compareTimes(b.StartTime, a.StartTime, b.ID, a.ID)
+	})
+
+	var details []*domain.FeedDetails
+	for _, row := range limit(rows, n) {
+		details = append(details, copyFeedDetails(row))
+	}
+
+	return details, nil
+}
+
+// UpdateFeedDetails updates feed details for an activity
+func (s *MemoryStore) UpdateFeedDetails(ctx context.Context, details *domain.FeedDetails) error {
+	defer s.lock()()
+
+	existing, ok := s.data.feedDetails[details.ID]
+	if !ok {
+		return fmt.Errorf("feed details not found: %s", details.ID)
+	}
+
+	updated := *copyFeedDetails(*details)
+	updated.ActivityID = existing.ActivityID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now()
+	s.data.feedDetails[details.ID] = updated
+
+	return nil
+}
+
+// Diaper Details
+
+// CreateDiaperDetails creates diaper details for an activity
+func (s *MemoryStore) CreateDiaperDetails(ctx context.Context, details *domain.DiaperDetails) error {
+	defer s.lock()()
+
+	if _, ok := s.data.diaperDetails[details.ID]; ok {
+		return fmt.Errorf("failed to create diaper details: diaper details already exist: %s", details.ID)
+	}
+	if err := s.data.requireActivity(details.ActivityID); err != nil {
+		return fmt.Errorf("failed to create diaper details: %w", err)
+	}
+
+	s.data.diaperDetails[details.ID] = *details
+	return nil
+}
+
+// GetDiaperDetails retrieves diaper details for an activity
+func (s *MemoryStore) GetDiaperDetails(ctx context.Context, activityID uuid.UUID) (*domain.DiaperDetails, error) {
+	defer s.rlock()()
+
+	details, ok := first(s.data.diaperDetails, func(d domain.DiaperDetails) bool { return d.ActivityID == activityID })
+	if !ok {
+		return nil, fmt.Errorf("diaper details not found for activity: %s", activityID)
+	}
+
+	return &details, nil
+}
+
+// GetDiaperDetailsByActivityIDs retrieves the diaper details for several activities at once
+func (s *MemoryStore) GetDiaperDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.DiaperDetails, error) {
+	defer s.rlock()()
+
+	wanted := idSet(activityIDs)
+	var details []*domain.DiaperDetails
+	for _, row := range filter(s.data.diaperDetails, func(d domain.DiaperDetails) bool { return wanted[d.ActivityID] }) {
+		details = append(details, &row)
+	}
+
+	return details, nil
+}
+
+// UpdateDiaperDetails updates diaper details for an activity
+func (s *MemoryStore) UpdateDiaperDetails(ctx context.Context, details *domain.DiaperDetails) error {
+	defer s.lock()()
+
+	existing, ok := s.data.diaperDetails[details.ID]
+	if !ok {
+		return fmt.Errorf("diaper details not found: %s", details.ID)
+	}
+
+	existing.ChangedAt = details.ChangedAt
+	existing.HadPoop = details.HadPoop
+	existing.HadPee = details.HadPee
+	existing.UpdatedAt = time.Now()
+	s.data.diaperDetails[details.ID] = existing
+
+	return nil
+}
+
+// Sleep Details
+
+func copySleepDetails(d domain.SleepDetails) *domain.SleepDetails {
+	d.EndTime = clone(d.EndTime)
+	d.DurationMinutes = clone(d.DurationMinutes)
+	return &d
+}
+
+// CreateSleepDetails creates sleep details for an activity
+func (s *MemoryStore) CreateSleepDetails(ctx context.Context, details *domain.SleepDetails) error {
+	defer s.lock()()
+
+	if _, ok := s.data.sleepDetails[details.ID]; ok {
+		return fmt.Errorf("failed to create sleep details: sleep details already exist: %s", details.ID)
+	}
+	if err := s.data.requireActivity(details.ActivityID); err != nil {
+		return fmt.Errorf("failed to create sleep details: %w", err)
+	}
+
+	s.data.sleepDetails[details.ID] = *copySleepDetails(*details)
+	return nil
+}
+
+// GetSleepDetails retrieves sleep details for an activity
+func (s *MemoryStore) GetSleepDetails(ctx context.Context, activityID uuid.UUID) (*domain.SleepDetails, error) {
+	defer s.rlock()()
+
+	details, ok := first(s.data.sleepDetails, func(d domain.SleepDetails) bool { return d.ActivityID == activityID })
+	if !ok {
+		return nil, fmt.Errorf("sleep details not found for activity: %s", activityID)
+	}
+
+	return copySleepDetails(details), nil
+}
+
+// GetSleepDetailsByActivityIDs retrieves the sleep details for several activities at once
+func (s *MemoryStore) GetSleepDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.SleepDetails, error) {
+	defer s.rlock()()
+
+	wanted := idSet(activityIDs)
+	var details []*domain.SleepDetails
+	for _, row := range filter(s.data.sleepDetails, func(d domain.SleepDetails) bool { return wanted[d.ActivityID] }) {
+		details = append(details, copySleepDetails(row))
+	}
+
+	return details, nil
+}
+
+// GetRecentSleepDetailsForBaby retrieves recent sleep details across all sessions for a baby
+func (s *MemoryStore) GetRecentSleepDetailsForBaby(ctx context.Context, babyID uuid.UUID, n int) ([]*domain.SleepDetails, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.sleepDetails, func(d domain.SleepDetails) bool { return s.data.forBaby(d.ActivityID, babyID) })
+	slices.SortFunc(rows, func(a, b domain.SleepDetails) int {
+		return compareTimes(b.StartTime, a.StartTime, b.ID, a.ID)
+	})
+
+	var details []*domain.SleepDetails
+	for _, row := range limit(rows, n) {
+		details = append(details, copySleepDetails(row))
+	}
+
+	return details, nil
+}
+
+// UpdateSleepDetails updates sleep details (for marking sleep as complete or editing)
+func (s *MemoryStore) UpdateSleepDetails(ctx context.Context, details *domain.SleepDetails) error {
+	defer s.lock()()
+
+	existing, ok := s.data.sleepDetails[details.ID]
+	if !ok {
+		return fmt.Errorf("sleep details not found: %s", details.ID)
+	}
+
+	existing.StartTime = details.StartTime
+	existing.EndTime = details.EndTime
+	existing.DurationMinutes = details.DurationMinutes
+	existing.UpdatedAt = time.Now()
+	s.data.sleepDetails[details.ID] = *copySleepDetails(existing)
+
+	return nil
+}
+
+// Pump Details
+
+func copyPumpDetails(d domain.PumpDetails) *domain.PumpDetails {
+	d.EndTime = clone(d.EndTime)
+	d.LeftMl = clone(d.LeftMl)
+	d.RightMl = clone(d.RightMl)
+	d.TotalMl = clone(d.TotalMl)
+	d.Notes = clone(d.Notes)
+	return &d
+}
+
+// CreatePumpDetails creates pump details for an activity
+func (s *MemoryStore) CreatePumpDetails(ctx context.Context, details *domain.PumpDetails) error {
+	defer s.lock()()
+
+	if _, ok := s.data.pumpDetails[details.ID]; ok {
+		return fmt.Errorf("failed to create pump details: pump details already exist: %s", details.ID)
+	}
+	if err := s.data.requireActivity(details.ActivityID); err != nil {
+		return fmt.Errorf("failed to create pump details: %w", err)
+	}
+
+	s.data.pumpDetails[details.ID] = *copyPumpDetails(*details)
+	return nil
+}
+
+// GetPumpDetails retrieves pump details for an activity
+func (s *MemoryStore) GetPumpDetails(ctx context.Context, activityID uuid.UUID) (*domain.PumpDetails, error) {
+	defer s.rlock()()
+
+	details, ok := first(s.data.pumpDetails, func(d domain.PumpDetails) bool { return d.ActivityID == activityID })
+	if !ok {
+		return nil, fmt.Errorf("pump details not found for activity: %s", activityID)
+	}
+
+	return copyPumpDetails(details), nil
+}
+
+// GetPumpDetailsByActivityIDs retrieves the pump details for several activities at once
+func (s *MemoryStore) GetPumpDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.PumpDetails, error) {
+	defer s.rlock()()
+
+	wanted := idSet(activityIDs)
+	var details []*domain.PumpDetails
+	for _, row := range filter(s.data.pumpDetails, func(d domain.PumpDetails) bool { return wanted[d.ActivityID] }) {
+		details = append(details, copyPumpDetails(row))
+	}
+
+	return details, nil
+}
+
+// UpdatePumpDetails updates pump details for an activity
+func (s *MemoryStore) UpdatePumpDetails(ctx context.Context, details *domain.PumpDetails) error {
+	defer s.lock()()
+
+	existing, ok := s.data.pumpDetails[details.ID]
+	if !ok {
+		return fmt.Errorf("pump details not found: %s", details.ID)
+	}
+
+	updated := *copyPumpDetails(*details)
+	updated.ActivityID = existing.ActivityID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now()
+	s.data.pumpDetails[details.ID] = updated
+
+	return nil
+}
+
+// Medication Details
+
+func copyMedicationDetails(d domain.MedicationDetails) *domain.MedicationDetails {
+	d.MedicationID = clone(d.MedicationID)
+	return &d
+}
+
+// CreateMedicationDetails creates medication details for an activity
+func (s *MemoryStore) CreateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error {
+	defer s.lock()()
+
+	if _, ok := s.data.medicationDetails[details.ID]; ok {
+		return fmt.Errorf("failed to create medication details: medication details already exist: %s", details.ID)
+	}
+	if err := s.data.requireActivity(details.ActivityID); err != nil {
+		return fmt.Errorf("failed to create medication details: %w", err)
+	}
+	if err := s.data.requireMedication(details.MedicationID); err != nil {
+		return fmt.Errorf("failed to create medication details: %w", err)
+	}
+
+	s.data.medicationDetails[details.ID] = *copyMedicationDetails(*details)
+	return nil
+}
+
+// GetMedicationDetails retrieves medication details for an activity
+func (s *MemoryStore) GetMedicationDetails(ctx context.Context, activityID uuid.UUID) (*domain.MedicationDetails, error) {
+	defer s.rlock()()
+
+	details, ok := first(s.data.medicationDetails, func(d domain.MedicationDetails) bool { return d.ActivityID == activityID })
+	if !ok {
+		return nil, fmt.Errorf("medication details not found for activity: %s", activityID)
+	}
+
+	return copyMedicationDetails(details), nil
+}
+
+// GetMedicationDetailsByActivityIDs retrieves the medication details for several activities at once
+func (s *MemoryStore) GetMedicationDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.MedicationDetails, error) {
+	defer s.rlock()()
+
+	wanted := idSet(activityIDs)
+	var details []*domain.MedicationDetails
+	for _, row := range filter(s.data.medicationDetails, func(d domain.MedicationDetails) bool { return wanted[d.ActivityID] }) {
+		details = append(details, copyMedicationDetails(row))
+	}
+
+	return details, nil
+}
+
+// mostRecentDoseFirst orders doses by given_at DESC
+func mostRecentDoseFirst(a, b domain.MedicationDetails) int {
+	return compareTimes(b.GivenAt, a.GivenAt, b.ID, a.ID)
+}
+
+// GetRecentMedicationDetailsForBaby retrieves all doses given to a baby at or after since across all sessions
+func (s *MemoryStore) GetRecentMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.MedicationDetails, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.medicationDetails, func(d domain.MedicationDetails) bool {
+		return s.data.forBaby(d.ActivityID, babyID) && !d.GivenAt.Before(since)
+	})
+	slices.SortFunc(rows, mostRecentDoseFirst)
+
+	var details []*domain.MedicationDetails
+	for _, row := range rows {
+		details = append(details, copyMedicationDetails(row))
+	}
+
+	return details, nil
+}
+
+// GetLatestMedicationDetailsForBaby retrieves the most recent dose of each distinct drug (by name, case-insensitive) given to a baby
+func (s *MemoryStore) GetLatestMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.MedicationDetails, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.medicationDetails, func(d domain.MedicationDetails) bool { return s.data.forBaby(d.ActivityID, babyID) })
+	slices.SortFunc(rows, func(a, b domain.MedicationDetails) int {
+		if c := strings.Compare(strings.ToLower(a.DrugName), strings.ToLower(b.DrugName)); c != 0 {
+			return c
+		}
+		return mostRecentDoseFirst(a, b)
+	})
+
+	var details []*domain.MedicationDetails
+	for i, row := range rows {
+		if i > 0 && strings.EqualFold(rows[i-1].DrugName, row.DrugName) {
+			continue
+		}
+		details = append(details, copyMedicationDetails(row))
+	}
+
+	return details, nil
+}
+
+// UpdateMedicationDetails updates medication details for an activity
+func (s *MemoryStore) UpdateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error {
+	defer s.lock()()
+
+	existing, ok := s.data.medicationDetails[details.ID]
+	if !ok {
+		return fmt.Errorf("medication details not found: %s", details.ID)
+	}
+	if err := s.data.requireMedication(details.MedicationID); err != nil {
+		return fmt.Errorf("failed to update medication details: %w", err)
+	}
+
+	updated := *copyMedicationDetails(*details)
+	updated.ActivityID = existing.ActivityID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now()
+	s.data.medicationDetails[details.ID] = updated
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Audit log operations
+
+func copyAuditEvent(event domain.AuditEvent) *domain.AuditEvent {
+	event.Before = clone(event.Before)
+	event.After = clone(event.After)
+	return &event
+}
+
+// CreateAuditEvent appends an event to its family's audit log
+func (s *MemoryStore) CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	defer s.lock()()
+
+	if _, ok := s.data.auditEvents[event.ID]; ok {
+		return fmt.Errorf("failed to create audit event: audit event already exists: %s", event.ID)
+	}
+	if err := s.data.requireFamily(event.FamilyID); err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+
+	s.data.auditEvents[event.ID] = *copyAuditEvent(*event)
+	return nil
+}
+
+// GetAuditEventsForFamily retrieves a page of a family's audit events matching filter using
+// keyset pagination, newest first. When afterTime/afterID are provided, returns events after
+// that cursor position.
+func (s *MemoryStore) GetAuditEventsForFamily(ctx context.Context, familyID uuid.UUID, f domain.AuditFilter, n int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.AuditEvent, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.auditEvents, func(e domain.AuditEvent) bool {
+		if e.FamilyID != familyID || !f.Matches(&e) {
+			return false
+		}
+		if afterTime == nil || afterID == nil {
+			return true
+		}
+		return e.CreatedAt.Before(*afterTime) ||
+			(e.CreatedAt.Equal(*afterTime) && bytes.Compare(e.ID[:], afterID[:]) < 0)
+	})
+	slices.SortFunc(rows, func(a, b domain.AuditEvent) int {
+		return compareTimes(b.CreatedAt, a.CreatedAt, b.ID, a.ID)
+	})
+
+	var events []*domain.AuditEvent
+	for _, row := range limit(rows, n) {
+		events = append(events, copyAuditEvent(row))
+	}
+
+	return events, nil
+}
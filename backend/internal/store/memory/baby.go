@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Baby operations
+
+func copyBaby(b domain.Baby) *domain.Baby {
+	b.BirthDate = clone(b.BirthDate)
+	b.Sex = clone(b.Sex)
+	return &b
+}
+
+// CreateBaby adds a baby to an existing family
+func (s *MemoryStore) CreateBaby(ctx context.Context, baby *domain.Baby) error {
+	defer s.lock()()
+
+	if err := s.data.insertBaby(baby); err != nil {
+		return fmt.Errorf("failed to create baby: %w", err)
+	}
+
+	return nil
+}
+
+func (t *tables) insertBaby(baby *domain.Baby) error {
+	if _, ok := t.babies[baby.ID]; ok {
+		return fmt.Errorf("baby already exists: %s", baby.ID)
+	}
+	if err := t.requireFamily(baby.FamilyID); err != nil {
+		return err
+	}
+	if err := t.checkBabyName(baby); err != nil {
+		return err
+	}
+
+	t.babies[baby.ID] = *copyBaby(*baby)
+	return nil
+}
+
+// checkBabyName enforces unique baby names (case-insensitive) within a family
+func (t *tables) checkBabyName(baby *domain.Baby) error {
+	for _, other := range t.babies {
+		if other.ID != baby.ID && other.FamilyID == baby.FamilyID && strings.EqualFold(other.Name, baby.Name) {
+			return fmt.Errorf("baby name already exists in family: %s", baby.Name)
+		}
+	}
+	return nil
+}
+
+func (t *tables) requireBaby(id uuid.UUID) error {
+	if _, ok := t.babies[id]; !ok {
+		return fmt.Errorf("baby not found: %s", id)
+	}
+	return nil
+}
+
+// GetBabyByID retrieves a baby by ID
+func (s *MemoryStore) GetBabyByID(ctx context.Context, id uuid.UUID) (*domain.Baby, error) {
+	defer s.rlock()()
+
+	baby, ok := s.data.babies[id]
+	if !ok {
+		return nil, fmt.Errorf("baby not found: %s", id)
+	}
+
+	return copyBaby(baby), nil
+}
+
+// GetBabiesForFamily retrieves a family's babies, oldest record first
+func (s *MemoryStore) GetBabiesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Baby, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.babies, func(b domain.Baby) bool { return b.FamilyID == familyID })
+	slices.SortFunc(rows, func(a, b domain.Baby) int {
+		return compareTimes(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	})
+
+	var babies []*domain.Baby
+	for _, row := range rows {
+		babies = append(babies, copyBaby(row))
+	}
+
+	return babies, nil
+}
+
+// UpdateBaby updates a baby's name, birth date and sex
+func (s *MemoryStore) UpdateBaby(ctx context.Context, baby *domain.Baby) error {
+	defer s.lock()()
+
+	existing, ok := s.data.babies[baby.ID]
+	if !ok {
+		return fmt.Errorf("baby not found: %s", baby.ID)
+	}
+	existing.Name = baby.Name
+	if err := s.data.checkBabyName(&existing); err != nil {
+		return fmt.Errorf("failed to update baby: %w", err)
+	}
+
+	existing.BirthDate = baby.BirthDate
+	existing.Sex = baby.Sex
+	existing.UpdatedAt = time.Now()
+	s.data.babies[baby.ID] = *copyBaby(existing)
+
+	return nil
+}
+
+// deleteBaby removes a baby and the activities, goals, predictions, measurements and
+// tombstones that reference it
+func (t *tables) deleteBaby(id uuid.UUID) {
+	for _, activity := range t.activities {
+		if activity.BabyID == id {
+			t.deleteActivity(activity.ID)
+		}
+	}
+	for _, p := range t.predictions {
+		if p.BabyID == id {
+			delete(t.predictions, p.ID)
+		}
+	}
+	for _, m := range t.growth {
+		if m.BabyID == id {
+			delete(t.growth, m.ID)
+		}
+	}
+	for _, d := range t.deletedActivities {
+		if d.BabyID == id {
+			delete(t.deletedActivities, d.ActivityID)
+		}
+	}
+	delete(t.scheduleGoals, id)
+	delete(t.babies, id)
+}
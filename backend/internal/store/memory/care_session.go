@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Care Session operations
+
+func copyCareSession(cs domain.CareSession) *domain.CareSession {
+	cs.CompletedAt = clone(cs.CompletedAt)
+	cs.Notes = clone(cs.Notes)
+	cs.DeletedAt = clone(cs.DeletedAt)
+	return &cs
+}
+
+// newestSessionFirst orders sessions by started_at DESC, id DESC
+func newestSessionFirst(a, b domain.CareSession) int {
+	return compareTimes(b.StartedAt, a.StartedAt, b.ID, a.ID)
+}
+
+func copyCareSessions(rows []domain.CareSession) []*domain.CareSession {
+	var sessions []*domain.CareSession
+	for _, row := range rows {
+		sessions = append(sessions, copyCareSession(row))
+	}
+	return sessions
+}
+
+// CreateCareSession creates a new care session
+func (s *MemoryStore) CreateCareSession(ctx context.Context, session *domain.CareSession) error {
+	defer s.lock()()
+
+	if _, ok := s.data.careSessions[session.ID]; ok {
+		return fmt.Errorf("failed to create care session: care session already exists: %s", session.ID)
+	}
+	if err := s.data.requireFamily(session.FamilyID); err != nil {
+		return fmt.Errorf("failed to create care session: %w", err)
+	}
+	if _, ok := s.data.caregivers[session.CaregiverID]; !ok {
+		return fmt.Errorf("failed to create care session: caregiver not found: %s", session.CaregiverID)
+	}
+
+	s.data.careSessions[session.ID] = *copyCareSession(*session)
+	return nil
+}
+
+// GetCareSessionByID retrieves a care session by ID
+func (s *MemoryStore) GetCareSessionByID(ctx context.Context, id uuid.UUID) (*domain.CareSession, error) {
+	defer s.rlock()()
+
+	session, ok := s.data.careSessions[id]
+	if !ok || session.DeletedAt != nil {
+		return nil, fmt.Errorf("care session not found: %s", id)
+	}
+
+	return copyCareSession(session), nil
+}
+
+// GetInProgressSessionForFamily retrieves the current in-progress session for a family
+func (s *MemoryStore) GetInProgressSessionForFamily(ctx context.Context, familyID uuid.UUID) (*domain.CareSession, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.careSessions, func(cs domain.CareSession) bool {
+		return cs.FamilyID == familyID && cs.Status == domain.StatusInProgress && cs.DeletedAt == nil
+	})
+	if len(rows) == 0 {
+		return nil, nil // No in-progress session is not an error
+	}
+
+	return copyCareSession(slices.MinFunc(rows, newestSessionFirst)), nil
+}
+
+// GetRecentCareSessionsForFamily retrieves recent completed sessions for a family
+func (s *MemoryStore) GetRecentCareSessionsForFamily(ctx context.Context, familyID uuid.UUID, n int) ([]*domain.CareSession, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.careSessions, func(cs domain.CareSession) bool {
+		return cs.FamilyID == familyID && cs.Status == domain.StatusCompleted && cs.DeletedAt == nil
+	})
+	slices.SortFunc(rows, newestSessionFirst)
+
+	return copyCareSessions(limit(rows, n)), nil
+}
+
+// GetCareSessionHistoryForFamily retrieves paginated sessions for a family using keyset pagination.
+// Returns all statuses (in_progress + completed), ordered newest first.
+// When afterTime/afterID are provided, returns sessions after that cursor position.
+func (s *MemoryStore) GetCareSessionHistoryForFamily(ctx context.Context, familyID uuid.UUID, n int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.CareSession, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.careSessions, func(cs domain.CareSession) bool {
+		if cs.FamilyID != familyID || cs.DeletedAt != nil {
+			return false
+		}
+		if afterTime == nil || afterID == nil {
+			return true
+		}
+		return cs.StartedAt.Before(*afterTime) ||
+			(cs.StartedAt.Equal(*afterTime) && bytes.Compare(cs.ID[:], afterID[:]) < 0)
+	})
+	slices.SortFunc(rows, newestSessionFirst)
+
+	return copyCareSessions(limit(rows, n)), nil
+}
+
+// UpdateCareSession updates an existing care session's status, completion time and notes
+func (s *MemoryStore) UpdateCareSession(ctx context.Context, session *domain.CareSession) error {
+	defer s.lock()()
+
+	existing, ok := s.data.careSessions[session.ID]
+	if !ok || existing.DeletedAt != nil {
+		return fmt.Errorf("care session not found: %s", session.ID)
+	}
+
+	existing.Status = session.Status
+	existing.CompletedAt = session.CompletedAt
+	existing.Notes = session.Notes
+	existing.UpdatedAt = time.Now()
+	s.data.careSessions[session.ID] = *copyCareSession(existing)
+
+	return nil
+}
+
+// DeleteCareSession moves a care session and its remaining activities to the trash. The
+// activities are stamped with the session's deleted_at so RestoreCareSession can tell them
+// apart from ones deleted earlier.
+func (s *MemoryStore) DeleteCareSession(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	session, ok := s.data.careSessions[id]
+	if !ok || session.DeletedAt != nil {
+		return fmt.Errorf("care session not found: %s", id)
+	}
+
+	now := time.Now()
+	for _, activity := range s.data.activities {
+		if activity.CareSessionID == id && activity.DeletedAt == nil {
+			activity.DeletedAt = &now
+			activity.UpdatedAt = now
+			s.data.activities[activity.ID] = *copyActivity(activity)
+		}
+	}
+	session.DeletedAt = &now
+	session.UpdatedAt = now
+	s.data.careSessions[id] = *copyCareSession(session)
+	return nil
+}
+
+// RestoreCareSession takes a care session out of the trash along with the activities
+// deleted with it
+func (s *MemoryStore) RestoreCareSession(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	session, ok := s.data.careSessions[id]
+	if !ok || session.DeletedAt == nil {
+		return fmt.Errorf("deleted care session not found: %s", id)
+	}
+
+	now := time.Now()
+	for _, activity := range s.data.activities {
+		if activity.CareSessionID == id && sameTime(activity.DeletedAt, session.DeletedAt) {
+			activity.DeletedAt = nil
+			activity.UpdatedAt = now
+			s.data.activities[activity.ID] = activity
+		}
+	}
+	session.DeletedAt = nil
+	session.UpdatedAt = now
+	s.data.careSessions[id] = session
+	return nil
+}
+
+// GetRecentlyDeletedCareSessionsForFamily retrieves a family's care sessions deleted after
+// since, most recently deleted first
+func (s *MemoryStore) GetRecentlyDeletedCareSessionsForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.CareSession, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.careSessions, func(cs domain.CareSession) bool {
+		return cs.FamilyID == familyID && cs.DeletedAt != nil && cs.DeletedAt.After(since)
+	})
+	slices.SortFunc(rows, func(a, b domain.CareSession) int {
+		return compareTimes(*b.DeletedAt, *a.DeletedAt, b.ID, a.ID)
+	})
+
+	return copyCareSessions(rows), nil
+}
+
+// deleteCareSession removes a session and its activities, and unlinks predictions
+// computed for it
+func (t *tables) deleteCareSession(id uuid.UUID) {
+	for _, activity := range t.activities {
+		if activity.CareSessionID == id {
+			t.deleteActivity(activity.ID)
+		}
+	}
+	for _, p := range t.predictions {
+		if p.CareSessionID != nil && *p.CareSessionID == id {
+			p.CareSessionID = nil
+			t.predictions[p.ID] = p
+		}
+	}
+	delete(t.careSessions, id)
+}
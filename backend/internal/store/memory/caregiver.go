@@ -0,0 +1,254 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Caregiver operations
+
+func copyCaregiver(c domain.Caregiver) *domain.Caregiver {
+	c.UserID = clone(c.UserID)
+	c.DeviceID = clone(c.DeviceID)
+	c.DeviceName = clone(c.DeviceName)
+	return &c
+}
+
+// CreateCaregiver creates a new caregiver
+func (s *MemoryStore) CreateCaregiver(ctx context.Context, caregiver *domain.Caregiver) error {
+	defer s.lock()()
+
+	if err := s.data.insertCaregiver(caregiver); err != nil {
+		return fmt.Errorf("failed to create caregiver: %w", err)
+	}
+
+	return nil
+}
+
+func (t *tables) insertCaregiver(caregiver *domain.Caregiver) error {
+	if _, ok := t.caregivers[caregiver.ID]; ok {
+		return fmt.Errorf("caregiver already exists: %s", caregiver.ID)
+	}
+	if err := t.requireFamily(caregiver.FamilyID); err != nil {
+		return err
+	}
+	if caregiver.UserID != nil {
+		if _, ok := t.users[*caregiver.UserID]; !ok {
+			return fmt.Errorf("user not found: %s", *caregiver.UserID)
+		}
+	}
+	if err := t.checkCaregiverUnique(caregiver); err != nil {
+		return err
+	}
+	if caregiver.Role == "" {
+		caregiver.Role = domain.RoleCaregiver
+	}
+	if !caregiver.Role.Valid() {
+		return fmt.Errorf("invalid role: %s", caregiver.Role)
+	}
+
+	t.caregivers[caregiver.ID] = *copyCaregiver(*caregiver)
+	return nil
+}
+
+// checkCaregiverUnique enforces that a device belongs to one caregiver and that a user
+// has at most one caregiver per family
+func (t *tables) checkCaregiverUnique(caregiver *domain.Caregiver) error {
+	for _, other := range t.caregivers {
+		if other.ID == caregiver.ID {
+			continue
+		}
+		if caregiver.DeviceID != nil && other.DeviceID != nil && *other.DeviceID == *caregiver.DeviceID {
+			return fmt.Errorf("device ID already in use: %s", *caregiver.DeviceID)
+		}
+		if caregiver.UserID != nil && other.UserID != nil && *other.UserID == *caregiver.UserID && other.FamilyID == caregiver.FamilyID {
+			return fmt.Errorf("user %s already has a caregiver in family %s", *caregiver.UserID, caregiver.FamilyID)
+		}
+	}
+	return nil
+}
+
+// GetCaregiverByID retrieves a caregiver by ID
+func (s *MemoryStore) GetCaregiverByID(ctx context.Context, id uuid.UUID) (*domain.Caregiver, error) {
+	defer s.rlock()()
+
+	caregiver, ok := s.data.caregivers[id]
+	if !ok {
+		return nil, fmt.Errorf("caregiver not found: %s", id)
+	}
+
+	return copyCaregiver(caregiver), nil
+}
+
+// GetCaregiverByDeviceID retrieves a caregiver by device ID
+func (s *MemoryStore) GetCaregiverByDeviceID(ctx context.Context, deviceID string) (*domain.Caregiver, error) {
+	defer s.rlock()()
+
+	for _, caregiver := range s.data.caregivers {
+		if caregiver.DeviceID != nil && *caregiver.DeviceID == deviceID {
+			return copyCaregiver(caregiver), nil
+		}
+	}
+
+	return nil, fmt.Errorf("caregiver not found with device ID: %s", deviceID)
+}
+
+// GetCaregiverByUserAndFamily retrieves a caregiver by user ID and family ID
+func (s *MemoryStore) GetCaregiverByUserAndFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (*domain.Caregiver, error) {
+	defer s.rlock()()
+
+	for _, caregiver := range s.data.caregivers {
+		if caregiver.UserID != nil && *caregiver.UserID == userID && caregiver.FamilyID == familyID {
+			return copyCaregiver(caregiver), nil
+		}
+	}
+
+	return nil, fmt.Errorf("caregiver not found for user %s in family %s", userID, familyID)
+}
+
+// GetCaregiversByFamily retrieves all caregivers for a family, oldest first
+func (s *MemoryStore) GetCaregiversByFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Caregiver, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.caregivers, func(c domain.Caregiver) bool { return c.FamilyID == familyID })
+	slices.SortFunc(rows, func(a, b domain.Caregiver) int {
+		return compareTimes(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	})
+
+	var caregivers []*domain.Caregiver
+	for _, row := range rows {
+		caregivers = append(caregivers, copyCaregiver(row))
+	}
+
+	return caregivers, nil
+}
+
+// GetCaregiversByIDs retrieves the caregivers among ids that exist
+func (s *MemoryStore) GetCaregiversByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Caregiver, error) {
+	defer s.rlock()()
+
+	wanted := idSet(ids)
+	var caregivers []*domain.Caregiver
+	for _, row := range filter(s.data.caregivers, func(c domain.Caregiver) bool { return wanted[c.ID] }) {
+		caregivers = append(caregivers, copyCaregiver(row))
+	}
+
+	return caregivers, nil
+}
+
+// UpdateCaregiver updates an existing caregiver's name and device
+func (s *MemoryStore) UpdateCaregiver(ctx context.Context, caregiver *domain.Caregiver) error {
+	defer s.lock()()
+
+	existing, ok := s.data.caregivers[caregiver.ID]
+	if !ok {
+		return fmt.Errorf("caregiver not found: %s", caregiver.ID)
+	}
+	existing.Name = caregiver.Name
+	existing.DeviceID = caregiver.DeviceID
+	existing.DeviceName = caregiver.DeviceName
+	if err := s.data.checkCaregiverUnique(&existing); err != nil {
+		return fmt.Errorf("failed to update caregiver: %w", err)
+	}
+
+	existing.UpdatedAt = time.Now()
+	s.data.caregivers[caregiver.ID] = *copyCaregiver(existing)
+
+	return nil
+}
+
+// LinkCaregiverToUser links an existing caregiver to a user
+func (s *MemoryStore) LinkCaregiverToUser(ctx context.Context, caregiverID uuid.UUID, userID uuid.UUID) error {
+	defer s.lock()()
+
+	existing, ok := s.data.caregivers[caregiverID]
+	if !ok {
+		return fmt.Errorf("caregiver not found: %s", caregiverID)
+	}
+	if _, ok := s.data.users[userID]; !ok {
+		return fmt.Errorf("failed to link caregiver to user: user not found: %s", userID)
+	}
+	existing.UserID = &userID
+	if err := s.data.checkCaregiverUnique(&existing); err != nil {
+		return fmt.Errorf("failed to link caregiver to user: %w", err)
+	}
+
+	existing.UpdatedAt = time.Now()
+	s.data.caregivers[caregiverID] = *copyCaregiver(existing)
+
+	return nil
+}
+
+// RotateDeviceToken advances a caregiver's device token generation and returns it
+func (s *MemoryStore) RotateDeviceToken(ctx context.Context, caregiverID uuid.UUID) (int, error) {
+	defer s.lock()()
+
+	existing, ok := s.data.caregivers[caregiverID]
+	if !ok {
+		return 0, fmt.Errorf("caregiver not found: %s", caregiverID)
+	}
+	existing.TokenGeneration++
+	s.data.caregivers[caregiverID] = existing
+
+	return existing.TokenGeneration, nil
+}
+
+// SetCaregiverRole changes a caregiver's role
+func (s *MemoryStore) SetCaregiverRole(ctx context.Context, caregiverID uuid.UUID, role domain.CaregiverRole) error {
+	defer s.lock()()
+
+	existing, ok := s.data.caregivers[caregiverID]
+	if !ok {
+		return fmt.Errorf("caregiver not found: %s", caregiverID)
+	}
+	if !role.Valid() {
+		return fmt.Errorf("failed to set caregiver role: invalid role: %s", role)
+	}
+
+	existing.Role = role
+	existing.UpdatedAt = time.Now()
+	s.data.caregivers[caregiverID] = existing
+
+	return nil
+}
+
+// DeleteCaregiver deletes a caregiver along with their care sessions
+func (s *MemoryStore) DeleteCaregiver(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	if _, ok := s.data.caregivers[id]; !ok {
+		return fmt.Errorf("caregiver not found: %s", id)
+	}
+
+	s.data.deleteCaregiver(id)
+	return nil
+}
+
+// deleteCaregiver removes a caregiver with their sessions and reminder preferences, and
+// unlinks the growth measurements they recorded
+func (t *tables) deleteCaregiver(id uuid.UUID) {
+	for _, session := range t.careSessions {
+		if session.CaregiverID == id {
+			t.deleteCareSession(session.ID)
+		}
+	}
+	for _, m := range t.growth {
+		if m.CaregiverID != nil && *m.CaregiverID == id {
+			m.CaregiverID = nil
+			t.growth[m.ID] = m
+		}
+	}
+	for _, invite := range t.invites {
+		if invite.CreatedBy != nil && *invite.CreatedBy == id {
+			invite.CreatedBy = nil
+			t.invites[invite.ID] = invite
+		}
+	}
+	delete(t.reminderPreferences, id)
+	delete(t.caregivers, id)
+}
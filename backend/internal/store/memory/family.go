@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Family operations
+
+func copyFamily(f domain.Family) *domain.Family {
+	f.Travel = clone(f.Travel)
+	return &f
+}
+
+// CreateFamilyWithCaregiver creates a family with its first baby and first caregiver atomically
+func (s *MemoryStore) CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, baby *domain.Baby, caregiver *domain.Caregiver) error {
+	return s.withTx(func(tx *MemoryStore) error {
+		t := tx.data
+
+		if err := t.insertFamily(family); err != nil {
+			return fmt.Errorf("failed to insert family: %w", err)
+		}
+		if err := t.insertBaby(baby); err != nil {
+			return fmt.Errorf("failed to insert baby: %w", err)
+		}
+		if err := t.insertCaregiver(caregiver); err != nil {
+			return fmt.Errorf("failed to insert caregiver: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (t *tables) insertFamily(family *domain.Family) error {
+	if _, ok := t.families[family.ID]; ok {
+		return fmt.Errorf("family already exists: %s", family.ID)
+	}
+	if t.familyByName(family.Name) != nil {
+		return fmt.Errorf("family name already exists: %s", family.Name)
+	}
+
+	t.families[family.ID] = *copyFamily(*family)
+	return nil
+}
+
+// familyByName finds a family by name, ignoring case
+func (t *tables) familyByName(name string) *domain.Family {
+	for _, family := range t.families {
+		if strings.EqualFold(family.Name, name) {
+			return copyFamily(family)
+		}
+	}
+	return nil
+}
+
+func (t *tables) requireFamily(id uuid.UUID) error {
+	if _, ok := t.families[id]; !ok {
+		return fmt.Errorf("family not found: %s", id)
+	}
+	return nil
+}
+
+// GetFamilyByID retrieves a family by ID
+func (s *MemoryStore) GetFamilyByID(ctx context.Context, id uuid.UUID) (*domain.Family, error) {
+	defer s.rlock()()
+
+	family, ok := s.data.families[id]
+	if !ok {
+		return nil, fmt.Errorf("family not found: %s", id)
+	}
+
+	return copyFamily(family), nil
+}
+
+// GetFamilyByName retrieves a family by name (case-insensitive)
+func (s *MemoryStore) GetFamilyByName(ctx context.Context, name string) (*domain.Family, error) {
+	defer s.rlock()()
+
+	family := s.data.familyByName(name)
+	if family == nil {
+		return nil, fmt.Errorf("family not found: %s", name)
+	}
+
+	return family, nil
+}
+
+// UpdateFamily updates an existing family's name, password hash, baby name, timezones and
+// prediction model
+func (s *MemoryStore) UpdateFamily(ctx context.Context, family *domain.Family) error {
+	defer s.lock()()
+
+	existing, ok := s.data.families[family.ID]
+	if !ok {
+		return fmt.Errorf("family not found: %s", family.ID)
+	}
+	if other := s.data.familyByName(family.Name); other != nil && other.ID != family.ID {
+		return fmt.Errorf("failed to update family: family name already exists: %s", family.Name)
+	}
+
+	existing.Name = family.Name
+	existing.PasswordHash = family.PasswordHash
+	existing.BabyName = family.BabyName
+	existing.Timezone = family.Timezone
+	existing.Travel = clone(family.Travel)
+	existing.PredictionModel = family.PredictionModel
+	existing.PredictionHalfLife = family.PredictionHalfLife.Truncate(time.Hour)
+	existing.UpdatedAt = time.Now()
+	s.data.families[family.ID] = existing
+
+	return nil
+}
+
+// DeleteFamily deletes a family and everything that belongs to it
+func (s *MemoryStore) DeleteFamily(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	if _, ok := s.data.families[id]; !ok {
+		return fmt.Errorf("family not found: %s", id)
+	}
+
+	s.data.deleteFamily(id)
+	return nil
+}
+
+func (t *tables) deleteFamily(id uuid.UUID) {
+	for _, caregiver := range t.caregivers {
+		if caregiver.FamilyID == id {
+			t.deleteCaregiver(caregiver.ID)
+		}
+	}
+	for _, baby := range t.babies {
+		if baby.FamilyID == id {
+			t.deleteBaby(baby.ID)
+		}
+	}
+	for _, medication := range t.medications {
+		if medication.FamilyID == id {
+			t.deleteMedication(medication.ID)
+		}
+	}
+	for _, m := range t.growth {
+		if m.FamilyID == id {
+			delete(t.growth, m.ID)
+		}
+	}
+	for _, p := range t.predictions {
+		if p.FamilyID == id {
+			delete(t.predictions, p.ID)
+		}
+	}
+	for key := range t.idempotencyKeys {
+		if key.familyID == id {
+			delete(t.idempotencyKeys, key)
+		}
+	}
+	for _, d := range t.deletedActivities {
+		if d.FamilyID == id {
+			delete(t.deletedActivities, d.ActivityID)
+		}
+	}
+	for _, sub := range t.webhookSubscriptions {
+		if sub.FamilyID == id {
+			t.deleteWebhookSubscription(sub.ID)
+		}
+	}
+	for _, invite := range t.invites {
+		if invite.FamilyID == id {
+			delete(t.invites, invite.ID)
+		}
+	}
+	for _, event := range t.auditEvents {
+		if event.FamilyID == id {
+			delete(t.auditEvents, event.ID)
+		}
+	}
+	delete(t.families, id)
+}
+
+// GetFamiliesByUserID retrieves all families where the user has a caregiver, oldest first
+func (s *MemoryStore) GetFamiliesByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Family, error) {
+	defer s.rlock()()
+
+	caregivers := filter(s.data.caregivers, func(c domain.Caregiver) bool {
+		return c.UserID != nil && *c.UserID == userID
+	})
+
+	var families []*domain.Family
+	for _, caregiver := range caregivers {
+		families = append(families, copyFamily(s.data.families[caregiver.FamilyID]))
+	}
+	slices.SortFunc(families, func(a, b *domain.Family) int {
+		return compareTimes(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	})
+
+	return families, nil
+}
+
+// GetActiveFamilyIDs retrieves the families with a care session in progress or updated
+// since the given time, ordered by ID
+func (s *MemoryStore) GetActiveFamilyIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	defer s.rlock()()
+
+	active := map[uuid.UUID]bool{}
+	for _, session := range s.data.careSessions {
+		if session.DeletedAt == nil && (session.Status == domain.StatusInProgress || !session.UpdatedAt.Before(since)) {
+			active[session.FamilyID] = true
+		}
+	}
+
+	familyIDs := slices.SortedFunc(maps.Keys(active), func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	return familyIDs, nil
+}
+
+// FamilyNameExists checks if a family name already exists (case-insensitive)
+func (s *MemoryStore) FamilyNameExists(ctx context.Context, name string) (bool, error) {
+	defer s.rlock()()
+
+	return s.data.familyByName(name) != nil, nil
+}
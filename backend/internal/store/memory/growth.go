@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Growth measurement operations
+
+func copyGrowthMeasurement(m domain.GrowthMeasurement) *domain.GrowthMeasurement {
+	m.CaregiverID = clone(m.CaregiverID)
+	m.WeightKg = clone(m.WeightKg)
+	m.LengthCm = clone(m.LengthCm)
+	m.HeadCircumferenceCm = clone(m.HeadCircumferenceCm)
+	m.Notes = clone(m.Notes)
+	return &m
+}
+
+// CreateGrowthMeasurement inserts a weight/length/head circumference measurement
+func (s *MemoryStore) CreateGrowthMeasurement(ctx context.Context, m *domain.GrowthMeasurement) error {
+	defer s.lock()()
+
+	if _, ok := s.data.growth[m.ID]; ok {
+		return fmt.Errorf("failed to create growth measurement: growth measurement already exists: %s", m.ID)
+	}
+	if err := s.data.requireFamily(m.FamilyID); err != nil {
+		return fmt.Errorf("failed to create growth measurement: %w", err)
+	}
+	if err := s.data.requireBaby(m.BabyID); err != nil {
+		return fmt.Errorf("failed to create growth measurement: %w", err)
+	}
+	if m.CaregiverID != nil {
+		if _, ok := s.data.caregivers[*m.CaregiverID]; !ok {
+			return fmt.Errorf("failed to create growth measurement: caregiver not found: %s", *m.CaregiverID)
+		}
+	}
+
+	s.data.growth[m.ID] = *copyGrowthMeasurement(*m)
+	return nil
+}
+
+// GetGrowthMeasurementsForBaby retrieves all of a baby's measurements, oldest first
+func (s *MemoryStore) GetGrowthMeasurementsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.GrowthMeasurement, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.growth, func(m domain.GrowthMeasurement) bool { return m.BabyID == babyID })
+	slices.SortFunc(rows, func(a, b domain.GrowthMeasurement) int {
+		return compareTimes(a.MeasuredAt, b.MeasuredAt, a.ID, b.ID)
+	})
+
+	var measurements []*domain.GrowthMeasurement
+	for _, row := range rows {
+		measurements = append(measurements, copyGrowthMeasurement(row))
+	}
+
+	return measurements, nil
+}
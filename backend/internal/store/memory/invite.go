@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Invite operations
+
+func copyFamilyInvite(i domain.FamilyInvite) *domain.FamilyInvite {
+	i.CreatedBy = clone(i.CreatedBy)
+	i.MaxUses = clone(i.MaxUses)
+	i.RevokedAt = clone(i.RevokedAt)
+	return &i
+}
+
+// CreateFamilyInvite creates an invite
+func (s *MemoryStore) CreateFamilyInvite(ctx context.Context, invite *domain.FamilyInvite) error {
+	defer s.lock()()
+
+	if _, ok := s.data.invites[invite.ID]; ok {
+		return fmt.Errorf("failed to create invite: invite already exists: %s", invite.ID)
+	}
+	if err := s.data.requireFamily(invite.FamilyID); err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+	if invite.CreatedBy != nil {
+		if _, ok := s.data.caregivers[*invite.CreatedBy]; !ok {
+			return fmt.Errorf("failed to create invite: caregiver not found: %s", *invite.CreatedBy)
+		}
+	}
+	if invite.MaxUses != nil && *invite.MaxUses <= 0 {
+		return fmt.Errorf("failed to create invite: max uses must be positive")
+	}
+	if invite.Role == "" {
+		invite.Role = domain.RoleCaregiver
+	}
+	if !invite.Role.Valid() {
+		return fmt.Errorf("failed to create invite: invalid role: %s", invite.Role)
+	}
+	for _, existing := range s.data.invites {
+		if existing.CodeHash == invite.CodeHash {
+			return fmt.Errorf("failed to create invite: code already in use")
+		}
+	}
+
+	s.data.invites[invite.ID] = *copyFamilyInvite(*invite)
+	return nil
+}
+
+// GetFamilyInviteByID retrieves an invite by ID
+func (s *MemoryStore) GetFamilyInviteByID(ctx context.Context, id uuid.UUID) (*domain.FamilyInvite, error) {
+	defer s.rlock()()
+
+	invite, ok := s.data.invites[id]
+	if !ok {
+		return nil, fmt.Errorf("invite not found: %s", id)
+	}
+
+	return copyFamilyInvite(invite), nil
+}
+
+// GetFamilyInviteByCodeHash retrieves an invite by the hash of its code
+func (s *MemoryStore) GetFamilyInviteByCodeHash(ctx context.Context, codeHash string) (*domain.FamilyInvite, error) {
+	defer s.rlock()()
+
+	invite, ok := first(s.data.invites, func(i domain.FamilyInvite) bool { return i.CodeHash == codeHash })
+	if !ok {
+		return nil, fmt.Errorf("invite not found")
+	}
+
+	return copyFamilyInvite(invite), nil
+}
+
+// GetFamilyInvitesForFamily retrieves a family's invites, newest first
+func (s *MemoryStore) GetFamilyInvitesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.FamilyInvite, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.invites, func(i domain.FamilyInvite) bool { return i.FamilyID == familyID })
+	slices.SortFunc(rows, func(a, b domain.FamilyInvite) int {
+		return compareTimes(b.CreatedAt, a.CreatedAt, b.ID, a.ID)
+	})
+
+	var invites []*domain.FamilyInvite
+	for _, row := range rows {
+		invites = append(invites, copyFamilyInvite(row))
+	}
+
+	return invites, nil
+}
+
+// RedeemFamilyInvite counts one use of the invite if it is active at now, and reports whether it was
+func (s *MemoryStore) RedeemFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	defer s.lock()()
+
+	invite, ok := s.data.invites[id]
+	if !ok {
+		return false, fmt.Errorf("invite not found: %s", id)
+	}
+	if !invite.Active(now) {
+		return false, nil
+	}
+
+	invite.UseCount++
+	invite.UpdatedAt = time.Now()
+	s.data.invites[id] = invite
+	return true, nil
+}
+
+// RevokeFamilyInvite revokes an invite. Revoking an already revoked invite keeps the original time.
+func (s *MemoryStore) RevokeFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) error {
+	defer s.lock()()
+
+	invite, ok := s.data.invites[id]
+	if !ok {
+		return fmt.Errorf("invite not found: %s", id)
+	}
+	if invite.RevokedAt == nil {
+		invite.RevokedAt = &now
+		invite.UpdatedAt = time.Now()
+		s.data.invites[id] = invite
+	}
+
+	return nil
+}
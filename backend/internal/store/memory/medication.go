@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func copyMedication(m domain.Medication) *domain.Medication {
+	m.DefaultDoseAmount = clone(m.DefaultDoseAmount)
+	m.DefaultDoseUnit = clone(m.DefaultDoseUnit)
+	m.DefaultRoute = clone(m.DefaultRoute)
+	m.MinIntervalMinutes = clone(m.MinIntervalMinutes)
+	m.MaxDailyDoses = clone(m.MaxDailyDoses)
+	return &m
+}
+
+// medicationByName finds a family's catalog entry by name, ignoring case
+func (t *tables) medicationByName(familyID uuid.UUID, name string) (domain.Medication, bool) {
+	return first(t.medications, func(m domain.Medication) bool {
+		return m.FamilyID == familyID && strings.EqualFold(m.Name, name)
+	})
+}
+
+// requireMedication checks an optional catalog link points at an existing entry
+func (t *tables) requireMedication(id *uuid.UUID) error {
+	if id == nil {
+		return nil
+	}
+	if _, ok := t.medications[*id]; !ok {
+		return fmt.Errorf("medication not found: %s", *id)
+	}
+	return nil
+}
+
+// UpsertMedication creates a catalog entry or replaces the one with the same name (case-insensitive) in the family
+func (s *MemoryStore) UpsertMedication(ctx context.Context, medication *domain.Medication) (*domain.Medication, error) {
+	defer s.lock()()
+
+	now := time.Now()
+	if medication.ID == uuid.Nil {
+		medication.ID = uuid.New()
+	}
+	if err := s.data.requireFamily(medication.FamilyID); err != nil {
+		return nil, fmt.Errorf("failed to upsert medication: %w", err)
+	}
+
+	row := *copyMedication(*medication)
+	if existing, ok := s.data.medicationByName(medication.FamilyID, medication.Name); ok {
+		row.ID = existing.ID
+		row.CreatedAt = existing.CreatedAt
+	} else {
+		if _, ok := s.data.medications[medication.ID]; ok {
+			return nil, fmt.Errorf("failed to upsert medication: medication already exists: %s", medication.ID)
+		}
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	s.data.medications[row.ID] = row
+
+	*medication = *copyMedication(row)
+	return medication, nil
+}
+
+// GetMedicationByID retrieves a catalog entry by ID
+func (s *MemoryStore) GetMedicationByID(ctx context.Context, id uuid.UUID) (*domain.Medication, error) {
+	defer s.rlock()()
+
+	m, ok := s.data.medications[id]
+	if !ok {
+		return nil, fmt.Errorf("medication not found: %s", id)
+	}
+
+	return copyMedication(m), nil
+}
+
+// GetMedicationByName retrieves a family's catalog entry by name (case-insensitive).
+// Returns (nil, nil) if the family has no entry for that name.
+func (s *MemoryStore) GetMedicationByName(ctx context.Context, familyID uuid.UUID, name string) (*domain.Medication, error) {
+	defer s.rlock()()
+
+	m, ok := s.data.medicationByName(familyID, name)
+	if !ok {
+		return nil, nil
+	}
+
+	return copyMedication(m), nil
+}
+
+// GetMedicationsForFamily retrieves a family's medication catalog ordered by name
+func (s *MemoryStore) GetMedicationsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Medication, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.medications, func(m domain.Medication) bool { return m.FamilyID == familyID })
+	slices.SortFunc(rows, func(a, b domain.Medication) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	var medications []*domain.Medication
+	for _, row := range rows {
+		medications = append(medications, copyMedication(row))
+	}
+
+	return medications, nil
+}
+
+// DeleteMedication removes a catalog entry. Logged doses keep their drug name but lose the catalog link.
+func (s *MemoryStore) DeleteMedication(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	if _, ok := s.data.medications[id]; !ok {
+		return fmt.Errorf("medication not found: %s", id)
+	}
+
+	s.data.deleteMedication(id)
+	return nil
+}
+
+func (t *tables) deleteMedication(id uuid.UUID) {
+	for _, d := range t.medicationDetails {
+		if d.MedicationID != nil && *d.MedicationID == id {
+			d.MedicationID = nil
+			t.medicationDetails[d.ID] = d
+		}
+	}
+	delete(t.medications, id)
+}
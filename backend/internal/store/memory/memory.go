@@ -0,0 +1,223 @@
+// Package memory provides an in-memory implementation of store.Store for tests and
+// local development. It mirrors PostgresStore's semantics: query ordering, unique
+// and foreign key constraints, cascading deletes and the updated_at trigger.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// tables holds one map per database table. Rows are stored by value so callers can
+// never mutate stored state through a pointer they passed in or got back.
+type tables struct {
+	families          map[uuid.UUID]domain.Family
+	babies            map[uuid.UUID]domain.Baby
+	users             map[uuid.UUID]domain.User
+	caregivers        map[uuid.UUID]domain.Caregiver
+	careSessions      map[uuid.UUID]domain.CareSession
+	activities        map[uuid.UUID]domain.Activity
+	feedDetails       map[uuid.UUID]domain.FeedDetails
+	diaperDetails     map[uuid.UUID]domain.DiaperDetails
+	sleepDetails      map[uuid.UUID]domain.SleepDetails
+	pumpDetails       map[uuid.UUID]domain.PumpDetails
+	medicationDetails map[uuid.UUID]domain.MedicationDetails
+	medications       map[uuid.UUID]domain.Medication
+	growth            map[uuid.UUID]domain.GrowthMeasurement
+	predictions       map[uuid.UUID]domain.Prediction
+	outcomes          map[uuid.UUID]domain.PredictionOutcome
+	scheduleGoals     map[uuid.UUID]domain.ScheduleGoals // keyed by baby ID
+	idempotencyKeys   map[idempotencyKey]domain.IdempotencyRecord
+	deletedActivities map[uuid.UUID]domain.DeletedActivity // keyed by activity ID
+
+	reminderPreferences  map[uuid.UUID]domain.ReminderPreferences // keyed by caregiver ID
+	webhookSubscriptions map[uuid.UUID]domain.WebhookSubscription
+	webhookDeliveries    map[uuid.UUID]domain.WebhookDelivery
+	invites              map[uuid.UUID]domain.FamilyInvite
+	auditEvents          map[uuid.UUID]domain.AuditEvent
+}
+
+type idempotencyKey struct {
+	familyID uuid.UUID
+	key      string
+}
+
+func newTables() *tables {
+	return &tables{
+		families:          map[uuid.UUID]domain.Family{},
+		babies:            map[uuid.UUID]domain.Baby{},
+		users:             map[uuid.UUID]domain.User{},
+		caregivers:        map[uuid.UUID]domain.Caregiver{},
+		careSessions:      map[uuid.UUID]domain.CareSession{},
+		activities:        map[uuid.UUID]domain.Activity{},
+		feedDetails:       map[uuid.UUID]domain.FeedDetails{},
+		diaperDetails:     map[uuid.UUID]domain.DiaperDetails{},
+		sleepDetails:      map[uuid.UUID]domain.SleepDetails{},
+		pumpDetails:       map[uuid.UUID]domain.PumpDetails{},
+		medicationDetails: map[uuid.UUID]domain.MedicationDetails{},
+		medications:       map[uuid.UUID]domain.Medication{},
+		growth:            map[uuid.UUID]domain.GrowthMeasurement{},
+		predictions:       map[uuid.UUID]domain.Prediction{},
+		outcomes:          map[uuid.UUID]domain.PredictionOutcome{},
+		scheduleGoals:     map[uuid.UUID]domain.ScheduleGoals{},
+		idempotencyKeys:   map[idempotencyKey]domain.IdempotencyRecord{},
+		deletedActivities: map[uuid.UUID]domain.DeletedActivity{},
+
+		reminderPreferences:  map[uuid.UUID]domain.ReminderPreferences{},
+		webhookSubscriptions: map[uuid.UUID]domain.WebhookSubscription{},
+		webhookDeliveries:    map[uuid.UUID]domain.WebhookDelivery{},
+		invites:              map[uuid.UUID]domain.FamilyInvite{},
+		auditEvents:          map[uuid.UUID]domain.AuditEvent{},
+	}
+}
+
+// clone copies every table. Rows are stored with their own copies of pointer fields
+// and are replaced rather than modified, so a shallow copy of each map is a full snapshot.
+func (t *tables) clone() *tables {
+	return &tables{
+		families:          maps.Clone(t.families),
+		babies:            maps.Clone(t.babies),
+		users:             maps.Clone(t.users),
+		caregivers:        maps.Clone(t.caregivers),
+		careSessions:      maps.Clone(t.careSessions),
+		activities:        maps.Clone(t.activities),
+		feedDetails:       maps.Clone(t.feedDetails),
+		diaperDetails:     maps.Clone(t.diaperDetails),
+		sleepDetails:      maps.Clone(t.sleepDetails),
+		pumpDetails:       maps.Clone(t.pumpDetails),
+		medicationDetails: maps.Clone(t.medicationDetails),
+		medications:       maps.Clone(t.medications),
+		growth:            maps.Clone(t.growth),
+		predictions:       maps.Clone(t.predictions),
+		outcomes:          maps.Clone(t.outcomes),
+		scheduleGoals:     maps.Clone(t.scheduleGoals),
+		idempotencyKeys:   maps.Clone(t.idempotencyKeys),
+		deletedActivities: maps.Clone(t.deletedActivities),
+
+		reminderPreferences:  maps.Clone(t.reminderPreferences),
+		webhookSubscriptions: maps.Clone(t.webhookSubscriptions),
+		webhookDeliveries:    maps.Clone(t.webhookDeliveries),
+		invites:              maps.Clone(t.invites),
+		auditEvents:          maps.Clone(t.auditEvents),
+	}
+}
+
+// MemoryStore implements the Store interface with maps guarded by a mutex
+type MemoryStore struct {
+	mu   *sync.RWMutex
+	data *tables
+	inTx bool // set when the store is bound to a transaction by WithTx
+}
+
+// Ensure MemoryStore implements Store interface
+var _ store.Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{mu: &sync.RWMutex{}, data: newTables()}
+}
+
+// Close is a no-op; there is no connection to release
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// WithTx runs fn against a store bound to a single transaction. The transaction holds
+// the write lock for its whole duration, so transactions are serializable, and if fn
+// returns an error every table is restored to its state before fn ran. Calls nested
+// inside fn join the outer transaction rather than starting their own.
+//
+// fn must use the store it is given: calling the outer store from inside fn blocks
+// until the transaction finishes.
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return s.withTx(func(tx *MemoryStore) error {
+		return fn(tx)
+	})
+}
+
+func (s *MemoryStore) withTx(fn func(*MemoryStore) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&MemoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+
+	return nil
+}
+
+// lock takes the write lock unless the store is bound to a transaction, which already
+// holds it, and returns the matching unlock
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// rlock is lock for read-only operations
+func (s *MemoryStore) rlock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// filter returns the rows of a table that match keep, in no particular order
+func filter[K comparable, V any](table map[K]V, keep func(V) bool) []V {
+	var rows []V
+	for _, row := range table {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// idSet returns ids as a set, for matching rows against a batch of IDs
+func idSet(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// limit truncates rows to at most n, matching SQL LIMIT
+func limit[T any](rows []T, n int) []T {
+	if n < len(rows) {
+		return rows[:max(n, 0)]
+	}
+	return rows
+}
+
+// compareTimes orders a before b, breaking ties by ID so results are deterministic
+func compareTimes(a, b time.Time, aID, bID uuid.UUID) int {
+	if c := a.Compare(b); c != 0 {
+		return c
+	}
+	return bytes.Compare(aID[:], bID[:])
+}
+
+func clone[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
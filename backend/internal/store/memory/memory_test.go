@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+	"github.com/swatkatz/babybaton/backend/internal/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, NewMemoryStore())
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	family := &domain.Family{ID: uuid.New(), Name: "Concurrent Family", CreatedAt: now, UpdatedAt: now}
+	baby := &domain.Baby{ID: uuid.New(), FamilyID: family.ID, Name: "Baby", CreatedAt: now, UpdatedAt: now}
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: family.ID, Name: "Caregiver", CreatedAt: now, UpdatedAt: now}
+	if err := s.CreateFamilyWithCaregiver(ctx, family, baby, caregiver); err != nil {
+		t.Fatalf("Failed to create family: %v", err)
+	}
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.WithTx(ctx, func(tx store.Store) error {
+				session := &domain.CareSession{
+					ID: uuid.New(), CaregiverID: caregiver.ID, FamilyID: family.ID,
+					Status: domain.StatusCompleted, StartedAt: now, CreatedAt: now, UpdatedAt: now,
+				}
+				if err := tx.CreateCareSession(ctx, session); err != nil {
+					return err
+				}
+				if i%2 == 1 {
+					return fmt.Errorf("rollback %d", i)
+				}
+				return nil
+			})
+			if err != nil && i%2 == 0 {
+				t.Errorf("Transaction %d failed: %v", i, err)
+			}
+			if _, err := s.GetRecentCareSessionsForFamily(ctx, family.ID, workers); err != nil {
+				t.Errorf("Failed to read sessions: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sessions, err := s.GetRecentCareSessionsForFamily(ctx, family.ID, workers)
+	if err != nil {
+		t.Fatalf("Failed to get sessions: %v", err)
+	}
+	if len(sessions) != workers/2 {
+		t.Errorf("Expected %d committed sessions, got %d", workers/2, len(sessions))
+	}
+}
+
+func TestReturnedRowsAreCopies(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	deviceName := "Phone"
+	family := &domain.Family{ID: uuid.New(), Name: "Copy Family", CreatedAt: now, UpdatedAt: now}
+	baby := &domain.Baby{ID: uuid.New(), FamilyID: family.ID, Name: "Baby", CreatedAt: now, UpdatedAt: now}
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: family.ID, Name: "Caregiver", DeviceName: &deviceName, CreatedAt: now, UpdatedAt: now}
+	if err := s.CreateFamilyWithCaregiver(ctx, family, baby, caregiver); err != nil {
+		t.Fatalf("Failed to create family: %v", err)
+	}
+
+	// Neither the caller's input nor a returned row aliases stored state
+	deviceName = "Changed"
+	got, err := s.GetCaregiverByID(ctx, caregiver.ID)
+	if err != nil {
+		t.Fatalf("Failed to get caregiver: %v", err)
+	}
+	*got.DeviceName = "Also Changed"
+	got.Name = "Renamed"
+
+	got, err = s.GetCaregiverByID(ctx, caregiver.ID)
+	if err != nil {
+		t.Fatalf("Failed to get caregiver: %v", err)
+	}
+	if *got.DeviceName != "Phone" || got.Name != "Caregiver" {
+		t.Errorf("Expected stored caregiver to be unchanged, got %q on %q", got.Name, *got.DeviceName)
+	}
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func copyPrediction(p domain.Prediction) *domain.Prediction {
+	p.CareSessionID = clone(p.CareSessionID)
+	p.Confidence = clone(p.Confidence)
+	p.Reasoning = clone(p.Reasoning)
+	p.PredictedAmountMl = clone(p.PredictedAmountMl)
+	p.PredictedDurationMinutes = clone(p.PredictedDurationMinutes)
+	p.DismissedAt = clone(p.DismissedAt)
+	return &p
+}
+
+// UpsertPredictions replaces a baby's non-dismissed predictions. Dismissed ones are kept
+// so a dismissal sticks until the prediction ages out.
+func (s *MemoryStore) UpsertPredictions(ctx context.Context, babyID uuid.UUID, predictions []*domain.Prediction) error {
+	return s.withTx(func(tx *MemoryStore) error {
+		t := tx.data
+
+		for _, p := range t.predictions {
+			if p.BabyID == babyID && p.DismissedAt == nil {
+				delete(t.predictions, p.ID)
+			}
+		}
+
+		now := time.Now()
+		for _, p := range predictions {
+			if _, ok := t.predictions[p.ID]; ok {
+				return fmt.Errorf("prediction already exists: %s", p.ID)
+			}
+			if err := t.requireFamily(p.FamilyID); err != nil {
+				return err
+			}
+			if err := t.requireBaby(babyID); err != nil {
+				return err
+			}
+			if p.CareSessionID != nil {
+				if _, ok := t.careSessions[*p.CareSessionID]; !ok {
+					return fmt.Errorf("care session not found: %s", *p.CareSessionID)
+				}
+			}
+
+			row := *copyPrediction(*p)
+			row.BabyID = babyID
+			row.DismissedAt = nil
+			row.CreatedAt = now
+			t.predictions[row.ID] = row
+		}
+
+		return nil
+	})
+}
+
+// GetPredictionsForBaby retrieves a baby's non-dismissed predictions, soonest first
+func (s *MemoryStore) GetPredictionsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.Prediction, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.predictions, func(p domain.Prediction) bool {
+		return p.BabyID == babyID && p.DismissedAt == nil
+	})
+	slices.SortFunc(rows, func(a, b domain.Prediction) int {
+		return compareTimes(a.PredictedTime, b.PredictedTime, a.ID, b.ID)
+	})
+
+	var predictions []*domain.Prediction
+	for _, row := range rows {
+		predictions = append(predictions, copyPrediction(row))
+	}
+
+	return predictions, nil
+}
+
+// DismissPrediction hides a prediction until it is cleaned up. Returns sql.ErrNoRows,
+// like PostgresStore, if the prediction doesn't exist.
+func (s *MemoryStore) DismissPrediction(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	p, ok := s.data.predictions[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now()
+	p.DismissedAt = &now
+	s.data.predictions[id] = p
+
+	return nil
+}
+
+// DeletePredictionsForBaby removes all of a baby's predictions, dismissed or not
+func (s *MemoryStore) DeletePredictionsForBaby(ctx context.Context, babyID uuid.UUID) error {
+	defer s.lock()()
+
+	for _, p := range s.data.predictions {
+		if p.BabyID == babyID {
+			delete(s.data.predictions, p.ID)
+		}
+	}
+
+	return nil
+}
+
+// CleanupOldPredictions removes predictions created before olderThan
+func (s *MemoryStore) CleanupOldPredictions(ctx context.Context, olderThan time.Time) error {
+	defer s.lock()()
+
+	for _, p := range s.data.predictions {
+		if p.CreatedAt.Before(olderThan) {
+			delete(s.data.predictions, p.ID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Prediction outcome operations
+
+func copyPredictionOutcome(o domain.PredictionOutcome) *domain.PredictionOutcome {
+	o.Confidence = clone(o.Confidence)
+	return &o
+}
+
+// CreatePredictionOutcome records how a prediction turned out. A prediction keeps its first
+// outcome; later ones are ignored.
+func (s *MemoryStore) CreatePredictionOutcome(ctx context.Context, o *domain.PredictionOutcome) error {
+	defer s.lock()()
+
+	if _, ok := s.data.outcomes[o.ID]; ok {
+		return fmt.Errorf("failed to create prediction outcome: prediction outcome already exists: %s", o.ID)
+	}
+	if err := s.data.requireFamily(o.FamilyID); err != nil {
+		return fmt.Errorf("failed to create prediction outcome: %w", err)
+	}
+	if err := s.data.requireBaby(o.BabyID); err != nil {
+		return fmt.Errorf("failed to create prediction outcome: %w", err)
+	}
+	if err := s.data.requireActivity(o.ActivityID); err != nil {
+		return fmt.Errorf("failed to create prediction outcome: %w", err)
+	}
+	for _, existing := range s.data.outcomes {
+		if existing.PredictionID == o.PredictionID {
+			return nil
+		}
+	}
+
+	s.data.outcomes[o.ID] = *copyPredictionOutcome(*o)
+	return nil
+}
+
+// GetPredictionOutcomesForFamily retrieves the outcomes of a family's events since since, oldest first
+func (s *MemoryStore) GetPredictionOutcomesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return s.predictionOutcomes(func(o domain.PredictionOutcome) bool { return o.FamilyID == familyID }, since), nil
+}
+
+// GetPredictionOutcomesForBaby retrieves the outcomes of a baby's events since since, oldest first
+func (s *MemoryStore) GetPredictionOutcomesForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return s.predictionOutcomes(func(o domain.PredictionOutcome) bool { return o.BabyID == babyID }, since), nil
+}
+
+func (s *MemoryStore) predictionOutcomes(keep func(domain.PredictionOutcome) bool, since time.Time) []*domain.PredictionOutcome {
+	defer s.rlock()()
+
+	rows := filter(s.data.outcomes, func(o domain.PredictionOutcome) bool {
+		return keep(o) && !o.ActualTime.Before(since)
+	})
+	slices.SortFunc(rows, func(a, b domain.PredictionOutcome) int {
+		return compareTimes(a.ActualTime, b.ActualTime, a.ID, b.ID)
+	})
+
+	var outcomes []*domain.PredictionOutcome
+	for _, row := range rows {
+		outcomes = append(outcomes, copyPredictionOutcome(row))
+	}
+	return outcomes
+}
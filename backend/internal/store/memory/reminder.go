@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Reminder preference operations
+
+// GetReminderPreferences retrieves a caregiver's reminder preferences. Returns (nil, nil) if none are set.
+func (s *MemoryStore) GetReminderPreferences(ctx context.Context, caregiverID uuid.UUID) (*domain.ReminderPreferences, error) {
+	defer s.rlock()()
+
+	prefs, ok := s.data.reminderPreferences[caregiverID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &prefs, nil
+}
+
+// GetReminderPreferencesForFamily retrieves the reminder preferences of every caregiver
+// in a family, oldest first
+func (s *MemoryStore) GetReminderPreferencesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.ReminderPreferences, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.reminderPreferences, func(p domain.ReminderPreferences) bool { return p.FamilyID == familyID })
+	slices.SortFunc(rows, func(a, b domain.ReminderPreferences) int {
+		return compareTimes(a.CreatedAt, b.CreatedAt, a.CaregiverID, b.CaregiverID)
+	})
+
+	var result []*domain.ReminderPreferences
+	for _, row := range rows {
+		result = append(result, &row)
+	}
+
+	return result, nil
+}
+
+// UpsertReminderPreferences sets a caregiver's reminder preferences. The family is taken
+// from the caregiver.
+func (s *MemoryStore) UpsertReminderPreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	defer s.lock()()
+
+	caregiver, ok := s.data.caregivers[prefs.CaregiverID]
+	if !ok {
+		return nil, fmt.Errorf("caregiver not found: %s", prefs.CaregiverID)
+	}
+	if prefs.LeadMinutes < 0 || prefs.LeadMinutes > domain.MaxReminderLeadMinutes {
+		return nil, fmt.Errorf("failed to save reminder preferences: lead minutes out of range: %d", prefs.LeadMinutes)
+	}
+
+	now := time.Now()
+	row, ok := s.data.reminderPreferences[prefs.CaregiverID]
+	if !ok {
+		row = domain.ReminderPreferences{CaregiverID: caregiver.ID, CreatedAt: now}
+	}
+	row.FamilyID = caregiver.FamilyID
+	row.FeedReminders = prefs.FeedReminders
+	row.NapReminders = prefs.NapReminders
+	row.BedtimeReminders = prefs.BedtimeReminders
+	row.OverdueReminders = prefs.OverdueReminders
+	row.LeadMinutes = prefs.LeadMinutes
+	row.UpdatedAt = now
+	s.data.reminderPreferences[prefs.CaregiverID] = row
+
+	*prefs = row
+	return prefs, nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+func copyScheduleGoals(g domain.ScheduleGoals) *domain.ScheduleGoals {
+	g.TargetWakeWindowMinutes = clone(g.TargetWakeWindowMinutes)
+	g.TargetFeedIntervalMinutes = clone(g.TargetFeedIntervalMinutes)
+	g.TargetNapCount = clone(g.TargetNapCount)
+	g.MaxDaytimeNapMinutes = clone(g.MaxDaytimeNapMinutes)
+	g.TargetBedtime = clone(g.TargetBedtime)
+	g.TargetWakeTime = clone(g.TargetWakeTime)
+	return &g
+}
+
+// GetScheduleGoals retrieves a baby's schedule goals. Returns (nil, nil) if none are set.
+func (s *MemoryStore) GetScheduleGoals(ctx context.Context, babyID uuid.UUID) (*domain.ScheduleGoals, error) {
+	defer s.rlock()()
+
+	goals, ok := s.data.scheduleGoals[babyID]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyScheduleGoals(goals), nil
+}
+
+// UpsertScheduleGoals sets a baby's schedule goals. Fields left nil keep their current value.
+func (s *MemoryStore) UpsertScheduleGoals(ctx context.Context, babyID uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error) {
+	defer s.lock()()
+
+	baby, ok := s.data.babies[babyID]
+	if !ok {
+		return nil, fmt.Errorf("baby not found: %s", babyID)
+	}
+
+	now := time.Now()
+	row, ok := s.data.scheduleGoals[babyID]
+	if !ok {
+		row = domain.ScheduleGoals{
+			ID:        uuid.New(),
+			FamilyID:  baby.FamilyID,
+			BabyID:    babyID,
+			CreatedAt: now,
+		}
+	}
+	row.TargetWakeWindowMinutes = coalesce(goals.TargetWakeWindowMinutes, row.TargetWakeWindowMinutes)
+	row.TargetFeedIntervalMinutes = coalesce(goals.TargetFeedIntervalMinutes, row.TargetFeedIntervalMinutes)
+	row.TargetNapCount = coalesce(goals.TargetNapCount, row.TargetNapCount)
+	row.MaxDaytimeNapMinutes = coalesce(goals.MaxDaytimeNapMinutes, row.MaxDaytimeNapMinutes)
+	row.TargetBedtime = coalesce(goals.TargetBedtime, row.TargetBedtime)
+	row.TargetWakeTime = coalesce(goals.TargetWakeTime, row.TargetWakeTime)
+	row.UpdatedAt = now
+	s.data.scheduleGoals[babyID] = *copyScheduleGoals(row)
+
+	*goals = *copyScheduleGoals(row)
+	return goals, nil
+}
+
+// coalesce returns the first non-nil pointer, matching SQL COALESCE
+func coalesce[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Offline sync operations
+
+// GetIdempotencyRecord looks up a previously applied write by its idempotency key.
+// Returns (nil, nil) if the key hasn't been seen.
+func (s *MemoryStore) GetIdempotencyRecord(ctx context.Context, familyID uuid.UUID, key string) (*domain.IdempotencyRecord, error) {
+	defer s.rlock()()
+
+	record, ok := s.data.idempotencyKeys[idempotencyKey{familyID: familyID, key: key}]
+	if !ok {
+		return nil, nil
+	}
+
+	return &record, nil
+}
+
+// SaveIdempotencyRecord marks a write as applied
+func (s *MemoryStore) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	defer s.lock()()
+
+	k := idempotencyKey{familyID: record.FamilyID, key: record.Key}
+	if _, ok := s.data.idempotencyKeys[k]; ok {
+		return fmt.Errorf("failed to save idempotency record: key already used: %s", record.Key)
+	}
+	if err := s.data.requireFamily(record.FamilyID); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+
+	s.data.idempotencyKeys[k] = *record
+	return nil
+}
+
+// RecordDeletedActivity stores a tombstone for a deleted activity. Recording the same
+// activity twice keeps the first tombstone.
+func (s *MemoryStore) RecordDeletedActivity(ctx context.Context, deleted *domain.DeletedActivity) error {
+	defer s.lock()()
+
+	if _, ok := s.data.deletedActivities[deleted.ActivityID]; ok {
+		return nil
+	}
+	if err := s.data.requireFamily(deleted.FamilyID); err != nil {
+		return fmt.Errorf("failed to record deleted activity: %w", err)
+	}
+	if err := s.data.requireBaby(deleted.BabyID); err != nil {
+		return fmt.Errorf("failed to record deleted activity: %w", err)
+	}
+
+	s.data.deletedActivities[deleted.ActivityID] = *deleted
+	return nil
+}
+
+// GetDeletedActivity retrieves the tombstone for an activity.
+// Returns (nil, nil) if the activity hasn't been deleted.
+func (s *MemoryStore) GetDeletedActivity(ctx context.Context, activityID uuid.UUID) (*domain.DeletedActivity, error) {
+	defer s.rlock()()
+
+	deleted, ok := s.data.deletedActivities[activityID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &deleted, nil
+}
+
+// ClearDeletedActivity removes the tombstone for a restored activity
+func (s *MemoryStore) ClearDeletedActivity(ctx context.Context, activityID uuid.UUID) error {
+	defer s.lock()()
+
+	delete(s.data.deletedActivities, activityID)
+	return nil
+}
+
+// GetDeletedActivitiesSinceForFamily retrieves tombstones recorded after since, oldest first
+func (s *MemoryStore) GetDeletedActivitiesSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.DeletedActivity, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.deletedActivities, func(d domain.DeletedActivity) bool {
+		return d.FamilyID == familyID && d.DeletedAt.After(since)
+	})
+	slices.SortFunc(rows, func(a, b domain.DeletedActivity) int {
+		return compareTimes(a.DeletedAt, b.DeletedAt, a.ActivityID, b.ActivityID)
+	})
+
+	var deleted []*domain.DeletedActivity
+	for _, row := range rows {
+		deleted = append(deleted, &row)
+	}
+
+	return deleted, nil
+}
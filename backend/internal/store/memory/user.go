@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// User operations
+
+// CreateUser creates a new user
+func (s *MemoryStore) CreateUser(ctx context.Context, user *domain.User) error {
+	defer s.lock()()
+
+	if _, ok := s.data.users[user.ID]; ok {
+		return fmt.Errorf("failed to create user: user already exists: %s", user.ID)
+	}
+	for _, other := range s.data.users {
+		if other.SupabaseUserID == user.SupabaseUserID {
+			return fmt.Errorf("failed to create user: supabase ID already in use: %s", user.SupabaseUserID)
+		}
+	}
+
+	s.data.users[user.ID] = *user
+	return nil
+}
+
+// GetUserBySupabaseID retrieves a user by their Supabase user ID
+func (s *MemoryStore) GetUserBySupabaseID(ctx context.Context, supabaseUserID string) (*domain.User, error) {
+	defer s.rlock()()
+
+	for _, user := range s.data.users {
+		if user.SupabaseUserID == supabaseUserID {
+			return &user, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user not found with supabase ID: %s", supabaseUserID)
+}
+
+// GetUserByID retrieves a user by their internal ID
+func (s *MemoryStore) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	defer s.rlock()()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", id)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Webhook operations
+
+func copyWebhookSubscription(sub domain.WebhookSubscription) *domain.WebhookSubscription {
+	sub.EventTypes = slices.Clone(sub.EventTypes)
+	return &sub
+}
+
+func copyWebhookDelivery(d domain.WebhookDelivery) *domain.WebhookDelivery {
+	d.LastStatusCode = clone(d.LastStatusCode)
+	d.LastError = clone(d.LastError)
+	d.DeliveredAt = clone(d.DeliveredAt)
+	return &d
+}
+
+// CreateWebhookSubscription creates a webhook subscription
+func (s *MemoryStore) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	defer s.lock()()
+
+	if _, ok := s.data.webhookSubscriptions[sub.ID]; ok {
+		return fmt.Errorf("failed to create webhook subscription: webhook subscription already exists: %s", sub.ID)
+	}
+	if err := s.data.requireFamily(sub.FamilyID); err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	s.data.webhookSubscriptions[sub.ID] = *copyWebhookSubscription(*sub)
+	return nil
+}
+
+// GetWebhookSubscriptionByID retrieves a webhook subscription by ID
+func (s *MemoryStore) GetWebhookSubscriptionByID(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	defer s.rlock()()
+
+	sub, ok := s.data.webhookSubscriptions[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook subscription not found: %s", id)
+	}
+
+	return copyWebhookSubscription(sub), nil
+}
+
+// GetWebhookSubscriptionsForFamily retrieves a family's webhook subscriptions, oldest first
+func (s *MemoryStore) GetWebhookSubscriptionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.webhookSubscriptions, func(sub domain.WebhookSubscription) bool { return sub.FamilyID == familyID })
+	slices.SortFunc(rows, func(a, b domain.WebhookSubscription) int {
+		return compareTimes(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	})
+
+	var subs []*domain.WebhookSubscription
+	for _, row := range rows {
+		subs = append(subs, copyWebhookSubscription(row))
+	}
+
+	return subs, nil
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription and its deliveries
+func (s *MemoryStore) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	defer s.lock()()
+
+	if _, ok := s.data.webhookSubscriptions[id]; !ok {
+		return fmt.Errorf("webhook subscription not found: %s", id)
+	}
+
+	s.data.deleteWebhookSubscription(id)
+	return nil
+}
+
+func (t *tables) deleteWebhookSubscription(id uuid.UUID) {
+	for _, d := range t.webhookDeliveries {
+		if d.SubscriptionID == id {
+			delete(t.webhookDeliveries, d.ID)
+		}
+	}
+	delete(t.webhookSubscriptions, id)
+}
+
+// CreateWebhookDelivery queues a delivery
+func (s *MemoryStore) CreateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	defer s.lock()()
+
+	if _, ok := s.data.webhookDeliveries[d.ID]; ok {
+		return fmt.Errorf("failed to create webhook delivery: webhook delivery already exists: %s", d.ID)
+	}
+	if _, ok := s.data.webhookSubscriptions[d.SubscriptionID]; !ok {
+		return fmt.Errorf("failed to create webhook delivery: webhook subscription not found: %s", d.SubscriptionID)
+	}
+	if err := s.data.requireFamily(d.FamilyID); err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	s.data.webhookDeliveries[d.ID] = *copyWebhookDelivery(*d)
+	return nil
+}
+
+// GetDueWebhookDeliveries retrieves up to limit pending deliveries due at or before now, earliest first
+func (s *MemoryStore) GetDueWebhookDeliveries(ctx context.Context, now time.Time, n int) ([]*domain.WebhookDelivery, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.webhookDeliveries, func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.WebhookDeliveryPending && !d.NextAttemptAt.After(now)
+	})
+	slices.SortFunc(rows, func(a, b domain.WebhookDelivery) int {
+		return compareTimes(a.NextAttemptAt, b.NextAttemptAt, a.ID, b.ID)
+	})
+
+	var deliveries []*domain.WebhookDelivery
+	for _, row := range limit(rows, n) {
+		deliveries = append(deliveries, copyWebhookDelivery(row))
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt
+func (s *MemoryStore) UpdateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	defer s.lock()()
+
+	existing, ok := s.data.webhookDeliveries[d.ID]
+	if !ok {
+		return fmt.Errorf("webhook delivery not found: %s", d.ID)
+	}
+
+	existing.Status = d.Status
+	existing.Attempts = d.Attempts
+	existing.NextAttemptAt = d.NextAttemptAt
+	existing.LastStatusCode = clone(d.LastStatusCode)
+	existing.LastError = clone(d.LastError)
+	existing.DeliveredAt = clone(d.DeliveredAt)
+	existing.UpdatedAt = time.Now()
+	s.data.webhookDeliveries[d.ID] = existing
+
+	return nil
+}
+
+// GetWebhookDeliveriesForFamily retrieves a family's most recent deliveries, newest first,
+// optionally only those with the given status
+func (s *MemoryStore) GetWebhookDeliveriesForFamily(ctx context.Context, familyID uuid.UUID, status *domain.WebhookDeliveryStatus, n int) ([]*domain.WebhookDelivery, error) {
+	defer s.rlock()()
+
+	rows := filter(s.data.webhookDeliveries, func(d domain.WebhookDelivery) bool {
+		return d.FamilyID == familyID && (status == nil || d.Status == *status)
+	})
+	slices.SortFunc(rows, func(a, b domain.WebhookDelivery) int {
+		return compareTimes(b.CreatedAt, a.CreatedAt, b.ID, a.ID)
+	})
+
+	var deliveries []*domain.WebhookDelivery
+	for _, row := range limit(rows, n) {
+		deliveries = append(deliveries, copyWebhookDelivery(row))
+	}
+
+	return deliveries, nil
+}
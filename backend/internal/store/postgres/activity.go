@@ -4,19 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
 )
 
 // Activity operations
 
 // CreateActivity creates a new activity
 func (s *PostgresStore) CreateActivity(ctx context.Context, activity *domain.Activity) error {
+	if activity.Version == 0 {
+		activity.Version = 1
+	}
+
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO activities (id, care_session_id, activity_type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-	`, activity.ID, activity.CareSessionID, activity.ActivityType, activity.CreatedAt, activity.UpdatedAt)
+		INSERT INTO activities (id, care_session_id, baby_id, activity_type, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, activity.ID, activity.CareSessionID, activity.BabyID, activity.ActivityType, activity.Version, activity.CreatedAt, activity.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create activity: %w", err)
@@ -30,13 +36,15 @@ func (s *PostgresStore) GetActivityByID(ctx context.Context, id uuid.UUID) (*dom
 	activity := &domain.Activity{}
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, care_session_id, activity_type, created_at, updated_at
+		SELECT id, care_session_id, baby_id, activity_type, version, created_at, updated_at
 		FROM activities
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
 		&activity.ID,
 		&activity.CareSessionID,
+		&activity.BabyID,
 		&activity.ActivityType,
+		&activity.Version,
 		&activity.CreatedAt,
 		&activity.UpdatedAt,
 	)
@@ -48,15 +56,17 @@ func (s *PostgresStore) GetActivityByID(ctx context.Context, id uuid.UUID) (*dom
 	return activity, nil
 }
 
-// GetActivitiesForSession retrieves all activities for a care session
+// GetActivitiesForSession retrieves all activities for a care session. For a deleted
+// session these are the activities deleted with it.
 func (s *PostgresStore) GetActivitiesForSession(ctx context.Context, sessionID uuid.UUID) ([]*domain.Activity, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT a.id, a.care_session_id, a.activity_type, a.created_at, a.updated_at
+		SELECT a.id, a.care_session_id, a.baby_id, a.activity_type, a.version, a.created_at, a.updated_at, a.deleted_at
 		FROM activities a
+		JOIN care_sessions cs ON a.care_session_id = cs.id
 		LEFT JOIN feed_details fd ON a.id = fd.activity_id
 		LEFT JOIN sleep_details sd ON a.id = sd.activity_id
 		LEFT JOIN diaper_details dd ON a.id = dd.activity_id
-		WHERE a.care_session_id = $1
+		WHERE a.care_session_id = $1 AND a.deleted_at IS NOT DISTINCT FROM cs.deleted_at
 		ORDER BY COALESCE(fd.start_time, sd.start_time, dd.changed_at, a.created_at) ASC
 	`, sessionID)
 
@@ -71,9 +81,57 @@ func (s *PostgresStore) GetActivitiesForSession(ctx context.Context, sessionID u
 		err := rows.Scan(
 			&activity.ID,
 			&activity.CareSessionID,
+			&activity.BabyID,
+			&activity.ActivityType,
+			&activity.Version,
+			&activity.CreatedAt,
+			&activity.UpdatedAt,
+			&activity.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		activities = append(activities, activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// GetActivitiesForSessions retrieves the activities for several care sessions at once,
+// each session's in GetActivitiesForSession order
+func (s *PostgresStore) GetActivitiesForSessions(ctx context.Context, sessionIDs []uuid.UUID) ([]*domain.Activity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.care_session_id, a.baby_id, a.activity_type, a.version, a.created_at, a.updated_at, a.deleted_at
+		FROM activities a
+		JOIN care_sessions cs ON a.care_session_id = cs.id
+		LEFT JOIN feed_details fd ON a.id = fd.activity_id
+		LEFT JOIN sleep_details sd ON a.id = sd.activity_id
+		LEFT JOIN diaper_details dd ON a.id = dd.activity_id
+		WHERE a.care_session_id = ANY($1::UUID[]) AND a.deleted_at IS NOT DISTINCT FROM cs.deleted_at
+		ORDER BY a.care_session_id, COALESCE(fd.start_time, sd.start_time, dd.changed_at, a.created_at) ASC
+	`, uuidArray(sessionIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activities: %w", err)
+	}
+	defer rows.Close()
+
+	var activities []*domain.Activity
+	for rows.Next() {
+		activity := &domain.Activity{}
+		err := rows.Scan(
+			&activity.ID,
+			&activity.CareSessionID,
+			&activity.BabyID,
 			&activity.ActivityType,
+			&activity.Version,
 			&activity.CreatedAt,
 			&activity.UpdatedAt,
+			&activity.DeletedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan activity: %w", err)
@@ -88,27 +146,30 @@ func (s *PostgresStore) GetActivitiesForSession(ctx context.Context, sessionID u
 	return activities, nil
 }
 
-// GetLatestActivityByTypeForFamily returns the most recent activity of a given type
-// across all sessions for a family, ordered by the activity's own time (e.g. startTime
-// for feeds/sleeps, changedAt for diapers) with created_at as fallback.
+// GetLatestActivityByTypeForBaby returns the most recent activity of a given type
+// across all sessions for a baby, ordered by the activity's own time (e.g. startTime
+// for feeds/sleeps/pumps, changedAt for diapers, givenAt for medication) with created_at as fallback.
 // Returns (nil, nil) if no matching activity exists.
-func (s *PostgresStore) GetLatestActivityByTypeForFamily(ctx context.Context, familyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
+func (s *PostgresStore) GetLatestActivityByTypeForBaby(ctx context.Context, babyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error) {
 	activity := &domain.Activity{}
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT a.id, a.care_session_id, a.activity_type, a.created_at, a.updated_at
+		SELECT a.id, a.care_session_id, a.baby_id, a.activity_type, a.version, a.created_at, a.updated_at
 		FROM activities a
-		JOIN care_sessions cs ON a.care_session_id = cs.id
 		LEFT JOIN feed_details fd ON a.id = fd.activity_id
 		LEFT JOIN sleep_details sd ON a.id = sd.activity_id
 		LEFT JOIN diaper_details dd ON a.id = dd.activity_id
-		WHERE cs.family_id = $1 AND a.activity_type = $2
-		ORDER BY COALESCE(fd.start_time, sd.start_time, dd.changed_at, a.created_at) DESC
+		LEFT JOIN pump_details pd ON a.id = pd.activity_id
+		LEFT JOIN medication_details md ON a.id = md.activity_id
+		WHERE a.baby_id = $1 AND a.activity_type = $2 AND a.deleted_at IS NULL
+		ORDER BY COALESCE(fd.start_time, sd.start_time, dd.changed_at, pd.start_time, md.given_at, a.created_at) DESC
 		LIMIT 1
-	`, familyID, activityType).Scan(
+	`, babyID, activityType).Scan(
 		&activity.ID,
 		&activity.CareSessionID,
+		&activity.BabyID,
 		&activity.ActivityType,
+		&activity.Version,
 		&activity.CreatedAt,
 		&activity.UpdatedAt,
 	)
@@ -123,10 +184,10 @@ func (s *PostgresStore) GetLatestActivityByTypeForFamily(ctx context.Context, fa
 	return activity, nil
 }
 
-// DeleteActivity deletes an activity (cascades to activity details)
+// DeleteActivity moves an activity to the trash. Its details are kept so it can be restored.
 func (s *PostgresStore) DeleteActivity(ctx context.Context, id uuid.UUID) error {
 	result, err := s.db.ExecContext(ctx, `
-		DELETE FROM activities WHERE id = $1
+		UPDATE activities SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL
 	`, id)
 
 	if err != nil {
@@ -144,3 +205,174 @@ func (s *PostgresStore) DeleteActivity(ctx context.Context, id uuid.UUID) error
 
 	return nil
 }
+
+// RestoreActivity takes an activity out of the trash, unless its session is deleted too
+func (s *PostgresStore) RestoreActivity(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE activities a SET deleted_at = NULL
+		FROM care_sessions cs
+		WHERE a.id = $1 AND a.deleted_at IS NOT NULL
+		  AND cs.id = a.care_session_id AND cs.deleted_at IS NULL
+	`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to restore activity: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("deleted activity not found: %s", id)
+	}
+
+	return nil
+}
+
+// GetRecentlyDeletedActivitiesForFamily retrieves a family's activities deleted after since,
+// most recently deleted first. Activities deleted with their session are left out; they
+// are restored with it.
+func (s *PostgresStore) GetRecentlyDeletedActivitiesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.care_session_id, a.baby_id, a.activity_type, a.version, a.created_at, a.updated_at, a.deleted_at
+		FROM activities a
+		JOIN care_sessions cs ON a.care_session_id = cs.id
+		WHERE cs.family_id = $1 AND cs.deleted_at IS NULL AND a.deleted_at > $2
+		ORDER BY a.deleted_at DESC, a.id DESC
+	`, familyID, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted activities: %w", err)
+	}
+	defer rows.Close()
+
+	var activities []*domain.Activity
+	for rows.Next() {
+		activity := &domain.Activity{}
+		err := rows.Scan(
+			&activity.ID,
+			&activity.CareSessionID,
+			&activity.BabyID,
+			&activity.ActivityType,
+			&activity.Version,
+			&activity.CreatedAt,
+			&activity.UpdatedAt,
+			&activity.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		activities = append(activities, activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// PurgeDeleted permanently removes activities and care sessions deleted before
+// deletedBefore (cascading to activity details)
+func (s *PostgresStore) PurgeDeleted(ctx context.Context, deletedBefore time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM activities WHERE deleted_at < $1
+	`, deletedBefore)
+
+	if err != nil {
+		return fmt.Errorf("failed to purge deleted activities: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		DELETE FROM care_sessions WHERE deleted_at < $1
+	`, deletedBefore)
+
+	if err != nil {
+		return fmt.Errorf("failed to purge deleted care sessions: %w", err)
+	}
+
+	return nil
+}
+
+// TouchActivity bumps an activity's version and updated_at so editors and syncing clients
+// see that its details changed. With expectedVersion set, the update only applies if nobody
+// changed the activity since that version.
+func (s *PostgresStore) TouchActivity(ctx context.Context, id uuid.UUID, expectedVersion *int) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE activities
+		SET version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::INTEGER IS NULL OR version = $2)
+		RETURNING version
+	`, id, expectedVersion).Scan(&version)
+
+	if err == sql.ErrNoRows {
+		return 0, s.touchActivityFailure(ctx, id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to touch activity: %w", err)
+	}
+
+	return version, nil
+}
+
+// touchActivityFailure explains why a touch matched no row: either the activity is gone or
+// it has moved past the expected version
+func (s *PostgresStore) touchActivityFailure(ctx context.Context, id uuid.UUID) error {
+	var version int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT version FROM activities WHERE id = $1 AND deleted_at IS NULL
+	`, id).Scan(&version)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("activity not found: %s", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to touch activity: %w", err)
+	}
+
+	return &store.VersionConflictError{ID: id, Version: version}
+}
+
+// GetActivitiesUpdatedSinceForFamily retrieves a family's activities created or changed after since,
+// oldest change first
+func (s *PostgresStore) GetActivitiesUpdatedSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.care_session_id, a.baby_id, a.activity_type, a.version, a.created_at, a.updated_at
+		FROM activities a
+		JOIN care_sessions cs ON a.care_session_id = cs.id
+		WHERE cs.family_id = $1 AND a.updated_at > $2 AND a.deleted_at IS NULL
+		ORDER BY a.updated_at ASC
+	`, familyID, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query updated activities: %w", err)
+	}
+	defer rows.Close()
+
+	var activities []*domain.Activity
+	for rows.Next() {
+		activity := &domain.Activity{}
+		err := rows.Scan(
+			&activity.ID,
+			&activity.CareSessionID,
+			&activity.BabyID,
+			&activity.ActivityType,
+			&activity.Version,
+			&activity.CreatedAt,
+			&activity.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		activities = append(activities, activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activities: %w", err)
+	}
+
+	return activities, nil
+}
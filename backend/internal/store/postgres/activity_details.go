@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
@@ -59,6 +60,48 @@ func (s *PostgresStore) GetFeedDetails(ctx context.Context, activityID uuid.UUID
 	return details, nil
 }
 
+// GetFeedDetailsByActivityIDs retrieves the feed details for several activities at once
+func (s *PostgresStore) GetFeedDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.FeedDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, activity_id, start_time, end_time, amount_ml, feed_type, food_name, quantity, quantity_unit, created_at, updated_at
+		FROM feed_details
+		WHERE activity_id = ANY($1::UUID[])
+	`, uuidArray(activityIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feed details: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.FeedDetails
+	for rows.Next() {
+		details := &domain.FeedDetails{}
+		err := rows.Scan(
+			&details.ID,
+			&details.ActivityID,
+			&details.StartTime,
+			&details.EndTime,
+			&details.AmountMl,
+			&details.FeedType,
+			&details.FoodName,
+			&details.Quantity,
+			&details.QuantityUnit,
+			&details.CreatedAt,
+			&details.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feed details: %w", err)
+		}
+		result = append(result, details)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feed details: %w", err)
+	}
+
+	return result, nil
+}
+
 // Diaper Details
 
 // CreateDiaperDetails creates diaper details for an activity
@@ -103,6 +146,44 @@ func (s *PostgresStore) GetDiaperDetails(ctx context.Context, activityID uuid.UU
 	return details, nil
 }
 
+// GetDiaperDetailsByActivityIDs retrieves the diaper details for several activities at once
+func (s *PostgresStore) GetDiaperDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.DiaperDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, activity_id, changed_at, had_poop, had_pee, created_at, updated_at
+		FROM diaper_details
+		WHERE activity_id = ANY($1::UUID[])
+	`, uuidArray(activityIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query diaper details: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.DiaperDetails
+	for rows.Next() {
+		details := &domain.DiaperDetails{}
+		err := rows.Scan(
+			&details.ID,
+			&details.ActivityID,
+			&details.ChangedAt,
+			&details.HadPoop,
+			&details.HadPee,
+			&details.CreatedAt,
+			&details.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan diaper details: %w", err)
+		}
+		result = append(result, details)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating diaper details: %w", err)
+	}
+
+	return result, nil
+}
+
 // Sleep Details
 
 // CreateSleepDetails creates sleep details for an activity
@@ -147,17 +228,54 @@ func (s *PostgresStore) GetSleepDetails(ctx context.Context, activityID uuid.UUI
 	return details, nil
 }
 
-// GetRecentFeedDetailsForFamily retrieves recent feed details across all sessions for a family
-func (s *PostgresStore) GetRecentFeedDetailsForFamily(ctx context.Context, familyID uuid.UUID, limit int) ([]*domain.FeedDetails, error) {
+// GetSleepDetailsByActivityIDs retrieves the sleep details for several activities at once
+func (s *PostgresStore) GetSleepDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.SleepDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, activity_id, start_time, end_time, duration_minutes, created_at, updated_at
+		FROM sleep_details
+		WHERE activity_id = ANY($1::UUID[])
+	`, uuidArray(activityIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sleep details: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.SleepDetails
+	for rows.Next() {
+		details := &domain.SleepDetails{}
+		err := rows.Scan(
+			&details.ID,
+			&details.ActivityID,
+			&details.StartTime,
+			&details.EndTime,
+			&details.DurationMinutes,
+			&details.CreatedAt,
+			&details.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sleep details: %w", err)
+		}
+		result = append(result, details)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sleep details: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetRecentFeedDetailsForBaby retrieves recent feed details across all sessions for a baby
+func (s *PostgresStore) GetRecentFeedDetailsForBaby(ctx context.Context, babyID uuid.UUID, limit int) ([]*domain.FeedDetails, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT fd.id, fd.activity_id, fd.start_time, fd.end_time, fd.amount_ml, fd.feed_type, fd.food_name, fd.quantity, fd.quantity_unit, fd.created_at, fd.updated_at
 		FROM feed_details fd
 		JOIN activities a ON fd.activity_id = a.id
-		JOIN care_sessions cs ON a.care_session_id = cs.id
-		WHERE cs.family_id = $1
+		WHERE a.baby_id = $1 AND a.deleted_at IS NULL
 		ORDER BY fd.start_time DESC
 		LIMIT $2
-	`, familyID, limit)
+	`, babyID, limit)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query recent feed details: %w", err)
@@ -233,17 +351,16 @@ func (s *PostgresStore) UpdateDiaperDetails(ctx context.Context, details *domain
 	return nil
 }
 
-// GetRecentSleepDetailsForFamily retrieves recent sleep details across all sessions for a family
-func (s *PostgresStore) GetRecentSleepDetailsForFamily(ctx context.Context, familyID uuid.UUID, limit int) ([]*domain.SleepDetails, error) {
+// GetRecentSleepDetailsForBaby retrieves recent sleep details across all sessions for a baby
+func (s *PostgresStore) GetRecentSleepDetailsForBaby(ctx context.Context, babyID uuid.UUID, limit int) ([]*domain.SleepDetails, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT sd.id, sd.activity_id, sd.start_time, sd.end_time, sd.duration_minutes, sd.created_at, sd.updated_at
 		FROM sleep_details sd
 		JOIN activities a ON sd.activity_id = a.id
-		JOIN care_sessions cs ON a.care_session_id = cs.id
-		WHERE cs.family_id = $1
+		WHERE a.baby_id = $1 AND a.deleted_at IS NULL
 		ORDER BY sd.start_time DESC
 		LIMIT $2
-	`, familyID, limit)
+	`, babyID, limit)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query recent sleep details: %w", err)
@@ -294,3 +411,262 @@ func (s *PostgresStore) UpdateSleepDetails(ctx context.Context, details *domain.
 
 	return nil
 }
+
+// Pump Details
+
+// CreatePumpDetails creates pump details for an activity
+func (s *PostgresStore) CreatePumpDetails(ctx context.Context, details *domain.PumpDetails) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO pump_details (id, activity_id, start_time, end_time, left_ml, right_ml, total_ml, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, details.ID, details.ActivityID, details.StartTime, details.EndTime, details.LeftMl, details.RightMl, details.TotalMl, details.Notes, details.CreatedAt, details.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create pump details: %w", err)
+	}
+
+	return nil
+}
+
+// GetPumpDetails retrieves pump details for an activity
+func (s *PostgresStore) GetPumpDetails(ctx context.Context, activityID uuid.UUID) (*domain.PumpDetails, error) {
+	details := &domain.PumpDetails{}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, activity_id, start_time, end_time, left_ml, right_ml, total_ml, notes, created_at, updated_at
+		FROM pump_details
+		WHERE activity_id = $1
+	`, activityID).Scan(
+		&details.ID,
+		&details.ActivityID,
+		&details.StartTime,
+		&details.EndTime,
+		&details.LeftMl,
+		&details.RightMl,
+		&details.TotalMl,
+		&details.Notes,
+		&details.CreatedAt,
+		&details.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pump details not found for activity: %s", activityID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pump details: %w", err)
+	}
+
+	return details, nil
+}
+
+// GetPumpDetailsByActivityIDs retrieves the pump details for several activities at once
+func (s *PostgresStore) GetPumpDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.PumpDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, activity_id, start_time, end_time, left_ml, right_ml, total_ml, notes, created_at, updated_at
+		FROM pump_details
+		WHERE activity_id = ANY($1::UUID[])
+	`, uuidArray(activityIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pump details: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.PumpDetails
+	for rows.Next() {
+		details := &domain.PumpDetails{}
+		err := rows.Scan(
+			&details.ID,
+			&details.ActivityID,
+			&details.StartTime,
+			&details.EndTime,
+			&details.LeftMl,
+			&details.RightMl,
+			&details.TotalMl,
+			&details.Notes,
+			&details.CreatedAt,
+			&details.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pump details: %w", err)
+		}
+		result = append(result, details)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pump details: %w", err)
+	}
+
+	return result, nil
+}
+
+// UpdatePumpDetails updates pump details for an activity
+func (s *PostgresStore) UpdatePumpDetails(ctx context.Context, details *domain.PumpDetails) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE pump_details
+		SET start_time = $1, end_time = $2, left_ml = $3, right_ml = $4, total_ml = $5, notes = $6, updated_at = $7
+		WHERE id = $8
+	`, details.StartTime, details.EndTime, details.LeftMl, details.RightMl, details.TotalMl, details.Notes, details.UpdatedAt, details.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update pump details: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("pump details not found: %s", details.ID)
+	}
+
+	return nil
+}
+
+// Medication Details
+
+// CreateMedicationDetails creates medication details for an activity
+func (s *PostgresStore) CreateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO medication_details (id, activity_id, medication_id, drug_name, dose_amount, dose_unit, route, given_at, interval_overridden, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, details.ID, details.ActivityID, details.MedicationID, details.DrugName, details.DoseAmount, details.DoseUnit, details.Route, details.GivenAt, details.IntervalOverridden, details.CreatedAt, details.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create medication details: %w", err)
+	}
+
+	return nil
+}
+
+// GetMedicationDetails retrieves medication details for an activity
+func (s *PostgresStore) GetMedicationDetails(ctx context.Context, activityID uuid.UUID) (*domain.MedicationDetails, error) {
+	details := &domain.MedicationDetails{}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, activity_id, medication_id, drug_name, dose_amount, dose_unit, route, given_at, interval_overridden, created_at, updated_at
+		FROM medication_details
+		WHERE activity_id = $1
+	`, activityID).Scan(
+		&details.ID,
+		&details.ActivityID,
+		&details.MedicationID,
+		&details.DrugName,
+		&details.DoseAmount,
+		&details.DoseUnit,
+		&details.Route,
+		&details.GivenAt,
+		&details.IntervalOverridden,
+		&details.CreatedAt,
+		&details.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("medication details not found for activity: %s", activityID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication details: %w", err)
+	}
+
+	return details, nil
+}
+
+// GetMedicationDetailsByActivityIDs retrieves the medication details for several activities at once
+func (s *PostgresStore) GetMedicationDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.MedicationDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, activity_id, medication_id, drug_name, dose_amount, dose_unit, route, given_at, interval_overridden, created_at, updated_at
+		FROM medication_details
+		WHERE activity_id = ANY($1::UUID[])
+	`, uuidArray(activityIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query medication details: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMedicationDetails(rows)
+}
+
+// GetRecentMedicationDetailsForBaby retrieves all doses given to a baby at or after since across all sessions
+func (s *PostgresStore) GetRecentMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.MedicationDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT md.id, md.activity_id, md.medication_id, md.drug_name, md.dose_amount, md.dose_unit, md.route, md.given_at, md.interval_overridden, md.created_at, md.updated_at
+		FROM medication_details md
+		JOIN activities a ON md.activity_id = a.id
+		WHERE a.baby_id = $1 AND a.deleted_at IS NULL AND md.given_at >= $2
+		ORDER BY md.given_at DESC
+	`, babyID, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent medication details: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMedicationDetails(rows)
+}
+
+// GetLatestMedicationDetailsForBaby retrieves the most recent dose of each distinct drug (by name, case-insensitive) given to a baby
+func (s *PostgresStore) GetLatestMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.MedicationDetails, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (LOWER(md.drug_name))
+			md.id, md.activity_id, md.medication_id, md.drug_name, md.dose_amount, md.dose_unit, md.route, md.given_at, md.interval_overridden, md.created_at, md.updated_at
+		FROM medication_details md
+		JOIN activities a ON md.activity_id = a.id
+		WHERE a.baby_id = $1 AND a.deleted_at IS NULL
+		ORDER BY LOWER(md.drug_name), md.given_at DESC
+	`, babyID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest medication details: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMedicationDetails(rows)
+}
+
+func scanMedicationDetails(rows *sql.Rows) ([]*domain.MedicationDetails, error) {
+	var details []*domain.MedicationDetails
+	for rows.Next() {
+		d := &domain.MedicationDetails{}
+		err := rows.Scan(
+			&d.ID, &d.ActivityID, &d.MedicationID, &d.DrugName,
+			&d.DoseAmount, &d.DoseUnit, &d.Route, &d.GivenAt, &d.IntervalOverridden,
+			&d.CreatedAt, &d.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan medication details: %w", err)
+		}
+		details = append(details, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating medication details: %w", err)
+	}
+
+	return details, nil
+}
+
+// UpdateMedicationDetails updates medication details for an activity
+func (s *PostgresStore) UpdateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE medication_details
+		SET medication_id = $1, drug_name = $2, dose_amount = $3, dose_unit = $4, route = $5, given_at = $6, interval_overridden = $7, updated_at = $8
+		WHERE id = $9
+	`, details.MedicationID, details.DrugName, details.DoseAmount, details.DoseUnit, details.Route, details.GivenAt, details.IntervalOverridden, details.UpdatedAt, details.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update medication details: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("medication details not found: %s", details.ID)
+	}
+
+	return nil
+}
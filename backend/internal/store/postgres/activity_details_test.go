@@ -20,7 +20,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 	ctx := context.Background()
 
 	// Create unique test family using helper
-	family, caregiver, err := CreateTestFamily(ctx, store)
+	family, baby, caregiver, err := CreateTestFamilyWithBaby(ctx, store)
 	if err != nil {
 		t.Fatalf("Failed to create test family: %v", err)
 	}
@@ -53,6 +53,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 	feedActivity := &domain.Activity{
 		ID:            uuid.New(),
 		CareSessionID: session.ID,
+		BabyID:        baby.ID,
 		ActivityType:  domain.ActivityTypeFeed,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -61,6 +62,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 	diaperActivity := &domain.Activity{
 		ID:            uuid.New(),
 		CareSessionID: session.ID,
+		BabyID:        baby.ID,
 		ActivityType:  domain.ActivityTypeDiaper,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -69,6 +71,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 	sleepActivity := &domain.Activity{
 		ID:            uuid.New(),
 		CareSessionID: session.ID,
+		BabyID:        baby.ID,
 		ActivityType:  domain.ActivityTypeSleep,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -283,6 +286,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 		solidsActivity := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -341,6 +345,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 		minimalActivity := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -389,6 +394,7 @@ func TestActivityDetailsOperations(t *testing.T) {
 		formulaActivity := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
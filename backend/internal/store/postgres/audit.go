@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Audit log operations
+
+// CreateAuditEvent appends an event to its family's audit log
+func (s *PostgresStore) CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_events (id, family_id, actor_caregiver_id, actor_name, action, entity_type, entity_id,
+		        before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, event.ID, event.FamilyID, event.ActorCaregiverID, event.ActorName, event.Action, event.EntityType, event.EntityID,
+		event.Before, event.After, event.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditEventsForFamily retrieves a page of a family's audit events matching filter using
+// keyset pagination, newest first. When afterTime/afterID are provided, returns events after
+// that cursor position.
+func (s *PostgresStore) GetAuditEventsForFamily(ctx context.Context, familyID uuid.UUID, filter domain.AuditFilter, limit int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.AuditEvent, error) {
+	if afterTime == nil || afterID == nil {
+		afterTime, afterID = nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, family_id, actor_caregiver_id, actor_name, action, entity_type, entity_id,
+		       before, after, created_at
+		FROM audit_events
+		WHERE family_id = $1
+		  AND ($2::UUID IS NULL OR actor_caregiver_id = $2)
+		  AND ($3::VARCHAR IS NULL OR action = $3)
+		  AND ($4::VARCHAR IS NULL OR entity_type = $4)
+		  AND ($5::UUID IS NULL OR entity_id = $5)
+		  AND ($6::TIMESTAMPTZ IS NULL OR created_at < $6 OR (created_at = $6 AND id < $7))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $8
+	`, familyID, filter.ActorCaregiverID, filter.Action, filter.EntityType, filter.EntityID, afterTime, afterID, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.AuditEvent
+	for rows.Next() {
+		event := &domain.AuditEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.FamilyID,
+			&event.ActorCaregiverID,
+			&event.ActorName,
+			&event.Action,
+			&event.EntityType,
+			&event.EntityID,
+			&event.Before,
+			&event.After,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+
+	return events, nil
+}
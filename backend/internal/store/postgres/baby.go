@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Baby operations
+
+// CreateBaby adds a baby to an existing family
+func (s *PostgresStore) CreateBaby(ctx context.Context, baby *domain.Baby) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO babies (id, family_id, name, birth_date, sex, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, baby.ID, baby.FamilyID, baby.Name, baby.BirthDate, baby.Sex, baby.CreatedAt, baby.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create baby: %w", err)
+	}
+
+	return nil
+}
+
+// GetBabyByID retrieves a baby by ID
+func (s *PostgresStore) GetBabyByID(ctx context.Context, id uuid.UUID) (*domain.Baby, error) {
+	baby := &domain.Baby{}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, family_id, name, birth_date, sex, created_at, updated_at
+		FROM babies
+		WHERE id = $1
+	`, id).Scan(
+		&baby.ID,
+		&baby.FamilyID,
+		&baby.Name,
+		&baby.BirthDate,
+		&baby.Sex,
+		&baby.CreatedAt,
+		&baby.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("baby not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get baby: %w", err)
+	}
+
+	return baby, nil
+}
+
+// GetBabiesForFamily retrieves a family's babies, oldest record first
+func (s *PostgresStore) GetBabiesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Baby, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, family_id, name, birth_date, sex, created_at, updated_at
+		FROM babies
+		WHERE family_id = $1
+		ORDER BY created_at ASC
+	`, familyID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query babies: %w", err)
+	}
+	defer rows.Close()
+
+	var babies []*domain.Baby
+	for rows.Next() {
+		baby := &domain.Baby{}
+		err := rows.Scan(
+			&baby.ID,
+			&baby.FamilyID,
+			&baby.Name,
+			&baby.BirthDate,
+			&baby.Sex,
+			&baby.CreatedAt,
+			&baby.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan baby: %w", err)
+		}
+		babies = append(babies, baby)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating babies: %w", err)
+	}
+
+	return babies, nil
+}
+
+// UpdateBaby updates a baby's name, birth date and sex
+func (s *PostgresStore) UpdateBaby(ctx context.Context, baby *domain.Baby) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE babies
+		SET name = $1, birth_date = $2, sex = $3, updated_at = $4
+		WHERE id = $5
+	`, baby.Name, baby.BirthDate, baby.Sex, baby.UpdatedAt, baby.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update baby: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("baby not found: %s", baby.ID)
+	}
+
+	return nil
+}
@@ -33,7 +33,7 @@ func (s *PostgresStore) GetCareSessionByID(ctx context.Context, id uuid.UUID) (*
 	err := s.db.QueryRowContext(ctx, `
 		SELECT id, caregiver_id, family_id, status, started_at, completed_at, notes, created_at, updated_at
 		FROM care_sessions
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
 		&session.ID,
 		&session.CaregiverID,
@@ -63,7 +63,7 @@ func (s *PostgresStore) GetInProgressSessionForFamily(ctx context.Context, famil
 	err := s.db.QueryRowContext(ctx, `
 		SELECT id, caregiver_id, family_id, status, started_at, completed_at, notes, created_at, updated_at
 		FROM care_sessions
-		WHERE family_id = $1 AND status = $2
+		WHERE family_id = $1 AND status = $2 AND deleted_at IS NULL
 		ORDER BY started_at DESC
 		LIMIT 1
 	`, familyID, domain.StatusInProgress).Scan(
@@ -93,7 +93,7 @@ func (s *PostgresStore) GetRecentCareSessionsForFamily(ctx context.Context, fami
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, caregiver_id, family_id, status, started_at, completed_at, notes, created_at, updated_at
 		FROM care_sessions
-		WHERE family_id = $1 AND status = $2
+		WHERE family_id = $1 AND status = $2 AND deleted_at IS NULL
 		ORDER BY started_at DESC
 		LIMIT $3
 	`, familyID, domain.StatusCompleted, limit)
@@ -141,7 +141,7 @@ func (s *PostgresStore) GetCareSessionHistoryForFamily(ctx context.Context, fami
 		rows, err = s.db.QueryContext(ctx, `
 			SELECT id, caregiver_id, family_id, status, started_at, completed_at, notes, created_at, updated_at
 			FROM care_sessions
-			WHERE family_id = $1 AND (started_at < $2 OR (started_at = $2 AND id < $3))
+			WHERE family_id = $1 AND deleted_at IS NULL AND (started_at < $2 OR (started_at = $2 AND id < $3))
 			ORDER BY started_at DESC, id DESC
 			LIMIT $4
 		`, familyID, *afterTime, *afterID, limit)
@@ -149,7 +149,7 @@ func (s *PostgresStore) GetCareSessionHistoryForFamily(ctx context.Context, fami
 		rows, err = s.db.QueryContext(ctx, `
 			SELECT id, caregiver_id, family_id, status, started_at, completed_at, notes, created_at, updated_at
 			FROM care_sessions
-			WHERE family_id = $1
+			WHERE family_id = $1 AND deleted_at IS NULL
 			ORDER BY started_at DESC, id DESC
 			LIMIT $2
 		`, familyID, limit)
@@ -192,7 +192,7 @@ func (s *PostgresStore) UpdateCareSession(ctx context.Context, session *domain.C
 	result, err := s.db.ExecContext(ctx, `
 		UPDATE care_sessions
 		SET status = $1, completed_at = $2, notes = $3, updated_at = $4
-		WHERE id = $5
+		WHERE id = $5 AND deleted_at IS NULL
 	`, session.Status, session.CompletedAt, session.Notes, session.UpdatedAt, session.ID)
 
 	if err != nil {
@@ -211,24 +211,106 @@ func (s *PostgresStore) UpdateCareSession(ctx context.Context, session *domain.C
 	return nil
 }
 
-// DeleteCareSession deletes a care session (cascades to activities and their details)
+// DeleteCareSession moves a care session and its remaining activities to the trash. The
+// activities are stamped with the session's deleted_at so RestoreCareSession can tell them
+// apart from ones deleted earlier.
 func (s *PostgresStore) DeleteCareSession(ctx context.Context, id uuid.UUID) error {
-	result, err := s.db.ExecContext(ctx, `
-		DELETE FROM care_sessions WHERE id = $1
-	`, id)
+	var deleted int
+	err := s.db.QueryRowContext(ctx, `
+		WITH session AS (
+			UPDATE care_sessions SET deleted_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id, deleted_at
+		), trashed_activities AS (
+			UPDATE activities a SET deleted_at = session.deleted_at
+			FROM session
+			WHERE a.care_session_id = session.id AND a.deleted_at IS NULL
+		)
+		SELECT COUNT(*) FROM session
+	`, id).Scan(&deleted)
 
 	if err != nil {
 		return fmt.Errorf("failed to delete care session: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
+	if deleted == 0 {
+		return fmt.Errorf("care session not found: %s", id)
+	}
+
+	return nil
+}
+
+// RestoreCareSession takes a care session out of the trash along with the activities
+// deleted with it
+func (s *PostgresStore) RestoreCareSession(ctx context.Context, id uuid.UUID) error {
+	var restored int
+	err := s.db.QueryRowContext(ctx, `
+		WITH session AS (
+			SELECT id, deleted_at FROM care_sessions
+			WHERE id = $1 AND deleted_at IS NOT NULL
+		), restored AS (
+			UPDATE care_sessions cs SET deleted_at = NULL
+			FROM session
+			WHERE cs.id = session.id
+			RETURNING cs.id
+		), restored_activities AS (
+			UPDATE activities a SET deleted_at = NULL
+			FROM session
+			WHERE a.care_session_id = session.id AND a.deleted_at = session.deleted_at
+		)
+		SELECT COUNT(*) FROM restored
+	`, id).Scan(&restored)
+
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to restore care session: %w", err)
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("care session not found: %s", id)
+	if restored == 0 {
+		return fmt.Errorf("deleted care session not found: %s", id)
 	}
 
 	return nil
 }
+
+// GetRecentlyDeletedCareSessionsForFamily retrieves a family's care sessions deleted after
+// since, most recently deleted first
+func (s *PostgresStore) GetRecentlyDeletedCareSessionsForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.CareSession, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, caregiver_id, family_id, status, started_at, completed_at, notes, created_at, updated_at, deleted_at
+		FROM care_sessions
+		WHERE family_id = $1 AND deleted_at > $2
+		ORDER BY deleted_at DESC, id DESC
+	`, familyID, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domain.CareSession
+	for rows.Next() {
+		session := &domain.CareSession{}
+		err := rows.Scan(
+			&session.ID,
+			&session.CaregiverID,
+			&session.FamilyID,
+			&session.Status,
+			&session.StartedAt,
+			&session.CompletedAt,
+			&session.Notes,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+			&session.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted sessions: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -13,10 +13,14 @@ import (
 
 // CreateCaregiver creates a new caregiver
 func (s *PostgresStore) CreateCaregiver(ctx context.Context, caregiver *domain.Caregiver) error {
+	if caregiver.Role == "" {
+		caregiver.Role = domain.RoleCaregiver
+	}
+
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO caregivers (id, family_id, user_id, name, device_id, device_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, caregiver.ID, caregiver.FamilyID, caregiver.UserID, caregiver.Name, caregiver.DeviceID, caregiver.DeviceName, caregiver.CreatedAt, caregiver.UpdatedAt)
+		INSERT INTO caregivers (id, family_id, user_id, name, device_id, device_name, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, caregiver.ID, caregiver.FamilyID, caregiver.UserID, caregiver.Name, caregiver.DeviceID, caregiver.DeviceName, caregiver.Role, caregiver.CreatedAt, caregiver.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create caregiver: %w", err)
@@ -30,7 +34,7 @@ func (s *PostgresStore) GetCaregiverByID(ctx context.Context, id uuid.UUID) (*do
 	caregiver := &domain.Caregiver{}
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, family_id, user_id, name, device_id, device_name, created_at, updated_at
+		SELECT id, family_id, user_id, name, device_id, device_name, token_generation, role, created_at, updated_at
 		FROM caregivers
 		WHERE id = $1
 	`, id).Scan(
@@ -40,6 +44,8 @@ func (s *PostgresStore) GetCaregiverByID(ctx context.Context, id uuid.UUID) (*do
 		&caregiver.Name,
 		&caregiver.DeviceID,
 		&caregiver.DeviceName,
+		&caregiver.TokenGeneration,
+		&caregiver.Role,
 		&caregiver.CreatedAt,
 		&caregiver.UpdatedAt,
 	)
@@ -59,7 +65,7 @@ func (s *PostgresStore) GetCaregiverByDeviceID(ctx context.Context, deviceID str
 	caregiver := &domain.Caregiver{}
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, family_id, user_id, name, device_id, device_name, created_at, updated_at
+		SELECT id, family_id, user_id, name, device_id, device_name, token_generation, role, created_at, updated_at
 		FROM caregivers
 		WHERE device_id = $1
 	`, deviceID).Scan(
@@ -69,6 +75,8 @@ func (s *PostgresStore) GetCaregiverByDeviceID(ctx context.Context, deviceID str
 		&caregiver.Name,
 		&caregiver.DeviceID,
 		&caregiver.DeviceName,
+		&caregiver.TokenGeneration,
+		&caregiver.Role,
 		&caregiver.CreatedAt,
 		&caregiver.UpdatedAt,
 	)
@@ -88,7 +96,7 @@ func (s *PostgresStore) GetCaregiverByUserAndFamily(ctx context.Context, userID
 	caregiver := &domain.Caregiver{}
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, family_id, user_id, name, device_id, device_name, created_at, updated_at
+		SELECT id, family_id, user_id, name, device_id, device_name, token_generation, role, created_at, updated_at
 		FROM caregivers
 		WHERE user_id = $1 AND family_id = $2
 	`, userID, familyID).Scan(
@@ -98,6 +106,8 @@ func (s *PostgresStore) GetCaregiverByUserAndFamily(ctx context.Context, userID
 		&caregiver.Name,
 		&caregiver.DeviceID,
 		&caregiver.DeviceName,
+		&caregiver.TokenGeneration,
+		&caregiver.Role,
 		&caregiver.CreatedAt,
 		&caregiver.UpdatedAt,
 	)
@@ -115,7 +125,7 @@ func (s *PostgresStore) GetCaregiverByUserAndFamily(ctx context.Context, userID
 // GetCaregiversByFamily retrieves all caregivers for a family
 func (s *PostgresStore) GetCaregiversByFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Caregiver, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, family_id, user_id, name, device_id, device_name, created_at, updated_at
+		SELECT id, family_id, user_id, name, device_id, device_name, token_generation, role, created_at, updated_at
 		FROM caregivers
 		WHERE family_id = $1
 		ORDER BY created_at ASC
@@ -136,6 +146,49 @@ func (s *PostgresStore) GetCaregiversByFamily(ctx context.Context, familyID uuid
 			&caregiver.Name,
 			&caregiver.DeviceID,
 			&caregiver.DeviceName,
+			&caregiver.TokenGeneration,
+			&caregiver.Role,
+			&caregiver.CreatedAt,
+			&caregiver.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan caregiver: %w", err)
+		}
+		caregivers = append(caregivers, caregiver)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating caregivers: %w", err)
+	}
+
+	return caregivers, nil
+}
+
+// GetCaregiversByIDs retrieves the caregivers among ids that exist
+func (s *PostgresStore) GetCaregiversByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Caregiver, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, family_id, user_id, name, device_id, device_name, token_generation, role, created_at, updated_at
+		FROM caregivers
+		WHERE id = ANY($1::UUID[])
+	`, uuidArray(ids))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query caregivers: %w", err)
+	}
+	defer rows.Close()
+
+	var caregivers []*domain.Caregiver
+	for rows.Next() {
+		caregiver := &domain.Caregiver{}
+		err := rows.Scan(
+			&caregiver.ID,
+			&caregiver.FamilyID,
+			&caregiver.UserID,
+			&caregiver.Name,
+			&caregiver.DeviceID,
+			&caregiver.DeviceName,
+			&caregiver.TokenGeneration,
+			&caregiver.Role,
 			&caregiver.CreatedAt,
 			&caregiver.UpdatedAt,
 		)
@@ -200,6 +253,51 @@ func (s *PostgresStore) LinkCaregiverToUser(ctx context.Context, caregiverID uui
 	return nil
 }
 
+// RotateDeviceToken advances a caregiver's device token generation, invalidating every
+// token issued before, and returns the new generation
+func (s *PostgresStore) RotateDeviceToken(ctx context.Context, caregiverID uuid.UUID) (int, error) {
+	var generation int
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE caregivers
+		SET token_generation = token_generation + 1
+		WHERE id = $1
+		RETURNING token_generation
+	`, caregiverID).Scan(&generation)
+
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("caregiver not found: %s", caregiverID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to rotate device token: %w", err)
+	}
+
+	return generation, nil
+}
+
+// SetCaregiverRole changes a caregiver's role
+func (s *PostgresStore) SetCaregiverRole(ctx context.Context, caregiverID uuid.UUID, role domain.CaregiverRole) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE caregivers
+		SET role = $1, updated_at = NOW()
+		WHERE id = $2
+	`, role, caregiverID)
+
+	if err != nil {
+		return fmt.Errorf("failed to set caregiver role: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("caregiver not found: %s", caregiverID)
+	}
+
+	return nil
+}
+
 // DeleteCaregiver deletes a caregiver
 func (s *PostgresStore) DeleteCaregiver(ctx context.Context, id uuid.UUID) error {
 	result, err := s.db.ExecContext(ctx, `
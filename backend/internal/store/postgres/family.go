@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/swatkatz/babybaton/backend/internal/domain"
@@ -11,58 +12,101 @@ import (
 
 // Family operations
 
-// CreateFamilyWithCaregiver creates a family and its first caregiver atomically
-func (s *PostgresStore) CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, caregiver *domain.Caregiver) error {
-	// Start transaction
-	tx, err := s.db.BeginTx(ctx, nil)
+const familyColumns = `id, name, password_hash, baby_name, timezone, travel_timezone, travel_start_date, travel_end_date,
+		        prediction_model, prediction_half_life_hours, created_at, updated_at`
+
+func scanFamily(row interface{ Scan(...any) error }, f *domain.Family) error {
+	var timezone, travelTimezone, predictionModel sql.NullString
+	var travelStart, travelEnd sql.NullTime
+	var halfLifeHours sql.NullInt64
+	err := row.Scan(
+		&f.ID, &f.Name, &f.PasswordHash, &f.BabyName, &timezone, &travelTimezone, &travelStart, &travelEnd,
+		&predictionModel, &halfLifeHours, &f.CreatedAt, &f.UpdatedAt,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return err
 	}
-	defer tx.Rollback()
 
-	// Insert family
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO families (id, name, password_hash, password, baby_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, family.ID, family.Name, family.PasswordHash, family.Password, family.BabyName, family.CreatedAt, family.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to insert family: %w", err)
+	f.Timezone = timezone.String
+	f.PredictionModel = domain.PredictionModel(predictionModel.String)
+	f.PredictionHalfLife = time.Duration(halfLifeHours.Int64) * time.Hour
+	f.Travel = nil
+	if travelTimezone.Valid {
+		f.Travel = &domain.TravelMode{Timezone: travelTimezone.String, StartDate: travelStart.Time, EndDate: travelEnd.Time}
 	}
+	return nil
+}
 
-	// Insert caregiver
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO caregivers (id, family_id, user_id, name, device_id, device_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, caregiver.ID, caregiver.FamilyID, caregiver.UserID, caregiver.Name, caregiver.DeviceID, caregiver.DeviceName, caregiver.CreatedAt, caregiver.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to insert caregiver: %w", err)
+// travelColumns returns the travel_* column values for travel, all NULL when it is off.
+// Dates are passed as text so the session timezone can't move them to another day.
+func travelColumns(travel *domain.TravelMode) (timezone, startDate, endDate *string) {
+	if travel == nil {
+		return nil, nil, nil
 	}
+	start := travel.StartDate.Format(time.DateOnly)
+	end := travel.EndDate.Format(time.DateOnly)
+	return &travel.Timezone, &start, &end
+}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+// halfLifeHours returns the prediction_half_life_hours column value, NULL for the default
+func halfLifeHours(halfLife time.Duration) *int {
+	if halfLife <= 0 {
+		return nil
 	}
+	hours := int(halfLife.Hours())
+	return &hours
+}
 
-	return nil
+// CreateFamilyWithCaregiver creates a family with its first baby and first caregiver atomically
+func (s *PostgresStore) CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, baby *domain.Baby, caregiver *domain.Caregiver) error {
+	if caregiver.Role == "" {
+		caregiver.Role = domain.RoleCaregiver
+	}
+
+	return s.withTx(ctx, func(tx *PostgresStore) error {
+		// Insert family
+		travelTimezone, travelStart, travelEnd := travelColumns(family.Travel)
+		_, err := tx.db.ExecContext(ctx, `
+			INSERT INTO families (id, name, password_hash, baby_name, timezone, travel_timezone, travel_start_date,
+			        travel_end_date, prediction_model, prediction_half_life_hours, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''), $10, $11, $12)
+		`, family.ID, family.Name, family.PasswordHash, family.BabyName, family.Timezone, travelTimezone, travelStart,
+			travelEnd, string(family.PredictionModel), halfLifeHours(family.PredictionHalfLife), family.CreatedAt, family.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert family: %w", err)
+		}
+
+		// Insert first baby
+		_, err = tx.db.ExecContext(ctx, `
+			INSERT INTO babies (id, family_id, name, birth_date, sex, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, baby.ID, baby.FamilyID, baby.Name, baby.BirthDate, baby.Sex, baby.CreatedAt, baby.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert baby: %w", err)
+		}
+
+		// Insert caregiver
+		_, err = tx.db.ExecContext(ctx, `
+			INSERT INTO caregivers (id, family_id, user_id, name, device_id, device_name, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, caregiver.ID, caregiver.FamilyID, caregiver.UserID, caregiver.Name, caregiver.DeviceID, caregiver.DeviceName, caregiver.Role, caregiver.CreatedAt, caregiver.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert caregiver: %w", err)
+		}
+
+		return nil
+	})
 }
 
 // GetFamilyByID retrieves a family by ID
 func (s *PostgresStore) GetFamilyByID(ctx context.Context, id uuid.UUID) (*domain.Family, error) {
 	family := &domain.Family{}
 
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, password_hash, password, baby_name, created_at, updated_at
+	err := scanFamily(s.db.QueryRowContext(ctx, `
+		SELECT `+familyColumns+`
 		FROM families
 		WHERE id = $1
-	`, id).Scan(
-		&family.ID,
-		&family.Name,
-		&family.PasswordHash,
-		&family.Password,
-		&family.BabyName,
-		&family.CreatedAt,
-		&family.UpdatedAt,
-	)
+	`, id), family)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("family not found: %s", id)
@@ -78,19 +122,11 @@ func (s *PostgresStore) GetFamilyByID(ctx context.Context, id uuid.UUID) (*domai
 func (s *PostgresStore) GetFamilyByName(ctx context.Context, name string) (*domain.Family, error) {
 	family := &domain.Family{}
 
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, password_hash, password, baby_name, created_at, updated_at
+	err := scanFamily(s.db.QueryRowContext(ctx, `
+		SELECT `+familyColumns+`
 		FROM families
 		WHERE LOWER(name) = LOWER($1)
-	`, name).Scan(
-		&family.ID,
-		&family.Name,
-		&family.PasswordHash,
-		&family.Password,
-		&family.BabyName,
-		&family.CreatedAt,
-		&family.UpdatedAt,
-	)
+	`, name), family)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("family not found: %s", name)
@@ -104,11 +140,15 @@ func (s *PostgresStore) GetFamilyByName(ctx context.Context, name string) (*doma
 
 // UpdateFamily updates an existing family
 func (s *PostgresStore) UpdateFamily(ctx context.Context, family *domain.Family) error {
+	travelTimezone, travelStart, travelEnd := travelColumns(family.Travel)
 	result, err := s.db.ExecContext(ctx, `
 		UPDATE families
-		SET name = $1, password_hash = $2, baby_name = $3, updated_at = $4
-		WHERE id = $5
-	`, family.Name, family.PasswordHash, family.BabyName, family.UpdatedAt, family.ID)
+		SET name = $1, password_hash = $2, baby_name = $3, timezone = NULLIF($4, ''), travel_timezone = $5,
+		    travel_start_date = $6, travel_end_date = $7, prediction_model = NULLIF($8, ''),
+		    prediction_half_life_hours = $9, updated_at = $10
+		WHERE id = $11
+	`, family.Name, family.PasswordHash, family.BabyName, family.Timezone, travelTimezone, travelStart, travelEnd,
+		string(family.PredictionModel), halfLifeHours(family.PredictionHalfLife), family.UpdatedAt, family.ID)
 
 	if err != nil {
 		return fmt.Errorf("failed to update family: %w", err)
@@ -151,11 +191,10 @@ func (s *PostgresStore) DeleteFamily(ctx context.Context, id uuid.UUID) error {
 // GetFamiliesByUserID retrieves all families where the user has a caregiver
 func (s *PostgresStore) GetFamiliesByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Family, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT f.id, f.name, f.password_hash, f.password, f.baby_name, f.created_at, f.updated_at
-		FROM families f
-		INNER JOIN caregivers c ON c.family_id = f.id
-		WHERE c.user_id = $1
-		ORDER BY f.created_at ASC
+		SELECT `+familyColumns+`
+		FROM families
+		WHERE id IN (SELECT family_id FROM caregivers WHERE user_id = $1)
+		ORDER BY created_at ASC
 	`, userID)
 
 	if err != nil {
@@ -166,16 +205,7 @@ func (s *PostgresStore) GetFamiliesByUserID(ctx context.Context, userID uuid.UUI
 	var families []*domain.Family
 	for rows.Next() {
 		family := &domain.Family{}
-		err := rows.Scan(
-			&family.ID,
-			&family.Name,
-			&family.PasswordHash,
-			&family.Password,
-			&family.BabyName,
-			&family.CreatedAt,
-			&family.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanFamily(rows, family); err != nil {
 			return nil, fmt.Errorf("failed to scan family: %w", err)
 		}
 		families = append(families, family)
@@ -188,6 +218,36 @@ func (s *PostgresStore) GetFamiliesByUserID(ctx context.Context, userID uuid.UUI
 	return families, nil
 }
 
+// GetActiveFamilyIDs retrieves the families with a care session in progress or updated since the given time
+func (s *PostgresStore) GetActiveFamilyIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT family_id
+		FROM care_sessions
+		WHERE deleted_at IS NULL AND (status = 'in_progress' OR updated_at >= $1)
+		ORDER BY family_id
+	`, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active families: %w", err)
+	}
+	defer rows.Close()
+
+	var familyIDs []uuid.UUID
+	for rows.Next() {
+		var familyID uuid.UUID
+		if err := rows.Scan(&familyID); err != nil {
+			return nil, fmt.Errorf("failed to scan family ID: %w", err)
+		}
+		familyIDs = append(familyIDs, familyID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating active families: %w", err)
+	}
+
+	return familyIDs, nil
+}
+
 // FamilyNameExists checks if a family name already exists (case-insensitive)
 func (s *PostgresStore) FamilyNameExists(ctx context.Context, name string) (bool, error) {
 	var exists bool
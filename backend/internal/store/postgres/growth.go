@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Growth measurement operations
+
+// CreateGrowthMeasurement inserts a weight/length/head circumference measurement
+func (s *PostgresStore) CreateGrowthMeasurement(ctx context.Context, m *domain.GrowthMeasurement) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO growth_measurements (id, family_id, baby_id, caregiver_id, measured_at, weight_kg, length_cm,
+		        head_circumference_cm, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, m.ID, m.FamilyID, m.BabyID, m.CaregiverID, m.MeasuredAt, m.WeightKg, m.LengthCm,
+		m.HeadCircumferenceCm, m.Notes, m.CreatedAt, m.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create growth measurement: %w", err)
+	}
+
+	return nil
+}
+
+// GetGrowthMeasurementsForBaby retrieves all of a baby's measurements, oldest first
+func (s *PostgresStore) GetGrowthMeasurementsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.GrowthMeasurement, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, family_id, baby_id, caregiver_id, measured_at, weight_kg, length_cm,
+		       head_circumference_cm, notes, created_at, updated_at
+		FROM growth_measurements
+		WHERE baby_id = $1
+		ORDER BY measured_at ASC
+	`, babyID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query growth measurements: %w", err)
+	}
+	defer rows.Close()
+
+	var measurements []*domain.GrowthMeasurement
+	for rows.Next() {
+		m := &domain.GrowthMeasurement{}
+		err := rows.Scan(
+			&m.ID,
+			&m.FamilyID,
+			&m.BabyID,
+			&m.CaregiverID,
+			&m.MeasuredAt,
+			&m.WeightKg,
+			&m.LengthCm,
+			&m.HeadCircumferenceCm,
+			&m.Notes,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan growth measurement: %w", err)
+		}
+		measurements = append(measurements, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating growth measurements: %w", err)
+	}
+
+	return measurements, nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Invite operations
+
+const familyInviteColumns = `id, family_id, code_hash, created_by, expires_at, max_uses, use_count, revoked_at,
+		        role, created_at, updated_at`
+
+func scanFamilyInvite(row interface{ Scan(...any) error }, i *domain.FamilyInvite) error {
+	return row.Scan(
+		&i.ID, &i.FamilyID, &i.CodeHash, &i.CreatedBy, &i.ExpiresAt, &i.MaxUses, &i.UseCount, &i.RevokedAt,
+		&i.Role, &i.CreatedAt, &i.UpdatedAt,
+	)
+}
+
+// CreateFamilyInvite creates an invite
+func (s *PostgresStore) CreateFamilyInvite(ctx context.Context, invite *domain.FamilyInvite) error {
+	if invite.Role == "" {
+		invite.Role = domain.RoleCaregiver
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO family_invites (id, family_id, code_hash, created_by, expires_at, max_uses, use_count, revoked_at,
+		        role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, invite.ID, invite.FamilyID, invite.CodeHash, invite.CreatedBy, invite.ExpiresAt, invite.MaxUses, invite.UseCount,
+		invite.RevokedAt, invite.Role, invite.CreatedAt, invite.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return nil
+}
+
+// GetFamilyInviteByID retrieves an invite by ID
+func (s *PostgresStore) GetFamilyInviteByID(ctx context.Context, id uuid.UUID) (*domain.FamilyInvite, error) {
+	invite := &domain.FamilyInvite{}
+	err := scanFamilyInvite(s.db.QueryRowContext(ctx, `
+		SELECT `+familyInviteColumns+`
+		FROM family_invites
+		WHERE id = $1
+	`, id), invite)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invite not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// GetFamilyInviteByCodeHash retrieves an invite by the hash of its code
+func (s *PostgresStore) GetFamilyInviteByCodeHash(ctx context.Context, codeHash string) (*domain.FamilyInvite, error) {
+	invite := &domain.FamilyInvite{}
+	err := scanFamilyInvite(s.db.QueryRowContext(ctx, `
+		SELECT `+familyInviteColumns+`
+		FROM family_invites
+		WHERE code_hash = $1
+	`, codeHash), invite)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invite not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// GetFamilyInvitesForFamily retrieves a family's invites, newest first
+func (s *PostgresStore) GetFamilyInvitesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.FamilyInvite, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+familyInviteColumns+`
+		FROM family_invites
+		WHERE family_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, familyID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*domain.FamilyInvite
+	for rows.Next() {
+		invite := &domain.FamilyInvite{}
+		if err := scanFamilyInvite(rows, invite); err != nil {
+			return nil, fmt.Errorf("failed to scan invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating invites: %w", err)
+	}
+
+	return invites, nil
+}
+
+// RedeemFamilyInvite counts one use of the invite if it is active at now, and reports whether it was.
+// The check and increment are one statement so concurrent joins can't exceed max_uses.
+func (s *PostgresStore) RedeemFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE family_invites
+		SET use_count = use_count + 1
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > $2
+		  AND (max_uses IS NULL OR use_count < max_uses)
+	`, id, now)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem invite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 1 {
+		return true, nil
+	}
+
+	// Tell an unusable invite apart from a missing one
+	if _, err := s.GetFamilyInviteByID(ctx, id); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// RevokeFamilyInvite revokes an invite. Revoking an already revoked invite keeps the original time.
+func (s *PostgresStore) RevokeFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE family_invites
+		SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE id = $1
+	`, id, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invite not found: %s", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+const medicationColumns = `id, family_id, name, default_dose_amount, default_dose_unit, default_route,
+		min_interval_minutes, max_daily_doses, created_at, updated_at`
+
+func scanMedication(row interface{ Scan(...any) error }, m *domain.Medication) error {
+	return row.Scan(
+		&m.ID, &m.FamilyID, &m.Name,
+		&m.DefaultDoseAmount, &m.DefaultDoseUnit, &m.DefaultRoute,
+		&m.MinIntervalMinutes, &m.MaxDailyDoses,
+		&m.CreatedAt, &m.UpdatedAt,
+	)
+}
+
+// UpsertMedication creates a catalog entry or replaces the one with the same name (case-insensitive) in the family
+func (s *PostgresStore) UpsertMedication(ctx context.Context, medication *domain.Medication) (*domain.Medication, error) {
+	now := time.Now()
+	if medication.ID == uuid.Nil {
+		medication.ID = uuid.New()
+	}
+
+	err := scanMedication(s.db.QueryRowContext(ctx, `
+		INSERT INTO medications (id, family_id, name, default_dose_amount, default_dose_unit, default_route,
+		        min_interval_minutes, max_daily_doses, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (family_id, LOWER(name)) DO UPDATE SET
+		        name = EXCLUDED.name,
+		        default_dose_amount = EXCLUDED.default_dose_amount,
+		        default_dose_unit = EXCLUDED.default_dose_unit,
+		        default_route = EXCLUDED.default_route,
+		        min_interval_minutes = EXCLUDED.min_interval_minutes,
+		        max_daily_doses = EXCLUDED.max_daily_doses,
+		        updated_at = $10
+		RETURNING `+medicationColumns,
+		medication.ID, medication.FamilyID, medication.Name,
+		medication.DefaultDoseAmount, medication.DefaultDoseUnit, medication.DefaultRoute,
+		medication.MinIntervalMinutes, medication.MaxDailyDoses,
+		now, now,
+	), medication)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert medication: %w", err)
+	}
+
+	return medication, nil
+}
+
+// GetMedicationByID retrieves a catalog entry by ID
+func (s *PostgresStore) GetMedicationByID(ctx context.Context, id uuid.UUID) (*domain.Medication, error) {
+	m := &domain.Medication{}
+	err := scanMedication(s.db.QueryRowContext(ctx, `
+		SELECT `+medicationColumns+`
+		FROM medications
+		WHERE id = $1
+	`, id), m)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("medication not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication: %w", err)
+	}
+
+	return m, nil
+}
+
+// GetMedicationByName retrieves a family's catalog entry by name (case-insensitive).
+// Returns (nil, nil) if the family has no entry for that name.
+func (s *PostgresStore) GetMedicationByName(ctx context.Context, familyID uuid.UUID, name string) (*domain.Medication, error) {
+	m := &domain.Medication{}
+	err := scanMedication(s.db.QueryRowContext(ctx, `
+		SELECT `+medicationColumns+`
+		FROM medications
+		WHERE family_id = $1 AND LOWER(name) = LOWER($2)
+	`, familyID, name), m)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication by name: %w", err)
+	}
+
+	return m, nil
+}
+
+// GetMedicationsForFamily retrieves a family's medication catalog ordered by name
+func (s *PostgresStore) GetMedicationsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Medication, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+medicationColumns+`
+		FROM medications
+		WHERE family_id = $1
+		ORDER BY LOWER(name)
+	`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query medications: %w", err)
+	}
+	defer rows.Close()
+
+	var medications []*domain.Medication
+	for rows.Next() {
+		m := &domain.Medication{}
+		if err := scanMedication(rows, m); err != nil {
+			return nil, fmt.Errorf("failed to scan medication: %w", err)
+		}
+		medications = append(medications, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating medications: %w", err)
+	}
+
+	return medications, nil
+}
+
+// DeleteMedication removes a catalog entry. Logged doses keep their drug name but lose the catalog link.
+func (s *PostgresStore) DeleteMedication(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM medications WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete medication: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("medication not found: %s", id)
+	}
+
+	return nil
+}
@@ -1,16 +1,29 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/swatkatz/babybaton/backend/internal/store"
 )
 
+// querier is the subset of *sql.DB and *sql.Tx used by the store methods, so the same
+// queries run either directly against the pool or inside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 // PostgresStore implements the Store interface using PostgreSQL
 type PostgresStore struct {
-	db *sql.DB
+	db   querier
+	pool *sql.DB
+	tx   *sql.Tx // set when the store is bound to a transaction by WithTx
 }
 
 // Ensure PostgresStore implements Store interface
@@ -18,7 +31,12 @@ var _ store.Store = (*PostgresStore)(nil)
 
 // NewPostgresStore creates a new PostgreSQL store
 func NewPostgresStore(databaseURL string) (*PostgresStore, error) {
-	db, err := sql.Open("postgres", databaseURL)
+	dsn, err := utcSession(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -28,10 +46,71 @@ func NewPostgresStore(databaseURL string) (*PostgresStore, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresStore{db: db}, nil
+	return &PostgresStore{db: db, pool: db}, nil
 }
 
-// Close closes the database connection
+// utcSession sets the session TimeZone to UTC unless the connection string already picks
+// one. Columns are TIMESTAMPTZ, so stored instants don't depend on it, but it decides the
+// zone times are read back in.
+func utcSession(databaseURL string) (string, error) {
+	dsn := databaseURL
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(dsn); err != nil {
+			return "", err
+		}
+	}
+	if strings.Contains(strings.ToLower(dsn), "timezone=") {
+		return dsn, nil
+	}
+	return strings.TrimSpace(dsn + " timezone=UTC"), nil
+}
+
+// Close closes the database connection. Stores bound to a transaction share the
+// parent's pool, so closing them is a no-op.
 func (s *PostgresStore) Close() error {
-	return s.db.Close()
+	if s.tx != nil {
+		return nil
+	}
+	return s.pool.Close()
+}
+
+// WithTx runs fn against a store bound to a single transaction, committing if fn
+// returns nil and rolling back otherwise. Calls nested inside fn join the outer
+// transaction rather than starting their own.
+func (s *PostgresStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return s.withTx(ctx, func(tx *PostgresStore) error {
+		return fn(tx)
+	})
+}
+
+func (s *PostgresStore) withTx(ctx context.Context, fn func(*PostgresStore) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	tx, err := s.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&PostgresStore{db: tx, pool: s.pool, tx: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// uuidArray converts ids to a parameter for queries matching against = ANY($n::UUID[])
+func uuidArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
 }
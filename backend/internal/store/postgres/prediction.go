@@ -9,56 +9,52 @@ import (
 	"github.com/swatkatz/babybaton/backend/internal/domain"
 )
 
-func (s *PostgresStore) UpsertPredictions(ctx context.Context, familyID uuid.UUID, predictions []*domain.Prediction) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// Delete existing non-dismissed predictions for this family
-	_, err = tx.ExecContext(ctx,
-		`DELETE FROM predictions WHERE family_id = $1 AND dismissed_at IS NULL`,
-		familyID,
-	)
-	if err != nil {
-		return err
-	}
-
-	// Insert new predictions
-	for _, p := range predictions {
-		var careSessionID *uuid.UUID
-		if p.CareSessionID != nil {
-			careSessionID = p.CareSessionID
-		}
-		var confidence *string
-		if p.Confidence != nil {
-			c := string(*p.Confidence)
-			confidence = &c
-		}
-
-		_, err = tx.ExecContext(ctx,
-			`INSERT INTO predictions (id, family_id, care_session_id, activity_type, prediction_type, predicted_time, status, confidence, reasoning, predicted_amount_ml, predicted_duration_minutes, computed_at)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
-			p.ID, familyID, careSessionID, string(p.ActivityType), string(p.PredictionType),
-			p.PredictedTime, string(p.Status), confidence, p.Reasoning,
-			p.PredictedAmountMl, p.PredictedDurationMinutes, p.ComputedAt,
+func (s *PostgresStore) UpsertPredictions(ctx context.Context, babyID uuid.UUID, predictions []*domain.Prediction) error {
+	return s.withTx(ctx, func(tx *PostgresStore) error {
+		// Delete existing non-dismissed predictions for this baby
+		_, err := tx.db.ExecContext(ctx,
+			`DELETE FROM predictions WHERE baby_id = $1 AND dismissed_at IS NULL`,
+			babyID,
 		)
 		if err != nil {
 			return err
 		}
-	}
 
-	return tx.Commit()
+		// Insert new predictions
+		for _, p := range predictions {
+			var careSessionID *uuid.UUID
+			if p.CareSessionID != nil {
+				careSessionID = p.CareSessionID
+			}
+			var confidence *string
+			if p.Confidence != nil {
+				c := string(*p.Confidence)
+				confidence = &c
+			}
+
+			_, err = tx.db.ExecContext(ctx,
+				`INSERT INTO predictions (id, family_id, baby_id, care_session_id, activity_type, prediction_type, predicted_time, status, confidence, reasoning, predicted_amount_ml, predicted_duration_minutes, computed_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+				p.ID, p.FamilyID, babyID, careSessionID, string(p.ActivityType), string(p.PredictionType),
+				p.PredictedTime, string(p.Status), confidence, p.Reasoning,
+				p.PredictedAmountMl, p.PredictedDurationMinutes, p.ComputedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
-func (s *PostgresStore) GetPredictionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Prediction, error) {
+func (s *PostgresStore) GetPredictionsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.Prediction, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, family_id, care_session_id, activity_type, prediction_type, predicted_time, status, confidence, reasoning, predicted_amount_ml, predicted_duration_minutes, computed_at, created_at
+		`SELECT id, family_id, baby_id, care_session_id, activity_type, prediction_type, predicted_time, status, confidence, reasoning, predicted_amount_ml, predicted_duration_minutes, computed_at, created_at
 		 FROM predictions
-		 WHERE family_id = $1 AND dismissed_at IS NULL
+		 WHERE baby_id = $1 AND dismissed_at IS NULL
 		 ORDER BY predicted_time ASC`,
-		familyID,
+		babyID,
 	)
 	if err != nil {
 		return nil, err
@@ -73,7 +69,7 @@ func (s *PostgresStore) GetPredictionsForFamily(ctx context.Context, familyID uu
 		var confidence *string
 
 		err := rows.Scan(
-			&p.ID, &p.FamilyID, &careSessionID,
+			&p.ID, &p.FamilyID, &p.BabyID, &careSessionID,
 			&activityType, &predictionType, &p.PredictedTime,
 			&status, &confidence, &p.Reasoning,
 			&p.PredictedAmountMl, &p.PredictedDurationMinutes,
@@ -118,10 +114,10 @@ func (s *PostgresStore) DismissPrediction(ctx context.Context, id uuid.UUID) err
 	return nil
 }
 
-func (s *PostgresStore) DeletePredictionsForFamily(ctx context.Context, familyID uuid.UUID) error {
+func (s *PostgresStore) DeletePredictionsForBaby(ctx context.Context, babyID uuid.UUID) error {
 	_, err := s.db.ExecContext(ctx,
-		`DELETE FROM predictions WHERE family_id = $1`,
-		familyID,
+		`DELETE FROM predictions WHERE baby_id = $1`,
+		babyID,
 	)
 	return err
 }
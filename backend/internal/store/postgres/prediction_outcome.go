@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Prediction outcome operations
+
+// CreatePredictionOutcome records how a prediction turned out. A prediction keeps its first
+// outcome; later ones are ignored.
+func (s *PostgresStore) CreatePredictionOutcome(ctx context.Context, o *domain.PredictionOutcome) error {
+	var confidence *string
+	if o.Confidence != nil {
+		c := string(*o.Confidence)
+		confidence = &c
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO prediction_outcomes (id, family_id, baby_id, prediction_id, activity_id, prediction_type,
+		        confidence, predicted_time, actual_time, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (prediction_id) DO NOTHING
+	`, o.ID, o.FamilyID, o.BabyID, o.PredictionID, o.ActivityID, string(o.PredictionType),
+		confidence, o.PredictedTime, o.ActualTime, o.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create prediction outcome: %w", err)
+	}
+
+	return nil
+}
+
+// GetPredictionOutcomesForFamily retrieves the outcomes of a family's events since since, oldest first
+func (s *PostgresStore) GetPredictionOutcomesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return s.queryPredictionOutcomes(ctx, `family_id = $1`, familyID, since)
+}
+
+// GetPredictionOutcomesForBaby retrieves the outcomes of a baby's events since since, oldest first
+func (s *PostgresStore) GetPredictionOutcomesForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	return s.queryPredictionOutcomes(ctx, `baby_id = $1`, babyID, since)
+}
+
+func (s *PostgresStore) queryPredictionOutcomes(ctx context.Context, where string, id uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, family_id, baby_id, prediction_id, activity_id, prediction_type, confidence,
+		       predicted_time, actual_time, created_at
+		FROM prediction_outcomes
+		WHERE `+where+` AND actual_time >= $2
+		ORDER BY actual_time ASC, id ASC
+	`, id, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prediction outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []*domain.PredictionOutcome
+	for rows.Next() {
+		o := &domain.PredictionOutcome{}
+		var predictionType string
+		var confidence *string
+		err := rows.Scan(
+			&o.ID,
+			&o.FamilyID,
+			&o.BabyID,
+			&o.PredictionID,
+			&o.ActivityID,
+			&predictionType,
+			&confidence,
+			&o.PredictedTime,
+			&o.ActualTime,
+			&o.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prediction outcome: %w", err)
+		}
+		o.PredictionType = domain.PredictionType(predictionType)
+		if confidence != nil {
+			c := domain.PredictionConfidence(*confidence)
+			o.Confidence = &c
+		}
+		outcomes = append(outcomes, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prediction outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
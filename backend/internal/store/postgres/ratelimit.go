@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Rate limit operations
+
+// RateLimitBackend stores rate limiter counters in Postgres so every instance shares them.
+// It implements ratelimit.Backend.
+type RateLimitBackend struct {
+	db querier
+}
+
+// RateLimitBackend returns a rate limiter backend using the store's database
+func (s *PostgresStore) RateLimitBackend() *RateLimitBackend {
+	return &RateLimitBackend{db: s.pool}
+}
+
+// Hit adds one to key's counter, starting a new window if the current one has ended
+func (b *RateLimitBackend) Hit(ctx context.Context, key string, window time.Duration, now time.Time) (int, time.Time, error) {
+	var count int
+	var resetAt time.Time
+	err := b.db.QueryRowContext(ctx, `
+		INSERT INTO rate_limits (key, count, reset_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limits.reset_at <= $3 THEN 1 ELSE rate_limits.count + 1 END,
+			reset_at = CASE WHEN rate_limits.reset_at <= $3 THEN EXCLUDED.reset_at ELSE rate_limits.reset_at END
+		RETURNING count, reset_at
+	`, key, now.Add(window), now).Scan(&count, &resetAt)
+
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to hit rate limit: %w", err)
+	}
+
+	return count, resetAt, nil
+}
+
+// Peek returns key's live counter, or zero if it has none
+func (b *RateLimitBackend) Peek(ctx context.Context, key string, now time.Time) (int, time.Time, error) {
+	var count int
+	var resetAt time.Time
+	err := b.db.QueryRowContext(ctx, `
+		SELECT count, reset_at FROM rate_limits WHERE key = $1 AND reset_at > $2
+	`, key, now).Scan(&count, &resetAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get rate limit: %w", err)
+	}
+
+	return count, resetAt, nil
+}
+
+// Reset deletes key's counter
+func (b *RateLimitBackend) Reset(ctx context.Context, key string) error {
+	_, err := b.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes counters whose window has ended
+func (b *RateLimitBackend) Prune(ctx context.Context, now time.Time) error {
+	_, err := b.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE reset_at <= $1`, now)
+	if err != nil {
+		return fmt.Errorf("failed to prune rate limits: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Reminder preference operations
+
+const reminderPreferencesColumns = `caregiver_id, family_id, feed_reminders, nap_reminders, bedtime_reminders,
+		        overdue_reminders, lead_minutes, created_at, updated_at`
+
+func scanReminderPreferences(row interface{ Scan(...any) error }, prefs *domain.ReminderPreferences) error {
+	return row.Scan(
+		&prefs.CaregiverID, &prefs.FamilyID,
+		&prefs.FeedReminders, &prefs.NapReminders, &prefs.BedtimeReminders, &prefs.OverdueReminders,
+		&prefs.LeadMinutes, &prefs.CreatedAt, &prefs.UpdatedAt,
+	)
+}
+
+// GetReminderPreferences retrieves a caregiver's reminder preferences. Returns (nil, nil) if none are set.
+func (s *PostgresStore) GetReminderPreferences(ctx context.Context, caregiverID uuid.UUID) (*domain.ReminderPreferences, error) {
+	prefs := &domain.ReminderPreferences{}
+	err := scanReminderPreferences(s.db.QueryRowContext(ctx,
+		`SELECT `+reminderPreferencesColumns+`
+		 FROM reminder_preferences
+		 WHERE caregiver_id = $1`,
+		caregiverID,
+	), prefs)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// GetReminderPreferencesForFamily retrieves the reminder preferences of every caregiver in a family
+func (s *PostgresStore) GetReminderPreferencesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.ReminderPreferences, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+reminderPreferencesColumns+`
+		 FROM reminder_preferences
+		 WHERE family_id = $1
+		 ORDER BY created_at ASC, caregiver_id ASC`,
+		familyID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminder preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.ReminderPreferences
+	for rows.Next() {
+		prefs := &domain.ReminderPreferences{}
+		if err := scanReminderPreferences(rows, prefs); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder preferences: %w", err)
+		}
+		result = append(result, prefs)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminder preferences: %w", err)
+	}
+
+	return result, nil
+}
+
+// UpsertReminderPreferences sets a caregiver's reminder preferences. The family is taken
+// from the caregiver.
+func (s *PostgresStore) UpsertReminderPreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	now := time.Now()
+
+	err := scanReminderPreferences(s.db.QueryRowContext(ctx,
+		`INSERT INTO reminder_preferences (caregiver_id, family_id, feed_reminders, nap_reminders,
+		        bedtime_reminders, overdue_reminders, lead_minutes, created_at, updated_at)
+		 SELECT id, family_id, $2, $3, $4, $5, $6, $7, $7
+		 FROM caregivers WHERE id = $1
+		 ON CONFLICT (caregiver_id) DO UPDATE SET
+		        feed_reminders = EXCLUDED.feed_reminders,
+		        nap_reminders = EXCLUDED.nap_reminders,
+		        bedtime_reminders = EXCLUDED.bedtime_reminders,
+		        overdue_reminders = EXCLUDED.overdue_reminders,
+		        lead_minutes = EXCLUDED.lead_minutes,
+		        updated_at = $7
+		 RETURNING `+reminderPreferencesColumns,
+		prefs.CaregiverID,
+		prefs.FeedReminders, prefs.NapReminders, prefs.BedtimeReminders, prefs.OverdueReminders,
+		prefs.LeadMinutes, now,
+	), prefs)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("caregiver not found: %s", prefs.CaregiverID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reminder preferences: %w", err)
+	}
+
+	return prefs, nil
+}
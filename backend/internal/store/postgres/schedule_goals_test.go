@@ -16,7 +16,7 @@ func TestScheduleGoals(t *testing.T) {
 
 	ctx := context.Background()
 
-	family, _, err := CreateTestFamily(ctx, store)
+	family, baby, _, err := CreateTestFamilyWithBaby(ctx, store)
 	if err != nil {
 		t.Fatalf("Failed to create test family: %v", err)
 	}
@@ -25,7 +25,7 @@ func TestScheduleGoals(t *testing.T) {
 	})
 
 	t.Run("GetReturnsNilWhenNone", func(t *testing.T) {
-		result, err := store.GetScheduleGoals(ctx, family.ID)
+		result, err := store.GetScheduleGoals(ctx, baby.ID)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -51,13 +51,16 @@ func TestScheduleGoals(t *testing.T) {
 			TargetWakeTime:            &wakeTime,
 		}
 
-		result, err := store.UpsertScheduleGoals(ctx, family.ID, goals)
+		result, err := store.UpsertScheduleGoals(ctx, baby.ID, goals)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if result.FamilyID != family.ID {
 			t.Errorf("FamilyID = %v, want %v", result.FamilyID, family.ID)
 		}
+		if result.BabyID != baby.ID {
+			t.Errorf("BabyID = %v, want %v", result.BabyID, baby.ID)
+		}
 		if *result.TargetWakeWindowMinutes != 90 {
 			t.Errorf("TargetWakeWindowMinutes = %v, want 90", *result.TargetWakeWindowMinutes)
 		}
@@ -78,7 +81,7 @@ func TestScheduleGoals(t *testing.T) {
 		}
 
 		// Verify via Get
-		retrieved, err := store.GetScheduleGoals(ctx, family.ID)
+		retrieved, err := store.GetScheduleGoals(ctx, baby.ID)
 		if err != nil {
 			t.Fatalf("unexpected error on get: %v", err)
 		}
@@ -96,7 +99,7 @@ func TestScheduleGoals(t *testing.T) {
 			TargetWakeWindowMinutes: &newWakeWindow,
 		}
 
-		result, err := store.UpsertScheduleGoals(ctx, family.ID, goals)
+		result, err := store.UpsertScheduleGoals(ctx, baby.ID, goals)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -112,7 +115,7 @@ func TestScheduleGoals(t *testing.T) {
 
 	t.Run("PartialFields", func(t *testing.T) {
 		// Create a new family for this test
-		family2, _, err := CreateTestFamily(ctx, store)
+		family2, baby2, _, err := CreateTestFamilyWithBaby(ctx, store)
 		if err != nil {
 			t.Fatalf("Failed to create test family: %v", err)
 		}
@@ -125,7 +128,7 @@ func TestScheduleGoals(t *testing.T) {
 			TargetNapCount: &napCount,
 		}
 
-		result, err := store.UpsertScheduleGoals(ctx, family2.ID, goals)
+		result, err := store.UpsertScheduleGoals(ctx, baby2.ID, goals)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -141,7 +144,7 @@ func TestScheduleGoals(t *testing.T) {
 	})
 
 	t.Run("ScopedToFamily", func(t *testing.T) {
-		family2, _, err := CreateTestFamily(ctx, store)
+		family2, baby2, _, err := CreateTestFamilyWithBaby(ctx, store)
 		if err != nil {
 			t.Fatalf("Failed to create second family: %v", err)
 		}
@@ -153,17 +156,17 @@ func TestScheduleGoals(t *testing.T) {
 		goals := &domain.ScheduleGoals{
 			TargetNapCount: &napCount,
 		}
-		_, err = store.UpsertScheduleGoals(ctx, family2.ID, goals)
+		_, err = store.UpsertScheduleGoals(ctx, baby2.ID, goals)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
 		// Family 1 should still have its own goals (from earlier tests)
-		result1, err := store.GetScheduleGoals(ctx, family.ID)
+		result1, err := store.GetScheduleGoals(ctx, baby.ID)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		result2, err := store.GetScheduleGoals(ctx, family2.ID)
+		result2, err := store.GetScheduleGoals(ctx, baby2.ID)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -180,13 +183,13 @@ func TestScheduleGoals(t *testing.T) {
 	})
 
 	t.Run("CascadeDelete", func(t *testing.T) {
-		family3, _, err := CreateTestFamily(ctx, store)
+		family3, baby3, _, err := CreateTestFamilyWithBaby(ctx, store)
 		if err != nil {
 			t.Fatalf("Failed to create family: %v", err)
 		}
 
 		napCount := 1
-		_, err = store.UpsertScheduleGoals(ctx, family3.ID, &domain.ScheduleGoals{
+		_, err = store.UpsertScheduleGoals(ctx, baby3.ID, &domain.ScheduleGoals{
 			TargetNapCount: &napCount,
 		})
 		if err != nil {
@@ -200,7 +203,7 @@ func TestScheduleGoals(t *testing.T) {
 		}
 
 		// Goals should be gone
-		result, err := store.GetScheduleGoals(ctx, family3.ID)
+		result, err := store.GetScheduleGoals(ctx, baby3.ID)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
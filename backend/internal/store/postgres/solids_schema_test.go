@@ -19,7 +19,7 @@ func TestSolidsSchema(t *testing.T) {
 	ctx := context.Background()
 
 	// Create unique test family using helper
-	family, caregiver, err := CreateTestFamily(ctx, store)
+	family, baby, caregiver, err := CreateTestFamilyWithBaby(ctx, store)
 	if err != nil {
 		t.Fatalf("Failed to create test family: %v", err)
 	}
@@ -47,6 +47,7 @@ func TestSolidsSchema(t *testing.T) {
 	feedActivity := &domain.Activity{
 		ID:            uuid.New(),
 		CareSessionID: session.ID,
+		BabyID:        baby.ID,
 		ActivityType:  domain.ActivityTypeFeed,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -93,6 +94,7 @@ func TestSolidsSchema(t *testing.T) {
 		feedActivity2 := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -139,6 +141,7 @@ func TestSolidsSchema(t *testing.T) {
 		feedActivity3 := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -169,6 +172,7 @@ func TestSolidsSchema(t *testing.T) {
 		feedActivity4 := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -201,6 +205,7 @@ func TestSolidsSchema(t *testing.T) {
 		feedActivity5 := &domain.Activity{
 			ID:            uuid.New(),
 			CareSessionID: session.ID,
+			BabyID:        baby.ID,
 			ActivityType:  domain.ActivityTypeFeed,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Offline sync operations
+
+// GetIdempotencyRecord looks up a previously applied write by its idempotency key.
+// Returns (nil, nil) if the key hasn't been seen.
+func (s *PostgresStore) GetIdempotencyRecord(ctx context.Context, familyID uuid.UUID, key string) (*domain.IdempotencyRecord, error) {
+	record := &domain.IdempotencyRecord{}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT family_id, idempotency_key, activity_id, operation, created_at
+		FROM idempotency_keys
+		WHERE family_id = $1 AND idempotency_key = $2
+	`, familyID, key).Scan(
+		&record.FamilyID,
+		&record.Key,
+		&record.ActivityID,
+		&record.Operation,
+		&record.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	return record, nil
+}
+
+// SaveIdempotencyRecord marks a write as applied
+func (s *PostgresStore) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (family_id, idempotency_key, activity_id, operation, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, record.FamilyID, record.Key, record.ActivityID, record.Operation, record.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// RecordDeletedActivity stores a tombstone for a deleted activity
+func (s *PostgresStore) RecordDeletedActivity(ctx context.Context, deleted *domain.DeletedActivity) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO deleted_activities (activity_id, family_id, baby_id, deleted_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (activity_id) DO NOTHING
+	`, deleted.ActivityID, deleted.FamilyID, deleted.BabyID, deleted.DeletedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record deleted activity: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeletedActivity retrieves the tombstone for an activity.
+// Returns (nil, nil) if the activity hasn't been deleted.
+func (s *PostgresStore) GetDeletedActivity(ctx context.Context, activityID uuid.UUID) (*domain.DeletedActivity, error) {
+	deleted := &domain.DeletedActivity{}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT activity_id, family_id, baby_id, deleted_at
+		FROM deleted_activities
+		WHERE activity_id = $1
+	`, activityID).Scan(
+		&deleted.ActivityID,
+		&deleted.FamilyID,
+		&deleted.BabyID,
+		&deleted.DeletedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted activity: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ClearDeletedActivity removes the tombstone for a restored activity
+func (s *PostgresStore) ClearDeletedActivity(ctx context.Context, activityID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM deleted_activities WHERE activity_id = $1
+	`, activityID)
+
+	if err != nil {
+		return fmt.Errorf("failed to clear deleted activity: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeletedActivitiesSinceForFamily retrieves tombstones recorded after since, oldest first
+func (s *PostgresStore) GetDeletedActivitiesSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.DeletedActivity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT activity_id, family_id, baby_id, deleted_at
+		FROM deleted_activities
+		WHERE family_id = $1 AND deleted_at > $2
+		ORDER BY deleted_at ASC
+	`, familyID, since)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted activities: %w", err)
+	}
+	defer rows.Close()
+
+	var deleted []*domain.DeletedActivity
+	for rows.Next() {
+		d := &domain.DeletedActivity{}
+		if err := rows.Scan(&d.ActivityID, &d.FamilyID, &d.BabyID, &d.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted activity: %w", err)
+		}
+		deleted = append(deleted, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted activities: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -11,6 +11,12 @@ import (
 
 // CreateTestFamily creates a unique test family with a caregiver for testing
 func CreateTestFamily(ctx context.Context, store *PostgresStore) (*domain.Family, *domain.Caregiver, error) {
+	family, _, caregiver, err := CreateTestFamilyWithBaby(ctx, store)
+	return family, caregiver, err
+}
+
+// CreateTestFamilyWithBaby creates a unique test family with its first baby and a caregiver for testing
+func CreateTestFamilyWithBaby(ctx context.Context, store *PostgresStore) (*domain.Family, *domain.Baby, *domain.Caregiver, error) {
 	// Generate unique name using UUID
 	uniqueName := "Test Family " + uuid.New().String()[:8]
 	password := "testpass"
@@ -21,12 +27,19 @@ func CreateTestFamily(ctx context.Context, store *PostgresStore) (*domain.Family
 		ID:           uuid.New(),
 		Name:         uniqueName,
 		PasswordHash: string(passwordHash),
-		Password: password,
 		BabyName:     "Test Baby",
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
+	baby := &domain.Baby{
+		ID:        uuid.New(),
+		FamilyID:  family.ID,
+		Name:      family.BabyName,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
 	deviceID := "test-device-" + uuid.New().String()[:8]
 	caregiver := &domain.Caregiver{
 		ID:         uuid.New(),
@@ -38,12 +51,12 @@ func CreateTestFamily(ctx context.Context, store *PostgresStore) (*domain.Family
 		UpdatedAt:  time.Now(),
 	}
 
-	err := store.CreateFamilyWithCaregiver(ctx, family, caregiver)
+	err := store.CreateFamilyWithCaregiver(ctx, family, baby, caregiver)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	return family, caregiver, nil
+	return family, baby, caregiver, nil
 }
 
 func stringPtr(s string) *string {
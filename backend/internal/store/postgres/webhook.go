@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+)
+
+// Webhook operations
+
+const webhookSubscriptionColumns = `id, family_id, url, secret, event_types, created_at, updated_at`
+
+func scanWebhookSubscription(row interface{ Scan(...any) error }, sub *domain.WebhookSubscription) error {
+	var eventTypes pq.StringArray
+	err := row.Scan(&sub.ID, &sub.FamilyID, &sub.URL, &sub.Secret, &eventTypes, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	sub.EventTypes = nil
+	for _, t := range eventTypes {
+		sub.EventTypes = append(sub.EventTypes, domain.WebhookEventType(t))
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `id, subscription_id, family_id, event_type, payload, status, attempts,
+		        next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at`
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }, d *domain.WebhookDelivery) error {
+	return row.Scan(
+		&d.ID, &d.SubscriptionID, &d.FamilyID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+}
+
+// CreateWebhookSubscription creates a webhook subscription
+func (s *PostgresStore) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	eventTypes := make(pq.StringArray, 0, len(sub.EventTypes))
+	for _, t := range sub.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (id, family_id, url, secret, event_types, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, sub.ID, sub.FamilyID, sub.URL, sub.Secret, eventTypes, sub.CreatedAt, sub.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetWebhookSubscriptionByID retrieves a webhook subscription by ID
+func (s *PostgresStore) GetWebhookSubscriptionByID(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	sub := &domain.WebhookSubscription{}
+	err := scanWebhookSubscription(s.db.QueryRowContext(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE id = $1
+	`, id), sub)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook subscription not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// GetWebhookSubscriptionsForFamily retrieves a family's webhook subscriptions, oldest first
+func (s *PostgresStore) GetWebhookSubscriptionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE family_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, familyID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*domain.WebhookSubscription
+	for rows.Next() {
+		sub := &domain.WebhookSubscription{}
+		if err := scanWebhookSubscription(rows, sub); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription and its deliveries
+func (s *PostgresStore) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription not found: %s", id)
+	}
+
+	return nil
+}
+
+// CreateWebhookDelivery queues a delivery
+func (s *PostgresStore) CreateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, subscription_id, family_id, event_type, payload, status, attempts,
+		        next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, d.ID, d.SubscriptionID, d.FamilyID, d.EventType, d.Payload, d.Status, d.Attempts,
+		d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.CreatedAt, d.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueWebhookDeliveries retrieves up to limit pending deliveries due at or before now, earliest first
+func (s *PostgresStore) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	return s.queryWebhookDeliveries(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT $2
+	`, now, limit)
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt
+func (s *PostgresStore) UpdateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6,
+		    delivered_at = $7
+		WHERE id = $1
+	`, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook delivery not found: %s", d.ID)
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveriesForFamily retrieves a family's most recent deliveries, newest first,
+// optionally only those with the given status
+func (s *PostgresStore) GetWebhookDeliveriesForFamily(ctx context.Context, familyID uuid.UUID, status *domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	return s.queryWebhookDeliveries(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE family_id = $1 AND ($2::VARCHAR IS NULL OR status = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`, familyID, status, limit)
+}
+
+func (s *PostgresStore) queryWebhookDeliveries(ctx context.Context, query string, args ...any) ([]*domain.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d := &domain.WebhookDelivery{}
+		if err := scanWebhookDelivery(rows, d); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,30 +12,54 @@ import (
 // Store defines the interface for data persistence operations
 type Store interface {
 	// Family operations
-	CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, caregiver *domain.Caregiver) error
+	CreateFamilyWithCaregiver(ctx context.Context, family *domain.Family, baby *domain.Baby, caregiver *domain.Caregiver) error
 	GetFamilyByID(ctx context.Context, id uuid.UUID) (*domain.Family, error)
 	GetFamilyByName(ctx context.Context, name string) (*domain.Family, error)
 	UpdateFamily(ctx context.Context, family *domain.Family) error
 	DeleteFamily(ctx context.Context, id uuid.UUID) error
 	FamilyNameExists(ctx context.Context, name string) (bool, error)
 	GetFamiliesByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Family, error)
+	GetActiveFamilyIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+
+	// Invite operations
+	CreateFamilyInvite(ctx context.Context, invite *domain.FamilyInvite) error
+	GetFamilyInviteByID(ctx context.Context, id uuid.UUID) (*domain.FamilyInvite, error)
+	GetFamilyInviteByCodeHash(ctx context.Context, codeHash string) (*domain.FamilyInvite, error)
+	GetFamilyInvitesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.FamilyInvite, error)
+	// RedeemFamilyInvite counts one use of the invite if it is active at now, and reports
+	// whether it was
+	RedeemFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+	RevokeFamilyInvite(ctx context.Context, id uuid.UUID, now time.Time) error
+
+	// Baby operations
+	CreateBaby(ctx context.Context, baby *domain.Baby) error
+	GetBabyByID(ctx context.Context, id uuid.UUID) (*domain.Baby, error)
+	GetBabiesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Baby, error)
+	UpdateBaby(ctx context.Context, baby *domain.Baby) error
 
 	// User operations
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserBySupabaseID(ctx context.Context, supabaseUserID string) (*domain.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
 
-	// Caregiver operations
+	// Caregiver operations. A caregiver or invite created without a role gets
+	// domain.RoleCaregiver.
 	CreateCaregiver(ctx context.Context, caregiver *domain.Caregiver) error
 	GetCaregiverByID(ctx context.Context, id uuid.UUID) (*domain.Caregiver, error)
 	GetCaregiverByDeviceID(ctx context.Context, deviceID string) (*domain.Caregiver, error)
 	GetCaregiverByUserAndFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (*domain.Caregiver, error)
 	GetCaregiversByFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Caregiver, error)
+	// GetCaregiversByIDs returns the caregivers among ids that exist, in no particular order
+	GetCaregiversByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Caregiver, error)
 	UpdateCaregiver(ctx context.Context, caregiver *domain.Caregiver) error
 	LinkCaregiverToUser(ctx context.Context, caregiverID uuid.UUID, userID uuid.UUID) error
+	RotateDeviceToken(ctx context.Context, caregiverID uuid.UUID) (int, error)
+	SetCaregiverRole(ctx context.Context, caregiverID uuid.UUID, role domain.CaregiverRole) error
 	DeleteCaregiver(ctx context.Context, id uuid.UUID) error
 
-	// Care Session operations
+	// Care Session operations. Deleting a session moves it and its activities to the trash,
+	// hidden from every other read; RestoreCareSession brings back the activities deleted
+	// with it, but not ones deleted before it.
 	CreateCareSession(ctx context.Context, session *domain.CareSession) error
 	GetCareSessionByID(ctx context.Context, id uuid.UUID) (*domain.CareSession, error)
 	GetInProgressSessionForFamily(ctx context.Context, familyID uuid.UUID) (*domain.CareSession, error)
@@ -42,40 +67,135 @@ type Store interface {
 	GetCareSessionHistoryForFamily(ctx context.Context, familyID uuid.UUID, limit int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.CareSession, error)
 	UpdateCareSession(ctx context.Context, session *domain.CareSession) error
 	DeleteCareSession(ctx context.Context, id uuid.UUID) error
+	RestoreCareSession(ctx context.Context, id uuid.UUID) error
+	GetRecentlyDeletedCareSessionsForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.CareSession, error)
 
-	// Activity operations (details loaded separately via resolvers)
+	// Activity operations (details loaded separately via resolvers). Deleted activities are
+	// kept in the trash, with their details, until restored or purged.
 	CreateActivity(ctx context.Context, activity *domain.Activity) error
 	GetActivityByID(ctx context.Context, id uuid.UUID) (*domain.Activity, error)
 	GetActivitiesForSession(ctx context.Context, sessionID uuid.UUID) ([]*domain.Activity, error)
-	GetLatestActivityByTypeForFamily(ctx context.Context, familyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error)
+	// GetActivitiesForSessions batches GetActivitiesForSession: each session's activities
+	// come back in the same order, with the sessions in no particular order
+	GetActivitiesForSessions(ctx context.Context, sessionIDs []uuid.UUID) ([]*domain.Activity, error)
+	GetLatestActivityByTypeForBaby(ctx context.Context, babyID uuid.UUID, activityType domain.ActivityType) (*domain.Activity, error)
 	DeleteActivity(ctx context.Context, id uuid.UUID) error
+	// RestoreActivity takes an activity out of the trash. Activities deleted with their
+	// session come back with RestoreCareSession instead.
+	RestoreActivity(ctx context.Context, id uuid.UUID) error
+	GetRecentlyDeletedActivitiesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error)
+	// PurgeDeleted permanently removes activities and care sessions deleted before deletedBefore
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) error
+	// TouchActivity bumps an activity's version and updated_at after a change to its details,
+	// returning the new version. When expectedVersion is set the activity must still be at
+	// that version, or a *VersionConflictError is returned.
+	TouchActivity(ctx context.Context, id uuid.UUID, expectedVersion *int) (int, error)
+	GetActivitiesUpdatedSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.Activity, error)
 
-	// Activity detail operations (lazy loaded)
+	// Activity detail operations (lazy loaded). The ByActivityIDs methods batch the single
+	// gets, returning whichever details exist in no particular order.
 	CreateFeedDetails(ctx context.Context, details *domain.FeedDetails) error
 	GetFeedDetails(ctx context.Context, activityID uuid.UUID) (*domain.FeedDetails, error)
-	GetRecentFeedDetailsForFamily(ctx context.Context, familyID uuid.UUID, limit int) ([]*domain.FeedDetails, error)
+	GetFeedDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.FeedDetails, error)
+	GetRecentFeedDetailsForBaby(ctx context.Context, babyID uuid.UUID, limit int) ([]*domain.FeedDetails, error)
 	UpdateFeedDetails(ctx context.Context, details *domain.FeedDetails) error
 
 	CreateDiaperDetails(ctx context.Context, details *domain.DiaperDetails) error
 	GetDiaperDetails(ctx context.Context, activityID uuid.UUID) (*domain.DiaperDetails, error)
+	GetDiaperDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.DiaperDetails, error)
 	UpdateDiaperDetails(ctx context.Context, details *domain.DiaperDetails) error
 
 	CreateSleepDetails(ctx context.Context, details *domain.SleepDetails) error
 	GetSleepDetails(ctx context.Context, activityID uuid.UUID) (*domain.SleepDetails, error)
-	GetRecentSleepDetailsForFamily(ctx context.Context, familyID uuid.UUID, limit int) ([]*domain.SleepDetails, error)
+	GetSleepDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.SleepDetails, error)
+	GetRecentSleepDetailsForBaby(ctx context.Context, babyID uuid.UUID, limit int) ([]*domain.SleepDetails, error)
 	UpdateSleepDetails(ctx context.Context, details *domain.SleepDetails) error
 
+	CreatePumpDetails(ctx context.Context, details *domain.PumpDetails) error
+	GetPumpDetails(ctx context.Context, activityID uuid.UUID) (*domain.PumpDetails, error)
+	GetPumpDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.PumpDetails, error)
+	UpdatePumpDetails(ctx context.Context, details *domain.PumpDetails) error
+
+	CreateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error
+	GetMedicationDetails(ctx context.Context, activityID uuid.UUID) (*domain.MedicationDetails, error)
+	GetMedicationDetailsByActivityIDs(ctx context.Context, activityIDs []uuid.UUID) ([]*domain.MedicationDetails, error)
+	GetRecentMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.MedicationDetails, error)
+	GetLatestMedicationDetailsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.MedicationDetails, error)
+	UpdateMedicationDetails(ctx context.Context, details *domain.MedicationDetails) error
+
+	// Medication catalog operations
+	UpsertMedication(ctx context.Context, medication *domain.Medication) (*domain.Medication, error)
+	GetMedicationByID(ctx context.Context, id uuid.UUID) (*domain.Medication, error)
+	GetMedicationByName(ctx context.Context, familyID uuid.UUID, name string) (*domain.Medication, error)
+	GetMedicationsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Medication, error)
+	DeleteMedication(ctx context.Context, id uuid.UUID) error
+
+	// Growth measurement operations
+	CreateGrowthMeasurement(ctx context.Context, measurement *domain.GrowthMeasurement) error
+	GetGrowthMeasurementsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.GrowthMeasurement, error)
+
 	// Prediction operations
-	UpsertPredictions(ctx context.Context, familyID uuid.UUID, predictions []*domain.Prediction) error
-	GetPredictionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.Prediction, error)
+	UpsertPredictions(ctx context.Context, babyID uuid.UUID, predictions []*domain.Prediction) error
+	GetPredictionsForBaby(ctx context.Context, babyID uuid.UUID) ([]*domain.Prediction, error)
 	DismissPrediction(ctx context.Context, id uuid.UUID) error
-	DeletePredictionsForFamily(ctx context.Context, familyID uuid.UUID) error
+	DeletePredictionsForBaby(ctx context.Context, babyID uuid.UUID) error
 	CleanupOldPredictions(ctx context.Context, olderThan time.Time) error
 
+	// Prediction outcome operations. A prediction has at most one outcome; creating a second
+	// does nothing. Outcomes are returned for events since the given time, oldest first.
+	CreatePredictionOutcome(ctx context.Context, outcome *domain.PredictionOutcome) error
+	GetPredictionOutcomesForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error)
+	GetPredictionOutcomesForBaby(ctx context.Context, babyID uuid.UUID, since time.Time) ([]*domain.PredictionOutcome, error)
+
 	// Schedule Goals operations
-	GetScheduleGoals(ctx context.Context, familyID uuid.UUID) (*domain.ScheduleGoals, error)
-	UpsertScheduleGoals(ctx context.Context, familyID uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error)
+	GetScheduleGoals(ctx context.Context, babyID uuid.UUID) (*domain.ScheduleGoals, error)
+	UpsertScheduleGoals(ctx context.Context, babyID uuid.UUID, goals *domain.ScheduleGoals) (*domain.ScheduleGoals, error)
+
+	// Reminder preference operations
+	GetReminderPreferences(ctx context.Context, caregiverID uuid.UUID) (*domain.ReminderPreferences, error)
+	GetReminderPreferencesForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.ReminderPreferences, error)
+	UpsertReminderPreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error)
+
+	// Webhook operations
+	CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	GetWebhookSubscriptionByID(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error)
+	GetWebhookSubscriptionsForFamily(ctx context.Context, familyID uuid.UUID) ([]*domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error
+	CreateWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetWebhookDeliveriesForFamily(ctx context.Context, familyID uuid.UUID, status *domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+
+	// Audit log operations. Events are append-only; the log is read newest first, and
+	// afterTime/afterID continue from an earlier page's last event.
+	CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error
+	GetAuditEventsForFamily(ctx context.Context, familyID uuid.UUID, filter domain.AuditFilter, limit int, afterTime *time.Time, afterID *uuid.UUID) ([]*domain.AuditEvent, error)
+
+	// Offline sync operations
+	GetIdempotencyRecord(ctx context.Context, familyID uuid.UUID, key string) (*domain.IdempotencyRecord, error)
+	SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error
+	RecordDeletedActivity(ctx context.Context, deleted *domain.DeletedActivity) error
+	GetDeletedActivity(ctx context.Context, activityID uuid.UUID) (*domain.DeletedActivity, error)
+	ClearDeletedActivity(ctx context.Context, activityID uuid.UUID) error
+	GetDeletedActivitiesSinceForFamily(ctx context.Context, familyID uuid.UUID, since time.Time) ([]*domain.DeletedActivity, error)
+
+	// Transactions
+	// WithTx runs fn against a Store whose operations share one transaction. The
+	// transaction commits if fn returns nil and rolls back otherwise.
+	WithTx(ctx context.Context, fn func(tx Store) error) error
 
 	// Lifecycle
 	Close() error
-}
\ No newline at end of file
+}
+
+// VersionConflictError is returned by a conditional update when the record was changed
+// after the caller read it
+type VersionConflictError struct {
+	ID uuid.UUID
+	// Version is the record's current version
+	Version int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s was changed by someone else and is now at version %d", e.ID, e.Version)
+}
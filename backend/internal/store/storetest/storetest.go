@@ -0,0 +1,2462 @@
+// Package storetest is a conformance suite for store.Store implementations. Every
+// implementation runs the same suite so that code written against one behaves the
+// same against the others.
+package storetest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// Run exercises s against the behaviour every Store must share: query ordering,
+// constraints, cascading deletes, cursor pagination, prediction upsert and cleanup,
+// and transactions. Each test creates its own uniquely named families and deletes
+// them when done, so Run is safe against a shared database.
+func Run(t *testing.T, s store.Store) {
+	su := &suite{
+		s:   s,
+		ctx: context.Background(),
+		// Fixtures are timed relative to a point well in the past so that anything the
+		// store stamps with the current time always sorts after them
+		base: time.Now().UTC().Truncate(time.Second).Add(-48 * time.Hour),
+	}
+
+	t.Run("Families", su.testFamilies)
+	t.Run("UsersAndCaregivers", su.testUsersAndCaregivers)
+	t.Run("Babies", su.testBabies)
+	t.Run("CareSessions", su.testCareSessions)
+	t.Run("CareSessionHistory", su.testCareSessionHistory)
+	t.Run("Activities", su.testActivities)
+	t.Run("ActivityDetails", su.testActivityDetails)
+	t.Run("MedicationDetails", su.testMedicationDetails)
+	t.Run("Medications", su.testMedications)
+	t.Run("GrowthMeasurements", su.testGrowthMeasurements)
+	t.Run("Predictions", su.testPredictions)
+	t.Run("PredictionOutcomes", su.testPredictionOutcomes)
+	t.Run("ScheduleGoals", su.testScheduleGoals)
+	t.Run("ReminderPreferences", su.testReminderPreferences)
+	t.Run("ActiveFamilies", su.testActiveFamilies)
+	t.Run("Webhooks", su.testWebhooks)
+	t.Run("Invites", su.testInvites)
+	t.Run("AuditLog", su.testAuditLog)
+	t.Run("OfflineSync", su.testOfflineSync)
+	t.Run("DeleteFamilyCascades", su.testDeleteFamilyCascades)
+	t.Run("DeleteCaregiverCascades", su.testDeleteCaregiverCascades)
+	t.Run("Trash", su.testTrash)
+	t.Run("Transactions", su.testTransactions)
+}
+
+type suite struct {
+	s    store.Store
+	ctx  context.Context
+	base time.Time
+}
+
+// at returns a time offset from the suite's base
+func (su *suite) at(offset time.Duration) time.Time {
+	return su.base.Add(offset)
+}
+
+type fixture struct {
+	family    *domain.Family
+	baby      *domain.Baby
+	caregiver *domain.Caregiver
+}
+
+func (su *suite) newFamily(t *testing.T) fixture {
+	t.Helper()
+	return su.newFamilyCreatedAt(t, su.base)
+}
+
+// newFamilyCreatedAt creates a uniquely named family with one baby and one caregiver,
+// and deletes it when the test finishes
+func (su *suite) newFamilyCreatedAt(t *testing.T, createdAt time.Time) fixture {
+	t.Helper()
+
+	family := &domain.Family{
+		ID:           uuid.New(),
+		Name:         "Conformance Family " + uuid.New().String()[:8],
+		PasswordHash: "hash",
+		BabyName:     "Baby",
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+	baby := &domain.Baby{
+		ID:        uuid.New(),
+		FamilyID:  family.ID,
+		Name:      family.BabyName,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	caregiver := su.caregiver(family.ID, createdAt)
+
+	if err := su.s.CreateFamilyWithCaregiver(su.ctx, family, baby, caregiver); err != nil {
+		t.Fatalf("Failed to create family: %v", err)
+	}
+	t.Cleanup(func() {
+		su.s.DeleteFamily(su.ctx, family.ID)
+	})
+
+	return fixture{family: family, baby: baby, caregiver: caregiver}
+}
+
+func (su *suite) caregiver(familyID uuid.UUID, createdAt time.Time) *domain.Caregiver {
+	deviceID := "conformance-device-" + uuid.New().String()
+	deviceName := "Test Phone"
+	return &domain.Caregiver{
+		ID:         uuid.New(),
+		FamilyID:   familyID,
+		Name:       "Caregiver",
+		DeviceID:   &deviceID,
+		DeviceName: &deviceName,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+func (su *suite) newBaby(t *testing.T, familyID uuid.UUID, name string, createdAt time.Time) *domain.Baby {
+	t.Helper()
+
+	baby := &domain.Baby{ID: uuid.New(), FamilyID: familyID, Name: name, CreatedAt: createdAt, UpdatedAt: createdAt}
+	if err := su.s.CreateBaby(su.ctx, baby); err != nil {
+		t.Fatalf("Failed to create baby: %v", err)
+	}
+	return baby
+}
+
+func (su *suite) newSession(t *testing.T, f fixture, status domain.SessionStatus, startedAt time.Time) *domain.CareSession {
+	t.Helper()
+
+	session := &domain.CareSession{
+		ID:          uuid.New(),
+		CaregiverID: f.caregiver.ID,
+		FamilyID:    f.family.ID,
+		Status:      status,
+		StartedAt:   startedAt,
+		CreatedAt:   startedAt,
+		UpdatedAt:   startedAt,
+	}
+	if status == domain.StatusCompleted {
+		completedAt := startedAt.Add(time.Hour)
+		session.CompletedAt = &completedAt
+	}
+	if err := su.s.CreateCareSession(su.ctx, session); err != nil {
+		t.Fatalf("Failed to create care session: %v", err)
+	}
+	return session
+}
+
+func (su *suite) newActivity(t *testing.T, sessionID, babyID uuid.UUID, activityType domain.ActivityType, createdAt time.Time) *domain.Activity {
+	t.Helper()
+
+	activity := &domain.Activity{
+		ID:            uuid.New(),
+		CareSessionID: sessionID,
+		BabyID:        babyID,
+		ActivityType:  activityType,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+	if err := su.s.CreateActivity(su.ctx, activity); err != nil {
+		t.Fatalf("Failed to create activity: %v", err)
+	}
+	return activity
+}
+
+func (su *suite) newFeed(t *testing.T, sessionID, babyID uuid.UUID, startTime time.Time) (*domain.Activity, *domain.FeedDetails) {
+	t.Helper()
+
+	activity := su.newActivity(t, sessionID, babyID, domain.ActivityTypeFeed, startTime)
+	amount := 120
+	feedType := domain.FeedTypeFormula
+	details := &domain.FeedDetails{
+		ID:         uuid.New(),
+		ActivityID: activity.ID,
+		StartTime:  startTime,
+		AmountMl:   &amount,
+		FeedType:   &feedType,
+		CreatedAt:  startTime,
+		UpdatedAt:  startTime,
+	}
+	if err := su.s.CreateFeedDetails(su.ctx, details); err != nil {
+		t.Fatalf("Failed to create feed details: %v", err)
+	}
+	return activity, details
+}
+
+func (su *suite) newSleep(t *testing.T, sessionID, babyID uuid.UUID, startTime time.Time) (*domain.Activity, *domain.SleepDetails) {
+	t.Helper()
+
+	activity := su.newActivity(t, sessionID, babyID, domain.ActivityTypeSleep, startTime)
+	details := &domain.SleepDetails{
+		ID:         uuid.New(),
+		ActivityID: activity.ID,
+		StartTime:  startTime,
+		CreatedAt:  startTime,
+		UpdatedAt:  startTime,
+	}
+	if err := su.s.CreateSleepDetails(su.ctx, details); err != nil {
+		t.Fatalf("Failed to create sleep details: %v", err)
+	}
+	return activity, details
+}
+
+func (su *suite) newDose(t *testing.T, sessionID, babyID uuid.UUID, drugName string, medicationID *uuid.UUID, givenAt time.Time) *domain.MedicationDetails {
+	t.Helper()
+
+	activity := su.newActivity(t, sessionID, babyID, domain.ActivityTypeMedication, givenAt)
+	details := &domain.MedicationDetails{
+		ID:           uuid.New(),
+		ActivityID:   activity.ID,
+		MedicationID: medicationID,
+		DrugName:     drugName,
+		DoseAmount:   2.5,
+		DoseUnit:     domain.DoseUnitMl,
+		Route:        domain.MedicationRouteOral,
+		GivenAt:      givenAt,
+		CreatedAt:    givenAt,
+		UpdatedAt:    givenAt,
+	}
+	if err := su.s.CreateMedicationDetails(su.ctx, details); err != nil {
+		t.Fatalf("Failed to create medication details: %v", err)
+	}
+	return details
+}
+
+func (su *suite) newPrediction(f fixture, babyID uuid.UUID, predictedTime time.Time) *domain.Prediction {
+	return &domain.Prediction{
+		ID:             uuid.New(),
+		FamilyID:       f.family.ID,
+		BabyID:         babyID,
+		ActivityType:   domain.ActivityTypeFeed,
+		PredictionType: domain.PredictionTypeNextFeed,
+		PredictedTime:  predictedTime,
+		Status:         domain.PredictionStatusUpcoming,
+		ComputedAt:     time.Now().UTC(),
+	}
+}
+
+// ids extracts IDs so orderings can be compared in one assertion
+func ids[T any](rows []*T, id func(*T) uuid.UUID) []uuid.UUID {
+	result := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		result[i] = id(row)
+	}
+	return result
+}
+
+func activityIDOf(a *domain.Activity) uuid.UUID         { return a.ID }
+func sessionIDOf(cs *domain.CareSession) uuid.UUID      { return cs.ID }
+func predictionIDOf(p *domain.Prediction) uuid.UUID     { return p.ID }
+func doseIDOf(d *domain.MedicationDetails) uuid.UUID    { return d.ID }
+func tombstoneIDOf(d *domain.DeletedActivity) uuid.UUID { return d.ActivityID }
+
+func expectIDs(t *testing.T, what string, got, want []uuid.UUID) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+// expectIDSet is expectIDs for results in no particular order
+func expectIDSet(t *testing.T, what string, got, want []uuid.UUID) {
+	t.Helper()
+	byBytes := func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) }
+	got, want = slices.Clone(got), slices.Clone(want)
+	slices.SortFunc(got, byBytes)
+	slices.SortFunc(want, byBytes)
+	expectIDs(t, what, got, want)
+}
+
+func (su *suite) testFamilies(t *testing.T) {
+	f := su.newFamily(t)
+
+	t.Run("GetFamilyByNameIgnoresCase", func(t *testing.T) {
+		family, err := su.s.GetFamilyByName(su.ctx, strings.ToUpper(f.family.Name))
+		if err != nil {
+			t.Fatalf("Failed to get family by name: %v", err)
+		}
+		if family.ID != f.family.ID {
+			t.Errorf("Expected family %s, got %s", f.family.ID, family.ID)
+		}
+
+		exists, err := su.s.FamilyNameExists(su.ctx, strings.ToLower(f.family.Name))
+		if err != nil || !exists {
+			t.Errorf("Expected family name to exist, got %v (err %v)", exists, err)
+		}
+		exists, err = su.s.FamilyNameExists(su.ctx, "No Such Family "+uuid.New().String())
+		if err != nil || exists {
+			t.Errorf("Expected family name not to exist, got %v (err %v)", exists, err)
+		}
+	})
+
+	t.Run("MissingFamilyIsAnError", func(t *testing.T) {
+		if _, err := su.s.GetFamilyByID(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error getting missing family")
+		}
+		if _, err := su.s.GetFamilyByName(su.ctx, "No Such Family "+uuid.New().String()); err == nil {
+			t.Error("Expected error getting missing family by name")
+		}
+		if err := su.s.UpdateFamily(su.ctx, &domain.Family{ID: uuid.New(), Name: "Missing " + uuid.New().String()}); err == nil {
+			t.Error("Expected error updating missing family")
+		}
+		if err := su.s.DeleteFamily(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error deleting missing family")
+		}
+	})
+
+	t.Run("NamesAreUniqueIgnoringCase", func(t *testing.T) {
+		dupe := &domain.Family{ID: uuid.New(), Name: strings.ToUpper(f.family.Name), CreatedAt: su.base, UpdatedAt: su.base}
+		baby := &domain.Baby{ID: uuid.New(), FamilyID: dupe.ID, Name: "Baby", CreatedAt: su.base, UpdatedAt: su.base}
+		err := su.s.CreateFamilyWithCaregiver(su.ctx, dupe, baby, su.caregiver(dupe.ID, su.base))
+		if err == nil {
+			su.s.DeleteFamily(su.ctx, dupe.ID)
+			t.Fatal("Expected error creating family with a taken name")
+		}
+	})
+
+	t.Run("CreateFamilyWithCaregiverIsAtomic", func(t *testing.T) {
+		family := &domain.Family{ID: uuid.New(), Name: "Conformance Family " + uuid.New().String()[:8], CreatedAt: su.base, UpdatedAt: su.base}
+		baby := &domain.Baby{ID: uuid.New(), FamilyID: family.ID, Name: "Baby", CreatedAt: su.base, UpdatedAt: su.base}
+		caregiver := su.caregiver(family.ID, su.base)
+		caregiver.DeviceID = f.caregiver.DeviceID // already in use
+
+		if err := su.s.CreateFamilyWithCaregiver(su.ctx, family, baby, caregiver); err == nil {
+			su.s.DeleteFamily(su.ctx, family.ID)
+			t.Fatal("Expected error creating caregiver with a device ID in use")
+		}
+		if exists, _ := su.s.FamilyNameExists(su.ctx, family.Name); exists {
+			t.Error("Expected family insert to be rolled back")
+		}
+		if _, err := su.s.GetBabyByID(su.ctx, baby.ID); err == nil {
+			t.Error("Expected baby insert to be rolled back")
+		}
+	})
+
+	t.Run("UpdateFamily", func(t *testing.T) {
+		updated := *f.family
+		updated.Name = "Renamed Family " + uuid.New().String()[:8]
+		updated.BabyName = "Renamed Baby"
+		if err := su.s.UpdateFamily(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update family: %v", err)
+		}
+
+		family, err := su.s.GetFamilyByID(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get family: %v", err)
+		}
+		if family.Name != updated.Name || family.BabyName != "Renamed Baby" {
+			t.Errorf("Expected updated name and baby name, got %q and %q", family.Name, family.BabyName)
+		}
+		if !family.UpdatedAt.After(su.base) {
+			t.Errorf("Expected updated_at to be bumped past %v, got %v", su.base, family.UpdatedAt)
+		}
+	})
+
+	t.Run("Timezone", func(t *testing.T) {
+		family, err := su.s.GetFamilyByID(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get family: %v", err)
+		}
+		if family.Timezone != "" || family.Travel != nil {
+			t.Fatalf("Expected no timezone on a new family, got %q and %+v", family.Timezone, family.Travel)
+		}
+
+		start := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+		family.Timezone = "America/Toronto"
+		family.Travel = &domain.TravelMode{Timezone: "Asia/Kolkata", StartDate: start, EndDate: start.AddDate(0, 0, 13)}
+		if err := su.s.UpdateFamily(su.ctx, family); err != nil {
+			t.Fatalf("Failed to update family: %v", err)
+		}
+
+		got, err := su.s.GetFamilyByID(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get family: %v", err)
+		}
+		if got.Timezone != "America/Toronto" {
+			t.Errorf("Expected timezone America/Toronto, got %q", got.Timezone)
+		}
+		if got.Travel == nil || got.Travel.Timezone != "Asia/Kolkata" ||
+			got.Travel.StartDate.Format(time.DateOnly) != "2026-07-01" || got.Travel.EndDate.Format(time.DateOnly) != "2026-07-14" {
+			t.Errorf("Expected travel to Asia/Kolkata from 2026-07-01 to 2026-07-14, got %+v", got.Travel)
+		}
+
+		got.Travel = nil
+		if err := su.s.UpdateFamily(su.ctx, got); err != nil {
+			t.Fatalf("Failed to update family: %v", err)
+		}
+		got, err = su.s.GetFamilyByID(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get family: %v", err)
+		}
+		if got.Travel != nil || got.Timezone != "America/Toronto" {
+			t.Errorf("Expected travel mode cleared and home timezone kept, got %q and %+v", got.Timezone, got.Travel)
+		}
+	})
+
+	t.Run("PredictionModel", func(t *testing.T) {
+		family, err := su.s.GetFamilyByID(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get family: %v", err)
+		}
+		if family.PredictionModel != "" || family.PredictionHalfLife != 0 {
+			t.Fatalf("Expected the default prediction model on a new family, got %q and %v", family.PredictionModel, family.PredictionHalfLife)
+		}
+
+		family.PredictionModel = domain.PredictionModelRecencyWeighted
+		family.PredictionHalfLife = 36 * time.Hour
+		if err := su.s.UpdateFamily(su.ctx, family); err != nil {
+			t.Fatalf("Failed to update family: %v", err)
+		}
+
+		got, err := su.s.GetFamilyByID(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get family: %v", err)
+		}
+		if got.PredictionModel != domain.PredictionModelRecencyWeighted || got.PredictionHalfLife != 36*time.Hour {
+			t.Errorf("Expected recency weighting with a 36h half-life, got %q and %v", got.PredictionModel, got.PredictionHalfLife)
+		}
+	})
+}
+
+func (su *suite) testUsersAndCaregivers(t *testing.T) {
+	later := su.newFamilyCreatedAt(t, su.at(time.Hour))
+	earlier := su.newFamilyCreatedAt(t, su.at(-time.Hour))
+
+	user := &domain.User{
+		ID:             uuid.New(),
+		SupabaseUserID: "conformance-" + uuid.New().String(),
+		Email:          "conformance@example.com",
+		CreatedAt:      su.base,
+		UpdatedAt:      su.base,
+	}
+
+	t.Run("Users", func(t *testing.T) {
+		if err := su.s.CreateUser(su.ctx, user); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+
+		bySupabase, err := su.s.GetUserBySupabaseID(su.ctx, user.SupabaseUserID)
+		if err != nil || bySupabase.ID != user.ID {
+			t.Errorf("Expected user %s by supabase ID, got %v (err %v)", user.ID, bySupabase, err)
+		}
+		byID, err := su.s.GetUserByID(su.ctx, user.ID)
+		if err != nil || byID.SupabaseUserID != user.SupabaseUserID {
+			t.Errorf("Expected user by ID, got %v (err %v)", byID, err)
+		}
+
+		if _, err := su.s.GetUserByID(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error getting missing user")
+		}
+		if _, err := su.s.GetUserBySupabaseID(su.ctx, "missing-"+uuid.New().String()); err == nil {
+			t.Error("Expected error getting missing user by supabase ID")
+		}
+
+		dupe := *user
+		dupe.ID = uuid.New()
+		if err := su.s.CreateUser(su.ctx, &dupe); err == nil {
+			t.Error("Expected error creating user with a supabase ID in use")
+		}
+	})
+
+	t.Run("LinkCaregiverToUser", func(t *testing.T) {
+		for _, f := range []fixture{later, earlier} {
+			if err := su.s.LinkCaregiverToUser(su.ctx, f.caregiver.ID, user.ID); err != nil {
+				t.Fatalf("Failed to link caregiver: %v", err)
+			}
+		}
+
+		caregiver, err := su.s.GetCaregiverByUserAndFamily(su.ctx, user.ID, later.family.ID)
+		if err != nil || caregiver.ID != later.caregiver.ID {
+			t.Errorf("Expected caregiver %s, got %v (err %v)", later.caregiver.ID, caregiver, err)
+		}
+		if _, err := su.s.GetCaregiverByUserAndFamily(su.ctx, uuid.New(), later.family.ID); err == nil {
+			t.Error("Expected error getting caregiver for unknown user")
+		}
+
+		families, err := su.s.GetFamiliesByUserID(su.ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Failed to get families by user: %v", err)
+		}
+		got := ids(families, func(f *domain.Family) uuid.UUID { return f.ID })
+		expectIDs(t, "families oldest first", got, []uuid.UUID{earlier.family.ID, later.family.ID})
+
+		if err := su.s.LinkCaregiverToUser(su.ctx, uuid.New(), user.ID); err == nil {
+			t.Error("Expected error linking missing caregiver")
+		}
+	})
+
+	t.Run("CaregiversOldestFirst", func(t *testing.T) {
+		newest := su.caregiver(later.family.ID, su.at(2*time.Hour))
+		oldest := su.caregiver(later.family.ID, su.at(0))
+		for _, c := range []*domain.Caregiver{newest, oldest} {
+			if err := su.s.CreateCaregiver(su.ctx, c); err != nil {
+				t.Fatalf("Failed to create caregiver: %v", err)
+			}
+		}
+
+		caregivers, err := su.s.GetCaregiversByFamily(su.ctx, later.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get caregivers: %v", err)
+		}
+		got := ids(caregivers, func(c *domain.Caregiver) uuid.UUID { return c.ID })
+		expectIDs(t, "caregivers oldest first", got, []uuid.UUID{oldest.ID, later.caregiver.ID, newest.ID})
+	})
+
+	t.Run("DeviceIDsAreUnique", func(t *testing.T) {
+		caregiver, err := su.s.GetCaregiverByDeviceID(su.ctx, *earlier.caregiver.DeviceID)
+		if err != nil || caregiver.ID != earlier.caregiver.ID {
+			t.Errorf("Expected caregiver %s by device, got %v (err %v)", earlier.caregiver.ID, caregiver, err)
+		}
+		if _, err := su.s.GetCaregiverByDeviceID(su.ctx, "missing-"+uuid.New().String()); err == nil {
+			t.Error("Expected error getting caregiver for unknown device")
+		}
+
+		dupe := su.caregiver(later.family.ID, su.base)
+		dupe.DeviceID = earlier.caregiver.DeviceID
+		if err := su.s.CreateCaregiver(su.ctx, dupe); err == nil {
+			t.Error("Expected error creating caregiver with a device ID in use")
+		}
+	})
+
+	t.Run("UpdateCaregiver", func(t *testing.T) {
+		updated := *earlier.caregiver
+		updated.Name = "Renamed"
+		deviceName := "New Phone"
+		updated.DeviceName = &deviceName
+		if err := su.s.UpdateCaregiver(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update caregiver: %v", err)
+		}
+
+		caregiver, err := su.s.GetCaregiverByID(su.ctx, earlier.caregiver.ID)
+		if err != nil {
+			t.Fatalf("Failed to get caregiver: %v", err)
+		}
+		if caregiver.Name != "Renamed" || caregiver.DeviceName == nil || *caregiver.DeviceName != "New Phone" {
+			t.Errorf("Expected renamed caregiver on new phone, got %+v", caregiver)
+		}
+		if caregiver.UserID == nil || *caregiver.UserID != user.ID {
+			t.Error("Expected update to keep the user link")
+		}
+		if !caregiver.UpdatedAt.After(su.base) {
+			t.Errorf("Expected updated_at to be bumped, got %v", caregiver.UpdatedAt)
+		}
+
+		missing := su.caregiver(earlier.family.ID, su.base)
+		if err := su.s.UpdateCaregiver(su.ctx, missing); err == nil {
+			t.Error("Expected error updating missing caregiver")
+		}
+		if err := su.s.DeleteCaregiver(su.ctx, missing.ID); err == nil {
+			t.Error("Expected error deleting missing caregiver")
+		}
+		if _, err := su.s.GetCaregiverByID(su.ctx, missing.ID); err == nil {
+			t.Error("Expected error getting missing caregiver")
+		}
+	})
+
+	t.Run("RotateDeviceToken", func(t *testing.T) {
+		f := su.newFamily(t)
+		if f.caregiver.TokenGeneration != 0 {
+			t.Fatalf("Expected new caregivers to start at generation 0, got %d", f.caregiver.TokenGeneration)
+		}
+
+		for want := 1; want <= 2; want++ {
+			generation, err := su.s.RotateDeviceToken(su.ctx, f.caregiver.ID)
+			if err != nil {
+				t.Fatalf("Failed to rotate device token: %v", err)
+			}
+			if generation != want {
+				t.Errorf("Expected generation %d, got %d", want, generation)
+			}
+		}
+
+		caregiver, err := su.s.GetCaregiverByID(su.ctx, f.caregiver.ID)
+		if err != nil {
+			t.Fatalf("Failed to get caregiver: %v", err)
+		}
+		if caregiver.TokenGeneration != 2 {
+			t.Errorf("Expected stored generation 2, got %d", caregiver.TokenGeneration)
+		}
+		if _, err := su.s.RotateDeviceToken(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error rotating a missing caregiver's token")
+		}
+	})
+
+	t.Run("SetCaregiverRole", func(t *testing.T) {
+		f := su.newFamily(t)
+		if f.caregiver.Role != domain.RoleCaregiver {
+			t.Fatalf("Expected caregivers created without a role to be %q, got %q", domain.RoleCaregiver, f.caregiver.Role)
+		}
+
+		if err := su.s.SetCaregiverRole(su.ctx, f.caregiver.ID, domain.RoleViewer); err != nil {
+			t.Fatalf("Failed to set role: %v", err)
+		}
+		caregiver, err := su.s.GetCaregiverByID(su.ctx, f.caregiver.ID)
+		if err != nil {
+			t.Fatalf("Failed to get caregiver: %v", err)
+		}
+		if caregiver.Role != domain.RoleViewer {
+			t.Errorf("Expected role %q, got %q", domain.RoleViewer, caregiver.Role)
+		}
+
+		if err := su.s.SetCaregiverRole(su.ctx, f.caregiver.ID, domain.CaregiverRole("admin")); err == nil {
+			t.Error("Expected error setting an unknown role")
+		}
+		if err := su.s.SetCaregiverRole(su.ctx, uuid.New(), domain.RoleOwner); err == nil {
+			t.Error("Expected error setting a missing caregiver's role")
+		}
+	})
+}
+
+func (su *suite) testBabies(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+
+	t.Run("BabiesOldestFirst", func(t *testing.T) {
+		second := su.newBaby(t, f.family.ID, "Second", su.at(time.Hour))
+
+		babies, err := su.s.GetBabiesForFamily(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get babies: %v", err)
+		}
+		got := ids(babies, func(b *domain.Baby) uuid.UUID { return b.ID })
+		expectIDs(t, "babies oldest first", got, []uuid.UUID{f.baby.ID, second.ID})
+	})
+
+	t.Run("NamesAreUniqueWithinFamily", func(t *testing.T) {
+		dupe := &domain.Baby{ID: uuid.New(), FamilyID: f.family.ID, Name: strings.ToUpper(f.baby.Name), CreatedAt: su.base, UpdatedAt: su.base}
+		if err := su.s.CreateBaby(su.ctx, dupe); err == nil {
+			t.Error("Expected error creating baby with a name taken in the family")
+		}
+
+		su.newBaby(t, other.family.ID, "Second", su.base)
+	})
+
+	t.Run("CreateBabyRequiresFamily", func(t *testing.T) {
+		orphan := &domain.Baby{ID: uuid.New(), FamilyID: uuid.New(), Name: "Orphan", CreatedAt: su.base, UpdatedAt: su.base}
+		if err := su.s.CreateBaby(su.ctx, orphan); err == nil {
+			t.Error("Expected error creating baby in a missing family")
+		}
+	})
+
+	t.Run("UpdateBaby", func(t *testing.T) {
+		birthDate := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+		sex := domain.BabySexFemale
+		updated := *f.baby
+		updated.Name = "Renamed"
+		updated.BirthDate = &birthDate
+		updated.Sex = &sex
+		if err := su.s.UpdateBaby(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update baby: %v", err)
+		}
+
+		baby, err := su.s.GetBabyByID(su.ctx, f.baby.ID)
+		if err != nil {
+			t.Fatalf("Failed to get baby: %v", err)
+		}
+		if baby.Name != "Renamed" || baby.BirthDate == nil || !baby.BirthDate.Equal(birthDate) || baby.Sex == nil || *baby.Sex != sex {
+			t.Errorf("Expected updated profile, got %+v", baby)
+		}
+		if !baby.UpdatedAt.After(su.base) {
+			t.Errorf("Expected updated_at to be bumped, got %v", baby.UpdatedAt)
+		}
+
+		if err := su.s.UpdateBaby(su.ctx, &domain.Baby{ID: uuid.New(), Name: "Missing"}); err == nil {
+			t.Error("Expected error updating missing baby")
+		}
+		if _, err := su.s.GetBabyByID(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error getting missing baby")
+		}
+	})
+}
+
+func (su *suite) testCareSessions(t *testing.T) {
+	f := su.newFamily(t)
+	idle := su.newFamily(t)
+
+	first := su.newSession(t, f, domain.StatusCompleted, su.at(time.Hour))
+	second := su.newSession(t, f, domain.StatusCompleted, su.at(2*time.Hour))
+	third := su.newSession(t, f, domain.StatusCompleted, su.at(3*time.Hour))
+	current := su.newSession(t, f, domain.StatusInProgress, su.at(4*time.Hour))
+
+	t.Run("RecentSessionsAreCompletedNewestFirst", func(t *testing.T) {
+		sessions, err := su.s.GetRecentCareSessionsForFamily(su.ctx, f.family.ID, 2)
+		if err != nil {
+			t.Fatalf("Failed to get recent sessions: %v", err)
+		}
+		expectIDs(t, "recent sessions", ids(sessions, sessionIDOf), []uuid.UUID{third.ID, second.ID})
+	})
+
+	t.Run("InProgressSession", func(t *testing.T) {
+		session, err := su.s.GetInProgressSessionForFamily(su.ctx, f.family.ID)
+		if err != nil || session == nil || session.ID != current.ID {
+			t.Errorf("Expected in-progress session %s, got %v (err %v)", current.ID, session, err)
+		}
+
+		session, err = su.s.GetInProgressSessionForFamily(su.ctx, idle.family.ID)
+		if err != nil || session != nil {
+			t.Errorf("Expected (nil, nil) for a family with no session, got %v (err %v)", session, err)
+		}
+	})
+
+	t.Run("UpdateCareSession", func(t *testing.T) {
+		completedAt := su.at(5 * time.Hour)
+		notes := "handed off"
+		updated := *current
+		updated.Status = domain.StatusCompleted
+		updated.CompletedAt = &completedAt
+		updated.Notes = &notes
+		if err := su.s.UpdateCareSession(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update session: %v", err)
+		}
+
+		session, err := su.s.GetCareSessionByID(su.ctx, current.ID)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if session.Status != domain.StatusCompleted || session.CompletedAt == nil || !session.CompletedAt.Equal(completedAt) || session.Notes == nil {
+			t.Errorf("Expected completed session with notes, got %+v", session)
+		}
+		if !session.UpdatedAt.After(su.at(4 * time.Hour)) {
+			t.Errorf("Expected updated_at to be bumped, got %v", session.UpdatedAt)
+		}
+
+		sessions, err := su.s.GetRecentCareSessionsForFamily(su.ctx, f.family.ID, 10)
+		if err != nil {
+			t.Fatalf("Failed to get recent sessions: %v", err)
+		}
+		expectIDs(t, "recent sessions", ids(sessions, sessionIDOf), []uuid.UUID{current.ID, third.ID, second.ID, first.ID})
+	})
+
+	t.Run("MissingSessionIsAnError", func(t *testing.T) {
+		if _, err := su.s.GetCareSessionByID(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error getting missing session")
+		}
+		if err := su.s.UpdateCareSession(su.ctx, &domain.CareSession{ID: uuid.New(), Status: domain.StatusCompleted}); err == nil {
+			t.Error("Expected error updating missing session")
+		}
+		if err := su.s.DeleteCareSession(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error deleting missing session")
+		}
+	})
+}
+
+func (su *suite) testCareSessionHistory(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+	su.newSession(t, other, domain.StatusCompleted, su.at(time.Hour))
+
+	// Two pairs of sessions share a start time so the id tie-break is exercised
+	var sessions []*domain.CareSession
+	for _, offset := range []time.Duration{time.Hour, 2 * time.Hour, 2 * time.Hour, 3 * time.Hour, 3 * time.Hour} {
+		sessions = append(sessions, su.newSession(t, f, domain.StatusCompleted, su.at(offset)))
+	}
+	sessions = append(sessions, su.newSession(t, f, domain.StatusInProgress, su.at(4*time.Hour)))
+
+	slices.SortFunc(sessions, func(a, b *domain.CareSession) int {
+		if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+	want := ids(sessions, sessionIDOf)
+
+	t.Run("PagesThroughAllStatusesNewestFirst", func(t *testing.T) {
+		var got []uuid.UUID
+		var afterTime *time.Time
+		var afterID *uuid.UUID
+		for page := 0; page < len(want); page++ {
+			results, err := su.s.GetCareSessionHistoryForFamily(su.ctx, f.family.ID, 2, afterTime, afterID)
+			if err != nil {
+				t.Fatalf("Failed to get session history: %v", err)
+			}
+			if len(results) == 0 {
+				break
+			}
+			got = append(got, ids(results, sessionIDOf)...)
+			last := results[len(results)-1]
+			afterTime, afterID = &last.StartedAt, &last.ID
+		}
+		expectIDs(t, "session history", got, want)
+	})
+
+	t.Run("CursorNeedsTimeAndID", func(t *testing.T) {
+		afterTime := su.at(2 * time.Hour)
+		results, err := su.s.GetCareSessionHistoryForFamily(su.ctx, f.family.ID, 3, &afterTime, nil)
+		if err != nil {
+			t.Fatalf("Failed to get session history: %v", err)
+		}
+		expectIDs(t, "history without full cursor", ids(results, sessionIDOf), want[:3])
+	})
+}
+
+func (su *suite) testActivities(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+	session := su.newSession(t, f, domain.StatusInProgress, su.base)
+
+	// Logged out of order; each should sort by its own time
+	feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.at(3*time.Hour))
+	sleep, _ := su.newSleep(t, session.ID, f.baby.ID, su.at(2*time.Hour))
+	diaper := su.newActivity(t, session.ID, f.baby.ID, domain.ActivityTypeDiaper, su.at(5*time.Hour))
+	if err := su.s.CreateDiaperDetails(su.ctx, &domain.DiaperDetails{
+		ID: uuid.New(), ActivityID: diaper.ID, ChangedAt: su.at(time.Hour), HadPee: true, CreatedAt: su.base, UpdatedAt: su.base,
+	}); err != nil {
+		t.Fatalf("Failed to create diaper details: %v", err)
+	}
+	// Session ordering doesn't look at pump times, so this sorts by created_at
+	pump := su.newActivity(t, session.ID, f.baby.ID, domain.ActivityTypePump, su.at(30*time.Minute))
+	if err := su.s.CreatePumpDetails(su.ctx, &domain.PumpDetails{
+		ID: uuid.New(), ActivityID: pump.ID, StartTime: su.at(6 * time.Hour), CreatedAt: su.base, UpdatedAt: su.base,
+	}); err != nil {
+		t.Fatalf("Failed to create pump details: %v", err)
+	}
+
+	t.Run("SessionActivitiesInTimeOrder", func(t *testing.T) {
+		activities, err := su.s.GetActivitiesForSession(su.ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to get activities: %v", err)
+		}
+		expectIDs(t, "session activities", ids(activities, activityIDOf), []uuid.UUID{pump.ID, diaper.ID, sleep.ID, feed.ID})
+	})
+
+	t.Run("BatchLoads", func(t *testing.T) {
+		third := su.newFamily(t)
+		otherSession := su.newSession(t, third, domain.StatusCompleted, su.base)
+		otherFeed, _ := su.newFeed(t, otherSession.ID, third.baby.ID, su.base)
+		missing := uuid.New()
+
+		activities, err := su.s.GetActivitiesForSessions(su.ctx, []uuid.UUID{otherSession.ID, session.ID, missing})
+		if err != nil {
+			t.Fatalf("Failed to get activities: %v", err)
+		}
+		var inSession []*domain.Activity
+		for _, a := range activities {
+			if a.CareSessionID == session.ID {
+				inSession = append(inSession, a)
+			}
+		}
+		expectIDSet(t, "batched activities", ids(activities, activityIDOf), []uuid.UUID{otherFeed.ID, pump.ID, diaper.ID, sleep.ID, feed.ID})
+		expectIDs(t, "batched session activities", ids(inSession, activityIDOf), []uuid.UUID{pump.ID, diaper.ID, sleep.ID, feed.ID})
+
+		activityIDs := []uuid.UUID{feed.ID, sleep.ID, diaper.ID, pump.ID, otherFeed.ID, missing}
+		feeds, err := su.s.GetFeedDetailsByActivityIDs(su.ctx, activityIDs)
+		if err != nil {
+			t.Fatalf("Failed to get feed details: %v", err)
+		}
+		expectIDSet(t, "batched feeds", ids(feeds, func(d *domain.FeedDetails) uuid.UUID { return d.ActivityID }), []uuid.UUID{feed.ID, otherFeed.ID})
+		sleeps, err := su.s.GetSleepDetailsByActivityIDs(su.ctx, activityIDs)
+		if err != nil {
+			t.Fatalf("Failed to get sleep details: %v", err)
+		}
+		expectIDs(t, "batched sleeps", ids(sleeps, func(d *domain.SleepDetails) uuid.UUID { return d.ActivityID }), []uuid.UUID{sleep.ID})
+		diapers, err := su.s.GetDiaperDetailsByActivityIDs(su.ctx, activityIDs)
+		if err != nil || len(diapers) != 1 || !diapers[0].HadPee {
+			t.Errorf("Expected the one diaper change, got %v (err %v)", diapers, err)
+		}
+		pumps, err := su.s.GetPumpDetailsByActivityIDs(su.ctx, activityIDs)
+		if err != nil {
+			t.Fatalf("Failed to get pump details: %v", err)
+		}
+		expectIDs(t, "batched pumps", ids(pumps, func(d *domain.PumpDetails) uuid.UUID { return d.ActivityID }), []uuid.UUID{pump.ID})
+		if doses, err := su.s.GetMedicationDetailsByActivityIDs(su.ctx, activityIDs); err != nil || len(doses) != 0 {
+			t.Errorf("Expected no doses, got %d (err %v)", len(doses), err)
+		}
+
+		caregivers, err := su.s.GetCaregiversByIDs(su.ctx, []uuid.UUID{f.caregiver.ID, third.caregiver.ID, missing})
+		if err != nil {
+			t.Fatalf("Failed to get caregivers: %v", err)
+		}
+		expectIDSet(t, "batched caregivers", ids(caregivers, func(c *domain.Caregiver) uuid.UUID { return c.ID }), []uuid.UUID{f.caregiver.ID, third.caregiver.ID})
+	})
+
+	t.Run("LatestActivityByType", func(t *testing.T) {
+		// Logged last but pumped before the other pump
+		earlierPump := su.newActivity(t, session.ID, f.baby.ID, domain.ActivityTypePump, su.at(7*time.Hour))
+		if err := su.s.CreatePumpDetails(su.ctx, &domain.PumpDetails{
+			ID: uuid.New(), ActivityID: earlierPump.ID, StartTime: su.at(time.Hour), CreatedAt: su.base, UpdatedAt: su.base,
+		}); err != nil {
+			t.Fatalf("Failed to create pump details: %v", err)
+		}
+
+		latest, err := su.s.GetLatestActivityByTypeForBaby(su.ctx, f.baby.ID, domain.ActivityTypeFeed)
+		if err != nil || latest == nil || latest.ID != feed.ID {
+			t.Errorf("Expected latest feed %s, got %v (err %v)", feed.ID, latest, err)
+		}
+
+		latest, err = su.s.GetLatestActivityByTypeForBaby(su.ctx, f.baby.ID, domain.ActivityTypePump)
+		if err != nil || latest == nil || latest.ID != pump.ID {
+			t.Errorf("Expected latest pump %s, got %v (err %v)", pump.ID, latest, err)
+		}
+
+		latest, err = su.s.GetLatestActivityByTypeForBaby(su.ctx, other.baby.ID, domain.ActivityTypeFeed)
+		if err != nil || latest != nil {
+			t.Errorf("Expected (nil, nil) for a baby with no feeds, got %v (err %v)", latest, err)
+		}
+	})
+
+	t.Run("UpdatedSince", func(t *testing.T) {
+		otherSession := su.newSession(t, other, domain.StatusInProgress, su.base)
+		otherFeed, _ := su.newFeed(t, otherSession.ID, other.baby.ID, su.base)
+		since := time.Now().UTC().Add(-time.Hour)
+
+		if _, err := su.s.TouchActivity(su.ctx, sleep.ID, nil); err != nil {
+			t.Fatalf("Failed to touch activity: %v", err)
+		}
+		if _, err := su.s.TouchActivity(su.ctx, diaper.ID, nil); err != nil {
+			t.Fatalf("Failed to touch activity: %v", err)
+		}
+		if _, err := su.s.TouchActivity(su.ctx, otherFeed.ID, nil); err != nil {
+			t.Fatalf("Failed to touch activity: %v", err)
+		}
+
+		activities, err := su.s.GetActivitiesUpdatedSinceForFamily(su.ctx, f.family.ID, since)
+		if err != nil {
+			t.Fatalf("Failed to get updated activities: %v", err)
+		}
+		expectIDs(t, "updated activities", ids(activities, activityIDOf), []uuid.UUID{sleep.ID, diaper.ID})
+
+		if _, err := su.s.TouchActivity(su.ctx, uuid.New(), nil); err == nil {
+			t.Error("Expected error touching missing activity")
+		}
+	})
+
+	t.Run("ConditionalTouch", func(t *testing.T) {
+		fresh := su.newActivity(t, session.ID, f.baby.ID, domain.ActivityTypeDiaper, su.base)
+		if fresh.Version != 1 {
+			t.Fatalf("Expected a new activity at version 1, got %d", fresh.Version)
+		}
+
+		stale := 1
+		version, err := su.s.TouchActivity(su.ctx, fresh.ID, &stale)
+		if err != nil || version != 2 {
+			t.Fatalf("Expected touch at the current version to return 2, got %d (err %v)", version, err)
+		}
+
+		_, err = su.s.TouchActivity(su.ctx, fresh.ID, &stale)
+		var conflict *store.VersionConflictError
+		if !errors.As(err, &conflict) || conflict.ID != fresh.ID || conflict.Version != 2 {
+			t.Fatalf("Expected a version conflict at version 2, got %v", err)
+		}
+
+		got, err := su.s.GetActivityByID(su.ctx, fresh.ID)
+		if err != nil || got.Version != 2 {
+			t.Errorf("Expected the conflicting touch to leave version 2, got %v (err %v)", got, err)
+		}
+
+		if _, err := su.s.TouchActivity(su.ctx, uuid.New(), &stale); err == nil || errors.As(err, &conflict) {
+			t.Errorf("Expected a not found error touching a missing activity, got %v", err)
+		}
+	})
+
+	t.Run("DeleteActivityKeepsDetailsForRestore", func(t *testing.T) {
+		if err := su.s.DeleteActivity(su.ctx, feed.ID); err != nil {
+			t.Fatalf("Failed to delete activity: %v", err)
+		}
+		if _, err := su.s.GetActivityByID(su.ctx, feed.ID); err == nil {
+			t.Error("Expected activity to be deleted")
+		}
+		if _, err := su.s.GetFeedDetails(su.ctx, feed.ID); err != nil {
+			t.Errorf("Expected feed details to be kept: %v", err)
+		}
+		if err := su.s.DeleteActivity(su.ctx, feed.ID); err == nil {
+			t.Error("Expected error deleting an activity that's already deleted")
+		}
+	})
+}
+
+func (su *suite) testActivityDetails(t *testing.T) {
+	f := su.newFamily(t)
+	session := su.newSession(t, f, domain.StatusInProgress, su.base)
+	twin := su.newBaby(t, f.family.ID, "Twin", su.base)
+	su.newFeed(t, session.ID, twin.ID, su.at(10*time.Hour))
+	su.newSleep(t, session.ID, twin.ID, su.at(10*time.Hour))
+
+	_, feed1 := su.newFeed(t, session.ID, f.baby.ID, su.at(time.Hour))
+	_, feed3 := su.newFeed(t, session.ID, f.baby.ID, su.at(3*time.Hour))
+	_, feed2 := su.newFeed(t, session.ID, f.baby.ID, su.at(2*time.Hour))
+	_, sleep1 := su.newSleep(t, session.ID, f.baby.ID, su.at(time.Hour))
+	_, sleep2 := su.newSleep(t, session.ID, f.baby.ID, su.at(2*time.Hour))
+
+	t.Run("RecentFeedsNewestFirst", func(t *testing.T) {
+		details, err := su.s.GetRecentFeedDetailsForBaby(su.ctx, f.baby.ID, 2)
+		if err != nil {
+			t.Fatalf("Failed to get recent feeds: %v", err)
+		}
+		got := ids(details, func(d *domain.FeedDetails) uuid.UUID { return d.ID })
+		expectIDs(t, "recent feeds", got, []uuid.UUID{feed3.ID, feed2.ID})
+	})
+
+	t.Run("RecentSleepsNewestFirst", func(t *testing.T) {
+		details, err := su.s.GetRecentSleepDetailsForBaby(su.ctx, f.baby.ID, 10)
+		if err != nil {
+			t.Fatalf("Failed to get recent sleeps: %v", err)
+		}
+		got := ids(details, func(d *domain.SleepDetails) uuid.UUID { return d.ID })
+		expectIDs(t, "recent sleeps", got, []uuid.UUID{sleep2.ID, sleep1.ID})
+	})
+
+	t.Run("UpdateFeedDetails", func(t *testing.T) {
+		amount := 150
+		updated := *feed1
+		updated.AmountMl = &amount
+		if err := su.s.UpdateFeedDetails(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update feed details: %v", err)
+		}
+
+		details, err := su.s.GetFeedDetails(su.ctx, feed1.ActivityID)
+		if err != nil {
+			t.Fatalf("Failed to get feed details: %v", err)
+		}
+		if details.AmountMl == nil || *details.AmountMl != 150 {
+			t.Errorf("Expected amount 150, got %v", details.AmountMl)
+		}
+		if !details.UpdatedAt.After(su.at(time.Hour)) {
+			t.Errorf("Expected updated_at to be bumped, got %v", details.UpdatedAt)
+		}
+
+		if err := su.s.UpdateFeedDetails(su.ctx, &domain.FeedDetails{ID: uuid.New()}); err == nil {
+			t.Error("Expected error updating missing feed details")
+		}
+	})
+
+	t.Run("UpdateSleepDetails", func(t *testing.T) {
+		endTime := su.at(2 * time.Hour)
+		duration := 60
+		updated := *sleep1
+		updated.EndTime = &endTime
+		updated.DurationMinutes = &duration
+		if err := su.s.UpdateSleepDetails(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update sleep details: %v", err)
+		}
+
+		details, err := su.s.GetSleepDetails(su.ctx, sleep1.ActivityID)
+		if err != nil {
+			t.Fatalf("Failed to get sleep details: %v", err)
+		}
+		if details.EndTime == nil || !details.EndTime.Equal(endTime) || details.DurationMinutes == nil || *details.DurationMinutes != 60 {
+			t.Errorf("Expected completed sleep, got %+v", details)
+		}
+
+		if err := su.s.UpdateSleepDetails(su.ctx, &domain.SleepDetails{ID: uuid.New()}); err == nil {
+			t.Error("Expected error updating missing sleep details")
+		}
+	})
+
+	t.Run("MissingDetailsAreAnError", func(t *testing.T) {
+		missing := uuid.New()
+		if _, err := su.s.GetFeedDetails(su.ctx, missing); err == nil {
+			t.Error("Expected error getting missing feed details")
+		}
+		if _, err := su.s.GetDiaperDetails(su.ctx, missing); err == nil {
+			t.Error("Expected error getting missing diaper details")
+		}
+		if _, err := su.s.GetSleepDetails(su.ctx, missing); err == nil {
+			t.Error("Expected error getting missing sleep details")
+		}
+		if _, err := su.s.GetPumpDetails(su.ctx, missing); err == nil {
+			t.Error("Expected error getting missing pump details")
+		}
+		if _, err := su.s.GetMedicationDetails(su.ctx, missing); err == nil {
+			t.Error("Expected error getting missing medication details")
+		}
+	})
+}
+
+func (su *suite) testMedicationDetails(t *testing.T) {
+	f := su.newFamily(t)
+	session := su.newSession(t, f, domain.StatusInProgress, su.base)
+	twin := su.newBaby(t, f.family.ID, "Twin", su.base)
+	su.newDose(t, session.ID, twin.ID, "Tylenol", nil, su.at(5*time.Hour))
+
+	advil := su.newDose(t, session.ID, f.baby.ID, "Advil", nil, su.at(30*time.Minute))
+	su.newDose(t, session.ID, f.baby.ID, "Tylenol", nil, su.at(time.Hour))
+	tylenol := su.newDose(t, session.ID, f.baby.ID, "tylenol", nil, su.at(2*time.Hour))
+
+	t.Run("RecentDosesSinceInclusive", func(t *testing.T) {
+		details, err := su.s.GetRecentMedicationDetailsForBaby(su.ctx, f.baby.ID, su.at(time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to get recent doses: %v", err)
+		}
+		got := ids(details, doseIDOf)
+		if len(got) != 2 || got[0] != tylenol.ID {
+			t.Errorf("Expected the two doses from the cutoff on, newest first, got %v", got)
+		}
+	})
+
+	t.Run("LatestDosePerDrugIgnoringCase", func(t *testing.T) {
+		details, err := su.s.GetLatestMedicationDetailsForBaby(su.ctx, f.baby.ID)
+		if err != nil {
+			t.Fatalf("Failed to get latest doses: %v", err)
+		}
+		expectIDs(t, "latest doses", ids(details, doseIDOf), []uuid.UUID{advil.ID, tylenol.ID})
+	})
+
+	t.Run("UpdateMedicationDetails", func(t *testing.T) {
+		updated := *advil
+		updated.DoseAmount = 5
+		updated.IntervalOverridden = true
+		if err := su.s.UpdateMedicationDetails(su.ctx, &updated); err != nil {
+			t.Fatalf("Failed to update medication details: %v", err)
+		}
+
+		details, err := su.s.GetMedicationDetails(su.ctx, advil.ActivityID)
+		if err != nil {
+			t.Fatalf("Failed to get medication details: %v", err)
+		}
+		if details.DoseAmount != 5 || !details.IntervalOverridden {
+			t.Errorf("Expected updated dose, got %+v", details)
+		}
+
+		if err := su.s.UpdateMedicationDetails(su.ctx, &domain.MedicationDetails{ID: uuid.New()}); err == nil {
+			t.Error("Expected error updating missing medication details")
+		}
+	})
+}
+
+func (su *suite) testMedications(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+	session := su.newSession(t, f, domain.StatusInProgress, su.base)
+
+	interval := 240
+	tylenol, err := su.s.UpsertMedication(su.ctx, &domain.Medication{FamilyID: f.family.ID, Name: "Tylenol", MinIntervalMinutes: &interval})
+	if err != nil {
+		t.Fatalf("Failed to create medication: %v", err)
+	}
+	if tylenol.ID == uuid.Nil || tylenol.CreatedAt.IsZero() {
+		t.Fatalf("Expected upsert to assign an ID and timestamps, got %+v", tylenol)
+	}
+
+	t.Run("UpsertReplacesSameNameIgnoringCase", func(t *testing.T) {
+		maxDoses := 5
+		replaced, err := su.s.UpsertMedication(su.ctx, &domain.Medication{FamilyID: f.family.ID, Name: "TYLENOL", MaxDailyDoses: &maxDoses})
+		if err != nil {
+			t.Fatalf("Failed to upsert medication: %v", err)
+		}
+		if replaced.ID != tylenol.ID {
+			t.Errorf("Expected upsert to keep ID %s, got %s", tylenol.ID, replaced.ID)
+		}
+		if replaced.Name != "TYLENOL" || replaced.MinIntervalMinutes != nil || replaced.MaxDailyDoses == nil || *replaced.MaxDailyDoses != 5 {
+			t.Errorf("Expected upsert to replace the entry's fields, got %+v", replaced)
+		}
+
+		// The same name in another family is a separate entry
+		separate, err := su.s.UpsertMedication(su.ctx, &domain.Medication{FamilyID: other.family.ID, Name: "Tylenol"})
+		if err != nil {
+			t.Fatalf("Failed to upsert medication: %v", err)
+		}
+		if separate.ID == tylenol.ID {
+			t.Error("Expected a separate entry for another family")
+		}
+	})
+
+	t.Run("LookupsAndOrdering", func(t *testing.T) {
+		for _, name := range []string{"zyrtec", "Advil"} {
+			if _, err := su.s.UpsertMedication(su.ctx, &domain.Medication{FamilyID: f.family.ID, Name: name}); err != nil {
+				t.Fatalf("Failed to upsert medication: %v", err)
+			}
+		}
+
+		medications, err := su.s.GetMedicationsForFamily(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get medications: %v", err)
+		}
+		var names []string
+		for _, m := range medications {
+			names = append(names, m.Name)
+		}
+		if !slices.Equal(names, []string{"Advil", "TYLENOL", "zyrtec"}) {
+			t.Errorf("Expected medications ordered by name ignoring case, got %v", names)
+		}
+
+		byName, err := su.s.GetMedicationByName(su.ctx, f.family.ID, "tylenol")
+		if err != nil || byName == nil || byName.ID != tylenol.ID {
+			t.Errorf("Expected medication %s by name, got %v (err %v)", tylenol.ID, byName, err)
+		}
+		byName, err = su.s.GetMedicationByName(su.ctx, f.family.ID, "Unknown")
+		if err != nil || byName != nil {
+			t.Errorf("Expected (nil, nil) for an unknown name, got %v (err %v)", byName, err)
+		}
+		if _, err := su.s.GetMedicationByID(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error getting missing medication")
+		}
+	})
+
+	t.Run("DeleteKeepsDosesButDropsLink", func(t *testing.T) {
+		dose := su.newDose(t, session.ID, f.baby.ID, "Tylenol", &tylenol.ID, su.base)
+
+		if err := su.s.DeleteMedication(su.ctx, tylenol.ID); err != nil {
+			t.Fatalf("Failed to delete medication: %v", err)
+		}
+		details, err := su.s.GetMedicationDetails(su.ctx, dose.ActivityID)
+		if err != nil {
+			t.Fatalf("Expected dose to survive its catalog entry: %v", err)
+		}
+		if details.MedicationID != nil || details.DrugName != "Tylenol" {
+			t.Errorf("Expected dose to keep its name and lose the catalog link, got %+v", details)
+		}
+
+		if err := su.s.DeleteMedication(su.ctx, tylenol.ID); err == nil {
+			t.Error("Expected error deleting missing medication")
+		}
+	})
+}
+
+func (su *suite) testGrowthMeasurements(t *testing.T) {
+	f := su.newFamily(t)
+	twin := su.newBaby(t, f.family.ID, "Twin", su.base)
+
+	var want []uuid.UUID
+	for _, offset := range []time.Duration{2 * time.Hour, time.Hour} {
+		weight := 4.2
+		m := &domain.GrowthMeasurement{
+			ID:          uuid.New(),
+			FamilyID:    f.family.ID,
+			BabyID:      f.baby.ID,
+			CaregiverID: &f.caregiver.ID,
+			MeasuredAt:  su.at(offset),
+			WeightKg:    &weight,
+			CreatedAt:   su.base,
+			UpdatedAt:   su.base,
+		}
+		if err := su.s.CreateGrowthMeasurement(su.ctx, m); err != nil {
+			t.Fatalf("Failed to create growth measurement: %v", err)
+		}
+		want = append([]uuid.UUID{m.ID}, want...)
+	}
+
+	measurements, err := su.s.GetGrowthMeasurementsForBaby(su.ctx, f.baby.ID)
+	if err != nil {
+		t.Fatalf("Failed to get growth measurements: %v", err)
+	}
+	got := ids(measurements, func(m *domain.GrowthMeasurement) uuid.UUID { return m.ID })
+	expectIDs(t, "measurements oldest first", got, want)
+
+	measurements, err = su.s.GetGrowthMeasurementsForBaby(su.ctx, twin.ID)
+	if err != nil || len(measurements) != 0 {
+		t.Errorf("Expected no measurements for another baby, got %d (err %v)", len(measurements), err)
+	}
+}
+
+func (su *suite) testPredictions(t *testing.T) {
+	f := su.newFamily(t)
+	twin := su.newBaby(t, f.family.ID, "Twin", su.base)
+
+	later := su.newPrediction(f, f.baby.ID, su.at(2*time.Hour))
+	sooner := su.newPrediction(f, f.baby.ID, su.at(time.Hour))
+	twinPrediction := su.newPrediction(f, twin.ID, su.at(time.Hour))
+
+	if err := su.s.UpsertPredictions(su.ctx, f.baby.ID, []*domain.Prediction{later, sooner}); err != nil {
+		t.Fatalf("Failed to upsert predictions: %v", err)
+	}
+	if err := su.s.UpsertPredictions(su.ctx, twin.ID, []*domain.Prediction{twinPrediction}); err != nil {
+		t.Fatalf("Failed to upsert predictions: %v", err)
+	}
+
+	t.Run("SoonestFirst", func(t *testing.T) {
+		predictions, err := su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil {
+			t.Fatalf("Failed to get predictions: %v", err)
+		}
+		expectIDs(t, "predictions", ids(predictions, predictionIDOf), []uuid.UUID{sooner.ID, later.ID})
+		if predictions[0].CreatedAt.IsZero() {
+			t.Error("Expected created_at to be set by the store")
+		}
+	})
+
+	t.Run("DismissHidesPrediction", func(t *testing.T) {
+		if err := su.s.DismissPrediction(su.ctx, sooner.ID); err != nil {
+			t.Fatalf("Failed to dismiss prediction: %v", err)
+		}
+		predictions, err := su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil {
+			t.Fatalf("Failed to get predictions: %v", err)
+		}
+		expectIDs(t, "predictions after dismiss", ids(predictions, predictionIDOf), []uuid.UUID{later.ID})
+
+		if err := su.s.DismissPrediction(su.ctx, uuid.New()); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows dismissing missing prediction, got %v", err)
+		}
+	})
+
+	t.Run("UpsertReplacesOnlyThatBaby", func(t *testing.T) {
+		replacement := su.newPrediction(f, f.baby.ID, su.at(3*time.Hour))
+		if err := su.s.UpsertPredictions(su.ctx, f.baby.ID, []*domain.Prediction{replacement}); err != nil {
+			t.Fatalf("Failed to upsert predictions: %v", err)
+		}
+
+		predictions, err := su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil {
+			t.Fatalf("Failed to get predictions: %v", err)
+		}
+		expectIDs(t, "replaced predictions", ids(predictions, predictionIDOf), []uuid.UUID{replacement.ID})
+
+		predictions, err = su.s.GetPredictionsForBaby(su.ctx, twin.ID)
+		if err != nil {
+			t.Fatalf("Failed to get predictions: %v", err)
+		}
+		expectIDs(t, "other baby's predictions", ids(predictions, predictionIDOf), []uuid.UUID{twinPrediction.ID})
+	})
+
+	t.Run("DeletePredictionsForBaby", func(t *testing.T) {
+		if err := su.s.DeletePredictionsForBaby(su.ctx, twin.ID); err != nil {
+			t.Fatalf("Failed to delete predictions: %v", err)
+		}
+		predictions, err := su.s.GetPredictionsForBaby(su.ctx, twin.ID)
+		if err != nil || len(predictions) != 0 {
+			t.Errorf("Expected no predictions, got %d (err %v)", len(predictions), err)
+		}
+		predictions, err = su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil || len(predictions) != 1 {
+			t.Errorf("Expected other baby's prediction to remain, got %d (err %v)", len(predictions), err)
+		}
+	})
+
+	t.Run("CleanupByCreationTime", func(t *testing.T) {
+		if err := su.s.CleanupOldPredictions(su.ctx, time.Now().Add(-24*time.Hour)); err != nil {
+			t.Fatalf("Failed to clean up predictions: %v", err)
+		}
+		predictions, err := su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil || len(predictions) != 1 {
+			t.Errorf("Expected fresh prediction to survive cleanup, got %d (err %v)", len(predictions), err)
+		}
+
+		if err := su.s.CleanupOldPredictions(su.ctx, time.Now().Add(24*time.Hour)); err != nil {
+			t.Fatalf("Failed to clean up predictions: %v", err)
+		}
+		predictions, err = su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil || len(predictions) != 0 {
+			t.Errorf("Expected cleanup to remove prediction, got %d (err %v)", len(predictions), err)
+		}
+	})
+}
+
+func (su *suite) testPredictionOutcomes(t *testing.T) {
+	f := su.newFamily(t)
+	twin := su.newBaby(t, f.family.ID, "Twin", su.base)
+	session := su.newSession(t, f, domain.StatusInProgress, su.base)
+
+	newOutcome := func(babyID uuid.UUID, actualTime time.Time) *domain.PredictionOutcome {
+		t.Helper()
+		activity, _ := su.newFeed(t, session.ID, babyID, actualTime)
+		confidence := domain.PredictionConfidenceHigh
+		o := &domain.PredictionOutcome{
+			ID:             uuid.New(),
+			FamilyID:       f.family.ID,
+			BabyID:         babyID,
+			PredictionID:   uuid.New(),
+			ActivityID:     activity.ID,
+			PredictionType: domain.PredictionTypeNextFeed,
+			Confidence:     &confidence,
+			PredictedTime:  actualTime.Add(-10 * time.Minute),
+			ActualTime:     actualTime,
+			CreatedAt:      actualTime,
+		}
+		if err := su.s.CreatePredictionOutcome(su.ctx, o); err != nil {
+			t.Fatalf("Failed to create prediction outcome: %v", err)
+		}
+		return o
+	}
+	outcomeIDOf := func(o *domain.PredictionOutcome) uuid.UUID { return o.ID }
+
+	old := newOutcome(f.baby.ID, su.at(-48*time.Hour))
+	later := newOutcome(f.baby.ID, su.at(2*time.Hour))
+	sooner := newOutcome(f.baby.ID, su.at(time.Hour))
+	twinOutcome := newOutcome(twin.ID, su.at(90*time.Minute))
+
+	t.Run("OldestFirstSince", func(t *testing.T) {
+		outcomes, err := su.s.GetPredictionOutcomesForBaby(su.ctx, f.baby.ID, su.base)
+		if err != nil {
+			t.Fatalf("Failed to get prediction outcomes: %v", err)
+		}
+		expectIDs(t, "baby outcomes", ids(outcomes, outcomeIDOf), []uuid.UUID{sooner.ID, later.ID})
+		if got := outcomes[0]; got.Confidence == nil || *got.Confidence != domain.PredictionConfidenceHigh ||
+			got.Offset() != -10*time.Minute || got.PredictionType != domain.PredictionTypeNextFeed {
+			t.Errorf("Outcome not stored faithfully: %+v", got)
+		}
+
+		outcomes, err = su.s.GetPredictionOutcomesForFamily(su.ctx, f.family.ID, su.base)
+		if err != nil {
+			t.Fatalf("Failed to get prediction outcomes: %v", err)
+		}
+		expectIDs(t, "family outcomes", ids(outcomes, outcomeIDOf), []uuid.UUID{sooner.ID, twinOutcome.ID, later.ID})
+
+		outcomes, err = su.s.GetPredictionOutcomesForFamily(su.ctx, f.family.ID, old.ActualTime)
+		if err != nil || len(outcomes) != 4 {
+			t.Errorf("Expected since to be inclusive, got %d outcomes (err %v)", len(outcomes), err)
+		}
+	})
+
+	t.Run("OneOutcomePerPrediction", func(t *testing.T) {
+		activity, _ := su.newFeed(t, session.ID, f.baby.ID, su.at(3*time.Hour))
+		second := *sooner
+		second.ID = uuid.New()
+		second.ActivityID = activity.ID
+		second.ActualTime = su.at(3 * time.Hour)
+		if err := su.s.CreatePredictionOutcome(su.ctx, &second); err != nil {
+			t.Fatalf("Expected a second outcome for a prediction to be ignored, got %v", err)
+		}
+
+		outcomes, err := su.s.GetPredictionOutcomesForBaby(su.ctx, f.baby.ID, su.base)
+		if err != nil {
+			t.Fatalf("Failed to get prediction outcomes: %v", err)
+		}
+		expectIDs(t, "outcomes", ids(outcomes, outcomeIDOf), []uuid.UUID{sooner.ID, later.ID})
+	})
+
+	t.Run("DeletedWithFamily", func(t *testing.T) {
+		if err := su.s.DeleteFamily(su.ctx, f.family.ID); err != nil {
+			t.Fatalf("Failed to delete family: %v", err)
+		}
+		outcomes, err := su.s.GetPredictionOutcomesForFamily(su.ctx, f.family.ID, time.Time{})
+		if err != nil || len(outcomes) != 0 {
+			t.Errorf("Expected outcomes to be deleted with the family, got %d (err %v)", len(outcomes), err)
+		}
+	})
+}
+
+func (su *suite) testScheduleGoals(t *testing.T) {
+	f := su.newFamily(t)
+
+	goals, err := su.s.GetScheduleGoals(su.ctx, f.baby.ID)
+	if err != nil || goals != nil {
+		t.Fatalf("Expected (nil, nil) before goals are set, got %v (err %v)", goals, err)
+	}
+
+	wakeWindow := 90
+	created, err := su.s.UpsertScheduleGoals(su.ctx, f.baby.ID, &domain.ScheduleGoals{TargetWakeWindowMinutes: &wakeWindow})
+	if err != nil {
+		t.Fatalf("Failed to create goals: %v", err)
+	}
+	if created.ID == uuid.Nil || created.FamilyID != f.family.ID || created.BabyID != f.baby.ID {
+		t.Errorf("Expected goals linked to the baby and family, got %+v", created)
+	}
+
+	naps := 3
+	updated, err := su.s.UpsertScheduleGoals(su.ctx, f.baby.ID, &domain.ScheduleGoals{TargetNapCount: &naps})
+	if err != nil {
+		t.Fatalf("Failed to update goals: %v", err)
+	}
+	if updated.ID != created.ID {
+		t.Errorf("Expected upsert to keep ID %s, got %s", created.ID, updated.ID)
+	}
+	if updated.TargetWakeWindowMinutes == nil || *updated.TargetWakeWindowMinutes != 90 || updated.TargetNapCount == nil || *updated.TargetNapCount != 3 {
+		t.Errorf("Expected unset fields to keep their value, got %+v", updated)
+	}
+
+	goals, err = su.s.GetScheduleGoals(su.ctx, f.baby.ID)
+	if err != nil || goals == nil || goals.TargetNapCount == nil || *goals.TargetNapCount != 3 {
+		t.Errorf("Expected stored goals to match, got %+v (err %v)", goals, err)
+	}
+
+	if _, err := su.s.UpsertScheduleGoals(su.ctx, uuid.New(), &domain.ScheduleGoals{TargetNapCount: &naps}); err == nil {
+		t.Error("Expected error setting goals for a missing baby")
+	}
+}
+
+func (su *suite) testReminderPreferences(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+	second := su.caregiver(f.family.ID, su.at(time.Hour))
+	if err := su.s.CreateCaregiver(su.ctx, second); err != nil {
+		t.Fatalf("Failed to create caregiver: %v", err)
+	}
+
+	prefs, err := su.s.GetReminderPreferences(su.ctx, f.caregiver.ID)
+	if err != nil || prefs != nil {
+		t.Fatalf("Expected (nil, nil) before preferences are set, got %+v (err %v)", prefs, err)
+	}
+
+	created, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: f.caregiver.ID, FeedReminders: true, LeadMinutes: 15})
+	if err != nil {
+		t.Fatalf("Failed to create preferences: %v", err)
+	}
+	if created.FamilyID != f.family.ID || !created.FeedReminders || created.NapReminders || created.CreatedAt.IsZero() {
+		t.Errorf("Expected preferences linked to the caregiver's family, got %+v", created)
+	}
+
+	updated, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: f.caregiver.ID, NapReminders: true, OverdueReminders: true, LeadMinutes: 30})
+	if err != nil {
+		t.Fatalf("Failed to update preferences: %v", err)
+	}
+	if updated.FeedReminders || !updated.NapReminders || !updated.OverdueReminders || updated.LeadMinutes != 30 {
+		t.Errorf("Expected upsert to replace every setting, got %+v", updated)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected upsert to keep CreatedAt %v, got %v", created.CreatedAt, updated.CreatedAt)
+	}
+
+	prefs, err = su.s.GetReminderPreferences(su.ctx, f.caregiver.ID)
+	if err != nil || prefs == nil || !prefs.NapReminders || prefs.LeadMinutes != 30 {
+		t.Errorf("Expected stored preferences to match, got %+v (err %v)", prefs, err)
+	}
+
+	for _, caregiverID := range []uuid.UUID{second.ID, other.caregiver.ID} {
+		if _, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: caregiverID, BedtimeReminders: true}); err != nil {
+			t.Fatalf("Failed to create preferences: %v", err)
+		}
+	}
+	family, err := su.s.GetReminderPreferencesForFamily(su.ctx, f.family.ID)
+	if err != nil {
+		t.Fatalf("Failed to get family preferences: %v", err)
+	}
+	var caregiverIDs []uuid.UUID
+	for _, p := range family {
+		caregiverIDs = append(caregiverIDs, p.CaregiverID)
+	}
+	expectIDs(t, "family reminder preferences", caregiverIDs, []uuid.UUID{f.caregiver.ID, second.ID})
+
+	if _, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: f.caregiver.ID, LeadMinutes: domain.MaxReminderLeadMinutes + 1}); err == nil {
+		t.Error("Expected error for an out of range lead time")
+	}
+	if _, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: uuid.New()}); err == nil {
+		t.Error("Expected error setting preferences for a missing caregiver")
+	}
+}
+
+func (su *suite) testActiveFamilies(t *testing.T) {
+	inProgress := su.newFamily(t)
+	recent := su.newFamily(t)
+	stale := su.newFamily(t)
+	idle := su.newFamily(t)
+	su.newSession(t, inProgress, domain.StatusInProgress, su.base)
+	su.newSession(t, recent, domain.StatusCompleted, su.at(2*time.Hour))
+	su.newSession(t, stale, domain.StatusCompleted, su.base)
+
+	// Other families may share the database, so only check ours
+	familyIDs, err := su.s.GetActiveFamilyIDs(su.ctx, su.at(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get active families: %v", err)
+	}
+	for _, f := range []fixture{inProgress, recent} {
+		if !slices.Contains(familyIDs, f.family.ID) {
+			t.Errorf("Expected family %s to be active", f.family.ID)
+		}
+	}
+	for _, f := range []fixture{stale, idle} {
+		if slices.Contains(familyIDs, f.family.ID) {
+			t.Errorf("Expected family %s to be inactive", f.family.ID)
+		}
+	}
+	if !slices.IsSortedFunc(familyIDs, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) }) {
+		t.Error("Expected active families ordered by ID")
+	}
+}
+
+func (su *suite) testOfflineSync(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+
+	t.Run("IdempotencyKeysArePerFamily", func(t *testing.T) {
+		key := "key-" + uuid.New().String()
+		record := &domain.IdempotencyRecord{FamilyID: f.family.ID, Key: key, ActivityID: uuid.New(), Operation: domain.SyncOperationCreate, CreatedAt: su.base}
+		if err := su.s.SaveIdempotencyRecord(su.ctx, record); err != nil {
+			t.Fatalf("Failed to save idempotency record: %v", err)
+		}
+
+		saved, err := su.s.GetIdempotencyRecord(su.ctx, f.family.ID, key)
+		if err != nil || saved == nil || saved.ActivityID != record.ActivityID || saved.Operation != domain.SyncOperationCreate {
+			t.Errorf("Expected saved record, got %+v (err %v)", saved, err)
+		}
+		saved, err = su.s.GetIdempotencyRecord(su.ctx, other.family.ID, key)
+		if err != nil || saved != nil {
+			t.Errorf("Expected (nil, nil) for another family, got %+v (err %v)", saved, err)
+		}
+
+		if err := su.s.SaveIdempotencyRecord(su.ctx, record); err == nil {
+			t.Error("Expected error reusing an idempotency key")
+		}
+		reused := *record
+		reused.FamilyID = other.family.ID
+		if err := su.s.SaveIdempotencyRecord(su.ctx, &reused); err != nil {
+			t.Errorf("Expected another family to be able to use the same key: %v", err)
+		}
+	})
+
+	t.Run("Tombstones", func(t *testing.T) {
+		first := &domain.DeletedActivity{ActivityID: uuid.New(), FamilyID: f.family.ID, BabyID: f.baby.ID, DeletedAt: su.at(2 * time.Hour)}
+		second := &domain.DeletedActivity{ActivityID: uuid.New(), FamilyID: f.family.ID, BabyID: f.baby.ID, DeletedAt: su.at(3 * time.Hour)}
+		old := &domain.DeletedActivity{ActivityID: uuid.New(), FamilyID: f.family.ID, BabyID: f.baby.ID, DeletedAt: su.base}
+		elsewhere := &domain.DeletedActivity{ActivityID: uuid.New(), FamilyID: other.family.ID, BabyID: other.baby.ID, DeletedAt: su.at(2 * time.Hour)}
+		for _, d := range []*domain.DeletedActivity{second, first, old, elsewhere} {
+			if err := su.s.RecordDeletedActivity(su.ctx, d); err != nil {
+				t.Fatalf("Failed to record deleted activity: %v", err)
+			}
+		}
+
+		// Recording again keeps the original tombstone
+		again := *first
+		again.DeletedAt = su.at(10 * time.Hour)
+		if err := su.s.RecordDeletedActivity(su.ctx, &again); err != nil {
+			t.Fatalf("Failed to re-record deleted activity: %v", err)
+		}
+		tombstone, err := su.s.GetDeletedActivity(su.ctx, first.ActivityID)
+		if err != nil || tombstone == nil || !tombstone.DeletedAt.Equal(first.DeletedAt) {
+			t.Errorf("Expected original tombstone, got %+v (err %v)", tombstone, err)
+		}
+
+		tombstone, err = su.s.GetDeletedActivity(su.ctx, uuid.New())
+		if err != nil || tombstone != nil {
+			t.Errorf("Expected (nil, nil) for an activity that wasn't deleted, got %+v (err %v)", tombstone, err)
+		}
+
+		deleted, err := su.s.GetDeletedActivitiesSinceForFamily(su.ctx, f.family.ID, su.base)
+		if err != nil {
+			t.Fatalf("Failed to get deleted activities: %v", err)
+		}
+		expectIDs(t, "tombstones after since", ids(deleted, tombstoneIDOf), []uuid.UUID{first.ActivityID, second.ActivityID})
+
+		if err := su.s.ClearDeletedActivity(su.ctx, first.ActivityID); err != nil {
+			t.Fatalf("Failed to clear deleted activity: %v", err)
+		}
+		if tombstone, err := su.s.GetDeletedActivity(su.ctx, first.ActivityID); err != nil || tombstone != nil {
+			t.Errorf("Expected tombstone to be cleared, got %+v (err %v)", tombstone, err)
+		}
+	})
+}
+
+func (su *suite) newWebhookSubscription(t *testing.T, familyID uuid.UUID, createdAt time.Time, eventTypes ...domain.WebhookEventType) *domain.WebhookSubscription {
+	t.Helper()
+	sub := &domain.WebhookSubscription{
+		ID: uuid.New(), FamilyID: familyID, URL: "https://example.com/hooks", Secret: "secret",
+		EventTypes: eventTypes, CreatedAt: createdAt, UpdatedAt: createdAt,
+	}
+	if err := su.s.CreateWebhookSubscription(su.ctx, sub); err != nil {
+		t.Fatalf("Failed to create webhook subscription: %v", err)
+	}
+	return sub
+}
+
+func (su *suite) newWebhookDelivery(t *testing.T, sub *domain.WebhookSubscription, nextAttemptAt time.Time) *domain.WebhookDelivery {
+	t.Helper()
+	d := &domain.WebhookDelivery{
+		ID: uuid.New(), SubscriptionID: sub.ID, FamilyID: sub.FamilyID, EventType: domain.WebhookEventActivityAdded,
+		Payload: `{"type":"activity_added"}`, Status: domain.WebhookDeliveryPending,
+		NextAttemptAt: nextAttemptAt, CreatedAt: nextAttemptAt, UpdatedAt: nextAttemptAt,
+	}
+	if err := su.s.CreateWebhookDelivery(su.ctx, d); err != nil {
+		t.Fatalf("Failed to create webhook delivery: %v", err)
+	}
+	return d
+}
+
+func (su *suite) testWebhooks(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+
+	t.Run("SubscriptionsOldestFirst", func(t *testing.T) {
+		second := su.newWebhookSubscription(t, f.family.ID, su.at(time.Hour), domain.WebhookEventActivityAdded, domain.WebhookEventPredictionOverdue)
+		first := su.newWebhookSubscription(t, f.family.ID, su.base)
+		su.newWebhookSubscription(t, other.family.ID, su.base)
+
+		subs, err := su.s.GetWebhookSubscriptionsForFamily(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get webhook subscriptions: %v", err)
+		}
+		var ids []uuid.UUID
+		for _, sub := range subs {
+			ids = append(ids, sub.ID)
+		}
+		expectIDs(t, "webhook subscriptions", ids, []uuid.UUID{first.ID, second.ID})
+
+		got, err := su.s.GetWebhookSubscriptionByID(su.ctx, second.ID)
+		if err != nil {
+			t.Fatalf("Failed to get webhook subscription: %v", err)
+		}
+		if !slices.Equal(got.EventTypes, second.EventTypes) || got.Secret != "secret" {
+			t.Errorf("Expected stored subscription to match, got %+v", got)
+		}
+		if len(subs[0].EventTypes) != 0 || !subs[0].Wants(domain.WebhookEventSessionStarted) {
+			t.Errorf("Expected an empty filter to want every event, got %v", subs[0].EventTypes)
+		}
+	})
+
+	t.Run("DueDeliveriesEarliestFirst", func(t *testing.T) {
+		sub := su.newWebhookSubscription(t, f.family.ID, su.base)
+		later := su.newWebhookDelivery(t, sub, su.at(2*time.Minute))
+		earlier := su.newWebhookDelivery(t, sub, su.at(time.Minute))
+		notDue := su.newWebhookDelivery(t, sub, su.at(time.Hour))
+
+		// Other families may share the database, so only check ours
+		due, err := su.s.GetDueWebhookDeliveries(su.ctx, su.at(5*time.Minute), 1000)
+		if err != nil {
+			t.Fatalf("Failed to get due deliveries: %v", err)
+		}
+		var ids []uuid.UUID
+		for _, d := range due {
+			if d.SubscriptionID == sub.ID {
+				ids = append(ids, d.ID)
+			}
+		}
+		expectIDs(t, "due deliveries", ids, []uuid.UUID{earlier.ID, later.ID})
+
+		code := 200
+		deliveredAt := su.at(5 * time.Minute)
+		earlier.Status = domain.WebhookDeliveryDelivered
+		earlier.Attempts = 1
+		earlier.LastStatusCode = &code
+		earlier.DeliveredAt = &deliveredAt
+		if err := su.s.UpdateWebhookDelivery(su.ctx, earlier); err != nil {
+			t.Fatalf("Failed to update delivery: %v", err)
+		}
+
+		message := "connection refused"
+		later.Attempts = 1
+		later.LastError = &message
+		later.NextAttemptAt = su.at(10 * time.Minute)
+		if err := su.s.UpdateWebhookDelivery(su.ctx, later); err != nil {
+			t.Fatalf("Failed to update delivery: %v", err)
+		}
+
+		due, err = su.s.GetDueWebhookDeliveries(su.ctx, su.at(5*time.Minute), 1000)
+		if err != nil {
+			t.Fatalf("Failed to get due deliveries: %v", err)
+		}
+		for _, d := range due {
+			if d.SubscriptionID == sub.ID {
+				t.Errorf("Expected no due deliveries after updates, got %s", d.ID)
+			}
+		}
+
+		delivered := domain.WebhookDeliveryDelivered
+		deliveries, err := su.s.GetWebhookDeliveriesForFamily(su.ctx, f.family.ID, &delivered, 10)
+		if err != nil {
+			t.Fatalf("Failed to get deliveries: %v", err)
+		}
+		if len(deliveries) != 1 || deliveries[0].ID != earlier.ID || deliveries[0].LastStatusCode == nil || *deliveries[0].LastStatusCode != 200 || deliveries[0].DeliveredAt == nil {
+			t.Errorf("Expected the delivered delivery with its outcome, got %+v", deliveries)
+		}
+
+		deliveries, err = su.s.GetWebhookDeliveriesForFamily(su.ctx, f.family.ID, nil, 10)
+		if err != nil {
+			t.Fatalf("Failed to get deliveries: %v", err)
+		}
+		ids = nil
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+		}
+		expectIDs(t, "family deliveries", ids, []uuid.UUID{notDue.ID, later.ID, earlier.ID})
+		if deliveries[1].LastError == nil || *deliveries[1].LastError != message || deliveries[1].Attempts != 1 {
+			t.Errorf("Expected the failed attempt to be recorded, got %+v", deliveries[1])
+		}
+	})
+
+	t.Run("DeleteSubscriptionCascadesToDeliveries", func(t *testing.T) {
+		sub := su.newWebhookSubscription(t, other.family.ID, su.base)
+		su.newWebhookDelivery(t, sub, su.base)
+
+		if err := su.s.DeleteWebhookSubscription(su.ctx, sub.ID); err != nil {
+			t.Fatalf("Failed to delete webhook subscription: %v", err)
+		}
+		if _, err := su.s.GetWebhookSubscriptionByID(su.ctx, sub.ID); err == nil {
+			t.Error("Expected webhook subscription to be deleted")
+		}
+		deliveries, err := su.s.GetWebhookDeliveriesForFamily(su.ctx, other.family.ID, nil, 10)
+		if err != nil {
+			t.Fatalf("Failed to get deliveries: %v", err)
+		}
+		if len(deliveries) != 0 {
+			t.Errorf("Expected deliveries to be deleted with the subscription, got %d", len(deliveries))
+		}
+		if err := su.s.DeleteWebhookSubscription(su.ctx, sub.ID); err == nil {
+			t.Error("Expected error deleting a missing webhook subscription")
+		}
+	})
+
+	t.Run("CreateRequiresFamily", func(t *testing.T) {
+		sub := &domain.WebhookSubscription{ID: uuid.New(), FamilyID: uuid.New(), URL: "https://example.com", Secret: "s", CreatedAt: su.base, UpdatedAt: su.base}
+		if err := su.s.CreateWebhookSubscription(su.ctx, sub); err == nil {
+			t.Error("Expected error creating a subscription for a missing family")
+		}
+	})
+}
+
+func (su *suite) newInvite(t *testing.T, f fixture, createdAt time.Time, maxUses *int) *domain.FamilyInvite {
+	t.Helper()
+	invite := &domain.FamilyInvite{
+		ID: uuid.New(), FamilyID: f.family.ID, CodeHash: "hash-" + uuid.New().String(), CreatedBy: &f.caregiver.ID,
+		ExpiresAt: createdAt.Add(domain.DefaultInviteTTL), MaxUses: maxUses, CreatedAt: createdAt, UpdatedAt: createdAt,
+	}
+	if err := su.s.CreateFamilyInvite(su.ctx, invite); err != nil {
+		t.Fatalf("Failed to create invite: %v", err)
+	}
+	return invite
+}
+
+func (su *suite) testInvites(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+
+	t.Run("InvitesNewestFirst", func(t *testing.T) {
+		first := su.newInvite(t, f, su.base, nil)
+		second := su.newInvite(t, f, su.at(time.Hour), nil)
+		su.newInvite(t, other, su.base, nil)
+
+		invites, err := su.s.GetFamilyInvitesForFamily(su.ctx, f.family.ID)
+		if err != nil {
+			t.Fatalf("Failed to get invites: %v", err)
+		}
+		var ids []uuid.UUID
+		for _, invite := range invites {
+			ids = append(ids, invite.ID)
+		}
+		expectIDs(t, "invites", ids, []uuid.UUID{second.ID, first.ID})
+
+		got, err := su.s.GetFamilyInviteByCodeHash(su.ctx, first.CodeHash)
+		if err != nil {
+			t.Fatalf("Failed to get invite by code hash: %v", err)
+		}
+		if got.ID != first.ID || got.CreatedBy == nil || *got.CreatedBy != f.caregiver.ID || !got.ExpiresAt.Equal(first.ExpiresAt) {
+			t.Errorf("Expected stored invite to match, got %+v", got)
+		}
+		if _, err := su.s.GetFamilyInviteByCodeHash(su.ctx, "no-such-hash"); err == nil {
+			t.Error("Expected error for an unknown code hash")
+		}
+	})
+
+	t.Run("CodeHashesAreUnique", func(t *testing.T) {
+		invite := su.newInvite(t, f, su.base, nil)
+		duplicate := &domain.FamilyInvite{
+			ID: uuid.New(), FamilyID: other.family.ID, CodeHash: invite.CodeHash,
+			ExpiresAt: su.at(time.Hour), CreatedAt: su.base, UpdatedAt: su.base,
+		}
+		if err := su.s.CreateFamilyInvite(su.ctx, duplicate); err == nil {
+			t.Error("Expected error reusing a code hash")
+		}
+	})
+
+	t.Run("RoleDefaultsToCaregiver", func(t *testing.T) {
+		invite := su.newInvite(t, f, su.base, nil)
+
+		got, err := su.s.GetFamilyInviteByID(su.ctx, invite.ID)
+		if err != nil {
+			t.Fatalf("Failed to get invite: %v", err)
+		}
+		if got.Role != domain.RoleCaregiver {
+			t.Errorf("Expected role %q, got %q", domain.RoleCaregiver, got.Role)
+		}
+	})
+
+	t.Run("RedeemUpToMaxUses", func(t *testing.T) {
+		maxUses := 2
+		invite := su.newInvite(t, f, su.base, &maxUses)
+
+		for i := range 3 {
+			ok, err := su.s.RedeemFamilyInvite(su.ctx, invite.ID, su.at(time.Minute))
+			if err != nil {
+				t.Fatalf("Failed to redeem invite: %v", err)
+			}
+			if ok != (i < maxUses) {
+				t.Errorf("Redemption %d: expected %v, got %v", i+1, i < maxUses, ok)
+			}
+		}
+
+		got, err := su.s.GetFamilyInviteByID(su.ctx, invite.ID)
+		if err != nil {
+			t.Fatalf("Failed to get invite: %v", err)
+		}
+		if got.UseCount != maxUses {
+			t.Errorf("Expected use count %d, got %d", maxUses, got.UseCount)
+		}
+	})
+
+	t.Run("ExpiredInvitesCannotBeRedeemed", func(t *testing.T) {
+		invite := su.newInvite(t, f, su.base, nil)
+
+		ok, err := su.s.RedeemFamilyInvite(su.ctx, invite.ID, invite.ExpiresAt)
+		if err != nil {
+			t.Fatalf("Failed to redeem invite: %v", err)
+		}
+		if ok {
+			t.Error("Expected an expired invite not to be redeemed")
+		}
+		if _, err := su.s.RedeemFamilyInvite(su.ctx, uuid.New(), su.base); err == nil {
+			t.Error("Expected error redeeming a missing invite")
+		}
+	})
+
+	t.Run("RevokeKeepsFirstTime", func(t *testing.T) {
+		invite := su.newInvite(t, f, su.base, nil)
+
+		if err := su.s.RevokeFamilyInvite(su.ctx, invite.ID, su.at(time.Minute)); err != nil {
+			t.Fatalf("Failed to revoke invite: %v", err)
+		}
+		if err := su.s.RevokeFamilyInvite(su.ctx, invite.ID, su.at(time.Hour)); err != nil {
+			t.Fatalf("Failed to revoke invite again: %v", err)
+		}
+
+		got, err := su.s.GetFamilyInviteByID(su.ctx, invite.ID)
+		if err != nil {
+			t.Fatalf("Failed to get invite: %v", err)
+		}
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(su.at(time.Minute)) {
+			t.Errorf("Expected invite revoked at %v, got %v", su.at(time.Minute), got.RevokedAt)
+		}
+		ok, err := su.s.RedeemFamilyInvite(su.ctx, invite.ID, su.at(2*time.Minute))
+		if err != nil || ok {
+			t.Errorf("Expected a revoked invite not to be redeemed, got %v (err %v)", ok, err)
+		}
+		if err := su.s.RevokeFamilyInvite(su.ctx, uuid.New(), su.base); err == nil {
+			t.Error("Expected error revoking a missing invite")
+		}
+	})
+}
+
+func (su *suite) newAuditEvent(t *testing.T, f fixture, action domain.AuditAction, entityType domain.AuditEntityType, entityID uuid.UUID, createdAt time.Time) *domain.AuditEvent {
+	t.Helper()
+
+	before := `{"name":"before"}`
+	event := &domain.AuditEvent{
+		ID:               uuid.New(),
+		FamilyID:         f.family.ID,
+		ActorCaregiverID: f.caregiver.ID,
+		ActorName:        f.caregiver.Name,
+		Action:           action,
+		EntityType:       entityType,
+		EntityID:         entityID,
+		Before:           &before,
+		CreatedAt:        createdAt,
+	}
+	if err := su.s.CreateAuditEvent(su.ctx, event); err != nil {
+		t.Fatalf("Failed to create audit event: %v", err)
+	}
+	return event
+}
+
+func auditEventIDOf(e *domain.AuditEvent) uuid.UUID { return e.ID }
+
+func (su *suite) testAuditLog(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+	su.newAuditEvent(t, other, domain.AuditActionCreate, domain.AuditEntityBaby, other.baby.ID, su.base)
+
+	// Two events share a time so the id tie-break is exercised
+	activityID := uuid.New()
+	events := []*domain.AuditEvent{
+		su.newAuditEvent(t, f, domain.AuditActionCreate, domain.AuditEntityActivity, activityID, su.at(time.Minute)),
+		su.newAuditEvent(t, f, domain.AuditActionUpdate, domain.AuditEntityActivity, activityID, su.at(2*time.Minute)),
+		su.newAuditEvent(t, f, domain.AuditActionUpdate, domain.AuditEntityBaby, f.baby.ID, su.at(2*time.Minute)),
+		su.newAuditEvent(t, f, domain.AuditActionDelete, domain.AuditEntityActivity, activityID, su.at(3*time.Minute)),
+	}
+	slices.SortFunc(events, func(a, b *domain.AuditEvent) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+	want := ids(events, auditEventIDOf)
+
+	t.Run("PagesNewestFirst", func(t *testing.T) {
+		var got []uuid.UUID
+		var afterTime *time.Time
+		var afterID *uuid.UUID
+		for page := 0; page < len(want); page++ {
+			results, err := su.s.GetAuditEventsForFamily(su.ctx, f.family.ID, domain.AuditFilter{}, 3, afterTime, afterID)
+			if err != nil {
+				t.Fatalf("Failed to get audit events: %v", err)
+			}
+			if len(results) == 0 {
+				break
+			}
+			got = append(got, ids(results, auditEventIDOf)...)
+			last := results[len(results)-1]
+			afterTime, afterID = &last.CreatedAt, &last.ID
+		}
+		expectIDs(t, "audit events", got, want)
+	})
+
+	t.Run("StoresSnapshots", func(t *testing.T) {
+		results, err := su.s.GetAuditEventsForFamily(su.ctx, f.family.ID, domain.AuditFilter{}, 1, nil, nil)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("Expected one audit event, got %d (err %v)", len(results), err)
+		}
+		got := results[0]
+		if got.ActorCaregiverID != f.caregiver.ID || got.ActorName != f.caregiver.Name {
+			t.Errorf("Expected actor %s (%s), got %s (%s)", f.caregiver.ID, f.caregiver.Name, got.ActorCaregiverID, got.ActorName)
+		}
+		if got.Before == nil || !strings.Contains(*got.Before, `"before"`) {
+			t.Errorf("Expected before snapshot to round-trip, got %v", got.Before)
+		}
+		if got.After != nil {
+			t.Errorf("Expected no after snapshot, got %q", *got.After)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		update := domain.AuditActionUpdate
+		activity := domain.AuditEntityActivity
+		someoneElse := uuid.New()
+
+		tests := []struct {
+			name   string
+			filter domain.AuditFilter
+			want   int
+		}{
+			{"Action", domain.AuditFilter{Action: &update}, 2},
+			{"EntityType", domain.AuditFilter{EntityType: &activity}, 3},
+			{"EntityID", domain.AuditFilter{EntityID: &f.baby.ID}, 1},
+			{"Combined", domain.AuditFilter{Action: &update, EntityType: &activity, EntityID: &activityID}, 1},
+			{"Actor", domain.AuditFilter{ActorCaregiverID: &f.caregiver.ID}, 4},
+			{"OtherActor", domain.AuditFilter{ActorCaregiverID: &someoneElse}, 0},
+		}
+		for _, tt := range tests {
+			results, err := su.s.GetAuditEventsForFamily(su.ctx, f.family.ID, tt.filter, 10, nil, nil)
+			if err != nil {
+				t.Fatalf("%s: failed to get audit events: %v", tt.name, err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("%s: expected %d events, got %d", tt.name, tt.want, len(results))
+			}
+		}
+	})
+
+	t.Run("EventsOutliveTheirActor", func(t *testing.T) {
+		if err := su.s.DeleteCaregiver(su.ctx, f.caregiver.ID); err != nil {
+			t.Fatalf("Failed to delete caregiver: %v", err)
+		}
+		results, err := su.s.GetAuditEventsForFamily(su.ctx, f.family.ID, domain.AuditFilter{ActorCaregiverID: &f.caregiver.ID}, 10, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to get audit events: %v", err)
+		}
+		if len(results) != len(want) {
+			t.Errorf("Expected %d events to survive their actor, got %d", len(want), len(results))
+		}
+	})
+
+	t.Run("CreateRequiresFamily", func(t *testing.T) {
+		event := &domain.AuditEvent{
+			ID: uuid.New(), FamilyID: uuid.New(), ActorCaregiverID: uuid.New(), ActorName: "Nobody",
+			Action: domain.AuditActionCreate, EntityType: domain.AuditEntityBaby, EntityID: uuid.New(), CreatedAt: su.base,
+		}
+		if err := su.s.CreateAuditEvent(su.ctx, event); err == nil {
+			t.Error("Expected error creating an audit event for a missing family")
+		}
+	})
+}
+
+func (su *suite) testDeleteFamilyCascades(t *testing.T) {
+	f := su.newFamily(t)
+	session := su.newSession(t, f, domain.StatusInProgress, su.base)
+	feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.base)
+	medication, err := su.s.UpsertMedication(su.ctx, &domain.Medication{FamilyID: f.family.ID, Name: "Tylenol"})
+	if err != nil {
+		t.Fatalf("Failed to create medication: %v", err)
+	}
+	naps := 3
+	if _, err := su.s.UpsertScheduleGoals(su.ctx, f.baby.ID, &domain.ScheduleGoals{TargetNapCount: &naps}); err != nil {
+		t.Fatalf("Failed to create goals: %v", err)
+	}
+	if err := su.s.UpsertPredictions(su.ctx, f.baby.ID, []*domain.Prediction{su.newPrediction(f, f.baby.ID, su.base)}); err != nil {
+		t.Fatalf("Failed to create predictions: %v", err)
+	}
+	key := "key-" + uuid.New().String()
+	if err := su.s.SaveIdempotencyRecord(su.ctx, &domain.IdempotencyRecord{FamilyID: f.family.ID, Key: key, ActivityID: feed.ID, Operation: domain.SyncOperationCreate, CreatedAt: su.base}); err != nil {
+		t.Fatalf("Failed to save idempotency record: %v", err)
+	}
+	tombstone := &domain.DeletedActivity{ActivityID: uuid.New(), FamilyID: f.family.ID, BabyID: f.baby.ID, DeletedAt: su.base}
+	if err := su.s.RecordDeletedActivity(su.ctx, tombstone); err != nil {
+		t.Fatalf("Failed to record deleted activity: %v", err)
+	}
+	if _, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: f.caregiver.ID, FeedReminders: true}); err != nil {
+		t.Fatalf("Failed to save reminder preferences: %v", err)
+	}
+	sub := su.newWebhookSubscription(t, f.family.ID, su.base)
+	su.newWebhookDelivery(t, sub, su.base)
+	invite := su.newInvite(t, f, su.base, nil)
+	su.newAuditEvent(t, f, domain.AuditActionCreate, domain.AuditEntityBaby, f.baby.ID, su.base)
+
+	if err := su.s.DeleteFamily(su.ctx, f.family.ID); err != nil {
+		t.Fatalf("Failed to delete family: %v", err)
+	}
+
+	if _, err := su.s.GetBabyByID(su.ctx, f.baby.ID); err == nil {
+		t.Error("Expected baby to be deleted")
+	}
+	if _, err := su.s.GetCaregiverByID(su.ctx, f.caregiver.ID); err == nil {
+		t.Error("Expected caregiver to be deleted")
+	}
+	if _, err := su.s.GetCareSessionByID(su.ctx, session.ID); err == nil {
+		t.Error("Expected care session to be deleted")
+	}
+	if _, err := su.s.GetActivityByID(su.ctx, feed.ID); err == nil {
+		t.Error("Expected activity to be deleted")
+	}
+	if _, err := su.s.GetMedicationByID(su.ctx, medication.ID); err == nil {
+		t.Error("Expected medication to be deleted")
+	}
+	if goals, _ := su.s.GetScheduleGoals(su.ctx, f.baby.ID); goals != nil {
+		t.Error("Expected schedule goals to be deleted")
+	}
+	if predictions, _ := su.s.GetPredictionsForBaby(su.ctx, f.baby.ID); len(predictions) != 0 {
+		t.Error("Expected predictions to be deleted")
+	}
+	if record, _ := su.s.GetIdempotencyRecord(su.ctx, f.family.ID, key); record != nil {
+		t.Error("Expected idempotency record to be deleted")
+	}
+	if deleted, _ := su.s.GetDeletedActivity(su.ctx, tombstone.ActivityID); deleted != nil {
+		t.Error("Expected tombstone to be deleted")
+	}
+	if prefs, _ := su.s.GetReminderPreferences(su.ctx, f.caregiver.ID); prefs != nil {
+		t.Error("Expected reminder preferences to be deleted")
+	}
+	if _, err := su.s.GetWebhookSubscriptionByID(su.ctx, sub.ID); err == nil {
+		t.Error("Expected webhook subscription to be deleted")
+	}
+	if deliveries, _ := su.s.GetWebhookDeliveriesForFamily(su.ctx, f.family.ID, nil, 10); len(deliveries) != 0 {
+		t.Error("Expected webhook deliveries to be deleted")
+	}
+	if _, err := su.s.GetFamilyInviteByID(su.ctx, invite.ID); err == nil {
+		t.Error("Expected invite to be deleted")
+	}
+	if events, _ := su.s.GetAuditEventsForFamily(su.ctx, f.family.ID, domain.AuditFilter{}, 10, nil, nil); len(events) != 0 {
+		t.Error("Expected audit events to be deleted")
+	}
+}
+
+func (su *suite) testDeleteCaregiverCascades(t *testing.T) {
+	f := su.newFamily(t)
+	session := su.newSession(t, f, domain.StatusCompleted, su.base)
+	feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.base)
+	measurement := &domain.GrowthMeasurement{
+		ID: uuid.New(), FamilyID: f.family.ID, BabyID: f.baby.ID, CaregiverID: &f.caregiver.ID,
+		MeasuredAt: su.base, CreatedAt: su.base, UpdatedAt: su.base,
+	}
+	if err := su.s.CreateGrowthMeasurement(su.ctx, measurement); err != nil {
+		t.Fatalf("Failed to create growth measurement: %v", err)
+	}
+	if _, err := su.s.UpsertReminderPreferences(su.ctx, &domain.ReminderPreferences{CaregiverID: f.caregiver.ID, FeedReminders: true}); err != nil {
+		t.Fatalf("Failed to save reminder preferences: %v", err)
+	}
+	invite := su.newInvite(t, f, su.base, nil)
+
+	if err := su.s.DeleteCaregiver(su.ctx, f.caregiver.ID); err != nil {
+		t.Fatalf("Failed to delete caregiver: %v", err)
+	}
+
+	if _, err := su.s.GetCareSessionByID(su.ctx, session.ID); err == nil {
+		t.Error("Expected caregiver's session to be deleted")
+	}
+	if _, err := su.s.GetActivityByID(su.ctx, feed.ID); err == nil {
+		t.Error("Expected caregiver's activities to be deleted")
+	}
+	measurements, err := su.s.GetGrowthMeasurementsForBaby(su.ctx, f.baby.ID)
+	if err != nil || len(measurements) != 1 {
+		t.Fatalf("Expected measurement to survive its caregiver, got %d (err %v)", len(measurements), err)
+	}
+	if measurements[0].CaregiverID != nil {
+		t.Error("Expected measurement to lose its caregiver link")
+	}
+	if prefs, _ := su.s.GetReminderPreferences(su.ctx, f.caregiver.ID); prefs != nil {
+		t.Error("Expected caregiver's reminder preferences to be deleted")
+	}
+	if got, err := su.s.GetFamilyInviteByID(su.ctx, invite.ID); err != nil || got.CreatedBy != nil {
+		t.Errorf("Expected invite to survive without its creator, got %+v (err %v)", got, err)
+	}
+	if _, err := su.s.GetBabyByID(su.ctx, f.baby.ID); err != nil {
+		t.Errorf("Expected baby to survive: %v", err)
+	}
+}
+
+func (su *suite) testTrash(t *testing.T) {
+	f := su.newFamily(t)
+	other := su.newFamily(t)
+	since := time.Now().UTC().Add(-time.Hour)
+
+	t.Run("DeletedActivitiesAreHidden", func(t *testing.T) {
+		session := su.newSession(t, f, domain.StatusCompleted, su.base)
+		feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.at(time.Hour))
+		sleep, _ := su.newSleep(t, session.ID, f.baby.ID, su.at(2*time.Hour))
+		dose := su.newDose(t, session.ID, f.baby.ID, "Tylenol", nil, su.at(3*time.Hour))
+
+		for _, id := range []uuid.UUID{feed.ID, sleep.ID, dose.ActivityID} {
+			if err := su.s.DeleteActivity(su.ctx, id); err != nil {
+				t.Fatalf("Failed to delete activity: %v", err)
+			}
+		}
+
+		activities, err := su.s.GetActivitiesForSession(su.ctx, session.ID)
+		if err != nil || len(activities) != 0 {
+			t.Errorf("Expected no session activities, got %d (err %v)", len(activities), err)
+		}
+		if latest, err := su.s.GetLatestActivityByTypeForBaby(su.ctx, f.baby.ID, domain.ActivityTypeFeed); err != nil || latest != nil {
+			t.Errorf("Expected no latest feed, got %v (err %v)", latest, err)
+		}
+		if feeds, err := su.s.GetRecentFeedDetailsForBaby(su.ctx, f.baby.ID, 10); err != nil || len(feeds) != 0 {
+			t.Errorf("Expected no recent feeds, got %d (err %v)", len(feeds), err)
+		}
+		if sleeps, err := su.s.GetRecentSleepDetailsForBaby(su.ctx, f.baby.ID, 10); err != nil || len(sleeps) != 0 {
+			t.Errorf("Expected no recent sleeps, got %d (err %v)", len(sleeps), err)
+		}
+		if doses, err := su.s.GetRecentMedicationDetailsForBaby(su.ctx, f.baby.ID, su.base); err != nil || len(doses) != 0 {
+			t.Errorf("Expected no recent doses, got %d (err %v)", len(doses), err)
+		}
+		if doses, err := su.s.GetLatestMedicationDetailsForBaby(su.ctx, f.baby.ID); err != nil || len(doses) != 0 {
+			t.Errorf("Expected no latest doses, got %d (err %v)", len(doses), err)
+		}
+		if updated, err := su.s.GetActivitiesUpdatedSinceForFamily(su.ctx, f.family.ID, since); err != nil || len(updated) != 0 {
+			t.Errorf("Expected no updated activities, got %d (err %v)", len(updated), err)
+		}
+		if _, err := su.s.TouchActivity(su.ctx, feed.ID, nil); err == nil {
+			t.Error("Expected error touching a deleted activity")
+		}
+
+		if err := su.s.PurgeDeleted(su.ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Failed to purge: %v", err)
+		}
+		for _, id := range []uuid.UUID{feed.ID, sleep.ID, dose.ActivityID} {
+			if err := su.s.RestoreActivity(su.ctx, id); err == nil {
+				t.Errorf("Expected purged activity %s to be gone", id)
+			}
+		}
+		if _, err := su.s.GetFeedDetails(su.ctx, feed.ID); err == nil {
+			t.Error("Expected feed details to be purged")
+		}
+	})
+
+	t.Run("RestoreActivity", func(t *testing.T) {
+		session := su.newSession(t, f, domain.StatusCompleted, su.base)
+		feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.at(time.Hour))
+		sleep, _ := su.newSleep(t, session.ID, f.baby.ID, su.at(2*time.Hour))
+		for _, id := range []uuid.UUID{sleep.ID, feed.ID} {
+			if err := su.s.DeleteActivity(su.ctx, id); err != nil {
+				t.Fatalf("Failed to delete activity: %v", err)
+			}
+		}
+
+		deleted, err := su.s.GetRecentlyDeletedActivitiesForFamily(su.ctx, f.family.ID, since)
+		if err != nil {
+			t.Fatalf("Failed to get deleted activities: %v", err)
+		}
+		expectIDs(t, "deleted activities", ids(deleted, activityIDOf), []uuid.UUID{feed.ID, sleep.ID})
+		if deleted[0].DeletedAt == nil {
+			t.Error("Expected DeletedAt to be set")
+		}
+		deleted, err = su.s.GetRecentlyDeletedActivitiesForFamily(su.ctx, other.family.ID, since)
+		if err != nil || len(deleted) != 0 {
+			t.Errorf("Expected no deleted activities for another family, got %d (err %v)", len(deleted), err)
+		}
+
+		if err := su.s.RestoreActivity(su.ctx, feed.ID); err != nil {
+			t.Fatalf("Failed to restore activity: %v", err)
+		}
+		restored, err := su.s.GetActivityByID(su.ctx, feed.ID)
+		if err != nil || restored.DeletedAt != nil {
+			t.Fatalf("Expected restored activity, got %+v (err %v)", restored, err)
+		}
+		if _, err := su.s.GetFeedDetails(su.ctx, feed.ID); err != nil {
+			t.Errorf("Expected feed details to come back: %v", err)
+		}
+		if updated, err := su.s.GetActivitiesUpdatedSinceForFamily(su.ctx, f.family.ID, since); err != nil || len(updated) != 1 {
+			t.Errorf("Expected the restored activity to count as updated, got %d (err %v)", len(updated), err)
+		}
+
+		if err := su.s.RestoreActivity(su.ctx, feed.ID); err == nil {
+			t.Error("Expected error restoring an activity that isn't deleted")
+		}
+		if err := su.s.RestoreActivity(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error restoring a missing activity")
+		}
+		if err := su.s.DeleteActivity(su.ctx, feed.ID); err != nil {
+			t.Errorf("Failed to delete restored activity again: %v", err)
+		}
+	})
+
+	t.Run("DeleteAndRestoreCareSession", func(t *testing.T) {
+		session := su.newSession(t, f, domain.StatusCompleted, su.at(5*time.Hour))
+		feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.at(5*time.Hour))
+		sleep, _ := su.newSleep(t, session.ID, f.baby.ID, su.at(6*time.Hour))
+		// Deleted on its own first, so restoring the session leaves it deleted
+		earlier, _ := su.newFeed(t, session.ID, f.baby.ID, su.at(7*time.Hour))
+		if err := su.s.DeleteActivity(su.ctx, earlier.ID); err != nil {
+			t.Fatalf("Failed to delete activity: %v", err)
+		}
+
+		if err := su.s.DeleteCareSession(su.ctx, session.ID); err != nil {
+			t.Fatalf("Failed to delete session: %v", err)
+		}
+		if _, err := su.s.GetCareSessionByID(su.ctx, session.ID); err == nil {
+			t.Error("Expected session to be hidden")
+		}
+		history, err := su.s.GetCareSessionHistoryForFamily(su.ctx, f.family.ID, 100, nil, nil)
+		if err != nil || slices.ContainsFunc(history, func(cs *domain.CareSession) bool { return cs.ID == session.ID }) {
+			t.Errorf("Expected session to be left out of history (err %v)", err)
+		}
+		if err := su.s.UpdateCareSession(su.ctx, session); err == nil {
+			t.Error("Expected error updating a deleted session")
+		}
+		for _, id := range []uuid.UUID{feed.ID, sleep.ID} {
+			if _, err := su.s.GetActivityByID(su.ctx, id); err == nil {
+				t.Errorf("Expected activity %s to be deleted with its session", id)
+			}
+		}
+		if err := su.s.RestoreActivity(su.ctx, feed.ID); err == nil {
+			t.Error("Expected error restoring an activity whose session is deleted")
+		}
+
+		sessions, err := su.s.GetRecentlyDeletedCareSessionsForFamily(su.ctx, f.family.ID, since)
+		if err != nil {
+			t.Fatalf("Failed to get deleted sessions: %v", err)
+		}
+		expectIDs(t, "deleted sessions", ids(sessions, sessionIDOf), []uuid.UUID{session.ID})
+		// The session's activities stay readable through it, and not on their own
+		activities, err := su.s.GetActivitiesForSession(su.ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to get activities: %v", err)
+		}
+		expectIDs(t, "deleted session's activities", ids(activities, activityIDOf), []uuid.UUID{feed.ID, sleep.ID})
+		deleted, err := su.s.GetRecentlyDeletedActivitiesForFamily(su.ctx, f.family.ID, since)
+		if err != nil || slices.ContainsFunc(deleted, func(a *domain.Activity) bool { return a.CareSessionID == session.ID }) {
+			t.Errorf("Expected the session's activities to be left out of deleted activities (err %v)", err)
+		}
+
+		if err := su.s.RestoreCareSession(su.ctx, session.ID); err != nil {
+			t.Fatalf("Failed to restore session: %v", err)
+		}
+		if _, err := su.s.GetCareSessionByID(su.ctx, session.ID); err != nil {
+			t.Errorf("Expected restored session: %v", err)
+		}
+		activities, err = su.s.GetActivitiesForSession(su.ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to get activities: %v", err)
+		}
+		expectIDs(t, "restored session's activities", ids(activities, activityIDOf), []uuid.UUID{feed.ID, sleep.ID})
+
+		if err := su.s.RestoreCareSession(su.ctx, session.ID); err == nil {
+			t.Error("Expected error restoring a session that isn't deleted")
+		}
+		if err := su.s.DeleteCareSession(su.ctx, uuid.New()); err == nil {
+			t.Error("Expected error deleting a missing session")
+		}
+	})
+
+	t.Run("PurgeCascades", func(t *testing.T) {
+		session := su.newSession(t, f, domain.StatusCompleted, su.base)
+		feed, _ := su.newFeed(t, session.ID, f.baby.ID, su.base)
+		sleep, _ := su.newSleep(t, session.ID, f.baby.ID, su.base)
+		prediction := su.newPrediction(f, f.baby.ID, su.base)
+		prediction.CareSessionID = &session.ID
+		if err := su.s.UpsertPredictions(su.ctx, f.baby.ID, []*domain.Prediction{prediction}); err != nil {
+			t.Fatalf("Failed to create predictions: %v", err)
+		}
+		kept := su.newSession(t, f, domain.StatusCompleted, su.base)
+		if err := su.s.DeleteCareSession(su.ctx, session.ID); err != nil {
+			t.Fatalf("Failed to delete session: %v", err)
+		}
+		if err := su.s.DeleteCareSession(su.ctx, kept.ID); err != nil {
+			t.Fatalf("Failed to delete session: %v", err)
+		}
+
+		// Nothing is old enough yet
+		if err := su.s.PurgeDeleted(su.ctx, since); err != nil {
+			t.Fatalf("Failed to purge: %v", err)
+		}
+		sessions, err := su.s.GetRecentlyDeletedCareSessionsForFamily(su.ctx, f.family.ID, since)
+		if err != nil || len(sessions) < 2 {
+			t.Fatalf("Expected both sessions to survive an early purge, got %d (err %v)", len(sessions), err)
+		}
+
+		if err := su.s.PurgeDeleted(su.ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Failed to purge: %v", err)
+		}
+		for _, id := range []uuid.UUID{session.ID, kept.ID} {
+			if err := su.s.RestoreCareSession(su.ctx, id); err == nil {
+				t.Errorf("Expected purged session %s to be gone", id)
+			}
+		}
+		for _, id := range []uuid.UUID{feed.ID, sleep.ID} {
+			if err := su.s.RestoreActivity(su.ctx, id); err == nil {
+				t.Errorf("Expected purged activity %s to be gone", id)
+			}
+		}
+		if _, err := su.s.GetSleepDetails(su.ctx, sleep.ID); err == nil {
+			t.Error("Expected sleep details to be purged")
+		}
+		predictions, err := su.s.GetPredictionsForBaby(su.ctx, f.baby.ID)
+		if err != nil || len(predictions) != 1 {
+			t.Fatalf("Expected prediction to survive its session, got %d (err %v)", len(predictions), err)
+		}
+		if predictions[0].CareSessionID != nil {
+			t.Error("Expected prediction to lose its session link")
+		}
+	})
+}
+
+func (su *suite) testTransactions(t *testing.T) {
+	f := su.newFamily(t)
+	errRollback := errors.New("rollback")
+
+	t.Run("CommitOnSuccess", func(t *testing.T) {
+		baby := &domain.Baby{ID: uuid.New(), FamilyID: f.family.ID, Name: "Committed", CreatedAt: su.base, UpdatedAt: su.base}
+		err := su.s.WithTx(su.ctx, func(tx store.Store) error {
+			if err := tx.CreateBaby(su.ctx, baby); err != nil {
+				return err
+			}
+			// Writes are visible inside the transaction
+			_, err := tx.GetBabyByID(su.ctx, baby.ID)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Transaction failed: %v", err)
+		}
+		if _, err := su.s.GetBabyByID(su.ctx, baby.ID); err != nil {
+			t.Errorf("Expected committed baby: %v", err)
+		}
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		baby := &domain.Baby{ID: uuid.New(), FamilyID: f.family.ID, Name: "Rolled Back", CreatedAt: su.base, UpdatedAt: su.base}
+		err := su.s.WithTx(su.ctx, func(tx store.Store) error {
+			if err := tx.CreateBaby(su.ctx, baby); err != nil {
+				return err
+			}
+			if err := tx.DeleteCaregiver(su.ctx, f.caregiver.ID); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("Expected fn's error to be returned, got %v", err)
+		}
+		if _, err := su.s.GetBabyByID(su.ctx, baby.ID); err == nil {
+			t.Error("Expected baby insert to be rolled back")
+		}
+		if _, err := su.s.GetCaregiverByID(su.ctx, f.caregiver.ID); err != nil {
+			t.Errorf("Expected caregiver delete to be rolled back: %v", err)
+		}
+	})
+
+	t.Run("NestedJoinsOuter", func(t *testing.T) {
+		outer := &domain.Baby{ID: uuid.New(), FamilyID: f.family.ID, Name: "Outer", CreatedAt: su.base, UpdatedAt: su.base}
+		inner := &domain.Baby{ID: uuid.New(), FamilyID: f.family.ID, Name: "Inner", CreatedAt: su.base, UpdatedAt: su.base}
+		err := su.s.WithTx(su.ctx, func(tx store.Store) error {
+			if err := tx.CreateBaby(su.ctx, outer); err != nil {
+				return err
+			}
+			if err := tx.WithTx(su.ctx, func(nested store.Store) error {
+				return nested.CreateBaby(su.ctx, inner)
+			}); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("Expected fn's error to be returned, got %v", err)
+		}
+		for _, baby := range []*domain.Baby{outer, inner} {
+			if _, err := su.s.GetBabyByID(su.ctx, baby.ID); err == nil {
+				t.Errorf("Expected %s baby to be rolled back with the outer transaction", baby.Name)
+			}
+		}
+	})
+}
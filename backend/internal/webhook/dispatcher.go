@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts = 8
+
+	// The first retry waits baseRetryDelay, doubling after each failure up to maxRetryDelay
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 2 * time.Hour
+
+	// batchSize bounds how many deliveries one tick sends
+	batchSize = 50
+
+	requestTimeout = 10 * time.Second
+
+	// maxErrorLength truncates receiver error messages stored on a delivery
+	maxErrorLength = 500
+)
+
+// Dispatcher sends queued deliveries. Only one dispatcher should run per database.
+type Dispatcher struct {
+	store    store.Store
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewDispatcher creates a dispatcher that polls for due deliveries every interval.
+func NewDispatcher(s store.Store, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:    s,
+		client:   &http.Client{Timeout: requestTimeout},
+		interval: interval,
+	}
+}
+
+// Run sends due deliveries every interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := d.Tick(ctx, now); err != nil {
+				log.Printf("webhook dispatcher: %v", err)
+			}
+		}
+	}
+}
+
+// Tick attempts every delivery due at now and records each outcome.
+func (d *Dispatcher) Tick(ctx context.Context, now time.Time) error {
+	deliveries, err := d.store.GetDueWebhookDeliveries(ctx, now, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		d.attempt(ctx, delivery, now)
+		if err := d.store.UpdateWebhookDelivery(ctx, delivery); err != nil {
+			log.Printf("webhook dispatcher: delivery %s: %v", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// attempt sends a delivery once and updates its status, attempt count and next attempt time
+func (d *Dispatcher) attempt(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) {
+	delivery.Attempts++
+
+	statusCode, err := d.send(ctx, delivery, now)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+		return
+	}
+
+	msg := err.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+	delivery.LastError = &msg
+
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = domain.WebhookDeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = now.Add(RetryDelay(delivery.Attempts))
+}
+
+// send POSTs the delivery's payload to its subscription's URL. It returns the response
+// status, if there was one, and an error unless the receiver answered 2xx.
+func (d *Dispatcher) send(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) (*int, error) {
+	sub, err := d.store.GetWebhookSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(now.Unix()))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, now, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return &resp.StatusCode, nil
+}
+
+// RetryDelay returns how long to wait after the given number of failed attempts
+func RetryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,90 @@
+// Package webhook delivers a family's care events to the URLs it subscribed. Events are
+// queued in the store and sent by a Dispatcher as HMAC-SHA256 signed JSON, with
+// exponential backoff between failed attempts.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+)
+
+// Request headers sent with every delivery
+const (
+	HeaderDeliveryID = "X-BabyBaton-Delivery"
+	HeaderEvent      = "X-BabyBaton-Event"
+	HeaderTimestamp  = "X-BabyBaton-Timestamp"
+	HeaderSignature  = "X-BabyBaton-Signature"
+)
+
+// Event is the JSON body of a delivery. Like subscription events it carries IDs rather
+// than full records; receivers that need more can query the API.
+type Event struct {
+	ID         uuid.UUID               `json:"id"`
+	Type       domain.WebhookEventType `json:"type"`
+	FamilyID   uuid.UUID               `json:"familyId"`
+	OccurredAt time.Time               `json:"occurredAt"`
+	Data       map[string]any          `json:"data"`
+}
+
+// Enqueue queues event for every subscription of its family whose filter includes the
+// event type. It does nothing if the family has no matching subscriptions.
+func Enqueue(ctx context.Context, s store.Store, eventType domain.WebhookEventType, familyID uuid.UUID, data map[string]any) error {
+	subs, err := s.GetWebhookSubscriptionsForFamily(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	now := time.Now()
+	var payload []byte
+	for _, sub := range subs {
+		if !sub.Wants(eventType) {
+			continue
+		}
+
+		// Every subscription receives the same event ID so receivers can deduplicate
+		if payload == nil {
+			payload, err = json.Marshal(Event{ID: uuid.New(), Type: eventType, FamilyID: familyID, OccurredAt: now, Data: data})
+			if err != nil {
+				return fmt.Errorf("failed to encode webhook event: %w", err)
+			}
+		}
+
+		delivery := &domain.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: sub.ID,
+			FamilyID:       familyID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.CreateWebhookDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sign returns the signature header value for a delivery body sent at timestamp: the
+// hex HMAC-SHA256 of "<unix timestamp>.<body>" keyed by the subscription secret.
+// Receivers recompute it to check that the request came from us and wasn't replayed.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
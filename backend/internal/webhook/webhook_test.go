@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
+	"github.com/swatkatz/babybaton/backend/internal/store/memory"
+)
+
+type webhookFixture struct {
+	store  *memory.MemoryStore
+	family *domain.Family
+}
+
+func newWebhookFixture(t *testing.T) webhookFixture {
+	t.Helper()
+	now := time.Now()
+	s := memory.NewMemoryStore()
+
+	family := &domain.Family{ID: uuid.New(), Name: "Webhook Family", CreatedAt: now, UpdatedAt: now}
+	baby := &domain.Baby{ID: uuid.New(), FamilyID: family.ID, Name: "Emma", CreatedAt: now, UpdatedAt: now}
+	caregiver := &domain.Caregiver{ID: uuid.New(), FamilyID: family.ID, Name: "Parent", CreatedAt: now, UpdatedAt: now}
+	if err := s.CreateFamilyWithCaregiver(context.Background(), family, baby, caregiver); err != nil {
+		t.Fatalf("Failed to create family: %v", err)
+	}
+
+	return webhookFixture{store: s, family: family}
+}
+
+func (f webhookFixture) subscribe(t *testing.T, url string, eventTypes ...domain.WebhookEventType) *domain.WebhookSubscription {
+	t.Helper()
+	now := time.Now()
+	sub := &domain.WebhookSubscription{
+		ID: uuid.New(), FamilyID: f.family.ID, URL: url, Secret: "s3cret",
+		EventTypes: eventTypes, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := f.store.CreateWebhookSubscription(context.Background(), sub); err != nil {
+		t.Fatalf("Failed to create webhook subscription: %v", err)
+	}
+	return sub
+}
+
+func (f webhookFixture) deliveries(t *testing.T) []*domain.WebhookDelivery {
+	t.Helper()
+	deliveries, err := f.store.GetWebhookDeliveriesForFamily(context.Background(), f.family.ID, nil, 100)
+	if err != nil {
+		t.Fatalf("Failed to get deliveries: %v", err)
+	}
+	return deliveries
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	got := Sign("secret", time.Unix(1700000000, 0), []byte(`{"a":1}`))
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+	if got == Sign("secret", time.Unix(1700000001, 0), []byte(`{"a":1}`)) {
+		t.Error("Expected a different timestamp to change the signature")
+	}
+}
+
+func TestEnqueueFiltersByEventType(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(t)
+	all := f.subscribe(t, "https://example.com/all")
+	f.subscribe(t, "https://example.com/sessions", domain.WebhookEventSessionStarted)
+	activities := f.subscribe(t, "https://example.com/activities", domain.WebhookEventActivityAdded)
+
+	data := map[string]any{"activityId": uuid.New()}
+	if err := Enqueue(ctx, f.store, domain.WebhookEventActivityAdded, f.family.ID, data); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	deliveries := f.deliveries(t)
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(deliveries))
+	}
+
+	var eventIDs []uuid.UUID
+	for _, d := range deliveries {
+		if d.SubscriptionID != all.ID && d.SubscriptionID != activities.ID {
+			t.Errorf("Unexpected delivery to subscription %s", d.SubscriptionID)
+		}
+		if d.Status != domain.WebhookDeliveryPending || d.EventType != domain.WebhookEventActivityAdded {
+			t.Errorf("Unexpected delivery: %+v", d)
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(d.Payload), &event); err != nil {
+			t.Fatalf("Invalid payload %q: %v", d.Payload, err)
+		}
+		if event.Type != domain.WebhookEventActivityAdded || event.FamilyID != f.family.ID || event.Data["activityId"] != data["activityId"].(uuid.UUID).String() {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+		eventIDs = append(eventIDs, event.ID)
+	}
+	if eventIDs[0] != eventIDs[1] {
+		t.Errorf("Expected every subscription to get the same event ID, got %v", eventIDs)
+	}
+}
+
+func TestDispatcherDeliversSignedPayload(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(t)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sub := f.subscribe(t, server.URL)
+	if err := Enqueue(ctx, f.store, domain.WebhookEventSessionStarted, f.family.ID, map[string]any{}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	now := time.Now()
+	d := NewDispatcher(f.store, time.Minute)
+	if err := d.Tick(ctx, now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+
+	if received == nil {
+		t.Fatal("Expected the webhook to be called")
+	}
+	if got := received.Header.Get(HeaderEvent); got != string(domain.WebhookEventSessionStarted) {
+		t.Errorf("%s = %q", HeaderEvent, got)
+	}
+	if got, want := received.Header.Get(HeaderSignature), Sign(sub.Secret, now, body); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+
+	delivery := f.deliveries(t)[0]
+	if delivery.Status != domain.WebhookDeliveryDelivered || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Errorf("Expected delivery to be marked delivered, got %+v", delivery)
+	}
+	if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusNoContent {
+		t.Errorf("Expected status code 204 to be recorded, got %v", delivery.LastStatusCode)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	f := newWebhookFixture(t)
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	f.subscribe(t, server.URL)
+	if err := Enqueue(ctx, f.store, domain.WebhookEventActivityDeleted, f.family.ID, map[string]any{}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	d := NewDispatcher(f.store, time.Minute)
+	now := time.Now()
+	if err := d.Tick(ctx, now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+
+	delivery := f.deliveries(t)[0]
+	if delivery.Status != domain.WebhookDeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("Expected a pending retry, got %+v", delivery)
+	}
+	if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusServiceUnavailable || delivery.LastError == nil {
+		t.Errorf("Expected the failure to be recorded, got %+v", delivery)
+	}
+	if want := now.Add(RetryDelay(1)); !delivery.NextAttemptAt.Equal(want) {
+		t.Errorf("NextAttemptAt = %v, want %v", delivery.NextAttemptAt, want)
+	}
+
+	// Not due again until the backoff passes
+	if err := d.Tick(ctx, now.Add(time.Second)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected no retry before the backoff passed, got %d calls", calls)
+	}
+
+	for calls < MaxAttempts {
+		now = f.deliveries(t)[0].NextAttemptAt
+		if err := d.Tick(ctx, now); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+	}
+
+	delivery = f.deliveries(t)[0]
+	if delivery.Status != domain.WebhookDeliveryFailed || delivery.Attempts != MaxAttempts {
+		t.Errorf("Expected delivery to fail after %d attempts, got %+v", MaxAttempts, delivery)
+	}
+	if err := d.Tick(ctx, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if calls != MaxAttempts {
+		t.Errorf("Expected no attempts after failing, got %d calls", calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{20, maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := RetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
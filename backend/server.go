@@ -1,23 +1,35 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 	"github.com/swatkatz/babybaton/backend/graph"
 	"github.com/swatkatz/babybaton/backend/internal/auth"
+	"github.com/swatkatz/babybaton/backend/internal/dataloader"
+	"github.com/swatkatz/babybaton/backend/internal/devicetoken"
+	"github.com/swatkatz/babybaton/backend/internal/domain"
 	"github.com/swatkatz/babybaton/backend/internal/middleware"
+	"github.com/swatkatz/babybaton/backend/internal/ratelimit"
+	"github.com/swatkatz/babybaton/backend/internal/reminder"
+	"github.com/swatkatz/babybaton/backend/internal/store"
+	"github.com/swatkatz/babybaton/backend/internal/store/memory"
 	"github.com/swatkatz/babybaton/backend/internal/store/postgres"
+	"github.com/swatkatz/babybaton/backend/internal/webhook"
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
@@ -38,28 +50,147 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable is required")
 	}
 
-	// Initialize database store
-	store, err := postgres.NewPostgresStore(databaseURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// Initialize database store (DATABASE_URL=memory runs without Postgres; data is lost on restart)
+	var store store.Store
+	if databaseURL == "memory" {
+		store = memory.NewMemoryStore()
+		log.Println("Using in-memory store")
+	} else {
+		pgStore, err := postgres.NewPostgresStore(databaseURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		store = pgStore
+		log.Println("Successfully connected to database")
 	}
 	defer store.Close()
-	log.Println("Successfully connected to database")
 
-	// Initialize auth verifier (optional — falls back to header-only auth if no Supabase URL)
+	// Device tokens are signed with DEVICE_TOKEN_SECRET. Without one, a random secret is
+	// used and every device has to join again after a restart.
+	deviceTokenSecret := []byte(os.Getenv("DEVICE_TOKEN_SECRET"))
+	if len(deviceTokenSecret) == 0 {
+		secret, err := devicetoken.RandomSecret()
+		if err != nil {
+			log.Fatal(err)
+		}
+		deviceTokenSecret = secret
+		log.Println("DEVICE_TOKEN_SECRET not set; device tokens won't survive a restart")
+	}
+	deviceTokens := devicetoken.NewSigner(deviceTokenSecret)
+
+	// ALLOW_UNSIGNED_DEVICE_AUTH=true keeps accepting bare X-Caregiver-Id / X-Family-Id
+	// headers from clients that predate device tokens
+	allowUnsigned := os.Getenv("ALLOW_UNSIGNED_DEVICE_AUTH") == "true"
+	deviceAuth := middleware.NewDeviceAuth(deviceTokens, store, allowUnsigned)
+	if allowUnsigned {
+		log.Println("Accepting unsigned device auth headers")
+	}
+
+	// Initialize auth verifier (optional — falls back to device-only auth if no Supabase URL)
 	var authMiddleware func(http.Handler) http.Handler
+	var websocketInit transport.WebsocketInitFunc
 	if supabaseURL := os.Getenv("SUPABASE_URL"); supabaseURL != "" {
 		verifier := auth.NewSupabaseVerifier(supabaseURL)
-		dualAuth := middleware.NewDualAuthMiddleware(verifier, store)
+		dualAuth := middleware.NewDualAuthMiddleware(verifier, store, deviceAuth)
 		authMiddleware = dualAuth.Handler
-		log.Println("Dual auth enabled (JWT via JWKS + header-based)")
+		websocketInit = dualAuth.WebsocketInit
+		log.Println("Dual auth enabled (JWT via JWKS + device tokens)")
 	} else {
-		authMiddleware = middleware.AuthMiddleware
-		log.Println("Header-based auth only (SUPABASE_URL not set)")
+		authMiddleware = deviceAuth.Handler
+		websocketInit = deviceAuth.WebsocketInit
+		log.Println("Device token auth only (SUPABASE_URL not set)")
 	}
 
+	// Add CORS middleware (CORS_ALLOWED_ORIGIN supports comma-separated values)
+	allowedOrigins := []string{"http://localhost:8081"}
+	if corsOrigin := os.Getenv("CORS_ALLOWED_ORIGIN"); corsOrigin != "" {
+		for _, origin := range strings.Split(corsOrigin, ",") {
+			if o := strings.TrimSpace(origin); o != "" {
+				allowedOrigins = append(allowedOrigins, o)
+			}
+		}
+	}
 	// Create resolver with store
 	resolver := graph.NewResolver(store)
+	resolver.SetDeviceTokenSigner(deviceTokens)
+	if inviteLinkBase := os.Getenv("INVITE_LINK_BASE_URL"); inviteLinkBase != "" {
+		resolver.SetInviteLinkBase(inviteLinkBase)
+	}
+
+	// Rate limit logins and family name checks. Counters are shared through Postgres
+	// unless RATE_LIMIT_BACKEND=memory; RATE_LIMIT_BACKEND=off disables rate limiting.
+	rateLimit := func(next http.Handler) http.Handler { return next }
+	if backendName := os.Getenv("RATE_LIMIT_BACKEND"); backendName != "off" {
+		var backend ratelimit.Backend = ratelimit.NewMemoryBackend()
+		if pgStore, ok := store.(*postgres.PostgresStore); ok && backendName != "memory" {
+			backend = pgStore.RateLimitBackend()
+		}
+		limiter := ratelimit.NewLimiter(backend, nil)
+		resolver.SetRateLimiter(limiter)
+		rateLimit = ratelimit.Middleware(limiter, os.Getenv("TRUST_PROXY") == "true")
+		go limiter.Run(context.Background(), 10*time.Minute)
+		log.Printf("Rate limiting enabled (%T)", backend)
+	}
+
+	// Deleted activities and sessions can be restored for DELETED_RETENTION, then are purged
+	deletedRetention := graph.DefaultDeletedRetention
+	if v := os.Getenv("DELETED_RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid DELETED_RETENTION: %q", v)
+		}
+		deletedRetention = d
+	}
+	resolver.SetDeletedRetention(deletedRetention)
+	go purgeDeleted(context.Background(), store, deletedRetention, time.Hour)
+	log.Printf("Purging deleted activities and sessions after %s", deletedRetention)
+
+	// Start the reminder scheduler (REMINDER_INTERVAL=0 disables it)
+	reminderInterval := time.Minute
+	if v := os.Getenv("REMINDER_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid REMINDER_INTERVAL: %v", err)
+		}
+		reminderInterval = d
+	}
+	if reminderInterval > 0 {
+		var notifier reminder.Notifier = reminder.LogNotifier{}
+		if webhookURL := os.Getenv("REMINDER_WEBHOOK_URL"); webhookURL != "" {
+			notifier = reminder.NewWebhookNotifier(webhookURL)
+			log.Println("Reminders delivered via webhook")
+		}
+		timezone := os.Getenv("REMINDER_TIMEZONE")
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		scheduler := reminder.NewScheduler(store, notifier, reminderInterval, timezone)
+		scheduler.OnOverdue(func(ctx context.Context, familyID uuid.UUID, baby *domain.Baby, p *domain.Prediction) error {
+			return webhook.Enqueue(ctx, store, domain.WebhookEventPredictionOverdue, familyID, map[string]any{
+				"babyId":         baby.ID,
+				"predictionId":   p.ID,
+				"predictionType": p.PredictionType,
+				"predictedTime":  p.PredictedTime,
+			})
+		})
+		go scheduler.Run(context.Background())
+		log.Printf("Reminder scheduler running every %s", reminderInterval)
+	}
+
+	// Start the webhook dispatcher (WEBHOOK_INTERVAL=0 disables it)
+	webhookInterval := 30 * time.Second
+	if v := os.Getenv("WEBHOOK_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid WEBHOOK_INTERVAL: %v", err)
+		}
+		webhookInterval = d
+	}
+	if webhookInterval > 0 {
+		dispatcher := webhook.NewDispatcher(store, webhookInterval)
+		go dispatcher.Run(context.Background())
+		log.Printf("Webhook dispatcher running every %s", webhookInterval)
+	}
 
 	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
 
@@ -67,6 +198,25 @@ func main() {
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
 	srv.AddTransport(transport.MultipartForm{})
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					// Native clients don't send an Origin header
+					return true
+				}
+				for _, allowed := range allowedOrigins {
+					if origin == allowed {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		InitFunc: websocketInit,
+	})
 
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
@@ -75,15 +225,6 @@ func main() {
 		Cache: lru.New[string](100),
 	})
 
-	// Add CORS middleware (CORS_ALLOWED_ORIGIN supports comma-separated values)
-	allowedOrigins := []string{"http://localhost:8081"}
-	if corsOrigin := os.Getenv("CORS_ALLOWED_ORIGIN"); corsOrigin != "" {
-		for _, origin := range strings.Split(corsOrigin, ",") {
-			if o := strings.TrimSpace(origin); o != "" {
-				allowedOrigins = append(allowedOrigins, o)
-			}
-		}
-	}
 	c := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowCredentials: true,
@@ -98,8 +239,27 @@ func main() {
 	})
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", c.Handler(authMiddleware(srv)))
+	http.Handle("/query", c.Handler(rateLimit(authMiddleware(dataloader.Middleware(store)(srv)))))
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// purgeDeleted permanently removes activities and care sessions deleted more than
+// retention ago, then again every interval until ctx is cancelled.
+func purgeDeleted(ctx context.Context, s store.Store, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeDeleted(ctx, time.Now().Add(-retention)); err != nil {
+			log.Printf("Failed to purge deleted activities and sessions: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
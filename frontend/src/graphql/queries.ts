@@ -250,7 +250,6 @@ export const GET_FAMILY_SETTINGS = gql`
       id
       name
       babyName
-      password
       caregivers {
         id
         name
@@ -0,0 +1,26 @@
+-- Add pumping sessions as a first-class activity type
+-- Creates pump_details table and allows 'pump' in activities.activity_type
+
+-- Update activity_type CHECK constraint to include 'pump'
+ALTER TABLE activities DROP CONSTRAINT activities_activity_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_activity_type_check CHECK (activity_type IN ('feed', 'diaper', 'sleep', 'pump'));
+
+-- Pump details table (amounts are all optional — caregivers often only know the total)
+CREATE TABLE pump_details (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP,
+    left_ml INTEGER CHECK (left_ml >= 0),
+    right_ml INTEGER CHECK (right_ml >= 0),
+    total_ml INTEGER CHECK (total_ml >= 0),
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pump_details_activity ON pump_details(activity_id);
+CREATE INDEX idx_pump_details_start_time ON pump_details(start_time DESC);
+
+CREATE TRIGGER update_pump_details_updated_at BEFORE UPDATE ON pump_details
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,49 @@
+-- Add medication tracking
+-- Creates a per-family medication catalog (dose safety limits) and medication_details for doses given
+-- Allows 'medication' in activities.activity_type
+
+-- Update activity_type CHECK constraint to include 'medication'
+ALTER TABLE activities DROP CONSTRAINT activities_activity_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_activity_type_check CHECK (activity_type IN ('feed', 'diaper', 'sleep', 'pump', 'medication'));
+
+-- Per-family medication catalog
+CREATE TABLE medications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    default_dose_amount DECIMAL(8,2) CHECK (default_dose_amount > 0),
+    default_dose_unit VARCHAR(20) CHECK (default_dose_unit IN ('ml', 'mg', 'drops', 'tablets', 'iu')),
+    default_route VARCHAR(20) CHECK (default_route IN ('oral', 'rectal', 'topical', 'other')),
+    min_interval_minutes INTEGER CHECK (min_interval_minutes > 0),
+    max_daily_doses INTEGER CHECK (max_daily_doses > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Medication names are unique per family regardless of case ("Tylenol" == "tylenol")
+CREATE UNIQUE INDEX idx_medications_family_name ON medications(family_id, LOWER(name));
+
+CREATE TRIGGER update_medications_updated_at BEFORE UPDATE ON medications
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Medication details table (one dose per activity)
+CREATE TABLE medication_details (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+    medication_id UUID REFERENCES medications(id) ON DELETE SET NULL,
+    drug_name VARCHAR(100) NOT NULL,
+    dose_amount DECIMAL(8,2) NOT NULL CHECK (dose_amount > 0),
+    dose_unit VARCHAR(20) NOT NULL CHECK (dose_unit IN ('ml', 'mg', 'drops', 'tablets', 'iu')),
+    route VARCHAR(20) NOT NULL DEFAULT 'oral' CHECK (route IN ('oral', 'rectal', 'topical', 'other')),
+    given_at TIMESTAMP NOT NULL,
+    interval_overridden BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_medication_details_activity ON medication_details(activity_id);
+CREATE INDEX idx_medication_details_given_at ON medication_details(given_at DESC);
+CREATE INDEX idx_medication_details_drug ON medication_details(LOWER(drug_name));
+
+CREATE TRIGGER update_medication_details_updated_at BEFORE UPDATE ON medication_details
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
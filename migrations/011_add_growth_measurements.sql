@@ -0,0 +1,28 @@
+-- Add growth tracking
+-- Adds baby birth date and sex to families (needed for WHO percentiles)
+-- Creates growth_measurements for weight, length and head circumference
+
+ALTER TABLE families ADD COLUMN baby_birth_date DATE;
+ALTER TABLE families ADD COLUMN baby_sex VARCHAR(10) CHECK (baby_sex IN ('male', 'female'));
+
+CREATE TABLE growth_measurements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    caregiver_id UUID REFERENCES caregivers(id) ON DELETE SET NULL,
+    measured_at TIMESTAMP NOT NULL,
+    weight_kg DECIMAL(6,3) CHECK (weight_kg > 0),
+    length_cm DECIMAL(5,1) CHECK (length_cm > 0),
+    head_circumference_cm DECIMAL(5,1) CHECK (head_circumference_cm > 0),
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- A measurement must record at least one value
+    CONSTRAINT growth_measurements_has_value CHECK (
+        weight_kg IS NOT NULL OR length_cm IS NOT NULL OR head_circumference_cm IS NOT NULL
+    )
+);
+
+CREATE INDEX idx_growth_measurements_family_measured_at ON growth_measurements(family_id, measured_at);
+
+CREATE TRIGGER update_growth_measurements_updated_at BEFORE UPDATE ON growth_measurements
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,75 @@
+-- Add multi-baby support
+-- Creates babies (owned by a family) and attaches activities, schedule goals, predictions
+-- and growth measurements to a baby instead of only the family.
+-- Each existing family gets one baby carrying over its name, birth date and sex.
+
+CREATE TABLE babies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    birth_date DATE,
+    sex VARCHAR(10) CHECK (sex IN ('male', 'female')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Baby names are unique per family regardless of case, so voice input can resolve "Ava" unambiguously
+CREATE UNIQUE INDEX idx_babies_family_name ON babies(family_id, LOWER(name));
+
+CREATE TRIGGER update_babies_updated_at BEFORE UPDATE ON babies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO babies (family_id, name, birth_date, sex, created_at, updated_at)
+SELECT id, baby_name, baby_birth_date, baby_sex, created_at, NOW()
+FROM families;
+
+-- Birth date and sex now live on the baby; families.baby_name is kept for older clients
+ALTER TABLE families DROP COLUMN baby_birth_date;
+ALTER TABLE families DROP COLUMN baby_sex;
+
+-- Activities
+ALTER TABLE activities ADD COLUMN baby_id UUID REFERENCES babies(id) ON DELETE CASCADE;
+
+UPDATE activities a
+SET baby_id = b.id
+FROM care_sessions cs
+JOIN babies b ON b.family_id = cs.family_id
+WHERE a.care_session_id = cs.id;
+
+ALTER TABLE activities ALTER COLUMN baby_id SET NOT NULL;
+CREATE INDEX idx_activities_baby_type ON activities(baby_id, activity_type);
+
+-- Schedule goals: one row per baby instead of per family
+ALTER TABLE schedule_goals ADD COLUMN baby_id UUID REFERENCES babies(id) ON DELETE CASCADE;
+
+UPDATE schedule_goals sg
+SET baby_id = b.id
+FROM babies b
+WHERE b.family_id = sg.family_id;
+
+ALTER TABLE schedule_goals ALTER COLUMN baby_id SET NOT NULL;
+ALTER TABLE schedule_goals DROP CONSTRAINT schedule_goals_family_id_key;
+ALTER TABLE schedule_goals ADD CONSTRAINT schedule_goals_baby_id_key UNIQUE (baby_id);
+
+-- Predictions
+ALTER TABLE predictions ADD COLUMN baby_id UUID REFERENCES babies(id) ON DELETE CASCADE;
+
+UPDATE predictions p
+SET baby_id = b.id
+FROM babies b
+WHERE b.family_id = p.family_id;
+
+ALTER TABLE predictions ALTER COLUMN baby_id SET NOT NULL;
+CREATE INDEX idx_predictions_baby ON predictions(baby_id) WHERE dismissed_at IS NULL;
+
+-- Growth measurements
+ALTER TABLE growth_measurements ADD COLUMN baby_id UUID REFERENCES babies(id) ON DELETE CASCADE;
+
+UPDATE growth_measurements gm
+SET baby_id = b.id
+FROM babies b
+WHERE b.family_id = gm.family_id;
+
+ALTER TABLE growth_measurements ALTER COLUMN baby_id SET NOT NULL;
+DROP INDEX idx_growth_measurements_family_measured_at;
+CREATE INDEX idx_growth_measurements_baby_measured_at ON growth_measurements(baby_id, measured_at);
@@ -0,0 +1,24 @@
+-- Add offline sync support
+-- Records idempotency keys for replayed writes and tombstones for deleted activities,
+-- so clients syncing a queue of offline changes can retry safely and learn about deletions.
+
+CREATE TABLE idempotency_keys (
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    activity_id UUID NOT NULL,
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (family_id, idempotency_key)
+);
+
+CREATE TABLE deleted_activities (
+    activity_id UUID PRIMARY KEY,
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    baby_id UUID NOT NULL REFERENCES babies(id) ON DELETE CASCADE,
+    deleted_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_deleted_activities_family_deleted_at ON deleted_activities(family_id, deleted_at);
+
+-- Sync pulls activities changed since a cursor
+CREATE INDEX idx_activities_updated_at ON activities(updated_at);
@@ -0,0 +1,23 @@
+-- Add reminder preferences
+-- Caregivers opt in to server-sent reminders before predicted feeds, naps and bedtime,
+-- and when a prediction becomes overdue. No row means no reminders.
+
+CREATE TABLE reminder_preferences (
+    caregiver_id UUID PRIMARY KEY REFERENCES caregivers(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    feed_reminders BOOLEAN NOT NULL DEFAULT FALSE,
+    nap_reminders BOOLEAN NOT NULL DEFAULT FALSE,
+    bedtime_reminders BOOLEAN NOT NULL DEFAULT FALSE,
+    overdue_reminders BOOLEAN NOT NULL DEFAULT FALSE,
+    lead_minutes INTEGER NOT NULL DEFAULT 15 CHECK (lead_minutes BETWEEN 0 AND 120),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reminder_preferences_family ON reminder_preferences(family_id);
+
+CREATE TRIGGER update_reminder_preferences_updated_at BEFORE UPDATE ON reminder_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- The reminder scheduler looks for families with an in-progress or recently updated session
+CREATE INDEX idx_care_sessions_updated_at ON care_sessions(updated_at);
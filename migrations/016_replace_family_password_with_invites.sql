@@ -0,0 +1,29 @@
+-- Replace the shared plaintext family password with invite codes
+-- Caregivers share expiring, revocable invite codes instead of the family password.
+-- Only a SHA-256 hash of each code is stored.
+--
+-- Migration path for existing families: password_hash is kept, so caregivers who know
+-- the password can still join through joinFamily. The plaintext copy added in 002 is
+-- dropped and cannot be shown again; caregivers already in the family create an
+-- invite to bring in anyone new.
+
+CREATE TABLE family_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES caregivers(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    -- NULL means unlimited
+    max_uses INTEGER CHECK (max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_family_invites_family_created ON family_invites(family_id, created_at DESC);
+
+CREATE TRIGGER update_family_invites_updated_at BEFORE UPDATE ON family_invites
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE families DROP COLUMN password;
@@ -0,0 +1,12 @@
+-- Add rate limiter counters
+-- Keys look like "<kind>:<action>:<scope>:<value>", e.g. "fail:joinFamily:ip:203.0.113.7".
+-- A counter counts hits until reset_at, after which the next hit starts a new window.
+-- Expired rows are pruned periodically by the server.
+
+CREATE TABLE rate_limits (
+    key TEXT PRIMARY KEY,
+    count INTEGER NOT NULL,
+    reset_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_rate_limits_reset_at ON rate_limits(reset_at);
@@ -0,0 +1,6 @@
+-- Add device token generations to caregivers
+-- Device-based clients authenticate with a server-signed token naming their caregiver,
+-- family and token generation. Bumping the generation rotates or revokes the token:
+-- only a token carrying the caregiver's current generation is accepted.
+
+ALTER TABLE caregivers ADD COLUMN token_generation INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+-- Add caregiver roles
+-- Every caregiver used to be an admin. Roles (owner, parent, caregiver, viewer) limit
+-- what a caregiver may change, e.g. a nanny can log care but not delete history.
+--
+-- Existing caregivers become owners so nobody loses access they had; new caregivers
+-- get the role of the invite they join with.
+
+ALTER TABLE caregivers ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'owner'
+    CHECK (role IN ('owner', 'parent', 'caregiver', 'viewer'));
+ALTER TABLE caregivers ALTER COLUMN role SET DEFAULT 'caregiver';
+
+ALTER TABLE family_invites ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'caregiver'
+    CHECK (role IN ('owner', 'parent', 'caregiver', 'viewer'));
@@ -0,0 +1,34 @@
+-- Add an append-only audit log
+-- Every mutation that changes a family's data records who made the change, what it
+-- touched and JSON snapshots of the entity before and after.
+--
+-- actor_caregiver_id has no foreign key and actor_name is copied at write time, so the
+-- log keeps saying who made a change after they leave the family. Events are never
+-- updated; they are only deleted along with their family.
+
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    actor_caregiver_id UUID NOT NULL,
+    actor_name VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'revoke')),
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    -- NULL before a create and after a delete
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_family_created ON audit_events(family_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_id);
+
+CREATE OR REPLACE FUNCTION reject_audit_event_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_update();
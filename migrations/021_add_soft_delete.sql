@@ -0,0 +1,19 @@
+-- Soft delete activities and care sessions
+-- Deleting an activity or session sets deleted_at instead of removing the row, and
+-- every read skips deleted rows. They can be restored until a background job purges
+-- them after the retention window.
+--
+-- Deleting a session also sets deleted_at on its remaining activities, using the
+-- session's timestamp, so restoring the session brings back exactly the activities
+-- that were deleted with it.
+
+ALTER TABLE care_sessions ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE activities ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_care_sessions_deleted_at ON care_sessions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_activities_deleted_at ON activities(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Restores are recorded in the audit log
+ALTER TABLE audit_events DROP CONSTRAINT audit_events_action_check;
+ALTER TABLE audit_events ADD CONSTRAINT audit_events_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'revoke', 'restore'));
@@ -0,0 +1,6 @@
+-- Add versions to activities
+-- Every change to an activity or its details bumps its version. Clients send back the
+-- version they edited, and the update is rejected if someone else changed the activity
+-- in the meantime instead of silently overwriting their edit.
+
+ALTER TABLE activities ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
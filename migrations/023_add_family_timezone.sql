@@ -0,0 +1,16 @@
+-- Add a home timezone to families
+-- Predictions and voice parsing used to take the timezone from each request's X-Timezone
+-- header, so they changed depending on which phone asked. Families now store their home
+-- timezone, with an optional travel timezone that replaces it between two dates.
+
+ALTER TABLE families ADD COLUMN timezone TEXT;
+
+ALTER TABLE families ADD COLUMN travel_timezone TEXT;
+ALTER TABLE families ADD COLUMN travel_start_date DATE;
+ALTER TABLE families ADD COLUMN travel_end_date DATE;
+
+ALTER TABLE families ADD CONSTRAINT families_travel_mode_complete CHECK (
+    (travel_timezone IS NULL) = (travel_start_date IS NULL)
+    AND (travel_timezone IS NULL) = (travel_end_date IS NULL)
+);
+ALTER TABLE families ADD CONSTRAINT families_travel_dates_ordered CHECK (travel_end_date >= travel_start_date);
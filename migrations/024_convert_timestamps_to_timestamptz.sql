@@ -0,0 +1,30 @@
+-- Convert every TIMESTAMP column to TIMESTAMPTZ
+-- Times were stored without a zone, so a value's meaning depended on the zone of whoever
+-- wrote it and on the server's TimeZone setting; times around DST changes could be shifted
+-- or collide. Existing values were written in UTC and are converted as UTC.
+--
+-- Each table is altered once with all its columns, inside one transaction. Columns that are
+-- already TIMESTAMPTZ are skipped, so running this again changes nothing.
+
+BEGIN;
+
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN
+        SELECT table_name,
+               string_agg(format('ALTER COLUMN %I TYPE TIMESTAMPTZ USING %I AT TIME ZONE ''UTC''',
+                                 column_name, column_name), ', ' ORDER BY ordinal_position) AS changes
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND data_type = 'timestamp without time zone'
+        GROUP BY table_name
+        ORDER BY table_name
+    LOOP
+        EXECUTE format('ALTER TABLE %I %s', t.table_name, t.changes);
+    END LOOP;
+END
+$$;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Add prediction outcomes
+-- Predictions are replaced whenever a feed or sleep is logged and cleaned up after a day,
+-- so nothing recorded how they turned out. When an event fulfils an outstanding prediction,
+-- the prediction and the actual time are kept here to track accuracy per family.
+
+CREATE TABLE prediction_outcomes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    baby_id UUID NOT NULL REFERENCES babies(id) ON DELETE CASCADE,
+    -- Not a foreign key: the prediction itself is replaced soon after
+    prediction_id UUID NOT NULL UNIQUE,
+    activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+    prediction_type VARCHAR(20) NOT NULL,
+    confidence VARCHAR(20),
+    predicted_time TIMESTAMPTZ NOT NULL,
+    actual_time TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_prediction_outcomes_family ON prediction_outcomes(family_id, actual_time);
+CREATE INDEX idx_prediction_outcomes_baby ON prediction_outcomes(baby_id, actual_time);
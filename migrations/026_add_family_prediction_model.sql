@@ -0,0 +1,9 @@
+-- Let families choose how predictions estimate typical intervals
+-- The median of recent history lags behind growth spurts and nap transitions, so families
+-- can switch to a recency-weighted average instead. NULL means the median and the default
+-- half-life.
+
+ALTER TABLE families ADD COLUMN prediction_model TEXT
+    CHECK (prediction_model IN ('median', 'recency_weighted'));
+ALTER TABLE families ADD COLUMN prediction_half_life_hours INTEGER
+    CHECK (prediction_half_life_hours > 0);